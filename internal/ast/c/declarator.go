@@ -0,0 +1,563 @@
+package c
+
+import (
+	"slices"
+	"strings"
+
+	"repository-context-protocol/internal/models"
+)
+
+// classKeys are the keywords that introduce a struct, union, class or enum
+var classKeys = map[string]bool{"struct": true, "union": true, "class": true, "enum": true}
+
+// qualifiers are the cv-qualifiers that may appear among pointer operators
+var qualifiers = map[string]bool{"const": true, "volatile": true, "restrict": true, "__restrict": true, "__restrict__": true}
+
+// declarator is one name declared by a declaration with its full type
+type declarator struct {
+	name     string
+	typeName string
+	pointer  bool   // Declared through a pointer, reference or function pointer
+	isConst  bool   // The declared object itself is const-qualified
+	value    string // Literal initializer, if any
+}
+
+// toItems groups bracketed token runs into items. The tokens must be balanced.
+func toItems(tokens []token) []item {
+	var items []item
+	for i := 0; i < len(tokens); i++ {
+		tok := tokens[i]
+		if tok.kind != tokenPunct || (tok.text != "(" && tok.text != "[" && tok.text != "{") {
+			items = append(items, item{tok: tok})
+			continue
+		}
+
+		depth := 0
+		end := i
+		for ; end < len(tokens); end++ {
+			switch tokens[end].text {
+			case "(", "[", "{":
+				depth++
+			case ")", "]", "}":
+				depth--
+			}
+			if depth == 0 && tokens[end].kind == tokenPunct {
+				break
+			}
+		}
+		end = min(end, len(tokens)-1)
+		items = append(items, item{tok: tok, group: tokens[i : end+1]})
+		i = end
+	}
+	return items
+}
+
+// flatten returns the tokens covered by items
+func flatten(items []item) []token {
+	var tokens []token
+	for _, it := range items {
+		tokens = append(tokens, it.tokens()...)
+	}
+	return tokens
+}
+
+// joinItems renders items as source text
+func joinItems(items []item) string {
+	return joinTokens(flatten(items))
+}
+
+// joinTokens renders tokens as source text with conventional C spacing
+func joinTokens(tokens []token) string {
+	var builder strings.Builder
+	for i, tok := range tokens {
+		if i > 0 {
+			prev := tokens[i-1]
+			prevWord := prev.kind != tokenPunct
+			switch {
+			case prev.text == ",":
+				builder.WriteString(" ")
+			case prevWord && tok.kind != tokenPunct, prevWord && tok.text == "~":
+				builder.WriteString(" ")
+			case prev.text == ")" && tok.kind == tokenIdent:
+				builder.WriteString(" ")
+			case (tok.text == "*" || tok.text == "&" || tok.text == "&&") && (prevWord || prev.text == ">"):
+				builder.WriteString(" ")
+			case tok.text == "(" && prevWord && i+1 < len(tokens) && tokens[i+1].text == "*":
+				builder.WriteString(" ")
+			case tok.text == "->" || prev.text == "->":
+				builder.WriteString(" ")
+			case tok.text == "=" && prev.text != "operator" && prev.text != "=" && !isOperatorSymbol(prev.text):
+				builder.WriteString(" ")
+			case prev.text == "=" && tok.text != "=" && tok.text != "(" && (i < 2 || tokens[i-2].text != "operator"):
+				builder.WriteString(" ")
+			case prev.text == "..." && tok.kind == tokenIdent:
+				builder.WriteString(" ")
+			}
+		}
+		builder.WriteString(tok.text)
+	}
+	return builder.String()
+}
+
+// isOperatorSymbol reports whether text combines with '=' into a compound operator
+func isOperatorSymbol(text string) bool {
+	return len(text) == 1 && strings.Contains("!<>+-*/%&|^", text)
+}
+
+// classKeyIndex returns the index of the first class key in items, or -1
+func classKeyIndex(items []item) int {
+	return slices.IndexFunc(items, func(it item) bool {
+		return it.isIdent() && classKeys[it.tok.text]
+	})
+}
+
+// hasAssignment reports whether an '=' outside an operator name appears before limit
+func hasAssignment(items []item, limit int) bool {
+	for i := 0; i < limit && i < len(items); i++ {
+		if items[i].is("=") && !operatorNear(items, i) {
+			return true
+		}
+	}
+	return false
+}
+
+// operatorNear reports whether the token at index is part of an operator function name
+func operatorNear(items []item, index int) bool {
+	for i := index - 1; i >= 0 && i >= index-3; i-- {
+		if items[i].is("operator") {
+			return true
+		}
+	}
+	return false
+}
+
+// hasInitializerList reports whether a constructor initializer list follows the
+// parameter group
+func hasInitializerList(items []item, group int) bool {
+	return slices.ContainsFunc(items[group+1:], func(it item) bool { return it.is(":") })
+}
+
+// findFunctionGroup returns the index of the parameter list of the function a
+// declaration declares, or -1. Prototypes must have parameter lists that could be
+// declarations, which rules out macro invocations with literal arguments.
+func findFunctionGroup(items []item, definition bool) int {
+	for i := 1; i < len(items); i++ {
+		if !items[i].isGroup("(") {
+			continue
+		}
+		// The empty group in "operator()" is part of the name
+		if items[i-1].is("operator") && len(items[i].inner()) == 0 && i+1 < len(items) && items[i+1].isGroup("(") {
+			continue
+		}
+
+		prev := items[i-1]
+		isName := prev.isIdent() && !builtinTypes[prev.tok.text] && !nonNameKeywords[prev.tok.text] &&
+			!attributeKeywords[prev.tok.text] && !storageSpecifiers[prev.tok.text]
+		if !isName && operatorIndex(items, i) < 0 {
+			continue
+		}
+
+		inner := items[i].inner()
+		if len(inner) > 0 && (inner[0].text == "*" || inner[0].text == "&" || inner[0].text == "^") {
+			// A function pointer declarator such as "handler_t (*fn)(int)"
+			return -1
+		}
+		if i+1 < len(items) && (items[i+1].group != nil || !functionSuffixes[items[i+1].tok.text]) {
+			continue
+		}
+		if !definition && !looksLikeParameters(inner) {
+			continue
+		}
+		return i
+	}
+	return -1
+}
+
+// looksLikeParameters reports whether tokens could be a parameter declaration list
+// rather than call arguments
+func looksLikeParameters(tokens []token) bool {
+	depth := 0
+	for i, tok := range tokens {
+		switch tok.text {
+		case "(", "[", "{":
+			depth++
+		case ")", "]", "}":
+			depth--
+		}
+		if depth > 0 && tok.text != "(" && tok.text != "[" && tok.text != "{" {
+			continue
+		}
+
+		afterAssign := i > 0 && tokens[i-1].text == "="
+		if (tok.kind == tokenString || tok.kind == tokenChar) && !afterAssign {
+			return false
+		}
+		startsParam := i == 0 || tokens[i-1].text == ","
+		if startsParam && tok.kind != tokenIdent && tok.text != "..." && tok.text != "::" && tok.text != "[" {
+			return false
+		}
+	}
+	return true
+}
+
+// operatorIndex returns the index of the "operator" keyword naming the function whose
+// parameter list is at group, or -1
+func operatorIndex(items []item, group int) int {
+	for i := group - 1; i >= 0 && i >= group-5; i-- {
+		if items[i].is("operator") {
+			return i
+		}
+		if items[i].group != nil && len(items[i].inner()) > 0 {
+			return -1
+		}
+	}
+	return -1
+}
+
+// functionName extracts the function name before the parameter list at group. It
+// returns the name, the index where the qualified name starts and the class
+// qualifier of out-of-class member definitions such as "Widget::draw".
+func functionName(items []item, group int) (string, int, string) {
+	var name string
+	nameStart := group - 1
+	if op := operatorIndex(items, group); op >= 0 {
+		suffix := items[op+1 : group]
+		name = "operator" + joinItems(suffix)
+		if len(suffix) > 0 && suffix[0].isIdent() {
+			// Conversion operators and operator new/delete
+			name = "operator " + joinItems(suffix)
+		}
+		nameStart = op
+	} else {
+		name = items[nameStart].tok.text
+		if nameStart > 0 && items[nameStart-1].is("~") {
+			name = "~" + name
+			nameStart--
+		}
+	}
+
+	receiver := ""
+	for nameStart >= 2 && items[nameStart-1].is("::") {
+		qualifier := nameStart - 2
+		if items[qualifier].is(">") {
+			qualifier = matchingAngle(items, qualifier) - 1
+		}
+		if qualifier < 0 || !items[qualifier].isIdent() {
+			break
+		}
+		if receiver == "" {
+			receiver = items[qualifier].tok.text
+		}
+		nameStart = qualifier
+	}
+	if nameStart >= 1 && items[nameStart-1].is("::") {
+		nameStart--
+	}
+	return name, nameStart, receiver
+}
+
+// matchingAngle returns the index of the '<' matching the '>' at end, or -1
+func matchingAngle(items []item, end int) int {
+	depth := 0
+	for i := end; i >= 0; i-- {
+		switch {
+		case items[i].is(">"):
+			depth++
+		case items[i].is("<"):
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// removeTypedef removes the typedef specifier, reporting whether it was present
+func removeTypedef(items []item) ([]item, bool) {
+	if !slices.ContainsFunc(items, func(it item) bool { return it.is("typedef") }) {
+		return items, false
+	}
+	return slices.DeleteFunc(slices.Clone(items), func(it item) bool { return it.is("typedef") }), true
+}
+
+// isAttributeGroup reports whether the item is a C++11 attribute such as [[nodiscard]]
+func isAttributeGroup(it item) bool {
+	inner := it.inner()
+	return it.isGroup("[") && len(inner) >= 2 && inner[0].text == "[" && inner[len(inner)-1].text == "]"
+}
+
+// stripAttributes removes compiler attributes, alignment specifiers, asm labels and
+// the language string of extern "C" declarations
+func stripAttributes(items []item) []item {
+	var stripped []item
+	for i := 0; i < len(items); i++ {
+		it := items[i]
+		switch {
+		case it.isIdent() && attributeKeywords[it.tok.text]:
+			if i+1 < len(items) && items[i+1].isGroup("(") {
+				i++
+			}
+		case isAttributeGroup(it), it.is("__extension__"):
+		case it.tok.kind == tokenString && i > 0 && items[i-1].is("extern"):
+		default:
+			stripped = append(stripped, it)
+		}
+	}
+	return stripped
+}
+
+// attributeDecorators returns the C++11 attributes of a declaration, such as "nodiscard"
+func attributeDecorators(items []item) []string {
+	var decorators []string
+	for _, it := range items {
+		if isAttributeGroup(it) {
+			inner := it.inner()
+			decorators = append(decorators, joinTokens(inner[1:len(inner)-1]))
+		}
+	}
+	return decorators
+}
+
+// stripAngles removes template argument lists that follow identifiers
+func stripAngles(items []item) []item {
+	var stripped []item
+	depth := 0
+	for i, it := range items {
+		switch {
+		case it.is("<") && (depth > 0 || (i > 0 && items[i-1].isIdent())):
+			depth++
+		case it.is(">") && depth > 0:
+			depth--
+		case depth == 0:
+			stripped = append(stripped, it)
+		}
+	}
+	return stripped
+}
+
+// splitItems splits items at top-level commas, treating template argument lists as nested
+func splitItems(items []item) [][]item {
+	var parts [][]item
+	var current []item
+	depth := 0
+	for i, it := range items {
+		switch {
+		case it.is("<") && i > 0 && items[i-1].isIdent():
+			depth++
+		case it.is(">") && depth > 0:
+			depth--
+		case it.is(",") && depth == 0:
+			parts = append(parts, current)
+			current = nil
+			continue
+		}
+		current = append(current, it)
+	}
+	if len(current) > 0 {
+		parts = append(parts, current)
+	}
+	return parts
+}
+
+// cutInitializer removes an initializer or bitfield width from a declarator, returning
+// the literal value of the initializer if there is one
+func cutInitializer(items []item) ([]item, string) {
+	for i, it := range items {
+		if (it.is("=") && !operatorNear(items, i)) || it.is(":") {
+			value := ""
+			if it.is("=") {
+				value = literalValue(flatten(items[i+1:]))
+			}
+			return items[:i], value
+		}
+	}
+	if n := len(items); n >= 2 && (items[n-1].isGroup("{") || (items[n-1].isGroup("(") && items[n-2].isIdent())) {
+		// Brace or direct initialization: int count{0}; Widget w(parent);
+		return items[:n-1], literalValue(items[n-1].inner())
+	}
+	return items, ""
+}
+
+// splitDeclarator locates the declared name in a declarator. It returns the index of
+// the name or -1 when the declarator is unnamed, and the index where the pointer and
+// reference operators before the name start.
+func splitDeclarator(items []item, hasBase bool) (int, int) {
+	end := len(items)
+	for end > 0 && items[end-1].isGroup("[") && !isAttributeGroup(items[end-1]) {
+		end--
+	}
+	if end == 0 {
+		return -1, end
+	}
+
+	nameIndex := end - 1
+	candidate := items[nameIndex]
+	if !candidate.isIdent() || builtinTypes[candidate.tok.text] || qualifiers[candidate.tok.text] || storageSpecifiers[candidate.tok.text] {
+		return -1, end
+	}
+	if nameIndex == 0 && !hasBase {
+		return -1, end
+	}
+	if nameIndex > 0 {
+		prev := items[nameIndex-1]
+		if prev.is("::") || prev.is("typename") || (prev.isIdent() && classKeys[prev.tok.text]) {
+			return -1, end
+		}
+	}
+
+	pointerStart := nameIndex
+	for pointerStart > 0 {
+		prev := items[pointerStart-1]
+		if !prev.is("*") && !prev.is("&") && !prev.is("&&") && !(prev.isIdent() && qualifiers[prev.tok.text]) {
+			break
+		}
+		pointerStart--
+	}
+	for pointerStart < nameIndex && items[pointerStart].isIdent() {
+		// Qualifiers before the first operator qualify the base type: int const x
+		pointerStart++
+	}
+	return nameIndex, pointerStart
+}
+
+// parseDeclarators parses the declarators of a declaration. When base is nil the type
+// specifiers are taken from the first declarator, otherwise base is the shared type.
+// Declarators without a name or a type are skipped.
+func parseDeclarators(items []item, base []item) []declarator {
+	var declarators []declarator
+	for _, segment := range splitItems(items) {
+		segment, value := cutInitializer(segment)
+
+		// Function pointers: void (*handler)(int)
+		if group := slices.IndexFunc(segment, isPointerGroup); group >= 0 {
+			inner := segment[group].inner()
+			nameOffset := -1
+			for i, tok := range inner {
+				if tok.kind == tokenIdent && !qualifiers[tok.text] {
+					nameOffset = i
+				}
+			}
+			if nameOffset < 0 || (base == nil && group == 0) {
+				continue
+			}
+			tokens := flatten(slices.Concat(base, segment))
+			nameIndex := len(flatten(slices.Concat(base, segment[:group]))) + 1 + nameOffset
+			name := tokens[nameIndex].text
+			declarators = append(declarators, declarator{
+				name:     name,
+				typeName: joinTokens(slices.Delete(slices.Clone(tokens), nameIndex, nameIndex+1)),
+				pointer:  true,
+				value:    value,
+			})
+			if base == nil {
+				base = segment[:group]
+			}
+			continue
+		}
+
+		nameIndex, pointerStart := splitDeclarator(segment, base != nil)
+		if nameIndex < 0 {
+			continue
+		}
+		if base == nil {
+			if pointerStart == 0 {
+				continue
+			}
+			base = segment[:pointerStart]
+			segment = segment[pointerStart:]
+			nameIndex -= pointerStart
+			pointerStart = 0
+		}
+
+		operators := segment[pointerStart:nameIndex]
+		typeItems := slices.Concat(base, operators, segment[nameIndex+1:])
+		declarators = append(declarators, declarator{
+			name:     segment[nameIndex].tok.text,
+			typeName: joinItems(typeItems),
+			pointer:  slices.ContainsFunc(operators, func(it item) bool { return !it.isIdent() }),
+			isConst:  isConstQualified(base, operators),
+			value:    value,
+		})
+	}
+	return declarators
+}
+
+// isPointerGroup reports whether the item is a parenthesised pointer declarator
+func isPointerGroup(it item) bool {
+	inner := it.inner()
+	if !it.isGroup("(") || len(inner) == 0 {
+		return false
+	}
+	// Calling convention macros may precede the operator: (__stdcall *fn)
+	if inner[0].kind == tokenIdent && len(inner) > 1 {
+		inner = inner[1:]
+	}
+	return inner[0].text == "*" || inner[0].text == "&" || inner[0].text == "^"
+}
+
+// isConstQualified reports whether the declared object is const: a const base type
+// without pointer operators, or a const pointer
+func isConstQualified(base, operators []item) bool {
+	if len(operators) > 0 {
+		last := operators[len(operators)-1]
+		return last.is("const")
+	}
+	return slices.ContainsFunc(base, func(it item) bool { return it.is("const") })
+}
+
+// parseParameters parses the tokens of a parameter list
+func parseParameters(tokens []token) []models.Parameter {
+	parameters := []models.Parameter{}
+	for _, param := range splitItems(stripAttributes(toItems(tokens))) {
+		param, _ = cutInitializer(param)
+		if len(param) == 0 || (len(param) == 1 && param[0].is(typeVoid)) {
+			continue
+		}
+
+		if slices.ContainsFunc(param, isPointerGroup) {
+			declarators := parseDeclarators(param, nil)
+			if len(declarators) == 1 {
+				parameters = append(parameters, models.Parameter{Name: declarators[0].name, Type: declarators[0].typeName})
+			} else {
+				parameters = append(parameters, models.Parameter{Type: joinItems(param)})
+			}
+			continue
+		}
+
+		nameIndex, _ := splitDeclarator(param, false)
+		if nameIndex < 0 {
+			parameters = append(parameters, models.Parameter{Type: joinItems(param)})
+			continue
+		}
+		typeItems := slices.Concat(param[:nameIndex], param[nameIndex+1:])
+		parameters = append(parameters, models.Parameter{Name: param[nameIndex].tok.text, Type: joinItems(typeItems)})
+	}
+	return parameters
+}
+
+// parseTemplateParams parses the tokens of a template parameter list
+func parseTemplateParams(tokens []token) []models.TypeParam {
+	var params []models.TypeParam
+	for _, param := range splitItems(toItems(tokens)) {
+		param, _ = cutInitializer(param)
+		nameIndex := -1
+		for i, it := range param {
+			if it.isIdent() && i > 0 {
+				nameIndex = i
+			}
+		}
+		if nameIndex < 0 {
+			// Unnamed parameters such as "typename" are only constraints
+			if len(param) > 0 {
+				params = append(params, models.TypeParam{Constraint: joinItems(param)})
+			}
+			continue
+		}
+		params = append(params, models.TypeParam{
+			Name:       param[nameIndex].tok.text,
+			Constraint: joinItems(param[:nameIndex]),
+		})
+	}
+	return params
+}
@@ -0,0 +1,385 @@
+package c
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"repository-context-protocol/internal/models"
+)
+
+// tokenKind classifies lexical tokens
+type tokenKind int
+
+const (
+	tokenIdent tokenKind = iota
+	tokenNumber
+	tokenString
+	tokenChar
+	tokenPunct
+	tokenDirective
+	tokenEOF
+)
+
+// token is a single lexical token with its source lines.
+// Doc holds the comment immediately preceding the token, if any.
+// Directive tokens hold a whole preprocessor line without the leading '#'.
+type token struct {
+	kind    tokenKind
+	text    string
+	line    int
+	endLine int
+	doc     string
+}
+
+// multiCharPuncts are the operators the declaration parser needs to see as one token.
+// Everything else, including '>' runs, is emitted one character at a time so that
+// nested template arguments such as map<int, vector<int>> close correctly.
+var multiCharPuncts = []string{"...", "::", "->", "&&"}
+
+// rawStringPrefixes are the identifiers that start a C++ raw string literal
+var rawStringPrefixes = map[string]bool{"R": true, "LR": true, "uR": true, "UR": true, "u8R": true}
+
+// literalPrefixes are the identifiers that may prefix a string or character literal
+var literalPrefixes = map[string]bool{"L": true, "u": true, "U": true, "u8": true}
+
+// lexer converts C or C++ source into tokens, dropping comments and whitespace
+type lexer struct {
+	src           string
+	pos           int
+	line          int
+	atLineStart   bool
+	lastTokenLine int
+	pendingDoc    string
+	pendingDocEnd int
+	lineComment   bool
+	tokens        []token
+}
+
+// tokenize splits source into tokens terminated by an EOF token
+func tokenize(src string) ([]token, error) {
+	l := &lexer{src: src, line: 1, atLineStart: true}
+	for {
+		if err := l.skipWhitespaceAndComments(); err != nil {
+			return nil, err
+		}
+		if l.pos >= len(l.src) {
+			break
+		}
+		if err := l.lexToken(); err != nil {
+			return nil, err
+		}
+	}
+	l.tokens = append(l.tokens, token{kind: tokenEOF, line: l.line, endLine: l.line})
+	return l.tokens, nil
+}
+
+// skipWhitespaceAndComments advances past whitespace and comments, remembering the
+// comment block directly above the next declaration as its documentation
+func (l *lexer) skipWhitespaceAndComments() error {
+	for l.pos < len(l.src) {
+		c := l.src[l.pos]
+		switch {
+		case c == '\n':
+			l.line++
+			l.pos++
+			l.atLineStart = true
+		case c == ' ' || c == '\t' || c == '\r' || c == '\f' || c == '\v':
+			l.pos++
+		case c == '\\' && strings.HasPrefix(l.src[l.pos+1:], "\n"):
+			// Line splice outside a directive
+			l.pos += 2
+			l.line++
+		case strings.HasPrefix(l.src[l.pos:], "//"):
+			end := strings.IndexByte(l.src[l.pos:], '\n')
+			if end < 0 {
+				end = len(l.src) - l.pos
+			}
+			l.addComment(l.src[l.pos:l.pos+end], true)
+			l.pos += end
+		case strings.HasPrefix(l.src[l.pos:], "/*"):
+			end := strings.Index(l.src[l.pos+2:], "*/")
+			if end < 0 {
+				return syntaxErrorf(l.line, "unterminated comment")
+			}
+			comment := l.src[l.pos : l.pos+2+end+2]
+			startLine := l.line
+			l.line += strings.Count(comment, "\n")
+			l.pos += len(comment)
+			l.addCommentAt(comment, false, startLine)
+		default:
+			return nil
+		}
+	}
+	return nil
+}
+
+// addComment records a comment that starts on the current line
+func (l *lexer) addComment(comment string, isLine bool) {
+	l.addCommentAt(comment, isLine, l.line)
+}
+
+// addCommentAt records a comment as pending documentation. Comments trailing code on
+// the same line are not documentation; consecutive line comments are joined.
+func (l *lexer) addCommentAt(comment string, isLine bool, startLine int) {
+	if startLine == l.lastTokenLine && len(l.tokens) > 0 {
+		return
+	}
+
+	text := commentText(comment)
+	if isLine && l.lineComment && l.pendingDoc != "" && l.pendingDocEnd == startLine-1 {
+		l.pendingDoc += "\n" + text
+	} else {
+		l.pendingDoc = text
+	}
+	l.pendingDocEnd = l.line
+	l.lineComment = isLine
+}
+
+// takeDoc returns the pending documentation if it ends directly above line
+func (l *lexer) takeDoc(line int) string {
+	doc := ""
+	if l.pendingDoc != "" && l.pendingDocEnd >= line-1 {
+		doc = l.pendingDoc
+	}
+	l.pendingDoc = ""
+	return doc
+}
+
+// lexToken reads one token at the current position
+func (l *lexer) lexToken() error {
+	start := l.pos
+	line := l.line
+	c := l.src[l.pos]
+
+	if c == '#' && l.atLineStart {
+		return l.lexDirective()
+	}
+
+	var kind tokenKind
+	switch {
+	case c == '"' || c == '\'':
+		if err := l.skipQuoted(c); err != nil {
+			return err
+		}
+		kind = literalKind(c)
+	case isDigit(c) || (c == '.' && l.pos+1 < len(l.src) && isDigit(l.src[l.pos+1])):
+		l.skipNumber()
+		kind = tokenNumber
+	case isIdentStart(l.src[l.pos:]):
+		l.skipIdent()
+		kind = tokenIdent
+		prefix := l.src[start:l.pos]
+		switch {
+		case rawStringPrefixes[prefix] && strings.HasPrefix(l.src[l.pos:], `"`):
+			if err := l.skipRawString(); err != nil {
+				return err
+			}
+			kind = tokenString
+		case literalPrefixes[prefix] && l.pos < len(l.src) && (l.src[l.pos] == '"' || l.src[l.pos] == '\''):
+			quote := l.src[l.pos]
+			if err := l.skipQuoted(quote); err != nil {
+				return err
+			}
+			kind = literalKind(quote)
+		}
+	default:
+		kind = tokenPunct
+		l.pos++
+		for _, punct := range multiCharPuncts {
+			if strings.HasPrefix(l.src[start:], punct) {
+				l.pos = start + len(punct)
+				break
+			}
+		}
+	}
+
+	l.emit(token{kind: kind, text: l.src[start:l.pos], line: line, endLine: l.line})
+	return nil
+}
+
+// emit appends a token, attaching pending documentation
+func (l *lexer) emit(tok token) {
+	tok.doc = l.takeDoc(tok.line)
+	l.tokens = append(l.tokens, tok)
+	l.lastTokenLine = tok.endLine
+	l.atLineStart = false
+}
+
+// lexDirective reads a preprocessor line, following line splices and dropping comments
+func (l *lexer) lexDirective() error {
+	line := l.line
+	l.pos++ // #
+
+	var builder strings.Builder
+	for l.pos < len(l.src) {
+		c := l.src[l.pos]
+		switch {
+		case c == '\n':
+			l.emitDirective(builder.String(), line)
+			return nil
+		case c == '\\' && (strings.HasPrefix(l.src[l.pos+1:], "\n") || strings.HasPrefix(l.src[l.pos+1:], "\r\n")):
+			l.pos += strings.IndexByte(l.src[l.pos:], '\n') + 1
+			l.line++
+			builder.WriteByte(' ')
+		case strings.HasPrefix(l.src[l.pos:], "//"):
+			end := strings.IndexByte(l.src[l.pos:], '\n')
+			if end < 0 {
+				end = len(l.src) - l.pos
+			}
+			l.pos += end
+		case strings.HasPrefix(l.src[l.pos:], "/*"):
+			end := strings.Index(l.src[l.pos+2:], "*/")
+			if end < 0 {
+				return syntaxErrorf(l.line, "unterminated comment")
+			}
+			comment := l.src[l.pos : l.pos+2+end+2]
+			l.line += strings.Count(comment, "\n")
+			l.pos += len(comment)
+			builder.WriteByte(' ')
+		case c == '"' || c == '\'':
+			start := l.pos
+			if err := l.skipQuoted(c); err != nil {
+				return err
+			}
+			builder.WriteString(l.src[start:l.pos])
+		default:
+			builder.WriteByte(c)
+			l.pos++
+		}
+	}
+	l.emitDirective(builder.String(), line)
+	return nil
+}
+
+// emitDirective appends a directive token spanning from line to the current line
+func (l *lexer) emitDirective(text string, line int) {
+	l.emit(token{kind: tokenDirective, text: strings.TrimSpace(text), line: line, endLine: l.line})
+	l.atLineStart = true
+}
+
+// skipQuoted advances past a string or character literal
+func (l *lexer) skipQuoted(quote byte) error {
+	l.pos++
+	for l.pos < len(l.src) {
+		switch l.src[l.pos] {
+		case '\\':
+			if strings.HasPrefix(l.src[l.pos+1:], "\n") {
+				l.line++
+			}
+			l.pos += 2
+		case '\n':
+			return syntaxErrorf(l.line, "unterminated literal")
+		case quote:
+			l.pos++
+			return nil
+		default:
+			l.pos++
+		}
+	}
+	return syntaxErrorf(l.line, "unterminated literal")
+}
+
+// skipRawString advances past a raw string literal body such as "delim(...)delim"
+func (l *lexer) skipRawString() error {
+	startLine := l.line
+	open := strings.IndexByte(l.src[l.pos:], '(')
+	if open < 0 {
+		return syntaxErrorf(startLine, "malformed raw string literal")
+	}
+	delimiter := l.src[l.pos+1 : l.pos+open]
+	terminator := ")" + delimiter + `"`
+
+	end := strings.Index(l.src[l.pos+open:], terminator)
+	if end < 0 {
+		return syntaxErrorf(startLine, "unterminated raw string literal")
+	}
+	literal := l.src[l.pos : l.pos+open+end+len(terminator)]
+	l.line += strings.Count(literal, "\n")
+	l.pos += len(literal)
+	return nil
+}
+
+// skipNumber advances past a numeric literal, including exponents, suffixes and
+// digit separators
+func (l *lexer) skipNumber() {
+	isHex := strings.HasPrefix(strings.ToLower(l.src[l.pos:]), "0x")
+	for l.pos < len(l.src) {
+		c := l.src[l.pos]
+		prev := byte(0)
+		if l.pos > 0 {
+			prev = l.src[l.pos-1] | 0x20 // lower-case ASCII letters
+		}
+		switch {
+		case isDigit(c) || isLetter(c) || c == '_' || c == '.':
+			l.pos++
+		case c == '\'' && l.pos+1 < len(l.src) && (isDigit(l.src[l.pos+1]) || isLetter(l.src[l.pos+1])):
+			l.pos++
+		case (c == '+' || c == '-') && ((!isHex && prev == 'e') || (isHex && prev == 'p')):
+			l.pos++
+		default:
+			return
+		}
+	}
+}
+
+// skipIdent advances past an identifier or keyword
+func (l *lexer) skipIdent() {
+	for l.pos < len(l.src) {
+		r, size := utf8.DecodeRuneInString(l.src[l.pos:])
+		if r != '_' && r != '$' && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			return
+		}
+		l.pos += size
+	}
+}
+
+// literalKind returns the token kind for a literal opened by quote
+func literalKind(quote byte) tokenKind {
+	if quote == '\'' {
+		return tokenChar
+	}
+	return tokenString
+}
+
+// isIdentStart reports whether s begins with an identifier start character
+func isIdentStart(s string) bool {
+	r, _ := utf8.DecodeRuneInString(s)
+	return r == '_' || r == '$' || unicode.IsLetter(r)
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isLetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// commentText strips comment markers and leading asterisks from a comment
+func commentText(comment string) string {
+	if strings.HasPrefix(comment, "//") {
+		return strings.TrimSpace(strings.TrimLeft(comment, "/!"))
+	}
+
+	body := strings.TrimSuffix(strings.TrimLeft(strings.TrimPrefix(comment, "/*"), "*!"), "*/")
+	lines := strings.Split(body, "\n")
+	for i, line := range lines {
+		line = strings.TrimSpace(line)
+		line = strings.TrimPrefix(line, "*")
+		lines[i] = strings.TrimPrefix(line, " ")
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// syntaxErrorf formats a syntax error at a source line.
+// ParseFile fills in the file path.
+func syntaxErrorf(line int, format string, args ...interface{}) error {
+	return &models.ParseError{Line: line, Kind: models.ParseErrorSyntax, Message: fmt.Sprintf(format, args...)}
+}
+
+// unsupportedErrorf formats an error for source the parser deliberately does not handle
+func unsupportedErrorf(line int, format string, args ...interface{}) error {
+	return &models.ParseError{Line: line, Kind: models.ParseErrorUnsupported, Message: fmt.Sprintf(format, args...)}
+}
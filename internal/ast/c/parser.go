@@ -0,0 +1,1351 @@
+// Package c parses C and C++ source files.
+//
+// The parser is a pragmatic declaration parser that does not run the preprocessor
+// or depend on libclang. It indexes function definitions and prototypes, structs,
+// unions, classes, enums, typedefs, global variables, #include directives and
+// #define macros, and maps C++ class methods to their class.
+//
+// Known limitations:
+//   - Macros are recorded but never expanded, so declarations generated by macros
+//     are not indexed and macro-wrapped declarations are parsed as written.
+//   - Every branch of a conditional compilation block is parsed, except branches
+//     disabled with a literal "#if 0". Conditionals that leave braces unbalanced
+//     are reported as a models.ParseError of kind unsupported.
+//   - Template declarations are indexed by name with their template parameters;
+//     explicit specializations are indexed as separate declarations.
+//   - K&R style function definitions are not recognised.
+package c
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"repository-context-protocol/internal/models"
+)
+
+const (
+	languageC   = "c"
+	languageCpp = "cpp"
+
+	kindStruct    = "struct"
+	kindClass     = "class"
+	kindEnum      = "enum"
+	kindAlias     = "alias"
+	kindBasic     = "basic"
+	kindComposite = "composite"
+	kindNamed     = "named"
+
+	typeVoid  = "void"
+	typeMacro = "macro"
+)
+
+// cExtensions and cppExtensions are the handled file extensions per language
+var (
+	cExtensions   = []string{".c", ".h"}
+	cppExtensions = []string{".cpp", ".hpp"}
+)
+
+// headerExtensions are the extensions whose declarations are visible to other files
+var headerExtensions = []string{".h", ".hpp"}
+
+// storageSpecifiers are the declaration specifiers that are not part of a type
+var storageSpecifiers = map[string]bool{
+	"static": true, "extern": true, "inline": true, "__inline": true, "__inline__": true,
+	"virtual": true, "explicit": true, "constexpr": true, "consteval": true, "constinit": true,
+	"friend": true, "register": true, "thread_local": true, "_Thread_local": true,
+	"_Noreturn": true, "mutable": true, "__forceinline": true,
+}
+
+// builtinTypes are the fundamental type keywords
+var builtinTypes = map[string]bool{
+	"void": true, "char": true, "short": true, "int": true, "long": true, "float": true,
+	"double": true, "signed": true, "unsigned": true, "bool": true, "_Bool": true,
+	"wchar_t": true, "char8_t": true, "char16_t": true, "char32_t": true, "auto": true,
+	"_Complex": true,
+}
+
+// nonNameKeywords are keywords that may be followed by '(' without naming a declaration
+var nonNameKeywords = map[string]bool{
+	"if": true, "for": true, "while": true, "switch": true, "return": true, "sizeof": true,
+	"alignof": true, "_Alignof": true, "decltype": true, "typeid": true, "static_assert": true,
+	"_Static_assert": true, "catch": true, "do": true, "else": true, "case": true, "new": true,
+	"delete": true, "throw": true, "defined": true, "noexcept": true, "co_await": true,
+	"co_return": true, "co_yield": true, "goto": true, "typeof": true, "__typeof__": true,
+	"const": true, "volatile": true, "struct": true, "union": true, "enum": true, "class": true,
+	"typename": true, "template": true, "requires": true, "this": true,
+}
+
+// attributeKeywords introduce compiler attributes followed by a parenthesised group
+var attributeKeywords = map[string]bool{
+	"__attribute__": true, "__attribute": true, "__declspec": true, "alignas": true,
+	"_Alignas": true, "__asm__": true, "__asm": true, "asm": true,
+}
+
+// functionSuffixes are the tokens that may follow a function's parameter list
+var functionSuffixes = map[string]bool{
+	"const": true, "volatile": true, "noexcept": true, "override": true, "final": true,
+	"throw": true, "->": true, "=": true, ":": true, "&": true, "&&": true, "try": true,
+	"requires": true,
+}
+
+// accessSpecifiers are the C++ member access labels
+var accessSpecifiers = map[string]bool{"public": true, "private": true, "protected": true}
+
+// CParser implements the LanguageParser interface for C and C++ files.
+// See the package documentation for the constructs it does not handle.
+type CParser struct{}
+
+// NewCParser creates a new C and C++ parser instance
+func NewCParser() *CParser {
+	return &CParser{}
+}
+
+// GetSupportedExtensions returns the file extensions supported by this parser
+func (p *CParser) GetSupportedExtensions() []string {
+	return append(slices.Clone(cExtensions), cppExtensions...)
+}
+
+// GetLanguageName returns the name of the language this parser handles
+func (p *CParser) GetLanguageName() string {
+	return languageC
+}
+
+// ParseFile parses a C or C++ file and returns a FileContext
+func (p *CParser) ParseFile(path string, content []byte) (*models.FileContext, error) {
+	tokens, err := tokenize(string(content))
+	if err != nil {
+		return nil, withPath(err, path)
+	}
+
+	// Calculate checksum of content
+	hash := sha256.Sum256(content)
+	checksum := fmt.Sprintf("%x", hash)
+
+	// Get modification time
+	var modTime time.Time
+	if fileInfo, err := os.Stat(path); err == nil {
+		modTime = fileInfo.ModTime()
+	} else {
+		// If file doesn't exist (e.g., in-memory parsing), use current time
+		modTime = time.Now()
+	}
+
+	ext := strings.ToLower(filepath.Ext(path))
+	language := languageC
+	if slices.Contains(cppExtensions, ext) {
+		language = languageCpp
+	}
+
+	ctx := &models.FileContext{
+		Path:      path,
+		Language:  language,
+		Checksum:  checksum,
+		ModTime:   modTime,
+		Functions: []models.Function{},
+		Types:     []models.TypeDef{},
+		Variables: []models.Variable{},
+		Constants: []models.Constant{},
+		Imports:   []models.Import{},
+		Exports:   []models.Export{},
+	}
+
+	fp := &fileParser{ctx: ctx, isHeader: slices.Contains(headerExtensions, ext), prototypes: make(map[int]bool)}
+	if err := fp.preprocess(tokens); err != nil {
+		return nil, withPath(err, path)
+	}
+	if err := fp.parseBlock(false); err != nil {
+		return nil, withPath(err, path)
+	}
+	fp.finish()
+
+	// Build call graph relationships (second pass)
+	p.buildCallGraph(ctx)
+
+	return ctx, nil
+}
+
+// buildCallGraph builds the CalledBy relationships between functions in the file
+func (p *CParser) buildCallGraph(ctx *models.FileContext) {
+	funcMap := make(map[string]int)
+	for i := range ctx.Functions {
+		funcMap[ctx.Functions[i].Name] = i
+	}
+
+	for i := range ctx.Functions {
+		caller := &ctx.Functions[i]
+
+		// Unqualified calls resolve to functions in the same file
+		for _, calledName := range caller.LocalCalls {
+			if targetIdx, exists := funcMap[calledName]; exists {
+				addUnique(&ctx.Functions[targetIdx].CalledBy, caller.Name)
+				addUnique(&ctx.Functions[targetIdx].LocalCallers, caller.Name)
+			}
+		}
+
+		// Member calls like "conn.send" are linked by member name for the deprecated field
+		for _, calledName := range caller.Calls {
+			if idx := strings.LastIndex(calledName, "."); idx >= 0 {
+				if targetIdx, exists := funcMap[calledName[idx+1:]]; exists {
+					addUnique(&ctx.Functions[targetIdx].CalledBy, caller.Name)
+				}
+			}
+		}
+	}
+}
+
+// addUnique appends value to list if it is not already present
+func addUnique(list *[]string, value string) {
+	if !slices.Contains(*list, value) {
+		*list = append(*list, value)
+	}
+}
+
+// withPath attaches the file path to a parse error
+func withPath(err error, path string) error {
+	var parseErr *models.ParseError
+	if errors.As(err, &parseErr) {
+		parseErr.File = path
+		return parseErr
+	}
+	return fmt.Errorf("%s: %w", path, err)
+}
+
+// fileParser holds the parsing state for a single translation unit
+type fileParser struct {
+	tokens          []token
+	pos             int
+	ctx             *models.FileContext
+	isHeader        bool
+	hasConditionals bool
+	prototypes      map[int]bool // Indexes of Functions that are declarations without a body
+}
+
+// item is one element of a declaration: a single token or a bracketed group
+type item struct {
+	tok   token   // The token, or the opening bracket of a group
+	group []token // The whole group including its brackets, nil for single tokens
+}
+
+// is reports whether the item is the single punctuation or keyword token text
+func (it item) is(text string) bool {
+	return it.group == nil && it.tok.kind != tokenString && it.tok.kind != tokenChar && it.tok.text == text
+}
+
+// isGroup reports whether the item is a group opened by the bracket
+func (it item) isGroup(open string) bool {
+	return it.group != nil && it.tok.text == open
+}
+
+// isIdent reports whether the item is a single identifier
+func (it item) isIdent() bool {
+	return it.group == nil && it.tok.kind == tokenIdent
+}
+
+// tokens returns the tokens the item covers
+func (it item) tokens() []token {
+	if it.group != nil {
+		return it.group
+	}
+	return []token{it.tok}
+}
+
+// inner returns the tokens of a group without its brackets
+func (it item) inner() []token {
+	if len(it.group) < 2 {
+		return nil
+	}
+	return it.group[1 : len(it.group)-1]
+}
+
+// scope is the class or struct whose members are being parsed
+type scope struct {
+	typeDef *models.TypeDef
+	access  string
+}
+
+// declaration is a declaration collected up to its terminating ';' or function body
+type declaration struct {
+	start       token
+	typeParams  []models.TypeParam
+	items       []item
+	compound    *compound // Struct, union, class or enum defined inside the declaration
+	compoundPos int       // Index in items where the declarators after the compound start
+	body        []token   // Function body including braces, nil for declarations
+	endLine     int
+}
+
+// compound records a struct, union, class or enum definition within a declaration
+type compound struct {
+	typeIndex   int   // Slot in ctx.Types
+	enumerators []int // Indexes in ctx.Constants of the enumerators
+}
+
+// preprocess handles directives, recording includes and macros, and keeps the code
+// tokens outside "#if 0" blocks for the declaration parser
+func (fp *fileParser) preprocess(tokens []token) error {
+	type conditional struct {
+		line           int
+		parentDisabled bool
+		branchDisabled bool
+		decided        bool // A branch with a literal true condition was already seen
+	}
+
+	var stack []conditional
+	disabled := func() bool {
+		if len(stack) == 0 {
+			return false
+		}
+		top := stack[len(stack)-1]
+		return top.parentDisabled || top.branchDisabled
+	}
+
+	for _, tok := range tokens {
+		if tok.kind != tokenDirective {
+			if tok.kind == tokenEOF || !disabled() {
+				fp.tokens = append(fp.tokens, tok)
+			}
+			continue
+		}
+
+		name, rest := splitDirective(tok.text)
+		switch name {
+		case "if", "ifdef", "ifndef":
+			fp.hasConditionals = true
+			value := -1
+			if name == "if" {
+				value = literalCondition(rest)
+			}
+			stack = append(stack, conditional{
+				line:           tok.line,
+				parentDisabled: disabled(),
+				branchDisabled: value == 0,
+				decided:        value == 1,
+			})
+		case "elif", "elifdef", "elifndef", "else":
+			if len(stack) == 0 {
+				return syntaxErrorf(tok.line, "#%s without #if", name)
+			}
+			top := &stack[len(stack)-1]
+			value := -1
+			if name == "elif" {
+				value = literalCondition(rest)
+			}
+			top.branchDisabled = top.decided || value == 0
+			top.decided = top.decided || value == 1
+		case "endif":
+			if len(stack) == 0 {
+				return syntaxErrorf(tok.line, "#endif without #if")
+			}
+			stack = stack[:len(stack)-1]
+		case "include", "include_next", "import":
+			if !disabled() {
+				fp.parseInclude(rest)
+			}
+		case "define":
+			if !disabled() {
+				fp.parseMacro(tok, rest)
+			}
+		}
+	}
+
+	if len(stack) > 0 {
+		return syntaxErrorf(stack[len(stack)-1].line, "unterminated #if")
+	}
+	return nil
+}
+
+// splitDirective splits a directive into its name and the remaining text
+func splitDirective(text string) (string, string) {
+	end := strings.IndexFunc(text, func(r rune) bool {
+		return !(r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9'))
+	})
+	if end < 0 {
+		return text, ""
+	}
+	return text[:end], strings.TrimSpace(text[end:])
+}
+
+// literalCondition evaluates "#if 0" and "#if 1", returning -1 for anything else
+func literalCondition(condition string) int {
+	switch strings.TrimSpace(condition) {
+	case "0", "false":
+		return 0
+	case "1", "true":
+		return 1
+	}
+	return -1
+}
+
+// parseInclude records the path of an #include directive. Includes of macro names
+// cannot be resolved without the preprocessor and are skipped.
+func (fp *fileParser) parseInclude(rest string) {
+	if len(rest) < 2 {
+		return
+	}
+	closing := map[byte]byte{'<': '>', '"': '"'}[rest[0]]
+	if closing == 0 {
+		return
+	}
+	if end := strings.IndexByte(rest[1:], closing); end >= 0 {
+		fp.ctx.Imports = append(fp.ctx.Imports, models.Import{Path: rest[1 : end+1]})
+	}
+}
+
+// parseMacro records a #define. Object-like macros become constants and function-like
+// macros become functions. Empty macros are configuration flags or include guards and
+// are not indexed.
+func (fp *fileParser) parseMacro(tok token, rest string) {
+	name, definition := splitDirective(rest)
+	if name == "" {
+		return
+	}
+
+	// A '(' directly after the name makes a function-like macro
+	if !strings.HasPrefix(rest[len(name):], "(") {
+		if definition == "" {
+			return
+		}
+		fp.ctx.Constants = append(fp.ctx.Constants, models.Constant{
+			Name:      name,
+			Type:      typeMacro,
+			Value:     definition,
+			StartLine: tok.line,
+			EndLine:   tok.endLine,
+			Doc:       tok.doc,
+			Scope:     fp.declarationScope(),
+		})
+		if fp.isHeader {
+			fp.addExport(name, typeMacro, "constant")
+		}
+		return
+	}
+
+	end := strings.IndexByte(definition, ')')
+	if end < 0 {
+		return
+	}
+	parameters := []models.Parameter{}
+	var names []string
+	for _, param := range strings.Split(definition[1:end], ",") {
+		if param = strings.TrimSpace(param); param != "" {
+			parameters = append(parameters, models.Parameter{Name: param})
+			names = append(names, param)
+		}
+	}
+
+	fn := newFunction(name, fmt.Sprintf("#define %s(%s)", name, strings.Join(names, ", ")))
+	fn.Parameters = parameters
+	fn.StartLine = tok.line
+	fn.EndLine = tok.endLine
+	fn.Doc = tok.doc
+
+	// Macro bodies are scanned for calls like function bodies; malformed bodies have none
+	if body, err := tokenize(definition[end+1:]); err == nil {
+		fp.populateCalls(append([]token{{kind: tokenPunct, text: "{"}}, body...), &fn)
+	} else {
+		fp.populateCalls(nil, &fn)
+	}
+
+	fp.ctx.Functions = append(fp.ctx.Functions, fn)
+	if fp.isHeader {
+		fp.addExport(name, fn.Signature, "function")
+	}
+}
+
+// finish drops prototypes of functions defined in the same file and anonymous types
+func (fp *fileParser) finish() {
+	defined := make(map[string]bool)
+	for i := range fp.ctx.Functions {
+		if !fp.prototypes[i] {
+			defined[fp.ctx.Functions[i].Receiver+"::"+fp.ctx.Functions[i].Name] = true
+		}
+	}
+
+	functions := []models.Function{}
+	for i := range fp.ctx.Functions {
+		fn := &fp.ctx.Functions[i]
+		if fp.prototypes[i] && defined[fn.Receiver+"::"+fn.Name] {
+			continue
+		}
+		functions = append(functions, *fn)
+	}
+	fp.ctx.Functions = functions
+
+	fp.ctx.Types = slices.DeleteFunc(fp.ctx.Types, func(typeDef models.TypeDef) bool {
+		return typeDef.Name == ""
+	})
+}
+
+// addExport records an exported symbol once
+func (fp *fileParser) addExport(name, exportType, kind string) {
+	if slices.ContainsFunc(fp.ctx.Exports, func(export models.Export) bool {
+		return export.Name == name && export.Kind == kind
+	}) {
+		return
+	}
+	fp.ctx.Exports = append(fp.ctx.Exports, models.Export{Name: name, Type: exportType, Kind: kind})
+}
+
+// peek returns the token offset positions ahead without consuming it
+func (fp *fileParser) peek(offset int) token {
+	if fp.pos+offset >= len(fp.tokens) {
+		return fp.tokens[len(fp.tokens)-1]
+	}
+	return fp.tokens[fp.pos+offset]
+}
+
+// next consumes and returns the current token
+func (fp *fileParser) next() token {
+	tok := fp.peek(0)
+	if tok.kind != tokenEOF {
+		fp.pos++
+	}
+	return tok
+}
+
+// at reports whether the current token is the punctuation or keyword text
+func (fp *fileParser) at(text string) bool {
+	tok := fp.peek(0)
+	return tok.kind != tokenEOF && tok.kind != tokenString && tok.kind != tokenChar && tok.text == text
+}
+
+// expect consumes the token text or returns a syntax error
+func (fp *fileParser) expect(text string) (token, error) {
+	if !fp.at(text) {
+		return token{}, fp.unexpected(fmt.Sprintf("expected '%s'", text))
+	}
+	return fp.next(), nil
+}
+
+// unexpected builds an error describing the current token. Brace mismatches in files
+// with conditional compilation are reported as unsupported rather than as syntax errors.
+func (fp *fileParser) unexpected(reason string) error {
+	tok := fp.peek(0)
+	if fp.hasConditionals && (tok.kind == tokenEOF || tok.text == "}") {
+		return unsupportedErrorf(tok.line, "%s; braces are unbalanced, likely across preprocessor conditionals", reason)
+	}
+	if tok.kind == tokenEOF {
+		return syntaxErrorf(tok.line, "%s, found end of file", reason)
+	}
+	return syntaxErrorf(tok.line, "%s, found '%s'", reason, tok.text)
+}
+
+// parseBlock parses declarations at file or namespace scope, up to the closing brace
+// of the enclosing block when inBlock is set
+func (fp *fileParser) parseBlock(inBlock bool) error {
+	for {
+		switch {
+		case fp.peek(0).kind == tokenEOF:
+			if inBlock {
+				return fp.unexpected("expected '}'")
+			}
+			return nil
+		case fp.at("}"):
+			if inBlock {
+				return nil
+			}
+			return fp.unexpected("expected declaration")
+		case fp.at(";"):
+			fp.next()
+		case fp.at("namespace") || (fp.at("inline") && fp.peek(1).text == "namespace"):
+			if err := fp.parseNamespace(); err != nil {
+				return err
+			}
+		case fp.at("extern") && fp.peek(1).kind == tokenString && fp.peek(2).text == "{":
+			// extern "C" { ... }
+			fp.pos += 3
+			if err := fp.parseBlock(true); err != nil {
+				return err
+			}
+			if _, err := fp.expect("}"); err != nil {
+				return err
+			}
+		case fp.at("using") || fp.at("static_assert") || fp.at("_Static_assert"):
+			if err := fp.parseUsing(nil); err != nil {
+				return err
+			}
+		default:
+			if err := fp.parseDeclaration(nil); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// parseNamespace parses a namespace definition or skips a namespace alias
+func (fp *fileParser) parseNamespace() error {
+	if fp.at("inline") {
+		fp.next()
+	}
+	fp.next() // namespace
+	for !fp.at("{") {
+		if fp.at(";") || fp.at("=") || fp.peek(0).kind == tokenEOF {
+			// namespace alias: namespace fs = std::filesystem;
+			return fp.skipStatement()
+		}
+		fp.next()
+	}
+	fp.next() // {
+	if err := fp.parseBlock(true); err != nil {
+		return err
+	}
+	_, err := fp.expect("}")
+	return err
+}
+
+// parseUsing records "using Name = Type;" aliases and skips other using declarations
+func (fp *fileParser) parseUsing(owner *scope) error {
+	start := fp.peek(0)
+	if start.text == "using" && fp.peek(1).kind == tokenIdent && fp.peek(2).text == "=" {
+		name := fp.peek(1)
+		if err := fp.skipStatement(); err != nil {
+			return err
+		}
+		fp.addTypeDef(models.TypeDef{
+			Name:      name.text,
+			Kind:      kindAlias,
+			StartLine: start.line,
+			EndLine:   fp.tokens[fp.pos-1].line,
+			Doc:       start.doc,
+		}, owner)
+		return nil
+	}
+	return fp.skipStatement()
+}
+
+// skipStatement consumes tokens through the next ';' outside brackets
+func (fp *fileParser) skipStatement() error {
+	for !fp.at(";") {
+		if fp.peek(0).kind == tokenEOF || fp.at("}") {
+			return fp.unexpected("expected ';'")
+		}
+		if _, err := fp.skipBalancedOrNext(); err != nil {
+			return err
+		}
+	}
+	fp.next()
+	return nil
+}
+
+// addTypeDef records a type and exports it when declared at file scope of a header
+func (fp *fileParser) addTypeDef(typeDef models.TypeDef, owner *scope) {
+	fp.ctx.Types = append(fp.ctx.Types, typeDef)
+	if owner == nil && fp.isHeader {
+		fp.addExport(typeDef.Name, typeDef.Kind, "type")
+	}
+}
+
+// parseDeclaration parses one declaration and records what it declares
+func (fp *fileParser) parseDeclaration(owner *scope) error {
+	decl, err := fp.collectDeclaration(owner)
+	if err != nil {
+		return err
+	}
+	fp.recordDeclaration(decl, owner)
+	return nil
+}
+
+// collectDeclaration gathers a declaration's items up to its ';' or function body.
+// Struct, union, class and enum bodies inside it are parsed as they are reached.
+func (fp *fileParser) collectDeclaration(owner *scope) (*declaration, error) {
+	decl := &declaration{start: fp.peek(0)}
+
+	if fp.at("template") && fp.peek(1).text == "<" {
+		fp.next()
+		params, err := fp.skipAngles()
+		if err != nil {
+			return nil, err
+		}
+		decl.typeParams = parseTemplateParams(params)
+	}
+
+	for {
+		switch {
+		case fp.peek(0).kind == tokenEOF || fp.at("}"):
+			return nil, fp.unexpected("expected ';'")
+		case fp.at(";"):
+			decl.endLine = fp.next().line
+			return decl, nil
+		case fp.at("(") || fp.at("["):
+			group, err := fp.skipBalancedOrNext()
+			if err != nil {
+				return nil, err
+			}
+			decl.items = append(decl.items, item{tok: group[0], group: group})
+		case fp.at("{"):
+			done, err := fp.collectBrace(decl, owner)
+			if err != nil || done {
+				return decl, err
+			}
+		default:
+			decl.items = append(decl.items, item{tok: fp.next()})
+		}
+	}
+}
+
+// collectBrace handles a '{' inside a declaration: an initializer, a function body
+// or a struct, union, class or enum body. It reports whether the declaration ended.
+func (fp *fileParser) collectBrace(decl *declaration, owner *scope) (bool, error) {
+	items := decl.items
+	if len(items) == 0 {
+		return false, unsupportedErrorf(fp.peek(0).line, "block without a declarator; K&R style function definitions are not supported")
+	}
+	functionGroup := -1
+	if decl.compound == nil && !hasAssignment(items, len(items)) {
+		functionGroup = findFunctionGroup(items, true)
+	}
+
+	switch {
+	case functionGroup >= 0:
+		// Braces inside a constructor initializer list initialize members
+		if hasInitializerList(items, functionGroup) && (items[len(items)-1].isIdent() || items[len(items)-1].is(">")) {
+			break
+		}
+		body, err := fp.skipBalanced("{", "}")
+		if err != nil {
+			return false, err
+		}
+		decl.body = body
+		decl.endLine = body[len(body)-1].line
+		return true, nil
+	case decl.compound == nil && !hasAssignment(items, len(items)) && classKeyIndex(items) >= 0:
+		return false, fp.parseCompound(decl, owner, classKeyIndex(items))
+	}
+
+	group, err := fp.skipBalanced("{", "}")
+	if err != nil {
+		return false, err
+	}
+	decl.items = append(decl.items, item{tok: group[0], group: group})
+	return false, nil
+}
+
+// parseCompound parses the body of a struct, union, class or enum definition
+func (fp *fileParser) parseCompound(decl *declaration, owner *scope, keyIndex int) error {
+	keyword := decl.items[keyIndex].tok.text
+	name, bases := compoundHeader(decl.items[keyIndex+1:], keyword)
+
+	typeDef := models.TypeDef{
+		Name:       name,
+		Kind:       compoundKind(keyword),
+		StartLine:  decl.start.line,
+		Doc:        decl.start.doc,
+		Embedded:   bases,
+		TypeParams: decl.typeParams,
+		Decorators: attributeDecorators(decl.items),
+	}
+
+	// Reserve the slot so outer types precede their nested types
+	info := &compound{typeIndex: len(fp.ctx.Types)}
+	fp.ctx.Types = append(fp.ctx.Types, models.TypeDef{})
+
+	fp.next() // {
+	if keyword == kindEnum {
+		info.enumerators = fp.parseEnumBody(&typeDef)
+	} else if err := fp.parseMemberBody(&typeDef, keyword == kindClass); err != nil {
+		return err
+	}
+	closing, err := fp.expect("}")
+	if err != nil {
+		return err
+	}
+	typeDef.EndLine = closing.line
+
+	fp.ctx.Types[info.typeIndex] = typeDef
+	decl.compound = info
+	decl.compoundPos = len(decl.items)
+	return nil
+}
+
+// compoundKind maps a class key to the TypeDef kind. Unions are recorded as structs.
+func compoundKind(keyword string) string {
+	switch keyword {
+	case kindClass:
+		return kindClass
+	case kindEnum:
+		return kindEnum
+	default:
+		return kindStruct
+	}
+}
+
+// compoundHeader extracts the name and base classes from the items after a class key
+func compoundHeader(items []item, keyword string) (string, []string) {
+	items = stripAttributes(items)
+	if keyword == kindEnum && len(items) > 0 && (items[0].is("class") || items[0].is("struct")) {
+		items = items[1:]
+	}
+
+	colon := slices.IndexFunc(items, func(it item) bool { return it.is(":") })
+	nameItems := items
+	if colon >= 0 {
+		nameItems = items[:colon]
+	}
+
+	// The name is the last identifier, skipping template arguments and export macros before it
+	name := ""
+	for _, it := range stripAngles(nameItems) {
+		if it.isIdent() && !it.is("final") {
+			name = it.tok.text
+		}
+	}
+
+	var bases []string
+	if colon >= 0 && keyword != kindEnum {
+		for _, base := range splitItems(items[colon+1:]) {
+			base = slices.DeleteFunc(base, func(it item) bool {
+				return it.isIdent() && (accessSpecifiers[it.tok.text] || it.tok.text == "virtual")
+			})
+			if len(base) > 0 {
+				bases = append(bases, joinItems(base))
+			}
+		}
+	}
+	return name, bases
+}
+
+// parseEnumBody parses enumerators, recording them as fields and constants.
+// It returns the indexes of the constants so typedef names can be applied later.
+func (fp *fileParser) parseEnumBody(typeDef *models.TypeDef) []int {
+	var indexes []int
+	for fp.peek(0).kind == tokenIdent {
+		name := fp.next()
+		var value []token
+		for !fp.at(",") && !fp.at("}") && fp.peek(0).kind != tokenEOF {
+			tokens, err := fp.skipBalancedOrNext()
+			if err != nil {
+				break
+			}
+			value = append(value, tokens...)
+		}
+		if len(value) > 0 && value[0].text == "=" {
+			value = value[1:]
+		}
+
+		typeDef.Fields = append(typeDef.Fields, models.Field{Name: name.text, Type: typeDef.Name})
+		indexes = append(indexes, len(fp.ctx.Constants))
+		fp.ctx.Constants = append(fp.ctx.Constants, models.Constant{
+			Name:      name.text,
+			Type:      typeDef.Name,
+			Value:     literalValue(value),
+			StartLine: name.line,
+			EndLine:   name.line,
+			Doc:       name.doc,
+			Scope:     fp.declarationScope(),
+		})
+
+		if !fp.at(",") {
+			break
+		}
+		fp.next()
+	}
+	return indexes
+}
+
+// parseMemberBody parses the members of a struct, union or class until its closing brace
+func (fp *fileParser) parseMemberBody(typeDef *models.TypeDef, isClass bool) error {
+	owner := &scope{typeDef: typeDef, access: "public"}
+	if isClass {
+		owner.access = "private"
+	}
+
+	for !fp.at("}") {
+		switch {
+		case fp.peek(0).kind == tokenEOF:
+			return fp.unexpected("expected '}'")
+		case fp.at(";"):
+			fp.next()
+		case accessSpecifiers[fp.peek(0).text] && fp.peek(1).text == ":":
+			owner.access = fp.next().text
+			fp.next()
+		case fp.peek(0).kind == tokenIdent && fp.peek(1).text == ":" && fp.peek(2).text != ":":
+			// Other labels such as Qt's "signals:"
+			fp.pos += 2
+		case accessSpecifiers[fp.peek(0).text] && fp.peek(1).kind == tokenIdent && fp.peek(2).text == ":":
+			// "public slots:"
+			owner.access = fp.next().text
+			fp.pos += 2
+		case fp.at("using") || fp.at("static_assert"):
+			if err := fp.parseUsing(owner); err != nil {
+				return err
+			}
+		case fp.at("friend"):
+			// Friends are declared elsewhere
+			if _, err := fp.collectDeclaration(nil); err != nil {
+				return err
+			}
+		default:
+			if err := fp.parseDeclaration(owner); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// recordDeclaration adds what a declaration declares to the file context
+func (fp *fileParser) recordDeclaration(decl *declaration, owner *scope) {
+	items, isTypedef := removeTypedef(decl.items)
+	decorators := attributeDecorators(items)
+
+	if decl.compound != nil {
+		fp.recordCompound(decl, owner, isTypedef)
+		return
+	}
+
+	items = stripAttributes(items)
+	if isTypedef {
+		for _, declarator := range parseDeclarators(items, nil) {
+			fp.addTypeDef(models.TypeDef{
+				Name:      declarator.name,
+				Kind:      kindAlias,
+				StartLine: decl.start.line,
+				EndLine:   decl.endLine,
+				Doc:       decl.start.doc,
+			}, owner)
+		}
+		return
+	}
+
+	if group := findFunctionGroup(items, decl.body != nil); group >= 0 && !hasAssignment(items, group) {
+		if fp.recordFunction(decl, owner, items, group, decorators) {
+			return
+		}
+	}
+	fp.recordVariables(decl, owner, items)
+}
+
+// recordCompound records the declarators that follow a struct, union, class or enum body
+func (fp *fileParser) recordCompound(decl *declaration, owner *scope, isTypedef bool) {
+	typeDef := &fp.ctx.Types[decl.compound.typeIndex]
+	keyIndex := classKeyIndex(decl.items)
+	base := []item{decl.items[keyIndex]}
+	if typeDef.Name != "" {
+		base = append(base, item{tok: token{kind: tokenIdent, text: typeDef.Name}})
+	}
+	declarators := parseDeclarators(stripAttributes(decl.items[decl.compoundPos:]), base)
+
+	switch {
+	case isTypedef:
+		// typedef struct tag { ... } name_t; indexes the struct as name_t when it has no tag
+		for _, declarator := range declarators {
+			if typeDef.Name == "" {
+				typeDef.Name = declarator.name
+				continue
+			}
+			fp.addTypeDef(models.TypeDef{
+				Name:      declarator.name,
+				Kind:      kindAlias,
+				StartLine: decl.start.line,
+				EndLine:   decl.endLine,
+				Doc:       decl.start.doc,
+			}, owner)
+		}
+	case typeDef.Name == "" && len(declarators) == 0 && owner != nil:
+		// Members of an anonymous struct or union belong to the enclosing type
+		owner.typeDef.Fields = append(owner.typeDef.Fields, typeDef.Fields...)
+	default:
+		isStatic := slices.ContainsFunc(decl.items[:keyIndex], func(it item) bool { return it.is("static") })
+		for _, declarator := range declarators {
+			fp.recordVariable(decl, owner, declarator.name, declarator.typeName, isStatic, false, "")
+		}
+	}
+
+	for i := range typeDef.Fields {
+		if typeDef.Kind == kindEnum && typeDef.Fields[i].Type == "" {
+			typeDef.Fields[i].Type = typeDef.Name
+		}
+	}
+	for _, index := range decl.compound.enumerators {
+		if fp.ctx.Constants[index].Type == "" {
+			fp.ctx.Constants[index].Type = typeDef.Name
+		}
+	}
+
+	if typeDef.Name != "" && owner == nil && fp.isHeader {
+		fp.addExport(typeDef.Name, typeDef.Kind, "type")
+		for _, index := range decl.compound.enumerators {
+			fp.addExport(fp.ctx.Constants[index].Name, typeDef.Name, "constant")
+		}
+	}
+}
+
+// recordFunction records a function or method declaration. It reports false when the
+// items are not a function after all, such as a macro invocation without return type.
+func (fp *fileParser) recordFunction(decl *declaration, owner *scope, items []item, group int, decorators []string) bool {
+	name, nameStart, receiver := functionName(items, group)
+	typeStart := 0
+	for i := nameStart - 1; i >= 0; i-- {
+		if items[i].isGroup("(") {
+			// Everything before a parenthesised group is a macro invocation, not the return type
+			typeStart = i + 1
+			break
+		}
+	}
+
+	var specifiers []string
+	var returnItems []item
+	for _, it := range items[typeStart:nameStart] {
+		if it.isIdent() && storageSpecifiers[it.tok.text] {
+			specifiers = append(specifiers, it.tok.text)
+			continue
+		}
+		returnItems = append(returnItems, it)
+	}
+
+	// Trailing qualifiers end where a constructor initializer list starts
+	end := group + 1
+	for end < len(items) && !items[end].is(":") {
+		end++
+	}
+	returnType := joinItems(returnItems)
+	if arrow := slices.IndexFunc(items[group+1:end], func(it item) bool { return it.is("->") }); arrow >= 0 && returnType == "auto" {
+		returnType = joinItems(items[group+2+arrow : end])
+	}
+
+	typeName := receiver
+	if owner != nil {
+		typeName = owner.typeDef.Name
+	}
+	isConstructor := typeName != "" && (name == typeName || name == "~"+typeName)
+	if returnType == "" && !isConstructor && !strings.HasPrefix(name, "operator") {
+		return false
+	}
+
+	signature := joinItems(items[typeStart:end])
+	parameters := parseParameters(items[group].inner())
+
+	if owner != nil {
+		owner.typeDef.Methods = append(owner.typeDef.Methods, models.Method{
+			Name:       name,
+			Signature:  signature,
+			Parameters: parameters,
+			Returns:    returnTypes(returnType),
+			StartLine:  decl.start.line,
+			EndLine:    decl.endLine,
+			Doc:        decl.start.doc,
+			Receiver:   owner.typeDef.Name,
+			Decorators: decorators,
+		})
+		// Only methods with bodies participate in the call graph
+		if decl.body == nil {
+			return true
+		}
+		receiver = owner.typeDef.Name
+	}
+
+	fn := newFunction(name, signature)
+	fn.Parameters = parameters
+	fn.Returns = returnTypes(returnType)
+	fn.StartLine = decl.start.line
+	fn.EndLine = decl.endLine
+	fn.Doc = decl.start.doc
+	fn.TypeParams = decl.typeParams
+	fn.Receiver = receiver
+	fn.Decorators = decorators
+	fp.populateCalls(decl.body, &fn)
+
+	if decl.body == nil {
+		fp.prototypes[len(fp.ctx.Functions)] = true
+	}
+	fp.ctx.Functions = append(fp.ctx.Functions, fn)
+
+	if owner == nil && receiver != "" {
+		fp.attachMethod(&fn)
+	}
+	if owner == nil && receiver == "" && !slices.Contains(specifiers, "static") {
+		fp.addExport(fn.Name, fn.Signature, "function")
+	}
+	return true
+}
+
+// attachMethod adds an out-of-class method definition to its class when the class is
+// declared in the same file and does not already declare the method
+func (fp *fileParser) attachMethod(fn *models.Function) {
+	for i := range fp.ctx.Types {
+		typeDef := &fp.ctx.Types[i]
+		if typeDef.Name != fn.Receiver {
+			continue
+		}
+		if slices.ContainsFunc(typeDef.Methods, func(method models.Method) bool { return method.Name == fn.Name }) {
+			return
+		}
+		typeDef.Methods = append(typeDef.Methods, models.Method{
+			Name:       fn.Name,
+			Signature:  fn.Signature,
+			Parameters: fn.Parameters,
+			Returns:    fn.Returns,
+			StartLine:  fn.StartLine,
+			EndLine:    fn.EndLine,
+			Doc:        fn.Doc,
+			Receiver:   fn.Receiver,
+			Decorators: fn.Decorators,
+		})
+		return
+	}
+}
+
+// recordVariables records the variables or fields of a declaration
+func (fp *fileParser) recordVariables(decl *declaration, owner *scope, items []item) {
+	var specifiers []string
+	items = slices.DeleteFunc(slices.Clone(items), func(it item) bool {
+		if it.isIdent() && storageSpecifiers[it.tok.text] {
+			specifiers = append(specifiers, it.tok.text)
+			return true
+		}
+		return false
+	})
+	isStatic := slices.Contains(specifiers, "static")
+	isConstexpr := slices.Contains(specifiers, "constexpr")
+
+	// Declarators without a type, such as macro invocations, are not recorded
+	for _, declarator := range parseDeclarators(items, nil) {
+		isConstant := isConstexpr || declarator.isConst
+		if owner != nil {
+			isConstant = isConstant && isStatic
+		}
+		fp.recordVariable(decl, owner, declarator.name, declarator.typeName, isStatic, isConstant, declarator.value)
+	}
+}
+
+// recordVariable records one variable, constant or field
+func (fp *fileParser) recordVariable(decl *declaration, owner *scope, name, varType string, isStatic, isConstant bool, value string) {
+	if owner != nil {
+		owner.typeDef.Fields = append(owner.typeDef.Fields, models.Field{Name: name, Type: varType})
+		if !isConstant {
+			return
+		}
+	}
+
+	// Class members and non-static globals have linkage beyond the file
+	scope := models.ScopePackage
+	if owner == nil && isStatic {
+		scope = models.ScopeFile
+	}
+
+	kind := "variable"
+	if isConstant {
+		kind = "constant"
+		fp.ctx.Constants = append(fp.ctx.Constants, models.Constant{
+			Name:      name,
+			Type:      varType,
+			Value:     value,
+			StartLine: decl.start.line,
+			EndLine:   decl.endLine,
+			Doc:       decl.start.doc,
+			Scope:     scope,
+		})
+	} else {
+		fp.ctx.Variables = append(fp.ctx.Variables, models.Variable{
+			Name:      name,
+			Type:      varType,
+			StartLine: decl.start.line,
+			EndLine:   decl.endLine,
+			Doc:       decl.start.doc,
+			Scope:     scope,
+		})
+	}
+
+	if owner == nil && !isStatic {
+		fp.addExport(name, varType, kind)
+	}
+}
+
+// declarationScope returns the scope of macros and enumerators, which are visible
+// outside the file only when declared in a header
+func (fp *fileParser) declarationScope() string {
+	if fp.isHeader {
+		return models.ScopePackage
+	}
+	return models.ScopeFile
+}
+
+// skipBalanced consumes a bracketed group and returns its tokens including the delimiters
+func (fp *fileParser) skipBalanced(open, closing string) ([]token, error) {
+	start := fp.pos
+	if _, err := fp.expect(open); err != nil {
+		return nil, err
+	}
+
+	depth := 1
+	for depth > 0 {
+		switch {
+		case fp.peek(0).kind == tokenEOF:
+			return nil, fp.unexpected(fmt.Sprintf("expected '%s'", closing))
+		case fp.at(open):
+			depth++
+		case fp.at(closing):
+			depth--
+		case fp.at(")") || fp.at("]") || fp.at("}"):
+			// A mismatched closing bracket inside a group is a syntax error
+			return nil, fp.unexpected(fmt.Sprintf("expected '%s'", closing))
+		case fp.at("(") || fp.at("[") || fp.at("{"):
+			if _, err := fp.skipBalancedOrNext(); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		fp.next()
+	}
+
+	return fp.tokens[start:fp.pos], nil
+}
+
+// skipBalancedOrNext consumes a bracketed group if one starts here, otherwise a single token
+func (fp *fileParser) skipBalancedOrNext() ([]token, error) {
+	switch {
+	case fp.at("("):
+		return fp.skipBalanced("(", ")")
+	case fp.at("["):
+		return fp.skipBalanced("[", "]")
+	case fp.at("{"):
+		return fp.skipBalanced("{", "}")
+	case fp.at(")") || fp.at("]") || fp.at("}"):
+		return nil, fp.unexpected("unbalanced bracket")
+	}
+	return []token{fp.next()}, nil
+}
+
+// skipAngles consumes a template parameter list and returns the tokens between the
+// angle brackets. Parenthesised groups inside it are consumed whole.
+func (fp *fileParser) skipAngles() ([]token, error) {
+	if _, err := fp.expect("<"); err != nil {
+		return nil, err
+	}
+	start := fp.pos
+	depth := 1
+	for {
+		switch {
+		case fp.peek(0).kind == tokenEOF:
+			return nil, fp.unexpected("expected '>'")
+		case fp.at("<"):
+			depth++
+		case fp.at(">"):
+			depth--
+			if depth == 0 {
+				params := fp.tokens[start:fp.pos]
+				fp.next()
+				return params, nil
+			}
+		case fp.at("(") || fp.at("[") || fp.at("{"):
+			if _, err := fp.skipBalancedOrNext(); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		fp.next()
+	}
+}
+
+// populateCalls scans a function body for call expressions
+func (fp *fileParser) populateCalls(body []token, fn *models.Function) {
+	seen := make(map[string]bool)
+
+	for i := 1; i+1 < len(body); i++ {
+		tok := body[i]
+		if tok.kind != tokenIdent || body[i+1].text != "(" || nonNameKeywords[tok.text] || builtinTypes[tok.text] {
+			continue
+		}
+
+		prev := body[i-1]
+		// Skip declarations with direct initialization and constructor invocations
+		if (prev.kind == tokenIdent && !nonNameKeywords[prev.text]) || prev.text == "new" ||
+			prev.text == "~" || prev.text == ">" || prev.text == "*" && i >= 2 && body[i-2].kind == tokenIdent {
+			continue
+		}
+
+		callName := tok.text
+		callType := models.CallTypeFunction
+		switch {
+		case prev.text == "." || prev.text == "->":
+			callType = models.CallTypeMethod
+			if i >= 2 && body[i-2].kind == tokenIdent && (i < 3 || (body[i-3].text != "." && body[i-3].text != "->")) {
+				qualifier := body[i-2].text
+				if qualifier == "this" {
+					// this->helper() is a call to a method of the same class
+					callType = models.CallTypeFunction
+				} else {
+					callName = qualifier + "." + tok.text
+				}
+			}
+		case prev.text == "::" && i >= 2 && body[i-2].text == "std":
+			callName = "std::" + tok.text
+			callType = models.CallTypeExternal
+		}
+
+		if seen[callName] {
+			continue
+		}
+		seen[callName] = true
+
+		fn.Calls = append(fn.Calls, callName)
+		fn.LocalCalls = append(fn.LocalCalls, callName)
+		fn.LocalCallsWithMetadata = append(fn.LocalCallsWithMetadata, models.CallReference{
+			FunctionName: callName,
+			Line:         tok.line,
+			CallType:     callType,
+		})
+	}
+
+	// Ensure fields are never nil for JSON serialization
+	if fn.Calls == nil {
+		fn.Calls = []string{}
+	}
+	if fn.LocalCallsWithMetadata == nil {
+		fn.LocalCallsWithMetadata = []models.CallReference{}
+	}
+}
+
+// newFunction creates a function with its call graph fields initialized
+func newFunction(name, signature string) models.Function {
+	return models.Function{
+		Name:             name,
+		Signature:        signature,
+		Parameters:       []models.Parameter{},
+		Returns:          []models.Type{},
+		CalledBy:         []string{},
+		LocalCalls:       []string{},
+		CrossFileCalls:   []models.CallReference{},
+		LocalCallers:     []string{},
+		CrossFileCallers: []models.CallReference{},
+	}
+}
+
+// returnTypes converts a declared return type to the model representation
+func returnTypes(returnType string) []models.Type {
+	if returnType == "" || returnType == typeVoid {
+		return []models.Type{}
+	}
+	return []models.Type{{Name: returnType, Kind: typeKind(returnType)}}
+}
+
+// typeKind classifies a C or C++ type reference
+func typeKind(typeName string) string {
+	switch {
+	case strings.ContainsAny(typeName, "*&[<("):
+		return kindComposite
+	case !slices.ContainsFunc(strings.Fields(typeName), func(word string) bool {
+		return !builtinTypes[word] && word != "const" && word != "volatile"
+	}):
+		return kindBasic
+	default:
+		return kindNamed
+	}
+}
+
+// literalValue returns the initializer text when it is a single literal
+func literalValue(initializer []token) string {
+	switch {
+	case len(initializer) == 1 && initializer[0].kind != tokenPunct:
+		return initializer[0].text
+	case len(initializer) == 2 && initializer[0].text == "-" && initializer[1].kind == tokenNumber:
+		return "-" + initializer[1].text
+	}
+	return ""
+}
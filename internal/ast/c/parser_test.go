@@ -0,0 +1,548 @@
+package c
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"repository-context-protocol/internal/models"
+)
+
+const sampleHeader = `#ifndef QUEUE_H
+#define QUEUE_H
+
+#include <stddef.h>
+#include "list.h"
+
+#define QUEUE_MAX 64
+#define QUEUE_EMPTY(q) ((q)->size == 0)
+
+/* A bounded FIFO queue. */
+typedef struct queue {
+    int *items;
+    size_t size;
+    unsigned flags : 4;
+    union {
+        int tag;
+        void *data;
+    };
+} queue_t;
+
+typedef enum { QUEUE_OK, QUEUE_FULL = 2 } queue_status;
+
+typedef int (*queue_visit_fn)(const int *item, void *ctx);
+
+#ifdef __cplusplus
+extern "C" {
+#endif
+
+// Creates a queue.
+queue_t *queue_new(size_t capacity);
+int queue_push(queue_t *q, int item);
+extern const int queue_version;
+
+#ifdef __cplusplus
+}
+#endif
+
+#if 0
+int disabled_function(void);
+#endif
+
+#endif
+`
+
+const sampleSource = `#include "queue.h"
+#include <stdlib.h>
+
+static int counter = 0;
+const int queue_version = 3;
+static const char *names[] = {"a", "b"};
+
+static void log_event(const char *name, ...) __attribute__((format(printf, 1, 2)));
+
+/* Creates a queue. */
+queue_t *queue_new(size_t capacity)
+{
+    queue_t *q = malloc(sizeof(*q));
+    q->items = calloc(capacity, sizeof(int));
+    log_event("new");
+    return q;
+}
+
+int queue_push(queue_t *q, int item)
+{
+    if (QUEUE_EMPTY(q)) {
+        log_event("push");
+    }
+    q->items[q->size++] = item;
+    return QUEUE_OK;
+}
+
+static void log_event(const char *name, ...)
+{
+    counter++;
+}
+
+struct point { int x, y; } origin = {0, 0};
+`
+
+const sampleCpp = `#pragma once
+#include <string>
+#include <vector>
+
+namespace shapes {
+
+/// Base class for shapes.
+class Shape {
+public:
+    explicit Shape(std::string name) : name_(std::move(name)), id_{next_id()} {}
+    virtual ~Shape() = default;
+
+    // Computes the area.
+    [[nodiscard]] virtual double area() const = 0;
+    const std::string &name() const { return name_; }
+    Shape &operator=(const Shape &other);
+    bool operator==(const Shape &other) const;
+    static constexpr int kSides = 0;
+
+protected:
+    static int next_id();
+
+private:
+    std::string name_;
+    int id_;
+};
+
+class Circle final : public Shape, private Printable {
+public:
+    Circle(double radius);
+    double area() const override;
+    void draw(Canvas *canvas, int (*color)(int)) const;
+
+private:
+    double radius_;
+};
+
+template <typename T, int N = 4>
+struct Buffer {
+    T data[N];
+    T &at(int i) { return data[i]; }
+};
+
+template <typename T>
+T clamp(T value, T low, T high);
+
+enum class Color : unsigned char { Red, Green = 3 };
+
+using ShapeList = std::vector<Shape *>;
+
+Circle::Circle(double radius) : Shape("circle"), radius_(radius) {}
+
+double Circle::area() const {
+    return M_PI * radius_ * radius_;
+}
+
+void Circle::draw(Canvas *canvas, int (*color)(int)) const {
+    canvas->fill(area(), color(0));
+    this->area();
+    Helper helper(canvas);
+    auto sides = std::max(1, kSides);
+}
+
+int Shape::next_id() {
+    static int id = 0;
+    return ++id;
+}
+
+template <typename T>
+T clamp(T value, T low, T high) {
+    return value < low ? low : (value > high ? high : value);
+}
+
+} // namespace shapes
+
+int main(int argc, char **argv) {
+    shapes::Circle c(2.0);
+    std::vector<shapes::Shape *> all;
+    return static_cast<int>(c.area());
+}
+`
+
+func parseSample(t *testing.T, path, source string) *models.FileContext {
+	t.Helper()
+	fileContext, err := NewCParser().ParseFile(path, []byte(source))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	return fileContext
+}
+
+func findType(fileContext *models.FileContext, name string) *models.TypeDef {
+	for i := range fileContext.Types {
+		if fileContext.Types[i].Name == name {
+			return &fileContext.Types[i]
+		}
+	}
+	return nil
+}
+
+func findFunction(fileContext *models.FileContext, name string) *models.Function {
+	for i := range fileContext.Functions {
+		if fileContext.Functions[i].Name == name {
+			return &fileContext.Functions[i]
+		}
+	}
+	return nil
+}
+
+func exportedNames(fileContext *models.FileContext) map[string]bool {
+	exported := make(map[string]bool)
+	for _, export := range fileContext.Exports {
+		exported[export.Kind+":"+export.Name] = true
+	}
+	return exported
+}
+
+func TestCParser_Interface(t *testing.T) {
+	parser := NewCParser()
+
+	if parser.GetLanguageName() != "c" {
+		t.Errorf("Expected language 'c', got %s", parser.GetLanguageName())
+	}
+	expected := []string{".c", ".h", ".cpp", ".hpp"}
+	if !slices.Equal(parser.GetSupportedExtensions(), expected) {
+		t.Errorf("Expected %v, got %v", expected, parser.GetSupportedExtensions())
+	}
+
+	if language := parseSample(t, "shapes.hpp", "").Language; language != "cpp" {
+		t.Errorf("Expected C++ files to have language 'cpp', got %s", language)
+	}
+}
+
+func TestCParser_IncludesAndMacros(t *testing.T) {
+	fileContext := parseSample(t, "queue.h", sampleHeader)
+
+	if fileContext.Language != "c" {
+		t.Errorf("Expected language 'c', got %s", fileContext.Language)
+	}
+
+	var paths []string
+	for _, imp := range fileContext.Imports {
+		paths = append(paths, imp.Path)
+	}
+	if !slices.Equal(paths, []string{"stddef.h", "list.h"}) {
+		t.Errorf("Expected includes [stddef.h list.h], got %v", paths)
+	}
+
+	constants := make(map[string]models.Constant)
+	for _, constant := range fileContext.Constants {
+		constants[constant.Name] = constant
+	}
+	if constant := constants["QUEUE_MAX"]; constant.Type != "macro" || constant.Value != "64" {
+		t.Errorf("Expected QUEUE_MAX macro with value 64, got %+v", constant)
+	}
+	if _, ok := constants["QUEUE_H"]; ok {
+		t.Error("Expected the empty include guard macro to be skipped")
+	}
+
+	macro := findFunction(fileContext, "QUEUE_EMPTY")
+	if macro == nil || macro.Signature != "#define QUEUE_EMPTY(q)" || len(macro.Parameters) != 1 {
+		t.Errorf("Expected function-like macro QUEUE_EMPTY, got %+v", macro)
+	}
+	if findFunction(fileContext, "disabled_function") != nil {
+		t.Error("Expected declarations inside #if 0 to be skipped")
+	}
+}
+
+func TestCParser_StructsEnumsAndTypedefs(t *testing.T) {
+	fileContext := parseSample(t, "queue.h", sampleHeader)
+
+	queue := findType(fileContext, "queue")
+	if queue == nil {
+		t.Fatal("Expected struct queue to be extracted")
+	}
+	if queue.Kind != "struct" || queue.Doc != "A bounded FIFO queue." || queue.StartLine != 11 || queue.EndLine != 19 {
+		t.Errorf("Unexpected struct queue: %+v", queue)
+	}
+	var fields []string
+	for _, field := range queue.Fields {
+		fields = append(fields, field.Name+":"+field.Type)
+	}
+	expectedFields := []string{"items:int *", "size:size_t", "flags:unsigned", "tag:int", "data:void *"}
+	if !slices.Equal(fields, expectedFields) {
+		t.Errorf("Expected fields %v, got %v", expectedFields, fields)
+	}
+
+	expectedKinds := map[string]string{
+		"queue_t":        "alias",
+		"queue_status":   "enum",
+		"queue_visit_fn": "alias",
+	}
+	for name, kind := range expectedKinds {
+		typeDef := findType(fileContext, name)
+		if typeDef == nil || typeDef.Kind != kind {
+			t.Errorf("Expected %s with kind %s, got %+v", name, kind, typeDef)
+		}
+	}
+
+	status := findType(fileContext, "queue_status")
+	if len(status.Fields) != 2 || status.Fields[1].Name != "QUEUE_FULL" || status.Fields[1].Type != "queue_status" {
+		t.Errorf("Expected enumerators typed by the typedef name, got %v", status.Fields)
+	}
+	for _, constant := range fileContext.Constants {
+		if constant.Name == "QUEUE_FULL" && constant.Value != "2" {
+			t.Errorf("Expected QUEUE_FULL value 2, got %q", constant.Value)
+		}
+	}
+}
+
+func TestCParser_Functions(t *testing.T) {
+	fileContext := parseSample(t, "queue.c", sampleSource)
+
+	queueNew := findFunction(fileContext, "queue_new")
+	if queueNew == nil {
+		t.Fatal("Expected queue_new to be extracted")
+	}
+	if queueNew.Signature != "queue_t *queue_new(size_t capacity)" {
+		t.Errorf("Unexpected signature %q", queueNew.Signature)
+	}
+	if queueNew.Doc != "Creates a queue." || queueNew.StartLine != 11 || queueNew.EndLine != 17 {
+		t.Errorf("Expected documented queue_new at lines 11-17, got %q %d-%d", queueNew.Doc, queueNew.StartLine, queueNew.EndLine)
+	}
+	if len(queueNew.Returns) != 1 || queueNew.Returns[0].Name != "queue_t *" {
+		t.Errorf("Expected queue_t * return type, got %v", queueNew.Returns)
+	}
+
+	logEvent := findFunction(fileContext, "log_event")
+	if logEvent == nil || logEvent.StartLine != 28 {
+		t.Fatalf("Expected the log_event definition to replace its prototype, got %+v", logEvent)
+	}
+	expectedParams := []models.Parameter{{Name: "name", Type: "const char *"}, {Type: "..."}}
+	if !slices.Equal(logEvent.Parameters, expectedParams) {
+		t.Errorf("Expected parameters %v, got %v", expectedParams, logEvent.Parameters)
+	}
+	if len(fileContext.Functions) != 3 {
+		t.Errorf("Expected 3 functions, got %d", len(fileContext.Functions))
+	}
+
+	header := parseSample(t, "queue.h", sampleHeader)
+	push := findFunction(header, "queue_push")
+	if push == nil || len(push.Parameters) != 2 || push.Parameters[0].Type != "queue_t *" {
+		t.Errorf("Expected queue_push prototype from the header, got %+v", push)
+	}
+}
+
+func TestCParser_VariablesAndExports(t *testing.T) {
+	fileContext := parseSample(t, "queue.c", sampleSource)
+
+	variables := make(map[string]string)
+	for _, variable := range fileContext.Variables {
+		variables[variable.Name] = variable.Type
+	}
+	expected := map[string]string{"counter": "int", "names": "const char *[]", "origin": "struct point"}
+	for name, varType := range expected {
+		if variables[name] != varType {
+			t.Errorf("Expected variable %s of type %q, got %q", name, varType, variables[name])
+		}
+	}
+	if len(fileContext.Constants) != 1 || fileContext.Constants[0].Name != "queue_version" || fileContext.Constants[0].Value != "3" {
+		t.Errorf("Expected queue_version constant, got %v", fileContext.Constants)
+	}
+
+	exported := exportedNames(fileContext)
+	for _, name := range []string{"function:queue_new", "constant:queue_version", "variable:origin"} {
+		if !exported[name] {
+			t.Errorf("Expected export %s", name)
+		}
+	}
+	for _, name := range []string{"function:log_event", "variable:counter", "type:point"} {
+		if exported[name] {
+			t.Errorf("Expected %s not to be exported", name)
+		}
+	}
+
+	headerExports := exportedNames(parseSample(t, "queue.h", sampleHeader))
+	for _, name := range []string{"type:queue_t", "type:queue", "constant:QUEUE_MAX", "constant:QUEUE_OK", "function:queue_push"} {
+		if !headerExports[name] {
+			t.Errorf("Expected header export %s", name)
+		}
+	}
+}
+
+func TestCParser_Classes(t *testing.T) {
+	fileContext := parseSample(t, "shapes.cpp", sampleCpp)
+
+	shape := findType(fileContext, "Shape")
+	if shape == nil || shape.Kind != "class" || shape.Doc != "Base class for shapes." {
+		t.Fatalf("Expected documented class Shape, got %+v", shape)
+	}
+
+	methods := make(map[string]models.Method)
+	for _, method := range shape.Methods {
+		methods[method.Name] = method
+	}
+	for _, name := range []string{"Shape", "~Shape", "area", "name", "operator=", "operator==", "next_id"} {
+		if _, ok := methods[name]; !ok {
+			t.Errorf("Expected method %s on Shape, got %v", name, shape.Methods)
+		}
+	}
+	if len(shape.Methods) != 7 {
+		t.Errorf("Expected 7 methods without duplicates for out-of-class definitions, got %d", len(shape.Methods))
+	}
+	area := methods["area"]
+	if area.Signature != "virtual double area() const = 0" || area.Doc != "Computes the area." || area.Receiver != "Shape" {
+		t.Errorf("Unexpected area method: %+v", area)
+	}
+	if !slices.Equal(area.Decorators, []string{"nodiscard"}) {
+		t.Errorf("Expected area to carry [[nodiscard]], got %v", area.Decorators)
+	}
+
+	var fields []string
+	for _, field := range shape.Fields {
+		fields = append(fields, field.Name)
+	}
+	if !slices.Equal(fields, []string{"kSides", "name_", "id_"}) {
+		t.Errorf("Expected fields [kSides name_ id_], got %v", fields)
+	}
+
+	circle := findType(fileContext, "Circle")
+	if circle == nil || !slices.Equal(circle.Embedded, []string{"Shape", "Printable"}) {
+		t.Errorf("Expected Circle to derive from Shape and Printable, got %+v", circle)
+	}
+
+	buffer := findType(fileContext, "Buffer")
+	expectedParams := []models.TypeParam{{Name: "T", Constraint: "typename"}, {Name: "N", Constraint: "int"}}
+	if buffer == nil || buffer.Kind != "struct" || !slices.Equal(buffer.TypeParams, expectedParams) {
+		t.Errorf("Expected template struct Buffer, got %+v", buffer)
+	}
+
+	if color := findType(fileContext, "Color"); color == nil || color.Kind != "enum" || len(color.Fields) != 2 {
+		t.Errorf("Expected enum class Color, got %+v", color)
+	}
+	if alias := findType(fileContext, "ShapeList"); alias == nil || alias.Kind != "alias" {
+		t.Errorf("Expected using alias ShapeList, got %+v", alias)
+	}
+}
+
+func TestCParser_MemberFunctions(t *testing.T) {
+	fileContext := parseSample(t, "shapes.cpp", sampleCpp)
+
+	var areas []string
+	for _, fn := range fileContext.Functions {
+		if fn.Name == "area" {
+			areas = append(areas, fn.Receiver)
+		}
+	}
+	if !slices.Equal(areas, []string{"Circle"}) {
+		t.Errorf("Expected only the Circle::area definition as a function, got receivers %v", areas)
+	}
+
+	draw := findFunction(fileContext, "draw")
+	if draw == nil {
+		t.Fatal("Expected Circle::draw to be extracted")
+	}
+	if draw.Signature != "void Circle::draw(Canvas *canvas, int (*color)(int)) const" {
+		t.Errorf("Unexpected signature %q", draw.Signature)
+	}
+	if len(draw.Parameters) != 2 || draw.Parameters[1].Name != "color" || draw.Parameters[1].Type != "int (*)(int)" {
+		t.Errorf("Expected function pointer parameter, got %v", draw.Parameters)
+	}
+
+	if clamp := findFunction(fileContext, "clamp"); clamp == nil || clamp.StartLine != 69 || len(clamp.TypeParams) != 1 {
+		t.Errorf("Expected the clamp template definition, got %+v", clamp)
+	}
+	if name := findFunction(fileContext, "name"); name == nil || name.Receiver != "Shape" {
+		t.Errorf("Expected inline method name to be a function with receiver Shape, got %+v", name)
+	}
+}
+
+func TestCParser_CallGraph(t *testing.T) {
+	fileContext := parseSample(t, "shapes.cpp", sampleCpp)
+
+	draw := findFunction(fileContext, "draw")
+	for _, call := range []string{"canvas.fill", "area", "color", "std::max"} {
+		if !slices.Contains(draw.LocalCalls, call) {
+			t.Errorf("Expected draw to call %s, got %v", call, draw.LocalCalls)
+		}
+	}
+	if slices.Contains(draw.LocalCalls, "helper") || slices.Contains(draw.LocalCalls, "Helper") {
+		t.Errorf("Expected declarations not to be recorded as calls, got %v", draw.LocalCalls)
+	}
+
+	callTypes := make(map[string]string)
+	for _, call := range draw.LocalCallsWithMetadata {
+		callTypes[call.FunctionName] = call.CallType
+	}
+	if callTypes["canvas.fill"] != models.CallTypeMethod {
+		t.Errorf("Expected canvas->fill to be a method call, got %s", callTypes["canvas.fill"])
+	}
+	if callTypes["std::max"] != models.CallTypeExternal {
+		t.Errorf("Expected std::max to be external, got %s", callTypes["std::max"])
+	}
+
+	area := findFunction(fileContext, "area")
+	if !slices.Contains(area.LocalCallers, "draw") {
+		t.Errorf("Expected area to be called by draw, got %v", area.LocalCallers)
+	}
+
+	source := parseSample(t, "queue.c", sampleSource)
+	if logEvent := findFunction(source, "log_event"); !slices.Equal(logEvent.CalledBy, []string{"queue_new", "queue_push"}) {
+		t.Errorf("Expected log_event to be called by queue_new and queue_push, got %v", logEvent.CalledBy)
+	}
+}
+
+func TestCParser_Errors(t *testing.T) {
+	parser := NewCParser()
+
+	invalidSources := map[string]struct {
+		source string
+		kind   string
+	}{
+		"unclosed function":   {"int run(void) {\n    return 0;\n", models.ParseErrorSyntax},
+		"stray brace":         {"int count;\n}\n", models.ParseErrorSyntax},
+		"unterminated string": {"const char *s = \"oops;\n", models.ParseErrorSyntax},
+		"unterminated if":     {"#if FEATURE\nint x;\n", models.ParseErrorSyntax},
+		"conditional braces":  {"#ifdef A\nint f(void) {\n#else\nint f(int x) {\n#endif\n    return 0;\n}\n", models.ParseErrorUnsupported},
+		"K&R definition":      {"int add(a, b)\nint a; int b;\n{\n    return a + b;\n}\n", models.ParseErrorUnsupported},
+	}
+
+	for name, tc := range invalidSources {
+		t.Run(name, func(t *testing.T) {
+			_, err := parser.ParseFile("broken.c", []byte(tc.source))
+			var parseErr *models.ParseError
+			if !errors.As(err, &parseErr) {
+				t.Fatalf("Expected a ParseError, got %v", err)
+			}
+			if parseErr.Kind != tc.kind || parseErr.File != "broken.c" || parseErr.Line == 0 {
+				t.Errorf("Expected a located %s error for broken.c, got %#v", tc.kind, parseErr)
+			}
+			if !strings.Contains(err.Error(), "broken.c") {
+				t.Errorf("Expected the error to name the file, got %v", err)
+			}
+		})
+	}
+}
+
+func TestCParser_MacroInvocations(t *testing.T) {
+	source := "MODULE_LICENSE(\"GPL\");\nLIST_HEAD(pending);\nEXPORT_SYMBOL(helper)\nint helper(void) { return 0; }\n"
+	fileContext := parseSample(t, "module.c", source)
+
+	if len(fileContext.Functions) != 1 || fileContext.Functions[0].Name != "helper" {
+		t.Errorf("Expected only helper to be extracted, got %+v", fileContext.Functions)
+	}
+	if len(fileContext.Variables) != 0 {
+		t.Errorf("Expected macro invocations not to be variables, got %v", fileContext.Variables)
+	}
+}
+
+func TestCParser_EmptyFile(t *testing.T) {
+	fileContext := parseSample(t, "empty.c", "")
+	if fileContext.Path != "empty.c" {
+		t.Errorf("Expected path 'empty.c', got %s", fileContext.Path)
+	}
+	if len(fileContext.Functions) != 0 || len(fileContext.Types) != 0 {
+		t.Errorf("Expected empty file to have no declarations")
+	}
+}
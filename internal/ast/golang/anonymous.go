@@ -0,0 +1,81 @@
+package golang
+
+import (
+	"fmt"
+	"go/ast"
+
+	"repository-context-protocol/internal/models"
+)
+
+// anonymousFunctionNames names the function literals of a file func@file:line, adding
+// the column to the second and later literals starting on a line, so names only change
+// when the literal moves. It returns nil unless anonymous functions are indexed.
+func (p *GoParser) anonymousFunctionNames(file *ast.File) map[*ast.FuncLit]string {
+	if !p.indexAnonymous {
+		return nil
+	}
+
+	literals := make(map[*ast.FuncLit]string)
+	taken := make(map[string]bool)
+	ast.Inspect(file, func(n ast.Node) bool {
+		if lit, ok := n.(*ast.FuncLit); ok {
+			pos := p.fset.Position(lit.Pos())
+			name := fmt.Sprintf("func@%s:%d", pos.Filename, pos.Line)
+			if taken[name] {
+				name = fmt.Sprintf("%s:%d", name, pos.Column)
+			}
+			taken[name] = true
+			literals[lit] = name
+		}
+		return true
+	})
+	return literals
+}
+
+// extractAnonymousFunctions returns the function literals of a file as functions in
+// source order, each naming the function or literal enclosing it as its parent.
+// Package-level literals, such as variable initializers, have no parent.
+func (p *GoParser) extractAnonymousFunctions(
+	file *ast.File, imports []models.Import, literals map[*ast.FuncLit]string,
+) []models.Function {
+	var functions []models.Function
+	var visit func(node ast.Node, parent string)
+	visit = func(node ast.Node, parent string) {
+		ast.Inspect(node, func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.FuncDecl:
+				if n.Body != nil {
+					visit(n.Body, p.funcDeclName(n))
+				}
+				return false
+			case *ast.FuncLit:
+				name := literals[n]
+				fn := p.extractFunction(&ast.FuncDecl{Name: ast.NewIdent(name), Type: n.Type, Body: n.Body}, imports, literals)
+				fn.Signature = "func" + p.buildFuncTypeSignature(n.Type)
+				fn.Parent = parent
+				functions = append(functions, fn)
+				visit(n.Body, name)
+				return false
+			}
+			return true
+		})
+	}
+	visit(file, "")
+	return functions
+}
+
+// funcDeclName names a declared function as a parent: its name, prefixed with the
+// receiver type for methods, e.g. "Service.Run"
+func (p *GoParser) funcDeclName(node *ast.FuncDecl) string {
+	if node.Recv != nil && len(node.Recv.List) > 0 {
+		return p.extractReceiverType(node.Recv.List[0].Type) + "." + node.Name.Name
+	}
+	return node.Name.Name
+}
+
+// isIndexedLiteralCall reports whether a call invokes a function literal that is indexed
+// on its own; the literal is then recorded as the callee instead of "<anonymous>"
+func isIndexedLiteralCall(call *ast.CallExpr, literals map[*ast.FuncLit]string) bool {
+	_, isLiteral := call.Fun.(*ast.FuncLit)
+	return isLiteral && literals != nil
+}
@@ -0,0 +1,105 @@
+package golang
+
+import (
+	"slices"
+	"testing"
+)
+
+const anonymousSource = `package handlers
+
+var fallback = func() { log() }
+
+type Server struct{}
+
+func (s *Server) Serve(items []int) {
+	go func() {
+		handle()
+		defer func() { recover() }()
+	}()
+	sortBy(items, func(i, j int) bool { return i < j }); done := func() {}; done()
+}
+
+func handle() {}
+func log()    {}
+func sortBy(items []int, less func(i, j int) bool) {}
+`
+
+func TestGoParser_IndexAnonymous(t *testing.T) {
+	parser := NewGoParser()
+	parser.SetIndexAnonymous(true)
+
+	fileContext, err := parser.ParseFile("handlers.go", []byte(anonymousSource))
+	if err != nil {
+		t.Fatalf("Failed to parse file: %v", err)
+	}
+
+	functions := make(map[string]int)
+	for i, function := range fileContext.Functions {
+		functions[function.Name] = i
+	}
+	expected := []struct {
+		name      string
+		parent    string
+		signature string
+		calls     []string
+		callers   []string
+	}{
+		{"func@handlers.go:3", "", "func()", []string{"log"}, nil},
+		{"func@handlers.go:8", "Server.Serve", "func()", []string{"handle", "func@handlers.go:10"}, []string{"Serve"}},
+		{"func@handlers.go:10", "func@handlers.go:8", "func()", []string{"recover"}, []string{"func@handlers.go:8"}},
+		{"func@handlers.go:12", "Server.Serve", "func(i int, j int) bool", nil, []string{"Serve"}},
+		{"func@handlers.go:12:63", "Server.Serve", "func()", nil, []string{"Serve"}},
+	}
+	for _, want := range expected {
+		i, found := functions[want.name]
+		if !found {
+			t.Errorf("Expected anonymous function %s to be indexed", want.name)
+			continue
+		}
+		function := fileContext.Functions[i]
+		if function.Parent != want.parent {
+			t.Errorf("Expected %s to have parent %q, got %q", want.name, want.parent, function.Parent)
+		}
+		if function.Signature != want.signature {
+			t.Errorf("Expected %s to have signature %q, got %q", want.name, want.signature, function.Signature)
+		}
+		// Calls are collected through a map, so their order is not defined
+		slices.Sort(function.LocalCalls)
+		slices.Sort(want.calls)
+		if !slices.Equal(function.LocalCalls, want.calls) && len(function.LocalCalls)+len(want.calls) > 0 {
+			t.Errorf("Expected %s to call %v, got %v", want.name, want.calls, function.LocalCalls)
+		}
+		if !slices.Equal(function.LocalCallers, want.callers) && len(function.LocalCallers)+len(want.callers) > 0 {
+			t.Errorf("Expected %s to be called by %v, got %v", want.name, want.callers, function.LocalCallers)
+		}
+	}
+
+	// Calls made inside the literals belong to them, not to Serve
+	serve := fileContext.Functions[functions["Serve"]]
+	for _, call := range []string{"handle", "recover", "<anonymous>"} {
+		if slices.Contains(serve.LocalCalls, call) || slices.Contains(serve.Calls, call) {
+			t.Errorf("Expected Serve not to call %s directly, got %v", call, serve.LocalCalls)
+		}
+	}
+	for _, call := range []string{"func@handlers.go:8", "sortBy", "func@handlers.go:12", "func@handlers.go:12:63", "done"} {
+		if !slices.Contains(serve.LocalCalls, call) {
+			t.Errorf("Expected Serve to call %s, got %v", call, serve.LocalCalls)
+		}
+	}
+}
+
+func TestGoParser_AnonymousNotIndexedByDefault(t *testing.T) {
+	fileContext, err := NewGoParser().ParseFile("handlers.go", []byte(anonymousSource))
+	if err != nil {
+		t.Fatalf("Failed to parse file: %v", err)
+	}
+
+	if len(fileContext.Functions) != 4 {
+		t.Errorf("Expected only the 4 declared functions, got %d", len(fileContext.Functions))
+	}
+	for _, function := range fileContext.Functions {
+		if function.Name == "Serve" && !slices.Contains(function.LocalCalls, "handle") {
+			t.Errorf("Expected Serve to keep the calls made in its closures, got %v", function.LocalCalls)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package golang
+
+import (
+	"go/ast"
+	"go/build/constraint"
+	"runtime"
+	"slices"
+	"strings"
+)
+
+// Operating systems and architectures recognised in build tags, as listed by go tool dist
+var (
+	knownOS = []string{
+		"aix", "android", "darwin", "dragonfly", "freebsd", "hurd", "illumos", "ios", "js",
+		"linux", "nacl", "netbsd", "openbsd", "plan9", "solaris", "wasip1", "windows", "zos",
+	}
+	unixOS = []string{
+		"aix", "android", "darwin", "dragonfly", "freebsd", "hurd", "illumos", "ios",
+		"linux", "netbsd", "openbsd", "solaris",
+	}
+	knownArch = []string{
+		"386", "amd64", "amd64p32", "arm", "armbe", "arm64", "arm64be", "loong64", "mips", "mipsle",
+		"mips64", "mips64le", "mips64p32", "mips64p32le", "ppc", "ppc64", "ppc64le", "riscv", "riscv64",
+		"s390", "s390x", "sparc", "sparc64", "wasm",
+	}
+)
+
+// extractBuildConstraint returns the build constraint guarding file in //go:build form,
+// or "" when it has none. Legacy // +build lines are combined with &&, as the go
+// command does, when no //go:build line is present.
+func extractBuildConstraint(file *ast.File) string {
+	var plusBuild constraint.Expr
+	for _, group := range file.Comments {
+		// Constraints must appear before the package clause
+		if group.Pos() >= file.Package {
+			break
+		}
+		for _, comment := range group.List {
+			if !constraint.IsGoBuild(comment.Text) && !constraint.IsPlusBuild(comment.Text) {
+				continue
+			}
+			expr, err := constraint.Parse(comment.Text)
+			if err != nil {
+				continue
+			}
+			if constraint.IsGoBuild(comment.Text) {
+				return expr.String()
+			}
+			if plusBuild == nil {
+				plusBuild = expr
+			} else {
+				plusBuild = &constraint.AndExpr{X: plusBuild, Y: expr}
+			}
+		}
+	}
+	if plusBuild == nil {
+		return ""
+	}
+	return plusBuild.String()
+}
+
+// MatchBuildConstraint reports whether a //go:build expression such as "linux && !cgo"
+// is satisfied by tags. The host GOOS and GOARCH apply unless tags name another
+// operating system or architecture, "unix" follows the selected GOOS, and release
+// tags such as go1.21 are always satisfied. An empty expression always matches.
+func MatchBuildConstraint(expr string, tags []string) (bool, error) {
+	if strings.TrimSpace(expr) == "" {
+		return true, nil
+	}
+	parsed, err := constraint.Parse("//go:build " + expr)
+	if err != nil {
+		return false, err
+	}
+
+	goos, goarch := runtime.GOOS, runtime.GOARCH
+	for _, tag := range tags {
+		if slices.Contains(knownOS, tag) {
+			goos = tag
+		}
+		if slices.Contains(knownArch, tag) {
+			goarch = tag
+		}
+	}
+
+	return parsed.Eval(func(tag string) bool {
+		switch {
+		case tag == goos || tag == goarch:
+			return true
+		case tag == "unix":
+			return slices.Contains(unixOS, goos)
+		case slices.Contains(knownOS, tag) || slices.Contains(knownArch, tag):
+			// Only the selected platform is satisfied
+			return false
+		case strings.HasPrefix(tag, "go1."):
+			return true
+		default:
+			return slices.Contains(tags, tag)
+		}
+	}), nil
+}
@@ -0,0 +1,66 @@
+package golang
+
+import (
+	"runtime"
+	"testing"
+)
+
+func TestGoParser_BuildConstraint(t *testing.T) {
+	tests := []struct {
+		name string
+		code string
+		want string
+	}{
+		{name: "none", code: "package test\n", want: ""},
+		{name: "go:build", code: "//go:build linux && (amd64 || arm64)\n\npackage test\n", want: "linux && (amd64 || arm64)"},
+		{name: "plus build lines are combined", code: "// +build linux darwin\n// +build !cgo\n\npackage test\n", want: "(linux || darwin) && !cgo"},
+		{name: "go:build wins over plus build", code: "//go:build ignore\n// +build ignore\n\npackage test\n", want: "ignore"},
+		{name: "after package clause is ignored", code: "package test\n\n//go:build linux\n", want: ""},
+	}
+
+	parser := NewGoParser()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fileContext, err := parser.ParseFile("tagged.go", []byte(tt.code))
+			if err != nil {
+				t.Fatalf("Failed to parse code: %v", err)
+			}
+			if fileContext.BuildConstraint != tt.want {
+				t.Errorf("Expected constraint %q, got %q", tt.want, fileContext.BuildConstraint)
+			}
+		})
+	}
+}
+
+func TestMatchBuildConstraint(t *testing.T) {
+	tests := []struct {
+		expr string
+		tags []string
+		want bool
+	}{
+		{expr: "", want: true},
+		{expr: runtime.GOOS, want: true},
+		{expr: runtime.GOARCH, want: true},
+		{expr: "integration", want: false},
+		{expr: "integration", tags: []string{"integration"}, want: true},
+		{expr: "windows && amd64", tags: []string{"windows", "amd64"}, want: true},
+		{expr: "linux", tags: []string{"windows"}, want: false},
+		{expr: "unix", tags: []string{"darwin"}, want: true},
+		{expr: "unix", tags: []string{"windows"}, want: false},
+		{expr: "go1.21 && !ignore", want: true},
+	}
+
+	for _, tt := range tests {
+		got, err := MatchBuildConstraint(tt.expr, tt.tags)
+		if err != nil {
+			t.Fatalf("Failed to evaluate %q: %v", tt.expr, err)
+		}
+		if got != tt.want {
+			t.Errorf("MatchBuildConstraint(%q, %v) = %t, want %t", tt.expr, tt.tags, got, tt.want)
+		}
+	}
+
+	if _, err := MatchBuildConstraint("linux &&", nil); err == nil {
+		t.Error("Expected error for malformed expression")
+	}
+}
@@ -0,0 +1,36 @@
+package golang
+
+import (
+	"go/ast"
+	"go/token"
+)
+
+// cyclomaticComplexity returns the complexity of a function body as defined by
+// models.ComplexityDefinition, or zero for declarations without a body
+func cyclomaticComplexity(body *ast.BlockStmt) int {
+	if body == nil {
+		return 0
+	}
+
+	complexity := 1
+	ast.Inspect(body, func(node ast.Node) bool {
+		switch n := node.(type) {
+		case *ast.IfStmt, *ast.ForStmt, *ast.RangeStmt:
+			complexity++
+		case *ast.CaseClause:
+			if n.List != nil { // A nil list is the default clause
+				complexity++
+			}
+		case *ast.CommClause:
+			if n.Comm != nil { // A nil communication is the default clause
+				complexity++
+			}
+		case *ast.BinaryExpr:
+			if n.Op == token.LAND || n.Op == token.LOR {
+				complexity++
+			}
+		}
+		return true
+	})
+	return complexity
+}
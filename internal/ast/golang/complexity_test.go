@@ -0,0 +1,90 @@
+package golang
+
+import (
+	"testing"
+)
+
+func TestGoParser_Complexity(t *testing.T) {
+	parser := NewGoParser()
+
+	code := `package test
+
+func Straight() int {
+	return 1
+}
+
+func Branchy(items []int, ch chan int) int {
+	total := 0
+	for _, item := range items {
+		if item > 0 && item < 10 || item == 42 {
+			total += item
+		}
+	}
+	for i := 0; i < 3; i++ {
+	}
+	switch total {
+	case 1, 2:
+	case 3:
+	default:
+	}
+	select {
+	case v := <-ch:
+		total += v
+	default:
+	}
+	check := func() bool { return total > 0 || total < -5 }
+	_ = check
+	return total
+}
+
+type Counter struct{}
+
+// Inc has one branch
+func (c *Counter) Inc(n int) {
+	if n > 0 {
+	}
+}
+
+type Incrementer interface {
+	Inc(n int)
+}
+`
+
+	fileContext, err := parser.ParseFile("complexity.go", []byte(code))
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+
+	// Branchy: 1 + range + if + && + || + for + two cases + select case + || in the literal
+	expected := map[string]struct{ complexity, lines int }{
+		"Straight": {complexity: 1, lines: 3},
+		"Branchy":  {complexity: 10, lines: 23},
+		"Inc":      {complexity: 2, lines: 4},
+	}
+	for i := range fileContext.Functions {
+		function := &fileContext.Functions[i]
+		want, ok := expected[function.Name]
+		if !ok {
+			continue
+		}
+		if function.Complexity != want.complexity || function.LineCount != want.lines {
+			t.Errorf("%s: expected complexity %d over %d lines, got %d over %d lines",
+				function.Name, want.complexity, want.lines, function.Complexity, function.LineCount)
+		}
+	}
+
+	for i := range fileContext.Types {
+		for _, method := range fileContext.Types[i].Methods {
+			switch fileContext.Types[i].Name {
+			case "Counter":
+				if method.Complexity != 2 || method.LineCount != 4 {
+					t.Errorf("Expected Counter.Inc complexity 2 over 4 lines, got %d over %d", method.Complexity, method.LineCount)
+				}
+			case "Incrementer":
+				if method.Complexity != 0 {
+					t.Errorf("Expected no complexity for an interface method without a body, got %d", method.Complexity)
+				}
+			}
+		}
+	}
+}
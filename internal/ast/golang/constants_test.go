@@ -2,6 +2,8 @@ package golang
 
 import (
 	"testing"
+
+	"repository-context-protocol/internal/models"
 )
 
 func TestGoParser_ConstantExtraction(t *testing.T) {
@@ -286,3 +288,76 @@ const (
 		}
 	}
 }
+
+func TestGoParser_ConstantIotaGroups(t *testing.T) {
+	parser := NewGoParser()
+
+	code := `package test
+
+type Status int
+
+const (
+	StatusUnknown Status = iota
+	StatusActive
+	StatusInactive
+	StatusMax Status = 99
+	StatusAlias
+)
+
+const (
+	FlagRead = 1 << iota
+	FlagWrite
+)
+
+const Standalone = "solo"`
+
+	fileContext, err := parser.ParseFile("status.go", []byte(code))
+	if err != nil {
+		t.Fatalf("Failed to parse code: %v", err)
+	}
+
+	constants := make(map[string]models.Constant)
+	for _, constant := range fileContext.Constants {
+		constants[constant.Name] = constant
+	}
+
+	expected := map[string]struct{ value, typeName string }{
+		"StatusUnknown":  {"iota", "Status"},
+		"StatusActive":   {"iota", "Status"},
+		"StatusInactive": {"iota", "Status"},
+		"StatusMax":      {"99", "Status"},
+		"StatusAlias":    {"99", "Status"},
+		"FlagRead":       {"1 << iota", ""},
+		"FlagWrite":      {"1 << iota", ""},
+		"Standalone":     {`"solo"`, "string"},
+	}
+	for name, want := range expected {
+		constant, ok := constants[name]
+		if !ok {
+			t.Errorf("Expected constant %s", name)
+			continue
+		}
+		if constant.Value != want.value {
+			t.Errorf("Constant %s expected value %q, got %q", name, want.value, constant.Value)
+		}
+		if constant.Type != want.typeName {
+			t.Errorf("Constant %s expected type %q, got %q", name, want.typeName, constant.Type)
+		}
+	}
+
+	statusGroup := constants["StatusUnknown"].GroupID
+	if statusGroup == "" {
+		t.Fatal("Expected grouped constants to have a GroupID")
+	}
+	for _, name := range []string{"StatusActive", "StatusInactive", "StatusMax", "StatusAlias"} {
+		if constants[name].GroupID != statusGroup {
+			t.Errorf("Expected %s to share group %s, got %s", name, statusGroup, constants[name].GroupID)
+		}
+	}
+	if constants["FlagRead"].GroupID == statusGroup || constants["FlagRead"].GroupID != constants["FlagWrite"].GroupID {
+		t.Error("Expected each const block to have its own GroupID")
+	}
+	if constants["Standalone"].GroupID != "" {
+		t.Errorf("Expected ungrouped constant to have no GroupID, got %s", constants["Standalone"].GroupID)
+	}
+}
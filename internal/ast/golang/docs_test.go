@@ -0,0 +1,101 @@
+package golang
+
+import (
+	"testing"
+)
+
+func TestGoParser_DocCommentExtraction(t *testing.T) {
+	parser := NewGoParser()
+
+	code := `package test
+
+// User represents a user in the system
+type User struct {
+	Name string
+}
+
+// Greet returns a greeting for the user.
+//
+// It is safe to call on a nil receiver.
+func (u *User) Greet() string {
+	return "hello"
+}
+
+/*
+NewUser creates a new user.
+*/
+func NewUser() *User {
+	return &User{}
+}
+
+// Store persists users
+type Store interface {
+	// Save writes a user
+	Save(u *User) error
+}
+
+// Grouped constants
+const (
+	// MaxUsers is the maximum number of users
+	MaxUsers = 100
+	MinUsers = 1
+)
+
+// DefaultName is used when no name is given
+var DefaultName = "anonymous"
+
+func undocumented() {}`
+
+	fileContext, err := parser.ParseFile("docs.go", []byte(code))
+	if err != nil {
+		t.Fatalf("Failed to parse code: %v", err)
+	}
+
+	functionDocs := map[string]string{
+		"Greet":        "Greet returns a greeting for the user.\n\nIt is safe to call on a nil receiver.",
+		"NewUser":      "NewUser creates a new user.",
+		"undocumented": "",
+	}
+	for _, fn := range fileContext.Functions {
+		if expected, ok := functionDocs[fn.Name]; ok && fn.Doc != expected {
+			t.Errorf("Function %s: expected doc %q, got %q", fn.Name, expected, fn.Doc)
+		}
+	}
+
+	typeDocs := map[string]string{
+		"User":  "User represents a user in the system",
+		"Store": "Store persists users",
+	}
+	for i := range fileContext.Types {
+		typeDef := &fileContext.Types[i]
+		if expected := typeDocs[typeDef.Name]; typeDef.Doc != expected {
+			t.Errorf("Type %s: expected doc %q, got %q", typeDef.Name, expected, typeDef.Doc)
+		}
+
+		switch typeDef.Name {
+		case "User":
+			if len(typeDef.Methods) != 1 || typeDef.Methods[0].Doc == "" {
+				t.Errorf("Expected User.Greet method to carry its doc comment, got %+v", typeDef.Methods)
+			}
+		case "Store":
+			if len(typeDef.Methods) != 1 || typeDef.Methods[0].Doc != "Save writes a user" {
+				t.Errorf("Expected Store.Save interface method doc, got %+v", typeDef.Methods)
+			}
+		}
+	}
+
+	// Grouped specs use their own doc; the group doc is not inherited
+	constantDocs := map[string]string{
+		"MaxUsers": "MaxUsers is the maximum number of users",
+		"MinUsers": "",
+	}
+	for _, constant := range fileContext.Constants {
+		if expected := constantDocs[constant.Name]; constant.Doc != expected {
+			t.Errorf("Constant %s: expected doc %q, got %q", constant.Name, expected, constant.Doc)
+		}
+	}
+
+	if len(fileContext.Variables) != 1 || fileContext.Variables[0].Doc != "DefaultName is used when no name is given" {
+		t.Errorf("Expected DefaultName variable doc, got %+v", fileContext.Variables)
+	}
+}
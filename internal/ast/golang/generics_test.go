@@ -0,0 +1,134 @@
+package golang
+
+import (
+	"testing"
+
+	"repository-context-protocol/internal/models"
+)
+
+const genericsSource = `package collections
+
+// Number is satisfied by integer and float types
+type Number interface {
+	~int | ~int64 | ~float64
+}
+
+// Map applies fn to every item
+func Map[T any, U any](items []T, fn func(T) U) []U {
+	result := make([]U, 0, len(items))
+	for _, item := range items {
+		result = append(result, fn(item))
+	}
+	return result
+}
+
+// Sum adds up numbers
+func Sum[N Number](values ...N) N {
+	var total N
+	for _, v := range values {
+		total += v
+	}
+	return total
+}
+
+// Set is a generic set
+type Set[T comparable] struct {
+	items map[T]struct{}
+}
+
+// Pair holds two values
+type Pair[K comparable, V any] struct {
+	Key   K
+	Value V
+}
+
+// Add inserts a value
+func (s *Set[T]) Add(value T) {
+	s.items[value] = struct{}{}
+}
+
+// Entries returns the set as pairs
+func (s *Set[T]) Entries() []Pair[T, bool] {
+	return nil
+}
+`
+
+func TestGoParser_GenericFunctions(t *testing.T) {
+	fileContext, err := NewGoParser().ParseFile("collections.go", []byte(genericsSource))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	functions := make(map[string]models.Function)
+	for _, fn := range fileContext.Functions {
+		functions[fn.Name] = fn
+	}
+
+	mapFn := functions["Map"]
+	expectedParams := []models.TypeParam{{Name: "T", Constraint: "any"}, {Name: "U", Constraint: "any"}}
+	if len(mapFn.TypeParams) != len(expectedParams) {
+		t.Fatalf("Expected type params %v, got %v", expectedParams, mapFn.TypeParams)
+	}
+	for i, expected := range expectedParams {
+		if mapFn.TypeParams[i] != expected {
+			t.Errorf("Expected type param %v, got %v", expected, mapFn.TypeParams[i])
+		}
+	}
+
+	expectedSignature := "func Map[T any, U any](items []T, fn func(T) U) []U"
+	if mapFn.Signature != expectedSignature {
+		t.Errorf("Expected signature %q, got %q", expectedSignature, mapFn.Signature)
+	}
+
+	sum := functions["Sum"]
+	if len(sum.TypeParams) != 1 || sum.TypeParams[0].Constraint != "Number" {
+		t.Errorf("Expected Sum to be constrained by Number, got %v", sum.TypeParams)
+	}
+	if sum.Signature != "func Sum[N Number](values ...N) N" {
+		t.Errorf("Unexpected Sum signature %q", sum.Signature)
+	}
+
+	entries := functions["Entries"]
+	if entries.Signature != "func (s *Set[T]) Entries() []Pair[T, bool]" {
+		t.Errorf("Expected generic receiver and return types, got %q", entries.Signature)
+	}
+	if entries.Receiver != "Set" {
+		t.Errorf("Expected receiver type Set, got %q", entries.Receiver)
+	}
+	if mapFn.Receiver != "" {
+		t.Errorf("Expected plain function to have no receiver, got %q", mapFn.Receiver)
+	}
+	if len(entries.TypeParams) != 0 {
+		t.Errorf("Expected methods to declare no type params, got %v", entries.TypeParams)
+	}
+}
+
+func TestGoParser_GenericTypes(t *testing.T) {
+	fileContext, err := NewGoParser().ParseFile("collections.go", []byte(genericsSource))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	typeDefs := make(map[string]models.TypeDef)
+	for _, typeDef := range fileContext.Types {
+		typeDefs[typeDef.Name] = typeDef
+	}
+
+	set := typeDefs["Set"]
+	if len(set.TypeParams) != 1 || set.TypeParams[0] != (models.TypeParam{Name: "T", Constraint: "comparable"}) {
+		t.Errorf("Expected Set[T comparable], got %v", set.TypeParams)
+	}
+	if len(set.Methods) != 2 {
+		t.Errorf("Expected methods on generic receiver to be attached to Set, got %d", len(set.Methods))
+	}
+
+	pair := typeDefs["Pair"]
+	if models.FormatTypeParams(pair.TypeParams) != "[K comparable, V any]" {
+		t.Errorf("Expected Pair[K comparable, V any], got %v", pair.TypeParams)
+	}
+
+	number := typeDefs["Number"]
+	if len(number.TypeParams) != 0 {
+		t.Errorf("Expected non-generic type to have no type params, got %v", number.TypeParams)
+	}
+}
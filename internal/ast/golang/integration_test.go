@@ -191,3 +191,47 @@ func findType(types []models.TypeDef, name string) *models.TypeDef {
 	}
 	return nil
 }
+
+func TestGoParser_InterfaceMethodSet(t *testing.T) {
+	parser := NewGoParser()
+
+	testFile := filepath.Join("..", "..", "..", "testdata", "simple-go", "main.go")
+	content, err := os.ReadFile(testFile)
+	if err != nil {
+		t.Fatalf("Failed to read test file: %v", err)
+	}
+
+	fileContext, err := parser.ParseFile(testFile, content)
+	if err != nil {
+		t.Fatalf("Failed to parse file: %v", err)
+	}
+
+	serviceType := findType(fileContext.Types, "UserService")
+	if serviceType == nil {
+		t.Fatal("Expected to find UserService type")
+	}
+
+	expected := []struct {
+		name      string
+		signature string
+		line      int
+	}{
+		{"GetUser", "GetUser(id int) (*User, error)", 28},
+		{"CreateUser", "CreateUser(name string, email string) (*User, error)", 29},
+		{"UpdateUser", "UpdateUser(user *User) error", 30},
+		{"DeleteUser", "DeleteUser(id int) error", 31},
+	}
+	if len(serviceType.Methods) != len(expected) {
+		t.Fatalf("Expected UserService to declare %d methods, got %d", len(expected), len(serviceType.Methods))
+	}
+	for i, want := range expected {
+		method := serviceType.Methods[i]
+		if method.Name != want.name || method.Signature != want.signature || method.StartLine != want.line {
+			t.Errorf("Expected method %s %q at line %d, got %s %q at line %d",
+				want.name, want.signature, want.line, method.Name, method.Signature, method.StartLine)
+		}
+	}
+	if len(serviceType.Fields) != 0 {
+		t.Errorf("Expected interface methods not to be recorded as fields, got %+v", serviceType.Fields)
+	}
+}
@@ -2,15 +2,19 @@ package golang
 
 import (
 	"crypto/sha256"
+	"errors"
 	"fmt"
 	"go/ast"
 	"go/parser"
+	"go/scanner"
 	"go/token"
+	"go/types"
 	"os"
 	"slices"
 	"strings"
 	"time"
 	"unicode"
+	"unicode/utf8"
 
 	"repository-context-protocol/internal/models"
 )
@@ -36,7 +40,8 @@ const (
 
 // Go AST parser implementation
 type GoParser struct {
-	fset *token.FileSet
+	fset           *token.FileSet
+	indexAnonymous bool
 }
 
 func NewGoParser() *GoParser {
@@ -45,6 +50,14 @@ func NewGoParser() *GoParser {
 	}
 }
 
+// SetIndexAnonymous makes the parser list function literals as functions named
+// func@file:line, with the enclosing function as their parent. Calls made inside a
+// literal are then attributed to it instead of to the enclosing function, which calls
+// the literal instead.
+func (p *GoParser) SetIndexAnonymous(enabled bool) {
+	p.indexAnonymous = enabled
+}
+
 func (p *GoParser) GetSupportedExtensions() []string {
 	return []string{".go"}
 }
@@ -57,7 +70,7 @@ func (p *GoParser) ParseFile(path string, content []byte) (*models.FileContext,
 	// Parse Go AST and extract functions, types, imports
 	file, err := parser.ParseFile(p.fset, path, content, parser.ParseComments)
 	if err != nil {
-		return nil, err
+		return nil, toParseError(path, err)
 	}
 
 	// Calculate checksum of content
@@ -74,15 +87,18 @@ func (p *GoParser) ParseFile(path string, content []byte) (*models.FileContext,
 	}
 
 	ctx := &models.FileContext{
-		Path:      path,
-		Language:  languageGo,
-		Checksum:  checksum,
-		ModTime:   modTime,
-		Functions: []models.Function{},
-		Types:     []models.TypeDef{},
-		Variables: []models.Variable{},
-		Imports:   []models.Import{},
-		Exports:   []models.Export{},
+		Path:            path,
+		Language:        languageGo,
+		Checksum:        checksum,
+		ModTime:         modTime,
+		Package:         file.Name.Name,
+		Doc:             docText(file.Doc),
+		BuildConstraint: extractBuildConstraint(file),
+		Functions:       []models.Function{},
+		Types:           []models.TypeDef{},
+		Variables:       []models.Variable{},
+		Imports:         []models.Import{},
+		Exports:         []models.Export{},
 	}
 
 	// Extract imports
@@ -98,38 +114,63 @@ func (p *GoParser) ParseFile(path string, content []byte) (*models.FileContext,
 		})
 	}
 
+	// Map specs to their doc comments (a spec may inherit the doc of an ungrouped GenDecl)
+	specDocs := p.collectSpecDocs(file)
+
+	// Function literals by synthetic name, nil unless they are indexed
+	literals := p.anonymousFunctionNames(file)
+
+	// Declarations outside file.Decls sit inside function bodies
+	topLevel := make(map[ast.Decl]bool, len(file.Decls))
+	for _, decl := range file.Decls {
+		topLevel[decl] = true
+	}
+
 	// Extract functions, types, etc. from AST
 	ast.Inspect(file, func(n ast.Node) bool {
 		switch node := n.(type) {
 		case *ast.FuncDecl:
 			// Extract all functions (not just exported ones for testing)
-			ctx.Functions = append(ctx.Functions, p.extractFunction(node, ctx.Imports))
+			fn := p.extractFunction(node, ctx.Imports, literals)
+			fn.IsTest = strings.HasSuffix(path, "_test.go") && isTestFunc(node)
+			ctx.Functions = append(ctx.Functions, fn)
 		case *ast.TypeSpec:
-			ctx.Types = append(ctx.Types, p.extractType(node))
+			typeDef := p.extractType(node)
+			typeDef.Doc = docText(specDocs[node])
+			ctx.Types = append(ctx.Types, typeDef)
 		case *ast.GenDecl:
+			scope := models.ScopeFunction
+			if topLevel[node] {
+				scope = models.ScopePackage
+			}
 			if node.Tok == token.VAR {
 				for _, spec := range node.Specs {
 					if valueSpec, ok := spec.(*ast.ValueSpec); ok {
 						for _, name := range valueSpec.Names {
 							// Extract all variables (not just exported ones)
-							ctx.Variables = append(ctx.Variables, p.extractVariable(name, valueSpec))
+							variable := p.extractVariable(name, valueSpec)
+							variable.Doc = docText(specDocs[valueSpec])
+							variable.Scope = scope
+							ctx.Variables = append(ctx.Variables, variable)
 						}
 					}
 				}
 			} else if node.Tok == token.CONST {
-				for _, spec := range node.Specs {
-					if valueSpec, ok := spec.(*ast.ValueSpec); ok {
-						for _, name := range valueSpec.Names {
-							// Extract all constants (not just exported ones)
-							ctx.Constants = append(ctx.Constants, p.extractConstant(name, valueSpec))
-						}
-					}
+				// Extract all constants (not just exported ones)
+				constants := p.extractConstantGroup(node, path, specDocs)
+				for i := range constants {
+					constants[i].Scope = scope
 				}
+				ctx.Constants = append(ctx.Constants, constants...)
 			}
 		}
 		return true
 	})
 
+	if literals != nil {
+		ctx.Functions = append(ctx.Functions, p.extractAnonymousFunctions(file, ctx.Imports, literals)...)
+	}
+
 	// Extract methods for types (second pass)
 	p.extractMethods(file, ctx)
 
@@ -142,7 +183,29 @@ func (p *GoParser) ParseFile(path string, content []byte) (*models.FileContext,
 	return ctx, nil
 }
 
-func (p *GoParser) extractFunction(node *ast.FuncDecl, imports []models.Import) models.Function {
+// toParseError converts a go/parser error into a ParseError located at its first error
+func toParseError(path string, err error) error {
+	var errorList scanner.ErrorList
+	if !errors.As(err, &errorList) || len(errorList) == 0 {
+		return &models.ParseError{File: path, Kind: models.ParseErrorSyntax, Message: err.Error(), Err: err}
+	}
+
+	first := errorList[0]
+	message := first.Msg
+	if len(errorList) > 1 {
+		message = fmt.Sprintf("%s (and %d more errors)", message, len(errorList)-1)
+	}
+	return &models.ParseError{
+		File:    path,
+		Line:    first.Pos.Line,
+		Column:  first.Pos.Column,
+		Message: message,
+		Kind:    models.ParseErrorSyntax,
+		Err:     err,
+	}
+}
+
+func (p *GoParser) extractFunction(node *ast.FuncDecl, imports []models.Import, literals map[*ast.FuncLit]string) models.Function {
 	fn := models.Function{
 		Name:       node.Name.Name,
 		Parameters: []models.Parameter{},
@@ -169,19 +232,112 @@ func (p *GoParser) extractFunction(node *ast.FuncDecl, imports []models.Import)
 		fn.EndLine = pos.Line
 	}
 
-	// Extract parameters and returns
+	// Extract type parameters, parameters and returns
+	fn.TypeParams = p.extractTypeParams(node.Type.TypeParams)
+	if node.Recv != nil && len(node.Recv.List) > 0 {
+		fn.Receiver = p.extractReceiverType(node.Recv.List[0].Type)
+	}
 	fn.Parameters = p.extractFunctionParameters(node)
 	fn.Returns = p.extractFunctionReturns(node)
 
+	fn.LineCount = models.LineCount(fn.StartLine, fn.EndLine)
+	fn.Complexity = cyclomaticComplexity(node.Body)
+
 	// Extract function calls
-	p.populateFunctionCalls(node, &fn, imports)
+	p.populateFunctionCalls(node, &fn, imports, literals)
 
 	// Build signature
 	fn.Signature = p.buildFunctionSignature(node)
+	fn.Doc = docText(node.Doc)
 
 	return fn
 }
 
+// isTestFunc reports whether a function declaration is a test go test runs:
+// func TestXxx(t *testing.T), where Xxx does not start with a lowercase letter
+func isTestFunc(node *ast.FuncDecl) bool {
+	name, found := strings.CutPrefix(node.Name.Name, "Test")
+	if !found || node.Recv != nil || node.Type.TypeParams != nil {
+		return false
+	}
+	if first, _ := utf8.DecodeRuneInString(name); name != "" && unicode.IsLower(first) {
+		return false
+	}
+
+	params := node.Type.Params.List
+	if len(params) != 1 || len(params[0].Names) > 1 || node.Type.Results != nil {
+		return false
+	}
+	star, ok := params[0].Type.(*ast.StarExpr)
+	if !ok {
+		return false
+	}
+	selector, ok := star.X.(*ast.SelectorExpr)
+	if !ok {
+		return false
+	}
+	pkg, ok := selector.X.(*ast.Ident)
+	return ok && pkg.Name == "testing" && selector.Sel.Name == "T"
+}
+
+// collectSpecDocs maps type and value specs to their documentation comments.
+// A spec's own doc comment wins; otherwise an ungrouped declaration's doc is used.
+func (p *GoParser) collectSpecDocs(file *ast.File) map[ast.Spec]*ast.CommentGroup {
+	docs := make(map[ast.Spec]*ast.CommentGroup)
+	for _, decl := range file.Decls {
+		genDecl, ok := decl.(*ast.GenDecl)
+		if !ok {
+			continue
+		}
+		for _, spec := range genDecl.Specs {
+			var doc *ast.CommentGroup
+			switch s := spec.(type) {
+			case *ast.TypeSpec:
+				doc = s.Doc
+			case *ast.ValueSpec:
+				doc = s.Doc
+			default:
+				continue
+			}
+			if doc == nil && !genDecl.Lparen.IsValid() {
+				doc = genDecl.Doc
+			}
+			if doc != nil {
+				docs[spec] = doc
+			}
+		}
+	}
+	return docs
+}
+
+// docText returns the text of a doc comment with comment markers removed.
+// Line structure is preserved; surrounding whitespace is trimmed.
+func docText(doc *ast.CommentGroup) string {
+	if doc == nil {
+		return ""
+	}
+	return strings.TrimSpace(doc.Text())
+}
+
+// extractTypeParams extracts generic type parameters and their constraints from a field list
+func (p *GoParser) extractTypeParams(fields *ast.FieldList) []models.TypeParam {
+	if fields == nil {
+		return nil
+	}
+
+	var typeParams []models.TypeParam
+	for _, field := range fields.List {
+		constraint := types.ExprString(field.Type)
+		for _, name := range field.Names {
+			typeParams = append(typeParams, models.TypeParam{
+				Name:       name.Name,
+				Constraint: constraint,
+			})
+		}
+	}
+	return typeParams
+}
+
 // extractFunctionParameters extracts parameter information from a function declaration
 func (p *GoParser) extractFunctionParameters(node *ast.FuncDecl) []models.Parameter {
 	var parameters []models.Parameter
@@ -227,13 +383,15 @@ func (p *GoParser) extractFunctionReturns(node *ast.FuncDecl) []models.Type {
 }
 
 // populateFunctionCalls extracts function calls from the body and populates call fields
-func (p *GoParser) populateFunctionCalls(node *ast.FuncDecl, fn *models.Function, imports []models.Import) {
+func (p *GoParser) populateFunctionCalls(
+	node *ast.FuncDecl, fn *models.Function, imports []models.Import, literals map[*ast.FuncLit]string,
+) {
 	if node.Body != nil {
 		// Extract calls for deprecated field (backward compatibility)
-		fn.Calls = p.extractFunctionCalls(node.Body)
+		fn.Calls = p.extractFunctionCalls(node.Body, literals)
 
 		// Extract calls with metadata for enhanced fields
-		callsWithMetadata := p.extractFunctionCallsWithMetadata(node.Body, imports)
+		callsWithMetadata := p.extractFunctionCallsWithMetadata(node.Body, imports, literals)
 
 		// Store call metadata for enrichment phase
 		fn.LocalCallsWithMetadata = callsWithMetadata
@@ -263,6 +421,7 @@ func (p *GoParser) extractType(node *ast.TypeSpec) models.TypeDef {
 		Methods:  []models.Method{},
 		Embedded: []string{},
 	}
+	typeDef.TypeParams = p.extractTypeParams(node.TypeParams)
 
 	// Extract position information
 	if node.Pos().IsValid() {
@@ -343,6 +502,51 @@ func (p *GoParser) extractConstant(name *ast.Ident, spec *ast.ValueSpec) models.
 	}
 }
 
+// extractConstantGroup extracts the constants of a const declaration.
+// Specs without values repeat the previous expression and type, as the Go spec
+// defines for iota enumerations, and constants of a parenthesised block share a GroupID.
+func (p *GoParser) extractConstantGroup(decl *ast.GenDecl, path string, specDocs map[ast.Spec]*ast.CommentGroup) []models.Constant {
+	var constants []models.Constant
+
+	groupID := ""
+	if decl.Lparen.IsValid() {
+		groupID = fmt.Sprintf("%s:%d", path, p.fset.Position(decl.Pos()).Line)
+	}
+
+	var inheritedType ast.Expr
+	var inheritedValues []ast.Expr
+	for _, spec := range decl.Specs {
+		valueSpec, ok := spec.(*ast.ValueSpec)
+		if !ok {
+			continue
+		}
+
+		implicit := len(valueSpec.Values) == 0
+		if !implicit {
+			inheritedType = valueSpec.Type
+			inheritedValues = valueSpec.Values
+		}
+
+		for i, name := range valueSpec.Names {
+			constant := p.extractConstant(name, valueSpec)
+			if i < len(inheritedValues) {
+				constant.Value = types.ExprString(inheritedValues[i])
+				if implicit {
+					constant.Type = p.inferTypeFromValue(inheritedValues[i])
+				}
+			}
+			if implicit && inheritedType != nil {
+				constant.Type = p.typeToString(inheritedType)
+			}
+			constant.Doc = docText(specDocs[valueSpec])
+			constant.GroupID = groupID
+			constants = append(constants, constant)
+		}
+	}
+
+	return constants
+}
+
 // extractValueSpecInfo extracts common information from a ValueSpec
 func (p *GoParser) extractValueSpecInfo(name *ast.Ident, spec *ast.ValueSpec) (typeName string, startLine, endLine int) {
 	if spec.Type != nil {
@@ -458,6 +662,7 @@ func (p *GoParser) extractInterfaceMethod(name *ast.Ident, field *ast.Field) mod
 
 		method.Signature = p.buildMethodSignature(name.Name, funcType)
 	}
+	method.Doc = docText(field.Doc)
 
 	return method
 }
@@ -470,6 +675,16 @@ func (p *GoParser) typeToString(expr ast.Expr) string {
 		return p.typeToString(t.X) + "." + t.Sel.Name
 	case *ast.StarExpr:
 		return "*" + p.typeToString(t.X)
+	case *ast.Ellipsis:
+		return "..." + p.typeToString(t.Elt)
+	case *ast.IndexExpr:
+		return p.typeToString(t.X) + "[" + p.typeToString(t.Index) + "]"
+	case *ast.IndexListExpr:
+		typeArgs := make([]string, len(t.Indices))
+		for i, index := range t.Indices {
+			typeArgs[i] = p.typeToString(index)
+		}
+		return p.typeToString(t.X) + "[" + strings.Join(typeArgs, ", ") + "]"
 	case *ast.ArrayType:
 		if t.Len == nil {
 			return "[]" + p.typeToString(t.Elt)
@@ -548,6 +763,7 @@ func (p *GoParser) buildFunctionSignature(node *ast.FuncDecl) string {
 	}
 
 	sig.WriteString(node.Name.Name)
+	sig.WriteString(models.FormatTypeParams(p.extractTypeParams(node.Type.TypeParams)))
 	sig.WriteString(p.buildFuncTypeSignature(node.Type))
 
 	return sig.String()
@@ -632,6 +848,11 @@ func (p *GoParser) extractReceiverType(expr ast.Expr) string {
 		return t.Name
 	case *ast.StarExpr:
 		return p.extractReceiverType(t.X)
+	case *ast.IndexExpr:
+		// Receiver of a generic type, e.g. (s *Set[T])
+		return p.extractReceiverType(t.X)
+	case *ast.IndexListExpr:
+		return p.extractReceiverType(t.X)
 	default:
 		return ""
 	}
@@ -642,6 +863,7 @@ func (p *GoParser) extractMethodFromFunc(node *ast.FuncDecl) models.Method {
 		Name:       node.Name.Name,
 		Parameters: []models.Parameter{},
 		Returns:    []models.Type{},
+		Receiver:   p.extractReceiverType(node.Recv.List[0].Type),
 	}
 
 	// Extract position information
@@ -685,19 +907,32 @@ func (p *GoParser) extractMethodFromFunc(node *ast.FuncDecl) models.Method {
 		}
 	}
 
+	method.LineCount = models.LineCount(method.StartLine, method.EndLine)
+	method.Complexity = cyclomaticComplexity(node.Body)
+
 	// Build signature
 	method.Signature = p.buildFunctionSignature(node)
+	method.Doc = docText(node.Doc)
 
 	return method
 }
 
-// extractFunctionCalls analyzes a function body to find all function calls
-func (p *GoParser) extractFunctionCalls(body *ast.BlockStmt) []string {
+// extractFunctionCalls analyzes a function body to find all function calls. Indexed
+// function literals, those in literals, count as a call to the literal; the calls they
+// make are their own.
+func (p *GoParser) extractFunctionCalls(body *ast.BlockStmt, literals map[*ast.FuncLit]string) []string {
 	var calls []string
 	callMap := make(map[string]bool) // To avoid duplicates
 
 	ast.Inspect(body, func(n ast.Node) bool {
-		if node, ok := n.(*ast.CallExpr); ok {
+		if lit, ok := n.(*ast.FuncLit); ok && literals != nil {
+			if !callMap[literals[lit]] {
+				calls = append(calls, literals[lit])
+				callMap[literals[lit]] = true
+			}
+			return false
+		}
+		if node, ok := n.(*ast.CallExpr); ok && !isIndexedLiteralCall(node, literals) {
 			callName := p.extractCallName(node.Fun)
 			if callName != "" && !callMap[callName] {
 				calls = append(calls, callName)
@@ -731,13 +966,24 @@ func (p *GoParser) extractCallName(expr ast.Expr) string {
 	}
 }
 
-// extractFunctionCallsWithMetadata analyzes a function body to find all function calls with metadata
-func (p *GoParser) extractFunctionCallsWithMetadata(body *ast.BlockStmt, imports []models.Import) []models.CallReference {
+// extractFunctionCallsWithMetadata analyzes a function body to find all function calls with
+// metadata, treating indexed function literals as extractFunctionCalls does
+func (p *GoParser) extractFunctionCallsWithMetadata(
+	body *ast.BlockStmt, imports []models.Import, literals map[*ast.FuncLit]string,
+) []models.CallReference {
 	var calls []models.CallReference
 	callMap := make(map[string]models.CallReference) // Deduplicate by name but keep metadata
 
 	ast.Inspect(body, func(n ast.Node) bool {
-		if callExpr, ok := n.(*ast.CallExpr); ok {
+		if lit, ok := n.(*ast.FuncLit); ok && literals != nil {
+			callMap[literals[lit]] = models.CallReference{
+				FunctionName: literals[lit],
+				Line:         p.fset.Position(lit.Pos()).Line,
+				CallType:     models.CallTypeFunction,
+			}
+			return false
+		}
+		if callExpr, ok := n.(*ast.CallExpr); ok && !isIndexedLiteralCall(callExpr, literals) {
 			callName := p.extractCallName(callExpr.Fun)
 			if callName != "" {
 				pos := p.fset.Position(callExpr.Pos())
@@ -910,9 +1156,9 @@ func (p *GoParser) extractExports(ctx *models.FileContext) {
 		}
 	}
 
-	// Extract exported variables
+	// Extract exported variables; locals are never exported whatever their case
 	for _, variable := range ctx.Variables {
-		if isExported(variable.Name) {
+		if variable.Scope == models.ScopePackage && isExported(variable.Name) {
 			ctx.Exports = append(ctx.Exports, models.Export{
 				Name: variable.Name,
 				Type: variable.Type,
@@ -923,7 +1169,7 @@ func (p *GoParser) extractExports(ctx *models.FileContext) {
 
 	// Extract exported constants
 	for _, constant := range ctx.Constants {
-		if isExported(constant.Name) {
+		if constant.Scope == models.ScopePackage && isExported(constant.Name) {
 			ctx.Exports = append(ctx.Exports, models.Export{
 				Name: constant.Name,
 				Type: constant.Type,
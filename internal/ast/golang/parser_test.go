@@ -1,6 +1,7 @@
 package golang
 
 import (
+	"errors"
 	"testing"
 
 	"repository-context-protocol/internal/models"
@@ -194,7 +195,18 @@ func TestGoParser_InvalidCode(t *testing.T) {
 
 	_, err := parser.ParseFile("invalid.go", []byte(invalidCode))
 	if err == nil {
-		t.Error("Expected error for invalid Go code")
+		t.Fatal("Expected error for invalid Go code")
+	}
+
+	var parseErr *models.ParseError
+	if !errors.As(err, &parseErr) {
+		t.Fatalf("Expected a ParseError, got %T: %v", err, err)
+	}
+	if parseErr.Kind != models.ParseErrorSyntax || parseErr.File != "invalid.go" {
+		t.Errorf("Expected syntax error in invalid.go, got %+v", parseErr)
+	}
+	if parseErr.Line != 3 || parseErr.Column == 0 {
+		t.Errorf("Expected error located on line 3 with a column, got line %d column %d", parseErr.Line, parseErr.Column)
 	}
 }
 
@@ -436,3 +448,50 @@ func localFunction() {}
 		t.Error("Expected to find at least one external call, but found none")
 	}
 }
+
+func TestGoParser_TestFunctions(t *testing.T) {
+	parser := NewGoParser()
+
+	code := `package user
+
+import "testing"
+
+func TestGetUser(t *testing.T) {}
+
+func Test(t *testing.T) {}
+
+func Testify(t *testing.T) {}
+
+func TestHelper(t *testing.T, name string) {}
+
+func BenchmarkGetUser(b *testing.B) {}
+
+func TestWithB(b *testing.B) {}
+
+type suite struct{}
+
+func (s *suite) TestMethod(t *testing.T) {}
+`
+
+	expected := map[string]bool{"TestGetUser": true, "Test": true}
+	fileContext, err := parser.ParseFile("user_test.go", []byte(code))
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+	for _, function := range fileContext.Functions {
+		if function.IsTest != expected[function.Name] {
+			t.Errorf("%s: expected IsTest %v, got %v", function.Name, expected[function.Name], function.IsTest)
+		}
+	}
+
+	// The same declarations outside a _test.go file are not tests
+	fileContext, err = parser.ParseFile("user.go", []byte(code))
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+	for _, function := range fileContext.Functions {
+		if function.IsTest {
+			t.Errorf("%s: expected no tests outside _test.go files", function.Name)
+		}
+	}
+}
@@ -2,6 +2,8 @@ package golang
 
 import (
 	"testing"
+
+	"repository-context-protocol/internal/models"
 )
 
 func TestGoParser_VariableExtraction(t *testing.T) {
@@ -327,3 +329,51 @@ const (
 		}
 	}
 }
+
+func TestGoParser_VariableScope(t *testing.T) {
+	parser := NewGoParser()
+
+	code := `package test
+
+var Registry = map[string]int{}
+
+const Limit = 10
+
+func run() {
+	var Counter int
+	const step = 2
+	Counter += step
+}`
+
+	fileContext, err := parser.ParseFile("scope.go", []byte(code))
+	if err != nil {
+		t.Fatalf("Failed to parse code: %v", err)
+	}
+
+	scopes := make(map[string]string)
+	for _, variable := range fileContext.Variables {
+		scopes[variable.Name] = variable.Scope
+	}
+	for _, constant := range fileContext.Constants {
+		scopes[constant.Name] = constant.Scope
+	}
+
+	expected := map[string]string{
+		"Registry": models.ScopePackage,
+		"Limit":    models.ScopePackage,
+		"Counter":  models.ScopeFunction,
+		"step":     models.ScopeFunction,
+	}
+	for name, scope := range expected {
+		if scopes[name] != scope {
+			t.Errorf("Expected %s to have scope %q, got %q", name, scope, scopes[name])
+		}
+	}
+
+	// Locals are not exported even when capitalised
+	for _, export := range fileContext.Exports {
+		if export.Name == "Counter" {
+			t.Error("Expected local variable Counter not to be exported")
+		}
+	}
+}
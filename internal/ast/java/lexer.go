@@ -0,0 +1,244 @@
+package java
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"repository-context-protocol/internal/models"
+)
+
+// tokenKind classifies lexical tokens
+type tokenKind int
+
+const (
+	tokenIdent tokenKind = iota
+	tokenNumber
+	tokenString
+	tokenChar
+	tokenPunct
+	tokenEOF
+)
+
+// token is a single lexical token with its source line.
+// Doc holds the Javadoc comment immediately preceding the token, if any.
+type token struct {
+	kind tokenKind
+	text string
+	line int
+	doc  string
+}
+
+// multiCharPuncts are the operators the declaration parser needs to see as one token.
+// Everything else, including '>' runs, is emitted one character at a time so that
+// nested generic arguments such as List<List<String>> close correctly.
+var multiCharPuncts = []string{"...", "->", "::"}
+
+// lexer converts Java source into tokens, dropping comments and whitespace
+type lexer struct {
+	src        string
+	pos        int
+	line       int
+	pendingDoc string
+	tokens     []token
+}
+
+// tokenize splits Java source into tokens terminated by an EOF token
+func tokenize(src string) ([]token, error) {
+	l := &lexer{src: src, line: 1}
+	for {
+		if err := l.skipWhitespaceAndComments(); err != nil {
+			return nil, err
+		}
+		if l.pos >= len(l.src) {
+			break
+		}
+		if err := l.lexToken(); err != nil {
+			return nil, err
+		}
+	}
+	l.tokens = append(l.tokens, token{kind: tokenEOF, line: l.line})
+	return l.tokens, nil
+}
+
+// skipWhitespaceAndComments advances past whitespace and comments, remembering Javadoc
+func (l *lexer) skipWhitespaceAndComments() error {
+	for l.pos < len(l.src) {
+		c := l.src[l.pos]
+		switch {
+		case c == '\n':
+			l.line++
+			l.pos++
+		case c == ' ' || c == '\t' || c == '\r' || c == '\f':
+			l.pos++
+		case strings.HasPrefix(l.src[l.pos:], "//"):
+			end := strings.IndexByte(l.src[l.pos:], '\n')
+			if end < 0 {
+				l.pos = len(l.src)
+			} else {
+				l.pos += end
+			}
+		case strings.HasPrefix(l.src[l.pos:], "/*"):
+			end := strings.Index(l.src[l.pos+2:], "*/")
+			if end < 0 {
+				return syntaxErrorf(l.line, "unterminated comment")
+			}
+			comment := l.src[l.pos : l.pos+2+end+2]
+			if strings.HasPrefix(comment, "/**") && comment != "/**/" {
+				l.pendingDoc = javadocText(comment)
+			}
+			l.line += strings.Count(comment, "\n")
+			l.pos += len(comment)
+		default:
+			return nil
+		}
+	}
+	return nil
+}
+
+// lexToken reads one token at the current position
+func (l *lexer) lexToken() error {
+	start := l.pos
+	line := l.line
+	c := l.src[l.pos]
+
+	var kind tokenKind
+	switch {
+	case strings.HasPrefix(l.src[l.pos:], `"""`):
+		if err := l.skipTextBlock(); err != nil {
+			return err
+		}
+		kind = tokenString
+	case c == '"' || c == '\'':
+		if err := l.skipQuoted(c); err != nil {
+			return err
+		}
+		kind = tokenString
+		if c == '\'' {
+			kind = tokenChar
+		}
+	case isDigit(c) || (c == '.' && l.pos+1 < len(l.src) && isDigit(l.src[l.pos+1])):
+		l.skipNumber()
+		kind = tokenNumber
+	case isIdentStart(l.src[l.pos:]):
+		l.skipIdent()
+		kind = tokenIdent
+	default:
+		kind = tokenPunct
+		l.pos++
+		for _, punct := range multiCharPuncts {
+			if strings.HasPrefix(l.src[start:], punct) {
+				l.pos = start + len(punct)
+				break
+			}
+		}
+	}
+
+	l.tokens = append(l.tokens, token{kind: kind, text: l.src[start:l.pos], line: line, doc: l.pendingDoc})
+	l.pendingDoc = ""
+	return nil
+}
+
+// skipQuoted advances past a string or character literal
+func (l *lexer) skipQuoted(quote byte) error {
+	l.pos++
+	for l.pos < len(l.src) {
+		switch l.src[l.pos] {
+		case '\\':
+			l.pos += 2
+		case '\n':
+			return syntaxErrorf(l.line, "unterminated literal")
+		case quote:
+			l.pos++
+			return nil
+		default:
+			l.pos++
+		}
+	}
+	return syntaxErrorf(l.line, "unterminated literal")
+}
+
+// skipTextBlock advances past a """ text block
+func (l *lexer) skipTextBlock() error {
+	startLine := l.line
+	l.pos += 3
+	for l.pos < len(l.src) {
+		switch {
+		case l.src[l.pos] == '\\':
+			l.pos += 2
+		case strings.HasPrefix(l.src[l.pos:], `"""`):
+			l.pos += 3
+			return nil
+		default:
+			if l.src[l.pos] == '\n' {
+				l.line++
+			}
+			l.pos++
+		}
+	}
+	return syntaxErrorf(startLine, "unterminated text block")
+}
+
+// skipNumber advances past a numeric literal, including exponents and suffixes
+func (l *lexer) skipNumber() {
+	isHex := strings.HasPrefix(strings.ToLower(l.src[l.pos:]), "0x")
+	for l.pos < len(l.src) {
+		c := l.src[l.pos]
+		prev := byte(0)
+		if l.pos > 0 {
+			prev = l.src[l.pos-1] | 0x20 // lower-case ASCII letters
+		}
+		switch {
+		case isDigit(c) || isLetter(c) || c == '_' || c == '.':
+			l.pos++
+		case (c == '+' || c == '-') && ((!isHex && prev == 'e') || (isHex && prev == 'p')):
+			l.pos++
+		default:
+			return
+		}
+	}
+}
+
+// skipIdent advances past an identifier or keyword
+func (l *lexer) skipIdent() {
+	for l.pos < len(l.src) {
+		r, size := utf8.DecodeRuneInString(l.src[l.pos:])
+		if r != '_' && r != '$' && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			return
+		}
+		l.pos += size
+	}
+}
+
+// isIdentStart reports whether s begins with a Java identifier start character
+func isIdentStart(s string) bool {
+	r, _ := utf8.DecodeRuneInString(s)
+	return r == '_' || r == '$' || unicode.IsLetter(r)
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isLetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// javadocText strips comment markers and leading asterisks from a Javadoc comment
+func javadocText(comment string) string {
+	body := strings.TrimSuffix(strings.TrimPrefix(comment, "/**"), "*/")
+	lines := strings.Split(body, "\n")
+	for i, line := range lines {
+		line = strings.TrimSpace(line)
+		line = strings.TrimPrefix(line, "*")
+		lines[i] = strings.TrimPrefix(line, " ")
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// syntaxErrorf formats a syntax error at a source line.
+// ParseFile fills in the file path.
+func syntaxErrorf(line int, format string, args ...interface{}) error {
+	return &models.ParseError{Line: line, Kind: models.ParseErrorSyntax, Message: fmt.Sprintf(format, args...)}
+}
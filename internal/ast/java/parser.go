@@ -0,0 +1,1070 @@
+package java
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"repository-context-protocol/internal/models"
+)
+
+const (
+	languageJava  = "java"
+	extensionJava = ".java"
+
+	kindClass     = "class"
+	kindInterface = "interface"
+	kindEnum      = "enum"
+	kindBasic     = "basic"
+	kindComposite = "composite"
+	kindNamed     = "named"
+
+	typeVoid = "void"
+)
+
+// javaModifiers are the declaration modifiers recognised before a member or type
+var javaModifiers = map[string]bool{
+	"public": true, "protected": true, "private": true, "static": true, "final": true,
+	"abstract": true, "native": true, "synchronized": true, "transient": true,
+	"volatile": true, "strictfp": true, "default": true, "sealed": true,
+}
+
+// javaPrimitives are the built-in primitive types
+var javaPrimitives = map[string]bool{
+	"boolean": true, "byte": true, "char": true, "short": true,
+	"int": true, "long": true, "float": true, "double": true,
+}
+
+// nonCallKeywords are keywords that may be followed by '(' without being a call
+var nonCallKeywords = map[string]bool{
+	"if": true, "for": true, "while": true, "switch": true, "catch": true,
+	"synchronized": true, "return": true, "new": true, "throw": true,
+	"this": true, "super": true, "try": true, "assert": true, "case": true,
+	"yield": true, "instanceof": true, "else": true, "do": true,
+}
+
+// JavaParser implements the LanguageParser interface for Java files.
+// It is a lightweight declaration parser: type, member and import structure is
+// parsed fully, while method bodies are only scanned for call expressions.
+type JavaParser struct{}
+
+// NewJavaParser creates a new Java parser instance
+func NewJavaParser() *JavaParser {
+	return &JavaParser{}
+}
+
+// GetSupportedExtensions returns the file extensions supported by this parser
+func (p *JavaParser) GetSupportedExtensions() []string {
+	return []string{extensionJava}
+}
+
+// GetLanguageName returns the name of the language this parser handles
+func (p *JavaParser) GetLanguageName() string {
+	return languageJava
+}
+
+// ParseFile parses a Java file and returns a FileContext
+func (p *JavaParser) ParseFile(path string, content []byte) (*models.FileContext, error) {
+	tokens, err := tokenize(string(content))
+	if err != nil {
+		return nil, withPath(err, path)
+	}
+
+	// Calculate checksum of content
+	hash := sha256.Sum256(content)
+	checksum := fmt.Sprintf("%x", hash)
+
+	// Get modification time
+	var modTime time.Time
+	if fileInfo, err := os.Stat(path); err == nil {
+		modTime = fileInfo.ModTime()
+	} else {
+		// If file doesn't exist (e.g., in-memory parsing), use current time
+		modTime = time.Now()
+	}
+
+	ctx := &models.FileContext{
+		Path:      path,
+		Language:  languageJava,
+		Checksum:  checksum,
+		ModTime:   modTime,
+		Functions: []models.Function{},
+		Types:     []models.TypeDef{},
+		Variables: []models.Variable{},
+		Constants: []models.Constant{},
+		Imports:   []models.Import{},
+		Exports:   []models.Export{},
+	}
+
+	fp := &fileParser{tokens: tokens, ctx: ctx}
+	if err := fp.parseCompilationUnit(); err != nil {
+		return nil, withPath(err, path)
+	}
+
+	// Build call graph relationships (second pass)
+	p.buildCallGraph(ctx)
+
+	return ctx, nil
+}
+
+// buildCallGraph builds the CalledBy relationships between methods in the file
+func (p *JavaParser) buildCallGraph(ctx *models.FileContext) {
+	funcMap := make(map[string]int)
+	for i := range ctx.Functions {
+		funcMap[ctx.Functions[i].Name] = i
+	}
+
+	for i := range ctx.Functions {
+		caller := &ctx.Functions[i]
+
+		// Unqualified and this-qualified calls resolve to methods in the same file
+		for _, calledName := range caller.LocalCalls {
+			if targetIdx, exists := funcMap[calledName]; exists {
+				addUnique(&ctx.Functions[targetIdx].CalledBy, caller.Name)
+				addUnique(&ctx.Functions[targetIdx].LocalCallers, caller.Name)
+			}
+		}
+
+		// Qualified calls like "helper.format" are linked by method name for the deprecated field
+		for _, calledName := range caller.Calls {
+			if idx := strings.LastIndex(calledName, "."); idx >= 0 {
+				if targetIdx, exists := funcMap[calledName[idx+1:]]; exists {
+					addUnique(&ctx.Functions[targetIdx].CalledBy, caller.Name)
+				}
+			}
+		}
+	}
+}
+
+// addUnique appends value to list if it is not already present
+func addUnique(list *[]string, value string) {
+	if !slices.Contains(*list, value) {
+		*list = append(*list, value)
+	}
+}
+
+// fileParser holds the parsing state for a single compilation unit
+type fileParser struct {
+	tokens []token
+	pos    int
+	ctx    *models.FileContext
+}
+
+// withPath attaches the file path to a parse error
+func withPath(err error, path string) error {
+	var parseErr *models.ParseError
+	if errors.As(err, &parseErr) {
+		parseErr.File = path
+		return parseErr
+	}
+	return fmt.Errorf("%s: %w", path, err)
+}
+
+// declHeader holds the annotations and modifiers that precede a declaration
+type declHeader struct {
+	start       token
+	modifiers   []string
+	annotations []string
+}
+
+func (h *declHeader) has(modifier string) bool {
+	return slices.Contains(h.modifiers, modifier)
+}
+
+// peek returns the token offset positions ahead without consuming it
+func (fp *fileParser) peek(offset int) token {
+	if fp.pos+offset >= len(fp.tokens) {
+		return fp.tokens[len(fp.tokens)-1]
+	}
+	return fp.tokens[fp.pos+offset]
+}
+
+// next consumes and returns the current token
+func (fp *fileParser) next() token {
+	tok := fp.peek(0)
+	if tok.kind != tokenEOF {
+		fp.pos++
+	}
+	return tok
+}
+
+// at reports whether the current token is the punctuation or keyword text
+func (fp *fileParser) at(text string) bool {
+	tok := fp.peek(0)
+	return tok.kind != tokenEOF && tok.kind != tokenString && tok.kind != tokenChar && tok.text == text
+}
+
+// expect consumes the token text or returns a syntax error
+func (fp *fileParser) expect(text string) (token, error) {
+	if !fp.at(text) {
+		return token{}, fp.unexpected(fmt.Sprintf("expected '%s'", text))
+	}
+	return fp.next(), nil
+}
+
+// expectIdent consumes an identifier or returns a syntax error
+func (fp *fileParser) expectIdent() (token, error) {
+	if fp.peek(0).kind != tokenIdent {
+		return token{}, fp.unexpected("expected identifier")
+	}
+	return fp.next(), nil
+}
+
+// unexpected builds a syntax error describing the current token
+func (fp *fileParser) unexpected(reason string) error {
+	tok := fp.peek(0)
+	if tok.kind == tokenEOF {
+		return syntaxErrorf(tok.line, "%s, found end of file", reason)
+	}
+	return syntaxErrorf(tok.line, "%s, found '%s'", reason, tok.text)
+}
+
+// parseCompilationUnit parses package, imports and top-level type declarations
+func (fp *fileParser) parseCompilationUnit() error {
+	// Package annotations precede the package declaration
+	start := fp.pos
+	if _, err := fp.parseHeader(); err != nil {
+		return err
+	}
+	if fp.at("package") {
+		fp.next()
+		name, err := fp.parseQualifiedName()
+		if err != nil {
+			return err
+		}
+		fp.ctx.Package = name
+		if _, err := fp.expect(";"); err != nil {
+			return err
+		}
+	} else {
+		fp.pos = start
+	}
+
+	for fp.at("import") {
+		if err := fp.parseImport(); err != nil {
+			return err
+		}
+	}
+
+	for fp.peek(0).kind != tokenEOF {
+		if fp.at(";") {
+			fp.next()
+			continue
+		}
+		if fp.at("module") || (fp.at("open") && fp.peek(1).text == "module") {
+			// module-info.java carries no indexable declarations
+			return fp.skipModuleDeclaration()
+		}
+		header, err := fp.parseHeader()
+		if err != nil {
+			return err
+		}
+		if !fp.atTypeDeclaration() {
+			return fp.unexpected("expected class, interface, enum or record declaration")
+		}
+		if err := fp.parseTypeDeclaration(header, nil); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// parseImport parses a single import declaration
+func (fp *fileParser) parseImport() error {
+	fp.next() // import
+	if fp.at("static") {
+		fp.next()
+	}
+	path, err := fp.parseQualifiedName()
+	if err != nil {
+		return err
+	}
+	if fp.at(".") && fp.peek(1).text == "*" {
+		fp.next()
+		fp.next()
+		path += ".*"
+	}
+	if _, err := fp.expect(";"); err != nil {
+		return err
+	}
+	fp.ctx.Imports = append(fp.ctx.Imports, models.Import{Path: path})
+	return nil
+}
+
+// skipModuleDeclaration consumes a module declaration through its closing brace
+func (fp *fileParser) skipModuleDeclaration() error {
+	for !fp.at("{") {
+		if fp.peek(0).kind == tokenEOF {
+			return fp.unexpected("expected '{'")
+		}
+		fp.next()
+	}
+	_, err := fp.skipBalanced("{", "}")
+	return err
+}
+
+// parseQualifiedName parses a dotted name such as java.util.List
+func (fp *fileParser) parseQualifiedName() (string, error) {
+	first, err := fp.expectIdent()
+	if err != nil {
+		return "", err
+	}
+	parts := []string{first.text}
+	for fp.at(".") && fp.peek(1).kind == tokenIdent {
+		fp.next()
+		parts = append(parts, fp.next().text)
+	}
+	return strings.Join(parts, "."), nil
+}
+
+// parseHeader parses annotations and modifiers preceding a declaration
+func (fp *fileParser) parseHeader() (*declHeader, error) {
+	header := &declHeader{start: fp.peek(0)}
+	for {
+		switch {
+		case fp.at("@") && fp.peek(1).text != "interface":
+			annotation, err := fp.parseAnnotation()
+			if err != nil {
+				return nil, err
+			}
+			header.annotations = append(header.annotations, annotation)
+		case fp.at("non") && fp.peek(1).text == "-" && fp.peek(2).text == "sealed":
+			fp.pos += 3
+			header.modifiers = append(header.modifiers, "non-sealed")
+		case fp.peek(0).kind == tokenIdent && javaModifiers[fp.peek(0).text] && fp.peek(1).text != ":":
+			header.modifiers = append(header.modifiers, fp.next().text)
+		default:
+			return header, nil
+		}
+	}
+}
+
+// parseAnnotation parses an annotation such as @Override or @RequestMapping("/x")
+func (fp *fileParser) parseAnnotation() (string, error) {
+	fp.next() // @
+	name, err := fp.parseQualifiedName()
+	if err != nil {
+		return "", err
+	}
+	annotation := "@" + name
+	if fp.at("(") {
+		args, err := fp.skipBalanced("(", ")")
+		if err != nil {
+			return "", err
+		}
+		annotation += joinTokens(args)
+	}
+	return annotation, nil
+}
+
+// atTypeDeclaration reports whether the current token starts a type declaration
+func (fp *fileParser) atTypeDeclaration() bool {
+	switch {
+	case fp.at("class"), fp.at("interface"), fp.at("enum"):
+		return true
+	case fp.at("@") && fp.peek(1).text == "interface":
+		return true
+	case fp.at("record") && fp.peek(1).kind == tokenIdent && (fp.peek(2).text == "(" || fp.peek(2).text == "<"):
+		return true
+	}
+	return false
+}
+
+// parseTypeDeclaration parses a class, interface, enum, record or annotation type
+func (fp *fileParser) parseTypeDeclaration(header *declHeader, outer *models.TypeDef) error {
+	keyword := fp.next().text
+	if keyword == "@" {
+		keyword = fp.next().text // @interface
+	}
+
+	name, err := fp.expectIdent()
+	if err != nil {
+		return err
+	}
+
+	typeDef := models.TypeDef{
+		Name:       name.text,
+		Kind:       typeKindForKeyword(keyword),
+		StartLine:  header.start.line,
+		Doc:        header.start.doc,
+		Decorators: header.annotations,
+	}
+
+	if fp.at("<") {
+		if _, err := fp.skipBalanced("<", ">"); err != nil {
+			return err
+		}
+	}
+
+	// Record components become fields
+	if keyword == "record" {
+		components, err := fp.parseParameters()
+		if err != nil {
+			return err
+		}
+		for _, component := range components {
+			typeDef.Fields = append(typeDef.Fields, models.Field{Name: component.Name, Type: component.Type})
+		}
+	}
+
+	// extends/implements clauses populate Embedded; permits lists are skipped
+	for fp.at("extends") || fp.at("implements") || fp.at("permits") {
+		clause := fp.next().text
+		for {
+			typeName, err := fp.parseType()
+			if err != nil {
+				return err
+			}
+			if clause != "permits" {
+				typeDef.Embedded = append(typeDef.Embedded, typeName)
+			}
+			if !fp.at(",") {
+				break
+			}
+			fp.next()
+		}
+	}
+
+	// Reserve the slot so outer types precede their nested types
+	index := len(fp.ctx.Types)
+	fp.ctx.Types = append(fp.ctx.Types, models.TypeDef{})
+
+	if _, err := fp.expect("{"); err != nil {
+		return err
+	}
+	if keyword == kindEnum {
+		if err := fp.parseEnumConstants(&typeDef, header); err != nil {
+			return err
+		}
+	}
+	if err := fp.parseClassBody(&typeDef, keyword); err != nil {
+		return err
+	}
+	closing, err := fp.expect("}")
+	if err != nil {
+		return err
+	}
+	typeDef.EndLine = closing.line
+
+	fp.ctx.Types[index] = typeDef
+
+	if header.has("public") || (outer != nil && outer.Kind == kindInterface) {
+		fp.ctx.Exports = append(fp.ctx.Exports, models.Export{Name: typeDef.Name, Type: typeDef.Kind, Kind: "type"})
+	}
+
+	return nil
+}
+
+// typeKindForKeyword maps a declaration keyword to the TypeDef kind.
+// Records are classes and annotation types are interfaces in the Java type system.
+func typeKindForKeyword(keyword string) string {
+	switch keyword {
+	case "interface":
+		return kindInterface
+	case kindEnum:
+		return kindEnum
+	default:
+		return kindClass
+	}
+}
+
+// parseEnumConstants parses the constant list at the start of an enum body
+func (fp *fileParser) parseEnumConstants(typeDef *models.TypeDef, enumHeader *declHeader) error {
+	for !fp.at("}") {
+		if fp.at(";") {
+			fp.next()
+			return nil
+		}
+
+		header, err := fp.parseHeader()
+		if err != nil {
+			return err
+		}
+		name, err := fp.expectIdent()
+		if err != nil {
+			return err
+		}
+		if fp.at("(") {
+			if _, err := fp.skipBalanced("(", ")"); err != nil {
+				return err
+			}
+		}
+		if fp.at("{") {
+			if _, err := fp.skipBalanced("{", "}"); err != nil {
+				return err
+			}
+		}
+
+		typeDef.Fields = append(typeDef.Fields, models.Field{Name: name.text, Type: typeDef.Name})
+		fp.ctx.Constants = append(fp.ctx.Constants, models.Constant{
+			Name:      name.text,
+			Type:      typeDef.Name,
+			StartLine: name.line,
+			EndLine:   name.line,
+			Doc:       header.start.doc,
+			Scope:     models.ScopePackage,
+		})
+		if enumHeader.has("public") {
+			fp.ctx.Exports = append(fp.ctx.Exports, models.Export{Name: name.text, Type: typeDef.Name, Kind: "constant"})
+		}
+
+		if !fp.at(",") {
+			break
+		}
+		fp.next()
+	}
+
+	if fp.at(";") {
+		fp.next()
+	}
+	return nil
+}
+
+// parseClassBody parses members until the closing brace of a type body
+func (fp *fileParser) parseClassBody(typeDef *models.TypeDef, keyword string) error {
+	for !fp.at("}") {
+		if fp.peek(0).kind == tokenEOF {
+			return fp.unexpected("expected '}'")
+		}
+		if fp.at(";") {
+			fp.next()
+			continue
+		}
+
+		// Instance and static initializer blocks
+		if fp.at("{") || (fp.at("static") && fp.peek(1).text == "{") {
+			if fp.at("static") {
+				fp.next()
+			}
+			if _, err := fp.skipBalanced("{", "}"); err != nil {
+				return err
+			}
+			continue
+		}
+
+		header, err := fp.parseHeader()
+		if err != nil {
+			return err
+		}
+
+		if fp.atTypeDeclaration() {
+			if err := fp.parseTypeDeclaration(header, typeDef); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := fp.parseMember(header, typeDef, keyword); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseMember parses a constructor, method or field declaration
+func (fp *fileParser) parseMember(header *declHeader, typeDef *models.TypeDef, keyword string) error {
+	typeParams := ""
+	if fp.at("<") {
+		params, err := fp.skipBalanced("<", ">")
+		if err != nil {
+			return err
+		}
+		typeParams = joinTokens(params)
+	}
+
+	// Constructors, including compact record constructors
+	if fp.peek(0).text == typeDef.Name && (fp.peek(1).text == "(" || (keyword == "record" && fp.peek(1).text == "{")) {
+		name := fp.next()
+		return fp.parseMethod(header, typeDef, keyword, typeParams, "", name)
+	}
+
+	returnType, err := fp.parseType()
+	if err != nil {
+		return err
+	}
+	name, err := fp.expectIdent()
+	if err != nil {
+		return err
+	}
+
+	if fp.at("(") {
+		return fp.parseMethod(header, typeDef, keyword, typeParams, returnType, name)
+	}
+	return fp.parseFields(header, typeDef, keyword, returnType, name)
+}
+
+// parseMethod parses a method or constructor after its name
+func (fp *fileParser) parseMethod(header *declHeader, typeDef *models.TypeDef, keyword, typeParams, returnType string, name token) error {
+	var parameters []models.Parameter
+	if fp.at("(") {
+		params, err := fp.parseParameters()
+		if err != nil {
+			return err
+		}
+		parameters = params
+	}
+
+	// Legacy array dimensions after the parameter list
+	for fp.at("[") && fp.peek(1).text == "]" {
+		fp.next()
+		fp.next()
+		returnType += "[]"
+	}
+
+	var throws []string
+	if fp.at("throws") {
+		fp.next()
+		for {
+			exception, err := fp.parseType()
+			if err != nil {
+				return err
+			}
+			throws = append(throws, exception)
+			if !fp.at(",") {
+				break
+			}
+			fp.next()
+		}
+	}
+
+	// Annotation type elements may declare a default value
+	if fp.at("default") {
+		for !fp.at(";") {
+			if fp.peek(0).kind == tokenEOF {
+				return fp.unexpected("expected ';'")
+			}
+			if _, err := fp.skipBalancedOrNext(); err != nil {
+				return err
+			}
+		}
+	}
+
+	var body []token
+	var endLine int
+	switch {
+	case fp.at("{"):
+		tokens, err := fp.skipBalanced("{", "}")
+		if err != nil {
+			return err
+		}
+		body = tokens
+		endLine = fp.tokens[fp.pos-1].line
+	case fp.at(";"):
+		endLine = fp.next().line
+	default:
+		return fp.unexpected("expected method body or ';'")
+	}
+
+	method := models.Method{
+		Name:       name.text,
+		Signature:  buildSignature(header.modifiers, typeParams, returnType, name.text, parameters, throws),
+		Parameters: parameters,
+		Returns:    returnTypes(returnType),
+		StartLine:  header.start.line,
+		EndLine:    endLine,
+		Doc:        header.start.doc,
+		Decorators: header.annotations,
+	}
+	typeDef.Methods = append(typeDef.Methods, method)
+
+	// Methods with bodies participate in the call graph
+	if body != nil {
+		fn := models.Function{
+			Name:             method.Name,
+			Signature:        method.Signature,
+			Parameters:       method.Parameters,
+			Returns:          method.Returns,
+			StartLine:        method.StartLine,
+			EndLine:          method.EndLine,
+			Doc:              method.Doc,
+			Decorators:       method.Decorators,
+			CalledBy:         []string{},
+			LocalCalls:       []string{},
+			CrossFileCalls:   []models.CallReference{},
+			LocalCallers:     []string{},
+			CrossFileCallers: []models.CallReference{},
+		}
+		fp.populateCalls(body, &fn)
+		fp.ctx.Functions = append(fp.ctx.Functions, fn)
+
+		if header.has("public") || keyword == "interface" {
+			fp.ctx.Exports = append(fp.ctx.Exports, models.Export{Name: fn.Name, Type: fn.Signature, Kind: "function"})
+		}
+	}
+
+	return nil
+}
+
+// parseFields parses one or more field declarators sharing a type
+func (fp *fileParser) parseFields(header *declHeader, typeDef *models.TypeDef, keyword, fieldType string, name token) error {
+	// Interface fields are implicitly public static final
+	isConstant := keyword == "interface" || (header.has("static") && header.has("final"))
+	isPublic := keyword == "interface" || header.has("public")
+
+	for {
+		declType := fieldType
+		for fp.at("[") && fp.peek(1).text == "]" {
+			fp.next()
+			fp.next()
+			declType += "[]"
+		}
+
+		var initializer []token
+		if fp.at("=") {
+			fp.next()
+			tokens, err := fp.parseInitializer()
+			if err != nil {
+				return err
+			}
+			initializer = tokens
+		}
+
+		typeDef.Fields = append(typeDef.Fields, models.Field{Name: name.text, Type: declType})
+		if isConstant {
+			fp.ctx.Constants = append(fp.ctx.Constants, models.Constant{
+				Name:      name.text,
+				Type:      declType,
+				Value:     literalValue(initializer),
+				StartLine: header.start.line,
+				EndLine:   fp.peek(0).line,
+				Doc:       header.start.doc,
+				Scope:     models.ScopePackage,
+			})
+			if isPublic {
+				fp.ctx.Exports = append(fp.ctx.Exports, models.Export{Name: name.text, Type: declType, Kind: "constant"})
+			}
+		}
+
+		if !fp.at(",") {
+			break
+		}
+		fp.next()
+		next, err := fp.expectIdent()
+		if err != nil {
+			return err
+		}
+		name = next
+	}
+
+	_, err := fp.expect(";")
+	return err
+}
+
+// parseInitializer consumes a variable initializer up to the next top-level ',' or ';'.
+// Generic type arguments are consumed whole so their commas don't end the declarator.
+func (fp *fileParser) parseInitializer() ([]token, error) {
+	var initializer []token
+	for !fp.at(",") && !fp.at(";") {
+		switch {
+		case fp.peek(0).kind == tokenEOF:
+			return nil, fp.unexpected("expected ';'")
+		case fp.at("new") && fp.peek(1).kind == tokenIdent:
+			// Instance creation: new HashMap<String, Integer>()
+			initializer = append(initializer, fp.next())
+			start := fp.pos
+			if _, err := fp.parseType(); err != nil {
+				return nil, err
+			}
+			initializer = append(initializer, fp.tokens[start:fp.pos]...)
+			continue
+		case fp.at(".") && fp.peek(1).text == "<":
+			// Explicit generic method arguments: Collections.<String, Integer>emptyMap()
+			initializer = append(initializer, fp.next())
+			tokens, err := fp.skipBalanced("<", ">")
+			if err != nil {
+				return nil, err
+			}
+			initializer = append(initializer, tokens...)
+			continue
+		}
+		tokens, err := fp.skipBalancedOrNext()
+		if err != nil {
+			return nil, err
+		}
+		initializer = append(initializer, tokens...)
+	}
+	return initializer, nil
+}
+
+// parseParameters parses a parenthesised formal parameter list
+func (fp *fileParser) parseParameters() ([]models.Parameter, error) {
+	if _, err := fp.expect("("); err != nil {
+		return nil, err
+	}
+
+	parameters := []models.Parameter{}
+	for !fp.at(")") {
+		if _, err := fp.parseHeader(); err != nil {
+			return nil, err
+		}
+		paramType, err := fp.parseType()
+		if err != nil {
+			return nil, err
+		}
+		if fp.at("...") {
+			fp.next()
+			paramType += "..."
+		}
+		name, err := fp.expectIdent()
+		if err != nil {
+			return nil, err
+		}
+		for fp.at("[") && fp.peek(1).text == "]" {
+			fp.next()
+			fp.next()
+			paramType += "[]"
+		}
+		parameters = append(parameters, models.Parameter{Name: name.text, Type: paramType})
+
+		if !fp.at(",") {
+			break
+		}
+		fp.next()
+	}
+
+	if _, err := fp.expect(")"); err != nil {
+		return nil, err
+	}
+	return parameters, nil
+}
+
+// parseType parses a type reference including generic arguments and array dimensions
+func (fp *fileParser) parseType() (string, error) {
+	// Type annotations such as @NonNull String
+	for fp.at("@") && fp.peek(1).text != "interface" {
+		if _, err := fp.parseAnnotation(); err != nil {
+			return "", err
+		}
+	}
+
+	var typeTokens []token
+	for {
+		name, err := fp.expectIdent()
+		if err != nil {
+			return "", err
+		}
+		typeTokens = append(typeTokens, name)
+		if fp.at("<") {
+			args, err := fp.skipBalanced("<", ">")
+			if err != nil {
+				return "", err
+			}
+			typeTokens = append(typeTokens, args...)
+		}
+		if !fp.at(".") || fp.peek(1).kind != tokenIdent {
+			break
+		}
+		typeTokens = append(typeTokens, fp.next())
+	}
+
+	for fp.at("[") && fp.peek(1).text == "]" {
+		typeTokens = append(typeTokens, fp.next(), fp.next())
+	}
+
+	return joinTokens(typeTokens), nil
+}
+
+// skipBalanced consumes a bracketed group and returns its tokens including the delimiters
+func (fp *fileParser) skipBalanced(open, closing string) ([]token, error) {
+	start := fp.pos
+	if _, err := fp.expect(open); err != nil {
+		return nil, err
+	}
+
+	depth := 1
+	for depth > 0 {
+		tok := fp.peek(0)
+		if tok.kind == tokenEOF {
+			return nil, fp.unexpected(fmt.Sprintf("expected '%s'", closing))
+		}
+		switch {
+		case fp.at(open):
+			depth++
+		case fp.at(closing):
+			depth--
+		case open != "<" && (fp.at(")") || fp.at("]") || fp.at("}")):
+			// A mismatched closing bracket inside a group is a syntax error
+			return nil, fp.unexpected(fmt.Sprintf("expected '%s'", closing))
+		case open != "<" && (fp.at("(") || fp.at("[") || fp.at("{")):
+			if _, err := fp.skipBalancedOrNext(); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		fp.next()
+	}
+
+	return fp.tokens[start:fp.pos], nil
+}
+
+// skipBalancedOrNext consumes a bracketed group if one starts here, otherwise a single token
+func (fp *fileParser) skipBalancedOrNext() ([]token, error) {
+	switch {
+	case fp.at("("):
+		return fp.skipBalanced("(", ")")
+	case fp.at("["):
+		return fp.skipBalanced("[", "]")
+	case fp.at("{"):
+		return fp.skipBalanced("{", "}")
+	case fp.at(")") || fp.at("]") || fp.at("}"):
+		return nil, fp.unexpected("unbalanced bracket")
+	}
+	return []token{fp.next()}, nil
+}
+
+// populateCalls scans a method body for call expressions
+func (fp *fileParser) populateCalls(body []token, fn *models.Function) {
+	seen := make(map[string]bool)
+
+	for i := 1; i+1 < len(body); i++ {
+		tok := body[i]
+		if tok.kind != tokenIdent || body[i+1].text != "(" || nonCallKeywords[tok.text] || javaPrimitives[tok.text] {
+			continue
+		}
+
+		prev := body[i-1]
+		// Skip annotations, method references and local method or class declarations
+		if prev.text == "@" || prev.text == "::" ||
+			(prev.kind == tokenIdent && !nonCallKeywords[prev.text]) ||
+			prev.text == ">" || prev.text == "]" {
+			continue
+		}
+
+		// Skip constructor invocations, including qualified ones: new Outer.Inner()
+		chainStart := i
+		for chainStart >= 2 && body[chainStart-1].text == "." && body[chainStart-2].kind == tokenIdent {
+			chainStart -= 2
+		}
+		if body[chainStart-1].text == "new" {
+			continue
+		}
+
+		callName := tok.text
+		callType := models.CallTypeFunction
+		if prev.text == "." {
+			callType = models.CallTypeMethod
+			if i >= 2 && body[i-2].kind == tokenIdent && (i < 3 || body[i-3].text != ".") {
+				qualifier := body[i-2].text
+				switch {
+				case qualifier == "this":
+					// this.helper() is a call to a method of the same class
+					callType = models.CallTypeFunction
+				case fp.isImportedType(qualifier):
+					callName = qualifier + "." + tok.text
+					callType = models.CallTypeExternal
+				default:
+					callName = qualifier + "." + tok.text
+				}
+			}
+		}
+
+		if seen[callName] {
+			continue
+		}
+		seen[callName] = true
+
+		fn.Calls = append(fn.Calls, callName)
+		fn.LocalCalls = append(fn.LocalCalls, callName)
+		fn.LocalCallsWithMetadata = append(fn.LocalCallsWithMetadata, models.CallReference{
+			FunctionName: callName,
+			Line:         tok.line,
+			CallType:     callType,
+		})
+	}
+
+	// Ensure fields are never nil for JSON serialization
+	if fn.Calls == nil {
+		fn.Calls = []string{}
+	}
+	if fn.LocalCallsWithMetadata == nil {
+		fn.LocalCallsWithMetadata = []models.CallReference{}
+	}
+}
+
+// isImportedType reports whether name is the simple name of a single-type import
+func (fp *fileParser) isImportedType(name string) bool {
+	for _, imp := range fp.ctx.Imports {
+		if strings.HasSuffix(imp.Path, "."+name) {
+			return true
+		}
+	}
+	return false
+}
+
+// buildSignature renders a method declaration without annotations or body
+func buildSignature(modifiers []string, typeParams, returnType, name string, parameters []models.Parameter, throws []string) string {
+	var parts []string
+	parts = append(parts, modifiers...)
+	if typeParams != "" {
+		parts = append(parts, typeParams)
+	}
+	if returnType != "" {
+		parts = append(parts, returnType)
+	}
+
+	params := make([]string, len(parameters))
+	for i, param := range parameters {
+		params[i] = param.Type + " " + param.Name
+	}
+	signature := strings.Join(append(parts, name), " ") + "(" + strings.Join(params, ", ") + ")"
+
+	if len(throws) > 0 {
+		signature += " throws " + strings.Join(throws, ", ")
+	}
+	return signature
+}
+
+// returnTypes converts a declared return type to the model representation
+func returnTypes(returnType string) []models.Type {
+	if returnType == "" || returnType == typeVoid {
+		return []models.Type{}
+	}
+	return []models.Type{{Name: returnType, Kind: typeKind(returnType)}}
+}
+
+// typeKind classifies a Java type reference
+func typeKind(typeName string) string {
+	switch {
+	case javaPrimitives[typeName]:
+		return kindBasic
+	case strings.ContainsAny(typeName, "<[") || strings.HasSuffix(typeName, "..."):
+		return kindComposite
+	default:
+		return kindNamed
+	}
+}
+
+// literalValue returns the initializer text when it is a single literal
+func literalValue(initializer []token) string {
+	switch {
+	case len(initializer) == 1 && initializer[0].kind != tokenPunct:
+		return initializer[0].text
+	case len(initializer) == 2 && initializer[0].text == "-" && initializer[1].kind == tokenNumber:
+		return "-" + initializer[1].text
+	}
+	return ""
+}
+
+// joinTokens reconstructs source text from tokens with conventional spacing
+func joinTokens(tokens []token) string {
+	var builder strings.Builder
+	for i, tok := range tokens {
+		if i > 0 {
+			prev := tokens[i-1]
+			wordPair := prev.kind != tokenPunct && tok.kind != tokenPunct
+			switch {
+			case prev.text == ",":
+				builder.WriteString(" ")
+			case wordPair, prev.text == "?" && tok.kind == tokenIdent, prev.text == "&" || tok.text == "&":
+				builder.WriteString(" ")
+			}
+		}
+		builder.WriteString(tok.text)
+	}
+	return builder.String()
+}
@@ -0,0 +1,406 @@
+package java
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"repository-context-protocol/internal/models"
+)
+
+const sampleJava = `package com.example.users;
+
+import java.util.List;
+import java.util.Map;
+import java.util.concurrent.*;
+import static org.junit.Assert.assertEquals;
+
+/**
+ * Manages users.
+ */
+@Service
+public class UserService extends BaseService implements Repository<User>, AutoCloseable {
+    public static final int MAX_USERS = 100;
+    private static final String PREFIX = "user-";
+    private final Map<String, List<User>> cache = new HashMap<String, List<User>>(), backup = Map.of();
+    protected int count;
+
+    static {
+        System.out.println("loaded");
+    }
+
+    public UserService(Map<String, List<User>> cache) {
+        this.count = 0;
+        init();
+    }
+
+    /**
+     * Finds a user by ID.
+     */
+    @Override
+    public synchronized User findUser(final String id) throws NotFoundException {
+        validate(id);
+        User user = this.lookup(id);
+        List<String> names = List.of(id);
+        return new User.Builder().build();
+    }
+
+    private User lookup(String id) {
+        validate(id);
+        return cache.get(id).get(0);
+    }
+
+    private void validate(String id) {
+        if (id == null) {
+            throw new IllegalArgumentException("id");
+        }
+    }
+
+    private void init() {
+    }
+
+    public static <T extends Comparable<? super T>> List<T> sorted(List<T> items, int... limits) {
+        Runnable r = () -> { validate2(); };
+        return items;
+    }
+
+    protected abstract String describe();
+
+    public enum Status {
+        ACTIVE("a"),
+        INACTIVE("i") {
+            @Override
+            public String toString() { return "inactive"; }
+        };
+
+        private final String code;
+
+        Status(String code) {
+            this.code = code;
+        }
+    }
+
+    interface Listener {
+        int PRIORITY = 5;
+
+        void onEvent(String event);
+
+        default void onStart() {
+            onEvent("start");
+        }
+    }
+
+    public record Point(int x, int y) {
+        public Point {
+            check(x);
+        }
+    }
+}
+`
+
+func parseSample(t *testing.T) *models.FileContext {
+	t.Helper()
+	fileContext, err := NewJavaParser().ParseFile("UserService.java", []byte(sampleJava))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	return fileContext
+}
+
+func findType(fileContext *models.FileContext, name string) *models.TypeDef {
+	for i := range fileContext.Types {
+		if fileContext.Types[i].Name == name {
+			return &fileContext.Types[i]
+		}
+	}
+	return nil
+}
+
+func findFunction(fileContext *models.FileContext, name string) *models.Function {
+	for i := range fileContext.Functions {
+		if fileContext.Functions[i].Name == name {
+			return &fileContext.Functions[i]
+		}
+	}
+	return nil
+}
+
+func TestJavaParser_Interface(t *testing.T) {
+	parser := NewJavaParser()
+
+	if parser.GetLanguageName() != "java" {
+		t.Errorf("Expected language 'java', got %s", parser.GetLanguageName())
+	}
+	extensions := parser.GetSupportedExtensions()
+	if len(extensions) != 1 || extensions[0] != ".java" {
+		t.Errorf("Expected [.java], got %v", extensions)
+	}
+}
+
+func TestJavaParser_PackageAndImports(t *testing.T) {
+	fileContext := parseSample(t)
+
+	if fileContext.Language != "java" {
+		t.Errorf("Expected language 'java', got %s", fileContext.Language)
+	}
+	if fileContext.Package != "com.example.users" {
+		t.Errorf("Expected package com.example.users, got %s", fileContext.Package)
+	}
+
+	var paths []string
+	for _, imp := range fileContext.Imports {
+		paths = append(paths, imp.Path)
+	}
+	expected := []string{"java.util.List", "java.util.Map", "java.util.concurrent.*", "org.junit.Assert.assertEquals"}
+	if !slices.Equal(paths, expected) {
+		t.Errorf("Expected imports %v, got %v", expected, paths)
+	}
+}
+
+func TestJavaParser_Types(t *testing.T) {
+	fileContext := parseSample(t)
+
+	expectedKinds := map[string]string{
+		"UserService": "class",
+		"Status":      "enum",
+		"Listener":    "interface",
+		"Point":       "class",
+	}
+	for name, kind := range expectedKinds {
+		typeDef := findType(fileContext, name)
+		if typeDef == nil {
+			t.Errorf("Expected type %s to be extracted", name)
+			continue
+		}
+		if typeDef.Kind != kind {
+			t.Errorf("Expected %s to have kind %s, got %s", name, kind, typeDef.Kind)
+		}
+	}
+	if fileContext.Types[0].Name != "UserService" {
+		t.Errorf("Expected outer type first, got %s", fileContext.Types[0].Name)
+	}
+
+	service := findType(fileContext, "UserService")
+	if service.Doc != "Manages users." {
+		t.Errorf("Expected class Javadoc, got %q", service.Doc)
+	}
+	if service.StartLine != 11 || service.EndLine != 88 {
+		t.Errorf("Expected UserService at lines 11-88, got %d-%d", service.StartLine, service.EndLine)
+	}
+	if !slices.Equal(service.Embedded, []string{"BaseService", "Repository<User>", "AutoCloseable"}) {
+		t.Errorf("Unexpected embedded types: %v", service.Embedded)
+	}
+
+	var fieldNames []string
+	for _, field := range service.Fields {
+		fieldNames = append(fieldNames, field.Name+":"+field.Type)
+	}
+	expectedFields := []string{
+		"MAX_USERS:int", "PREFIX:String", "cache:Map<String, List<User>>",
+		"backup:Map<String, List<User>>", "count:int",
+	}
+	if !slices.Equal(fieldNames, expectedFields) {
+		t.Errorf("Expected fields %v, got %v", expectedFields, fieldNames)
+	}
+
+	point := findType(fileContext, "Point")
+	if len(point.Fields) != 2 || point.Fields[0].Name != "x" || point.Fields[1].Type != "int" {
+		t.Errorf("Expected record components as fields, got %v", point.Fields)
+	}
+
+	status := findType(fileContext, "Status")
+	if len(status.Fields) < 2 || status.Fields[0].Name != "ACTIVE" || status.Fields[1].Name != "INACTIVE" {
+		t.Errorf("Expected enum constants as fields, got %v", status.Fields)
+	}
+}
+
+func TestJavaParser_Methods(t *testing.T) {
+	fileContext := parseSample(t)
+	service := findType(fileContext, "UserService")
+
+	methods := make(map[string]models.Method)
+	for _, method := range service.Methods {
+		methods[method.Name] = method
+	}
+
+	findUser, ok := methods["findUser"]
+	if !ok {
+		t.Fatal("Expected findUser method on UserService")
+	}
+	expectedSignature := "public synchronized User findUser(String id) throws NotFoundException"
+	if findUser.Signature != expectedSignature {
+		t.Errorf("Expected signature %q, got %q", expectedSignature, findUser.Signature)
+	}
+	if findUser.Doc != "Finds a user by ID." {
+		t.Errorf("Expected method Javadoc, got %q", findUser.Doc)
+	}
+	if len(findUser.Returns) != 1 || findUser.Returns[0].Name != "User" {
+		t.Errorf("Expected User return type, got %v", findUser.Returns)
+	}
+	if findUser.StartLine != 30 || findUser.EndLine != 36 {
+		t.Errorf("Expected findUser at lines 30-36, got %d-%d", findUser.StartLine, findUser.EndLine)
+	}
+
+	sorted := methods["sorted"]
+	expectedSorted := "public static <T extends Comparable<? super T>> List<T> sorted(List<T> items, int... limits)"
+	if sorted.Signature != expectedSorted {
+		t.Errorf("Expected signature %q, got %q", expectedSorted, sorted.Signature)
+	}
+
+	if _, ok := methods["UserService"]; !ok {
+		t.Error("Expected constructor to be extracted as a method")
+	}
+	if _, ok := methods["describe"]; !ok {
+		t.Error("Expected abstract method to be extracted")
+	}
+	if findFunction(fileContext, "describe") != nil {
+		t.Error("Expected abstract method without body to be excluded from functions")
+	}
+
+	listener := findType(fileContext, "Listener")
+	if len(listener.Methods) != 2 {
+		t.Errorf("Expected 2 interface methods, got %d", len(listener.Methods))
+	}
+}
+
+func TestJavaParser_Annotations(t *testing.T) {
+	fileContext := parseSample(t)
+
+	service := findType(fileContext, "UserService")
+	if !slices.Equal(service.Decorators, []string{"@Service"}) {
+		t.Errorf("Expected UserService to be annotated @Service, got %v", service.Decorators)
+	}
+
+	findUser := findFunction(fileContext, "findUser")
+	if findUser == nil || !slices.Equal(findUser.Decorators, []string{"@Override"}) {
+		t.Errorf("Expected findUser to be annotated @Override, got %+v", findUser)
+	}
+	if lookup := findFunction(fileContext, "lookup"); len(lookup.Decorators) != 0 {
+		t.Errorf("Expected lookup to have no annotations, got %v", lookup.Decorators)
+	}
+}
+
+func TestJavaParser_ConstantsAndExports(t *testing.T) {
+	fileContext := parseSample(t)
+
+	constants := make(map[string]models.Constant)
+	for _, constant := range fileContext.Constants {
+		constants[constant.Name] = constant
+	}
+
+	if constants["MAX_USERS"].Value != "100" {
+		t.Errorf("Expected MAX_USERS value 100, got %q", constants["MAX_USERS"].Value)
+	}
+	if constants["PREFIX"].Value != `"user-"` {
+		t.Errorf("Expected PREFIX value, got %q", constants["PREFIX"].Value)
+	}
+	if _, ok := constants["PRIORITY"]; !ok {
+		t.Error("Expected interface field to be extracted as a constant")
+	}
+	if _, ok := constants["ACTIVE"]; !ok {
+		t.Error("Expected enum constant to be extracted")
+	}
+	if _, ok := constants["count"]; ok {
+		t.Error("Expected instance field not to be a constant")
+	}
+
+	exported := make(map[string]bool)
+	for _, export := range fileContext.Exports {
+		exported[export.Kind+":"+export.Name] = true
+	}
+	for _, name := range []string{"type:UserService", "function:findUser", "constant:MAX_USERS", "type:Status"} {
+		if !exported[name] {
+			t.Errorf("Expected export %s", name)
+		}
+	}
+	for _, name := range []string{"function:lookup", "constant:PREFIX"} {
+		if exported[name] {
+			t.Errorf("Expected %s not to be exported", name)
+		}
+	}
+}
+
+func TestJavaParser_CallGraph(t *testing.T) {
+	fileContext := parseSample(t)
+
+	findUser := findFunction(fileContext, "findUser")
+	if findUser == nil {
+		t.Fatal("Expected findUser function")
+	}
+	for _, call := range []string{"validate", "lookup", "List.of", "build"} {
+		if !slices.Contains(findUser.LocalCalls, call) {
+			t.Errorf("Expected findUser to call %s, got %v", call, findUser.LocalCalls)
+		}
+	}
+	for _, call := range []string{"User", "Builder", "User.Builder", "Override"} {
+		if slices.Contains(findUser.LocalCalls, call) {
+			t.Errorf("Expected %s not to be recorded as a call", call)
+		}
+	}
+
+	callTypes := make(map[string]string)
+	for _, call := range findUser.LocalCallsWithMetadata {
+		callTypes[call.FunctionName] = call.CallType
+	}
+	if callTypes["lookup"] != models.CallTypeFunction {
+		t.Errorf("Expected this.lookup to be a local function call, got %s", callTypes["lookup"])
+	}
+	if callTypes["List.of"] != models.CallTypeExternal {
+		t.Errorf("Expected List.of to be external, got %s", callTypes["List.of"])
+	}
+
+	validate := findFunction(fileContext, "validate")
+	if !slices.Contains(validate.LocalCallers, "findUser") || !slices.Contains(validate.LocalCallers, "lookup") {
+		t.Errorf("Expected validate to be called by findUser and lookup, got %v", validate.LocalCallers)
+	}
+
+	constructor := findFunction(fileContext, "UserService")
+	if constructor == nil || !slices.Contains(constructor.LocalCalls, "init") {
+		t.Error("Expected constructor to call init")
+	}
+	if init := findFunction(fileContext, "init"); init == nil || !slices.Contains(init.CalledBy, "UserService") {
+		t.Error("Expected init to be called by the constructor")
+	}
+}
+
+func TestJavaParser_InvalidSyntax(t *testing.T) {
+	parser := NewJavaParser()
+
+	invalidSources := map[string]string{
+		"unclosed class":     "public class Broken {\n    void run() {\n",
+		"unclosed params":    "class Broken {\n    void run(String a {\n    }\n}\n",
+		"unterminated text":  "class Broken {\n    String s = \"oops;\n}\n",
+		"statement at top":   "int x = 1;\n",
+		"mismatched bracket": "class Broken {\n    void run() { call(]; }\n}\n",
+	}
+
+	for name, source := range invalidSources {
+		t.Run(name, func(t *testing.T) {
+			_, err := parser.ParseFile("Broken.java", []byte(source))
+			if err == nil {
+				t.Fatal("Expected syntax error")
+			}
+			if !strings.Contains(err.Error(), "syntax error at line") {
+				t.Errorf("Expected syntax error with line number, got %v", err)
+			}
+			var parseErr *models.ParseError
+			if !errors.As(err, &parseErr) || parseErr.File != "Broken.java" || parseErr.Line == 0 {
+				t.Errorf("Expected a located ParseError for Broken.java, got %#v", err)
+			}
+		})
+	}
+}
+
+func TestJavaParser_EmptyFile(t *testing.T) {
+	fileContext, err := NewJavaParser().ParseFile("Empty.java", []byte(""))
+	if err != nil {
+		t.Fatalf("Expected no error for empty file, got %v", err)
+	}
+	if fileContext.Path != "Empty.java" {
+		t.Errorf("Expected path 'Empty.java', got %s", fileContext.Path)
+	}
+	if len(fileContext.Functions) != 0 || len(fileContext.Types) != 0 {
+		t.Errorf("Expected empty file to have no declarations")
+	}
+}
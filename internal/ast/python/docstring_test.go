@@ -0,0 +1,74 @@
+package python
+
+import (
+	"testing"
+)
+
+func TestPythonParser_DocstringExtraction(t *testing.T) {
+	parser := NewPythonParser()
+
+	code := `"""Utilities for formatting users."""
+
+def format_name(name: str) -> str:
+    """Format a name by capitalizing first letter of each word."""
+    return name.title()
+
+def undocumented(value):
+    return value
+
+class UserService:
+    """Service for managing users.
+
+    Handles lookups and persistence.
+    """
+
+    def find_user(self, user_id: int) -> dict:
+        """
+        Find a user by ID.
+
+            Returns an empty dict when missing.
+        """
+        return {}
+`
+
+	fileContext, err := parser.ParseFile("users.py", []byte(code))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if fileContext.Doc != "Utilities for formatting users." {
+		t.Errorf("Expected module docstring, got %q", fileContext.Doc)
+	}
+
+	docs := make(map[string]string)
+	for i := range fileContext.Functions {
+		docs[fileContext.Functions[i].Name] = fileContext.Functions[i].Doc
+	}
+
+	if docs["format_name"] != "Format a name by capitalizing first letter of each word." {
+		t.Errorf("Unexpected doc for format_name: %q", docs["format_name"])
+	}
+	if doc, ok := docs["undocumented"]; !ok || doc != "" {
+		t.Errorf("Expected empty doc for undocumented function, got %q (found=%v)", doc, ok)
+	}
+
+	if len(fileContext.Types) != 1 {
+		t.Fatalf("Expected 1 type, got %d", len(fileContext.Types))
+	}
+	classDef := fileContext.Types[0]
+
+	expectedClassDoc := "Service for managing users.\n\nHandles lookups and persistence."
+	if classDef.Doc != expectedClassDoc {
+		t.Errorf("Expected class doc %q, got %q", expectedClassDoc, classDef.Doc)
+	}
+
+	if len(classDef.Methods) != 1 {
+		t.Fatalf("Expected 1 method, got %d", len(classDef.Methods))
+	}
+
+	// Common leading indentation is removed while relative indentation is kept
+	expectedMethodDoc := "Find a user by ID.\n\n    Returns an empty dict when missing."
+	if classDef.Methods[0].Doc != expectedMethodDoc {
+		t.Errorf("Expected method doc %q, got %q", expectedMethodDoc, classDef.Methods[0].Doc)
+	}
+}
@@ -13,11 +13,25 @@ import sys
 import traceback
 from typing import Dict, Any, Optional
 
+# Decorators and base classes marking a class as a record of annotated fields
+DATACLASS_DECORATORS = {"dataclass", "dataclasses.dataclass"}
+RECORD_BASES = {
+    "TypedDict",
+    "typing.TypedDict",
+    "typing_extensions.TypedDict",
+    "NamedTuple",
+    "typing.NamedTuple",
+}
+DATACLASS_FIELD_CALLS = {"field", "dataclasses.field"}
+CLASS_VAR_ANNOTATIONS = {"ClassVar", "typing.ClassVar"}
+TYPE_ALIAS_ANNOTATIONS = {"TypeAlias", "typing.TypeAlias", "typing_extensions.TypeAlias"}
 
 class PythonASTExtractor(ast.NodeVisitor):
-    def __init__(self, source_code: str, file_path: str = ""):
+    def __init__(self, source_code: str, file_path: str = "", anonymous_path: Optional[str] = None):
         self.source_code = source_code
         self.file_path = file_path
+        self.anonymous_path = anonymous_path  # Path naming lambdas, which are only indexed when set
+        self.anonymous_names = set()
         self.lines = source_code.split("\n")
         self.functions = []
         self.classes = []
@@ -25,6 +39,7 @@ class PythonASTExtractor(ast.NodeVisitor):
         self.constants = []
         self.imports = []
         self.exports = []
+        self.all_names = None  # Names listed in __all__, or None when the module declares none
         self.current_class = None
         self.call_stack = []
         self.scope_stack = ["module"]  # Track current scope for variable resolution
@@ -38,12 +53,19 @@ class PythonASTExtractor(ast.NodeVisitor):
             # Build call graph relationships
             self._build_call_graph()
 
+            # Functions the module runs when executed as a script are entry points
+            self._mark_main_guard_entry_points(tree)
+
+            # Read the public API the module declares in __all__, if any
+            self.all_names = self._declared_all(tree)
+
             # Extract exports (public API)
             self._extract_exports()
 
             return {
                 "path": self.file_path,
                 "language": "python",
+                "docstring": self._get_docstring(tree),
                 "functions": self.functions,
                 "types": self.classes,  # Map to 'types' for Go compatibility
                 "variables": self.variables,
@@ -52,20 +74,47 @@ class PythonASTExtractor(ast.NodeVisitor):
                 "exports": self.exports,
                 "errors": [],
             }
+        except SyntaxError as e:
+            result = self._error_result(f"Parse error: {str(e)}")
+            result["error_details"] = [
+                {
+                    "kind": "syntax",
+                    "message": e.msg,
+                    "line": e.lineno or 0,
+                    "column": e.offset or 0,
+                }
+            ]
+            return result
         except Exception as e:
-            return {
-                "path": self.file_path,
-                "language": "python",
-                "functions": [],
-                "types": [],
-                "variables": [],
-                "constants": [],
-                "imports": [],
-                "exports": [],
-                "errors": [f"Parse error: {str(e)}"],
-            }
+            return self._error_result(f"Parse error: {str(e)}")
+
+    def _error_result(self, error: str) -> Dict[str, Any]:
+        """Build an empty result carrying a single error message."""
+        return {
+            "path": self.file_path,
+            "language": "python",
+            "docstring": "",
+            "functions": [],
+            "types": [],
+            "variables": [],
+            "constants": [],
+            "imports": [],
+            "exports": [],
+            "errors": [error],
+        }
+
+    def _get_docstring(self, node: ast.AST) -> str:
+        """Return the first docstring of a node with quotes and common indentation stripped (PEP 257)."""
+        return ast.get_docstring(node, clean=True) or ""
 
     def visit_FunctionDef(self, node: ast.FunctionDef):
+        self._visit_function(node)
+
+    def visit_AsyncFunctionDef(self, node: ast.AsyncFunctionDef):
+        self._visit_function(node)
+
+    def _visit_function(self, node):
+        """Record a method, a nested function or a standalone function and visit its body."""
         func_info = self._extract_function(node)
 
         if self.current_class:
@@ -74,31 +123,51 @@ class PythonASTExtractor(ast.NodeVisitor):
             func_info["class_name"] = self.current_class["name"]
             self.current_class["methods"].append(func_info)
         else:
-            # This is a standalone function
+            # Nested functions such as closures are listed with the other functions,
+            # naming the function that encloses them
             func_info["is_method"] = False
+            if self.call_stack:
+                func_info["parent"] = self._qualified_name(self.call_stack[-1])
             self.functions.append(func_info)
 
-        # Visit function body to find calls
-        old_stack = self.call_stack[:]
-        self.call_stack.append(func_info["name"])
+        # Visit function body to find calls; classes defined in the body start a new class
+        old_class = self.current_class
+        self.current_class = None
+        self.call_stack.append(func_info)
         self.generic_visit(node)
-        self.call_stack = old_stack
+        self.call_stack.pop()
+        self.current_class = old_class
 
-    def visit_AsyncFunctionDef(self, node: ast.AsyncFunctionDef):
-        func_info = self._extract_function(node)
-        func_info["is_async"] = True
+    def visit_Lambda(self, node: ast.Lambda):
+        """Record a lambda as a function named lambda@path:line when anonymous functions are
+        indexed. The enclosing function calls the lambda and the calls in its body are its own."""
+        if self.anonymous_path is None:
+            self.generic_visit(node)
+            return
 
-        if self.current_class:
-            func_info["is_method"] = True
-            func_info["class_name"] = self.current_class["name"]
-            self.current_class["methods"].append(func_info)
-        else:
-            self.functions.append(func_info)
+        name = f"lambda@{self.anonymous_path}:{node.lineno}"
+        if name in self.anonymous_names:
+            name = f"{name}:{node.col_offset + 1}"
+        self.anonymous_names.add(name)
 
-        old_stack = self.call_stack[:]
-        self.call_stack.append(func_info["name"])
+        func_info = self._extract_function(node)
+        func_info["name"] = name
+        func_info["is_method"] = False
+        func_info["is_anonymous"] = True
+        if self.call_stack:
+            func_info["parent"] = self._qualified_name(self.call_stack[-1])
+            self.call_stack[-1]["calls"].append({"name": name, "line": node.lineno, "type": "function"})
+        self.functions.append(func_info)
+
+        self.call_stack.append(func_info)
         self.generic_visit(node)
-        self.call_stack = old_stack
+        self.call_stack.pop()
+
+    def _qualified_name(self, func_info: Dict[str, Any]) -> str:
+        """Return a function's name, prefixed with its class for methods."""
+        if func_info.get("is_method"):
+            return f"{func_info['class_name']}.{func_info['name']}"
+        return func_info["name"]
 
     def _extract_function(self, node) -> Dict[str, Any]:
         """Extract function information with Go model compatibility."""
@@ -151,23 +220,23 @@ class PythonASTExtractor(ast.NodeVisitor):
 
         # Extract return type
         returns = []
-        if node.returns:
+        if getattr(node, "returns", None):
             returns.append(
                 {
                     "name": self._normalize_type(ast.unparse(node.returns)),
                     "kind": "builtin",
                 }
             )
-        else:
+        elif not isinstance(node, ast.Lambda):
             returns.append({"name": "None", "kind": "builtin"})
 
         # Extract decorators
         decorators = []
-        for decorator in node.decorator_list:
+        for decorator in getattr(node, "decorator_list", []):
             decorators.append(ast.unparse(decorator))
 
         return {
-            "name": node.name,
+            "name": getattr(node, "name", ""),
             "parameters": parameters,
             "returns": returns,
             "calls": [],  # Will be populated by visit_Call
@@ -176,21 +245,52 @@ class PythonASTExtractor(ast.NodeVisitor):
             "end_line": node.end_lineno or node.lineno,
             "decorators": decorators,
             "is_async": isinstance(node, ast.AsyncFunctionDef),
-            "docstring": ast.get_docstring(node) or "",
+            "docstring": "" if isinstance(node, ast.Lambda) else self._get_docstring(node),
+            "complexity": self._complexity(node),
         }
 
+    def _complexity(self, node) -> int:
+        """Return the cyclomatic complexity of a function body as defined by ComplexityDefinition in the Go models."""
+        match_case = getattr(ast, "match_case", None)  # Python 3.10+
+        complexity = 1
+        stack = [node.body] if isinstance(node, ast.Lambda) else list(node.body)
+        while stack:
+            child = stack.pop()
+            if isinstance(child, (ast.FunctionDef, ast.AsyncFunctionDef, ast.ClassDef)):
+                continue  # Nested functions and classes are measured on their own
+            if isinstance(child, (ast.If, ast.IfExp, ast.For, ast.AsyncFor, ast.While)):
+                complexity += 1
+            elif isinstance(child, ast.comprehension):
+                complexity += 1 + len(child.ifs)
+            elif isinstance(child, ast.BoolOp):
+                complexity += len(child.values) - 1
+            elif match_case and isinstance(child, match_case) and not self._is_wildcard_case(child):
+                complexity += 1
+            stack.extend(ast.iter_child_nodes(child))
+        return complexity
+
+    def _is_wildcard_case(self, case) -> bool:
+        """Return whether a match case is the default "case _:" clause."""
+        pattern = case.pattern
+        return (
+            isinstance(pattern, ast.MatchAs)
+            and pattern.pattern is None
+            and pattern.name is None
+            and case.guard is None
+        )
+
     def visit_ClassDef(self, node: ast.ClassDef):
         """Extract class information with Go model compatibility."""
         class_info = {
             "name": node.name,
-            "kind": "class",
+            "kind": self._class_kind(node),
             "fields": [],
             "methods": [],
             "embedded": [ast.unparse(base) for base in node.bases],
             "start_line": node.lineno,
             "end_line": node.end_lineno or node.lineno,
             "decorators": [ast.unparse(d) for d in node.decorator_list],
-            "docstring": ast.get_docstring(node) or "",
+            "docstring": self._get_docstring(node),
         }
 
         old_class = self.current_class
@@ -206,23 +306,54 @@ class PythonASTExtractor(ast.NodeVisitor):
         self.current_class = old_class
         self.classes.append(class_info)
 
+    def _class_kind(self, node: ast.ClassDef) -> str:
+        """Classify a class: dataclasses and TypedDict or NamedTuple subclasses are records
+        defined by their annotated fields, so they are structs; other classes are classes."""
+        for decorator in node.decorator_list:
+            target = decorator.func if isinstance(decorator, ast.Call) else decorator
+            if ast.unparse(target) in DATACLASS_DECORATORS:
+                return "struct"
+        for base in node.bases:
+            if ast.unparse(base) in RECORD_BASES:
+                return "struct"
+        return "class"
+
     def visit_Call(self, node: ast.Call):
         if self.call_stack:  # We're inside a function
-            current_func = self.call_stack[-1]
             call_name = self._extract_call_name(node)
             if call_name:
-                # Find the function we're currently in and add this call
-                self._add_call_to_current_function(
-                    current_func,
+                # Calls belong to the innermost enclosing function
+                self.call_stack[-1]["calls"].append(
                     {
                         "name": call_name,
                         "line": node.lineno,
                         "type": self._classify_call(node),
-                    },
+                    }
                 )
 
         self.generic_visit(node)
 
+    def _mark_main_guard_entry_points(self, tree: ast.Module):
+        """Mark the module-level functions called under if __name__ == "__main__" as entry points."""
+        called = set()
+        for statement in tree.body:
+            if isinstance(statement, ast.If) and self._is_main_guard(statement.test):
+                for body_statement in statement.body:
+                    for node in ast.walk(body_statement):
+                        if isinstance(node, ast.Call):
+                            called.add(self._extract_call_name(node))
+
+        for func in self.functions:
+            if "parent" not in func and func["name"] in called:
+                func["is_entry_point"] = True
+
+    def _is_main_guard(self, test: ast.AST) -> bool:
+        """Whether a condition is __name__ == "__main__", in either order."""
+        if not (isinstance(test, ast.Compare) and len(test.ops) == 1 and isinstance(test.ops[0], ast.Eq)):
+            return False
+        operands = {ast.unparse(test.left), ast.unparse(test.comparators[0])}
+        return operands == {"__name__", "'__main__'"}
+
     def _extract_call_name(self, node: ast.Call) -> Optional[str]:
         try:
             if isinstance(node.func, ast.Name):
@@ -288,8 +419,15 @@ class PythonASTExtractor(ast.NodeVisitor):
         self.generic_visit(node)
 
     def visit_AnnAssign(self, node: ast.AnnAssign):
-        """Extract annotated assignments (type hints)."""
-        if len(self.scope_stack) == 1 and isinstance(
+        """Extract annotated assignments (type hints): fields in a class body, type aliases
+        and variables at module level."""
+        if self.current_class is not None and isinstance(node.target, ast.Name):
+            field_info = self._extract_field(node)
+            if field_info:
+                self.current_class["fields"].append(field_info)
+        elif len(self.scope_stack) == 1 and self._is_type_alias(node):
+            self.classes.append(self._type_alias_info(node.target.id, node))
+        elif len(self.scope_stack) == 1 and isinstance(
             node.target, ast.Name
         ):  # Module level
             var_info = self._extract_annotated_variable(node)
@@ -299,6 +437,65 @@ class PythonASTExtractor(ast.NodeVisitor):
                 self.variables.append(var_info)
         self.generic_visit(node)
 
+    def visit_TypeAlias(self, node):
+        """Extract a type statement (Python 3.12+) as an alias type."""
+        if len(self.scope_stack) == 1:
+            self.classes.append(self._type_alias_info(node.name.id, node))
+        self.generic_visit(node)
+
+    def _is_type_alias(self, node: ast.AnnAssign) -> bool:
+        """Whether a module-level annotated assignment declares a type alias: X: TypeAlias = Y."""
+        return (
+            isinstance(node.target, ast.Name)
+            and node.value is not None
+            and ast.unparse(node.annotation) in TYPE_ALIAS_ANNOTATIONS
+        )
+
+    def _type_alias_info(self, name: str, node: ast.AST) -> Dict[str, Any]:
+        """Build the type entry of a type alias."""
+        return {
+            "name": name,
+            "kind": "alias",
+            "fields": [],
+            "methods": [],
+            "embedded": [],
+            "start_line": node.lineno,
+            "end_line": node.end_lineno or node.lineno,
+            "decorators": [],
+            "docstring": "",
+        }
+
+    def _extract_field(self, node: ast.AnnAssign) -> Optional[Dict[str, Any]]:
+        """Extract an annotated class attribute as a field with its type and default value.
+        ClassVar attributes belong to the class rather than its instances and are skipped."""
+        annotation = ast.unparse(node.annotation)
+        if annotation.split("[", 1)[0] in CLASS_VAR_ANNOTATIONS:
+            return None
+
+        field_info = {
+            "name": node.target.id,
+            "type": self._normalize_type(annotation),
+            "line": node.lineno,
+        }
+        default = self._field_default(node.value)
+        if default:
+            field_info["default"] = default
+        return field_info
+
+    def _field_default(self, value: Optional[ast.AST]) -> str:
+        """Return the default of a field as source: the assigned value, or for a dataclass
+        field(...) call its default, or its default_factory called, e.g. list()."""
+        if value is None:
+            return ""
+        if isinstance(value, ast.Call) and ast.unparse(value.func) in DATACLASS_FIELD_CALLS:
+            for keyword in value.keywords:
+                if keyword.arg == "default":
+                    return ast.unparse(keyword.value)
+                if keyword.arg == "default_factory":
+                    return ast.unparse(keyword.value) + "()"
+            return ""
+        return ast.unparse(value)
+
     def _extract_variable(self, target: ast.Name, node: ast.Assign) -> Dict[str, Any]:
         """Extract variable information from assignment."""
         var_type = "Any"
@@ -483,21 +680,6 @@ class PythonASTExtractor(ast.NodeVisitor):
 
         return params
 
-    def _add_call_to_current_function(self, func_name: str, call_info: Dict[str, Any]):
-        """Add a call to the current function's call list."""
-        # Find the current function and add the call
-        for func in reversed(self.functions):
-            if func["name"] == func_name:
-                func["calls"].append(call_info)
-                break
-
-        # Also check methods in current class
-        if self.current_class:
-            for method in self.current_class["methods"]:
-                if method["name"] == func_name:
-                    method["calls"].append(call_info)
-                    break
-
     def _build_call_graph(self):
         """Build comprehensive call graph with caller relationships and metadata."""
         all_functions = self.functions[:]
@@ -517,8 +699,9 @@ class PythonASTExtractor(ast.NodeVisitor):
             for call in func.get("calls", []):
                 call_name = call["name"]
 
-                # Handle both local calls (same file) and external calls
-                if "." not in call_name and call_name in func_names:
+                # Handle both local calls (same file) and external calls; the only dotted
+                # function names are those of lambdas, such as lambda@pkg/app.py:3
+                if call_name in func_names:
                     # Local function call within same file
                     target_func = func_map[call_name]
                     caller_info = {
@@ -548,11 +731,54 @@ class PythonASTExtractor(ast.NodeVisitor):
                                 }
                                 target_func["called_by"].append(caller_info)
 
+    def _declared_all(self, tree: ast.Module) -> Optional[set]:
+        """Return the names a module lists in __all__, or None when it declares no __all__
+        or builds it in a way that cannot be read statically."""
+        names = None
+        for node in tree.body:
+            if isinstance(node, ast.Assign):
+                targets, value = node.targets, node.value
+            elif isinstance(node, (ast.AnnAssign, ast.AugAssign)):
+                targets, value = [node.target], node.value
+            else:
+                continue
+            if not any(isinstance(t, ast.Name) and t.id == "__all__" for t in targets):
+                continue
+
+            listed = self._string_sequence(value)
+            if listed is None:
+                return None
+            if isinstance(node, ast.AugAssign) and names is not None:
+                names |= listed
+            else:
+                names = listed
+        return names
+
+    def _string_sequence(self, node: Optional[ast.AST]) -> Optional[set]:
+        """Return the strings of a list or tuple literal of string constants, or None."""
+        if not isinstance(node, (ast.List, ast.Tuple)):
+            return None
+        strings = set()
+        for element in node.elts:
+            if not isinstance(element, ast.Constant) or not isinstance(element.value, str):
+                return None
+            strings.add(element.value)
+        return strings
+
+    def _is_public(self, name: str, default: bool) -> bool:
+        """Decide whether a module-level name is exported: listed in __all__ when the module
+        declares one, otherwise the naming heuristic given as default."""
+        if self.all_names is not None:
+            return name in self.all_names
+        return default
+
     def _extract_exports(self):
-        """Extract public API elements (exports)."""
-        # Functions that don't start with underscore are exported
+        """Extract public API elements (exports). Names are exported as listed in __all__
+        when the module declares one, and by not starting with an underscore otherwise.
+        Names __all__ re-exports from imports are not declared here and are skipped."""
+        # Module-level functions are exported, functions nested in another function never are
         for func in self.functions:
-            if not func["name"].startswith("_"):
+            if "parent" not in func and not func.get("is_anonymous") and self._is_public(func["name"], not func["name"].startswith("_")):
                 self.exports.append(
                     {
                         "name": func["name"],
@@ -561,40 +787,45 @@ class PythonASTExtractor(ast.NodeVisitor):
                     }
                 )
 
-        # Classes that don't start with underscore are exported
         for cls in self.classes:
-            if not cls["name"].startswith("_"):
+            if self._is_public(cls["name"], not cls["name"].startswith("_")):
                 self.exports.append(
                     {"name": cls["name"], "type": "class", "line": cls["start_line"]}
                 )
 
-        # Variables and constants that don't start with underscore are exported
         for var in self.variables:
-            if var.get("is_exported", False):
+            var["is_exported"] = self._is_public(var["name"], var.get("is_exported", False))
+            if var["is_exported"]:
                 self.exports.append(
                     {"name": var["name"], "type": "variable", "line": var["line"]}
                 )
 
         for const in self.constants:
-            if const.get("is_exported", False):
+            const["is_exported"] = self._is_public(const["name"], const.get("is_exported", False))
+            if const["is_exported"]:
                 self.exports.append(
                     {"name": const["name"], "type": "constant", "line": const["line"]}
                 )
 
-
 def main():
     """Main entry point for the Python AST extractor."""
     try:
         # Get file path from command line argument or use stdin
         file_path = ""
-        if len(sys.argv) > 1:
-            file_path = sys.argv[1]
+        anonymous_path = None
+        args = sys.argv[1:]
+        if args[:1] == ["--index-anonymous"] and len(args) > 1:
+            # Lambdas are indexed, named after the path that follows
+            anonymous_path = args[1]
+            args = args[2:]
+        if args:
+            file_path = args[0]
             with open(file_path, "r", encoding="utf-8") as f:
                 source_code = f.read()
         else:
             source_code = sys.stdin.read()
 
-        extractor = PythonASTExtractor(source_code, file_path)
+        extractor = PythonASTExtractor(source_code, file_path, anonymous_path)
         result = extractor.extract()
         print(json.dumps(result, indent=2))
 
@@ -0,0 +1,83 @@
+package python
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// InterpreterEnvVar names the environment variable selecting the Python interpreter
+const InterpreterEnvVar = "REPOCONTEXT_PYTHON"
+
+// NewPythonParserWithInterpreter creates a Python parser running the extractor with a
+// configured interpreter. The interpreter is resolved in this order:
+//
+//  1. interpreter, typically python_interpreter from .repocontext/config.json
+//  2. the REPOCONTEXT_PYTHON environment variable
+//  3. python3, python and on Windows py, looked up on PATH
+//
+// A configured interpreter may be an executable or a virtualenv directory. It is checked
+// here, and an error names it when it is missing or does not run. Without a configured
+// interpreter the parser behaves like NewPythonParser and setup problems surface when
+// files are parsed.
+func NewPythonParserWithInterpreter(interpreter string) (*PythonParser, error) {
+	source := "configured interpreter"
+	if interpreter == "" {
+		interpreter = os.Getenv(InterpreterEnvVar)
+		source = InterpreterEnvVar
+	}
+	if interpreter == "" {
+		return NewPythonParser(), nil
+	}
+
+	pythonPath, err := resolveInterpreter(interpreter)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Python interpreter %q from %s: %w", interpreter, source, err)
+	}
+	return &PythonParser{pythonPath: pythonPath}, nil
+}
+
+// resolveInterpreter returns the executable for an interpreter path, looking inside
+// virtualenv directories, and checks that it runs
+func resolveInterpreter(interpreter string) (string, error) {
+	info, err := os.Stat(interpreter)
+	switch {
+	case err == nil && info.IsDir():
+		executable, found := virtualenvExecutable(interpreter)
+		if !found {
+			return "", fmt.Errorf("directory is not a virtualenv: no Python executable in its bin or Scripts directory")
+		}
+		interpreter = executable
+	case err != nil && filepath.Base(interpreter) != interpreter: // Bare names are looked up on PATH
+		return "", fmt.Errorf("interpreter not found: %w", err)
+	}
+
+	// #nosec G204 - the interpreter is explicitly configured by the user
+	output, err := exec.Command(interpreter, "--version").CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("interpreter did not run: %w", err)
+	}
+	if !strings.HasPrefix(strings.TrimSpace(string(output)), "Python 3") {
+		return "", fmt.Errorf("interpreter is not Python 3: %s", strings.TrimSpace(string(output)))
+	}
+	return interpreter, nil
+}
+
+// virtualenvExecutable returns the Python executable of a virtualenv directory
+func virtualenvExecutable(dir string) (string, bool) {
+	candidates := []string{filepath.Join("bin", versionPython3), filepath.Join("bin", languagePython)}
+	if runtime.GOOS == osWindows {
+		candidates = []string{filepath.Join("Scripts", "python.exe")}
+	}
+
+	for _, candidate := range candidates {
+		executable := filepath.Join(dir, candidate)
+		if info, err := os.Stat(executable); err == nil && !info.IsDir() {
+			return executable, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,82 @@
+package python
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestNewPythonParserWithInterpreter(t *testing.T) {
+	python3, err := exec.LookPath("python3")
+	if err != nil || runtime.GOOS == osWindows {
+		t.Skip("Requires python3 on PATH and the Unix virtualenv layout")
+	}
+
+	t.Run("explicit executable", func(t *testing.T) {
+		parser, err := NewPythonParserWithInterpreter(python3)
+		if err != nil {
+			t.Fatalf("Expected python3 to be accepted, got %v", err)
+		}
+		if parser.pythonPath != python3 {
+			t.Errorf("Expected interpreter %s, got %s", python3, parser.pythonPath)
+		}
+		if _, err := parser.ParseFile("greet.py", []byte("def greet():\n    pass\n")); err != nil {
+			t.Errorf("Expected the configured interpreter to parse files, got %v", err)
+		}
+	})
+
+	t.Run("virtualenv directory", func(t *testing.T) {
+		venv := t.TempDir()
+		if err := os.Mkdir(filepath.Join(venv, "bin"), 0755); err != nil {
+			t.Fatalf("Failed to create bin directory: %v", err)
+		}
+		executable := filepath.Join(venv, "bin", "python3")
+		if err := os.Symlink(python3, executable); err != nil {
+			t.Fatalf("Failed to link interpreter: %v", err)
+		}
+
+		parser, err := NewPythonParserWithInterpreter(venv)
+		if err != nil {
+			t.Fatalf("Expected virtualenv to be accepted, got %v", err)
+		}
+		if parser.pythonPath != executable {
+			t.Errorf("Expected interpreter %s, got %s", executable, parser.pythonPath)
+		}
+	})
+
+	t.Run("environment variable", func(t *testing.T) {
+		missing := filepath.Join(t.TempDir(), "python3")
+		t.Setenv(InterpreterEnvVar, missing)
+
+		_, err := NewPythonParserWithInterpreter("")
+		if err == nil || !strings.Contains(err.Error(), missing) || !strings.Contains(err.Error(), InterpreterEnvVar) {
+			t.Errorf("Expected error naming %s and %s, got %v", missing, InterpreterEnvVar, err)
+		}
+
+		// An explicit interpreter takes precedence over the environment
+		if _, err := NewPythonParserWithInterpreter(python3); err != nil {
+			t.Errorf("Expected explicit interpreter to override %s, got %v", InterpreterEnvVar, err)
+		}
+	})
+
+	t.Run("invalid interpreters", func(t *testing.T) {
+		notPython := filepath.Join(t.TempDir(), "notpython")
+		if err := os.WriteFile(notPython, []byte("#!/bin/sh\necho Perl 5\n"), 0700); err != nil {
+			t.Fatalf("Failed to write script: %v", err)
+		}
+
+		for name, interpreter := range map[string]string{
+			"missing path":     filepath.Join(t.TempDir(), "bin", "python3"),
+			"missing command":  "python-does-not-exist",
+			"not a virtualenv": t.TempDir(),
+			"not python":       notPython,
+		} {
+			if _, err := NewPythonParserWithInterpreter(interpreter); err == nil || !strings.Contains(err.Error(), interpreter) {
+				t.Errorf("%s: expected error naming %s, got %v", name, interpreter, err)
+			}
+		}
+	})
+}
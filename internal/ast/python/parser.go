@@ -4,12 +4,14 @@ import (
 	"bytes"
 	"crypto/sha256"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"runtime"
+	"slices"
 	"strings"
 	"sync"
 	"time"
@@ -27,15 +29,24 @@ const (
 
 // PythonExtractorOutput represents the JSON output from the Python extractor
 type PythonExtractorOutput struct {
-	Path      string               `json:"path"`
-	Language  string               `json:"language"`
-	Functions []PythonFunctionInfo `json:"functions"`
-	Types     []PythonClassInfo    `json:"types"`
-	Variables []PythonVariableInfo `json:"variables"`
-	Constants []PythonVariableInfo `json:"constants"`
-	Imports   []PythonImportInfo   `json:"imports"`
-	Exports   []PythonExportInfo   `json:"exports"`
-	Errors    []string             `json:"errors"`
+	Path         string               `json:"path"`
+	Language     string               `json:"language"`
+	Docstring    string               `json:"docstring"`
+	Functions    []PythonFunctionInfo `json:"functions"`
+	Types        []PythonClassInfo    `json:"types"`
+	Variables    []PythonVariableInfo `json:"variables"`
+	Constants    []PythonVariableInfo `json:"constants"`
+	Imports      []PythonImportInfo   `json:"imports"`
+	Exports      []PythonExportInfo   `json:"exports"`
+	Errors       []string             `json:"errors"`
+	ErrorDetails []PythonErrorInfo    `json:"error_details,omitempty"` // Error locations, when the extractor knows them
+}
+
+type PythonErrorInfo struct {
+	Kind    string `json:"kind"`
+	Message string `json:"message"`
+	Line    int    `json:"line"`
+	Column  int    `json:"column"`
 }
 
 type PythonFunctionInfo struct {
@@ -49,6 +60,10 @@ type PythonFunctionInfo struct {
 	Decorators []string              `json:"decorators"`
 	IsAsync    bool                  `json:"is_async"`
 	Docstring  string                `json:"docstring"`
+	Parent     string                `json:"parent"`
+	Complexity int                   `json:"complexity"`
+	// IsEntryPoint is set on functions called under if __name__ == "__main__"
+	IsEntryPoint bool `json:"is_entry_point,omitempty"`
 }
 
 type PythonParameterInfo struct {
@@ -88,9 +103,10 @@ type PythonClassInfo struct {
 }
 
 type PythonFieldInfo struct {
-	Name string `json:"name"`
-	Type string `json:"type"`
-	Line int    `json:"line"`
+	Name    string `json:"name"`
+	Type    string `json:"type"`
+	Line    int    `json:"line"`
+	Default string `json:"default,omitempty"`
 }
 
 type PythonVariableInfo struct {
@@ -116,13 +132,14 @@ type PythonExportInfo struct {
 
 // PythonParser implements the LanguageParser interface for Python files
 type PythonParser struct {
-	mu              sync.RWMutex
-	pythonPath      string
-	extractorPath   string
-	currentFilePath string
+	mu             sync.RWMutex
+	pythonPath     string
+	extractorPath  string
+	indexAnonymous bool
 }
 
-// NewPythonParser creates a new Python parser instance
+// NewPythonParser creates a new Python parser instance using the first of python3, python
+// and py found on PATH. NewPythonParserWithInterpreter selects a specific interpreter.
 func NewPythonParser() *PythonParser {
 	parser := &PythonParser{
 		pythonPath:    versionPython3, // Default Python executable
@@ -139,6 +156,14 @@ func NewPythonParser() *PythonParser {
 	return parser
 }
 
+// SetIndexAnonymous makes the parser list lambdas as functions named lambda@file:line,
+// with the enclosing function as their parent and the calls in their body as their own
+func (p *PythonParser) SetIndexAnonymous(enabled bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.indexAnonymous = enabled
+}
+
 // GetSupportedExtensions returns the file extensions supported by this parser
 func (p *PythonParser) GetSupportedExtensions() []string {
 	return []string{extensionPython}
@@ -153,23 +178,32 @@ func (p *PythonParser) GetLanguageName() string {
 func (p *PythonParser) ParseFile(path string, content []byte) (*models.FileContext, error) {
 	// Ensure Python is available and paths are set
 	if err := p.ensureInitialized(); err != nil {
-		return nil, fmt.Errorf("parser initialization failed: %w", err)
+		return nil, &models.ParseError{
+			File:    path,
+			Kind:    models.ParseErrorSetup,
+			Message: fmt.Sprintf("parser initialization failed: %v", err),
+			Err:     err,
+		}
 	}
 
-	// Set the current file path for caller categorization
-	p.mu.Lock()
-	p.currentFilePath = path
-	p.mu.Unlock()
-
 	// Execute the Python extractor
 	extractedData, err := p.executeExtractor(path, content)
 	if err != nil {
-		return nil, fmt.Errorf("failed to execute Python extractor: %w", err)
+		return nil, &models.ParseError{
+			File:    path,
+			Kind:    models.ParseErrorSetup,
+			Message: fmt.Sprintf("failed to execute Python extractor: %v", err),
+			Err:     err,
+		}
 	}
 
 	// Parse the JSON output into Go models
 	fileContext, err := p.parseJSON(extractedData, path, content)
 	if err != nil {
+		var parseErr *models.ParseError
+		if errors.As(err, &parseErr) {
+			return nil, parseErr
+		}
 		return nil, fmt.Errorf("failed to parse extractor output: %w", err)
 	}
 
@@ -234,17 +268,21 @@ func (p *PythonParser) setExtractorPathLocked() error {
 }
 
 // executeExtractor runs the Python extractor script and returns the JSON output
-func (p *PythonParser) executeExtractor(_ string, content []byte) ([]byte, error) {
+func (p *PythonParser) executeExtractor(path string, content []byte) ([]byte, error) {
 	// Get paths with read lock
 	p.mu.RLock()
 	pythonPath := p.pythonPath
-	extractorPath := p.extractorPath
+	args := []string{p.extractorPath}
+	if p.indexAnonymous {
+		// The path only names lambdas; the content is still read from stdin
+		args = append(args, "--index-anonymous", path)
+	}
 	p.mu.RUnlock()
 
 	// Create command to run Python extractor
 	// Don't pass the file path as argument, use stdin instead
 	// #nosec G204 - pythonPath and extractorPath are controlled internally and validated
-	cmd := exec.Command(pythonPath, extractorPath)
+	cmd := exec.Command(pythonPath, args...)
 
 	// Always use content via stdin for consistency
 	cmd.Stdin = bytes.NewReader(content)
@@ -270,8 +308,22 @@ func (p *PythonParser) parseJSON(data []byte, path string, content []byte) (*mod
 	}
 
 	// Check for extraction errors
+	if len(pythonOutput.ErrorDetails) > 0 {
+		detail := pythonOutput.ErrorDetails[0]
+		return nil, &models.ParseError{
+			File:    path,
+			Line:    detail.Line,
+			Column:  detail.Column,
+			Message: detail.Message,
+			Kind:    detail.Kind,
+		}
+	}
 	if len(pythonOutput.Errors) > 0 {
-		return nil, fmt.Errorf("python extractor errors: %v", pythonOutput.Errors)
+		return nil, &models.ParseError{
+			File:    path,
+			Message: fmt.Sprintf("python extractor errors: %v", pythonOutput.Errors),
+			Kind:    models.ParseErrorSyntax,
+		}
 	}
 
 	// Calculate checksum and modification time
@@ -291,27 +343,67 @@ func (p *PythonParser) parseJSON(data []byte, path string, content []byte) (*mod
 		Language:  languagePython,
 		Checksum:  checksum,
 		ModTime:   modTime,
-		Functions: p.convertFunctions(pythonOutput.Functions),
+		Doc:       pythonOutput.Docstring,
+		Functions: p.convertFunctions(pythonOutput.Functions, path),
 		Types:     p.convertTypes(pythonOutput.Types),
 		Variables: p.convertVariables(pythonOutput.Variables),
 		Constants: p.convertConstants(pythonOutput.Constants),
 		Imports:   p.convertImports(pythonOutput.Imports),
 		Exports:   p.convertExports(pythonOutput.Exports),
 	}
+	if isTestFile(path) {
+		markTests(fileContext)
+	}
 
 	return fileContext, nil
 }
 
+// isTestFile reports whether a file is named as pytest and unittest discover tests:
+// test*.py or *_test.py
+func isTestFile(path string) bool {
+	base := filepath.Base(path)
+	return strings.HasPrefix(base, "test") || strings.HasSuffix(base, "_test.py")
+}
+
+// markTests flags the tests of a test file following pytest and unittest conventions:
+// module-level test* functions, and test* methods of Test* classes or TestCase subclasses
+func markTests(fileContext *models.FileContext) {
+	for i := range fileContext.Functions {
+		function := &fileContext.Functions[i]
+		function.IsTest = function.Parent == "" && strings.HasPrefix(function.Name, "test")
+	}
+
+	for i := range fileContext.Types {
+		typeDef := &fileContext.Types[i]
+		isTestClass := strings.HasPrefix(typeDef.Name, "Test") || slices.ContainsFunc(typeDef.Embedded, func(base string) bool {
+			return strings.HasSuffix(base, "TestCase")
+		})
+		if !isTestClass {
+			continue
+		}
+		for j := range typeDef.Methods {
+			typeDef.Methods[j].IsTest = strings.HasPrefix(typeDef.Methods[j].Name, "test")
+		}
+	}
+}
+
 // convertFunctions converts Python function info to Go models
-func (p *PythonParser) convertFunctions(pythonFunctions []PythonFunctionInfo) []models.Function {
+func (p *PythonParser) convertFunctions(pythonFunctions []PythonFunctionInfo, path string) []models.Function {
 	functions := make([]models.Function, len(pythonFunctions))
 
 	for i := range pythonFunctions {
 		pFunc := &pythonFunctions[i]
 		function := models.Function{
-			Name:      pFunc.Name,
-			StartLine: pFunc.StartLine,
-			EndLine:   pFunc.EndLine,
+			Name:       pFunc.Name,
+			StartLine:  pFunc.StartLine,
+			EndLine:    pFunc.EndLine,
+			Doc:        pFunc.Docstring,
+			Decorators: formatDecorators(pFunc.Decorators),
+			Parent:     pFunc.Parent,
+			LineCount:  models.LineCount(pFunc.StartLine, pFunc.EndLine),
+			Complexity: pFunc.Complexity,
+
+			IsEntryPoint: pFunc.IsEntryPoint,
 
 			// Populate deprecated fields for backward compatibility
 			Calls:    p.extractCallNames(pFunc.Calls),
@@ -328,7 +420,7 @@ func (p *PythonParser) convertFunctions(pythonFunctions []PythonFunctionInfo) []
 		p.convertPythonCalls(pFunc, &function)
 
 		// Convert callers to enhanced format
-		p.convertPythonCallers(pFunc, &function)
+		p.convertPythonCallers(pFunc, &function, path)
 
 		// Convert parameters
 		for _, param := range pFunc.Parameters {
@@ -357,6 +449,18 @@ func (p *PythonParser) convertFunctions(pythonFunctions []PythonFunctionInfo) []
 	return functions
 }
 
+// formatDecorators renders decorator expressions as written in source, e.g. "@app.route('/x')"
+func formatDecorators(decorators []string) []string {
+	if len(decorators) == 0 {
+		return nil
+	}
+	formatted := make([]string, len(decorators))
+	for i, decorator := range decorators {
+		formatted[i] = "@" + decorator
+	}
+	return formatted
+}
+
 // convertTypes converts Python class info to Go models
 func (p *PythonParser) convertTypes(pythonTypes []PythonClassInfo) []models.TypeDef {
 	types := make([]models.TypeDef, len(pythonTypes))
@@ -364,18 +468,21 @@ func (p *PythonParser) convertTypes(pythonTypes []PythonClassInfo) []models.Type
 	for i := range pythonTypes {
 		pType := &pythonTypes[i]
 		typeDef := models.TypeDef{
-			Name:      pType.Name,
-			Kind:      pType.Kind,
-			StartLine: pType.StartLine,
-			EndLine:   pType.EndLine,
-			Embedded:  pType.Embedded,
+			Name:       pType.Name,
+			Kind:       pType.Kind,
+			StartLine:  pType.StartLine,
+			EndLine:    pType.EndLine,
+			Embedded:   pType.Embedded,
+			Doc:        pType.Docstring,
+			Decorators: formatDecorators(pType.Decorators),
 		}
 
 		// Convert fields
 		for _, field := range pType.Fields {
 			modelField := models.Field{
-				Name: field.Name,
-				Type: field.Type,
+				Name:    field.Name,
+				Type:    field.Type,
+				Default: field.Default,
 			}
 			typeDef.Fields = append(typeDef.Fields, modelField)
 		}
@@ -384,10 +491,15 @@ func (p *PythonParser) convertTypes(pythonTypes []PythonClassInfo) []models.Type
 		for j := range pType.Methods {
 			method := &pType.Methods[j]
 			modelMethod := models.Method{
-				Name:      method.Name,
-				Signature: p.buildMethodSignature(method),
-				StartLine: method.StartLine,
-				EndLine:   method.EndLine,
+				Name:       method.Name,
+				Signature:  p.buildMethodSignature(method),
+				StartLine:  method.StartLine,
+				EndLine:    method.EndLine,
+				Doc:        method.Docstring,
+				Decorators: formatDecorators(method.Decorators),
+				LineCount:  models.LineCount(method.StartLine, method.EndLine),
+				Complexity: method.Complexity,
+				Calls:      p.extractCallNames(method.Calls),
 			}
 
 			// Convert method parameters
@@ -427,6 +539,7 @@ func (p *PythonParser) convertVariables(pythonVars []PythonVariableInfo) []model
 			Type:      pVar.Type,
 			StartLine: pVar.Line,
 			EndLine:   pVar.Line,
+			Scope:     models.ScopePackage, // Only module-level assignments are extracted
 		}
 	}
 
@@ -443,6 +556,7 @@ func (p *PythonParser) convertConstants(pythonConsts []PythonVariableInfo) []mod
 			Type:      pConst.Type,
 			StartLine: pConst.Line,
 			EndLine:   pConst.Line,
+			Scope:     models.ScopePackage, // Only module-level assignments are extracted
 		}
 	}
 
@@ -643,10 +757,11 @@ func (p *PythonParser) convertPythonCalls(pFunc *PythonFunctionInfo, function *m
 	}
 }
 
-// convertPythonCallers converts Python caller info to enhanced LocalCallers and CrossFileCallers
-func (p *PythonParser) convertPythonCallers(pFunc *PythonFunctionInfo, function *models.Function) {
+// convertPythonCallers converts Python caller info to enhanced LocalCallers and CrossFileCallers,
+// treating callers in path, the file being parsed, as local
+func (p *PythonParser) convertPythonCallers(pFunc *PythonFunctionInfo, function *models.Function, path string) {
 	for _, caller := range pFunc.CalledBy {
-		if caller.File == "" || caller.File == p.getCurrentFilePath() {
+		if caller.File == "" || caller.File == path {
 			// Local caller within same file
 			function.LocalCallers = append(function.LocalCallers, caller.FunctionName)
 		} else {
@@ -662,13 +777,6 @@ func (p *PythonParser) convertPythonCallers(pFunc *PythonFunctionInfo, function
 	}
 }
 
-// getCurrentFilePath returns the current file path being processed
-func (p *PythonParser) getCurrentFilePath() string {
-	p.mu.RLock()
-	defer p.mu.RUnlock()
-	return p.currentFilePath
-}
-
 // mapPythonCallType maps Python call types to Go model constants
 func (p *PythonParser) mapPythonCallType(pythonType string) string {
 	switch pythonType {
@@ -1,6 +1,7 @@
 package python
 
 import (
+	"errors"
 	"fmt"
 	"path/filepath"
 	"slices"
@@ -258,7 +259,18 @@ def broken_function(
 
 	_, err := parser.ParseFile("invalid.py", []byte(invalidCode))
 	if err == nil {
-		t.Error("Expected error for invalid Python code")
+		t.Fatal("Expected error for invalid Python code")
+	}
+
+	var parseErr *models.ParseError
+	if !errors.As(err, &parseErr) {
+		t.Fatalf("Expected a ParseError, got %T: %v", err, err)
+	}
+	if parseErr.Kind != models.ParseErrorSyntax || parseErr.File != "invalid.py" {
+		t.Errorf("Expected syntax error in invalid.py, got %+v", parseErr)
+	}
+	if parseErr.Line != 4 || parseErr.Column == 0 {
+		t.Errorf("Expected error located on line 4 with a column, got line %d column %d", parseErr.Line, parseErr.Column)
 	}
 }
 
@@ -1144,6 +1156,141 @@ def _private_function():
 	t.Logf("Export Kind field test completed successfully with %d exports", len(fileContext.Exports))
 }
 
+// TestPythonParser_ExportsHonorAll validates that __all__ overrides the underscore naming heuristic
+func TestPythonParser_ExportsHonorAll(t *testing.T) {
+	parser := NewPythonParser()
+
+	code := `"""Module declaring its public API."""
+
+__all__ = ["Client", "_connect", "TIMEOUT"]
+__all__ += ("default_client",)
+
+TIMEOUT = 30
+RETRIES = 3
+
+class Client:
+    pass
+
+class Helper:
+    pass
+
+def _connect():
+    pass
+
+def default_client():
+    pass
+
+def public_looking():
+    pass
+`
+
+	fileContext, err := parser.ParseFile("client.py", []byte(code))
+	if err != nil {
+		t.Fatalf("Failed to parse file: %v", err)
+	}
+
+	exportKinds := make(map[string]string)
+	for _, export := range fileContext.Exports {
+		exportKinds[export.Name] = export.Kind
+	}
+
+	expectedExports := map[string]string{
+		"Client":         "type",
+		"_connect":       "function",
+		"default_client": "function",
+		"TIMEOUT":        "constant",
+	}
+	if len(exportKinds) != len(expectedExports) {
+		t.Errorf("Expected exports %v, got %v", expectedExports, exportKinds)
+	}
+	for name, expectedKind := range expectedExports {
+		if kind, exists := exportKinds[name]; !exists || kind != expectedKind {
+			t.Errorf("Expected export '%s' with Kind '%s', got '%s' (found: %t)", name, expectedKind, kind, exists)
+		}
+	}
+	for _, name := range []string{"Helper", "public_looking", "RETRIES", "__all__"} {
+		if _, exists := exportKinds[name]; exists {
+			t.Errorf("Expected '%s' to be left out of the exports by __all__", name)
+		}
+	}
+
+	// A dynamically built __all__ cannot be read, so the naming heuristic applies
+	code = "__all__ = [name for name in dir() if name.isupper()]\n\ndef public():\n    pass\n\ndef _private():\n    pass\n"
+	fileContext, err = parser.ParseFile("dynamic.py", []byte(code))
+	if err != nil {
+		t.Fatalf("Failed to parse file: %v", err)
+	}
+	if len(fileContext.Exports) != 1 || fileContext.Exports[0].Name != "public" {
+		t.Errorf("Expected the underscore heuristic for a dynamic __all__, got %+v", fileContext.Exports)
+	}
+}
+
+func TestPythonParser_IndexAnonymous(t *testing.T) {
+	code := `def ranked(users):
+    return sorted(users, key=lambda user: score(user))
+
+class Report:
+    def render(self, rows):
+        return map(lambda row: format_row(row), rows), filter(lambda row: row, rows)
+`
+
+	parser := NewPythonParser()
+	fileContext, err := parser.ParseFile("report.py", []byte(code))
+	if err != nil {
+		t.Fatalf("Failed to parse file: %v", err)
+	}
+	if len(fileContext.Functions) != 1 || !slices.Contains(fileContext.Functions[0].LocalCalls, "score") {
+		t.Fatalf("Expected lambdas to be left out by default, got %+v", fileContext.Functions)
+	}
+
+	parser.SetIndexAnonymous(true)
+	fileContext, err = parser.ParseFile("report.py", []byte(code))
+	if err != nil {
+		t.Fatalf("Failed to parse file: %v", err)
+	}
+
+	functions := make(map[string]models.Function)
+	for _, function := range fileContext.Functions {
+		functions[function.Name] = function
+	}
+	expectedParents := map[string]string{
+		"ranked":             "",
+		"lambda@report.py:2": "ranked",
+		"lambda@report.py:6": "Report.render",
+		// The second lambda on a line is told apart by its column
+		"lambda@report.py:6:63": "Report.render",
+	}
+	if len(functions) != len(expectedParents) {
+		t.Errorf("Expected functions %v, got %d functions", expectedParents, len(functions))
+	}
+	for name, parent := range expectedParents {
+		function, found := functions[name]
+		if !found {
+			t.Errorf("Expected function %s to be indexed", name)
+			continue
+		}
+		if function.Parent != parent {
+			t.Errorf("Expected %s to have parent %q, got %q", name, parent, function.Parent)
+		}
+	}
+
+	// The call in the lambda is its own; the enclosing function calls the lambda
+	if calls := functions["lambda@report.py:2"].LocalCalls; !slices.Equal(calls, []string{"score"}) {
+		t.Errorf("Expected the lambda to call score, got %v", calls)
+	}
+	if calls := functions["ranked"].LocalCalls; slices.Contains(calls, "score") || !slices.Contains(calls, "lambda@report.py:2") {
+		t.Errorf("Expected ranked to call the lambda rather than score, got %v", calls)
+	}
+	if callers := functions["lambda@report.py:2"].LocalCallers; !slices.Equal(callers, []string{"ranked"}) {
+		t.Errorf("Expected ranked to be the lambda's caller, got %v", callers)
+	}
+	for _, export := range fileContext.Exports {
+		if strings.HasPrefix(export.Name, "lambda@") {
+			t.Errorf("Expected lambdas not to be exported, got %s", export.Name)
+		}
+	}
+}
+
 // TestPythonParser_VariableLinePositions validates the line positions for Python variables and constants
 func TestPythonParser_VariableLinePositions(t *testing.T) {
 	parser := NewPythonParser()
@@ -1364,6 +1511,371 @@ class MultipleInheritance(BaseClass1, BaseClass2):
 	t.Logf("  - MultipleInheritance: %d base classes (%v)", len(multipleClass.Embedded), multipleClass.Embedded)
 }
 
+// TestPythonParser_DottedBaseClasses tests that dotted bases are kept qualified and keyword arguments are ignored
+func TestPythonParser_DottedBaseClasses(t *testing.T) {
+	parser := NewPythonParser()
+
+	code := `from abc import ABCMeta
+from app import db
+
+class User:
+    pass
+
+class Admin(User, db.Model, metaclass=ABCMeta):
+    """An administrator persisted through the ORM."""
+    pass
+`
+
+	fileContext, err := parser.ParseFile("admin.py", []byte(code))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	admin := findType(fileContext.Types, "Admin")
+	if admin == nil {
+		t.Fatal("Expected to find Admin")
+	}
+
+	expected := []string{"User", "db.Model"}
+	if len(admin.Embedded) != len(expected) {
+		t.Fatalf("Expected base classes %v, got %v", expected, admin.Embedded)
+	}
+	for i, base := range expected {
+		if admin.Embedded[i] != base {
+			t.Errorf("Expected base class %d to be %q, got %q", i, base, admin.Embedded[i])
+		}
+	}
+}
+
+func TestPythonParser_Decorators(t *testing.T) {
+	parser := NewPythonParser()
+
+	code := `from dataclasses import dataclass
+from app import app
+
+@app.route('/users', methods=['GET'])
+def list_users():
+    pass
+
+def helper():
+    pass
+
+@dataclass
+class User:
+    name: str
+
+    @property
+    def display_name(self):
+        return self.name
+`
+
+	fileContext, err := parser.ParseFile("views.py", []byte(code))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	listUsers := findFunction(fileContext.Functions, "list_users")
+	if listUsers == nil || len(listUsers.Decorators) != 1 || listUsers.Decorators[0] != "@app.route('/users', methods=['GET'])" {
+		t.Errorf("Expected list_users route decorator, got %+v", listUsers)
+	}
+	if helper := findFunction(fileContext.Functions, "helper"); helper == nil || len(helper.Decorators) != 0 {
+		t.Errorf("Expected helper to have no decorators, got %+v", helper)
+	}
+
+	user := findType(fileContext.Types, "User")
+	if user == nil || len(user.Decorators) != 1 || user.Decorators[0] != "@dataclass" {
+		t.Fatalf("Expected User to be decorated with @dataclass, got %+v", user)
+	}
+	if len(user.Methods) != 1 || len(user.Methods[0].Decorators) != 1 || user.Methods[0].Decorators[0] != "@property" {
+		t.Errorf("Expected display_name to be decorated with @property, got %+v", user.Methods)
+	}
+}
+
+func TestPythonParser_RecordFields(t *testing.T) {
+	parser := NewPythonParser()
+
+	code := `from dataclasses import dataclass, field
+from typing import ClassVar, NamedTuple, Optional, TypeAlias, TypedDict
+
+UserID: TypeAlias = int
+
+@dataclass(frozen=True)
+class User:
+    """A registered user."""
+    registry: ClassVar[dict] = {}
+    id: UserID
+    name: str = "anonymous"
+    email: Optional[str] = None
+    tags: list[str] = field(default_factory=list)
+    score: float = field(default=0.0, repr=False)
+    notes: str = field(repr=False)
+
+    def display_name(self) -> str:
+        label: str = self.name
+        return label
+
+class Movie(TypedDict):
+    title: str
+    year: int
+
+class Point(NamedTuple):
+    x: int = 0
+
+class Service:
+    timeout: int = 30
+`
+
+	fileContext, err := parser.ParseFile("models.py", []byte(code))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	user := findType(fileContext.Types, "User")
+	if user == nil {
+		t.Fatal("Expected to find User")
+	}
+	if user.Kind != "struct" {
+		t.Errorf("Expected dataclass User to be a struct, got %s", user.Kind)
+	}
+	// ClassVar attributes and variables annotated in methods are not fields
+	expected := []models.Field{
+		{Name: "id", Type: "UserID"},
+		{Name: "name", Type: "str", Default: "'anonymous'"},
+		{Name: "email", Type: "Optional[str]", Default: "None"},
+		{Name: "tags", Type: "list[str]", Default: "list()"},
+		{Name: "score", Type: "float", Default: "0.0"},
+		{Name: "notes", Type: "str"},
+	}
+	if len(user.Fields) != len(expected) {
+		t.Fatalf("Expected fields %+v, got %+v", expected, user.Fields)
+	}
+	for i, field := range expected {
+		if user.Fields[i] != field {
+			t.Errorf("Expected field %d to be %+v, got %+v", i, field, user.Fields[i])
+		}
+	}
+
+	for name, kind := range map[string]string{"Movie": "struct", "Point": "struct", "Service": "class", "UserID": "alias"} {
+		typeDef := findType(fileContext.Types, name)
+		if typeDef == nil || typeDef.Kind != kind {
+			t.Errorf("Expected %s to be a %s, got %+v", name, kind, typeDef)
+		}
+	}
+	if movie := findType(fileContext.Types, "Movie"); movie != nil && len(movie.Fields) != 2 {
+		t.Errorf("Expected Movie to have 2 fields, got %+v", movie.Fields)
+	}
+	if service := findType(fileContext.Types, "Service"); service != nil &&
+		(len(service.Fields) != 1 || service.Fields[0].Default != "30") {
+		t.Errorf("Expected Service to have timeout defaulting to 30, got %+v", service.Fields)
+	}
+	for _, variable := range fileContext.Variables {
+		if variable.Name == "UserID" {
+			t.Error("Expected the UserID type alias not to be listed as a variable")
+		}
+	}
+}
+
+func TestPythonParser_MainGuardEntryPoints(t *testing.T) {
+	parser := NewPythonParser()
+
+	code := `import sys
+
+def run(argv):
+    return helper(argv)
+
+def helper(argv):
+    return argv
+
+def unused():
+    pass
+
+if __name__ == "__main__":
+    sys.exit(run(sys.argv))
+`
+
+	fileContext, err := parser.ParseFile("cli.py", []byte(code))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	for name, expected := range map[string]bool{"run": true, "helper": false, "unused": false} {
+		function := findFunction(fileContext.Functions, name)
+		if function == nil || function.IsEntryPoint != expected {
+			t.Errorf("Expected %s entry point to be %v, got %+v", name, expected, function)
+		}
+	}
+}
+
+func TestPythonParser_NestedFunctions(t *testing.T) {
+	parser := NewPythonParser()
+
+	code := `def retry(times):
+    def decorator(fn):
+        def wrapper(*args):
+            log_attempt(times)
+            return fn(*args)
+        return wrapper
+    return decorator
+
+class Service:
+    def run(self):
+        def step():
+            compute()
+        return step()
+`
+
+	fileContext, err := parser.ParseFile("retry.py", []byte(code))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		parent string
+		calls  []string
+	}{
+		{name: "retry", parent: ""},
+		{name: "decorator", parent: "retry"},
+		{name: "wrapper", parent: "decorator", calls: []string{"log_attempt", "fn"}},
+		{name: "step", parent: "Service.run", calls: []string{"compute"}},
+	}
+	for _, tt := range tests {
+		function := findFunction(fileContext.Functions, tt.name)
+		if function == nil {
+			t.Errorf("Expected function %s to be captured", tt.name)
+			continue
+		}
+		if function.Parent != tt.parent {
+			t.Errorf("Expected %s to have parent %q, got %q", tt.name, tt.parent, function.Parent)
+		}
+		if !slices.Equal(function.Calls, tt.calls) {
+			t.Errorf("Expected %s to call %v, got %v", tt.name, tt.calls, function.Calls)
+		}
+	}
+
+	// A function nested in a method is not a method of the class
+	service := findType(fileContext.Types, "Service")
+	if service == nil || len(service.Methods) != 1 || service.Methods[0].Name != "run" {
+		t.Errorf("Expected Service to have only the run method, got %+v", service)
+	}
+
+	// Nested functions are not part of the module's public API
+	for _, export := range fileContext.Exports {
+		if export.Name == "decorator" || export.Name == "wrapper" {
+			t.Errorf("Expected nested function %s not to be exported", export.Name)
+		}
+	}
+}
+
+func TestPythonParser_Complexity(t *testing.T) {
+	parser := NewPythonParser()
+
+	code := `def straight():
+    return 1
+
+def branchy(items, flag):
+    if flag and items or not items:
+        return [x for x in items if x]
+    for item in items:
+        while item:
+            item -= 1
+    def inner():
+        if flag:
+            pass
+    return 1 if flag else 2
+
+class Worker:
+    def run(self, jobs):
+        for job in jobs:
+            job()
+`
+
+	fileContext, err := parser.ParseFile("metrics.py", []byte(code))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// branchy: 1 + if + and + or + comprehension for and if + for + while + conditional expression;
+	// the nested function is measured on its own
+	expected := map[string]struct{ complexity, lines int }{
+		"straight": {complexity: 1, lines: 2},
+		"branchy":  {complexity: 9, lines: 10},
+		"inner":    {complexity: 2, lines: 3},
+	}
+	for name, want := range expected {
+		function := findFunction(fileContext.Functions, name)
+		if function == nil || function.Complexity != want.complexity || function.LineCount != want.lines {
+			t.Errorf("Expected %s to have complexity %d over %d lines, got %+v", name, want.complexity, want.lines, function)
+		}
+	}
+
+	worker := findType(fileContext.Types, "Worker")
+	if worker == nil || len(worker.Methods) != 1 || worker.Methods[0].Complexity != 2 || worker.Methods[0].LineCount != 3 {
+		t.Errorf("Expected Worker.run to have complexity 2 over 3 lines, got %+v", worker)
+	}
+}
+
+func TestPythonParser_TestFunctions(t *testing.T) {
+	parser := NewPythonParser()
+
+	code := `import unittest
+
+def test_get_user():
+    user = get_user(1)
+    def test_inner():
+        pass
+
+def helper():
+    pass
+
+class TestStore:
+    def test_save(self):
+        self.store.save()
+
+    def setup_method(self):
+        pass
+
+class StoreCase(unittest.TestCase):
+    def test_delete(self):
+        delete_user(1)
+
+class Store:
+    def test_connection(self):
+        pass
+`
+
+	fileContext, err := parser.ParseFile("tests/test_user.py", []byte(code))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	for name, expected := range map[string]bool{"test_get_user": true, "test_inner": false, "helper": false} {
+		if function := findFunction(fileContext.Functions, name); function == nil || function.IsTest != expected {
+			t.Errorf("Expected %s IsTest %v, got %+v", name, expected, function)
+		}
+	}
+	for _, typeDef := range fileContext.Types {
+		for _, method := range typeDef.Methods {
+			expected := method.Name == "test_save" || method.Name == "test_delete"
+			if method.IsTest != expected {
+				t.Errorf("Expected %s.%s IsTest %v", typeDef.Name, method.Name, expected)
+			}
+		}
+	}
+	if store := findType(fileContext.Types, "TestStore"); store == nil || !slices.Contains(store.Methods[0].Calls, "self.store.save") {
+		t.Errorf("Expected TestStore.test_save to record its calls, got %+v", store)
+	}
+
+	// Outside test files nothing is a test
+	fileContext, err = parser.ParseFile("user.py", []byte(code))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if function := findFunction(fileContext.Functions, "test_get_user"); function == nil || function.IsTest {
+		t.Errorf("Expected no tests outside test files, got %+v", function)
+	}
+}
+
 // Helper function to find a function by name
 func findFunction(functions []models.Function, name string) *models.Function {
 	for i := range functions {
@@ -0,0 +1,608 @@
+package ruby
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"repository-context-protocol/internal/models"
+)
+
+// tokenKind classifies lexical tokens
+type tokenKind int
+
+const (
+	tokenIdent    tokenKind = iota // Identifiers and keywords, including ? and ! method suffixes
+	tokenConstant                  // Identifiers starting with an upper-case letter
+	tokenVariable                  // Instance, class and global variables: @x, @@x, $x
+	tokenSymbol
+	tokenNumber
+	tokenString // Strings, heredocs, regular expressions, percent and character literals
+	tokenPunct
+	tokenNewline
+	tokenEOF
+)
+
+// token is a single lexical token with its source position.
+// Doc holds the comment block immediately preceding the token, if any.
+type token struct {
+	kind        tokenKind
+	text        string
+	line        int
+	offset      int  // Byte offset of the token in the source
+	spaceBefore bool // Whether whitespace separates the token from the previous one
+	doc         string
+}
+
+// end returns the byte offset just past the token
+func (t token) end() int {
+	return t.offset + len(t.text)
+}
+
+// multiCharPuncts are the operators recognised as one token, longest first
+var multiCharPuncts = []string{
+	"**=", "<=>", "===", "...", "<<=", ">>=", "&&=", "||=",
+	"::", "=>", "->", "**", "==", "!=", "<=", ">=", "&&", "||", "<<", ">>", "&.", "..",
+	"+=", "-=", "*=", "/=", "%=", "|=", "&=", "^=", "=~", "!~",
+}
+
+// pendingHeredoc is a heredoc whose body starts on the line after its opener
+type pendingHeredoc struct {
+	terminator string
+	indented   bool // <<~ and <<- allow the terminator to be indented
+	line       int
+}
+
+// lexer converts Ruby source into tokens, dropping comments and insignificant whitespace
+type lexer struct {
+	src         string
+	pos         int
+	line        int
+	spaceBefore bool
+	pendingDoc  []string
+	docLine     int // Line of the last comment in pendingDoc
+	heredocs    []pendingHeredoc
+	tokens      []token
+}
+
+// tokenize splits Ruby source into tokens terminated by an EOF token
+func tokenize(src string) ([]token, error) {
+	l := &lexer{src: src, line: 1}
+	for {
+		if err := l.skipWhitespaceAndComments(); err != nil {
+			return nil, err
+		}
+		if l.pos >= len(l.src) || l.atDataSection() {
+			break
+		}
+		if err := l.lexToken(); err != nil {
+			return nil, err
+		}
+	}
+	if len(l.heredocs) > 0 {
+		return nil, syntaxErrorf(l.heredocs[0].line, "unterminated heredoc, expected '%s'", l.heredocs[0].terminator)
+	}
+	l.tokens = append(l.tokens, token{kind: tokenEOF, line: l.line, offset: len(l.src)})
+	return l.tokens, nil
+}
+
+// atLineStart reports whether only whitespace precedes the current position on its line
+func (l *lexer) atLineStart() bool {
+	lineStart := strings.LastIndexByte(l.src[:l.pos], '\n') + 1
+	return strings.TrimSpace(l.src[lineStart:l.pos]) == ""
+}
+
+// atDataSection reports whether the current line is __END__, after which the file is data
+func (l *lexer) atDataSection() bool {
+	if !strings.HasPrefix(l.src[l.pos:], "__END__") || (l.pos > 0 && l.src[l.pos-1] != '\n') {
+		return false
+	}
+	rest := l.src[l.pos+len("__END__"):]
+	return rest == "" || rest[0] == '\n' || rest[0] == '\r'
+}
+
+// skipWhitespaceAndComments advances past spaces, comments and line continuations,
+// emitting newline tokens and collecting comment blocks as documentation
+func (l *lexer) skipWhitespaceAndComments() error {
+	l.spaceBefore = false
+	for l.pos < len(l.src) {
+		c := l.src[l.pos]
+		switch {
+		case c == '\n':
+			l.emit(tokenNewline, l.pos, l.pos+1)
+			l.line++
+			l.pos++
+			if err := l.skipHeredocBodies(); err != nil {
+				return err
+			}
+			if l.docLine < l.line-1 {
+				l.pendingDoc = nil // A blank line or code separates the comment from what follows
+			}
+		case c == ' ' || c == '\t' || c == '\r' || c == '\f':
+			l.pos++
+			l.spaceBefore = true
+		case c == '\\' && strings.HasPrefix(l.src[l.pos+1:], "\n"):
+			l.pos += 2
+			l.line++
+			l.spaceBefore = true
+		case c == '#':
+			l.skipComment()
+		case c == '=' && strings.HasPrefix(l.src[l.pos:], "=begin") && (l.pos == 0 || l.src[l.pos-1] == '\n'):
+			if err := l.skipBlockComment(); err != nil {
+				return err
+			}
+		default:
+			return nil
+		}
+	}
+	return nil
+}
+
+// skipComment advances past a # comment, keeping it as documentation when it starts its line
+func (l *lexer) skipComment() {
+	atLineStart := l.atLineStart()
+	end := strings.IndexByte(l.src[l.pos:], '\n')
+	if end < 0 {
+		end = len(l.src) - l.pos
+	}
+	comment := l.src[l.pos : l.pos+end]
+	l.pos += end
+
+	if !atLineStart {
+		return
+	}
+	if l.docLine != l.line-1 {
+		l.pendingDoc = nil
+	}
+	text := strings.TrimPrefix(strings.TrimPrefix(comment, "#"), " ")
+	l.pendingDoc = append(l.pendingDoc, strings.TrimRight(text, " \t\r"))
+	l.docLine = l.line
+}
+
+// skipBlockComment advances past an =begin ... =end comment
+func (l *lexer) skipBlockComment() error {
+	startLine := l.line
+	for l.pos < len(l.src) {
+		end := strings.IndexByte(l.src[l.pos:], '\n')
+		if end < 0 {
+			break
+		}
+		l.pos += end + 1
+		l.line++
+		if strings.HasPrefix(l.src[l.pos:], "=end") {
+			end := strings.IndexByte(l.src[l.pos:], '\n')
+			if end < 0 {
+				end = len(l.src) - l.pos
+			}
+			l.pos += end
+			return nil
+		}
+	}
+	return syntaxErrorf(startLine, "unterminated =begin comment")
+}
+
+// emit appends a token spanning src[start:end] at the current line
+func (l *lexer) emit(kind tokenKind, start, end int) {
+	l.emitAt(kind, start, end, l.line)
+}
+
+// emitAt appends a token spanning src[start:end] that starts on line
+func (l *lexer) emitAt(kind tokenKind, start, end, line int) {
+	tok := token{kind: kind, text: l.src[start:end], line: line, offset: start, spaceBefore: l.spaceBefore}
+	if kind != tokenNewline && len(l.pendingDoc) > 0 {
+		tok.doc = strings.TrimSpace(strings.Join(l.pendingDoc, "\n"))
+		l.pendingDoc = nil
+	}
+	l.tokens = append(l.tokens, tok)
+}
+
+// previous returns the last token emitted, or an EOF token at the start of the file
+func (l *lexer) previous() token {
+	if len(l.tokens) == 0 {
+		return token{kind: tokenEOF}
+	}
+	return l.tokens[len(l.tokens)-1]
+}
+
+// expectsOperand reports whether the next token starts an operand rather than continuing
+// an expression, which decides whether '/', '%', '<<', '?' and ':' open literals. After
+// a method name followed by a space, a literal is assumed unless another space follows,
+// so "split /,/" is a regular expression and "total / count" a division.
+func (l *lexer) expectsOperand() bool {
+	prev := l.previous()
+	next := byte(' ')
+	if l.pos+1 < len(l.src) {
+		next = l.src[l.pos+1]
+	}
+
+	switch prev.kind {
+	case tokenEOF, tokenNewline:
+		return true
+	case tokenPunct:
+		return prev.text != ")" && prev.text != "]" && prev.text != "}"
+	case tokenIdent:
+		if keywords[prev.text] {
+			// Operator method names follow def, as in def /(other)
+			return !valueKeywords[prev.text] && prev.text != "def"
+		}
+		if !l.spaceBefore {
+			return false
+		}
+		return next != ' ' && next != '=' && next != '\n'
+	}
+	return false
+}
+
+// lexToken reads one token at the current position
+func (l *lexer) lexToken() error {
+	start := l.pos
+	line := l.line
+	c := l.src[l.pos]
+
+	var kind tokenKind
+	var err error
+	switch {
+	case c == '"' || c == '`':
+		kind, err = tokenString, l.skipQuoted(c, 0, true)
+	case c == '\'':
+		kind, err = tokenString, l.skipQuoted(c, 0, false)
+	case c == '@' || (c == '$' && l.pos+1 < len(l.src)):
+		kind = tokenVariable
+		l.skipVariable()
+	case c == ':' && l.atSymbol():
+		kind, err = tokenSymbol, l.skipSymbol()
+	case c == '/' && l.expectsOperand():
+		kind, err = tokenString, l.skipRegexp()
+	case c == '%' && l.expectsOperand() && l.atPercentLiteral():
+		kind, err = tokenString, l.skipPercentLiteral()
+	case c == '<' && l.atHeredoc():
+		kind = tokenString
+		l.startHeredoc()
+	case c == '?' && l.expectsOperand() && l.atCharLiteral():
+		kind = tokenString
+		l.skipCharLiteral()
+	case isDigit(c):
+		kind = tokenNumber
+		l.skipNumber()
+	case isIdentStart(l.src[l.pos:]):
+		kind = tokenIdent
+		r, _ := utf8.DecodeRuneInString(l.src[l.pos:])
+		if unicode.IsUpper(r) {
+			kind = tokenConstant
+		}
+		l.skipIdent()
+	default:
+		kind = tokenPunct
+		l.pos++
+		for _, punct := range multiCharPuncts {
+			if strings.HasPrefix(l.src[start:], punct) {
+				l.pos = start + len(punct)
+				break
+			}
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	l.emitAt(kind, start, l.pos, line)
+	return nil
+}
+
+// skipQuoted advances past a literal closed by closing, which may span lines. A non-zero
+// opening bracket nests, as in %q(a (b) c); interpolate enables #{...} sequences.
+func (l *lexer) skipQuoted(opening, closing byte, interpolate bool) error {
+	if closing == 0 {
+		closing = opening
+		opening = 0
+	}
+	startLine := l.line
+	depth := 0
+	l.pos++
+	for l.pos < len(l.src) {
+		c := l.src[l.pos]
+		switch {
+		case c == '\\':
+			if l.pos+1 < len(l.src) && l.src[l.pos+1] == '\n' {
+				l.line++
+			}
+			l.pos += 2
+			continue
+		case c == '\n':
+			l.line++
+		case interpolate && c == '#' && strings.HasPrefix(l.src[l.pos:], "#{"):
+			if err := l.skipInterpolation(); err != nil {
+				return err
+			}
+			continue
+		case opening != 0 && c == opening:
+			depth++
+		case c == closing && depth > 0:
+			depth--
+		case c == closing:
+			l.pos++
+			return nil
+		}
+		l.pos++
+	}
+	return syntaxErrorf(startLine, "unterminated literal")
+}
+
+// skipInterpolation advances past a #{...} sequence, including nested strings and braces
+func (l *lexer) skipInterpolation() error {
+	startLine := l.line
+	l.pos += 2
+	depth := 1
+	for l.pos < len(l.src) {
+		c := l.src[l.pos]
+		switch c {
+		case '"', '`':
+			if err := l.skipQuoted(c, 0, true); err != nil {
+				return err
+			}
+			continue
+		case '\'':
+			if err := l.skipQuoted(c, 0, false); err != nil {
+				return err
+			}
+			continue
+		case '\n':
+			l.line++
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				l.pos++
+				return nil
+			}
+		}
+		l.pos++
+	}
+	return syntaxErrorf(startLine, "unterminated interpolation")
+}
+
+// skipVariable advances past an instance, class or global variable
+func (l *lexer) skipVariable() {
+	if l.src[l.pos] == '$' {
+		l.pos++
+		if l.pos < len(l.src) && !isIdentStart(l.src[l.pos:]) {
+			l.pos++ // Special globals such as $! and $~
+			return
+		}
+	} else {
+		l.pos++
+		if l.pos < len(l.src) && l.src[l.pos] == '@' {
+			l.pos++
+		}
+	}
+	l.skipIdentChars()
+}
+
+// atSymbol reports whether a ':' starts a symbol rather than a scope operator or a hash label
+func (l *lexer) atSymbol() bool {
+	if l.pos+1 >= len(l.src) || l.src[l.pos+1] == ':' {
+		return false
+	}
+	// A colon right after a word is a hash label separator, as in key:value
+	if prev := l.previous(); prev.end() == l.pos && (prev.kind == tokenIdent || prev.kind == tokenConstant || prev.kind == tokenString) {
+		return false
+	}
+	next := l.src[l.pos+1]
+	return next == '"' || next == '@' || next == '$' || isIdentStart(l.src[l.pos+1:])
+}
+
+// skipSymbol advances past a symbol such as :name, :name? or :"quoted name"
+func (l *lexer) skipSymbol() error {
+	l.pos++
+	switch l.src[l.pos] {
+	case '"':
+		return l.skipQuoted('"', 0, true)
+	case '@', '$':
+		l.skipVariable()
+	default:
+		l.skipIdent()
+		if l.pos < len(l.src) && l.src[l.pos] == '=' && !strings.HasPrefix(l.src[l.pos:], "=>") &&
+			!strings.HasPrefix(l.src[l.pos:], "==") {
+			l.pos++ // Setter names such as :name=
+		}
+	}
+	return nil
+}
+
+// skipRegexp advances past a /regular expression/ and its flags
+func (l *lexer) skipRegexp() error {
+	if err := l.skipQuoted('/', 0, true); err != nil {
+		return err
+	}
+	for l.pos < len(l.src) && strings.IndexByte("imxounse", l.src[l.pos]) >= 0 {
+		l.pos++
+	}
+	return nil
+}
+
+// percentDelimiters maps the opening brackets of percent literals to their closing ones
+var percentDelimiters = map[byte]byte{'(': ')', '[': ']', '{': '}', '<': '>'}
+
+// atPercentLiteral reports whether a '%' starts a literal such as %w[a b] or %(text)
+func (l *lexer) atPercentLiteral() bool {
+	rest := l.src[l.pos+1:]
+	if rest != "" && strings.IndexByte("qQwWiIrsx", rest[0]) >= 0 {
+		rest = rest[1:]
+	}
+	if rest == "" {
+		return false
+	}
+	delimiter := rest[0]
+	return percentDelimiters[delimiter] != 0 || strings.IndexByte("|!/^~", delimiter) >= 0
+}
+
+// skipPercentLiteral advances past a percent literal
+func (l *lexer) skipPercentLiteral() error {
+	l.pos++
+	interpolate := true
+	if c := l.src[l.pos]; strings.IndexByte("qQwWiIrsx", c) >= 0 {
+		interpolate = strings.IndexByte("QWIrx", c) >= 0
+		l.pos++
+	}
+	delimiter := l.src[l.pos]
+	if closing, ok := percentDelimiters[delimiter]; ok {
+		return l.skipQuoted(delimiter, closing, interpolate)
+	}
+	return l.skipQuoted(delimiter, 0, interpolate)
+}
+
+// atHeredoc reports whether '<<' opens a heredoc such as <<~SQL, <<-EOS or <<'TEXT'
+func (l *lexer) atHeredoc() bool {
+	rest := l.src[l.pos:]
+	if !strings.HasPrefix(rest, "<<") {
+		return false
+	}
+	rest = rest[2:]
+	if strings.HasPrefix(rest, "~") || strings.HasPrefix(rest, "-") {
+		rest = rest[1:]
+	} else if !l.expectsOperand() && !(l.spaceBefore && rest != "" && (rest[0] == '"' || rest[0] == '\'' || isUpper(rest[0]))) {
+		return false
+	}
+	return rest != "" && (rest[0] == '"' || rest[0] == '\'' || rest[0] == '`' || isIdentStart(rest))
+}
+
+// startHeredoc consumes a heredoc opener; its body is skipped at the end of the line
+func (l *lexer) startHeredoc() {
+	l.pos += 2
+	heredoc := pendingHeredoc{line: l.line}
+	if c := l.src[l.pos]; c == '~' || c == '-' {
+		heredoc.indented = true
+		l.pos++
+	}
+	if c := l.src[l.pos]; c == '"' || c == '\'' || c == '`' {
+		end := strings.IndexByte(l.src[l.pos+1:], c)
+		if end < 0 {
+			end = len(l.src) - l.pos - 1
+		}
+		heredoc.terminator = l.src[l.pos+1 : l.pos+1+end]
+		l.pos = min(l.pos+end+2, len(l.src))
+	} else {
+		start := l.pos
+		l.skipIdentChars()
+		heredoc.terminator = l.src[start:l.pos]
+	}
+	l.heredocs = append(l.heredocs, heredoc)
+}
+
+// skipHeredocBodies skips the bodies of the heredocs opened on the line just ended
+func (l *lexer) skipHeredocBodies() error {
+	for len(l.heredocs) > 0 {
+		heredoc := l.heredocs[0]
+		for {
+			if l.pos >= len(l.src) {
+				return syntaxErrorf(heredoc.line, "unterminated heredoc, expected '%s'", heredoc.terminator)
+			}
+			end := strings.IndexByte(l.src[l.pos:], '\n')
+			if end < 0 {
+				end = len(l.src) - l.pos
+			}
+			bodyLine := strings.TrimRight(l.src[l.pos:l.pos+end], "\r")
+			if heredoc.indented {
+				bodyLine = strings.TrimSpace(bodyLine)
+			}
+			l.pos = min(l.pos+end+1, len(l.src))
+			l.line++
+			if bodyLine == heredoc.terminator {
+				break
+			}
+		}
+		l.heredocs = l.heredocs[1:]
+	}
+	return nil
+}
+
+// atCharLiteral reports whether a '?' starts a character literal such as ?a or ?\n
+func (l *lexer) atCharLiteral() bool {
+	rest := l.src[l.pos+1:]
+	switch {
+	case rest == "" || rest[0] == ' ' || rest[0] == '\n' || rest[0] == '\t':
+		return false
+	case rest[0] == '\\':
+		return len(rest) > 1
+	}
+	_, size := utf8.DecodeRuneInString(rest)
+	return len(rest) == size || !isIdentChar(rest[size:])
+}
+
+// skipCharLiteral advances past a character literal
+func (l *lexer) skipCharLiteral() {
+	l.pos++
+	if l.src[l.pos] == '\\' {
+		l.pos++
+	}
+	_, size := utf8.DecodeRuneInString(l.src[l.pos:])
+	l.pos += size
+}
+
+// skipNumber advances past a numeric literal such as 1_000, 0x1f or 1.5e-3. A '.' is
+// only part of the number when a digit follows, so ranges such as 1..5 are left intact.
+func (l *lexer) skipNumber() {
+	isHex := strings.HasPrefix(strings.ToLower(l.src[l.pos:]), "0x")
+	for l.pos < len(l.src) {
+		c := l.src[l.pos]
+		switch {
+		case isDigit(c) || isLetter(c) || c == '_':
+			l.pos++
+		case c == '.' && l.pos+1 < len(l.src) && isDigit(l.src[l.pos+1]):
+			l.pos++
+		case (c == '+' || c == '-') && !isHex && (l.src[l.pos-1]|0x20) == 'e':
+			l.pos++
+		default:
+			return
+		}
+	}
+}
+
+// skipIdent advances past an identifier or keyword, including a ? or ! method suffix
+func (l *lexer) skipIdent() {
+	l.skipIdentChars()
+	if l.pos < len(l.src) && (l.src[l.pos] == '?' || l.src[l.pos] == '!') &&
+		!strings.HasPrefix(l.src[l.pos+1:], "=") && !strings.HasPrefix(l.src[l.pos+1:], ":") {
+		l.pos++
+	}
+}
+
+// skipIdentChars advances past identifier characters
+func (l *lexer) skipIdentChars() {
+	for l.pos < len(l.src) && isIdentChar(l.src[l.pos:]) {
+		_, size := utf8.DecodeRuneInString(l.src[l.pos:])
+		l.pos += size
+	}
+}
+
+// isIdentStart reports whether s begins with a Ruby identifier start character
+func isIdentStart(s string) bool {
+	r, _ := utf8.DecodeRuneInString(s)
+	return r == '_' || unicode.IsLetter(r)
+}
+
+// isIdentChar reports whether s begins with a character that may continue an identifier
+func isIdentChar(s string) bool {
+	r, _ := utf8.DecodeRuneInString(s)
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isLetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isUpper(c byte) bool {
+	return c >= 'A' && c <= 'Z'
+}
+
+// syntaxErrorf formats a syntax error at a source line.
+// ParseFile fills in the file path.
+func syntaxErrorf(line int, format string, args ...interface{}) error {
+	return &models.ParseError{Line: line, Kind: models.ParseErrorSyntax, Message: fmt.Sprintf(format, args...)}
+}
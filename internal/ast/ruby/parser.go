@@ -0,0 +1,941 @@
+package ruby
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"repository-context-protocol/internal/models"
+)
+
+const (
+	languageRuby  = "ruby"
+	extensionRuby = ".rb"
+
+	kindClass  = "class"
+	kindModule = "module"
+)
+
+// keywords are Ruby's reserved words
+var keywords = map[string]bool{
+	"alias": true, "and": true, "begin": true, "break": true, "case": true, "class": true,
+	"def": true, "defined?": true, "do": true, "else": true, "elsif": true, "end": true,
+	"ensure": true, "false": true, "for": true, "if": true, "in": true, "module": true,
+	"next": true, "nil": true, "not": true, "or": true, "redo": true, "rescue": true,
+	"retry": true, "return": true, "self": true, "super": true, "then": true, "true": true,
+	"undef": true, "unless": true, "until": true, "when": true, "while": true, "yield": true,
+	"__FILE__": true, "__LINE__": true, "__ENCODING__": true,
+}
+
+// valueKeywords are the keywords that are complete values, so an operator may follow them
+var valueKeywords = map[string]bool{
+	"end": true, "self": true, "true": true, "false": true, "nil": true,
+	"__FILE__": true, "__LINE__": true, "__ENCODING__": true,
+}
+
+// jumpKeywords are the keywords that may end a statement on their own, so an if or
+// while after them is a modifier, as in "return if done"
+var jumpKeywords = map[string]bool{
+	"return": true, "break": true, "next": true, "redo": true, "retry": true, "yield": true, "super": true,
+}
+
+// assignmentOperators are the operators that assign to the name on their left
+var assignmentOperators = map[string]bool{
+	"=": true, "+=": true, "-=": true, "*=": true, "/=": true, "%=": true, "**=": true,
+	"||=": true, "&&=": true, "|=": true, "&=": true, "^=": true, "<<=": true, ">>=": true,
+}
+
+// closingBrackets maps opening brackets to their closing ones
+var closingBrackets = map[string]string{"(": ")", "[": "]", "{": "}"}
+
+// attributeMacros declare accessor methods backed by instance variables
+var attributeMacros = map[string]bool{"attr_accessor": true, "attr_reader": true, "attr_writer": true}
+
+// mixinMacros add a module's methods to the enclosing class or module
+var mixinMacros = map[string]bool{"include": true, "prepend": true, "extend": true}
+
+// RubyParser implements the LanguageParser interface for Ruby files.
+// It is a lightweight structural parser: classes, modules, methods, requires
+// and constants are extracted from a single pass over the tokens, while method
+// bodies are only scanned for calls.
+type RubyParser struct{}
+
+// NewRubyParser creates a new Ruby parser instance
+func NewRubyParser() *RubyParser {
+	return &RubyParser{}
+}
+
+// GetSupportedExtensions returns the file extensions supported by this parser
+func (p *RubyParser) GetSupportedExtensions() []string {
+	return []string{extensionRuby}
+}
+
+// GetLanguageName returns the name of the language this parser handles
+func (p *RubyParser) GetLanguageName() string {
+	return languageRuby
+}
+
+// ParseFile parses a Ruby file and returns a FileContext
+func (p *RubyParser) ParseFile(path string, content []byte) (*models.FileContext, error) {
+	src := string(content)
+	tokens, err := tokenize(src)
+	if err != nil {
+		return nil, withPath(err, path)
+	}
+
+	// Calculate checksum of content
+	hash := sha256.Sum256(content)
+	checksum := fmt.Sprintf("%x", hash)
+
+	// Get modification time
+	var modTime time.Time
+	if fileInfo, err := os.Stat(path); err == nil {
+		modTime = fileInfo.ModTime()
+	} else {
+		// If file doesn't exist (e.g., in-memory parsing), use current time
+		modTime = time.Now()
+	}
+
+	ctx := &models.FileContext{
+		Path:      path,
+		Language:  languageRuby,
+		Checksum:  checksum,
+		ModTime:   modTime,
+		Functions: []models.Function{},
+		Types:     []models.TypeDef{},
+		Variables: []models.Variable{},
+		Constants: []models.Constant{},
+		Imports:   []models.Import{},
+		Exports:   []models.Export{},
+	}
+
+	fp := &fileParser{src: src, tokens: tokens, ctx: ctx, defEnds: make(map[int]int)}
+	if err := fp.parseProgram(); err != nil {
+		return nil, withPath(err, path)
+	}
+
+	// Build call graph relationships (second pass)
+	p.buildCallGraph(ctx)
+
+	if isTestFile(path) {
+		markTests(ctx)
+	}
+
+	return ctx, nil
+}
+
+// buildCallGraph builds the CalledBy relationships for the top-level methods of the file.
+// Callers inside a class or module are named Type.method.
+func (p *RubyParser) buildCallGraph(ctx *models.FileContext) {
+	funcMap := make(map[string]int)
+	for i := range ctx.Functions {
+		funcMap[ctx.Functions[i].Name] = i
+	}
+
+	link := func(caller string, calls []string) {
+		for _, calledName := range calls {
+			if targetIdx, exists := funcMap[calledName]; exists {
+				addUnique(&ctx.Functions[targetIdx].CalledBy, caller)
+				addUnique(&ctx.Functions[targetIdx].LocalCallers, caller)
+			}
+		}
+	}
+
+	for i := range ctx.Functions {
+		link(ctx.Functions[i].Name, ctx.Functions[i].LocalCalls)
+	}
+	for i := range ctx.Types {
+		for j := range ctx.Types[i].Methods {
+			link(ctx.Types[i].Name+"."+ctx.Types[i].Methods[j].Name, ctx.Types[i].Methods[j].Calls)
+		}
+	}
+}
+
+// addUnique appends value to list if it is not already present
+func addUnique(list *[]string, value string) {
+	if !slices.Contains(*list, value) {
+		*list = append(*list, value)
+	}
+}
+
+// isTestFile reports whether a file is named as Minitest discovers tests:
+// test_*.rb or *_test.rb
+func isTestFile(path string) bool {
+	base := filepath.Base(path)
+	return strings.HasPrefix(base, "test_") || strings.HasSuffix(base, "_test.rb")
+}
+
+// markTests flags the test_* methods of Minitest classes: classes named *Test or
+// inheriting from a *Test or *TestCase class such as Minitest::Test
+func markTests(ctx *models.FileContext) {
+	for i := range ctx.Types {
+		typeDef := &ctx.Types[i]
+		isTestClass := typeDef.Kind == kindClass && (strings.HasSuffix(typeDef.Name, "Test") ||
+			slices.ContainsFunc(typeDef.Embedded, func(base string) bool {
+				return strings.HasSuffix(base, "Test") || strings.HasSuffix(base, "TestCase")
+			}))
+		if !isTestClass {
+			continue
+		}
+		for j := range typeDef.Methods {
+			typeDef.Methods[j].IsTest = strings.HasPrefix(typeDef.Methods[j].Name, "test_")
+		}
+	}
+}
+
+// withPath attaches the file path to a parse error
+func withPath(err error, path string) error {
+	var parseErr *models.ParseError
+	if errors.As(err, &parseErr) {
+		parseErr.File = path
+		return parseErr
+	}
+	return fmt.Errorf("%s: %w", path, err)
+}
+
+// frame is an open bracket or keyword block awaiting its closing token
+type frame struct {
+	opener    token
+	typeIndex int  // Index into ctx.Types of the class or module whose body this is, or -1
+	singleton bool // class << self: methods belong to the enclosing type
+	def       *methodDef
+}
+
+// methodDef is a method whose body is being parsed
+type methodDef struct {
+	index      int // Token index of the def keyword
+	name       string
+	signature  string
+	parameters []models.Parameter
+	owner      int // Index into ctx.Types of the enclosing class or module, or -1
+	bodyStart  int // Token index where the body begins
+}
+
+// fileParser holds the parsing state for a single file
+type fileParser struct {
+	src    string
+	tokens []token
+	pos    int
+	ctx    *models.FileContext
+	stack  []frame
+
+	// awaitingLoopDo is set after while, until or for until the end of the line,
+	// where an optional do belongs to the loop rather than opening a block
+	awaitingLoopDo bool
+
+	// defEnds maps the def keyword of each parsed method to its end token,
+	// so nested methods are left out of the enclosing method's calls
+	defEnds map[int]int
+}
+
+// peek returns the token offset positions ahead without consuming it
+func (fp *fileParser) peek(offset int) token {
+	return fp.tokenAt(fp.pos + offset)
+}
+
+// tokenAt returns the token at index i, or the EOF token when i is out of range
+func (fp *fileParser) tokenAt(i int) token {
+	if i < 0 || i >= len(fp.tokens) {
+		return fp.tokens[len(fp.tokens)-1]
+	}
+	return fp.tokens[i]
+}
+
+// next consumes and returns the current token
+func (fp *fileParser) next() token {
+	tok := fp.peek(0)
+	if tok.kind != tokenEOF {
+		fp.pos++
+	}
+	return tok
+}
+
+// at reports whether the current token is the punctuation or keyword text
+func (fp *fileParser) at(text string) bool {
+	tok := fp.peek(0)
+	return (tok.kind == tokenPunct || tok.kind == tokenIdent) && tok.text == text
+}
+
+// atLineEnd reports whether the current token ends a statement
+func (fp *fileParser) atLineEnd() bool {
+	tok := fp.peek(0)
+	return tok.kind == tokenNewline || tok.kind == tokenEOF || (tok.kind == tokenPunct && tok.text == ";")
+}
+
+// unexpected builds a syntax error describing the current token
+func (fp *fileParser) unexpected(reason string) error {
+	tok := fp.peek(0)
+	switch tok.kind {
+	case tokenEOF:
+		return syntaxErrorf(tok.line, "%s, found end of file", reason)
+	case tokenNewline:
+		return syntaxErrorf(tok.line, "%s, found end of line", reason)
+	}
+	return syntaxErrorf(tok.line, "%s, found '%s'", reason, tok.text)
+}
+
+// isKeyword reports whether the token at index i is used as a keyword rather than
+// as a method name after '.', or as a hash label such as if: or end:
+func (fp *fileParser) isKeyword(i int) bool {
+	tok := fp.tokenAt(i)
+	if tok.kind != tokenIdent || !keywords[tok.text] {
+		return false
+	}
+	if next := fp.tokenAt(i + 1); next.kind == tokenPunct && next.text == ":" && !next.spaceBefore {
+		return false
+	}
+	prev := fp.tokenAt(i - 1)
+	return prev.kind != tokenPunct || (prev.text != "." && prev.text != "&." && prev.text != "::")
+}
+
+// atStatementStart reports whether the current token begins a statement
+func (fp *fileParser) atStatementStart() bool {
+	if fp.pos == 0 {
+		return true
+	}
+	prev := fp.tokens[fp.pos-1]
+	return prev.kind == tokenNewline || (prev.kind == tokenPunct && prev.text == ";")
+}
+
+// startsExpression reports whether the current token begins an expression, which
+// distinguishes an if or while statement from a modifier as in "retry if failed"
+func (fp *fileParser) startsExpression() bool {
+	if fp.pos == 0 {
+		return true
+	}
+	prev := fp.tokens[fp.pos-1]
+	switch prev.kind {
+	case tokenNewline:
+		return true
+	case tokenPunct:
+		return prev.text != ")" && prev.text != "]" && prev.text != "}"
+	case tokenIdent:
+		return fp.isKeyword(fp.pos-1) && !valueKeywords[prev.text] && !jumpKeywords[prev.text]
+	}
+	return false
+}
+
+// lineDoc returns the documentation preceding the line containing token index i,
+// so "private def helper" is documented by the comment above it
+func (fp *fileParser) lineDoc(i int) string {
+	for i > 0 && fp.tokens[i-1].kind != tokenNewline {
+		i--
+	}
+	return fp.tokens[i].doc
+}
+
+// sourceText returns the source spanning tokens[first..last] with whitespace collapsed
+func (fp *fileParser) sourceText(first, last int) string {
+	if last < first {
+		return ""
+	}
+	return strings.Join(strings.Fields(fp.src[fp.tokens[first].offset:fp.tokens[last].end()]), " ")
+}
+
+// parseProgram parses the file, tracking nested blocks until the end of the file
+func (fp *fileParser) parseProgram() error {
+	for fp.peek(0).kind != tokenEOF {
+		if err := fp.parseToken(); err != nil {
+			return err
+		}
+	}
+	if len(fp.stack) > 0 {
+		open := fp.stack[len(fp.stack)-1].opener
+		return syntaxErrorf(fp.peek(0).line, "unexpected end of file, '%s' at line %d is not closed", open.text, open.line)
+	}
+	return nil
+}
+
+// parseToken handles the current token and advances past it
+func (fp *fileParser) parseToken() error {
+	tok := fp.peek(0)
+	switch {
+	case tok.kind == tokenNewline || (tok.kind == tokenPunct && tok.text == ";"):
+		fp.awaitingLoopDo = false
+	case tok.kind == tokenPunct:
+		return fp.parseBracket(tok)
+	case fp.isKeyword(fp.pos):
+		return fp.parseKeyword(tok)
+	case fp.atStatementStart():
+		fp.parseStatement(tok)
+	}
+	fp.pos++
+	return nil
+}
+
+// push opens a bracket or block
+func (fp *fileParser) push(f frame) {
+	fp.stack = append(fp.stack, f)
+}
+
+// pop closes the innermost bracket or block with the closing token
+func (fp *fileParser) pop(closing token) error {
+	if len(fp.stack) == 0 {
+		return syntaxErrorf(closing.line, "unexpected '%s'", closing.text)
+	}
+	top := fp.stack[len(fp.stack)-1]
+	expected, isBracket := closingBrackets[top.opener.text]
+	if !isBracket {
+		expected = "end"
+	}
+	if closing.text != expected {
+		return syntaxErrorf(closing.line, "unexpected '%s', expected '%s' for '%s' at line %d",
+			closing.text, expected, top.opener.text, top.opener.line)
+	}
+	fp.stack = fp.stack[:len(fp.stack)-1]
+
+	if top.typeIndex >= 0 && !top.singleton {
+		fp.ctx.Types[top.typeIndex].EndLine = closing.line
+	}
+	if top.def != nil {
+		fp.finishMethod(top.def, fp.pos-1)
+	}
+	return nil
+}
+
+// parseBracket tracks opening and closing brackets
+func (fp *fileParser) parseBracket(tok token) error {
+	fp.pos++
+	switch tok.text {
+	case "(", "[", "{":
+		fp.push(frame{opener: tok, typeIndex: -1})
+	case ")", "]", "}":
+		return fp.pop(tok)
+	}
+	return nil
+}
+
+// parseKeyword handles the keywords that open and close blocks
+func (fp *fileParser) parseKeyword(tok token) error {
+	switch tok.text {
+	case "class":
+		return fp.parseClass(tok)
+	case "module":
+		return fp.parseModule(tok)
+	case "def":
+		return fp.parseMethod(tok)
+	case "end":
+		fp.pos++
+		return fp.pop(tok)
+	case "do":
+		if !fp.awaitingLoopDo {
+			fp.push(frame{opener: tok, typeIndex: -1})
+		}
+		fp.awaitingLoopDo = false
+	case "if", "unless", "while", "until":
+		if fp.startsExpression() {
+			fp.push(frame{opener: tok, typeIndex: -1})
+			fp.awaitingLoopDo = tok.text == "while" || tok.text == "until"
+		}
+	case "case", "begin", "for":
+		fp.push(frame{opener: tok, typeIndex: -1})
+		fp.awaitingLoopDo = tok.text == "for"
+	}
+	fp.pos++
+	return nil
+}
+
+// enclosingType returns the index of the innermost class or module being parsed, or -1
+func (fp *fileParser) enclosingType() int {
+	for i := len(fp.stack) - 1; i >= 0; i-- {
+		if fp.stack[i].typeIndex >= 0 {
+			return fp.stack[i].typeIndex
+		}
+	}
+	return -1
+}
+
+// inMethod reports whether the current token is inside a method body
+func (fp *fileParser) inMethod() bool {
+	return slices.ContainsFunc(fp.stack, func(f frame) bool { return f.def != nil })
+}
+
+// bodyType returns the index of the class or module whose body directly contains
+// the current token, or -1
+func (fp *fileParser) bodyType() int {
+	if len(fp.stack) == 0 {
+		return -1
+	}
+	if top := fp.stack[len(fp.stack)-1]; !top.singleton {
+		return top.typeIndex
+	}
+	return -1
+}
+
+// parseClass parses a class header: class Name < Superclass, or class << self
+func (fp *fileParser) parseClass(tok token) error {
+	start := fp.pos
+	fp.next() // class
+	if fp.at("<<") {
+		// The singleton class holds class methods of the enclosing type
+		fp.push(frame{opener: tok, typeIndex: fp.enclosingType(), singleton: true})
+		fp.next()
+		return nil
+	}
+
+	name, err := fp.parseConstantPath("expected class name")
+	if err != nil {
+		return err
+	}
+	typeDef := models.TypeDef{Name: name, Kind: kindClass, StartLine: tok.line, Doc: fp.lineDoc(start)}
+
+	if fp.at("<") {
+		fp.next()
+		superclass, err := fp.readToLineEnd()
+		if err != nil {
+			return err
+		}
+		if len(superclass) > 0 {
+			typeDef.Embedded = append(typeDef.Embedded, fp.sourceText(fp.pos-len(superclass), fp.pos-1))
+		}
+	}
+
+	fp.addType(tok, typeDef)
+	return nil
+}
+
+// parseModule parses a module header
+func (fp *fileParser) parseModule(tok token) error {
+	start := fp.pos
+	fp.next() // module
+	name, err := fp.parseConstantPath("expected module name")
+	if err != nil {
+		return err
+	}
+	fp.addType(tok, models.TypeDef{Name: name, Kind: kindModule, StartLine: tok.line, Doc: fp.lineDoc(start)})
+	return nil
+}
+
+// addType records a class or module and opens its body. Types are recorded when
+// opened so outer types precede their nested types.
+func (fp *fileParser) addType(opener token, typeDef models.TypeDef) {
+	fp.ctx.Types = append(fp.ctx.Types, typeDef)
+	fp.push(frame{opener: opener, typeIndex: len(fp.ctx.Types) - 1})
+	fp.ctx.Exports = append(fp.ctx.Exports, models.Export{Name: typeDef.Name, Type: typeDef.Kind, Kind: "type"})
+}
+
+// parseConstantPath parses a name such as Admin::User and returns its last segment
+func (fp *fileParser) parseConstantPath(reason string) (string, error) {
+	if fp.at("::") {
+		fp.next()
+	}
+	for {
+		if fp.peek(0).kind != tokenConstant {
+			return "", fp.unexpected(reason)
+		}
+		name := fp.next().text
+		if !fp.at("::") {
+			return name, nil
+		}
+		fp.next()
+	}
+}
+
+// readToLineEnd consumes the tokens up to the end of the statement, continuing
+// across lines inside brackets. A closing bracket of an enclosing group ends the
+// statement without being consumed.
+func (fp *fileParser) readToLineEnd() ([]token, error) {
+	start := fp.pos
+	for !fp.atLineEnd() {
+		tok := fp.peek(0)
+		if tok.kind == tokenPunct && closingBrackets[tok.text] != "" {
+			if _, err := fp.readGroup(); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if tok.kind == tokenPunct && (tok.text == ")" || tok.text == "]" || tok.text == "}") {
+			break
+		}
+		fp.next()
+	}
+	return fp.tokens[start:fp.pos], nil
+}
+
+// readGroup consumes a bracketed group and returns its tokens including the brackets
+func (fp *fileParser) readGroup() ([]token, error) {
+	start := fp.pos
+	var open []token
+	for {
+		tok := fp.next()
+		switch {
+		case tok.kind == tokenEOF:
+			innermost := open[len(open)-1]
+			return nil, syntaxErrorf(tok.line, "unexpected end of file, '%s' at line %d is not closed", innermost.text, innermost.line)
+		case tok.kind != tokenPunct:
+		case closingBrackets[tok.text] != "":
+			open = append(open, tok)
+		case tok.text == ")" || tok.text == "]" || tok.text == "}":
+			if expected := closingBrackets[open[len(open)-1].text]; tok.text != expected {
+				return nil, syntaxErrorf(tok.line, "unexpected '%s', expected '%s'", tok.text, expected)
+			}
+			open = open[:len(open)-1]
+			if len(open) == 0 {
+				return fp.tokens[start:fp.pos], nil
+			}
+		}
+	}
+}
+
+// parseMethod parses a method definition header. Endless methods such as
+// "def full_name = first + last" are complete at the end of the line; other
+// methods open a block that pop completes at the matching end.
+func (fp *fileParser) parseMethod(tok token) error {
+	def := &methodDef{index: fp.pos, owner: fp.enclosingType()}
+	fp.next() // def
+
+	// Singleton methods: def self.create or def Config.load
+	if (fp.at("self") || fp.peek(0).kind == tokenConstant) && fp.peek(1).text == "." && !fp.peek(1).spaceBefore {
+		fp.pos += 2
+	}
+	if fp.atLineEnd() {
+		return fp.unexpected("expected method name")
+	}
+
+	// Names may carry suffixes or be operators: name=, [], []=, <=>, -@
+	def.name = fp.next().text
+	for next := fp.peek(0); !next.spaceBefore && (next.kind == tokenPunct || next.kind == tokenVariable); next = fp.peek(0) {
+		if next.text == "(" || next.text == ";" || next.text == "." {
+			break
+		}
+		def.name += fp.next().text
+	}
+
+	var params []token
+	switch {
+	case fp.at("("):
+		group, err := fp.readGroup()
+		if err != nil {
+			return err
+		}
+		params = group[1 : len(group)-1]
+	case !fp.atLineEnd() && !fp.at("="):
+		group, err := fp.readToLineEnd()
+		if err != nil {
+			return err
+		}
+		params = group
+	}
+	def.parameters = parameters(params)
+	def.signature = fp.sourceText(def.index, fp.pos-1)
+
+	if fp.at("=") {
+		fp.next()
+		body, err := fp.readToLineEnd()
+		if err != nil {
+			return err
+		}
+		if len(body) == 0 {
+			return fp.unexpected("expected method body")
+		}
+		def.bodyStart = fp.pos - len(body)
+		fp.finishMethod(def, fp.pos-1)
+		return nil
+	}
+
+	def.bodyStart = fp.pos
+	fp.push(frame{opener: tok, typeIndex: -1, def: def})
+	return nil
+}
+
+// parameters extracts the parameter names from a parameter list. Ruby parameters
+// are untyped; the *, ** and & sigils and default values are left out.
+func parameters(tokens []token) []models.Parameter {
+	params := []models.Parameter{}
+	depth := 0
+	expectName := true
+	for _, tok := range tokens {
+		switch {
+		case tok.kind == tokenPunct && closingBrackets[tok.text] != "":
+			depth++
+		case tok.kind == tokenPunct && (tok.text == ")" || tok.text == "]" || tok.text == "}"):
+			depth--
+		case depth > 0:
+		case tok.kind == tokenPunct && tok.text == ",":
+			expectName = true
+		case expectName && (tok.kind == tokenIdent || tok.text == "..."):
+			params = append(params, models.Parameter{Name: tok.text})
+			expectName = false
+		}
+	}
+	return params
+}
+
+// finishMethod records a parsed method. Methods inside a class or module belong to
+// it; top-level methods are functions and participate in the file's call graph.
+func (fp *fileParser) finishMethod(def *methodDef, end int) {
+	fp.defEnds[def.index] = end
+
+	locals := make(map[string]bool)
+	for _, param := range def.parameters {
+		locals[param.Name] = true
+	}
+	calls := fp.collectCalls(def.bodyStart, end, locals)
+	callNames := make([]string, len(calls))
+	for i, call := range calls {
+		callNames[i] = call.FunctionName
+	}
+
+	start := fp.tokens[def.index]
+	endLine := fp.tokens[end].line
+	if def.owner >= 0 {
+		typeDef := &fp.ctx.Types[def.owner]
+		typeDef.Methods = append(typeDef.Methods, models.Method{
+			Name:       def.name,
+			Signature:  def.signature,
+			Parameters: def.parameters,
+			StartLine:  start.line,
+			EndLine:    endLine,
+			Doc:        fp.lineDoc(def.index),
+			LineCount:  models.LineCount(start.line, endLine),
+			Calls:      callNames,
+		})
+		return
+	}
+
+	fp.ctx.Functions = append(fp.ctx.Functions, models.Function{
+		Name:                   def.name,
+		Signature:              def.signature,
+		Parameters:             def.parameters,
+		StartLine:              start.line,
+		EndLine:                endLine,
+		Doc:                    fp.lineDoc(def.index),
+		LineCount:              models.LineCount(start.line, endLine),
+		Calls:                  callNames,
+		CalledBy:               []string{},
+		LocalCalls:             slices.Clone(callNames),
+		LocalCallsWithMetadata: calls,
+		CrossFileCalls:         []models.CallReference{},
+		LocalCallers:           []string{},
+		CrossFileCallers:       []models.CallReference{},
+	})
+	fp.ctx.Exports = append(fp.ctx.Exports, models.Export{Name: def.name, Type: def.signature, Kind: "function"})
+}
+
+// collectCalls scans the tokens of a method body for calls, skipping nested methods.
+// Names assigned in the body, block parameters and rescued exceptions are locals, so
+// a bare identifier is only a call when it does not name one.
+func (fp *fileParser) collectCalls(start, end int, locals map[string]bool) []models.CallReference {
+	calls := []models.CallReference{}
+	seen := make(map[string]bool)
+	for i := start; i < end; i++ {
+		if nestedEnd, ok := fp.defEnds[i]; ok {
+			i = nestedEnd
+			continue
+		}
+		tok := fp.tokens[i]
+		if tok.kind == tokenPunct && tok.text == "|" && fp.opensBlockParams(i) {
+			i = fp.declareBlockParams(i, end, locals)
+			continue
+		}
+		call, ok := fp.callAt(i, locals)
+		if !ok || seen[call.FunctionName] {
+			continue
+		}
+		seen[call.FunctionName] = true
+		calls = append(calls, call)
+	}
+	return calls
+}
+
+// callAt returns the call made by the identifier at token index i, if any
+func (fp *fileParser) callAt(i int, locals map[string]bool) (models.CallReference, bool) {
+	tok, prev, next := fp.tokens[i], fp.tokenAt(i-1), fp.tokenAt(i+1)
+	call := models.CallReference{FunctionName: tok.text, Line: tok.line, CallType: models.CallTypeFunction}
+
+	switch {
+	case tok.kind == tokenConstant:
+		// Conversion methods such as Integer("42")
+		return call, next.text == "(" && !next.spaceBefore
+	case tok.kind != tokenIdent || fp.isKeyword(i):
+		return call, false
+	case next.kind == tokenPunct && next.text == ":" && !next.spaceBefore:
+		return call, false // Hash label or keyword argument
+	case prev.kind == tokenPunct && (prev.text == "." || prev.text == "&."):
+		if next.kind == tokenPunct && next.text == "=" {
+			return call, false // Attribute assignment: self.name = value
+		}
+		return fp.methodCall(i, call), true
+	case prev.kind == tokenPunct && prev.text == "::":
+		return call, false
+	case next.kind == tokenPunct && assignmentOperators[next.text],
+		fp.isMultipleAssignment(i),
+		prev.kind == tokenIdent && prev.text == "for",
+		prev.kind == tokenPunct && prev.text == "=>" && (next.kind == tokenNewline || next.text == "then"):
+		// Local variable assignments, loop variables and rescue Error => e
+		locals[tok.text] = true
+		return call, false
+	}
+	return call, !locals[tok.text]
+}
+
+// isMultipleAssignment reports whether the identifier at index i is one of the
+// targets of a multiple assignment such as "status, body = response"
+func (fp *fileParser) isMultipleAssignment(i int) bool {
+	for j := i; fp.tokenAt(j).kind == tokenIdent; j += 2 {
+		next := fp.tokenAt(j + 1)
+		if next.kind != tokenPunct {
+			return false
+		}
+		if next.text == "=" {
+			return j > i
+		}
+		if next.text != "," {
+			return false
+		}
+	}
+	return false
+}
+
+// methodCall qualifies a call on a receiver. Calls on a simple receiver are named
+// receiver.method like the other parsers; self.method calls the enclosing class.
+func (fp *fileParser) methodCall(i int, call models.CallReference) models.CallReference {
+	call.CallType = models.CallTypeMethod
+	receiver, before := fp.tokenAt(i-2), fp.tokenAt(i-3)
+	switch {
+	case receiver.kind == tokenIdent && receiver.text == "self":
+		call.CallType = models.CallTypeFunction
+	case (receiver.kind == tokenIdent && !keywords[receiver.text]) || receiver.kind == tokenConstant || receiver.kind == tokenVariable:
+		if before.kind != tokenPunct || (before.text != "." && before.text != "&." && before.text != "::") {
+			call.FunctionName = receiver.text + "." + call.FunctionName
+		}
+	}
+	return call
+}
+
+// opensBlockParams reports whether the '|' at index i opens block parameters, as in do |item|
+func (fp *fileParser) opensBlockParams(i int) bool {
+	prev := fp.tokenAt(i - 1)
+	return (prev.kind == tokenPunct && prev.text == "{") || (prev.kind == tokenIdent && prev.text == "do")
+}
+
+// declareBlockParams records block parameters as locals and returns the index of the closing '|'
+func (fp *fileParser) declareBlockParams(i, end int, locals map[string]bool) int {
+	for i++; i < end; i++ {
+		tok := fp.tokens[i]
+		if tok.kind == tokenPunct && tok.text == "|" {
+			return i
+		}
+		if tok.kind == tokenIdent {
+			locals[tok.text] = true
+		}
+	}
+	return i
+}
+
+// parseStatement records declarations made by statements that start a line:
+// requires, attribute and mixin macros in a class body, and constant assignments
+func (fp *fileParser) parseStatement(tok token) {
+	switch {
+	case tok.kind == tokenIdent && (tok.text == "require" || tok.text == "require_relative"):
+		fp.parseRequire(tok)
+	case tok.kind == tokenIdent && attributeMacros[tok.text]:
+		if typeIndex := fp.bodyType(); typeIndex >= 0 {
+			for _, arg := range fp.macroArgs() {
+				if arg.kind == tokenSymbol || arg.kind == tokenString {
+					field := models.Field{Name: strings.TrimPrefix(unquote(arg), ":")}
+					fp.ctx.Types[typeIndex].Fields = append(fp.ctx.Types[typeIndex].Fields, field)
+				}
+			}
+		}
+	case tok.kind == tokenIdent && mixinMacros[tok.text]:
+		if typeIndex := fp.bodyType(); typeIndex >= 0 {
+			fp.ctx.Types[typeIndex].Embedded = append(fp.ctx.Types[typeIndex].Embedded, fp.constantArgs()...)
+		}
+	case tok.kind == tokenConstant && fp.peek(1).kind == tokenPunct && fp.peek(1).text == "=" && !fp.inMethod():
+		fp.parseConstant(tok)
+	}
+}
+
+// macroArgs returns the arguments of a macro call on the current line, such as
+// the symbols of attr_reader :name, :email
+func (fp *fileParser) macroArgs() []token {
+	var args []token
+	for i := fp.pos + 1; ; i++ {
+		tok := fp.tokenAt(i)
+		if tok.kind == tokenNewline || tok.kind == tokenEOF || (tok.kind == tokenPunct && tok.text == ";") {
+			return args
+		}
+		args = append(args, tok)
+	}
+}
+
+// constantArgs returns the constant paths passed to a macro, such as the modules
+// of include Comparable, Enumerable
+func (fp *fileParser) constantArgs() []string {
+	var names []string
+	args := fp.macroArgs()
+	for i := 0; i < len(args); i++ {
+		if args[i].kind != tokenConstant {
+			continue
+		}
+		name := args[i].text
+		for i+2 < len(args) && args[i+1].text == "::" && args[i+2].kind == tokenConstant {
+			name += "::" + args[i+2].text
+			i += 2
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
+// parseRequire records require "lib" and require_relative "path" as imports.
+// Relative requires are recorded as ./path, so they resolve like relative imports.
+func (fp *fileParser) parseRequire(tok token) {
+	args := fp.macroArgs()
+	if len(args) > 0 && args[0].text == "(" {
+		args = args[1:]
+	}
+	if len(args) == 0 || args[0].kind != tokenString || strings.Contains(args[0].text, "#{") {
+		return
+	}
+	path := unquote(args[0])
+	if tok.text == "require_relative" && !strings.HasPrefix(path, ".") {
+		path = "./" + path
+	}
+	fp.ctx.Imports = append(fp.ctx.Imports, models.Import{Path: path})
+}
+
+// parseConstant records a constant assignment such as MAX_RETRIES = 3
+func (fp *fileParser) parseConstant(tok token) {
+	value := fp.macroArgs()[1:] // Skip '='
+	constant := models.Constant{
+		Name:      tok.text,
+		Value:     literalValue(value),
+		StartLine: tok.line,
+		EndLine:   tok.line,
+		Doc:       tok.doc,
+		Scope:     models.ScopePackage,
+	}
+	fp.ctx.Constants = append(fp.ctx.Constants, constant)
+	fp.ctx.Exports = append(fp.ctx.Exports, models.Export{Name: tok.text, Kind: "constant"})
+}
+
+// literalValue returns the value text when it is a single literal
+func literalValue(value []token) string {
+	switch {
+	case len(value) == 1 && strings.HasPrefix(value[0].text, "<<"):
+		return "" // Heredoc bodies are not kept
+	case len(value) == 1 && value[0].kind != tokenPunct && value[0].kind != tokenIdent:
+		return value[0].text
+	case len(value) == 1 && (value[0].text == "true" || value[0].text == "false" || value[0].text == "nil"):
+		return value[0].text
+	case len(value) == 2 && value[0].text == "-" && value[1].kind == tokenNumber:
+		return "-" + value[1].text
+	}
+	return ""
+}
+
+// unquote strips the quotes from a simple string literal
+func unquote(tok token) string {
+	text := tok.text
+	if len(text) >= 2 && (text[0] == '"' || text[0] == '\'') && text[len(text)-1] == text[0] {
+		return text[1 : len(text)-1]
+	}
+	return text
+}
@@ -0,0 +1,406 @@
+package ruby
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"repository-context-protocol/internal/models"
+)
+
+const sampleRuby = `require "json"
+require_relative "models/base"
+
+# Shared helpers for formatting.
+module Formatting
+  DEFAULT_SEPARATOR = ", "
+
+  def format_list(items)
+    items.join(DEFAULT_SEPARATOR)
+  end
+end
+
+module Accounts
+  # A registered user.
+  class User < Models::Base
+    include Formatting
+    include Comparable
+    attr_accessor :name, :email
+    attr_reader :id
+    MAX_NAME = 64
+
+    # Creates a user from attributes.
+    def self.create(attrs = {}, **options)
+      user = new(attrs)
+      user.save
+      user
+    end
+
+    def initialize(attrs)
+      @name = normalize(attrs[:name])
+      @email = attrs.fetch(:email) { |key| default_email(key) }
+    end
+
+    def display_name
+      return name if email.nil?
+      "#{name} <#{email}>"
+    end
+
+    def <=>(other)
+      name <=> other.name
+    end
+
+    def valid? = !name.empty? && name.length <= MAX_NAME
+
+    private
+
+    def default_email(key)
+      format("%s@example.com", key)
+    end
+
+    class << self
+      def table_name
+        "users"
+      end
+    end
+  end
+end
+
+def normalize(value)
+  value.to_s.strip
+end
+
+def load_users(path)
+  data, errors = JSON.parse(File.read(path)), []
+  data.map do |row|
+    normalize(row["name"]) unless row.empty?
+  end
+rescue JSON::ParserError => e
+  warn e.message
+  []
+end
+
+SQL = <<~SQL
+  SELECT * FROM users
+  WHERE name = 'end'
+SQL
+`
+
+func parseSample(t *testing.T) *models.FileContext {
+	t.Helper()
+	fileContext, err := NewRubyParser().ParseFile("accounts.rb", []byte(sampleRuby))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	return fileContext
+}
+
+func findType(fileContext *models.FileContext, name string) *models.TypeDef {
+	for i := range fileContext.Types {
+		if fileContext.Types[i].Name == name {
+			return &fileContext.Types[i]
+		}
+	}
+	return nil
+}
+
+func findFunction(fileContext *models.FileContext, name string) *models.Function {
+	for i := range fileContext.Functions {
+		if fileContext.Functions[i].Name == name {
+			return &fileContext.Functions[i]
+		}
+	}
+	return nil
+}
+
+func findMethod(typeDef *models.TypeDef, name string) *models.Method {
+	for i := range typeDef.Methods {
+		if typeDef.Methods[i].Name == name {
+			return &typeDef.Methods[i]
+		}
+	}
+	return nil
+}
+
+func TestRubyParser_Interface(t *testing.T) {
+	parser := NewRubyParser()
+
+	if parser.GetLanguageName() != "ruby" {
+		t.Errorf("Expected language 'ruby', got %s", parser.GetLanguageName())
+	}
+	extensions := parser.GetSupportedExtensions()
+	if len(extensions) != 1 || extensions[0] != ".rb" {
+		t.Errorf("Expected [.rb], got %v", extensions)
+	}
+}
+
+func TestRubyParser_Imports(t *testing.T) {
+	fileContext := parseSample(t)
+
+	if fileContext.Language != "ruby" {
+		t.Errorf("Expected language 'ruby', got %s", fileContext.Language)
+	}
+
+	var paths []string
+	for _, imp := range fileContext.Imports {
+		paths = append(paths, imp.Path)
+	}
+	expected := []string{"json", "./models/base"}
+	if !slices.Equal(paths, expected) {
+		t.Errorf("Expected imports %v, got %v", expected, paths)
+	}
+}
+
+func TestRubyParser_Types(t *testing.T) {
+	fileContext := parseSample(t)
+
+	var names []string
+	for _, typeDef := range fileContext.Types {
+		names = append(names, typeDef.Name+":"+typeDef.Kind)
+	}
+	expected := []string{"Formatting:module", "Accounts:module", "User:class"}
+	if !slices.Equal(names, expected) {
+		t.Errorf("Expected types %v with outer types first, got %v", expected, names)
+	}
+
+	formatting := findType(fileContext, "Formatting")
+	if formatting.Doc != "Shared helpers for formatting." {
+		t.Errorf("Expected module doc, got %q", formatting.Doc)
+	}
+
+	user := findType(fileContext, "User")
+	if user.Doc != "A registered user." {
+		t.Errorf("Expected class doc, got %q", user.Doc)
+	}
+	if user.StartLine != 15 || user.EndLine != 56 {
+		t.Errorf("Expected User at lines 15-56, got %d-%d", user.StartLine, user.EndLine)
+	}
+	if !slices.Equal(user.Embedded, []string{"Models::Base", "Formatting", "Comparable"}) {
+		t.Errorf("Expected superclass and mixins as embedded types, got %v", user.Embedded)
+	}
+
+	var fieldNames []string
+	for _, field := range user.Fields {
+		fieldNames = append(fieldNames, field.Name)
+	}
+	if !slices.Equal(fieldNames, []string{"name", "email", "id"}) {
+		t.Errorf("Expected attribute fields, got %v", fieldNames)
+	}
+
+	accounts := findType(fileContext, "Accounts")
+	if len(accounts.Methods) != 0 || accounts.EndLine != 57 {
+		t.Errorf("Expected Accounts to end at line 57 without methods, got %d methods ending at %d",
+			len(accounts.Methods), accounts.EndLine)
+	}
+}
+
+func TestRubyParser_Methods(t *testing.T) {
+	fileContext := parseSample(t)
+	user := findType(fileContext, "User")
+
+	var names []string
+	for _, method := range user.Methods {
+		names = append(names, method.Name)
+	}
+	expected := []string{"create", "initialize", "display_name", "<=>", "valid?", "default_email", "table_name"}
+	if !slices.Equal(names, expected) {
+		t.Errorf("Expected methods %v, got %v", expected, names)
+	}
+
+	create := findMethod(user, "create")
+	if create.Signature != "def self.create(attrs = {}, **options)" {
+		t.Errorf("Unexpected signature %q", create.Signature)
+	}
+	if len(create.Parameters) != 2 || create.Parameters[0].Name != "attrs" || create.Parameters[1].Name != "options" {
+		t.Errorf("Expected parameters attrs and options, got %v", create.Parameters)
+	}
+	if create.Doc != "Creates a user from attributes." {
+		t.Errorf("Expected method doc, got %q", create.Doc)
+	}
+	if create.StartLine != 23 || create.EndLine != 27 || create.LineCount != 5 {
+		t.Errorf("Expected create at lines 23-27, got %d-%d (%d lines)", create.StartLine, create.EndLine, create.LineCount)
+	}
+
+	valid := findMethod(user, "valid?")
+	if valid.StartLine != 43 || valid.EndLine != 43 || valid.Signature != "def valid?" {
+		t.Errorf("Expected endless method on line 43, got %+v", valid)
+	}
+
+	if findMethod(findType(fileContext, "Formatting"), "format_list") == nil {
+		t.Error("Expected format_list to be a method of Formatting")
+	}
+	if findFunction(fileContext, "format_list") != nil || findFunction(fileContext, "create") != nil {
+		t.Error("Expected methods of classes and modules not to be listed as functions")
+	}
+
+	var functionNames []string
+	for _, function := range fileContext.Functions {
+		functionNames = append(functionNames, function.Name)
+	}
+	if !slices.Equal(functionNames, []string{"normalize", "load_users"}) {
+		t.Errorf("Expected top-level functions normalize and load_users, got %v", functionNames)
+	}
+}
+
+func TestRubyParser_ConstantsAndExports(t *testing.T) {
+	fileContext := parseSample(t)
+
+	constants := make(map[string]models.Constant)
+	for _, constant := range fileContext.Constants {
+		constants[constant.Name] = constant
+	}
+	if constants["DEFAULT_SEPARATOR"].Value != `", "` {
+		t.Errorf("Expected DEFAULT_SEPARATOR value, got %q", constants["DEFAULT_SEPARATOR"].Value)
+	}
+	if constants["MAX_NAME"].Value != "64" {
+		t.Errorf("Expected MAX_NAME value 64, got %q", constants["MAX_NAME"].Value)
+	}
+	if constant, ok := constants["SQL"]; !ok || constant.StartLine != 73 {
+		t.Errorf("Expected heredoc constant SQL at line 73, got %+v", constant)
+	}
+
+	exported := make(map[string]bool)
+	for _, export := range fileContext.Exports {
+		exported[export.Kind+":"+export.Name] = true
+	}
+	for _, name := range []string{"type:User", "type:Accounts", "function:normalize", "constant:MAX_NAME"} {
+		if !exported[name] {
+			t.Errorf("Expected export %s", name)
+		}
+	}
+}
+
+func TestRubyParser_CallGraph(t *testing.T) {
+	fileContext := parseSample(t)
+
+	loadUsers := findFunction(fileContext, "load_users")
+	if loadUsers == nil {
+		t.Fatal("Expected load_users function")
+	}
+	for _, call := range []string{"JSON.parse", "File.read", "data.map", "normalize", "warn"} {
+		if !slices.Contains(loadUsers.LocalCalls, call) {
+			t.Errorf("Expected load_users to call %s, got %v", call, loadUsers.LocalCalls)
+		}
+	}
+	for _, local := range []string{"data", "errors", "row", "e", "path"} {
+		if slices.Contains(loadUsers.LocalCalls, local) {
+			t.Errorf("Expected local %s not to be recorded as a call", local)
+		}
+	}
+
+	callTypes := make(map[string]string)
+	for _, call := range loadUsers.LocalCallsWithMetadata {
+		callTypes[call.FunctionName] = call.CallType
+	}
+	if callTypes["normalize"] != models.CallTypeFunction || callTypes["JSON.parse"] != models.CallTypeMethod {
+		t.Errorf("Unexpected call types %v", callTypes)
+	}
+
+	normalize := findFunction(fileContext, "normalize")
+	if !slices.Contains(normalize.LocalCallers, "load_users") || !slices.Contains(normalize.CalledBy, "User.initialize") {
+		t.Errorf("Expected normalize to be called by load_users and User.initialize, got %v", normalize.CalledBy)
+	}
+
+	user := findType(fileContext, "User")
+	create := findMethod(user, "create")
+	if !slices.Equal(create.Calls, []string{"new", "user.save"}) {
+		t.Errorf("Expected create to call new and user.save, got %v", create.Calls)
+	}
+	initialize := findMethod(user, "initialize")
+	if !slices.Contains(initialize.Calls, "default_email") || slices.Contains(initialize.Calls, "key") {
+		t.Errorf("Expected block calls without block parameters, got %v", initialize.Calls)
+	}
+}
+
+func TestRubyParser_Tests(t *testing.T) {
+	source := `require "minitest/autorun"
+
+class UserTest < Minitest::Test
+  def setup
+    @user = User.new
+  end
+
+  def test_display_name
+    assert_equal "Ada", @user.display_name
+  end
+end
+`
+	fileContext, err := NewRubyParser().ParseFile("test/user_test.rb", []byte(source))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	userTest := findType(fileContext, "UserTest")
+	if userTest == nil {
+		t.Fatal("Expected UserTest class")
+	}
+	if method := findMethod(userTest, "test_display_name"); method == nil || !method.IsTest {
+		t.Error("Expected test_display_name to be flagged as a test")
+	}
+	if method := findMethod(userTest, "setup"); method == nil || method.IsTest {
+		t.Error("Expected setup not to be flagged as a test")
+	}
+}
+
+func TestRubyParser_InvalidSyntax(t *testing.T) {
+	parser := NewRubyParser()
+
+	invalidSources := map[string]string{
+		"missing end":          "class Broken\n  def run\n  end\n",
+		"extra end":            "def run\nend\nend\n",
+		"unclosed params":      "def run(a, b\n  call\nend\n",
+		"unterminated string":  "def run\n  puts \"oops\nend\n",
+		"mismatched bracket":   "def run\n  call(]\nend\n",
+		"unterminated heredoc": "TEXT = <<~EOS\n  body\n",
+	}
+
+	for name, source := range invalidSources {
+		t.Run(name, func(t *testing.T) {
+			_, err := parser.ParseFile("broken.rb", []byte(source))
+			if err == nil {
+				t.Fatal("Expected syntax error")
+			}
+			if !strings.Contains(err.Error(), "syntax error at line") {
+				t.Errorf("Expected syntax error with line number, got %v", err)
+			}
+			var parseErr *models.ParseError
+			if !errors.As(err, &parseErr) || parseErr.File != "broken.rb" || parseErr.Line == 0 {
+				t.Errorf("Expected a located ParseError for broken.rb, got %#v", err)
+			}
+		})
+	}
+}
+
+func TestRubyParser_EmptyFile(t *testing.T) {
+	fileContext, err := NewRubyParser().ParseFile("empty.rb", []byte(""))
+	if err != nil {
+		t.Fatalf("Expected no error for empty file, got %v", err)
+	}
+	if fileContext.Path != "empty.rb" {
+		t.Errorf("Expected path 'empty.rb', got %s", fileContext.Path)
+	}
+	if len(fileContext.Functions) != 0 || len(fileContext.Types) != 0 {
+		t.Errorf("Expected empty file to have no declarations")
+	}
+}
+
+func TestRubyParser_TruncatedVariables(t *testing.T) {
+	sources := map[string]string{
+		"global symbol at end":   "*:$",
+		"global at end":          "x = $",
+		"instance symbol at end": "*:@",
+		"class variable at end":  "x = @@",
+	}
+
+	for name, source := range sources {
+		t.Run(name, func(t *testing.T) {
+			if _, err := tokenize(source); err != nil {
+				t.Errorf("Expected %q to tokenize, got %v", source, err)
+			}
+			if _, err := NewRubyParser().ParseFile("truncated.rb", []byte(source)); err != nil {
+				t.Errorf("Expected no error for %q, got %v", source, err)
+			}
+		})
+	}
+}
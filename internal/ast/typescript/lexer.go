@@ -0,0 +1,346 @@
+package typescript
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"repository-context-protocol/internal/models"
+)
+
+// tokenKind classifies lexical tokens
+type tokenKind int
+
+const (
+	tokenIdent tokenKind = iota
+	tokenNumber
+	tokenString
+	tokenTemplate
+	tokenRegex
+	tokenPunct
+	tokenEOF
+)
+
+// token is a single lexical token with its source line.
+// Doc holds the JSDoc comment immediately preceding the token, if any.
+type token struct {
+	kind tokenKind
+	text string
+	line int
+	doc  string
+}
+
+// multiCharPuncts are the operators the declaration parser needs to see as one token.
+// '>' runs are emitted one character at a time so that nested generic arguments
+// such as Array<Array<string>> close correctly.
+var multiCharPuncts = []string{"...", "=>", "?.", "??"}
+
+// regexPrecedingKeywords are keywords after which '/' starts a regular expression
+var regexPrecedingKeywords = map[string]bool{
+	"return": true, "typeof": true, "case": true, "do": true, "else": true, "in": true,
+	"of": true, "new": true, "delete": true, "void": true, "throw": true, "yield": true, "await": true,
+}
+
+// lexer converts TypeScript source into tokens, dropping comments and whitespace
+type lexer struct {
+	src        string
+	pos        int
+	line       int
+	pendingDoc string
+	tokens     []token
+}
+
+// tokenize splits TypeScript source into tokens terminated by an EOF token
+func tokenize(src string) ([]token, error) {
+	l := &lexer{src: src, line: 1}
+	for {
+		if err := l.skipWhitespaceAndComments(); err != nil {
+			return nil, err
+		}
+		if l.pos >= len(l.src) {
+			break
+		}
+		if err := l.lexToken(); err != nil {
+			return nil, err
+		}
+	}
+	l.tokens = append(l.tokens, token{kind: tokenEOF, line: l.line})
+	return l.tokens, nil
+}
+
+// skipWhitespaceAndComments advances past whitespace and comments, remembering JSDoc
+func (l *lexer) skipWhitespaceAndComments() error {
+	for l.pos < len(l.src) {
+		c := l.src[l.pos]
+		switch {
+		case c == '\n':
+			l.line++
+			l.pos++
+		case c == ' ' || c == '\t' || c == '\r' || c == '\f':
+			l.pos++
+		case strings.HasPrefix(l.src[l.pos:], "//"):
+			end := strings.IndexByte(l.src[l.pos:], '\n')
+			if end < 0 {
+				l.pos = len(l.src)
+			} else {
+				l.pos += end
+			}
+		case strings.HasPrefix(l.src[l.pos:], "/*"):
+			end := strings.Index(l.src[l.pos+2:], "*/")
+			if end < 0 {
+				return syntaxErrorf(l.line, "unterminated comment")
+			}
+			comment := l.src[l.pos : l.pos+2+end+2]
+			if strings.HasPrefix(comment, "/**") && comment != "/**/" {
+				l.pendingDoc = jsdocText(comment)
+			}
+			l.line += strings.Count(comment, "\n")
+			l.pos += len(comment)
+		default:
+			return nil
+		}
+	}
+	return nil
+}
+
+// lexToken reads one token at the current position
+func (l *lexer) lexToken() error {
+	start := l.pos
+	line := l.line
+	c := l.src[l.pos]
+
+	var kind tokenKind
+	switch {
+	case c == '"' || c == '\'':
+		if err := l.skipQuoted(c); err != nil {
+			return err
+		}
+		kind = tokenString
+	case c == '`':
+		if err := l.skipTemplate(); err != nil {
+			return err
+		}
+		kind = tokenTemplate
+	case c == '/' && l.regexAllowed():
+		if err := l.skipRegex(); err != nil {
+			return err
+		}
+		kind = tokenRegex
+	case isDigit(c) || (c == '.' && l.pos+1 < len(l.src) && isDigit(l.src[l.pos+1])):
+		l.skipNumber()
+		kind = tokenNumber
+	case isIdentStart(l.src[l.pos:]):
+		l.skipIdent()
+		kind = tokenIdent
+	default:
+		kind = tokenPunct
+		l.pos++
+		for _, punct := range multiCharPuncts {
+			if strings.HasPrefix(l.src[start:], punct) {
+				l.pos = start + len(punct)
+				break
+			}
+		}
+	}
+
+	l.tokens = append(l.tokens, token{kind: kind, text: l.src[start:l.pos], line: line, doc: l.pendingDoc})
+	l.pendingDoc = ""
+	return nil
+}
+
+// regexAllowed reports whether a '/' at the current position starts a regular
+// expression rather than a division, judging by the preceding token
+func (l *lexer) regexAllowed() bool {
+	if len(l.tokens) == 0 {
+		return true
+	}
+	prev := l.tokens[len(l.tokens)-1]
+	switch prev.kind {
+	case tokenIdent:
+		return regexPrecedingKeywords[prev.text]
+	case tokenPunct:
+		return prev.text != ")" && prev.text != "]" && prev.text != "}"
+	default:
+		return false
+	}
+}
+
+// skipQuoted advances past a string literal
+func (l *lexer) skipQuoted(quote byte) error {
+	l.pos++
+	for l.pos < len(l.src) {
+		switch l.src[l.pos] {
+		case '\\':
+			if l.pos+1 < len(l.src) && l.src[l.pos+1] == '\n' {
+				l.line++
+			}
+			l.pos += 2
+		case '\n':
+			return syntaxErrorf(l.line, "unterminated string literal")
+		case quote:
+			l.pos++
+			return nil
+		default:
+			l.pos++
+		}
+	}
+	return syntaxErrorf(l.line, "unterminated string literal")
+}
+
+// skipTemplate advances past a template literal, including nested ${...} substitutions
+func (l *lexer) skipTemplate() error {
+	startLine := l.line
+	l.pos++
+	for l.pos < len(l.src) {
+		switch {
+		case l.src[l.pos] == '\\':
+			l.pos += 2
+		case l.src[l.pos] == '`':
+			l.pos++
+			return nil
+		case strings.HasPrefix(l.src[l.pos:], "${"):
+			l.pos += 2
+			if err := l.skipSubstitution(); err != nil {
+				return err
+			}
+		default:
+			if l.src[l.pos] == '\n' {
+				l.line++
+			}
+			l.pos++
+		}
+	}
+	return syntaxErrorf(startLine, "unterminated template literal")
+}
+
+// skipSubstitution advances past the expression of a template substitution and its closing brace
+func (l *lexer) skipSubstitution() error {
+	depth := 1
+	for l.pos < len(l.src) {
+		if err := l.skipWhitespaceAndComments(); err != nil {
+			return err
+		}
+		if l.pos >= len(l.src) {
+			break
+		}
+		switch c := l.src[l.pos]; c {
+		case '"', '\'':
+			if err := l.skipQuoted(c); err != nil {
+				return err
+			}
+		case '`':
+			if err := l.skipTemplate(); err != nil {
+				return err
+			}
+		case '{':
+			depth++
+			l.pos++
+		case '}':
+			depth--
+			l.pos++
+			if depth == 0 {
+				return nil
+			}
+		default:
+			l.pos++
+		}
+	}
+	return syntaxErrorf(l.line, "unterminated template substitution")
+}
+
+// skipRegex advances past a regular expression literal and its flags
+func (l *lexer) skipRegex() error {
+	l.pos++
+	inClass := false
+	for l.pos < len(l.src) {
+		switch c := l.src[l.pos]; {
+		case c == '\\':
+			l.pos += 2
+		case c == '\n':
+			return syntaxErrorf(l.line, "unterminated regular expression")
+		case c == '[':
+			inClass = true
+			l.pos++
+		case c == ']':
+			inClass = false
+			l.pos++
+		case c == '/' && !inClass:
+			l.pos++
+			for l.pos < len(l.src) && isLetter(l.src[l.pos]) {
+				l.pos++
+			}
+			return nil
+		default:
+			l.pos++
+		}
+	}
+	return syntaxErrorf(l.line, "unterminated regular expression")
+}
+
+// skipNumber advances past a numeric literal, including exponents, separators and bigint suffixes
+func (l *lexer) skipNumber() {
+	isHex := strings.HasPrefix(strings.ToLower(l.src[l.pos:]), "0x")
+	for l.pos < len(l.src) {
+		c := l.src[l.pos]
+		prev := byte(0)
+		if l.pos > 0 {
+			prev = l.src[l.pos-1] | 0x20 // lower-case ASCII letters
+		}
+		switch {
+		case isDigit(c) || isLetter(c) || c == '_' || c == '.':
+			l.pos++
+		case (c == '+' || c == '-') && !isHex && prev == 'e':
+			l.pos++
+		default:
+			return
+		}
+	}
+}
+
+// skipIdent advances past an identifier or keyword, with the leading '#' of a private name
+func (l *lexer) skipIdent() {
+	if l.src[l.pos] == '#' {
+		l.pos++
+	}
+	for l.pos < len(l.src) {
+		r, size := utf8.DecodeRuneInString(l.src[l.pos:])
+		if r != '_' && r != '$' && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			return
+		}
+		l.pos += size
+	}
+}
+
+// isIdentStart reports whether s begins with an identifier start character.
+// A leading '#' starts a private class member name.
+func isIdentStart(s string) bool {
+	r, _ := utf8.DecodeRuneInString(s)
+	return r == '_' || r == '$' || r == '#' || unicode.IsLetter(r)
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isLetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// jsdocText strips comment markers and leading asterisks from a JSDoc comment
+func jsdocText(comment string) string {
+	body := strings.TrimSuffix(strings.TrimPrefix(comment, "/**"), "*/")
+	lines := strings.Split(body, "\n")
+	for i, line := range lines {
+		line = strings.TrimSpace(line)
+		line = strings.TrimPrefix(line, "*")
+		lines[i] = strings.TrimPrefix(line, " ")
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// syntaxErrorf formats a syntax error at a source line.
+// ParseFile fills in the file path.
+func syntaxErrorf(line int, format string, args ...interface{}) error {
+	return &models.ParseError{Line: line, Kind: models.ParseErrorSyntax, Message: fmt.Sprintf(format, args...)}
+}
@@ -1,3 +1,965 @@
 package typescript
 
-// TypeScript AST parser implementation (future)
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"repository-context-protocol/internal/models"
+)
+
+const (
+	languageTypeScript = "typescript"
+
+	kindInterface = "interface"
+	kindAlias     = "alias"
+	kindEnum      = "enum"
+	kindBasic     = "basic"
+	kindComposite = "composite"
+	kindNamed     = "named"
+
+	typeVoid = "void"
+	typeAny  = "any"
+
+	// constraintUnknown is the implicit constraint of a type parameter without extends
+	constraintUnknown = "unknown"
+)
+
+// tsPrimitives are the built-in primitive and top types
+var tsPrimitives = map[string]bool{
+	"string": true, "number": true, "boolean": true, "bigint": true, "symbol": true,
+	"any": true, "unknown": true, "never": true, "object": true, "null": true, "undefined": true,
+}
+
+// typeContinuations are the tokens that cannot end a type, so a type ending in one
+// continues on the next line
+var typeContinuations = []string{"|", "&", ":", "=>", ",", "?", "<", "(", "[", "{", "=", ".", "...",
+	"extends", "keyof", "typeof", "infer", "readonly", "is", "new", "unique"}
+
+// lineContinuations are the tokens that continue the type of the previous line
+var lineContinuations = []string{"|", "&", ".", "=>", "?", ":", "extends"}
+
+// TypeScriptParser implements the LanguageParser interface for TypeScript files.
+// It extracts type declarations: interfaces, type aliases and enums with their
+// fields, method signatures and type parameters, and the exports that name them.
+// Functions, classes and imports are not extracted yet, so they are skipped and the
+// index only holds the types of TypeScript files.
+type TypeScriptParser struct{}
+
+// NewTypeScriptParser creates a new TypeScript parser instance
+func NewTypeScriptParser() *TypeScriptParser {
+	return &TypeScriptParser{}
+}
+
+// GetSupportedExtensions returns the file extensions supported by this parser.
+// TSX is left out because JSX text is not tokenized.
+func (p *TypeScriptParser) GetSupportedExtensions() []string {
+	return []string{".ts", ".mts", ".cts"}
+}
+
+// GetLanguageName returns the name of the language this parser handles
+func (p *TypeScriptParser) GetLanguageName() string {
+	return languageTypeScript
+}
+
+// ParseFile parses a TypeScript file and returns a FileContext
+func (p *TypeScriptParser) ParseFile(path string, content []byte) (*models.FileContext, error) {
+	tokens, err := tokenize(string(content))
+	if err != nil {
+		return nil, withPath(err, path)
+	}
+
+	// Calculate checksum of content
+	hash := sha256.Sum256(content)
+	checksum := fmt.Sprintf("%x", hash)
+
+	// Get modification time
+	var modTime time.Time
+	if fileInfo, err := os.Stat(path); err == nil {
+		modTime = fileInfo.ModTime()
+	} else {
+		// If file doesn't exist (e.g., in-memory parsing), use current time
+		modTime = time.Now()
+	}
+
+	ctx := &models.FileContext{
+		Path:      path,
+		Language:  languageTypeScript,
+		Checksum:  checksum,
+		ModTime:   modTime,
+		Functions: []models.Function{},
+		Types:     []models.TypeDef{},
+		Variables: []models.Variable{},
+		Constants: []models.Constant{},
+		Imports:   []models.Import{},
+		Exports:   []models.Export{},
+	}
+
+	fp := &fileParser{tokens: tokens, ctx: ctx}
+	if err := fp.parseModule(); err != nil {
+		return nil, withPath(err, path)
+	}
+
+	return ctx, nil
+}
+
+// fileParser holds the parsing state for a single module
+type fileParser struct {
+	tokens      []token
+	pos         int
+	ctx         *models.FileContext
+	exportLists []exportRef
+}
+
+// exportRef is a name listed in an export clause such as "export { User as Account }"
+type exportRef struct {
+	local    string
+	exported string
+}
+
+// declHeader holds the start of a declaration statement and whether it is exported
+type declHeader struct {
+	start    token
+	exported bool
+}
+
+// withPath attaches the file path to a parse error
+func withPath(err error, path string) error {
+	var parseErr *models.ParseError
+	if errors.As(err, &parseErr) {
+		parseErr.File = path
+		return parseErr
+	}
+	return fmt.Errorf("%s: %w", path, err)
+}
+
+// peek returns the token offset positions ahead without consuming it
+func (fp *fileParser) peek(offset int) token {
+	if fp.pos+offset >= len(fp.tokens) {
+		return fp.tokens[len(fp.tokens)-1]
+	}
+	return fp.tokens[fp.pos+offset]
+}
+
+// next consumes and returns the current token
+func (fp *fileParser) next() token {
+	tok := fp.peek(0)
+	if tok.kind != tokenEOF {
+		fp.pos++
+	}
+	return tok
+}
+
+// at reports whether the current token is the punctuation or keyword text
+func (fp *fileParser) at(text string) bool {
+	tok := fp.peek(0)
+	return (tok.kind == tokenIdent || tok.kind == tokenPunct) && tok.text == text
+}
+
+// expect consumes the token text or returns a syntax error
+func (fp *fileParser) expect(text string) (token, error) {
+	if !fp.at(text) {
+		return token{}, fp.unexpected(fmt.Sprintf("expected '%s'", text))
+	}
+	return fp.next(), nil
+}
+
+// expectIdent consumes an identifier or returns a syntax error
+func (fp *fileParser) expectIdent() (token, error) {
+	if fp.peek(0).kind != tokenIdent {
+		return token{}, fp.unexpected("expected identifier")
+	}
+	return fp.next(), nil
+}
+
+// unexpected builds a syntax error describing the current token
+func (fp *fileParser) unexpected(reason string) error {
+	tok := fp.peek(0)
+	if tok.kind == tokenEOF {
+		return syntaxErrorf(tok.line, "%s, found end of file", reason)
+	}
+	return syntaxErrorf(tok.line, "%s, found '%s'", reason, tok.text)
+}
+
+// parseModule scans the top level of the module for type declarations, skipping
+// other statements and the bodies of functions, classes and namespaces
+func (fp *fileParser) parseModule() error {
+	for fp.peek(0).kind != tokenEOF {
+		if fp.atStatementStart() {
+			handled, err := fp.parseStatement()
+			if err != nil {
+				return err
+			}
+			if handled {
+				continue
+			}
+		}
+		if _, err := fp.skipBalancedOrNext(); err != nil {
+			return err
+		}
+	}
+
+	fp.resolveExportLists()
+	return nil
+}
+
+// atStatementStart reports whether the current token can begin a statement: it follows
+// a statement terminator or a closing brace, or starts a new line
+func (fp *fileParser) atStatementStart() bool {
+	if fp.pos == 0 {
+		return true
+	}
+	prev := fp.tokens[fp.pos-1]
+	return (prev.kind == tokenPunct && (prev.text == ";" || prev.text == "}")) || prev.line < fp.peek(0).line
+}
+
+// parseStatement parses a type declaration or export clause at the current token.
+// It reports false, consuming nothing, when the statement declares no type.
+func (fp *fileParser) parseStatement() (bool, error) {
+	start := fp.pos
+	header := &declHeader{start: fp.peek(0)}
+
+	if fp.at("export") {
+		fp.next()
+		header.exported = true
+		switch {
+		case fp.at("{"), fp.at("type") && fp.peek(1).text == "{":
+			return true, fp.parseExportList()
+		case fp.at("default"):
+			fp.next()
+		}
+	}
+	if fp.at("declare") {
+		fp.next()
+	}
+
+	switch {
+	case fp.at("interface") && fp.peek(1).kind == tokenIdent:
+		return true, fp.parseInterface(header)
+	case fp.at("type") && fp.peek(1).kind == tokenIdent && (fp.peek(2).text == "=" || fp.peek(2).text == "<"):
+		return true, fp.parseTypeAlias(header)
+	case fp.at("enum") && fp.peek(1).kind == tokenIdent:
+		return true, fp.parseEnum(header)
+	case fp.at("const") && fp.peek(1).text == "enum":
+		fp.next()
+		return true, fp.parseEnum(header)
+	}
+
+	fp.pos = start
+	return false, nil
+}
+
+// parseInterface parses an interface declaration with its extends clause and members
+func (fp *fileParser) parseInterface(header *declHeader) error {
+	fp.next() // interface
+	name, err := fp.expectIdent()
+	if err != nil {
+		return err
+	}
+
+	typeDef := models.TypeDef{
+		Name:      name.text,
+		Kind:      kindInterface,
+		StartLine: header.start.line,
+		Doc:       header.start.doc,
+	}
+
+	if fp.at("<") {
+		if typeDef.TypeParams, err = fp.parseTypeParams(); err != nil {
+			return err
+		}
+	}
+
+	if fp.at("extends") {
+		fp.next()
+		for {
+			baseType, err := fp.parseType(",", "{")
+			if err != nil {
+				return err
+			}
+			typeDef.Embedded = append(typeDef.Embedded, baseType)
+			if !fp.at(",") {
+				break
+			}
+			fp.next()
+		}
+	}
+
+	closing, err := fp.parseObjectMembers(&typeDef)
+	if err != nil {
+		return err
+	}
+	typeDef.EndLine = closing.line
+
+	fp.addType(&typeDef, header)
+	return nil
+}
+
+// parseTypeAlias parses a type alias. Object literal types, alone or in an
+// intersection, become fields and methods; other intersected types are embedded.
+func (fp *fileParser) parseTypeAlias(header *declHeader) error {
+	fp.next() // type
+	name, err := fp.expectIdent()
+	if err != nil {
+		return err
+	}
+
+	typeDef := models.TypeDef{
+		Name:      name.text,
+		Kind:      kindAlias,
+		StartLine: header.start.line,
+		Doc:       header.start.doc,
+	}
+
+	if fp.at("<") {
+		if typeDef.TypeParams, err = fp.parseTypeParams(); err != nil {
+			return err
+		}
+	}
+	if _, err := fp.expect("="); err != nil {
+		return err
+	}
+
+	begin := fp.pos
+	if _, err := fp.parseType(";"); err != nil {
+		return err
+	}
+	aliased := fp.tokens[begin:fp.pos]
+	typeDef.EndLine = aliased[len(aliased)-1].line
+	if fp.at(";") {
+		fp.next()
+	}
+
+	if parts, ok := intersectionParts(aliased); ok {
+		for _, part := range parts {
+			if part[0].text != "{" || part[len(part)-1].text != "}" {
+				typeDef.Embedded = append(typeDef.Embedded, joinTokens(part))
+				continue
+			}
+			members := &fileParser{tokens: append(slices.Clone(part), token{kind: tokenEOF, line: typeDef.EndLine}), ctx: fp.ctx}
+			if _, err := members.parseObjectMembers(&typeDef); err != nil {
+				return err
+			}
+		}
+	}
+
+	fp.addType(&typeDef, header)
+	return nil
+}
+
+// parseEnum parses an enum declaration. Members become fields of the enum type and
+// constants of the module, like Java enum constants.
+func (fp *fileParser) parseEnum(header *declHeader) error {
+	fp.next() // enum
+	name, err := fp.expectIdent()
+	if err != nil {
+		return err
+	}
+
+	typeDef := models.TypeDef{
+		Name:      name.text,
+		Kind:      kindEnum,
+		StartLine: header.start.line,
+		Doc:       header.start.doc,
+	}
+
+	scope := models.ScopeFile
+	if header.exported {
+		scope = models.ScopePackage
+	}
+
+	if _, err := fp.expect("{"); err != nil {
+		return err
+	}
+	for !fp.at("}") {
+		member := fp.peek(0)
+		if member.kind != tokenIdent && member.kind != tokenString {
+			return fp.unexpected("expected enum member")
+		}
+		fp.next()
+		memberName := unquote(member)
+
+		value := ""
+		if fp.at("=") {
+			fp.next()
+			if value, err = fp.parseExpression(",", "}"); err != nil {
+				return err
+			}
+		}
+
+		typeDef.Fields = append(typeDef.Fields, models.Field{Name: memberName, Type: typeDef.Name})
+		fp.ctx.Constants = append(fp.ctx.Constants, models.Constant{
+			Name:      memberName,
+			Type:      typeDef.Name,
+			Value:     value,
+			StartLine: member.line,
+			EndLine:   fp.tokens[fp.pos-1].line,
+			Doc:       member.doc,
+			Scope:     scope,
+		})
+
+		if !fp.at(",") {
+			break
+		}
+		fp.next()
+	}
+	closing, err := fp.expect("}")
+	if err != nil {
+		return err
+	}
+	typeDef.EndLine = closing.line
+
+	fp.addType(&typeDef, header)
+	return nil
+}
+
+// parseExportList parses an export clause such as "export { User, Role as UserRole }".
+// Re-exports from other modules are skipped; local names are resolved after parsing.
+func (fp *fileParser) parseExportList() error {
+	if fp.at("type") {
+		fp.next()
+	}
+	if _, err := fp.expect("{"); err != nil {
+		return err
+	}
+
+	var refs []exportRef
+	for !fp.at("}") {
+		if fp.at("type") && fp.peek(1).kind == tokenIdent {
+			fp.next()
+		}
+		local := fp.peek(0)
+		if local.kind != tokenIdent && local.kind != tokenString {
+			return fp.unexpected("expected exported name")
+		}
+		fp.next()
+		ref := exportRef{local: unquote(local), exported: unquote(local)}
+		if fp.at("as") {
+			fp.next()
+			exported := fp.next()
+			ref.exported = unquote(exported)
+		}
+		refs = append(refs, ref)
+
+		if !fp.at(",") {
+			break
+		}
+		fp.next()
+	}
+	if _, err := fp.expect("}"); err != nil {
+		return err
+	}
+
+	if fp.at("from") {
+		fp.next()
+		fp.next() // module specifier
+	} else {
+		fp.exportLists = append(fp.exportLists, refs...)
+	}
+	if fp.at(";") {
+		fp.next()
+	}
+	return nil
+}
+
+// resolveExportLists records the types named in local export clauses as exports
+func (fp *fileParser) resolveExportLists() {
+	for _, ref := range fp.exportLists {
+		for i := range fp.ctx.Types {
+			if fp.ctx.Types[i].Name == ref.local {
+				fp.ctx.Exports = append(fp.ctx.Exports, models.Export{Name: ref.exported, Type: fp.ctx.Types[i].Kind, Kind: "type"})
+				break
+			}
+		}
+	}
+}
+
+// addType records a type declaration and, when exported, its export
+func (fp *fileParser) addType(typeDef *models.TypeDef, header *declHeader) {
+	fp.ctx.Types = append(fp.ctx.Types, *typeDef)
+	if header.exported {
+		fp.ctx.Exports = append(fp.ctx.Exports, models.Export{Name: typeDef.Name, Type: typeDef.Kind, Kind: "type"})
+	}
+}
+
+// parseTypeParams parses a type parameter list such as <T extends object = {}, K>.
+// Defaults are skipped and unconstrained parameters get the constraint "unknown".
+func (fp *fileParser) parseTypeParams() ([]models.TypeParam, error) {
+	if _, err := fp.expect("<"); err != nil {
+		return nil, err
+	}
+
+	var params []models.TypeParam
+	for !fp.at(">") {
+		// Variance and const modifiers precede the parameter name
+		for (fp.at("in") || fp.at("out") || fp.at("const")) && fp.peek(1).kind == tokenIdent {
+			fp.next()
+		}
+		name, err := fp.expectIdent()
+		if err != nil {
+			return nil, err
+		}
+		param := models.TypeParam{Name: name.text, Constraint: constraintUnknown}
+		if fp.at("extends") {
+			fp.next()
+			if param.Constraint, err = fp.parseType(",", ">", "="); err != nil {
+				return nil, err
+			}
+		}
+		if fp.at("=") {
+			fp.next()
+			if _, err := fp.parseType(",", ">"); err != nil {
+				return nil, err
+			}
+		}
+		params = append(params, param)
+
+		if !fp.at(",") {
+			break
+		}
+		fp.next()
+	}
+	if _, err := fp.expect(">"); err != nil {
+		return nil, err
+	}
+	return params, nil
+}
+
+// parseObjectMembers parses the members of an interface body or object literal type
+// into typeDef and returns the closing brace
+func (fp *fileParser) parseObjectMembers(typeDef *models.TypeDef) (token, error) {
+	if _, err := fp.expect("{"); err != nil {
+		return token{}, err
+	}
+	for !fp.at("}") {
+		if fp.peek(0).kind == tokenEOF {
+			return token{}, fp.unexpected("expected '}'")
+		}
+		if fp.at(";") || fp.at(",") {
+			fp.next()
+			continue
+		}
+		if err := fp.parseMember(typeDef); err != nil {
+			return token{}, err
+		}
+	}
+	return fp.next(), nil
+}
+
+// parseMember parses a property, method, index, call or construct signature
+func (fp *fileParser) parseMember(typeDef *models.TypeDef) error {
+	start := fp.peek(0)
+
+	// Modifiers are only modifiers when a member name follows them; mapped types may
+	// add or remove readonly with a sign
+	for ((fp.at("+") || fp.at("-")) && fp.peek(1).text == "readonly") ||
+		((fp.at("readonly") || fp.at("get") || fp.at("set")) && (isMemberName(fp.peek(1)) || fp.peek(1).text == "[")) {
+		fp.next()
+	}
+
+	var name string
+	nameStart := fp.pos
+	switch {
+	case fp.at("(") || fp.at("<") || (fp.at("new") && (fp.peek(1).text == "(" || fp.peek(1).text == "<")):
+		// Call and construct signatures describe the object itself rather than a member
+		if fp.at("new") {
+			fp.next()
+		}
+		_, err := fp.parseMethodSignature(start, "", nameStart)
+		return err
+	case fp.at("["):
+		key, err := fp.skipBalanced("[", "]")
+		if err != nil {
+			return err
+		}
+		name = joinTokens(key)
+	case isMemberName(fp.peek(0)):
+		name = unquote(fp.next())
+	default:
+		return fp.unexpected("expected member name")
+	}
+
+	if (fp.at("+") || fp.at("-")) && fp.peek(1).text == "?" {
+		fp.next()
+	}
+	if fp.at("?") || fp.at("!") {
+		fp.next()
+	}
+
+	switch {
+	case fp.at("(") || fp.at("<"):
+		method, err := fp.parseMethodSignature(start, name, nameStart)
+		if err != nil {
+			return err
+		}
+		typeDef.Methods = append(typeDef.Methods, method)
+	case fp.at(":"):
+		fp.next()
+		fieldType, err := fp.parseType(";", ",", "}")
+		if err != nil {
+			return err
+		}
+		typeDef.Fields = append(typeDef.Fields, models.Field{Name: name, Type: fieldType})
+	default:
+		// A property without a type annotation is implicitly any
+		typeDef.Fields = append(typeDef.Fields, models.Field{Name: name, Type: typeAny})
+	}
+	return nil
+}
+
+// parseMethodSignature parses the type parameters, parameters and return type of a
+// method signature whose name begins at nameStart
+func (fp *fileParser) parseMethodSignature(start token, name string, nameStart int) (models.Method, error) {
+	if fp.at("<") {
+		if _, err := fp.skipBalanced("<", ">"); err != nil {
+			return models.Method{}, err
+		}
+	}
+	parameters, err := fp.parseParameters()
+	if err != nil {
+		return models.Method{}, err
+	}
+	returnType := ""
+	if fp.at(":") {
+		fp.next()
+		if returnType, err = fp.parseType(";", ",", "}"); err != nil {
+			return models.Method{}, err
+		}
+	}
+
+	return models.Method{
+		Name:       name,
+		Signature:  joinTokens(fp.tokens[nameStart:fp.pos]),
+		Parameters: parameters,
+		Returns:    returnTypes(returnType),
+		StartLine:  start.line,
+		EndLine:    fp.tokens[fp.pos-1].line,
+		Doc:        start.doc,
+	}, nil
+}
+
+// parseParameters parses a parenthesized parameter list. Rest parameters keep their
+// "..." prefix and parameters without an annotation are typed any.
+func (fp *fileParser) parseParameters() ([]models.Parameter, error) {
+	if _, err := fp.expect("("); err != nil {
+		return nil, err
+	}
+
+	parameters := []models.Parameter{}
+	for !fp.at(")") {
+		var name string
+		switch {
+		case fp.at("{") || fp.at("["):
+			pattern, err := fp.skipBalancedOrNext()
+			if err != nil {
+				return nil, err
+			}
+			name = joinTokens(pattern)
+		default:
+			if fp.at("...") {
+				fp.next()
+				name = "..."
+			}
+			ident, err := fp.expectIdent()
+			if err != nil {
+				return nil, err
+			}
+			name += ident.text
+		}
+
+		if fp.at("?") {
+			fp.next()
+		}
+		paramType := typeAny
+		if fp.at(":") {
+			fp.next()
+			var err error
+			if paramType, err = fp.parseType(",", ")", "="); err != nil {
+				return nil, err
+			}
+		}
+		if fp.at("=") {
+			fp.next()
+			if _, err := fp.parseExpression(",", ")"); err != nil {
+				return nil, err
+			}
+		}
+		parameters = append(parameters, models.Parameter{Name: name, Type: paramType})
+
+		if !fp.at(",") {
+			break
+		}
+		fp.next()
+	}
+	if _, err := fp.expect(")"); err != nil {
+		return nil, err
+	}
+	return parameters, nil
+}
+
+// parseType reads a type up to one of the stop tokens or an unmatched closing bracket
+// at bracket depth zero, or up to the end of a line on which the type is complete,
+// and returns it rendered as text
+func (fp *fileParser) parseType(stops ...string) (string, error) {
+	tokens := fp.readUntil(stops, true)
+
+	// A leading separator, as in multi-line unions, carries no meaning
+	if len(tokens) > 1 && (tokens[0].text == "|" || tokens[0].text == "&") {
+		tokens = tokens[1:]
+	}
+	if len(tokens) == 0 {
+		return "", fp.unexpected("expected type")
+	}
+	return joinTokens(tokens), nil
+}
+
+// parseExpression reads an initializer expression up to one of the stop tokens at
+// bracket depth zero and returns it rendered as text
+func (fp *fileParser) parseExpression(stops ...string) (string, error) {
+	tokens := fp.readUntil(stops, false)
+	if len(tokens) == 0 {
+		return "", fp.unexpected("expected expression")
+	}
+	return joinTokens(tokens), nil
+}
+
+// readUntil consumes tokens up to one of the stop tokens or an unmatched closing
+// bracket at depth zero, or a line break after which the tokens read so far are
+// complete. Angle brackets count as brackets only in types, where they cannot be
+// comparison operators.
+func (fp *fileParser) readUntil(stops []string, angles bool) []token {
+	isBracket := func(tok token, brackets func(string) bool) bool {
+		return tok.kind == tokenPunct && brackets(tok.text) && (angles || (tok.text != "<" && tok.text != ">"))
+	}
+
+	var tokens []token
+	depth := 0
+	for {
+		tok := fp.peek(0)
+		if tok.kind == tokenEOF {
+			return tokens
+		}
+		if depth == 0 {
+			if tok.kind == tokenPunct && slices.Contains(stops, tok.text) || isBracket(tok, isClosingBracket) {
+				return tokens
+			}
+			if len(tokens) > 0 && tok.line > tokens[len(tokens)-1].line && typeComplete(tokens, tok) {
+				return tokens
+			}
+		}
+		switch {
+		case isBracket(tok, isOpeningBracket):
+			depth++
+		case isBracket(tok, isClosingBracket):
+			depth--
+		}
+		tokens = append(tokens, fp.next())
+	}
+}
+
+// typeComplete reports whether a type ending in the given tokens can end before next
+func typeComplete(tokens []token, next token) bool {
+	last := tokens[len(tokens)-1]
+	if last.kind != tokenString && last.kind != tokenTemplate && slices.Contains(typeContinuations, last.text) {
+		return false
+	}
+	return !(next.kind == tokenPunct || next.kind == tokenIdent) || !slices.Contains(lineContinuations, next.text)
+}
+
+// intersectionParts splits an aliased type into the operands of a top-level
+// intersection. It reports false when the type is not a plain type or intersection,
+// such as a union, function or conditional type.
+func intersectionParts(tokens []token) ([][]token, bool) {
+	if tokens[0].text == "&" {
+		tokens = tokens[1:]
+	}
+
+	var parts [][]token
+	depth, start := 0, 0
+	for i, tok := range tokens {
+		if tok.kind != tokenPunct {
+			if depth == 0 && (tok.text == "extends" || tok.text == "keyof" || tok.text == "typeof") {
+				return nil, false
+			}
+			continue
+		}
+		switch {
+		case isOpeningBracket(tok.text):
+			depth++
+		case isClosingBracket(tok.text):
+			depth--
+		case depth > 0:
+		case tok.text == "&":
+			if i == start {
+				return nil, false
+			}
+			parts = append(parts, tokens[start:i])
+			start = i + 1
+		case tok.text == "|" || tok.text == "=>" || tok.text == "?":
+			return nil, false
+		}
+	}
+	if start >= len(tokens) {
+		return nil, false
+	}
+	return append(parts, tokens[start:]), true
+}
+
+// skipBalanced consumes a bracketed group, including nested groups, and returns its tokens
+func (fp *fileParser) skipBalanced(open, closing string) ([]token, error) {
+	start := fp.pos
+	if _, err := fp.expect(open); err != nil {
+		return nil, err
+	}
+
+	depth := 1
+	for depth > 0 {
+		tok := fp.peek(0)
+		if tok.kind == tokenEOF {
+			return nil, fp.unexpected(fmt.Sprintf("expected '%s'", closing))
+		}
+		switch {
+		case fp.at(open):
+			depth++
+		case fp.at(closing):
+			depth--
+		case open != "<" && (fp.at(")") || fp.at("]") || fp.at("}")):
+			// A mismatched closing bracket inside a group is a syntax error
+			return nil, fp.unexpected(fmt.Sprintf("expected '%s'", closing))
+		case open != "<" && (fp.at("(") || fp.at("[") || fp.at("{")):
+			if _, err := fp.skipBalancedOrNext(); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		fp.next()
+	}
+
+	return fp.tokens[start:fp.pos], nil
+}
+
+// skipBalancedOrNext consumes a bracketed group at the current token, or a single token
+func (fp *fileParser) skipBalancedOrNext() ([]token, error) {
+	switch {
+	case fp.at("("):
+		return fp.skipBalanced("(", ")")
+	case fp.at("["):
+		return fp.skipBalanced("[", "]")
+	case fp.at("{"):
+		return fp.skipBalanced("{", "}")
+	case fp.at(")") || fp.at("]") || fp.at("}"):
+		return nil, fp.unexpected("unbalanced bracket")
+	}
+	return []token{fp.next()}, nil
+}
+
+// isMemberName reports whether tok can name a property or method
+func isMemberName(tok token) bool {
+	return tok.kind == tokenIdent || tok.kind == tokenString || tok.kind == tokenNumber
+}
+
+func isOpeningBracket(text string) bool {
+	return text == "(" || text == "[" || text == "{" || text == "<"
+}
+
+func isClosingBracket(text string) bool {
+	return text == ")" || text == "]" || text == "}" || text == ">"
+}
+
+// unquote returns the text of a name token without string quotes
+func unquote(tok token) string {
+	if tok.kind == tokenString && len(tok.text) >= 2 {
+		return tok.text[1 : len(tok.text)-1]
+	}
+	return tok.text
+}
+
+// returnTypes converts a declared return type to the model representation
+func returnTypes(returnType string) []models.Type {
+	if returnType == "" || returnType == typeVoid {
+		return []models.Type{}
+	}
+	return []models.Type{{Name: returnType, Kind: typeKind(returnType)}}
+}
+
+// typeKind classifies a TypeScript type reference
+func typeKind(typeName string) string {
+	switch {
+	case tsPrimitives[typeName]:
+		return kindBasic
+	case strings.ContainsAny(typeName, "<[{(|&"):
+		return kindComposite
+	default:
+		return kindNamed
+	}
+}
+
+// joinTokens renders tokens as source text with conventional TypeScript spacing,
+// e.g. "Record<string, { id: number }>" or "(a: string) => void"
+func joinTokens(tokens []token) string {
+	var builder strings.Builder
+	// Bracket depths of conditional types awaiting their ':' branch
+	var conditionals []int
+	depth := 0
+
+	for i, tok := range tokens {
+		punct := tok.kind == tokenPunct
+		conditional := punct && tok.text == "?" && !optionalMarker(tokens, i)
+		if i > 0 {
+			prev := tokens[i-1]
+			prevPunct := prev.kind == tokenPunct
+			branch := punct && tok.text == ":" && len(conditionals) > 0 && conditionals[len(conditionals)-1] == depth
+			switch {
+			case prevPunct && (prev.text == "," || prev.text == ":" || prev.text == ";"):
+				builder.WriteString(" ")
+			case !prevPunct && !punct:
+				builder.WriteString(" ")
+			case spacedOperator(prev) || spacedOperator(tok), conditional, branch:
+				builder.WriteString(" ")
+			case prevPunct && prev.text == "?" && !optionalMarker(tokens, i-1):
+				builder.WriteString(" ")
+			case punct && tok.text == "{" && !prevPunct:
+				builder.WriteString(" ")
+			case prevPunct && prev.text == "{" && !(punct && tok.text == "}"):
+				builder.WriteString(" ")
+			case punct && tok.text == "}" && !(prevPunct && prev.text == "{"):
+				builder.WriteString(" ")
+			}
+			if branch {
+				conditionals = conditionals[:len(conditionals)-1]
+			}
+		}
+		if conditional {
+			conditionals = append(conditionals, depth)
+		}
+		if punct {
+			switch {
+			case isOpeningBracket(tok.text):
+				depth++
+			case isClosingBracket(tok.text):
+				depth--
+			}
+		}
+		builder.WriteString(tok.text)
+	}
+	return builder.String()
+}
+
+// optionalMarker reports whether the '?' at index i marks an optional member or
+// parameter rather than starting the branches of a conditional type
+func optionalMarker(tokens []token, i int) bool {
+	if i+1 >= len(tokens) {
+		return true
+	}
+	next := tokens[i+1]
+	return next.kind == tokenPunct && (next.text == ":" || next.text == "," || next.text == ")" || next.text == ";")
+}
+
+// spacedOperator reports whether tok is an operator written with surrounding spaces
+func spacedOperator(tok token) bool {
+	return tok.kind == tokenPunct && (tok.text == "|" || tok.text == "&" || tok.text == "=>" || tok.text == "=")
+}
@@ -0,0 +1,275 @@
+package typescript
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"repository-context-protocol/internal/models"
+)
+
+const sampleTypeScript = `import { Base } from "./base";
+
+/**
+ * A registered user.
+ */
+export interface User extends Base, Timestamped<Date> {
+  readonly id: number
+  name: string;
+  email?: string | null;
+  roles: Array<{ name: string; level: number }>;
+  [key: string]: unknown;
+  greet(prefix?: string, ...names: string[]): Promise<void>;
+  find<T>(id: number): T | undefined
+}
+
+export type Account = {
+  id: number; name: string
+};
+
+type Admin = User & {
+  permissions: string[];
+};
+
+export type Status =
+  | "active"
+  | "disabled";
+
+type Mapper<T extends object = {}, K extends keyof T = keyof T> = (value: T[K]) => string;
+
+export const enum Level {
+  Low = 1,
+  High = "high",
+  Mask = Low | High,
+}
+
+enum Direction { Up, Down }
+
+const pattern = /[{(]/g;
+const message = ` + "`value: ${JSON.stringify({ a: 1 })}`" + `;
+
+function local(): void {
+  interface Hidden { x: number }
+}
+
+namespace Internal {
+  export interface Nested { y: string }
+}
+
+interface Shape { kind: string }
+export { Shape as PublicShape, Direction };
+export { Other } from "./other";
+`
+
+func parseSample(t *testing.T) *models.FileContext {
+	t.Helper()
+	fileContext, err := NewTypeScriptParser().ParseFile("user.ts", []byte(sampleTypeScript))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	return fileContext
+}
+
+func findType(fileContext *models.FileContext, name string) *models.TypeDef {
+	for i := range fileContext.Types {
+		if fileContext.Types[i].Name == name {
+			return &fileContext.Types[i]
+		}
+	}
+	return nil
+}
+
+func TestTypeScriptParser_Interface(t *testing.T) {
+	parser := NewTypeScriptParser()
+
+	if parser.GetLanguageName() != "typescript" {
+		t.Errorf("Expected language 'typescript', got %s", parser.GetLanguageName())
+	}
+	if extensions := parser.GetSupportedExtensions(); !slices.Contains(extensions, ".ts") {
+		t.Errorf("Expected .ts to be supported, got %v", extensions)
+	}
+}
+
+func TestTypeScriptParser_Interfaces(t *testing.T) {
+	fileContext := parseSample(t)
+
+	user := findType(fileContext, "User")
+	if user == nil {
+		t.Fatal("Expected User interface")
+	}
+	if user.Kind != "interface" || user.Doc != "A registered user." || user.StartLine != 6 || user.EndLine != 14 {
+		t.Errorf("Expected documented interface on lines 6-14, got %+v", user)
+	}
+	if !slices.Equal(user.Embedded, []string{"Base", "Timestamped<Date>"}) {
+		t.Errorf("Expected extended types Base and Timestamped<Date>, got %v", user.Embedded)
+	}
+
+	expectedFields := []models.Field{
+		{Name: "id", Type: "number"},
+		{Name: "name", Type: "string"},
+		{Name: "email", Type: "string | null"},
+		{Name: "roles", Type: "Array<{ name: string; level: number }>"},
+		{Name: "[key: string]", Type: "unknown"},
+	}
+	if !slices.Equal(user.Fields, expectedFields) {
+		t.Errorf("Expected fields %v, got %v", expectedFields, user.Fields)
+	}
+
+	if len(user.Methods) != 2 {
+		t.Fatalf("Expected 2 methods, got %+v", user.Methods)
+	}
+	greet := user.Methods[0]
+	if greet.Name != "greet" || greet.Signature != "greet(prefix?: string, ...names: string[]): Promise<void>" {
+		t.Errorf("Unexpected greet signature %q", greet.Signature)
+	}
+	expectedParams := []models.Parameter{{Name: "prefix", Type: "string"}, {Name: "...names", Type: "string[]"}}
+	if !slices.Equal(greet.Parameters, expectedParams) {
+		t.Errorf("Expected parameters %v, got %v", expectedParams, greet.Parameters)
+	}
+	if len(greet.Returns) != 1 || greet.Returns[0].Name != "Promise<void>" || greet.Returns[0].Kind != "composite" {
+		t.Errorf("Expected Promise<void> return, got %v", greet.Returns)
+	}
+	if find := user.Methods[1]; find.Signature != "find<T>(id: number): T | undefined" || find.StartLine != 13 {
+		t.Errorf("Unexpected find method %+v", find)
+	}
+
+	// Declarations inside functions and namespaces are not module-level types
+	if findType(fileContext, "Hidden") != nil || findType(fileContext, "Nested") != nil {
+		t.Error("Expected nested declarations to be skipped")
+	}
+}
+
+func TestTypeScriptParser_TypeAliases(t *testing.T) {
+	fileContext := parseSample(t)
+
+	account := findType(fileContext, "Account")
+	expectedFields := []models.Field{{Name: "id", Type: "number"}, {Name: "name", Type: "string"}}
+	if account == nil || account.Kind != "alias" || !slices.Equal(account.Fields, expectedFields) {
+		t.Errorf("Expected alias Account with typed fields %v, got %+v", expectedFields, account)
+	}
+
+	admin := findType(fileContext, "Admin")
+	if admin == nil || !slices.Equal(admin.Embedded, []string{"User"}) ||
+		!slices.Equal(admin.Fields, []models.Field{{Name: "permissions", Type: "string[]"}}) {
+		t.Errorf("Expected Admin to embed User and add permissions, got %+v", admin)
+	}
+
+	status := findType(fileContext, "Status")
+	if status == nil || status.Kind != "alias" || len(status.Fields) != 0 || len(status.Embedded) != 0 ||
+		status.StartLine != 24 || status.EndLine != 26 {
+		t.Errorf("Expected union alias Status on lines 24-26 without fields, got %+v", status)
+	}
+
+	mapper := findType(fileContext, "Mapper")
+	expectedParams := []models.TypeParam{{Name: "T", Constraint: "object"}, {Name: "K", Constraint: "keyof T"}}
+	if mapper == nil || !slices.Equal(mapper.TypeParams, expectedParams) || len(mapper.Embedded) != 0 {
+		t.Errorf("Expected generic alias Mapper with %v, got %+v", expectedParams, mapper)
+	}
+}
+
+func TestTypeScriptParser_Enums(t *testing.T) {
+	fileContext := parseSample(t)
+
+	level := findType(fileContext, "Level")
+	if level == nil || level.Kind != "enum" ||
+		!slices.Equal(level.Fields, []models.Field{{Name: "Low", Type: "Level"}, {Name: "High", Type: "Level"}, {Name: "Mask", Type: "Level"}}) {
+		t.Errorf("Expected enum Level with members Low, High and Mask, got %+v", level)
+	}
+
+	constants := make(map[string]models.Constant)
+	for _, constant := range fileContext.Constants {
+		constants[constant.Name] = constant
+	}
+	if low := constants["Low"]; low.Type != "Level" || low.Value != "1" || low.Scope != models.ScopePackage {
+		t.Errorf("Expected exported constant Low = 1, got %+v", low)
+	}
+	if high := constants["High"]; high.Value != `"high"` {
+		t.Errorf("Expected constant High with a string value, got %+v", high)
+	}
+	if mask := constants["Mask"]; mask.Value != "Low | High" {
+		t.Errorf("Expected constant Mask with a computed value, got %+v", mask)
+	}
+	if up := constants["Up"]; up.Type != "Direction" || up.Scope != models.ScopeFile {
+		t.Errorf("Expected file-scoped constant Up, got %+v", up)
+	}
+}
+
+func TestTypeScriptParser_Exports(t *testing.T) {
+	fileContext := parseSample(t)
+
+	var exported []string
+	for _, export := range fileContext.Exports {
+		if export.Kind != "type" {
+			t.Errorf("Expected only type exports, got %+v", export)
+		}
+		exported = append(exported, export.Name+":"+export.Type)
+	}
+	expected := []string{"User:interface", "Account:alias", "Status:alias", "Level:enum", "PublicShape:interface", "Direction:enum"}
+	if !slices.Equal(exported, expected) {
+		t.Errorf("Expected exports %v, got %v", expected, exported)
+	}
+}
+
+func TestTypeScriptParser_InvalidSyntax(t *testing.T) {
+	parser := NewTypeScriptParser()
+
+	invalidSources := map[string]string{
+		"unclosed interface":  "interface Broken {\n  id: number;\n",
+		"unterminated string": "const s = \"oops;\n",
+		"unclosed template":   "const s = `oops\n",
+		"missing alias type":  "type Broken =\n",
+		"mismatched bracket":  "function run() { call(]; }\n",
+	}
+
+	for name, source := range invalidSources {
+		t.Run(name, func(t *testing.T) {
+			_, err := parser.ParseFile("broken.ts", []byte(source))
+			if err == nil {
+				t.Fatal("Expected syntax error")
+			}
+			if !strings.Contains(err.Error(), "syntax error at line") {
+				t.Errorf("Expected syntax error with line number, got %v", err)
+			}
+			var parseErr *models.ParseError
+			if !errors.As(err, &parseErr) || parseErr.File != "broken.ts" || parseErr.Line == 0 {
+				t.Errorf("Expected a located ParseError for broken.ts, got %#v", err)
+			}
+		})
+	}
+}
+
+func TestTypeScriptParser_EmptyFile(t *testing.T) {
+	fileContext, err := NewTypeScriptParser().ParseFile("empty.ts", []byte(""))
+	if err != nil {
+		t.Fatalf("Expected no error for empty file, got %v", err)
+	}
+	if fileContext.Language != "typescript" || len(fileContext.Types) != 0 {
+		t.Errorf("Expected empty file to have no declarations, got %+v", fileContext)
+	}
+}
+
+func TestTypeScriptParser_PrivateNames(t *testing.T) {
+	sources := map[string]struct {
+		source  string
+		private string
+	}{
+		"lone hash":     {"#", "#"},
+		"private field": {"class C { #count = 0 }\n", "#count"},
+	}
+
+	for name, tc := range sources {
+		t.Run(name, func(t *testing.T) {
+			tokens, err := tokenize(tc.source)
+			if err != nil {
+				t.Fatalf("Expected private names to tokenize, got %v", err)
+			}
+			if !slices.ContainsFunc(tokens, func(tok token) bool { return tok.kind == tokenIdent && tok.text == tc.private }) {
+				t.Errorf("Expected identifier %q, got %+v", tc.private, tokens)
+			}
+			if _, err := NewTypeScriptParser().ParseFile("private.ts", []byte(tc.source)); err != nil {
+				t.Errorf("Expected no error, got %v", err)
+			}
+		})
+	}
+}
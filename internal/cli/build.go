@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 
 	"repository-context-protocol/internal/index"
+	"repository-context-protocol/internal/mcp"
 
 	"github.com/spf13/cobra"
 )
@@ -14,6 +15,7 @@ import (
 func NewBuildCommand() *cobra.Command {
 	var path string
 	var verbose bool
+	var options index.IndexBuilderOptions
 
 	cmd := &cobra.Command{
 		Use:   "build",
@@ -26,21 +28,62 @@ This command:
 - Creates semantic chunks for efficient querying
 - Stores the index in .repocontext/index.db and .repocontext/chunks/
 
+With --store-source, compressed file content is kept in the chunks so context
+tools return exact function bodies even after the working tree has changed.
+
+With --since, only the files changed relative to a git ref are reindexed into
+the existing index and deleted files are removed, instead of rebuilding every file.
+
+With --tags, Go files whose build constraints are not satisfied by the given
+tags and the host GOOS/GOARCH are left out of the index, as go build does.
+
+Files are parsed in parallel, --concurrency at a time (default: GOMAXPROCS).
+
+With --git-blame, functions and types record the last commit that changed
+their lines, as reported by git blame. This runs git on every file, so it is
+off by default; files outside a git checkout are left unannotated.
+
+With --normalize-types, parameter, return and field types also record a
+language-independent form next to the type as written, so List[str], []string
+and string[] all read list<string>.
+
+With --index-anonymous, Go function literals and Python lambdas are indexed as
+functions named func@file:line or lambda@file:line, with their enclosing
+function as parent, so calls made inside closures stay in the call graph.
+
+With --compress-chunks, or compress_chunks set in .repocontext/config.json,
+chunk files are gzipped, roughly halving the index on disk at a small cost
+on every query. Indexes mixing compressed and uncompressed chunks still load.
+
+Python files are parsed with the interpreter named by python_interpreter in
+.repocontext/config.json (an executable or a virtualenv directory), else by
+$REPOCONTEXT_PYTHON, else by python3 or python on PATH.
+
 The repository must be initialized with 'repocontext init' before building.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runBuild(path, verbose)
+			options.MatchBuildTags = cmd.Flags().Changed("tags")
+			options.ToolVersion = Version
+			return runBuild(path, verbose, options)
 		},
 	}
 
 	// Add flags
 	cmd.Flags().StringVarP(&path, "path", "p", "", "Path to repository root (default: current directory)")
 	cmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "Enable verbose output")
+	cmd.Flags().BoolVar(&options.StoreSource, "store-source", false, "Store compressed source in the index (larger index)")
+	cmd.Flags().StringVar(&options.ChangedSince, "since", "", "Only reindex files changed since this git ref (e.g. main)")
+	cmd.Flags().IntVar(&options.Concurrency, "concurrency", 0, "Number of files to parse at once (default: GOMAXPROCS)")
+	cmd.Flags().BoolVar(&options.GitBlame, "git-blame", false, "Record the last commit changing each function and type (slower)")
+	cmd.Flags().BoolVar(&options.NormalizeTypes, "normalize-types", false, "Record language-independent parameter, return and field types")
+	cmd.Flags().BoolVar(&options.IndexAnonymous, "index-anonymous", false, "Index closures and lambdas as functions named func@file:line")
+	cmd.Flags().BoolVar(&options.CompressChunks, "compress-chunks", false, "Gzip chunk files to shrink the index on disk")
+	cmd.Flags().StringSliceVar(&options.BuildTags, "tags", nil, "Only index Go files whose build constraints these tags satisfy (e.g. linux,integration)")
 
 	return cmd
 }
 
 // runBuild executes the build command logic
-func runBuild(path string, verbose bool) error {
+func runBuild(path string, verbose bool, options index.IndexBuilderOptions) error {
 	// Determine the target path
 	targetPath, err := determineTargetPath(path)
 	if err != nil {
@@ -56,8 +99,19 @@ func runBuild(path string, verbose bool) error {
 		return fmt.Errorf("repository not initialized: %w", validateErr)
 	}
 
+	// The Python interpreter and other build settings can be configured in .repocontext/config.json
+	config, err := mcp.LoadRepoConfig(targetPath)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	if options.PythonInterpreter == "" {
+		options.PythonInterpreter = config.PythonInterpreter
+	}
+	options.CompressChunks = options.CompressChunks || config.CompressChunks
+	options.EntryPointPatterns = config.EntryPointPatterns
+
 	// Create and initialize the IndexBuilder
-	builder := index.NewIndexBuilder(targetPath)
+	builder := index.NewIndexBuilderWithOptions(targetPath, options)
 	if initErr := builder.Initialize(); initErr != nil {
 		return fmt.Errorf("failed to initialize index builder: %w", initErr)
 	}
@@ -87,6 +141,22 @@ func runBuild(path string, verbose bool) error {
 	fmt.Printf("Call relationships: %d\n", stats.CallsIndexed)
 	fmt.Printf("Build duration: %v\n", stats.Duration)
 
+	if len(stats.FailedFiles) > 0 {
+		fmt.Printf("Files failed: %d\n", len(stats.FailedFiles))
+		for _, failed := range stats.FailedFiles {
+			fmt.Printf("  %s\n", failed.Error())
+		}
+	}
+
+	if len(stats.SkippedFiles) > 0 {
+		fmt.Printf("Files skipped: %d\n", len(stats.SkippedFiles))
+		if verbose {
+			for _, skipped := range stats.SkippedFiles {
+				fmt.Printf("  %s\n", skipped)
+			}
+		}
+	}
+
 	if verbose {
 		fmt.Printf("Index stored in: %s\n", filepath.Join(targetPath, ".repocontext"))
 	}
@@ -23,6 +23,9 @@ Features:
 - Initialize repository context tracking
 - Build semantic indexes from source code
 - Query code semantics and relationships
+- Compare index builds
+- Compact the index storage
+- Snapshot and restore the index
 - Serve context via HTTP API
 
 Use 'repocontext <command> --help' for more information about a command.`,
@@ -42,6 +45,11 @@ Use 'repocontext <command> --help' for more information about a command.`,
 	rootCmd.AddCommand(NewInitCommand())
 	rootCmd.AddCommand(NewBuildCommand())
 	rootCmd.AddCommand(NewQueryCommand())
+	rootCmd.AddCommand(NewDiffCommand())
+	rootCmd.AddCommand(NewCompactCommand())
+	rootCmd.AddCommand(NewSnapshotCommand())
+	rootCmd.AddCommand(NewRestoreCommand())
+	rootCmd.AddCommand(NewServeCommand())
 
 	return rootCmd
 }
@@ -41,9 +41,14 @@ func TestRootCommand_HasSubcommands(t *testing.T) {
 
 	// Create a map of expected commands
 	expectedCommands := map[string]bool{
-		"init":  false,
-		"build": false,
-		"query": false,
+		"init":     false,
+		"build":    false,
+		"query":    false,
+		"diff":     false,
+		"compact":  false,
+		"snapshot": false,
+		"restore":  false,
+		"serve":    false,
 	}
 
 	// Check that expected commands are present
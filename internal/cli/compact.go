@@ -0,0 +1,51 @@
+package cli
+
+import (
+	"path/filepath"
+
+	"repository-context-protocol/internal/index"
+
+	"github.com/spf13/cobra"
+)
+
+// NewCompactCommand creates the compact command for reclaiming index storage
+func NewCompactCommand() *cobra.Command {
+	var path string
+
+	cmd := &cobra.Command{
+		Use:   "compact",
+		Short: "Reclaim disk space used by the index",
+		Long: `Reclaim disk space left behind by repeated index builds.
+
+This command:
+- Deletes chunk files in .repocontext/chunks/ that no index entry refers to
+- Vacuums .repocontext/index.db to return unused pages to the filesystem
+- Reports the number of bytes reclaimed
+
+Compaction never removes indexed data, so it is safe to run while the index
+is being queried. It refuses while a build or update has the index open for
+writes, since chunks being written would look unreferenced.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runCompact(path, cmd)
+		},
+	}
+
+	cmd.Flags().StringVarP(&path, "path", "p", ".", "Path to the repository (defaults to current directory)")
+
+	return cmd
+}
+
+// runCompact executes the compact command
+func runCompact(path string, cmd *cobra.Command) error {
+	if err := validateRepository(path); err != nil {
+		return err
+	}
+
+	before, after, err := index.CompactIndex(filepath.Join(path, ".repocontext"))
+	if err != nil {
+		return err
+	}
+
+	cmd.Printf("Index compacted: %d bytes reclaimed (%d -> %d bytes)\n", max(before-after, 0), before, after)
+	return nil
+}
@@ -0,0 +1,77 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"repository-context-protocol/internal/index"
+)
+
+func TestCompactCommand_ReclaimsOrphanedChunks(t *testing.T) {
+	tempDir, storage := setupTestRepository(t)
+	defer os.RemoveAll(tempDir)
+
+	addTestData(t, storage)
+	storage.Close()
+
+	orphanPath := filepath.Join(tempDir, ".repocontext", "chunks", "orphan.msgpack")
+	if err := os.WriteFile(orphanPath, bytes.Repeat([]byte{0}, 1024), 0600); err != nil {
+		t.Fatalf("Failed to write orphaned chunk: %v", err)
+	}
+
+	cmd := NewCompactCommand()
+	if err := cmd.Flags().Set("path", tempDir); err != nil {
+		t.Fatalf("Failed to set path flag: %v", err)
+	}
+	var buf bytes.Buffer
+	cmd.SetOut(&buf)
+	if err := cmd.RunE(cmd, []string{}); err != nil {
+		t.Fatalf("Expected compaction to succeed, got error: %v", err)
+	}
+
+	if _, err := os.Stat(orphanPath); !os.IsNotExist(err) {
+		t.Error("Expected orphaned chunk to be deleted")
+	}
+	if !strings.Contains(buf.String(), "bytes reclaimed") {
+		t.Errorf("Expected reclaimed bytes in output, got: %s", buf.String())
+	}
+}
+
+func TestCompactCommand_Uninitialized(t *testing.T) {
+	cmd := NewCompactCommand()
+	if err := cmd.Flags().Set("path", t.TempDir()); err != nil {
+		t.Fatalf("Failed to set path flag: %v", err)
+	}
+	cmd.SetOut(&bytes.Buffer{})
+	if err := cmd.RunE(cmd, []string{}); err == nil {
+		t.Error("Expected error for uninitialized repository")
+	}
+}
+
+func TestCompactCommand_RefusesWhileIndexOpenForWrites(t *testing.T) {
+	tempDir, storage := setupTestRepository(t)
+	defer os.RemoveAll(tempDir)
+	storage.Close()
+
+	// A running process, this one, has the index open for writes
+	lockPath := filepath.Join(tempDir, ".repocontext", index.WriteLockFileName)
+	if err := os.WriteFile(lockPath, []byte(strconv.Itoa(os.Getpid())), 0600); err != nil {
+		t.Fatalf("Failed to write lock: %v", err)
+	}
+
+	cmd := NewCompactCommand()
+	if err := cmd.Flags().Set("path", tempDir); err != nil {
+		t.Fatalf("Failed to set path flag: %v", err)
+	}
+	cmd.SetOut(&bytes.Buffer{})
+	if err := cmd.RunE(cmd, []string{}); err == nil || !strings.Contains(err.Error(), "open for writes") {
+		t.Errorf("Expected compaction to refuse while the index is open for writes, got %v", err)
+	}
+	if _, err := os.Stat(lockPath); err != nil {
+		t.Errorf("Expected the writer's lock to be left in place, got %v", err)
+	}
+}
@@ -0,0 +1,153 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"repository-context-protocol/internal/index"
+
+	"github.com/spf13/cobra"
+)
+
+// DiffFlags holds all the flags for the diff command
+type DiffFlags struct {
+	Base   string
+	Save   string
+	Format string
+	Path   string
+}
+
+// NewDiffCommand creates the diff command for comparing two index builds
+func NewDiffCommand() *cobra.Command {
+	flags := &DiffFlags{}
+
+	cmd := &cobra.Command{
+		Use:   "diff",
+		Short: "Compare the current index with a saved snapshot",
+		Long: `Compare the current semantic index with a snapshot of an earlier build.
+
+The diff reports functions and types that were added, removed or modified,
+grouped by file. Modified symbols are marked as:
+  signature_changed  The declaration changed
+  body_changed       The declaration is the same but its source changed
+  relocated          The declaration is the same but moved to another file
+
+Examples:
+  # Save a snapshot of the current index
+  repocontext diff --save /tmp/base-index
+
+  # Rebuild after making changes, then compare against the snapshot
+  repocontext build
+  repocontext diff --base /tmp/base-index
+
+  # JSON output for tooling
+  repocontext diff --base /tmp/base-index --format json`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runDiff(flags, cmd)
+		},
+	}
+
+	cmd.Flags().StringVar(&flags.Base, "base", "", "Snapshot directory to compare the current index against")
+	cmd.Flags().StringVar(&flags.Save, "save", "", "Save a snapshot of the current index to this directory")
+	cmd.Flags().StringVar(&flags.Format, "format", "text", "Output format: text, json")
+	cmd.Flags().StringVarP(&flags.Path, "path", "p", ".", "Path to the repository (defaults to current directory)")
+
+	return cmd
+}
+
+// runDiff executes the diff command
+func runDiff(flags *DiffFlags, cmd *cobra.Command) error {
+	if (flags.Base == "") == (flags.Save == "") {
+		return fmt.Errorf("exactly one of --base or --save must be specified")
+	}
+	if flags.Format != "text" && flags.Format != "json" {
+		return fmt.Errorf("invalid format '%s', must be one of: text, json", flags.Format)
+	}
+	if err := validateRepository(flags.Path); err != nil {
+		return err
+	}
+
+	repoContextDir := filepath.Join(flags.Path, ".repocontext")
+
+	if flags.Save != "" {
+		if err := index.SnapshotIndex(repoContextDir, flags.Save); err != nil {
+			return fmt.Errorf("failed to save snapshot: %w", err)
+		}
+		cmd.Printf("Saved index snapshot to %s\n", flags.Save)
+		return nil
+	}
+
+	// Opening storage creates a missing index, which would report everything as added
+	if _, err := os.Stat(filepath.Join(flags.Base, "manifest.json")); err != nil {
+		return fmt.Errorf("no index snapshot at %s", flags.Base)
+	}
+
+	baseStorage := index.NewHybridStorage(flags.Base)
+	if err := baseStorage.Initialize(); err != nil {
+		return fmt.Errorf("failed to open snapshot: %w", err)
+	}
+	defer baseStorage.Close()
+
+	currentStorage := index.NewHybridStorage(repoContextDir)
+	if err := currentStorage.Initialize(); err != nil {
+		return fmt.Errorf("failed to initialize storage: %w", err)
+	}
+	defer currentStorage.Close()
+
+	diff, err := index.DiffIndexes(baseStorage, currentStorage)
+	if err != nil {
+		return fmt.Errorf("diff failed: %w", err)
+	}
+
+	if flags.Format == "json" {
+		output, err := json.MarshalIndent(diff, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to format diff: %w", err)
+		}
+		cmd.Println(string(output))
+		return nil
+	}
+
+	cmd.Print(formatDiffText(diff))
+	return nil
+}
+
+// formatDiffText renders a diff for human consumption
+func formatDiffText(diff *index.IndexDiff) string {
+	if diff.IsEmpty() {
+		return "No changes\n"
+	}
+
+	var builder strings.Builder
+	for _, file := range diff.Files {
+		if file.Status != "" {
+			builder.WriteString(fmt.Sprintf("%s (%s)\n", file.Path, file.Status))
+		} else {
+			builder.WriteString(file.Path + "\n")
+		}
+
+		for _, change := range file.Added {
+			builder.WriteString(fmt.Sprintf("  + %s %s: %s\n", change.Kind, change.Name, change.NewSignature))
+		}
+		for _, change := range file.Removed {
+			builder.WriteString(fmt.Sprintf("  - %s %s: %s\n", change.Kind, change.Name, change.OldSignature))
+		}
+		for _, change := range file.Modified {
+			builder.WriteString(fmt.Sprintf("  ~ %s %s [%s]\n", change.Kind, change.Name, change.Change))
+			switch change.Change {
+			case index.ChangeSignature:
+				builder.WriteString(fmt.Sprintf("      old: %s\n      new: %s\n", change.OldSignature, change.NewSignature))
+			case index.ChangeRelocated:
+				builder.WriteString(fmt.Sprintf("      moved from %s:%d\n", change.OldFile, change.OldLine))
+			}
+		}
+	}
+
+	summary := diff.Summary
+	builder.WriteString(fmt.Sprintf("\n%d added, %d removed, %d modified across %d files\n",
+		summary.Added, summary.Removed, summary.Modified, len(diff.Files)))
+	return builder.String()
+}
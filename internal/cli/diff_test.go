@@ -0,0 +1,116 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"repository-context-protocol/internal/index"
+	"repository-context-protocol/internal/models"
+)
+
+func TestDiffCommand_SaveAndCompare(t *testing.T) {
+	tempDir, storage := setupTestRepository(t)
+	defer os.RemoveAll(tempDir)
+
+	addTestData(t, storage)
+	storage.Close()
+
+	snapshotDir := filepath.Join(t.TempDir(), "base")
+
+	saveCmd := NewDiffCommand()
+	if err := saveCmd.Flags().Set("path", tempDir); err != nil {
+		t.Fatalf("Failed to set path flag: %v", err)
+	}
+	if err := saveCmd.Flags().Set("save", snapshotDir); err != nil {
+		t.Fatalf("Failed to set save flag: %v", err)
+	}
+	saveCmd.SetOut(&bytes.Buffer{})
+	if err := saveCmd.RunE(saveCmd, []string{}); err != nil {
+		t.Fatalf("Expected snapshot to succeed, got error: %v", err)
+	}
+
+	// Change the current index after the snapshot was taken
+	storage = index.NewHybridStorage(filepath.Join(tempDir, ".repocontext"))
+	if err := storage.Initialize(); err != nil {
+		t.Fatalf("Failed to reopen storage: %v", err)
+	}
+	err := storage.StoreFileContext(&models.FileContext{
+		Path:      "main.go",
+		Language:  "go",
+		Checksum:  "changed",
+		Functions: []models.Function{{Name: "TestFunction", Signature: "func TestFunction(ctx context.Context) error", StartLine: 10, EndLine: 15}},
+	})
+	if err != nil {
+		t.Fatalf("Failed to store changed file: %v", err)
+	}
+	storage.Close()
+
+	diffCmd := NewDiffCommand()
+	if err := diffCmd.Flags().Set("path", tempDir); err != nil {
+		t.Fatalf("Failed to set path flag: %v", err)
+	}
+	if err := diffCmd.Flags().Set("base", snapshotDir); err != nil {
+		t.Fatalf("Failed to set base flag: %v", err)
+	}
+
+	var buf bytes.Buffer
+	diffCmd.SetOut(&buf)
+	if err := diffCmd.RunE(diffCmd, []string{}); err != nil {
+		t.Fatalf("Expected diff to succeed, got error: %v", err)
+	}
+
+	output := buf.String()
+	for _, expected := range []string{
+		"main.go (modified)",
+		"~ function TestFunction [signature_changed]",
+		"- function AnotherFunction",
+		"- type TestStruct",
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Expected output to contain %q, got:\n%s", expected, output)
+		}
+	}
+}
+
+func TestDiffCommand_RequiresBaseOrSave(t *testing.T) {
+	tempDir, storage := setupTestRepository(t)
+	defer os.RemoveAll(tempDir)
+	storage.Close()
+
+	cmd := NewDiffCommand()
+	if err := cmd.Flags().Set("path", tempDir); err != nil {
+		t.Fatalf("Failed to set path flag: %v", err)
+	}
+
+	err := cmd.RunE(cmd, []string{})
+	if err == nil || !strings.Contains(err.Error(), "exactly one of --base or --save") {
+		t.Errorf("Expected missing flag error, got %v", err)
+	}
+}
+
+func TestDiffCommand_MissingBase(t *testing.T) {
+	tempDir, storage := setupTestRepository(t)
+	defer os.RemoveAll(tempDir)
+	storage.Close()
+
+	missingDir := filepath.Join(t.TempDir(), "no-such-snapshot")
+	cmd := NewDiffCommand()
+	if err := cmd.Flags().Set("path", tempDir); err != nil {
+		t.Fatalf("Failed to set path flag: %v", err)
+	}
+	if err := cmd.Flags().Set("base", missingDir); err != nil {
+		t.Fatalf("Failed to set base flag: %v", err)
+	}
+	cmd.SetOut(&bytes.Buffer{})
+
+	err := cmd.RunE(cmd, []string{})
+	if err == nil || !strings.Contains(err.Error(), "no index snapshot at") {
+		t.Errorf("Expected missing snapshot error, got %v", err)
+	}
+	if _, err := os.Stat(missingDir); !os.IsNotExist(err) {
+		t.Errorf("Expected no index to be created at the missing base, got %v", err)
+	}
+}
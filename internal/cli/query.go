@@ -32,6 +32,8 @@ type QueryFlags struct {
 	IncludeTypes   bool
 	Depth          int
 	MaxTokens      int
+	TokenEstimator string
+	SourceOrder    bool // List --file results in declaration order instead of grouped by kind
 
 	// Output flags
 	Format  string
@@ -40,7 +42,8 @@ type QueryFlags struct {
 	Compact bool
 
 	// Repository flags
-	Path string
+	Path  string
+	Since string // Only return entities in files changed since this git ref
 }
 
 // NewQueryCommand creates the query command for searching indexed repositories
@@ -77,9 +80,12 @@ Context Options:
   --include-types   Include related type definitions
   --depth           Maximum depth for relationship traversal (default: 2)
   --max-tokens      Maximum tokens for LLM consumption (0 = no limit)
+  --token-estimator Token estimator: heuristic, bpe (default: heuristic)
+  --since           Only return entities in files changed since a git ref
+  --source-order    List --file results top to bottom as declared instead of grouped by kind
 
 Output Options:
-  --format          Output format: text, json (default: text)
+  --format          Output format: text, json, jsonl, markdown (default: text)
   --json            Shorthand for --format json
   --verbose         Include detailed information
   --compact         Minimal output
@@ -97,8 +103,17 @@ Examples:
   # Search in a specific file
   repocontext query --file main.go
 
+  # Outline a file in declaration order
+  repocontext query --file main.go --include-types --source-order
+
+  # Stream one JSON object per entry for tools like jq
+  repocontext query --entity-type function --format jsonl | jq -r 'select(.kind == "entry") | .index_entry.name'
+
   # Pattern search
-  repocontext query --search "Test*" --compact`,
+  repocontext query --search "Test*" --compact
+
+  # Functions touched by the branch under review
+  repocontext query --entity-type function --since main`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return runQuery(flags, cmd)
 		},
@@ -121,15 +136,20 @@ func addQueryFlags(cmd *cobra.Command, flags *QueryFlags) {
 	cmd.Flags().BoolVar(&flags.IncludeTypes, "include-types", false, "Include related type definitions")
 	cmd.Flags().IntVar(&flags.Depth, "depth", DefaultDepth, "Maximum depth for relationship traversal")
 	cmd.Flags().IntVar(&flags.MaxTokens, "max-tokens", 0, "Maximum tokens for LLM consumption (0 = no limit)")
+	cmd.Flags().StringVar(&flags.TokenEstimator, "token-estimator", index.TokenEstimatorHeuristic,
+		"Token estimator used for --max-tokens truncation: heuristic, bpe")
+	cmd.Flags().BoolVar(&flags.SourceOrder, "source-order", false,
+		"List --file results in declaration order instead of grouped by kind")
 
 	// Output flags
-	cmd.Flags().StringVar(&flags.Format, "format", "text", "Output format: text, json")
+	cmd.Flags().StringVar(&flags.Format, "format", "text", "Output format: text, json, jsonl, markdown")
 	cmd.Flags().BoolVar(&flags.JSON, "json", false, "Output in JSON format (shorthand for --format json)")
-	cmd.Flags().BoolVar(&flags.Verbose, "verbose", false, "Include detailed information")
+	cmd.Flags().BoolVar(&flags.Verbose, "verbose", false, "List function parameters, returns and doc comments in text and markdown output")
 	cmd.Flags().BoolVar(&flags.Compact, "compact", false, "Minimal output")
 
 	// Repository flags
 	cmd.Flags().StringVarP(&flags.Path, "path", "p", ".", "Path to the repository (defaults to current directory)")
+	cmd.Flags().StringVar(&flags.Since, "since", "", "Only return entities in files changed since this git ref (e.g. main)")
 }
 
 // runQuery executes the query command
@@ -152,6 +172,11 @@ func runQuery(flags *QueryFlags, cmd *cobra.Command) error {
 	defer storage.Close()
 
 	queryEngine := index.NewQueryEngine(storage)
+	estimator, err := index.NewTokenEstimator(flags.TokenEstimator)
+	if err != nil {
+		return err
+	}
+	queryEngine.SetTokenEstimator(estimator)
 
 	// Execute search
 	result, err := executeSearch(queryEngine, flags)
@@ -187,6 +212,22 @@ func executeSearch(queryEngine *index.QueryEngine, flags *QueryFlags) (*index.Se
 		MaxDepth:       flags.Depth,
 		MaxTokens:      flags.MaxTokens,
 		Format:         flags.Format,
+		Verbose:        flags.Verbose,
+		OrderBySource:  flags.SourceOrder,
+	}
+
+	// Restrict results to the files changed since the ref, made repo-relative as the index records them
+	if flags.Since != "" {
+		repoPath, err := filepath.Abs(flags.Path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get absolute path: %w", err)
+		}
+		changedFiles, err := index.ChangedFiles(repoPath, flags.Since)
+		if err != nil {
+			return nil, err
+		}
+		queryOptions.ChangedFiles = index.RepoRelativePaths(repoPath, changedFiles)
+		queryOptions.ChangedOnly = true
 	}
 
 	// Enable types by default for pattern searches since classes/types are often searched
@@ -206,7 +247,7 @@ func executeSearch(queryEngine *index.QueryEngine, flags *QueryFlags) (*index.Se
 	case flags.Variable != "":
 		result, err = queryEngine.SearchByNameWithOptions(flags.Variable, queryOptions)
 	case flags.File != "":
-		result, err = queryEngine.SearchInFileWithOptions(flags.File, queryOptions)
+		result, err = queryEngine.SearchInFileWithOptions(index.RepoRelativePath(flags.Path, flags.File), queryOptions)
 	case flags.Search != "":
 		result, err = queryEngine.SearchByPatternWithOptions(flags.Search, queryOptions)
 	case flags.EntityType != "":
@@ -223,6 +264,14 @@ func executeSearch(queryEngine *index.QueryEngine, flags *QueryFlags) (*index.Se
 }
 
 func outputResults(result *index.SearchResult, queryEngine *index.QueryEngine, flags *QueryFlags, cmd *cobra.Command) error {
+	// Stream JSON Lines directly to stdout so large results are not buffered
+	if flags.Format == "jsonl" {
+		if err := index.WriteJSONLines(cmd.OutOrStdout(), result); err != nil {
+			return fmt.Errorf("failed to write results: %w", err)
+		}
+		return nil
+	}
+
 	// Format and output results
 	output, err := queryEngine.FormatResults(result, flags.Format)
 	if err != nil {
@@ -277,7 +326,7 @@ func validateSearchCriteria(flags *QueryFlags) error {
 // validateFlags validates flag values
 func validateFlags(flags *QueryFlags) error {
 	// Validate format
-	validFormats := []string{"text", "json"}
+	validFormats := []string{"text", "json", "jsonl", "markdown", "md"}
 	if !contains(validFormats, flags.Format) {
 		return fmt.Errorf("invalid format '%s', must be one of: %s", flags.Format, strings.Join(validFormats, ", "))
 	}
@@ -292,6 +341,11 @@ func validateFlags(flags *QueryFlags) error {
 		return fmt.Errorf("max-tokens must be non-negative, got %d", flags.MaxTokens)
 	}
 
+	// Validate token estimator
+	if _, err := index.NewTokenEstimator(flags.TokenEstimator); err != nil {
+		return err
+	}
+
 	// Validate entity-type if specified
 	if flags.EntityType != "" {
 		validEntityTypes := []string{"function", "type", "variable", "constant"}
@@ -450,6 +450,48 @@ func TestQueryCommand_JSONOutputCallGraphFlags(t *testing.T) {
 	}
 }
 
+func TestQueryCommand_JSONLinesOutput(t *testing.T) {
+	tempDir, storage := setupTestRepository(t)
+	defer os.RemoveAll(tempDir)
+	defer storage.Close()
+
+	addTestData(t, storage)
+
+	cmd := NewQueryCommand()
+	setRequiredFlags(t, cmd, tempDir, "entity-type", "function")
+
+	if err := cmd.Flags().Set("format", "jsonl"); err != nil {
+		t.Fatalf("Failed to set format flag: %v", err)
+	}
+
+	var buf bytes.Buffer
+	cmd.SetOut(&buf)
+
+	if err := cmd.RunE(cmd, []string{}); err != nil {
+		t.Fatalf("Expected jsonl search to succeed, got error: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected 2 entry lines and a summary line, got %d:\n%s", len(lines), buf.String())
+	}
+
+	for i, line := range lines {
+		var record map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("Line %d is not valid JSON: %v", i+1, err)
+		}
+
+		expectedKind := index.JSONLinesKindEntry
+		if i == len(lines)-1 {
+			expectedKind = index.JSONLinesKindSummary
+		}
+		if record["kind"] != expectedKind {
+			t.Errorf("Expected line %d to have kind %q, got %v", i+1, expectedKind, record["kind"])
+		}
+	}
+}
+
 func TestQueryCommand_SearchByPatternWithOptions(t *testing.T) {
 	runQueryTest(t, testQueryWithFlags{
 		searchType:   "search",
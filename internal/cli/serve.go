@@ -1,3 +1,78 @@
 package cli
 
 // HTTP server mode command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+
+	"repository-context-protocol/internal/mcp"
+
+	"github.com/spf13/cobra"
+)
+
+// DefaultServeAddr is the address the HTTP API listens on when --addr is not given
+const DefaultServeAddr = ":8080"
+
+// NewServeCommand creates the serve command exposing the query engine over HTTP
+func NewServeCommand() *cobra.Command {
+	var path string
+	var addr string
+
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Serve repository context via an HTTP API",
+		Long: `Serve the semantic index over HTTP for clients that do not speak MCP.
+
+Endpoints:
+  GET /query?name=...            Search by exact name (same as query_by_name)
+  GET /pattern?p=...             Search by pattern (same as query_by_pattern)
+  GET /callgraph?fn=...&depth=2  Call graph for a function (same as get_call_graph)
+  GET /status                    Repository status (same as get_repository_status)
+  GET /healthz                   Liveness check
+
+Other query parameters are passed through under their MCP tool argument
+names, e.g. /query?name=main&include_callers=true. Responses are the same
+JSON documents the MCP tools return.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runServe(path, addr, cmd)
+		},
+	}
+
+	cmd.Flags().StringVarP(&path, "path", "p", ".", "Path to the repository (defaults to current directory)")
+	cmd.Flags().StringVar(&addr, "addr", DefaultServeAddr, "Address to listen on")
+
+	return cmd
+}
+
+// runServe executes the serve command
+func runServe(path, addr string, cmd *cobra.Command) error {
+	if err := validateRepository(path); err != nil {
+		return err
+	}
+
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return fmt.Errorf("failed to resolve path: %w", err)
+	}
+
+	server := mcp.NewRepoContextMCPServer()
+	if err := server.InitializeRepository(absPath); err != nil {
+		return fmt.Errorf("failed to open index: %w", err)
+	}
+	defer server.Storage.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	cmd.Printf("Serving repository context for %s on %s\n", absPath, addr)
+	if err := server.RunHTTP(ctx, addr); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("HTTP server failed: %w", err)
+	}
+	return nil
+}
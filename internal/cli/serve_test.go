@@ -0,0 +1,28 @@
+package cli
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestServeCommand_Uninitialized(t *testing.T) {
+	cmd := NewServeCommand()
+	if err := cmd.Flags().Set("path", t.TempDir()); err != nil {
+		t.Fatalf("Failed to set path flag: %v", err)
+	}
+	cmd.SetOut(&bytes.Buffer{})
+	if err := cmd.RunE(cmd, []string{}); err == nil {
+		t.Error("Expected error for uninitialized repository")
+	}
+}
+
+func TestServeCommand_DefaultAddr(t *testing.T) {
+	cmd := NewServeCommand()
+	flag := cmd.Flags().Lookup("addr")
+	if flag == nil {
+		t.Fatal("Expected --addr flag")
+	}
+	if flag.DefValue != DefaultServeAddr {
+		t.Errorf("Expected default address %s, got %s", DefaultServeAddr, flag.DefValue)
+	}
+}
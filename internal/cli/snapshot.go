@@ -0,0 +1,104 @@
+package cli
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"repository-context-protocol/internal/index"
+
+	"github.com/spf13/cobra"
+)
+
+// NewSnapshotCommand creates the snapshot command for saving a named copy of the index
+func NewSnapshotCommand() *cobra.Command {
+	var path string
+	var name string
+
+	cmd := &cobra.Command{
+		Use:   "snapshot",
+		Short: "Save a named copy of the index",
+		Long: `Save a named copy of the index before experimental reindexing.
+
+This command:
+- Copies .repocontext/index.db, the chunk files and the manifest into
+  .repocontext/snapshots/<name>/
+- Lists the saved snapshots when no name is given
+
+Use 'repocontext restore --name <name>' to roll the index back to a snapshot.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runSnapshot(path, name, cmd)
+		},
+	}
+
+	cmd.Flags().StringVarP(&path, "path", "p", ".", "Path to the repository (defaults to current directory)")
+	cmd.Flags().StringVarP(&name, "name", "n", "", "Name of the snapshot to save; lists snapshots when empty")
+
+	return cmd
+}
+
+// NewRestoreCommand creates the restore command for rolling the index back to a snapshot
+func NewRestoreCommand() *cobra.Command {
+	var path string
+	var name string
+
+	cmd := &cobra.Command{
+		Use:   "restore",
+		Short: "Restore the index from a named snapshot",
+		Long: `Replace the index with a snapshot saved by 'repocontext snapshot'.
+
+The restore is refused while an index build holds the index open for writes.
+Running servers keep answering from the replaced index until restarted.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runRestore(path, name, cmd)
+		},
+	}
+
+	cmd.Flags().StringVarP(&path, "path", "p", ".", "Path to the repository (defaults to current directory)")
+	cmd.Flags().StringVarP(&name, "name", "n", "", "Name of the snapshot to restore")
+
+	return cmd
+}
+
+// runSnapshot executes the snapshot command
+func runSnapshot(path, name string, cmd *cobra.Command) error {
+	if err := validateRepository(path); err != nil {
+		return err
+	}
+	repoContextDir := filepath.Join(path, ".repocontext")
+
+	if name == "" {
+		names, err := index.ListSnapshots(repoContextDir)
+		if err != nil {
+			return err
+		}
+		if len(names) == 0 {
+			cmd.Println("No snapshots saved")
+		}
+		for _, snapshot := range names {
+			cmd.Println(snapshot)
+		}
+		return nil
+	}
+
+	if err := index.SaveSnapshot(repoContextDir, name); err != nil {
+		return err
+	}
+	cmd.Printf("Snapshot %q saved\n", name)
+	return nil
+}
+
+// runRestore executes the restore command
+func runRestore(path, name string, cmd *cobra.Command) error {
+	if err := validateRepository(path); err != nil {
+		return err
+	}
+	if name == "" {
+		return fmt.Errorf("a snapshot name is required (--name)")
+	}
+
+	if err := index.RestoreSnapshot(filepath.Join(path, ".repocontext"), name); err != nil {
+		return err
+	}
+	cmd.Printf("Index restored from snapshot %q\n", name)
+	return nil
+}
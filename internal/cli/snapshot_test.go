@@ -0,0 +1,63 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+// runSnapshotTestCommand runs a snapshot or restore command against path and returns its output
+func runSnapshotTestCommand(t *testing.T, cmd *cobra.Command, path, name string) (string, error) {
+	t.Helper()
+
+	if err := cmd.Flags().Set("path", path); err != nil {
+		t.Fatalf("Failed to set path flag: %v", err)
+	}
+	if err := cmd.Flags().Set("name", name); err != nil {
+		t.Fatalf("Failed to set name flag: %v", err)
+	}
+	var buf bytes.Buffer
+	cmd.SetOut(&buf)
+	err := cmd.RunE(cmd, []string{})
+	return buf.String(), err
+}
+
+func TestSnapshotCommand_SaveListRestore(t *testing.T) {
+	tempDir, storage := setupTestRepository(t)
+	defer os.RemoveAll(tempDir)
+
+	addTestData(t, storage)
+	storage.Close()
+
+	output, err := runSnapshotTestCommand(t, NewSnapshotCommand(), tempDir, "")
+	if err != nil || !strings.Contains(output, "No snapshots saved") {
+		t.Errorf("Expected empty snapshot list, got %q (err: %v)", output, err)
+	}
+
+	if _, err := runSnapshotTestCommand(t, NewSnapshotCommand(), tempDir, "baseline"); err != nil {
+		t.Fatalf("Expected snapshot to succeed, got error: %v", err)
+	}
+
+	output, err = runSnapshotTestCommand(t, NewSnapshotCommand(), tempDir, "")
+	if err != nil || strings.TrimSpace(output) != "baseline" {
+		t.Errorf("Expected snapshot list to name baseline, got %q (err: %v)", output, err)
+	}
+
+	output, err = runSnapshotTestCommand(t, NewRestoreCommand(), tempDir, "baseline")
+	if err != nil || !strings.Contains(output, "restored") {
+		t.Errorf("Expected restore to succeed, got %q (err: %v)", output, err)
+	}
+}
+
+func TestRestoreCommand_RequiresName(t *testing.T) {
+	tempDir, storage := setupTestRepository(t)
+	defer os.RemoveAll(tempDir)
+	storage.Close()
+
+	if _, err := runSnapshotTestCommand(t, NewRestoreCommand(), tempDir, ""); err == nil {
+		t.Error("Expected restore without a name to fail")
+	}
+}
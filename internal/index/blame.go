@@ -0,0 +1,37 @@
+package index
+
+import "repository-context-protocol/internal/models"
+
+// annotateLastModified records on the functions and types of fileContext the newest
+// commit touching their lines. Files git cannot blame, such as those outside a checkout
+// or not yet committed, are left unannotated rather than failing the build.
+func annotateLastModified(fileContext *models.FileContext) {
+	lines, err := BlameFile(fileContext.Path)
+	if err != nil {
+		return
+	}
+
+	for i := range fileContext.Functions {
+		function := &fileContext.Functions[i]
+		if change, ok := lastChange(lines, function.StartLine, function.EndLine); ok {
+			function.LastModified, function.LastCommit = &change.Time, change.Commit
+		}
+	}
+	for i := range fileContext.Types {
+		typeDef := &fileContext.Types[i]
+		if change, ok := lastChange(lines, typeDef.StartLine, typeDef.EndLine); ok {
+			typeDef.LastModified, typeDef.LastCommit = &change.Time, change.Commit
+		}
+	}
+}
+
+// lastChange returns the newest committed change among lines startLine to endLine
+func lastChange(lines []LineBlame, startLine, endLine int) (LineBlame, bool) {
+	var newest LineBlame
+	for line := max(startLine, 1); line <= min(endLine, len(lines)); line++ {
+		if change := lines[line-1]; change.Commit != "" && change.Time.After(newest.Time) {
+			newest = change
+		}
+	}
+	return newest, newest.Commit != ""
+}
@@ -1,15 +1,26 @@
 package index
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"io/fs"
+	"maps"
 	"os"
 	"path/filepath"
+	"runtime"
+	"slices"
 	"strings"
+	"sync"
 	"time"
 
 	"repository-context-protocol/internal/ast"
+	"repository-context-protocol/internal/ast/c"
 	"repository-context-protocol/internal/ast/golang"
+	"repository-context-protocol/internal/ast/java"
 	"repository-context-protocol/internal/ast/python"
+	"repository-context-protocol/internal/ast/ruby"
+	"repository-context-protocol/internal/ast/typescript"
 	"repository-context-protocol/internal/models"
 )
 
@@ -18,11 +29,86 @@ import (
 // IndexBuilder orchestrates the parsing and indexing of repository files
 type IndexBuilder struct {
 	rootPath       string
+	options        IndexBuilderOptions
 	storage        *HybridStorage
 	parserRegistry *ast.ParserRegistry
 	stats          IndexStatistics
 }
 
+// IndexBuilderOptions configures what the index builder stores
+type IndexBuilderOptions struct {
+	// StoreSource keeps compressed file content in the chunks so context tools can
+	// return exact lines after the working tree has changed, at the cost of index size
+	StoreSource bool
+
+	// MatchBuildTags leaves out Go files whose build constraints are not satisfied by
+	// BuildTags and the host GOOS and GOARCH, as go build does. When false every file
+	// is indexed whatever its constraints.
+	MatchBuildTags bool
+	BuildTags      []string
+
+	// ToolVersion is recorded with each build in the manifest build history
+	ToolVersion string
+
+	// PythonInterpreter is the Python executable or virtualenv directory running the
+	// Python extractor; relative paths such as .venv are resolved against the root path.
+	// When empty the REPOCONTEXT_PYTHON environment variable and then PATH are consulted,
+	// see python.NewPythonParserWithInterpreter.
+	PythonInterpreter string
+
+	// MaxFileSize is the size in bytes above which files are skipped rather than parsed,
+	// so a generated or minified file cannot stall the build. DefaultMaxFileSize is used
+	// when zero, and a negative size removes the limit.
+	MaxFileSize int64
+
+	// Concurrency is the number of files BuildIndex parses at once. GOMAXPROCS is used
+	// when zero or negative. Results are stored in walk order whatever the concurrency,
+	// so the statistics and the index do not depend on it.
+	Concurrency int
+
+	// ChangedSince makes BuildIndex reindex only the files changed relative to this git
+	// ref, as listed by ChangedFiles, into the existing index and remove deleted ones
+	// instead of rebuilding every file
+	ChangedSince string
+
+	// GitBlame annotates functions and types with the last commit changing their lines,
+	// running git blame on every file. Files git cannot blame are left unannotated.
+	GitBlame bool
+
+	// NormalizeTypes records next to each parameter, return and field type its
+	// language-independent form, e.g. list<string> for List[str], []string and string[]
+	NormalizeTypes bool
+
+	// IndexAnonymous also indexes Go function literals and Python lambdas, as functions
+	// named func@file:line or lambda@file:line with their enclosing function as parent.
+	// The calls made inside them are then their own rather than the enclosing function's.
+	IndexAnonymous bool
+
+	// CompressChunks gzips the chunk files written by the build, roughly halving the
+	// index on disk at the cost of decompressing chunks on every query reading them.
+	// Chunks written without compression, e.g. by older builds, still load.
+	CompressChunks bool
+
+	// EntryPointPatterns marks more functions as entry points, e.g. framework handlers
+	// called by reflection, besides main and the like. Each pattern is a glob matched
+	// against function names and Receiver.Name for methods, such as "Handle*", or against
+	// decorator names when it starts with @, such as "@app.route". Entry points are never
+	// dead code candidates and are listed first in overviews.
+	EntryPointPatterns []string
+}
+
+const (
+	// DefaultMaxFileSize is the file size limit used when IndexBuilderOptions.MaxFileSize is zero
+	DefaultMaxFileSize = 4 << 20
+
+	// Bytes examined when detecting binary files, as git does
+	binaryDetectionLength = 8000
+
+	// SkipReasonBuildConstraints and SkipReasonBinary explain why a file was skipped
+	SkipReasonBuildConstraints = "build constraints not satisfied"
+	SkipReasonBinary           = "binary content"
+)
+
 // IndexStatistics tracks indexing progress and results
 type IndexStatistics struct {
 	FilesProcessed   int
@@ -34,47 +120,141 @@ type IndexStatistics struct {
 	StartTime        time.Time
 	EndTime          time.Time
 	Duration         time.Duration
+	FailedFiles      []FileError    // Files skipped because they could not be read or parsed
+	SkippedFiles     []SkippedFile  // Files left out on purpose: unsatisfied build constraints, too large or binary
+	Languages        map[string]int // Files processed per language
+}
+
+// FileError records why a file was left out of the index
+type FileError struct {
+	Path    string `json:"path"`
+	Kind    string `json:"kind,omitempty"`   // Parse error kind: syntax, io or setup
+	Line    int    `json:"line,omitempty"`   // 1-based line of the error, zero when unknown
+	Column  int    `json:"column,omitempty"` // 1-based column of the error, zero when unknown
+	Message string `json:"message"`
+}
+
+// SkippedFile records a file left out of the index on purpose and why
+type SkippedFile struct {
+	Path   string `json:"path"`
+	Reason string `json:"reason"`
+}
+
+// String renders the skipped file as "path: reason"
+func (sf SkippedFile) String() string {
+	return sf.Path + ": " + sf.Reason
+}
+
+// newFileError builds a FileError, taking the location from a ParseError when the parser provided one
+func newFileError(path string, err error) FileError {
+	var parseErr *models.ParseError
+	if errors.As(err, &parseErr) {
+		return FileError{Path: path, Kind: parseErr.Kind, Line: parseErr.Line, Column: parseErr.Column, Message: parseErr.Message}
+	}
+	return FileError{Path: path, Message: err.Error()}
+}
+
+// Error renders the failure as "path:line:column: message"
+func (fe FileError) Error() string {
+	location := fe.Path
+	if fe.Line > 0 {
+		location += fmt.Sprintf(":%d", fe.Line)
+		if fe.Column > 0 {
+			location += fmt.Sprintf(":%d", fe.Column)
+		}
+	}
+	return location + ": " + fe.Message
 }
 
 // NewIndexBuilder creates a new index builder for the given root path
 func NewIndexBuilder(rootPath string) *IndexBuilder {
+	return NewIndexBuilderWithOptions(rootPath, IndexBuilderOptions{})
+}
+
+// NewIndexBuilderWithOptions creates a new index builder with the given options
+func NewIndexBuilderWithOptions(rootPath string, options IndexBuilderOptions) *IndexBuilder {
 	return &IndexBuilder{
 		rootPath: rootPath,
+		options:  options,
 		stats:    IndexStatistics{},
 	}
 }
 
 // Initialize sets up the index builder and its components
 func (ib *IndexBuilder) Initialize() error {
+	// Initialize parser registry first so a misconfigured parser leaves no storage open
+	if err := ib.initializeParsers(); err != nil {
+		return fmt.Errorf("failed to initialize parsers: %w", err)
+	}
+
 	// Initialize hybrid storage with .repocontext subdirectory
 	repoContextDir := filepath.Join(ib.rootPath, ".repocontext")
 	ib.storage = NewHybridStorage(repoContextDir)
+	ib.storage.SetChunkCompression(ib.options.CompressChunks)
 	if err := ib.storage.Initialize(); err != nil {
 		return fmt.Errorf("failed to initialize storage: %w", err)
 	}
 
-	// Initialize parser registry
-	ib.initializeParsers()
+	// Mark the index as open for writes so snapshots are not restored underneath it
+	if err := acquireWriteLock(repoContextDir); err != nil {
+		ib.storage.Close()
+		ib.storage = nil
+		return err
+	}
+
+	// An empty index stores paths repo-relative from the start; older indexes are
+	// migrated by the next full build
+	files, err := ib.storage.ListFiles()
+	if err == nil && len(files) == 0 {
+		err = ib.storage.SetPathFormat(models.PathFormatRepoRelative)
+	}
+	if err != nil {
+		ib.Close()
+		return fmt.Errorf("failed to initialize storage: %w", err)
+	}
 
 	return nil
 }
 
 // initializeParsers sets up the parser registry with available language parsers
-func (ib *IndexBuilder) initializeParsers() {
+func (ib *IndexBuilder) initializeParsers() error {
 	// Create parser registry
 	ib.parserRegistry = ast.NewParserRegistry()
 
 	// Register Go parser
 	goParser := golang.NewGoParser()
+	goParser.SetIndexAnonymous(ib.options.IndexAnonymous)
 	ib.parserRegistry.Register(goParser)
 
 	// Register Python parser
-	pythonParser := python.NewPythonParser()
+	interpreter := ib.options.PythonInterpreter
+	if interpreter != "" && !filepath.IsAbs(interpreter) && filepath.Base(interpreter) != interpreter {
+		interpreter = filepath.Join(ib.rootPath, interpreter)
+	}
+	pythonParser, err := python.NewPythonParserWithInterpreter(interpreter)
+	if err != nil {
+		return err
+	}
+	pythonParser.SetIndexAnonymous(ib.options.IndexAnonymous)
 	ib.parserRegistry.Register(pythonParser)
 
-	// Future: Register additional parsers
-	// typescriptParser := typescript.NewTypeScriptParser()
-	// ib.parserRegistry.Register(typescriptParser)
+	// Register Java parser
+	javaParser := java.NewJavaParser()
+	ib.parserRegistry.Register(javaParser)
+
+	// Register C and C++ parser
+	cParser := c.NewCParser()
+	ib.parserRegistry.Register(cParser)
+
+	// Register Ruby parser
+	rubyParser := ruby.NewRubyParser()
+	ib.parserRegistry.Register(rubyParser)
+
+	// Register TypeScript parser, which indexes type declarations only
+	typescriptParser := typescript.NewTypeScriptParser()
+	ib.parserRegistry.Register(typescriptParser)
+
+	return nil
 }
 
 // ProcessFile processes a single file and adds it to the index
@@ -83,10 +263,29 @@ func (ib *IndexBuilder) ProcessFile(filePath string) error {
 		return fmt.Errorf("index builder not initialized")
 	}
 
+	fileContext, err := ib.parseFile(filePath)
+	if err != nil || fileContext == nil {
+		return err
+	}
+
+	// Store in hybrid storage
+	if err := ib.storage.StoreFileContext(fileContext); err != nil {
+		return fmt.Errorf("failed to store file context: %w", err)
+	}
+
+	// Update statistics
+	ib.updateStatistics(fileContext)
+
+	return nil
+}
+
+// parseFile validates, reads and parses a single file. It returns a nil context for
+// unsupported file types and files excluded by build constraints.
+func (ib *IndexBuilder) parseFile(filePath string) (*models.FileContext, error) {
 	// Validate and clean the file path
 	cleanPath, err := ib.validateAndCleanPath(filePath)
 	if err != nil {
-		return fmt.Errorf("invalid file path %s: %w", filePath, err)
+		return nil, fmt.Errorf("invalid file path %s: %w", filePath, err)
 	}
 
 	// Get file extension
@@ -96,30 +295,95 @@ func (ib *IndexBuilder) ProcessFile(filePath string) error {
 	parser, exists := ib.parserRegistry.GetParser(ext)
 	if !exists {
 		// Skip unsupported file types
-		return nil
+		return nil, nil
 	}
 
 	// Read file content
-	content, err := os.ReadFile(cleanPath) // #nosec G304 - Path validated above
+	content, skipReason, err := ib.readSource(cleanPath)
 	if err != nil {
-		return fmt.Errorf("failed to read file %s: %w", cleanPath, err)
+		return nil, fmt.Errorf("failed to read file %s: %w", cleanPath, err)
+	}
+	if skipReason != "" {
+		ib.skipFile(cleanPath, skipReason)
+		return nil, nil
 	}
 
 	// Parse the file using the registry parser
 	fileContext, err := parser.ParseFile(cleanPath, content)
 	if err != nil {
-		return fmt.Errorf("failed to parse file %s: %w", cleanPath, err)
+		return nil, fmt.Errorf("failed to parse file %s: %w", cleanPath, err)
+	}
+	if !ib.matchesBuildTags(fileContext) {
+		ib.skipFile(cleanPath, SkipReasonBuildConstraints)
+		return nil, nil
+	}
+	if err := ib.attachSource(fileContext, content); err != nil {
+		return nil, err
+	}
+	if ib.options.GitBlame {
+		annotateLastModified(fileContext)
+	}
+	if ib.options.NormalizeTypes {
+		annotateNormalizedTypes(fileContext)
+	}
+	annotateEntryPoints(fileContext, ib.options.EntryPointPatterns)
+	annotateRecursion(fileContext)
+	annotateContentHashes(fileContext, content)
+	ib.relativizePaths(fileContext)
+	return fileContext, nil
+}
+
+// readSource reads a file for parsing. Files larger than the size limit or with binary
+// content are not returned; the reason to skip them is instead. It leaves the statistics
+// untouched so files can be read concurrently.
+func (ib *IndexBuilder) readSource(cleanPath string) (content []byte, skipReason string, err error) {
+	info, err := os.Stat(cleanPath)
+	if err != nil {
+		return nil, "", err
+	}
+	if limit := ib.maxFileSize(); limit > 0 && info.Size() > limit {
+		return nil, fmt.Sprintf("file size %d bytes exceeds the %d byte limit", info.Size(), limit), nil
 	}
 
-	// Store in hybrid storage
-	if err := ib.storage.StoreFileContext(fileContext); err != nil {
-		return fmt.Errorf("failed to store file context: %w", err)
+	content, err = os.ReadFile(cleanPath) // #nosec G304 - Path validated by the caller
+	if err != nil {
+		return nil, "", err
 	}
+	if isBinary(content) {
+		return nil, SkipReasonBinary, nil
+	}
+	return content, "", nil
+}
 
-	// Update statistics
-	ib.updateStatistics(fileContext)
+// maxFileSize returns the configured file size limit, zero meaning no limit
+func (ib *IndexBuilder) maxFileSize() int64 {
+	switch {
+	case ib.options.MaxFileSize == 0:
+		return DefaultMaxFileSize
+	case ib.options.MaxFileSize < 0:
+		return 0
+	}
+	return ib.options.MaxFileSize
+}
 
-	return nil
+// skipFile records a file left out of the index on purpose
+func (ib *IndexBuilder) skipFile(path, reason string) {
+	ib.stats.SkippedFiles = append(ib.stats.SkippedFiles, SkippedFile{Path: path, Reason: reason})
+}
+
+// isBinary reports whether content looks binary: text source files contain no null bytes
+func isBinary(content []byte) bool {
+	return bytes.IndexByte(content[:min(len(content), binaryDetectionLength)], 0) >= 0
+}
+
+// matchesBuildTags reports whether the file's build constraint allows indexing it.
+// Files whose constraint cannot be evaluated are indexed rather than silently dropped.
+func (ib *IndexBuilder) matchesBuildTags(fileContext *models.FileContext) bool {
+	if !ib.options.MatchBuildTags {
+		return true
+	}
+	matched, err := golang.MatchBuildConstraint(fileContext.BuildConstraint, ib.options.BuildTags)
+	return err != nil || matched
 }
 
 // ProcessDirectory processes all supported files in a directory recursively
@@ -139,12 +403,171 @@ func (ib *IndexBuilder) ProcessDirectory(dirPath string) error {
 	})
 }
 
-// BuildIndex builds a complete index of the repository
+// BuildIndex builds a complete index of the repository, or updates the files changed
+// since a git ref when the ChangedSince option is set
 func (ib *IndexBuilder) BuildIndex() (*IndexStatistics, error) {
+	if ib.options.ChangedSince != "" {
+		return ib.buildChangedSince(ib.options.ChangedSince)
+	}
 	ib.stats.StartTime = time.Now()
 
-	// Phase 1: Parse all files individually
-	var fileContexts []models.FileContext
+	// Phase 1: Parse all files, concurrently, in walk order
+	jobs, err := ib.collectParseJobs()
+	if err != nil {
+		return nil, fmt.Errorf("failed to process directory: %w", err)
+	}
+	fileContexts, err := ib.collectParseOutcomes(jobs, ib.parseConcurrently(jobs))
+	if err != nil {
+		return nil, fmt.Errorf("failed to process directory: %w", err)
+	}
+
+	// Phase 2: Global enrichment - enhance file contexts with cross-file analysis
+	enrichment := NewGlobalEnrichment()
+	enrichedContexts, err := enrichment.EnrichFileContexts(fileContexts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to enrich file contexts: %w", err)
+	}
+
+	// Attach methods declared in other files of the same package to their receiver types
+	linkMethodReceivers(enrichedContexts)
+
+	// Record the Go fields and methods promoted through embedded types
+	linkPromotedMembers(enrichedContexts)
+
+	// Phase 3: Store enriched contexts
+	if err := ib.migratePathFormat(); err != nil {
+		return nil, err
+	}
+	for i := range enrichedContexts {
+		if err := ib.storage.StoreFileContext(&enrichedContexts[i]); err != nil {
+			return nil, fmt.Errorf("failed to store file context: %w", err)
+		}
+
+		// Update statistics
+		ib.updateStatistics(&enrichedContexts[i])
+	}
+
+	ib.stats.EndTime = time.Now()
+	ib.stats.Duration = ib.stats.EndTime.Sub(ib.stats.StartTime)
+
+	if err := ib.storage.RecordBuild(ib.buildRecord()); err != nil {
+		return nil, fmt.Errorf("failed to record build: %w", err)
+	}
+
+	return &ib.stats, nil
+}
+
+// migratePathFormat drops the files of an index built before paths were stored
+// repo-relative, as the build is about to store them again under their new paths,
+// and records the new format
+func (ib *IndexBuilder) migratePathFormat() error {
+	if ib.storage.PathFormat() == models.PathFormatRepoRelative {
+		return nil
+	}
+
+	files, err := ib.storage.ListFiles()
+	if err != nil {
+		return fmt.Errorf("failed to list indexed files: %w", err)
+	}
+	for _, file := range files {
+		if err := ib.storage.DeleteFile(file); err != nil {
+			return fmt.Errorf("failed to migrate %s: %w", file, err)
+		}
+	}
+	if err := ib.storage.SetPathFormat(models.PathFormatRepoRelative); err != nil {
+		return fmt.Errorf("failed to record path format: %w", err)
+	}
+	return nil
+}
+
+// repoRelative returns a path found while walking the repository relative to its root
+func (ib *IndexBuilder) repoRelative(path string) string {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return path
+	}
+	return RepoRelativePath(ib.rootPath, absPath)
+}
+
+// relativizePaths stores the file of a parsed context, and the references parsers make
+// to it, relative to the repository root so the index does not depend on how the
+// builder was pointed at the repository
+func (ib *IndexBuilder) relativizePaths(fileContext *models.FileContext) {
+	parsedPath := fileContext.Path
+	fileContext.Path = ib.repoRelative(parsedPath)
+
+	relativize := func(references []models.CallReference) {
+		for i := range references {
+			if references[i].File == parsedPath {
+				references[i].File = fileContext.Path
+			}
+		}
+	}
+	for i := range fileContext.Functions {
+		function := &fileContext.Functions[i]
+		relativize(function.LocalCallsWithMetadata)
+		relativize(function.CrossFileCalls)
+		relativize(function.CrossFileCallers)
+	}
+	for i := range fileContext.Types {
+		for j := range fileContext.Types[i].Methods {
+			if method := &fileContext.Types[i].Methods[j]; method.File == parsedPath {
+				method.File = fileContext.Path
+			}
+		}
+	}
+	if ib.options.IndexAnonymous && parsedPath != fileContext.Path {
+		renameAnonymousFunctions(fileContext, parsedPath)
+	}
+}
+
+// renameAnonymousFunctions rewrites the names parsers give anonymous functions, such as
+// func@parsedPath:12, to use the repo-relative path of the file, so they are the same
+// whatever path the builder parsed the file under
+func renameAnonymousFunctions(fileContext *models.FileContext, parsedPath string) {
+	replacer := strings.NewReplacer("@"+parsedPath+":", "@"+fileContext.Path+":")
+	rename := func(names []string) {
+		for i := range names {
+			names[i] = replacer.Replace(names[i])
+		}
+	}
+	for i := range fileContext.Functions {
+		function := &fileContext.Functions[i]
+		function.Name = replacer.Replace(function.Name)
+		function.Parent = replacer.Replace(function.Parent)
+		rename(function.Calls)
+		rename(function.CalledBy)
+		rename(function.LocalCalls)
+		rename(function.LocalCallers)
+		for j := range function.LocalCallsWithMetadata {
+			function.LocalCallsWithMetadata[j].FunctionName = replacer.Replace(function.LocalCallsWithMetadata[j].FunctionName)
+		}
+	}
+	for i := range fileContext.Types {
+		for j := range fileContext.Types[i].Methods {
+			rename(fileContext.Types[i].Methods[j].Calls)
+		}
+	}
+}
+
+// parseJob is a file found while walking the repository and the parser handling it
+type parseJob struct {
+	path   string
+	parser ast.LanguageParser
+}
+
+// parseOutcome is the result of parsing one file: its context, why it failed or was
+// skipped, or an error aborting the build
+type parseOutcome struct {
+	fileContext *models.FileContext
+	failure     *FileError
+	skipReason  string
+	err         error
+}
+
+// collectParseJobs walks the repository and returns the files a parser supports, in walk order
+func (ib *IndexBuilder) collectParseJobs() ([]parseJob, error) {
+	var jobs []parseJob
 	err := filepath.Walk(ib.rootPath, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
@@ -162,61 +585,244 @@ func (ib *IndexBuilder) BuildIndex() (*IndexStatistics, error) {
 			return nil
 		}
 
-		// Get file extension
-		ext := strings.ToLower(filepath.Ext(cleanPath))
-
-		// Find appropriate parser using registry
-		parser, exists := ib.parserRegistry.GetParser(ext)
-		if !exists {
-			// Skip unsupported file types
-			return nil
-		}
-
-		// Read file content
-		content, err := os.ReadFile(cleanPath) // #nosec G304 - Path validated above
-		if err != nil {
-			return fmt.Errorf("failed to read file %s: %w", cleanPath, err)
+		// Find appropriate parser using registry, skipping unsupported file types
+		parser, exists := ib.parserRegistry.GetParser(strings.ToLower(filepath.Ext(cleanPath)))
+		if exists {
+			jobs = append(jobs, parseJob{path: cleanPath, parser: parser})
 		}
+		return nil
+	})
+	return jobs, err
+}
 
-		// Parse the file using the registry parser
-		fileContext, err := parser.ParseFile(cleanPath, content)
-		if err != nil {
-			return fmt.Errorf("failed to parse file %s: %w", cleanPath, err)
-		}
+// concurrency returns the number of files to parse at once
+func (ib *IndexBuilder) concurrency() int {
+	if ib.options.Concurrency > 0 {
+		return ib.options.Concurrency
+	}
+	return runtime.GOMAXPROCS(0)
+}
 
-		// Add to collection for global analysis
-		fileContexts = append(fileContexts, *fileContext)
+// parseConcurrently parses jobs on a pool of workers. Each outcome is stored at its job's
+// index, so the caller sees results in walk order whatever order the workers finish in.
+func (ib *IndexBuilder) parseConcurrently(jobs []parseJob) []parseOutcome {
+	outcomes := make([]parseOutcome, len(jobs))
+	next := make(chan int)
+
+	var wg sync.WaitGroup
+	for range min(ib.concurrency(), len(jobs)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				outcomes[i] = ib.runParseJob(jobs[i])
+			}
+		}()
+	}
+	for i := range jobs {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
 
-		return nil
-	})
+	return outcomes
+}
 
+// runParseJob reads and parses one file without touching shared builder state
+func (ib *IndexBuilder) runParseJob(job parseJob) parseOutcome {
+	// Failures are recorded per file so one broken file does not leave the repository unindexed
+	content, skipReason, err := ib.readSource(job.path)
 	if err != nil {
-		return nil, fmt.Errorf("failed to process directory: %w", err)
+		failure := newFileError(job.path, &models.ParseError{
+			File: job.path, Kind: models.ParseErrorIO, Message: err.Error(), Err: err,
+		})
+		return parseOutcome{failure: &failure}
+	}
+	if skipReason != "" {
+		return parseOutcome{skipReason: skipReason}
 	}
 
-	// Phase 2: Global enrichment - enhance file contexts with cross-file analysis
-	enrichment := NewGlobalEnrichment()
-	enrichedContexts, err := enrichment.EnrichFileContexts(fileContexts)
+	// Parse the file using the registry parser
+	fileContext, err := job.parser.ParseFile(job.path, content)
 	if err != nil {
-		return nil, fmt.Errorf("failed to enrich file contexts: %w", err)
+		failure := newFileError(job.path, err)
+		return parseOutcome{failure: &failure}
+	}
+	if !ib.matchesBuildTags(fileContext) {
+		return parseOutcome{skipReason: SkipReasonBuildConstraints}
+	}
+	if err := ib.attachSource(fileContext, content); err != nil {
+		return parseOutcome{err: err}
 	}
+	if ib.options.GitBlame {
+		annotateLastModified(fileContext)
+	}
+	if ib.options.NormalizeTypes {
+		annotateNormalizedTypes(fileContext)
+	}
+	annotateEntryPoints(fileContext, ib.options.EntryPointPatterns)
+	annotateRecursion(fileContext)
+	annotateContentHashes(fileContext, content)
+	ib.relativizePaths(fileContext)
+	return parseOutcome{fileContext: fileContext}
+}
 
-	// Phase 3: Store enriched contexts
-	for i := range enrichedContexts {
-		if err := ib.storage.StoreFileContext(&enrichedContexts[i]); err != nil {
-			return nil, fmt.Errorf("failed to store file context: %w", err)
+// collectParseOutcomes records failed and skipped files in the statistics in walk order,
+// keeping them deterministic, and returns the parsed file contexts
+func (ib *IndexBuilder) collectParseOutcomes(jobs []parseJob, outcomes []parseOutcome) ([]models.FileContext, error) {
+	var fileContexts []models.FileContext
+	for i := range outcomes {
+		outcome := &outcomes[i]
+		switch {
+		case outcome.err != nil:
+			return nil, outcome.err
+		case outcome.failure != nil:
+			ib.stats.FailedFiles = append(ib.stats.FailedFiles, *outcome.failure)
+		case outcome.skipReason != "":
+			ib.skipFile(jobs[i].path, outcome.skipReason)
+		default:
+			fileContexts = append(fileContexts, *outcome.fileContext)
 		}
+	}
+	return fileContexts, nil
+}
 
-		// Update statistics
-		ib.updateStatistics(&enrichedContexts[i])
+// buildChangedSince reindexes the files changed relative to ref one at a time, patching
+// the call graph as ReindexFile does, and removes deleted files from the index
+func (ib *IndexBuilder) buildChangedSince(ref string) (*IndexStatistics, error) {
+	if ib.storage == nil {
+		return nil, fmt.Errorf("index builder not initialized")
+	}
+	ib.stats.StartTime = time.Now()
+
+	// Paths of older indexes would not match the repo-relative paths of the changed files
+	if ib.storage.PathFormat() != models.PathFormatRepoRelative {
+		return nil, fmt.Errorf("the index predates repo-relative file paths; run a full build to migrate it")
+	}
+
+	files, err := ChangedFiles(ib.rootPath, ref)
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range files {
+		if _, err := os.Stat(file); errors.Is(err, fs.ErrNotExist) {
+			if err := ib.removeDeletedFile(ib.repoRelative(file)); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		// Failures are recorded per file as in a full build
+		if err := ib.ReindexFile(file); err != nil {
+			ib.stats.FailedFiles = append(ib.stats.FailedFiles, newFileError(file, err))
+		}
 	}
 
 	ib.stats.EndTime = time.Now()
 	ib.stats.Duration = ib.stats.EndTime.Sub(ib.stats.StartTime)
 
+	if err := ib.storage.RecordBuild(ib.buildRecord()); err != nil {
+		return nil, fmt.Errorf("failed to record build: %w", err)
+	}
+
 	return &ib.stats, nil
 }
 
+// removeDeletedFile drops a file deleted from the repository from the index, and the
+// methods it declared from the types of its package linked to them
+func (ib *IndexBuilder) removeDeletedFile(path string) error {
+	oldContext, err := ib.storedFileContext(path)
+	if err != nil || oldContext == nil {
+		return err
+	}
+	if err := ib.storage.DeleteFile(path); err != nil {
+		return fmt.Errorf("failed to remove deleted file %s: %w", path, err)
+	}
+	return ib.relinkPackage(filepath.Dir(path), oldContext.Package)
+}
+
+// buildRecord summarizes the statistics of the last build for the manifest build history
+func (ib *IndexBuilder) buildRecord() *models.BuildRecord {
+	return &models.BuildRecord{
+		StartedAt:      ib.stats.StartTime,
+		Duration:       ib.stats.Duration,
+		FilesProcessed: ib.stats.FilesProcessed,
+		FilesFailed:    len(ib.stats.FailedFiles),
+		FilesSkipped:   len(ib.stats.SkippedFiles),
+		Languages:      maps.Clone(ib.stats.Languages),
+		ToolVersion:    ib.options.ToolVersion,
+	}
+}
+
+// attachSource keeps the file content in the file context when StoreSource is enabled
+func (ib *IndexBuilder) attachSource(fileContext *models.FileContext, content []byte) error {
+	if !ib.options.StoreSource {
+		return nil
+	}
+
+	source, err := compressSource(content)
+	if err != nil {
+		return fmt.Errorf("failed to store source of %s: %w", fileContext.Path, err)
+	}
+	fileContext.Source = source
+	return nil
+}
+
+// linkMethodReceivers attaches methods to their receiver's TypeDef when the method is
+// declared in a different file of the same package. Parsers only see one file at a time,
+// so they can only attach methods declared next to the type.
+func linkMethodReceivers(fileContexts []models.FileContext) {
+	type packageKey struct {
+		dir  string
+		name string
+	}
+
+	typesByPackage := make(map[packageKey]map[string]*models.TypeDef)
+	typeFiles := make(map[*models.TypeDef]string)
+	for i := range fileContexts {
+		fileContext := &fileContexts[i]
+		key := packageKey{dir: filepath.Dir(fileContext.Path), name: fileContext.Package}
+		for j := range fileContext.Types {
+			if typesByPackage[key] == nil {
+				typesByPackage[key] = make(map[string]*models.TypeDef)
+			}
+			typesByPackage[key][fileContext.Types[j].Name] = &fileContext.Types[j]
+			typeFiles[&fileContext.Types[j]] = fileContext.Path
+		}
+	}
+
+	for i := range fileContexts {
+		fileContext := &fileContexts[i]
+		key := packageKey{dir: filepath.Dir(fileContext.Path), name: fileContext.Package}
+		for j := range fileContext.Functions {
+			fn := &fileContext.Functions[j]
+			if fn.Receiver == "" {
+				continue
+			}
+
+			typeDef, exists := typesByPackage[key][fn.Receiver]
+			if !exists || typeFiles[typeDef] == fileContext.Path {
+				continue
+			}
+			// C++ classes declare their methods in the class body, usually in a header
+			if slices.ContainsFunc(typeDef.Methods, func(method models.Method) bool { return method.Name == fn.Name }) {
+				continue
+			}
+
+			typeDef.Methods = append(typeDef.Methods, models.Method{
+				Name:       fn.Name,
+				Signature:  fn.Signature,
+				Parameters: fn.Parameters,
+				Returns:    fn.Returns,
+				StartLine:  fn.StartLine,
+				EndLine:    fn.EndLine,
+				Doc:        fn.Doc,
+				Receiver:   fn.Receiver,
+				File:       fileContext.Path,
+			})
+		}
+	}
+}
+
 // GetStatistics returns current indexing statistics
 func (ib *IndexBuilder) GetStatistics() IndexStatistics {
 	return ib.stats
@@ -225,6 +831,12 @@ func (ib *IndexBuilder) GetStatistics() IndexStatistics {
 // updateStatistics updates the internal statistics based on processed file
 func (ib *IndexBuilder) updateStatistics(fileContext *models.FileContext) {
 	ib.stats.FilesProcessed++
+	if fileContext.Language != "" {
+		if ib.stats.Languages == nil {
+			ib.stats.Languages = make(map[string]int)
+		}
+		ib.stats.Languages[fileContext.Language]++
+	}
 	ib.stats.FunctionsIndexed += len(fileContext.Functions)
 	ib.stats.TypesIndexed += len(fileContext.Types)
 	ib.stats.VariablesIndexed += len(fileContext.Variables)
@@ -239,10 +851,16 @@ func (ib *IndexBuilder) updateStatistics(fileContext *models.FileContext) {
 // Close properly shuts down the index builder and its components
 func (ib *IndexBuilder) Close() error {
 	if ib.storage != nil {
-		if err := ib.storage.Close(); err != nil {
-			return fmt.Errorf("failed to close storage: %w", err)
-		}
+		// The lock is released even when closing fails, or it would block snapshots until exit
+		closeErr := ib.storage.Close()
 		ib.storage = nil
+		lockErr := releaseWriteLock(filepath.Join(ib.rootPath, ".repocontext"))
+		if closeErr != nil {
+			return errors.Join(fmt.Errorf("failed to close storage: %w", closeErr), lockErr)
+		}
+		if lockErr != nil {
+			return lockErr
+		}
 	}
 
 	// Clear parser registry
@@ -105,10 +105,12 @@ func main() {
 	// Test metadata for different entity types
 	storage := builder.storage
 
-	testFunctionMetadata(t, storage, testFile, expectedModTime)
-	testTypeMetadata(t, storage, testFile)
-	testVariableMetadata(t, storage, testFile)
-	testConstantMetadata(t, storage, testFile)
+	// Files are stored relative to the repository root
+	storedFile := "metadata_test.go"
+	testFunctionMetadata(t, storage, storedFile, expectedModTime)
+	testTypeMetadata(t, storage, storedFile)
+	testVariableMetadata(t, storage, storedFile)
+	testConstantMetadata(t, storage, storedFile)
 	testSignatureMetadata(t, storage)
 }
 
@@ -381,7 +383,7 @@ var Variable2 = "value2"
 			t.Fatalf("Failed to get file info for %s: %v", filename, err)
 		}
 
-		expectedFiles[filePath] = struct {
+		expectedFiles[filename] = struct {
 			checksum string
 			modTime  time.Time
 		}{
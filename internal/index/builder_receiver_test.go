@@ -0,0 +1,102 @@
+package index
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestIndexBuilder_LinksMethodsAcrossFiles(t *testing.T) {
+	tempDir := t.TempDir()
+
+	files := map[string]string{
+		"types.go": `package accounts
+
+type User struct {
+	Name string
+}
+
+type UserGroup struct {
+	Users []User
+}
+
+// Activate enables the user
+func (u *User) Activate() error {
+	return nil
+}
+`,
+		"group.go": `package accounts
+
+// Activate enables every user in the group
+func (g *UserGroup) Activate() error {
+	return nil
+}
+
+// ActivateUser enables a single user
+func ActivateUser(u *User) error {
+	return u.Activate()
+}
+`,
+		"other/user.go": `package other
+
+type User struct{}
+`,
+		"other/extra.go": `package other
+
+func (u User) Deactivate() {}
+`,
+	}
+	for name, content := range files {
+		path := filepath.Join(tempDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	builder := NewIndexBuilder(tempDir)
+	if err := builder.Initialize(); err != nil {
+		t.Fatalf("Failed to initialize index builder: %v", err)
+	}
+	defer builder.Close()
+
+	if _, err := builder.BuildIndex(); err != nil {
+		t.Fatalf("Failed to build index: %v", err)
+	}
+
+	fileContext, err := builder.storage.GetFileContext("types.go")
+	if err != nil {
+		t.Fatalf("Failed to load types.go: %v", err)
+	}
+
+	methods := make(map[string][]string)
+	for _, typeDef := range fileContext.Types {
+		for _, method := range typeDef.Methods {
+			methods[typeDef.Name] = append(methods[typeDef.Name], method.Signature)
+		}
+	}
+
+	if len(methods["User"]) != 1 || methods["User"][0] != "func (u *User) Activate() error" {
+		t.Errorf("Expected User to have only its own Activate method, got %v", methods["User"])
+	}
+	if len(methods["UserGroup"]) != 1 || methods["UserGroup"][0] != "func (g *UserGroup) Activate() error" {
+		t.Errorf("Expected UserGroup to have only its own Activate method, got %v", methods["UserGroup"])
+	}
+
+	for _, typeDef := range fileContext.Types {
+		if typeDef.Name == "UserGroup" && typeDef.Methods[0].File != "group.go" {
+			t.Errorf("Expected linked method to record its file, got %q", typeDef.Methods[0].File)
+		}
+	}
+
+	otherContext, err := builder.storage.GetFileContext(filepath.Join("other", "user.go"))
+	if err != nil {
+		t.Fatalf("Failed to load other/user.go: %v", err)
+	}
+	otherMethods := otherContext.Types[0].Methods
+	if len(otherMethods) != 1 || otherMethods[0].Name != "Deactivate" {
+		t.Errorf("Expected other.User to only have Deactivate, got %v", otherMethods)
+	}
+}
@@ -82,10 +82,13 @@ func TestIndexBuilder_MultipleParserSupport(t *testing.T) {
 		t.Errorf("Expected Python parser language name 'python', got '%s'", pythonParser.GetLanguageName())
 	}
 
-	// TypeScript is still not supported
-	_, exists = builder.parserRegistry.GetParser(".ts")
-	if exists {
-		t.Error("Expected TypeScript parser to not be registered")
+	// Verify TypeScript parser is registered (it indexes type declarations)
+	typescriptParser, exists := builder.parserRegistry.GetParser(".ts")
+	if !exists {
+		t.Fatal("Expected TypeScript parser to be registered")
+	}
+	if typescriptParser.GetLanguageName() != "typescript" {
+		t.Errorf("Expected TypeScript parser language name 'typescript', got '%s'", typescriptParser.GetLanguageName())
 	}
 
 	// Verify we can still get the Go parser
@@ -166,9 +169,8 @@ func TestIndexBuilder_UnsupportedFileTypes(t *testing.T) {
 	}
 	defer builder.Close()
 
-	// Create unsupported file types (removed test.py since Python is now supported)
+	// Create unsupported file types (removed test.py and test.ts since Python and TypeScript are now supported)
 	unsupportedFiles := []string{
-		"test.ts",   // TypeScript
 		"test.js",   // JavaScript
 		"test.txt",  // Text
 		"README.md", // Markdown
@@ -1,9 +1,14 @@
 package index
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
+
+	"repository-context-protocol/internal/models"
 )
 
 func TestIndexBuilder_Initialize(t *testing.T) {
@@ -288,6 +293,645 @@ func (u *User) String() string {
 	}
 }
 
+func TestIndexBuilder_BuildIndexContinuesPastFailedFiles(t *testing.T) {
+	projectDir := t.TempDir()
+
+	validContent := "package main\n\nfunc main() {}\n"
+	if err := os.WriteFile(filepath.Join(projectDir, "main.go"), []byte(validContent), 0600); err != nil {
+		t.Fatalf("Failed to create main.go: %v", err)
+	}
+	brokenContent := "package main\n\nfunc broken( {\n}\n"
+	if err := os.WriteFile(filepath.Join(projectDir, "broken.go"), []byte(brokenContent), 0600); err != nil {
+		t.Fatalf("Failed to create broken.go: %v", err)
+	}
+
+	builder := NewIndexBuilder(projectDir)
+	if err := builder.Initialize(); err != nil {
+		t.Fatalf("Failed to initialize index builder: %v", err)
+	}
+	defer builder.Close()
+
+	stats, err := builder.BuildIndex()
+	if err != nil {
+		t.Fatalf("Expected syntax errors not to fail the build, got %v", err)
+	}
+
+	if stats.FilesProcessed != 1 {
+		t.Errorf("Expected the valid file to be indexed, got %d files processed", stats.FilesProcessed)
+	}
+	if len(stats.FailedFiles) != 1 {
+		t.Fatalf("Expected 1 failed file, got %v", stats.FailedFiles)
+	}
+	failed := stats.FailedFiles[0]
+	if filepath.Base(failed.Path) != "broken.go" || failed.Line != 3 || failed.Kind != "syntax" {
+		t.Errorf("Expected syntax error at broken.go:3, got %+v", failed)
+	}
+	if !strings.Contains(failed.Error(), "broken.go:3:") {
+		t.Errorf("Expected failure to render its location, got %q", failed.Error())
+	}
+
+	results, err := builder.storage.QueryByName("main")
+	if err != nil || len(results) != 1 {
+		t.Errorf("Expected main to be indexed despite the broken file, got %d results (err: %v)", len(results), err)
+	}
+}
+
+func TestIndexBuilder_ConcurrentBuildIsDeterministic(t *testing.T) {
+	projectDir := t.TempDir()
+	for i := range 12 {
+		content := fmt.Sprintf("package main\n\nfunc Handler%d() {}\n", i)
+		if i%4 == 0 {
+			content = "package main\n\nfunc broken( {\n}\n"
+		}
+		name := filepath.Join(projectDir, fmt.Sprintf("file%02d.go", i))
+		if err := os.WriteFile(name, []byte(content), 0600); err != nil {
+			t.Fatalf("Failed to create %s: %v", name, err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(projectDir, "blob.go"), []byte("package main\x00"), 0600); err != nil {
+		t.Fatalf("Failed to create blob.go: %v", err)
+	}
+
+	builder := NewIndexBuilderWithOptions(projectDir, IndexBuilderOptions{Concurrency: 8})
+	if err := builder.Initialize(); err != nil {
+		t.Fatalf("Failed to initialize index builder: %v", err)
+	}
+	defer builder.Close()
+
+	stats, err := builder.BuildIndex()
+	if err != nil {
+		t.Fatalf("Failed to build index: %v", err)
+	}
+	if stats.FilesProcessed != 9 || stats.FunctionsIndexed != 9 {
+		t.Errorf("Expected 9 files and functions indexed, got %d files and %d functions", stats.FilesProcessed, stats.FunctionsIndexed)
+	}
+
+	// Failures are reported in walk order however the workers were scheduled
+	var failed []string
+	for _, failure := range stats.FailedFiles {
+		failed = append(failed, filepath.Base(failure.Path))
+	}
+	if !slices.Equal(failed, []string{"file00.go", "file04.go", "file08.go"}) {
+		t.Errorf("Expected the broken files to fail in walk order, got %v", failed)
+	}
+	if len(stats.SkippedFiles) != 1 || filepath.Base(stats.SkippedFiles[0].Path) != "blob.go" {
+		t.Errorf("Expected blob.go to be skipped, got %v", stats.SkippedFiles)
+	}
+
+	files, err := builder.storage.ListFiles()
+	if err != nil || len(files) != 9 {
+		t.Errorf("Expected 9 files stored, got %v (err: %v)", files, err)
+	}
+}
+
+func TestIndexBuilder_SkipsOversizedAndBinaryFiles(t *testing.T) {
+	projectDir := t.TempDir()
+	files := map[string]string{
+		"main.go":      "package main\n\nfunc main() {}\n",
+		"generated.go": "package main\n\nvar table = `" + strings.Repeat("x", 200) + "`\n",
+		"blob.go":      "package main\x00\x01\x02",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(projectDir, name), []byte(content), 0600); err != nil {
+			t.Fatalf("Failed to create %s: %v", name, err)
+		}
+	}
+
+	builder := NewIndexBuilderWithOptions(projectDir, IndexBuilderOptions{MaxFileSize: 100})
+	if err := builder.Initialize(); err != nil {
+		t.Fatalf("Failed to initialize index builder: %v", err)
+	}
+	defer builder.Close()
+
+	stats, err := builder.BuildIndex()
+	if err != nil {
+		t.Fatalf("Failed to build index: %v", err)
+	}
+	if stats.FilesProcessed != 1 || len(stats.FailedFiles) != 0 {
+		t.Errorf("Expected only main.go processed and no failures, got %d processed and %v failed",
+			stats.FilesProcessed, stats.FailedFiles)
+	}
+
+	reasons := make(map[string]string)
+	for _, skipped := range stats.SkippedFiles {
+		reasons[filepath.Base(skipped.Path)] = skipped.Reason
+	}
+	if len(reasons) != 2 || reasons["blob.go"] != SkipReasonBinary || !strings.Contains(reasons["generated.go"], "exceeds the 100 byte limit") {
+		t.Errorf("Expected the binary and oversized files skipped with reasons, got %v", stats.SkippedFiles)
+	}
+
+	// A single oversized file processed directly is skipped the same way
+	if err := builder.ProcessFile(filepath.Join(projectDir, "generated.go")); err != nil {
+		t.Errorf("Expected an oversized file to be skipped without error, got %v", err)
+	}
+	if results, _ := builder.storage.QueryByName("table"); len(results) != 0 {
+		t.Errorf("Expected the oversized file not to be indexed, got %d results", len(results))
+	}
+}
+
+func TestIndexBuilder_BuildChangedSince(t *testing.T) {
+	repoDir := newGitTestRepo(t, map[string]string{
+		"main.go":   "package main\n\nfunc main() { helper() }\n",
+		"helper.go": "package main\n\nfunc helper() {}\n",
+		"old.go":    "package main\n\nfunc obsolete() {}\n\nfunc (c Config) Reset() {}\n",
+		"config.go": "package main\n\ntype Config struct{}\n",
+	})
+
+	builder := NewIndexBuilder(repoDir)
+	if err := builder.Initialize(); err != nil {
+		t.Fatalf("Failed to initialize index builder: %v", err)
+	}
+	if _, err := builder.BuildIndex(); err != nil {
+		t.Fatalf("Failed to build index: %v", err)
+	}
+	builder.Close()
+
+	writeGitTestFile(t, repoDir, "helper.go", "package main\n\nfunc helper() {}\n\nfunc added() {}\n")
+	if err := os.Remove(filepath.Join(repoDir, "old.go")); err != nil {
+		t.Fatalf("Failed to remove old.go: %v", err)
+	}
+
+	builder = NewIndexBuilderWithOptions(repoDir, IndexBuilderOptions{ChangedSince: "HEAD"})
+	if err := builder.Initialize(); err != nil {
+		t.Fatalf("Failed to initialize index builder: %v", err)
+	}
+	defer builder.Close()
+
+	stats, err := builder.BuildIndex()
+	if err != nil {
+		t.Fatalf("Failed to update index: %v", err)
+	}
+	if stats.FilesProcessed != 1 {
+		t.Errorf("Expected only helper.go reindexed, got %d files processed", stats.FilesProcessed)
+	}
+	for name, want := range map[string]int{"added": 1, "obsolete": 0, "main": 1} {
+		if results, _ := builder.storage.QueryByName(name); len(results) != want {
+			t.Errorf("Expected %d entries for %s, got %d", want, name, len(results))
+		}
+	}
+	if callers, _ := builder.storage.QueryCallsTo("helper"); len(callers) != 1 {
+		t.Errorf("Expected the unchanged caller of helper to be kept, got %v", callers)
+	}
+	if config, err := builder.storage.GetFileContext("config.go"); err != nil || len(config.Types) != 1 || len(config.Types[0].Methods) != 0 {
+		t.Errorf("Expected the method of the deleted old.go to be unlinked from Config, got %+v (err: %v)", config, err)
+	}
+
+	// Outside a git checkout the build fails with a clear error
+	plainDir := t.TempDir()
+	builder = NewIndexBuilderWithOptions(plainDir, IndexBuilderOptions{ChangedSince: "HEAD"})
+	if err := builder.Initialize(); err != nil {
+		t.Fatalf("Failed to initialize index builder: %v", err)
+	}
+	defer builder.Close()
+	if _, err := builder.BuildIndex(); err == nil || !strings.Contains(err.Error(), "not a git checkout") {
+		t.Errorf("Expected a not a git checkout error, got %v", err)
+	}
+}
+
+func TestIndexBuilder_BuildIndexCppMethodsAcrossFiles(t *testing.T) {
+	projectDir := t.TempDir()
+
+	header := "class Shape {\npublic:\n    double area() const;\n};\n"
+	if err := os.WriteFile(filepath.Join(projectDir, "shape.hpp"), []byte(header), 0600); err != nil {
+		t.Fatalf("Failed to create shape.hpp: %v", err)
+	}
+	source := "#include \"shape.hpp\"\n\ndouble Shape::area() const {\n    return 0;\n}\n\ndouble Shape::perimeter() const {\n    return area();\n}\n"
+	if err := os.WriteFile(filepath.Join(projectDir, "shape.cpp"), []byte(source), 0600); err != nil {
+		t.Fatalf("Failed to create shape.cpp: %v", err)
+	}
+
+	builder := NewIndexBuilder(projectDir)
+	if err := builder.Initialize(); err != nil {
+		t.Fatalf("Failed to initialize index builder: %v", err)
+	}
+	defer builder.Close()
+
+	if _, err := builder.BuildIndex(); err != nil {
+		t.Fatalf("Failed to build index: %v", err)
+	}
+
+	results, err := builder.storage.QueryByName("Shape")
+	if err != nil || len(results) != 1 {
+		t.Fatalf("Expected Shape to be indexed once, got %d results (err: %v)", len(results), err)
+	}
+	fileContext, err := builder.storage.GetFileContext(results[0].IndexEntry.File)
+	if err != nil {
+		t.Fatalf("Failed to load shape.hpp: %v", err)
+	}
+
+	var methods []string
+	for _, method := range fileContext.Types[0].Methods {
+		methods = append(methods, method.Name)
+	}
+	if strings.Join(methods, ",") != "area,perimeter" {
+		t.Errorf("Expected the declared area method and the out-of-class perimeter method, got %v", methods)
+	}
+}
+
+func TestIndexBuilder_BuildIndexTypeScriptTypes(t *testing.T) {
+	projectDir := t.TempDir()
+
+	source := `export interface User {
+    id: number;
+    name?: string;
+}
+
+export type UserID = User["id"];
+
+export class UserService {
+    find(id: UserID): User | undefined {
+        return undefined;
+    }
+}
+
+export function greet(user: User): string {
+    return "hello " + user.name;
+}
+`
+	if err := os.WriteFile(filepath.Join(projectDir, "user.ts"), []byte(source), 0600); err != nil {
+		t.Fatalf("Failed to create user.ts: %v", err)
+	}
+
+	builder := NewIndexBuilder(projectDir)
+	if err := builder.Initialize(); err != nil {
+		t.Fatalf("Failed to initialize index builder: %v", err)
+	}
+	defer builder.Close()
+
+	stats, err := builder.BuildIndex()
+	if err != nil {
+		t.Fatalf("Failed to build index: %v", err)
+	}
+	if stats.FilesProcessed != 1 || len(stats.FailedFiles) != 0 || stats.Languages["typescript"] != 1 {
+		t.Fatalf("Expected user.ts to be indexed as TypeScript, got %d files, languages %v and failures %v",
+			stats.FilesProcessed, stats.Languages, stats.FailedFiles)
+	}
+
+	fileContext, err := builder.storage.GetFileContext("user.ts")
+	if err != nil {
+		t.Fatalf("Failed to load user.ts: %v", err)
+	}
+	kinds := make(map[string]string)
+	for _, typeDef := range fileContext.Types {
+		kinds[typeDef.Name] = typeDef.Kind
+	}
+	for name, kind := range map[string]string{"User": "interface", "UserID": "alias"} {
+		if results, err := builder.storage.QueryByName(name); err != nil || len(results) != 1 {
+			t.Errorf("Expected %s to be indexed once, got %d results (err: %v)", name, len(results), err)
+		}
+		if kinds[name] != kind {
+			t.Errorf("Expected %s to be indexed as %s, got %q", name, kind, kinds[name])
+		}
+	}
+
+	// Classes and functions are skipped until the parser extracts them
+	for _, name := range []string{"UserService", "greet"} {
+		if results, err := builder.storage.QueryByName(name); err != nil || len(results) != 0 {
+			t.Errorf("Expected %s not to be indexed, got %d results (err: %v)", name, len(results), err)
+		}
+	}
+}
+
+func TestIndexBuilder_StoreSource(t *testing.T) {
+	for _, storeSource := range []bool{true, false} {
+		t.Run(fmt.Sprintf("store source %t", storeSource), func(t *testing.T) {
+			projectDir := t.TempDir()
+			mainPath := filepath.Join(projectDir, "main.go")
+
+			original := "package main\n\nfunc Greet() string {\n\treturn \"hello\"\n}\n"
+			if err := os.WriteFile(mainPath, []byte(original), 0600); err != nil {
+				t.Fatalf("Failed to create main.go: %v", err)
+			}
+
+			builder := NewIndexBuilderWithOptions(projectDir, IndexBuilderOptions{StoreSource: storeSource})
+			if err := builder.Initialize(); err != nil {
+				t.Fatalf("Failed to initialize index builder: %v", err)
+			}
+			defer builder.Close()
+
+			if _, err := builder.BuildIndex(); err != nil {
+				t.Fatalf("Failed to build index: %v", err)
+			}
+
+			impl, err := builder.storage.GetFunctionImplementation("Greet", 0)
+			if err != nil {
+				t.Fatalf("Failed to get implementation: %v", err)
+			}
+			if impl.Source != SourceWorkingTree || impl.Stale {
+				t.Errorf("Expected unchanged file to be read from the working tree, got source %q (stale: %t)", impl.Source, impl.Stale)
+			}
+
+			// The working tree moves on after indexing
+			changed := "package main\n\n// Greet was rewritten\nfunc Greet() string {\n\treturn \"hi\"\n}\n"
+			if err := os.WriteFile(mainPath, []byte(changed), 0600); err != nil {
+				t.Fatalf("Failed to update main.go: %v", err)
+			}
+
+			impl, err = builder.storage.GetFunctionImplementation("Greet", 0)
+			if err != nil {
+				t.Fatalf("Failed to get implementation: %v", err)
+			}
+			if storeSource {
+				expected := "func Greet() string {\n\treturn \"hello\"\n}"
+				if impl.Body != expected || impl.Source != SourceIndex || impl.Stale {
+					t.Errorf("Expected indexed body %q from the index, got %q (source %q, stale: %t)",
+						expected, impl.Body, impl.Source, impl.Stale)
+				}
+			} else if !impl.Stale {
+				t.Errorf("Expected changed file without stored source to be reported stale, got %+v", impl)
+			}
+		})
+	}
+}
+
+func TestIndexBuilder_RepoRelativePaths(t *testing.T) {
+	projectDir := t.TempDir()
+	for _, dir := range []string{"a", "b"} {
+		if err := os.MkdirAll(filepath.Join(projectDir, dir), 0755); err != nil {
+			t.Fatalf("Failed to create %s: %v", dir, err)
+		}
+		source := fmt.Sprintf("package %s\n\nfunc From%s() {}\n", dir, strings.ToUpper(dir))
+		if err := os.WriteFile(filepath.Join(projectDir, dir, "util.go"), []byte(source), 0600); err != nil {
+			t.Fatalf("Failed to create %s/util.go: %v", dir, err)
+		}
+	}
+
+	builder := NewIndexBuilder(projectDir)
+	if err := builder.Initialize(); err != nil {
+		t.Fatalf("Failed to initialize index builder: %v", err)
+	}
+	defer builder.Close()
+	if _, err := builder.BuildIndex(); err != nil {
+		t.Fatalf("Failed to build index: %v", err)
+	}
+
+	files, err := builder.storage.ListFiles()
+	if err != nil || !slices.Equal(files, []string{filepath.Join("a", "util.go"), filepath.Join("b", "util.go")}) {
+		t.Fatalf("Expected repo-relative paths, got %v (err: %v)", files, err)
+	}
+	if format := builder.storage.PathFormat(); format != models.PathFormatRepoRelative {
+		t.Errorf("Expected the path format to be recorded, got %q", format)
+	}
+
+	// Files sharing a base name in different directories are told apart
+	engine := NewQueryEngine(builder.storage)
+	result, err := engine.SearchInFile(RepoRelativePath(projectDir, filepath.Join(projectDir, "a", "util.go")))
+	if err != nil {
+		t.Fatalf("Failed to search in file: %v", err)
+	}
+	if len(result.Entries) != 1 || result.Entries[0].IndexEntry.Name != "FromA" {
+		t.Errorf("Expected only FromA in a/util.go, got %+v", result.Entries)
+	}
+}
+
+func TestIndexBuilder_MigratesLegacyPaths(t *testing.T) {
+	projectDir := t.TempDir()
+	mainPath := filepath.Join(projectDir, "main.go")
+	if err := os.WriteFile(mainPath, []byte("package main\n\nfunc main() {}\n"), 0600); err != nil {
+		t.Fatalf("Failed to create main.go: %v", err)
+	}
+
+	// An index built before paths were stored repo-relative
+	legacy := NewHybridStorage(filepath.Join(projectDir, ".repocontext"))
+	if err := legacy.Initialize(); err != nil {
+		t.Fatalf("Failed to initialize storage: %v", err)
+	}
+	if err := legacy.StoreFileContext(&models.FileContext{
+		Path: mainPath, Language: "go", Functions: []models.Function{{Name: "main", StartLine: 3}},
+	}); err != nil {
+		t.Fatalf("Failed to store file context: %v", err)
+	}
+	legacy.Close()
+
+	builder := NewIndexBuilderWithOptions(projectDir, IndexBuilderOptions{ChangedSince: "HEAD"})
+	if err := builder.Initialize(); err != nil {
+		t.Fatalf("Failed to initialize index builder: %v", err)
+	}
+	defer builder.Close()
+	if _, err := builder.BuildIndex(); err == nil || !strings.Contains(err.Error(), "full build") {
+		t.Errorf("Expected an incremental build of a legacy index to ask for a full build, got %v", err)
+	}
+
+	builder.options.ChangedSince = ""
+	if _, err := builder.BuildIndex(); err != nil {
+		t.Fatalf("Failed to build index: %v", err)
+	}
+	files, err := builder.storage.ListFiles()
+	if err != nil || !slices.Equal(files, []string{"main.go"}) {
+		t.Errorf("Expected the legacy absolute path to be replaced, got %v (err: %v)", files, err)
+	}
+	if format := builder.storage.PathFormat(); format != models.PathFormatRepoRelative {
+		t.Errorf("Expected the migrated path format to be recorded, got %q", format)
+	}
+}
+
+func TestIndexBuilder_NormalizeTypes(t *testing.T) {
+	for _, normalizeTypes := range []bool{true, false} {
+		t.Run(fmt.Sprintf("normalize types %t", normalizeTypes), func(t *testing.T) {
+			projectDir := t.TempDir()
+			source := "package main\n\ntype Team struct {\n\tMembers map[string]*User\n}\n\n" +
+				"type User struct{}\n\nfunc Names(users []*User) ([]string, error) {\n\treturn nil, nil\n}\n"
+			if err := os.WriteFile(filepath.Join(projectDir, "main.go"), []byte(source), 0600); err != nil {
+				t.Fatalf("Failed to create main.go: %v", err)
+			}
+
+			builder := NewIndexBuilderWithOptions(projectDir, IndexBuilderOptions{NormalizeTypes: normalizeTypes})
+			if err := builder.Initialize(); err != nil {
+				t.Fatalf("Failed to initialize index builder: %v", err)
+			}
+			defer builder.Close()
+
+			if _, err := builder.BuildIndex(); err != nil {
+				t.Fatalf("Failed to build index: %v", err)
+			}
+			results, err := builder.storage.QueryByName("Names")
+			if err != nil || len(results) != 1 {
+				t.Fatalf("Expected Names to be indexed once, got %d results (err: %v)", len(results), err)
+			}
+			fileContext, err := builder.storage.GetFileContext(results[0].IndexEntry.File)
+			if err != nil {
+				t.Fatalf("Failed to load main.go: %v", err)
+			}
+
+			var normalized []string
+			for _, function := range fileContext.Functions {
+				for _, parameter := range function.Parameters {
+					normalized = append(normalized, parameter.Type+"="+parameter.NormalizedType)
+				}
+				for _, result := range function.Returns {
+					normalized = append(normalized, result.Name+"="+result.NormalizedType)
+				}
+			}
+			for _, typeDef := range fileContext.Types {
+				for _, field := range typeDef.Fields {
+					normalized = append(normalized, field.Type+"="+field.NormalizedType)
+				}
+			}
+
+			expected := "[]*User=,[]string=,error=,map[string]*User="
+			if normalizeTypes {
+				expected = "[]*User=list<User>,[]string=list<string>,error=error,map[string]*User=map<string,User>"
+			}
+			if got := strings.Join(normalized, ","); got != expected {
+				t.Errorf("Expected types %q, got %q", expected, got)
+			}
+		})
+	}
+}
+
+func TestIndexBuilder_IndexAnonymous(t *testing.T) {
+	projectDir := t.TempDir()
+	source := "package handlers\n\nfunc Serve() {\n\tgo func() {\n\t\thandle()\n\t}()\n}\n\nfunc handle() {}\n"
+	if err := os.MkdirAll(filepath.Join(projectDir, "handlers"), 0750); err != nil {
+		t.Fatalf("Failed to create handlers: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(projectDir, "handlers", "serve.go"), []byte(source), 0600); err != nil {
+		t.Fatalf("Failed to create serve.go: %v", err)
+	}
+
+	builder := NewIndexBuilderWithOptions(projectDir, IndexBuilderOptions{IndexAnonymous: true})
+	if err := builder.Initialize(); err != nil {
+		t.Fatalf("Failed to initialize index builder: %v", err)
+	}
+	defer builder.Close()
+
+	// The name depends on the repo-relative path only, so it survives rebuilds
+	literal := "func@" + filepath.Join("handlers", "serve.go") + ":4"
+	for build := 0; build < 2; build++ {
+		if _, err := builder.BuildIndex(); err != nil {
+			t.Fatalf("Failed to build index: %v", err)
+		}
+		results, err := builder.storage.QueryByName(literal)
+		if err != nil || len(results) != 1 {
+			t.Fatalf("Expected %s to be indexed once, got %d results (err: %v)", literal, len(results), err)
+		}
+	}
+
+	fileContext, err := builder.storage.GetFileContext(filepath.Join("handlers", "serve.go"))
+	if err != nil {
+		t.Fatalf("Failed to load serve.go: %v", err)
+	}
+	for _, function := range fileContext.Functions {
+		switch function.Name {
+		case "Serve":
+			if !slices.Equal(function.LocalCalls, []string{literal}) {
+				t.Errorf("Expected Serve to call only %s, got %v", literal, function.LocalCalls)
+			}
+		case literal:
+			if function.Parent != "Serve" || !slices.Equal(function.LocalCalls, []string{"handle"}) {
+				t.Errorf("Expected %s to be nested in Serve and call handle, got parent %q and calls %v",
+					literal, function.Parent, function.LocalCalls)
+			}
+		}
+	}
+}
+
+func TestIndexBuilder_CompressChunks(t *testing.T) {
+	projectDir := t.TempDir()
+	var source strings.Builder
+	source.WriteString("package service\n")
+	for i := 0; i < 30; i++ {
+		fmt.Fprintf(&source, "\n// Handle%d handles a request\nfunc Handle%d(name string) error {\n\treturn validate(name)\n}\n", i, i)
+	}
+	if err := os.WriteFile(filepath.Join(projectDir, "service.go"), []byte(source.String()), 0600); err != nil {
+		t.Fatalf("Failed to create service.go: %v", err)
+	}
+
+	builder := NewIndexBuilderWithOptions(projectDir, IndexBuilderOptions{CompressChunks: true})
+	if err := builder.Initialize(); err != nil {
+		t.Fatalf("Failed to initialize index builder: %v", err)
+	}
+	defer builder.Close()
+	if _, err := builder.BuildIndex(); err != nil {
+		t.Fatalf("Failed to build index: %v", err)
+	}
+
+	results, err := builder.storage.QueryByName("Handle29")
+	if err != nil || len(results) != 1 || results[0].ChunkData == nil {
+		t.Fatalf("Expected Handle29 to load from its compressed chunk, got %d results (err: %v)", len(results), err)
+	}
+	stored, logical, err := builder.storage.ChunkStorageSize()
+	if err != nil {
+		t.Fatalf("Failed to size chunks: %v", err)
+	}
+	if stored <= 0 || stored >= logical {
+		t.Errorf("Expected compressed chunks smaller than their %d logical bytes, got %d", logical, stored)
+	}
+}
+
+func TestIndexBuilder_BuildTags(t *testing.T) {
+	files := map[string]string{
+		"common.go":      "package main\n\nfunc Common() {}\n",
+		"integration.go": "//go:build integration\n\npackage main\n\nfunc Integration() {}\n",
+		"windows.go":     "//go:build windows\n\npackage main\n\nfunc Windows() {}\n",
+		"linux.go":       "//go:build linux\n\npackage main\n\nfunc Linux() {}\n",
+		"ignored.go":     "// +build ignore\n\npackage main\n\nfunc Ignored() {}\n",
+	}
+
+	tests := []struct {
+		name    string
+		options IndexBuilderOptions
+		want    []string
+		skipped int
+	}{
+		{
+			name:    "all files without tag matching",
+			options: IndexBuilderOptions{},
+			want:    []string{"Common", "Ignored", "Integration", "Linux", "Windows"},
+		},
+		{
+			// Naming an operating system replaces the host GOOS
+			name:    "selected tags",
+			options: IndexBuilderOptions{MatchBuildTags: true, BuildTags: []string{"windows", "integration"}},
+			want:    []string{"Common", "Integration", "Windows"},
+			skipped: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			projectDir := t.TempDir()
+			for name, content := range files {
+				if err := os.WriteFile(filepath.Join(projectDir, name), []byte(content), 0600); err != nil {
+					t.Fatalf("Failed to create %s: %v", name, err)
+				}
+			}
+
+			builder := NewIndexBuilderWithOptions(projectDir, tt.options)
+			if err := builder.Initialize(); err != nil {
+				t.Fatalf("Failed to initialize index builder: %v", err)
+			}
+			defer builder.Close()
+
+			stats, err := builder.BuildIndex()
+			if err != nil {
+				t.Fatalf("Failed to build index: %v", err)
+			}
+			if len(stats.SkippedFiles) != tt.skipped {
+				t.Errorf("Expected %d skipped files, got %v", tt.skipped, stats.SkippedFiles)
+			}
+
+			result, err := NewQueryEngine(builder.storage).SearchByType(EntityTypeFunction)
+			if err != nil {
+				t.Fatalf("Failed to list functions: %v", err)
+			}
+			var names []string
+			for _, entry := range result.Entries {
+				names = append(names, entry.IndexEntry.Name)
+			}
+			slices.Sort(names)
+			if !slices.Equal(names, tt.want) {
+				t.Errorf("Expected functions %v, got %v", tt.want, names)
+			}
+
+			fileContext, err := builder.storage.GetFileContext("integration.go")
+			if err != nil || fileContext.BuildConstraint != "integration" {
+				t.Errorf("Expected the stored constraint to be recorded, got %+v (err: %v)", fileContext, err)
+			}
+		})
+	}
+}
+
 func TestIndexBuilder_GetStatistics(t *testing.T) {
 	// Create temporary directory for testing
 	tempDir, err := os.MkdirTemp("", "builder_test")
@@ -378,3 +1022,54 @@ func TestIndexBuilder_Close(t *testing.T) {
 		t.Error("Expected error when using closed builder")
 	}
 }
+
+func TestIndexBuilder_BuildHistory(t *testing.T) {
+	projectDir := t.TempDir()
+	files := map[string]string{
+		"main.go":   "package main\n\nfunc main() {}\n",
+		"helper.py": "def helper():\n    return 1\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(projectDir, name), []byte(content), 0600); err != nil {
+			t.Fatalf("Failed to create %s: %v", name, err)
+		}
+	}
+
+	for i := 0; i < maxBuildHistory+1; i++ {
+		builder := NewIndexBuilderWithOptions(projectDir, IndexBuilderOptions{ToolVersion: fmt.Sprintf("1.0.%d", i)})
+		if err := builder.Initialize(); err != nil {
+			t.Fatalf("Failed to initialize builder: %v", err)
+		}
+		if _, err := builder.BuildIndex(); err != nil {
+			t.Fatalf("Failed to build index: %v", err)
+		}
+		if err := builder.Close(); err != nil {
+			t.Fatalf("Failed to close builder: %v", err)
+		}
+	}
+
+	storage := NewHybridStorage(filepath.Join(projectDir, ".repocontext"))
+	if err := storage.Initialize(); err != nil {
+		t.Fatalf("Failed to initialize storage: %v", err)
+	}
+	defer storage.Close()
+
+	history := storage.BuildHistory()
+	if len(history) != maxBuildHistory {
+		t.Fatalf("Expected %d recorded builds, got %d", maxBuildHistory, len(history))
+	}
+	if history[0].ToolVersion != "1.0.1" {
+		t.Errorf("Expected the oldest build to be dropped, first record has version %q", history[0].ToolVersion)
+	}
+
+	last := history[len(history)-1]
+	if last.ToolVersion != fmt.Sprintf("1.0.%d", maxBuildHistory) {
+		t.Errorf("Expected last build version 1.0.%d, got %q", maxBuildHistory, last.ToolVersion)
+	}
+	if last.FilesProcessed != 2 || last.Languages["go"] != 1 || last.Languages["python"] != 1 {
+		t.Errorf("Expected one go and one python file processed, got %d files %v", last.FilesProcessed, last.Languages)
+	}
+	if last.StartedAt.IsZero() || last.Duration <= 0 {
+		t.Errorf("Expected start time and duration to be recorded, got %v and %v", last.StartedAt, last.Duration)
+	}
+}
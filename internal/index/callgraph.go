@@ -101,6 +101,14 @@ func (gcg *GlobalCallGraph) BuildFromFiles(fileContexts []models.FileContext) er
 	return nil
 }
 
+// addRelation records a call relation collected outside BuildFromFiles
+func (gcg *GlobalCallGraph) addRelation(relation models.CallRelation) {
+	gcg.callersMap[relation.Callee] = append(gcg.callersMap[relation.Callee], relation)
+	gcg.calleesMap[relation.Caller] = append(gcg.calleesMap[relation.Caller], relation)
+	gcg.allFunctions[relation.Caller] = true
+	gcg.allFunctions[relation.Callee] = true
+}
+
 // GetCallers returns all functions that call the specified function
 func (gcg *GlobalCallGraph) GetCallers(functionName string) []models.CallRelation {
 	if callers, exists := gcg.callersMap[functionName]; exists {
@@ -0,0 +1,193 @@
+package index
+
+import "strings"
+
+// commentSyntax describes how a language writes comments and string literals
+type commentSyntax struct {
+	lineComment   string // Marker starting a comment that runs to the end of the line
+	blockComments bool   // /* ... */ comments
+	rawBackticks  bool   // Backtick strings without escapes, as in Go
+	tripleQuotes  bool   // Python triple-quoted strings
+	beginEnd      bool   // Ruby =begin ... =end comments
+	docstring     bool   // The leading string of a Python body is documentation
+}
+
+// commentSyntaxes maps parser language names to their comment syntax
+var commentSyntaxes = map[string]commentSyntax{
+	"go":         {lineComment: "//", blockComments: true, rawBackticks: true},
+	"c":          {lineComment: "//", blockComments: true},
+	"java":       {lineComment: "//", blockComments: true},
+	"typescript": {lineComment: "//", blockComments: true},
+	"python":     {lineComment: "#", tripleQuotes: true, docstring: true},
+	"ruby":       {lineComment: "#", beginEnd: true},
+}
+
+// StripComments removes the comments from source written in language and drops the lines
+// left empty by their removal. Go, C, Java and TypeScript lose // and /* */ comments, Python
+// and Ruby lose # comments, and a Python function or class also loses its leading docstring.
+// Comment markers inside string literals are kept. Other languages are returned unchanged.
+func StripComments(source, language string) string {
+	syntax, ok := commentSyntaxes[language]
+	if !ok {
+		return source
+	}
+
+	docStart, docEnd := -1, -1
+	if syntax.docstring {
+		docStart, docEnd = pythonDocstring(source, syntax)
+	}
+
+	// Removed text keeps its newlines so output lines line up with source lines
+	var stripped strings.Builder
+	line := 0
+	touched := make(map[int]bool)
+	keepNewlines := func(removed string) {
+		newlines := strings.Count(removed, "\n")
+		for offset := 0; offset <= newlines; offset++ {
+			touched[line+offset] = true
+		}
+		line += newlines
+		stripped.WriteString(strings.Repeat("\n", newlines))
+	}
+	for i := 0; i < len(source); {
+		switch {
+		case i == docStart:
+			keepNewlines(source[docStart:docEnd])
+			i = docEnd
+		case commentLength(source, i, syntax) > 0:
+			end := i + commentLength(source, i, syntax)
+			keepNewlines(source[i:end])
+			i = end
+		case source[i] == '"' || source[i] == '\'' || source[i] == '`':
+			end := i + stringLiteralEnd(source[i:], syntax)
+			line += strings.Count(source[i:end], "\n")
+			stripped.WriteString(source[i:end])
+			i = end
+		default:
+			if source[i] == '\n' {
+				line++
+			}
+			stripped.WriteByte(source[i])
+			i++
+		}
+	}
+
+	return dropEmptiedLines(strings.Split(stripped.String(), "\n"), touched)
+}
+
+// commentLength returns the length of the comment starting at offset, zero when there is none.
+// Line comments stop before their newline.
+func commentLength(source string, offset int, syntax commentSyntax) int {
+	rest := source[offset:]
+	switch {
+	case strings.HasPrefix(rest, syntax.lineComment):
+		if end := strings.IndexByte(rest, '\n'); end >= 0 {
+			return end
+		}
+		return len(rest)
+	case syntax.blockComments && strings.HasPrefix(rest, "/*"):
+		if end := strings.Index(rest[2:], "*/"); end >= 0 {
+			return end + 4
+		}
+		return len(rest)
+	case syntax.beginEnd && (offset == 0 || source[offset-1] == '\n') && strings.HasPrefix(rest, "=begin"):
+		end := strings.Index(rest, "\n=end")
+		if end < 0 {
+			return len(rest)
+		}
+		end += len("\n=end")
+		if lineEnd := strings.IndexByte(rest[end:], '\n'); lineEnd >= 0 {
+			return end + lineEnd
+		}
+		return len(rest)
+	default:
+		return 0
+	}
+}
+
+// dropEmptiedLines trims the lines comments were removed from, dropping those left blank.
+// Lines no comment touched are kept as they are, blank or not.
+func dropEmptiedLines(lines []string, touched map[int]bool) string {
+	kept := make([]string, 0, len(lines))
+	for i, line := range lines {
+		if !touched[i] {
+			kept = append(kept, line)
+			continue
+		}
+		if line = strings.TrimRight(line, " \t"); strings.TrimSpace(line) != "" {
+			kept = append(kept, line)
+		}
+	}
+	return strings.Join(kept, "\n")
+}
+
+// stringLiteralEnd returns the length of the string literal source starts with, or of
+// the rest of the line when the literal is not closed
+func stringLiteralEnd(source string, syntax commentSyntax) int {
+	quote := source[0]
+	if syntax.tripleQuotes && len(source) >= 3 && source[1] == quote && source[2] == quote {
+		delimiter := source[:3]
+		if end := strings.Index(source[3:], delimiter); end >= 0 {
+			return end + 6
+		}
+		return len(source)
+	}
+
+	raw := quote == '`' && syntax.rawBackticks
+	for i := 1; i < len(source); i++ {
+		switch {
+		case source[i] == '\\' && !raw:
+			i++
+		case source[i] == quote:
+			return i + 1
+		case source[i] == '\n' && quote != '`':
+			return i
+		}
+	}
+	return len(source)
+}
+
+// pythonDocstring returns the span of the string literal starting the body of the
+// function or class source declares, or -1, -1 when the body has no docstring
+func pythonDocstring(source string, syntax commentSyntax) (start, end int) {
+	// The body starts after the first colon outside brackets, past decorators and annotations
+	depth := 0
+	for i := 0; i < len(source); i++ {
+		switch c := source[i]; {
+		case c == '"' || c == '\'':
+			i += stringLiteralEnd(source[i:], syntax) - 1
+		case c == '#':
+			for i < len(source) && source[i] != '\n' {
+				i++
+			}
+		case strings.IndexByte("([{", c) >= 0:
+			depth++
+		case strings.IndexByte(")]}", c) >= 0:
+			depth--
+		case c == ':' && depth == 0:
+			return docstringAfter(source, i+1, syntax)
+		}
+	}
+	return -1, -1
+}
+
+// docstringAfter returns the span of the string literal that is the first statement
+// at or after offset, skipping blank lines and comments
+func docstringAfter(source string, offset int, syntax commentSyntax) (start, end int) {
+	for i := offset; i < len(source); i++ {
+		switch c := source[i]; {
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n':
+		case c == '#':
+			for i < len(source)-1 && source[i+1] != '\n' {
+				i++
+			}
+		case c == '"' || c == '\'':
+			return i, i + stringLiteralEnd(source[i:], syntax)
+		case strings.IndexByte("rRuU", c) >= 0 && i+1 < len(source) && (source[i+1] == '"' || source[i+1] == '\''):
+			return i, i + 1 + stringLiteralEnd(source[i+1:], syntax)
+		default:
+			return -1, -1
+		}
+	}
+	return -1, -1
+}
@@ -0,0 +1,72 @@
+package index
+
+import "testing"
+
+func TestStripComments(t *testing.T) {
+	tests := []struct {
+		name     string
+		language string
+		source   string
+		expected string
+	}{
+		{
+			name:     "go line and block comments",
+			language: "go",
+			source: "func Add(a, b int) int {\n\t// Add the numbers\n\tsum := a + b // inline\n" +
+				"\t/* multi\n\t   line */\n\treturn /* inner */ sum\n}",
+			expected: "func Add(a, b int) int {\n\tsum := a + b\n\treturn  sum\n}",
+		},
+		{
+			name:     "go comment markers in strings",
+			language: "go",
+			source:   "func URL() string {\n\treturn \"http://x\" + `/* raw */` + \"\\\"//\"\n}",
+			expected: "func URL() string {\n\treturn \"http://x\" + `/* raw */` + \"\\\"//\"\n}",
+		},
+		{
+			name:     "blank lines are kept",
+			language: "java",
+			source:   "void run() {\n\tstart();\n\n\tstop(); // done\n}",
+			expected: "void run() {\n\tstart();\n\n\tstop();\n}",
+		},
+		{
+			name:     "python comments and docstring",
+			language: "python",
+			source: "@app.route('/x')\ndef handler(a: Dict[str, int]) -> str:\n    \"\"\"Handle a request.\n\n" +
+				"    Returns the name.\n    \"\"\"\n    # look it up\n    name = a['#name']  # inline\n" +
+				"    return \"\"\"text\"\"\"",
+			expected: "@app.route('/x')\ndef handler(a: Dict[str, int]) -> str:\n    name = a['#name']\n    return \"\"\"text\"\"\"",
+		},
+		{
+			name:     "python single-quoted docstring after a comment",
+			language: "python",
+			source:   "def f():\n    # note\n    r'doc'\n    return 'x'",
+			expected: "def f():\n    return 'x'",
+		},
+		{
+			name:     "python without docstring",
+			language: "python",
+			source:   "def f(): return g('''a''')",
+			expected: "def f(): return g('''a''')",
+		},
+		{
+			name:     "ruby comments",
+			language: "ruby",
+			source:   "def greet\n=begin\nlong comment\n=end\n  # say hi\n  puts \"hi #{name}\" # inline\nend",
+			expected: "def greet\n  puts \"hi #{name}\"\nend",
+		},
+		{
+			name:     "unknown language is unchanged",
+			language: "cobol",
+			source:   "// kept",
+			expected: "// kept",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := StripComments(tt.source, tt.language); got != tt.expected {
+				t.Errorf("StripComments() =\n%q\nexpected\n%q", got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,146 @@
+package index
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// CompactIndex compacts the index of a .repocontext directory and returns its disk usage
+// before and after. It holds the write lock throughout, so it refuses while an IndexBuilder
+// has the directory open for writes: a build saves chunk files before the entries that
+// refer to them, and Compact would delete those chunks as orphans.
+func CompactIndex(repoContextDir string) (before, after int64, err error) {
+	if err := acquireWriteLock(repoContextDir); err != nil {
+		return 0, 0, err
+	}
+	defer func() { err = errors.Join(err, releaseWriteLock(repoContextDir)) }()
+
+	storage := NewHybridStorage(repoContextDir)
+	if err := storage.Initialize(); err != nil {
+		return 0, 0, fmt.Errorf("failed to initialize storage: %w", err)
+	}
+	defer storage.Close()
+
+	before, err = storage.DiskUsage()
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to measure index size: %w", err)
+	}
+	if err := storage.Compact(); err != nil {
+		return 0, 0, fmt.Errorf("compaction failed: %w", err)
+	}
+	after, err = storage.DiskUsage()
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to measure index size: %w", err)
+	}
+	return before, after, nil
+}
+
+// Compact reclaims space left behind by reindexing. It deletes chunk files that are
+// neither registered in SQLite, referenced by an index entry nor listed in the
+// manifest, then vacuums the SQLite database. Referenced chunks are never touched,
+// so queries running concurrently keep working, but chunks a writer has saved and not
+// yet referenced are deleted; use CompactIndex to hold the write lock.
+func (h *HybridStorage) Compact() error {
+	if h.sqliteIndex == nil || h.chunkSerializer == nil {
+		return fmt.Errorf("hybrid storage not initialized")
+	}
+
+	orphans, err := h.findOrphanedChunks()
+	if err != nil {
+		return err
+	}
+	for _, chunkID := range orphans {
+		if err := h.chunkSerializer.DeleteChunk(chunkID); err != nil {
+			return fmt.Errorf("failed to delete orphaned chunk %s: %w", chunkID, err)
+		}
+	}
+
+	return h.sqliteIndex.Vacuum()
+}
+
+// DiskUsage returns the bytes used by the SQLite database and the chunk files
+func (h *HybridStorage) DiskUsage() (int64, error) {
+	if h.chunkSerializer == nil {
+		return 0, fmt.Errorf("hybrid storage not initialized")
+	}
+
+	var total int64
+	if info, err := os.Stat(filepath.Join(h.baseDir, "index.db")); err == nil {
+		total += info.Size()
+	}
+
+	chunkIDs, err := h.chunkSerializer.ListChunks()
+	if err != nil {
+		return 0, fmt.Errorf("failed to list chunks: %w", err)
+	}
+	total += h.chunkFileSize(chunkIDs)
+	return total, nil
+}
+
+// FragmentationEstimate returns the fraction of disk usage, between 0 and 1, that
+// Compact would reclaim: free database pages plus orphaned chunk files
+func (h *HybridStorage) FragmentationEstimate() (float64, error) {
+	if h.sqliteIndex == nil {
+		return 0, fmt.Errorf("hybrid storage not initialized")
+	}
+
+	total, err := h.DiskUsage()
+	if err != nil || total == 0 {
+		return 0, err
+	}
+
+	reclaimable, err := h.sqliteIndex.FreeBytes()
+	if err != nil {
+		return 0, err
+	}
+	orphans, err := h.findOrphanedChunks()
+	if err != nil {
+		return 0, err
+	}
+	reclaimable += h.chunkFileSize(orphans)
+
+	return float64(reclaimable) / float64(total), nil
+}
+
+// findOrphanedChunks returns chunk files on disk that nothing in the index refers to
+func (h *HybridStorage) findOrphanedChunks() ([]string, error) {
+	referencedIDs, err := h.sqliteIndex.QueryChunkIDs()
+	if err != nil {
+		return nil, err
+	}
+	referenced := make(map[string]bool, len(referencedIDs))
+	for _, chunkID := range referencedIDs {
+		referenced[chunkID] = true
+	}
+	if h.manifest != nil {
+		for chunkID := range h.manifest.Chunks {
+			referenced[chunkID] = true
+		}
+	}
+
+	chunkIDs, err := h.chunkSerializer.ListChunks()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list chunks: %w", err)
+	}
+
+	var orphans []string
+	for _, chunkID := range chunkIDs {
+		if !referenced[chunkID] {
+			orphans = append(orphans, chunkID)
+		}
+	}
+	return orphans, nil
+}
+
+// chunkFileSize sums the on-disk size of the given chunks, ignoring missing files
+func (h *HybridStorage) chunkFileSize(chunkIDs []string) int64 {
+	var size int64
+	for _, chunkID := range chunkIDs {
+		if info, err := os.Stat(h.chunkSerializer.GetChunkPath(chunkID)); err == nil {
+			size += info.Size()
+		}
+	}
+	return size
+}
@@ -0,0 +1,86 @@
+package index
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"repository-context-protocol/internal/models"
+)
+
+func TestHybridStorage_Compact(t *testing.T) {
+	storage := newDiffTestStorage(t, &models.FileContext{
+		Path: "user.go", Language: "go", Checksum: "a1",
+		Functions: []models.Function{{Name: "GetUser", Signature: "func GetUser()", StartLine: 1, EndLine: 3}},
+	})
+
+	// A chunk left on disk by an earlier build that nothing refers to anymore
+	orphan := &models.SemanticChunk{
+		ID:        "orphan",
+		Files:     []string{"deleted.go"},
+		FileData:  []models.FileContext{{Path: "deleted.go", Language: "go"}},
+		CreatedAt: time.Now(),
+	}
+	if err := storage.chunkSerializer.SaveChunk(orphan); err != nil {
+		t.Fatalf("Failed to save orphaned chunk: %v", err)
+	}
+
+	fragmentation, err := storage.FragmentationEstimate()
+	if err != nil {
+		t.Fatalf("Failed to estimate fragmentation: %v", err)
+	}
+	if fragmentation <= 0 || fragmentation > 1 {
+		t.Errorf("Expected fragmentation between 0 and 1 with an orphaned chunk, got %f", fragmentation)
+	}
+
+	before, err := storage.DiskUsage()
+	if err != nil {
+		t.Fatalf("Failed to measure disk usage: %v", err)
+	}
+	if err := storage.Compact(); err != nil {
+		t.Fatalf("Failed to compact storage: %v", err)
+	}
+	after, err := storage.DiskUsage()
+	if err != nil {
+		t.Fatalf("Failed to measure disk usage: %v", err)
+	}
+	if after >= before {
+		t.Errorf("Expected compaction to reclaim space, usage went from %d to %d bytes", before, after)
+	}
+
+	if storage.chunkSerializer.ChunkExists("orphan") {
+		t.Error("Expected orphaned chunk to be deleted")
+	}
+	results, err := storage.QueryByName("GetUser")
+	if err != nil || len(results) != 1 {
+		t.Fatalf("Expected referenced data to survive compaction, got %v (err: %v)", results, err)
+	}
+	if _, err := storage.GetFileContext("user.go"); err != nil {
+		t.Errorf("Expected user.go to remain readable: %v", err)
+	}
+}
+
+func TestCompactIndex_RefusesWhileBuilding(t *testing.T) {
+	projectDir := t.TempDir()
+	repoContextDir := filepath.Join(projectDir, ".repocontext")
+
+	builder := NewIndexBuilder(projectDir)
+	if err := builder.Initialize(); err != nil {
+		t.Fatalf("Failed to initialize index builder: %v", err)
+	}
+	if _, _, err := CompactIndex(repoContextDir); err == nil || !strings.Contains(err.Error(), "open for writes") {
+		t.Errorf("Expected compaction to refuse while the builder holds the index, got %v", err)
+	}
+	if err := builder.Close(); err != nil {
+		t.Fatalf("Failed to close index builder: %v", err)
+	}
+
+	if _, _, err := CompactIndex(repoContextDir); err != nil {
+		t.Fatalf("Expected compaction to succeed once the builder closed, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(repoContextDir, WriteLockFileName)); !os.IsNotExist(err) {
+		t.Errorf("Expected compaction to release the write lock, got %v", err)
+	}
+}
@@ -0,0 +1,455 @@
+package index
+
+import (
+	"bytes"
+	"cmp"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"repository-context-protocol/internal/models"
+)
+
+// File statuses reported in an IndexDiff
+const (
+	FileStatusAdded    = "added"
+	FileStatusRemoved  = "removed"
+	FileStatusModified = "modified"
+)
+
+// Change kinds for symbols present in both indexes
+const (
+	ChangeSignature = "signature_changed" // Declaration changed
+	ChangeBody      = "body_changed"      // Same declaration, different source
+	ChangeRelocated = "relocated"         // Same declaration, moved to another file
+)
+
+// Symbol kinds compared by DiffIndexes
+const (
+	diffKindFunction = "function"
+	diffKindType     = "type"
+)
+
+// IndexDiff describes how the symbols of one index build differ from another
+type IndexDiff struct {
+	Files   []FileDiff  `json:"files"`
+	Summary DiffSummary `json:"summary"`
+}
+
+// DiffSummary counts the changes in an IndexDiff
+type DiffSummary struct {
+	FilesAdded    int `json:"files_added"`
+	FilesRemoved  int `json:"files_removed"`
+	FilesModified int `json:"files_modified"`
+	Added         int `json:"added"`
+	Removed       int `json:"removed"`
+	Modified      int `json:"modified"`
+}
+
+// FileDiff groups symbol changes by file. Added and modified symbols are reported
+// under their new file, removed symbols under the file they were removed from.
+type FileDiff struct {
+	Path     string         `json:"path"`
+	Status   string         `json:"status,omitempty"` // added, removed, modified or empty if the checksum is unchanged
+	Added    []SymbolChange `json:"added,omitempty"`
+	Removed  []SymbolChange `json:"removed,omitempty"`
+	Modified []SymbolChange `json:"modified,omitempty"`
+}
+
+// SymbolChange describes a single added, removed or modified function or type
+type SymbolChange struct {
+	Name         string `json:"name"`
+	Kind         string `json:"kind"`             // function or type
+	Change       string `json:"change,omitempty"` // Set for modified symbols
+	OldSignature string `json:"old_signature,omitempty"`
+	NewSignature string `json:"new_signature,omitempty"`
+	OldFile      string `json:"old_file,omitempty"`
+	OldLine      int    `json:"old_line,omitempty"`
+	NewFile      string `json:"new_file,omitempty"`
+	NewLine      int    `json:"new_line,omitempty"`
+}
+
+// IsEmpty reports whether the diff contains no changes
+func (d *IndexDiff) IsEmpty() bool {
+	return len(d.Files) == 0
+}
+
+// diffSymbol is a function or type flattened out of a file context for comparison
+type diffSymbol struct {
+	kind      string
+	name      string
+	file      string
+	signature string
+	startLine int
+	endLine   int
+	hash      string // Content hash, empty for indexes built before hashes were recorded
+	matched   bool
+}
+
+// indexSnapshot holds the symbols and checksums of every file in one index
+type indexSnapshot struct {
+	checksums map[string]string
+	symbols   []*diffSymbol
+}
+
+// DiffIndexes compares two index builds and reports added, removed and modified
+// functions and types grouped by file. Symbols are matched by name and file first;
+// unmatched symbols with the same name and signature in another file are reported
+// as relocations rather than a removal plus an addition.
+func DiffIndexes(oldStorage, newStorage *HybridStorage) (*IndexDiff, error) {
+	oldSnapshot, err := loadIndexSnapshot(oldStorage)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read old index: %w", err)
+	}
+	newSnapshot, err := loadIndexSnapshot(newStorage)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read new index: %w", err)
+	}
+
+	builder := newIndexDiffBuilder()
+
+	for path, newChecksum := range newSnapshot.checksums {
+		oldChecksum, exists := oldSnapshot.checksums[path]
+		switch {
+		case !exists:
+			builder.file(path).Status = FileStatusAdded
+		case oldChecksum != newChecksum:
+			builder.file(path).Status = FileStatusModified
+		}
+	}
+	for path := range oldSnapshot.checksums {
+		if _, exists := newSnapshot.checksums[path]; !exists {
+			builder.file(path).Status = FileStatusRemoved
+		}
+	}
+
+	// Pass 1: same name in the same file
+	pairSymbols(oldSnapshot.symbols, newSnapshot.symbols, func(s *diffSymbol) string {
+		return s.kind + "\x00" + s.name + "\x00" + s.file
+	}, builder.compare)
+
+	// Pass 2: identical declaration moved to another file
+	pairSymbols(oldSnapshot.symbols, newSnapshot.symbols, func(s *diffSymbol) string {
+		return s.kind + "\x00" + s.name + "\x00" + s.signature
+	}, builder.compare)
+
+	// Pass 3: a unique name that moved and changed its declaration
+	pairUniqueSymbols(oldSnapshot.symbols, newSnapshot.symbols, builder.compare)
+
+	for _, symbol := range oldSnapshot.symbols {
+		if !symbol.matched {
+			fileDiff := builder.file(symbol.file)
+			fileDiff.Removed = append(fileDiff.Removed, SymbolChange{
+				Name: symbol.name, Kind: symbol.kind,
+				OldSignature: symbol.signature, OldFile: symbol.file, OldLine: symbol.startLine,
+			})
+		}
+	}
+	for _, symbol := range newSnapshot.symbols {
+		if !symbol.matched {
+			fileDiff := builder.file(symbol.file)
+			fileDiff.Added = append(fileDiff.Added, SymbolChange{
+				Name: symbol.name, Kind: symbol.kind,
+				NewSignature: symbol.signature, NewFile: symbol.file, NewLine: symbol.startLine,
+			})
+		}
+	}
+
+	return builder.build(), nil
+}
+
+// annotateContentHashes records on the functions and types of fileContext the hash of
+// their source lines in content, so DiffIndexes can tell an edited body from a moved one
+func annotateContentHashes(fileContext *models.FileContext, content []byte) {
+	lines := bytes.Split(content, []byte("\n"))
+	hash := func(startLine, endLine int) string {
+		if startLine < 1 || startLine > len(lines) || endLine < startLine {
+			return ""
+		}
+		sum := sha256.Sum256(bytes.Join(lines[startLine-1:min(endLine, len(lines))], []byte("\n")))
+		return fmt.Sprintf("%x", sum)
+	}
+
+	for i := range fileContext.Functions {
+		function := &fileContext.Functions[i]
+		function.ContentHash = hash(function.StartLine, function.EndLine)
+	}
+	for i := range fileContext.Types {
+		typeDef := &fileContext.Types[i]
+		typeDef.ContentHash = hash(typeDef.StartLine, typeDef.EndLine)
+	}
+}
+
+// loadIndexSnapshot flattens every file in storage into comparable symbols
+func loadIndexSnapshot(storage *HybridStorage) (*indexSnapshot, error) {
+	files, err := storage.ListFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := &indexSnapshot{checksums: make(map[string]string, len(files))}
+	for _, path := range files {
+		fileContext, err := storage.GetFileContext(path)
+		if err != nil {
+			return nil, err
+		}
+		snapshot.checksums[path] = fileContext.Checksum
+
+		for i := range fileContext.Functions {
+			fn := &fileContext.Functions[i]
+			snapshot.symbols = append(snapshot.symbols, &diffSymbol{
+				kind: diffKindFunction, name: fn.Name, file: path,
+				signature: fn.Signature, startLine: fn.StartLine, endLine: fn.EndLine, hash: fn.ContentHash,
+			})
+		}
+		for i := range fileContext.Types {
+			typeDef := &fileContext.Types[i]
+			snapshot.symbols = append(snapshot.symbols, &diffSymbol{
+				kind: diffKindType, name: typeDef.Name, file: path,
+				signature: buildTypeSignature(typeDef), startLine: typeDef.StartLine, endLine: typeDef.EndLine,
+				hash: typeDef.ContentHash,
+			})
+		}
+	}
+
+	return snapshot, nil
+}
+
+// pairSymbols matches unmatched old and new symbols sharing a key. Within a key,
+// symbols with identical signatures are paired first so overloads and methods with
+// the same name on different receivers line up with their counterparts.
+func pairSymbols(oldSymbols, newSymbols []*diffSymbol, key func(*diffSymbol) string, pair func(oldSymbol, newSymbol *diffSymbol)) {
+	candidates := make(map[string][]*diffSymbol)
+	for _, symbol := range newSymbols {
+		if !symbol.matched {
+			k := key(symbol)
+			candidates[k] = append(candidates[k], symbol)
+		}
+	}
+
+	var leftovers []*diffSymbol
+	for _, oldSymbol := range oldSymbols {
+		if oldSymbol.matched {
+			continue
+		}
+		bucket := candidates[key(oldSymbol)]
+		index := slices.IndexFunc(bucket, func(s *diffSymbol) bool {
+			return !s.matched && s.signature == oldSymbol.signature
+		})
+		if index < 0 {
+			leftovers = append(leftovers, oldSymbol)
+			continue
+		}
+		pair(oldSymbol, bucket[index])
+	}
+
+	for _, oldSymbol := range leftovers {
+		bucket := candidates[key(oldSymbol)]
+		index := slices.IndexFunc(bucket, func(s *diffSymbol) bool { return !s.matched })
+		if index >= 0 {
+			pair(oldSymbol, bucket[index])
+		}
+	}
+}
+
+// pairUniqueSymbols matches names that occur exactly once among the remaining
+// unmatched symbols on each side
+func pairUniqueSymbols(oldSymbols, newSymbols []*diffSymbol, pair func(oldSymbol, newSymbol *diffSymbol)) {
+	unique := func(symbols []*diffSymbol) map[string]*diffSymbol {
+		counts := make(map[string]int)
+		byName := make(map[string]*diffSymbol)
+		for _, symbol := range symbols {
+			if symbol.matched {
+				continue
+			}
+			k := symbol.kind + "\x00" + symbol.name
+			counts[k]++
+			byName[k] = symbol
+		}
+		for k, count := range counts {
+			if count > 1 {
+				delete(byName, k)
+			}
+		}
+		return byName
+	}
+
+	newByName := unique(newSymbols)
+	for k, oldSymbol := range unique(oldSymbols) {
+		if newSymbol, exists := newByName[k]; exists {
+			pair(oldSymbol, newSymbol)
+		}
+	}
+}
+
+// indexDiffBuilder accumulates file diffs while symbols are being paired
+type indexDiffBuilder struct {
+	files map[string]*FileDiff
+}
+
+func newIndexDiffBuilder() *indexDiffBuilder {
+	return &indexDiffBuilder{files: make(map[string]*FileDiff)}
+}
+
+// file returns the diff for a path, creating it on first use
+func (b *indexDiffBuilder) file(path string) *FileDiff {
+	fileDiff, exists := b.files[path]
+	if !exists {
+		fileDiff = &FileDiff{Path: path}
+		b.files[path] = fileDiff
+	}
+	return fileDiff
+}
+
+// compare marks a pair as matched and records it if the symbol changed.
+// Line shifts within the same file are not reported since any edit above a
+// symbol moves it; a changed source is reported as a body change instead.
+func (b *indexDiffBuilder) compare(oldSymbol, newSymbol *diffSymbol) {
+	oldSymbol.matched = true
+	newSymbol.matched = true
+
+	var change string
+	switch {
+	case oldSymbol.signature != newSymbol.signature:
+		change = ChangeSignature
+	case oldSymbol.file != newSymbol.file:
+		change = ChangeRelocated
+	case bodyChanged(oldSymbol, newSymbol):
+		change = ChangeBody
+	default:
+		return
+	}
+
+	fileDiff := b.file(newSymbol.file)
+	fileDiff.Modified = append(fileDiff.Modified, SymbolChange{
+		Name:         newSymbol.name,
+		Kind:         newSymbol.kind,
+		Change:       change,
+		OldSignature: oldSymbol.signature,
+		NewSignature: newSymbol.signature,
+		OldFile:      oldSymbol.file,
+		OldLine:      oldSymbol.startLine,
+		NewFile:      newSymbol.file,
+		NewLine:      newSymbol.startLine,
+	})
+}
+
+// bodyChanged reports whether the source of a symbol changed, comparing content hashes.
+// An index built before hashes were recorded only has the line span to go by, so an edit
+// keeping the number of lines goes unnoticed there.
+func bodyChanged(oldSymbol, newSymbol *diffSymbol) bool {
+	if oldSymbol.hash != "" && newSymbol.hash != "" {
+		return oldSymbol.hash != newSymbol.hash
+	}
+	return oldSymbol.endLine-oldSymbol.startLine != newSymbol.endLine-newSymbol.startLine
+}
+
+// build sorts the accumulated file diffs, drops files without changes and computes the summary
+func (b *indexDiffBuilder) build() *IndexDiff {
+	diff := &IndexDiff{Files: []FileDiff{}}
+
+	for _, fileDiff := range b.files {
+		if fileDiff.Status == "" && len(fileDiff.Added)+len(fileDiff.Removed)+len(fileDiff.Modified) == 0 {
+			continue
+		}
+
+		for _, changes := range [][]SymbolChange{fileDiff.Added, fileDiff.Removed, fileDiff.Modified} {
+			slices.SortFunc(changes, compareSymbolChanges)
+		}
+
+		switch fileDiff.Status {
+		case FileStatusAdded:
+			diff.Summary.FilesAdded++
+		case FileStatusRemoved:
+			diff.Summary.FilesRemoved++
+		case FileStatusModified:
+			diff.Summary.FilesModified++
+		}
+		diff.Summary.Added += len(fileDiff.Added)
+		diff.Summary.Removed += len(fileDiff.Removed)
+		diff.Summary.Modified += len(fileDiff.Modified)
+
+		diff.Files = append(diff.Files, *fileDiff)
+	}
+
+	slices.SortFunc(diff.Files, func(a, b FileDiff) int {
+		return cmp.Compare(a.Path, b.Path)
+	})
+	return diff
+}
+
+// compareSymbolChanges orders changes by line, then name
+func compareSymbolChanges(a, b SymbolChange) int {
+	aLine, bLine := a.NewLine, b.NewLine
+	if aLine == 0 {
+		aLine = a.OldLine
+	}
+	if bLine == 0 {
+		bLine = b.OldLine
+	}
+	if aLine != bLine {
+		return cmp.Compare(aLine, bLine)
+	}
+	return cmp.Compare(a.Name, b.Name)
+}
+
+// SnapshotIndex copies the index files from a .repocontext directory into snapshotDir
+// so a later build can be diffed against it. The storage must not be open for writing.
+func SnapshotIndex(repoContextDir, snapshotDir string) error {
+	if _, err := os.Stat(filepath.Join(repoContextDir, "manifest.json")); err != nil {
+		return fmt.Errorf("no index found in %s: %w", repoContextDir, err)
+	}
+
+	if err := os.MkdirAll(filepath.Join(snapshotDir, "chunks"), dirPermissions); err != nil {
+		return fmt.Errorf("failed to create snapshot directory: %w", err)
+	}
+
+	for _, name := range []string{"index.db", "manifest.json"} {
+		if err := copyIndexFile(filepath.Join(repoContextDir, name), filepath.Join(snapshotDir, name)); err != nil {
+			return err
+		}
+	}
+
+	chunkFiles, err := os.ReadDir(filepath.Join(repoContextDir, "chunks"))
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read chunks directory: %w", err)
+	}
+	for _, entry := range chunkFiles {
+		if entry.IsDir() {
+			continue
+		}
+		src := filepath.Join(repoContextDir, "chunks", entry.Name())
+		dst := filepath.Join(snapshotDir, "chunks", entry.Name())
+		if err := copyIndexFile(src, dst); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// copyIndexFile copies a single file, skipping sources that do not exist
+func copyIndexFile(src, dst string) error {
+	in, err := os.Open(src) // #nosec G304 - Path is inside the index directory
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", src, err)
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, filePermissions) // #nosec G304 - Path is inside the snapshot directory
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", dst, err)
+	}
+
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return fmt.Errorf("failed to copy %s: %w", src, err)
+	}
+	return out.Close()
+}
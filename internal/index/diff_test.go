@@ -0,0 +1,274 @@
+package index
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"repository-context-protocol/internal/models"
+)
+
+func newDiffTestStorage(t *testing.T, fileContexts ...*models.FileContext) *HybridStorage {
+	t.Helper()
+
+	storage := NewHybridStorage(t.TempDir())
+	if err := storage.Initialize(); err != nil {
+		t.Fatalf("Failed to initialize storage: %v", err)
+	}
+	t.Cleanup(func() { storage.Close() })
+
+	for _, fileContext := range fileContexts {
+		if err := storage.StoreFileContext(fileContext); err != nil {
+			t.Fatalf("Failed to store file context: %v", err)
+		}
+	}
+	return storage
+}
+
+func findFileDiff(diff *IndexDiff, path string) *FileDiff {
+	for i := range diff.Files {
+		if diff.Files[i].Path == path {
+			return &diff.Files[i]
+		}
+	}
+	return nil
+}
+
+func TestDiffIndexes(t *testing.T) {
+	oldStorage := newDiffTestStorage(t,
+		&models.FileContext{
+			Path: "service.go", Language: "go", Checksum: "a1",
+			Functions: []models.Function{
+				{Name: "Start", Signature: "func Start()", StartLine: 3, EndLine: 5},
+				{Name: "Stop", Signature: "func Stop()", StartLine: 7, EndLine: 9},
+				{Name: "Shift", Signature: "func Shift()", StartLine: 11, EndLine: 13},
+				{Name: "Grow", Signature: "func Grow()", StartLine: 15, EndLine: 17},
+				{Name: "helper", Signature: "func helper(n int)", StartLine: 19, EndLine: 21},
+			},
+			Types: []models.TypeDef{{Name: "Service", Kind: "struct", StartLine: 23, EndLine: 25}},
+		},
+		&models.FileContext{
+			Path: "legacy.go", Language: "go", Checksum: "b1",
+			Functions: []models.Function{{Name: "Legacy", Signature: "func Legacy()", StartLine: 1, EndLine: 2}},
+		},
+		&models.FileContext{
+			Path: "stable.go", Language: "go", Checksum: "c1",
+			Functions: []models.Function{{Name: "Stable", Signature: "func Stable()", StartLine: 1, EndLine: 2}},
+		},
+	)
+
+	newStorage := newDiffTestStorage(t,
+		&models.FileContext{
+			Path: "service.go", Language: "go", Checksum: "a2",
+			Functions: []models.Function{
+				{Name: "Start", Signature: "func Start(ctx context.Context)", StartLine: 3, EndLine: 5},
+				{Name: "Shift", Signature: "func Shift()", StartLine: 20, EndLine: 22},
+				{Name: "Grow", Signature: "func Grow()", StartLine: 24, EndLine: 30},
+				{Name: "Restart", Signature: "func Restart()", StartLine: 32, EndLine: 34},
+			},
+			Types: []models.TypeDef{{Name: "Service", Kind: "struct", StartLine: 36, EndLine: 38}},
+		},
+		&models.FileContext{
+			Path: "helpers.go", Language: "go", Checksum: "d1",
+			Functions: []models.Function{{Name: "helper", Signature: "func helper(n int)", StartLine: 1, EndLine: 3}},
+		},
+		&models.FileContext{
+			Path: "stable.go", Language: "go", Checksum: "c1",
+			Functions: []models.Function{{Name: "Stable", Signature: "func Stable()", StartLine: 1, EndLine: 2}},
+		},
+	)
+
+	diff, err := DiffIndexes(oldStorage, newStorage)
+	if err != nil {
+		t.Fatalf("Failed to diff indexes: %v", err)
+	}
+
+	if findFileDiff(diff, "stable.go") != nil {
+		t.Error("Expected unchanged file to be omitted")
+	}
+
+	legacy := findFileDiff(diff, "legacy.go")
+	if legacy == nil || legacy.Status != FileStatusRemoved || len(legacy.Removed) != 1 {
+		t.Fatalf("Expected legacy.go to be removed with one symbol, got %+v", legacy)
+	}
+
+	helpers := findFileDiff(diff, "helpers.go")
+	if helpers == nil || helpers.Status != FileStatusAdded {
+		t.Fatalf("Expected helpers.go to be added, got %+v", helpers)
+	}
+	if len(helpers.Added) != 0 || len(helpers.Modified) != 1 {
+		t.Fatalf("Expected moved helper to be a modification, got %+v", helpers)
+	}
+	if helpers.Modified[0].Change != ChangeRelocated || helpers.Modified[0].OldFile != "service.go" {
+		t.Errorf("Expected helper to be relocated from service.go, got %+v", helpers.Modified[0])
+	}
+
+	service := findFileDiff(diff, "service.go")
+	if service == nil || service.Status != FileStatusModified {
+		t.Fatalf("Expected service.go to be modified, got %+v", service)
+	}
+
+	changes := make(map[string]string)
+	for _, change := range service.Modified {
+		changes[change.Name] = change.Change
+	}
+	if changes["Start"] != ChangeSignature {
+		t.Errorf("Expected Start to have a signature change, got %q", changes["Start"])
+	}
+	if changes["Grow"] != ChangeBody {
+		t.Errorf("Expected Grow to have a body change, got %q", changes["Grow"])
+	}
+	if _, exists := changes["Shift"]; exists {
+		t.Error("Expected a line shift within the same file not to be reported")
+	}
+	if _, exists := changes["Service"]; exists {
+		t.Error("Expected shifted type not to be reported")
+	}
+
+	if len(service.Added) != 1 || service.Added[0].Name != "Restart" {
+		t.Errorf("Expected Restart to be added, got %+v", service.Added)
+	}
+	if len(service.Removed) != 1 || service.Removed[0].Name != "Stop" {
+		t.Errorf("Expected Stop to be removed, got %+v", service.Removed)
+	}
+
+	expected := DiffSummary{FilesAdded: 1, FilesRemoved: 1, FilesModified: 1, Added: 1, Removed: 2, Modified: 3}
+	if diff.Summary != expected {
+		t.Errorf("Expected summary %+v, got %+v", expected, diff.Summary)
+	}
+}
+
+func TestDiffIndexes_ContentHashes(t *testing.T) {
+	oldStorage := newDiffTestStorage(t, &models.FileContext{
+		Path: "service.go", Language: "go", Checksum: "a1",
+		Functions: []models.Function{
+			{Name: "Edit", Signature: "func Edit()", StartLine: 1, EndLine: 3, ContentHash: "e1"},
+			{Name: "Move", Signature: "func Move()", StartLine: 5, EndLine: 7, ContentHash: "m1"},
+			{Name: "Legacy", Signature: "func Legacy()", StartLine: 9, EndLine: 11},
+		},
+	})
+	newStorage := newDiffTestStorage(t, &models.FileContext{
+		Path: "service.go", Language: "go", Checksum: "a2",
+		Functions: []models.Function{
+			{Name: "Edit", Signature: "func Edit()", StartLine: 1, EndLine: 3, ContentHash: "e2"},
+			{Name: "Move", Signature: "func Move()", StartLine: 15, EndLine: 17, ContentHash: "m1"},
+			{Name: "Legacy", Signature: "func Legacy()", StartLine: 9, EndLine: 12, ContentHash: "l1"},
+		},
+	})
+
+	diff, err := DiffIndexes(oldStorage, newStorage)
+	if err != nil {
+		t.Fatalf("Failed to diff indexes: %v", err)
+	}
+	service := findFileDiff(diff, "service.go")
+	if service == nil {
+		t.Fatal("Expected service.go to be modified")
+	}
+	changes := make(map[string]string)
+	for _, change := range service.Modified {
+		changes[change.Name] = change.Change
+	}
+	if changes["Edit"] != ChangeBody {
+		t.Errorf("Expected an edit keeping the line span to be a body change, got %q", changes["Edit"])
+	}
+	if _, exists := changes["Move"]; exists {
+		t.Error("Expected a moved function with the same source not to be reported")
+	}
+	// Without a hash on both sides the line span is compared
+	if changes["Legacy"] != ChangeBody {
+		t.Errorf("Expected a longer function without an old hash to be a body change, got %q", changes["Legacy"])
+	}
+}
+
+func TestDiffIndexes_BodyEditedInPlace(t *testing.T) {
+	projectDir := t.TempDir()
+	repoContextDir := filepath.Join(projectDir, ".repocontext")
+	build := func(source string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(projectDir, "main.go"), []byte(source), 0600); err != nil {
+			t.Fatalf("Failed to write main.go: %v", err)
+		}
+		builder := NewIndexBuilder(projectDir)
+		if err := builder.Initialize(); err != nil {
+			t.Fatalf("Failed to initialize index builder: %v", err)
+		}
+		defer builder.Close()
+		if _, err := builder.BuildIndex(); err != nil {
+			t.Fatalf("Failed to build index: %v", err)
+		}
+	}
+
+	build("package main\n\nfunc Limit() int {\n\treturn 10\n}\n")
+	baseDir := filepath.Join(t.TempDir(), "base")
+	if err := SnapshotIndex(repoContextDir, baseDir); err != nil {
+		t.Fatalf("Failed to snapshot index: %v", err)
+	}
+	build("package main\n\nfunc Limit() int {\n\treturn 20\n}\n")
+
+	oldStorage, newStorage := NewHybridStorage(baseDir), NewHybridStorage(repoContextDir)
+	for _, storage := range []*HybridStorage{oldStorage, newStorage} {
+		if err := storage.Initialize(); err != nil {
+			t.Fatalf("Failed to initialize storage: %v", err)
+		}
+		defer storage.Close()
+	}
+	diff, err := DiffIndexes(oldStorage, newStorage)
+	if err != nil {
+		t.Fatalf("Failed to diff indexes: %v", err)
+	}
+	mainDiff := findFileDiff(diff, "main.go")
+	if mainDiff == nil || len(mainDiff.Modified) != 1 || mainDiff.Modified[0].Change != ChangeBody {
+		t.Fatalf("Expected Limit to have a body change, got %+v", mainDiff)
+	}
+}
+
+func TestDiffIndexes_NoChanges(t *testing.T) {
+	fileContext := &models.FileContext{
+		Path: "main.go", Language: "go", Checksum: "a1",
+		Functions: []models.Function{{Name: "main", Signature: "func main()", StartLine: 1, EndLine: 3}},
+	}
+
+	diff, err := DiffIndexes(newDiffTestStorage(t, fileContext), newDiffTestStorage(t, fileContext))
+	if err != nil {
+		t.Fatalf("Failed to diff indexes: %v", err)
+	}
+	if !diff.IsEmpty() {
+		t.Errorf("Expected identical indexes to produce an empty diff, got %+v", diff.Files)
+	}
+}
+
+func TestSnapshotIndex(t *testing.T) {
+	baseDir := t.TempDir()
+	storage := NewHybridStorage(baseDir)
+	if err := storage.Initialize(); err != nil {
+		t.Fatalf("Failed to initialize storage: %v", err)
+	}
+	err := storage.StoreFileContext(&models.FileContext{
+		Path: "main.go", Language: "go", Checksum: "a1",
+		Functions: []models.Function{{Name: "main", Signature: "func main()", StartLine: 1, EndLine: 3}},
+	})
+	if err != nil {
+		t.Fatalf("Failed to store file context: %v", err)
+	}
+	storage.Close()
+
+	snapshotDir := filepath.Join(t.TempDir(), "snapshot")
+	if err := SnapshotIndex(baseDir, snapshotDir); err != nil {
+		t.Fatalf("Failed to snapshot index: %v", err)
+	}
+
+	snapshot := NewHybridStorage(snapshotDir)
+	if err := snapshot.Initialize(); err != nil {
+		t.Fatalf("Failed to open snapshot: %v", err)
+	}
+	defer snapshot.Close()
+
+	results, err := snapshot.QueryByName("main")
+	if err != nil || len(results) != 1 {
+		t.Fatalf("Expected snapshot to contain main, got %d results (err: %v)", len(results), err)
+	}
+
+	if err := SnapshotIndex(t.TempDir(), snapshotDir); err == nil {
+		t.Error("Expected snapshot of a directory without an index to fail")
+	}
+}
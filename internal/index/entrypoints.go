@@ -0,0 +1,87 @@
+package index
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"repository-context-protocol/internal/models"
+)
+
+// ValidateEntryPointPatterns checks entry point patterns for IndexBuilderOptions.EntryPointPatterns:
+// each is a glob matched against function names, or against decorator names when it starts with @
+func ValidateEntryPointPatterns(patterns []string) error {
+	for _, pattern := range patterns {
+		if strings.TrimSpace(pattern) == "" || pattern == "@" {
+			return fmt.Errorf("entry point patterns must not be empty")
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid entry point pattern '%s': %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// annotateEntryPoints marks the entry points of fileContext: top-level main functions, Go
+// init and TestMain functions, and functions matching one of patterns. Functions the parser
+// marked already, such as Python functions called under a main guard, stay marked.
+func annotateEntryPoints(fileContext *models.FileContext, patterns []string) {
+	for i := range fileContext.Functions {
+		function := &fileContext.Functions[i]
+		if function.IsEntryPoint || matchesEntryPointPattern(function, patterns) {
+			function.IsEntryPoint = true
+			continue
+		}
+		if function.Parent != "" || function.Receiver != "" {
+			continue
+		}
+		function.IsEntryPoint = isEntryPointName(function.Name) ||
+			(fileContext.Language == "go" && (function.Name == "init" || function.Name == "TestMain"))
+	}
+}
+
+// matchesEntryPointPattern reports whether a function matches an entry point pattern.
+// Name patterns match the function name or, for methods, Receiver.Name; patterns starting
+// with @ match decorator names without their arguments, e.g. @app.route for @app.route('/').
+func matchesEntryPointPattern(function *models.Function, patterns []string) bool {
+	for _, pattern := range patterns {
+		if strings.HasPrefix(pattern, "@") {
+			for _, decorator := range function.Decorators {
+				name, _, _ := strings.Cut(decorator, "(")
+				if matched, _ := path.Match(pattern, name); matched {
+					return true
+				}
+			}
+			continue
+		}
+		if matched, _ := path.Match(pattern, function.Name); matched {
+			return true
+		}
+		if function.Receiver != "" {
+			if matched, _ := path.Match(pattern, function.Receiver+"."+function.Name); matched {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isEntryPoint reports whether a function is an entry point, also recognizing main
+// functions in indexes built before entry points were marked
+func isEntryPoint(function *models.Function) bool {
+	return function.IsEntryPoint || (function.Parent == "" && function.Receiver == "" && isEntryPointName(function.Name))
+}
+
+// isEntryPointEntry reports whether a function entry is an entry point
+func isEntryPointEntry(entry *SearchResultEntry) bool {
+	if function := entryFunction(entry); function != nil {
+		return isEntryPoint(function)
+	}
+	return isEntryPointName(entry.IndexEntry.Name)
+}
+
+// isEntryPointName reports whether a function name is a program entry point in one of
+// the indexed languages
+func isEntryPointName(name string) bool {
+	return name == "main" || name == "__main__"
+}
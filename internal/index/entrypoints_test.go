@@ -0,0 +1,59 @@
+package index
+
+import (
+	"testing"
+
+	"repository-context-protocol/internal/models"
+)
+
+func TestAnnotateEntryPoints(t *testing.T) {
+	goFile := &models.FileContext{
+		Path: "cmd/app/main.go", Language: "go",
+		Functions: []models.Function{
+			{Name: "main"},
+			{Name: "init"},
+			{Name: "TestMain"},
+			{Name: "HandleLogin"},
+			{Name: "ServeHTTP", Receiver: "Router"},
+			{Name: "main", Receiver: "Runner"},
+			{Name: "helper"},
+		},
+	}
+	annotateEntryPoints(goFile, []string{"Handle*", "Router.ServeHTTP"})
+
+	expected := []bool{true, true, true, true, true, false, false}
+	for i, function := range goFile.Functions {
+		if function.IsEntryPoint != expected[i] {
+			t.Errorf("Expected %s.%s entry point to be %v", function.Receiver, function.Name, expected[i])
+		}
+	}
+
+	pythonFile := &models.FileContext{
+		Path: "app/views.py", Language: "python",
+		Functions: []models.Function{
+			{Name: "index", Decorators: []string{"@app.route('/')"}},
+			{Name: "cli", IsEntryPoint: true}, // Called under a main guard
+			{Name: "init"},
+			{Name: "cached", Decorators: []string{"@functools.cache"}},
+		},
+	}
+	annotateEntryPoints(pythonFile, []string{"@app.route"})
+
+	expected = []bool{true, true, false, false}
+	for i, function := range pythonFile.Functions {
+		if function.IsEntryPoint != expected[i] {
+			t.Errorf("Expected %s entry point to be %v", function.Name, expected[i])
+		}
+	}
+}
+
+func TestValidateEntryPointPatterns(t *testing.T) {
+	if err := ValidateEntryPointPatterns([]string{"Handle*", "@app.route", "*.ServeHTTP"}); err != nil {
+		t.Errorf("Expected valid patterns, got %v", err)
+	}
+	for _, pattern := range []string{"", " ", "@", "Handle[a-"} {
+		if err := ValidateEntryPointPatterns([]string{pattern}); err == nil {
+			t.Errorf("Expected %q to be rejected", pattern)
+		}
+	}
+}
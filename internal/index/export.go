@@ -0,0 +1,223 @@
+package index
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"repository-context-protocol/internal/models"
+)
+
+// ExportFormatVersion is the version of the document written by ExportIndex. It is raised
+// whenever the layout changes incompatibly; ImportIndex rejects documents from newer versions.
+const ExportFormatVersion = 1
+
+// IndexExportSummary describes an exported or imported index document
+type IndexExportSummary struct {
+	FormatVersion int `json:"format_version"`
+	Files         int `json:"files"`
+	CallRelations int `json:"call_relations"`
+}
+
+// ExportIndex writes the whole index as a single JSON document of the form
+//
+//	{"format_version": 1, "exported_at": ..., "manifest": {...}, "files": [...], "call_graph": [...]}
+//
+// where files holds every stored FileContext and call_graph the call relations derived
+// from them. The manifest is included for HybridStorage only, and source stored with
+// StoreSource is left out. Files are loaded and written one at a time, so memory use
+// does not grow with the size of the index.
+func ExportIndex(w io.Writer, storage Storage) (*IndexExportSummary, error) {
+	files, err := storage.ListFiles()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list files: %w", err)
+	}
+
+	var manifest *models.Manifest
+	if hybrid, ok := storage.(*HybridStorage); ok {
+		manifest = hybrid.manifest
+	}
+
+	summary := &IndexExportSummary{FormatVersion: ExportFormatVersion}
+	out := &exportWriter{w: w}
+	out.writeString(`{"format_version":`)
+	out.writeJSON(ExportFormatVersion)
+	out.writeString(`,"exported_at":`)
+	out.writeJSON(time.Now().UTC())
+	if manifest != nil {
+		out.writeString(`,"manifest":`)
+		out.writeJSON(manifest)
+	}
+
+	out.writeString(`,"files":[`)
+	for i, filePath := range files {
+		fileContext, err := storage.GetFileContext(filePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load %s: %w", filePath, err)
+		}
+		if i > 0 {
+			out.writeString(",")
+		}
+		out.writeString("\n")
+		out.writeJSON(fileContext)
+		summary.Files++
+	}
+
+	// The call graph is derived from the files again rather than collected during the
+	// first pass, so it is never held in memory as a whole
+	out.writeString("\n],\"call_graph\":[")
+	for _, filePath := range files {
+		fileContext, err := storage.GetFileContext(filePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load %s: %w", filePath, err)
+		}
+		for _, relation := range fileCallRelations(fileContext) {
+			if summary.CallRelations > 0 {
+				out.writeString(",")
+			}
+			out.writeString("\n")
+			out.writeJSON(relation)
+			summary.CallRelations++
+		}
+	}
+	out.writeString("\n]}\n")
+
+	if out.err != nil {
+		return nil, fmt.Errorf("failed to write index export: %w", out.err)
+	}
+	return summary, nil
+}
+
+// exportWriter writes to w until the first error, which it keeps
+type exportWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (ew *exportWriter) writeString(s string) {
+	if ew.err == nil {
+		_, ew.err = io.WriteString(ew.w, s)
+	}
+}
+
+func (ew *exportWriter) writeJSON(value interface{}) {
+	if ew.err != nil {
+		return
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		ew.err = err
+		return
+	}
+	_, ew.err = ew.w.Write(data)
+}
+
+// ImportIndex loads a document written by ExportIndex into storage, which must be empty.
+// Files are decoded and stored one at a time; the call graph is rebuilt from them as they
+// are stored, so the exported call_graph is not read back. For HybridStorage the exported
+// build history is restored. If an error occurs part way, the files stored so far remain.
+func ImportIndex(r io.Reader, storage Storage) (*IndexExportSummary, error) {
+	existing, err := storage.ListFiles()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list files: %w", err)
+	}
+	if len(existing) > 0 {
+		return nil, fmt.Errorf("cannot import into an index that already holds %d files", len(existing))
+	}
+
+	decoder := json.NewDecoder(r)
+	if err := expectJSONDelim(decoder, '{'); err != nil {
+		return nil, fmt.Errorf("invalid index export: %w", err)
+	}
+
+	summary := &IndexExportSummary{}
+	var manifest *models.Manifest
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, fmt.Errorf("invalid index export: %w", err)
+		}
+
+		switch key, _ := token.(string); key {
+		case "format_version":
+			if err := decoder.Decode(&summary.FormatVersion); err != nil {
+				return nil, fmt.Errorf("invalid format_version: %w", err)
+			}
+			if summary.FormatVersion < 1 || summary.FormatVersion > ExportFormatVersion {
+				return nil, fmt.Errorf("unsupported index export format version %d, expected at most %d",
+					summary.FormatVersion, ExportFormatVersion)
+			}
+		case "manifest":
+			if err := decoder.Decode(&manifest); err != nil {
+				return nil, fmt.Errorf("invalid manifest: %w", err)
+			}
+		case "files":
+			if summary.FormatVersion == 0 {
+				return nil, fmt.Errorf("invalid index export: format_version must precede files")
+			}
+			if err := importFiles(decoder, storage, summary); err != nil {
+				return nil, err
+			}
+		default:
+			// exported_at, call_graph and fields added by later minor revisions
+			var skipped json.RawMessage
+			if err := decoder.Decode(&skipped); err != nil {
+				return nil, fmt.Errorf("invalid index export: %w", err)
+			}
+		}
+	}
+	if err := expectJSONDelim(decoder, '}'); err != nil {
+		return nil, fmt.Errorf("invalid index export: %w", err)
+	}
+	if summary.FormatVersion == 0 {
+		return nil, fmt.Errorf("invalid index export: missing format_version")
+	}
+
+	if hybrid, ok := storage.(*HybridStorage); ok && manifest != nil {
+		for i := range manifest.BuildHistory {
+			if err := hybrid.RecordBuild(&manifest.BuildHistory[i]); err != nil {
+				return nil, fmt.Errorf("failed to restore build history: %w", err)
+			}
+		}
+		if err := hybrid.SetPathFormat(manifest.PathFormat); err != nil {
+			return nil, fmt.Errorf("failed to restore path format: %w", err)
+		}
+	}
+
+	return summary, nil
+}
+
+// importFiles stores each FileContext of the files array the decoder is positioned at
+func importFiles(decoder *json.Decoder, storage Storage, summary *IndexExportSummary) error {
+	if err := expectJSONDelim(decoder, '['); err != nil {
+		return fmt.Errorf("invalid files: %w", err)
+	}
+	for decoder.More() {
+		var fileContext models.FileContext
+		if err := decoder.Decode(&fileContext); err != nil {
+			return fmt.Errorf("invalid file entry %d: %w", summary.Files, err)
+		}
+		if err := storage.StoreFileContext(&fileContext); err != nil {
+			return fmt.Errorf("failed to store %s: %w", fileContext.Path, err)
+		}
+		summary.Files++
+		summary.CallRelations += len(fileCallRelations(&fileContext))
+	}
+	if err := expectJSONDelim(decoder, ']'); err != nil {
+		return fmt.Errorf("invalid files: %w", err)
+	}
+	return nil
+}
+
+// expectJSONDelim reads the next token and fails unless it is the given delimiter
+func expectJSONDelim(decoder *json.Decoder, delim json.Delim) error {
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+	if token != delim {
+		return fmt.Errorf("expected %q, got %v", delim, token)
+	}
+	return nil
+}
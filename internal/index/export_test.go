@@ -0,0 +1,102 @@
+package index
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"repository-context-protocol/internal/models"
+)
+
+func TestExportImportIndex_RoundTrip(t *testing.T) {
+	source := newDiffTestStorage(t, memoryTestFiles()[:2]...)
+	if err := source.RecordBuild(&models.BuildRecord{StartedAt: time.Unix(1700000000, 0).UTC(), FilesProcessed: 2}); err != nil {
+		t.Fatalf("Failed to record build: %v", err)
+	}
+
+	var document bytes.Buffer
+	exported, err := ExportIndex(&document, source)
+	if err != nil {
+		t.Fatalf("Failed to export index: %v", err)
+	}
+	if *exported != (IndexExportSummary{FormatVersion: ExportFormatVersion, Files: 2, CallRelations: 3}) {
+		t.Errorf("Unexpected export summary %+v", exported)
+	}
+
+	var decoded struct {
+		FormatVersion int                   `json:"format_version"`
+		Manifest      *models.Manifest      `json:"manifest"`
+		Files         []models.FileContext  `json:"files"`
+		CallGraph     []models.CallRelation `json:"call_graph"`
+	}
+	if err := json.Unmarshal(document.Bytes(), &decoded); err != nil {
+		t.Fatalf("Export is not valid JSON: %v\n%s", err, document.String())
+	}
+	if decoded.FormatVersion != ExportFormatVersion || decoded.Manifest == nil || len(decoded.Files) != 2 || len(decoded.CallGraph) != 3 {
+		t.Errorf("Unexpected export document %+v", decoded)
+	}
+
+	target := newDiffTestStorage(t)
+	imported, err := ImportIndex(bytes.NewReader(document.Bytes()), target)
+	if err != nil {
+		t.Fatalf("Failed to import index: %v", err)
+	}
+	if *imported != *exported {
+		t.Errorf("Expected import summary %+v, got %+v", exported, imported)
+	}
+
+	for _, path := range []string{"/repo/user.go", "/repo/log.go"} {
+		expected, _ := source.GetFileContext(path)
+		actual, err := target.GetFileContext(path)
+		if err != nil || !reflect.DeepEqual(actual, expected) {
+			t.Errorf("Expected %s to round trip, got %+v (%v)", path, actual, err)
+		}
+	}
+	expectedCalls, _ := source.QueryCallsTo("logf")
+	actualCalls, _ := target.QueryCallsTo("logf")
+	if !slices.Equal(actualCalls, expectedCalls) {
+		t.Errorf("Expected call graph %+v, got %+v", expectedCalls, actualCalls)
+	}
+	if history := target.BuildHistory(); len(history) != 1 || history[0].FilesProcessed != 2 {
+		t.Errorf("Expected the build history to be restored, got %+v", history)
+	}
+
+	// Exports load into any storage backend
+	memory := NewMemoryStorage()
+	if _, err := ImportIndex(bytes.NewReader(document.Bytes()), memory); err != nil {
+		t.Fatalf("Failed to import into memory storage: %v", err)
+	}
+	if files, _ := memory.ListFiles(); len(files) != 2 {
+		t.Errorf("Expected 2 files in memory storage, got %v", files)
+	}
+}
+
+func TestImportIndex_Errors(t *testing.T) {
+	var document bytes.Buffer
+	if _, err := ExportIndex(&document, newDiffTestStorage(t, memoryTestFiles()[1])); err != nil {
+		t.Fatalf("Failed to export index: %v", err)
+	}
+
+	tests := map[string]struct {
+		storage  Storage
+		document string
+		expected string
+	}{
+		"non-empty storage": {newDiffTestStorage(t, memoryTestFiles()[0]), document.String(), "already holds 1 files"},
+		"newer version":     {NewMemoryStorage(), `{"format_version": 99, "files": []}`, "unsupported index export format version 99"},
+		"missing version":   {NewMemoryStorage(), `{"files": []}`, "format_version must precede files"},
+		"empty document":    {NewMemoryStorage(), `{}`, "missing format_version"},
+		"not an object":     {NewMemoryStorage(), `[]`, "invalid index export"},
+		"truncated":         {NewMemoryStorage(), document.String()[:document.Len()/2], "invalid"},
+	}
+	for name, test := range tests {
+		_, err := ImportIndex(strings.NewReader(test.document), test.storage)
+		if err == nil || !strings.Contains(err.Error(), test.expected) {
+			t.Errorf("%s: expected error containing %q, got %v", name, test.expected, err)
+		}
+	}
+}
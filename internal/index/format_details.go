@@ -0,0 +1,70 @@
+package index
+
+import (
+	"fmt"
+	"strings"
+
+	"repository-context-protocol/internal/models"
+)
+
+// verboseFunction returns the function model of an entry when the result was requested
+// with Verbose, for formatters listing parameters, returns and doc comments
+func verboseFunction(result *SearchResult, entry *SearchResultEntry) *models.Function {
+	if result.Options == nil || !result.Options.Verbose {
+		return nil
+	}
+	return entryFunction(entry)
+}
+
+// writeTextFunctionDetails writes the doc comment, parameters and returns of a function,
+// one per line, indented under its text entry
+func writeTextFunctionDetails(output *strings.Builder, function *models.Function) {
+	if doc := strings.TrimSpace(function.Doc); doc != "" {
+		output.WriteString("   Doc:\n")
+		for _, line := range strings.Split(doc, "\n") {
+			output.WriteString(strings.TrimRight("     "+line, " ") + "\n")
+		}
+	}
+	if len(function.Parameters) > 0 {
+		output.WriteString("   Parameters:\n")
+		for _, parameter := range function.Parameters {
+			output.WriteString(fmt.Sprintf("     - %s\n", strings.TrimSpace(parameter.Name+" "+parameter.Type)))
+		}
+	}
+	if len(function.Returns) > 0 {
+		output.WriteString("   Returns:\n")
+		for _, result := range function.Returns {
+			output.WriteString(fmt.Sprintf("     - %s\n", result.Name))
+		}
+	}
+}
+
+// writeMarkdownFunctionDetails writes the doc comment of a function as a paragraph, its
+// parameters as a table and its returns as a list
+func writeMarkdownFunctionDetails(output *strings.Builder, function *models.Function) {
+	if doc := strings.TrimSpace(function.Doc); doc != "" {
+		output.WriteString(doc + "\n\n")
+	}
+	if len(function.Parameters) > 0 {
+		output.WriteString("| Parameter | Type |\n| --- | --- |\n")
+		for _, parameter := range function.Parameters {
+			output.WriteString(fmt.Sprintf("| %s | %s |\n", markdownCode(parameter.Name), markdownCode(parameter.Type)))
+		}
+		output.WriteString("\n")
+	}
+	if len(function.Returns) > 0 {
+		output.WriteString("Returns:\n\n")
+		for _, result := range function.Returns {
+			output.WriteString(fmt.Sprintf("- %s\n", markdownCode(result.Name)))
+		}
+		output.WriteString("\n")
+	}
+}
+
+// markdownCode renders text as inline code safe inside a table cell, or nothing when empty
+func markdownCode(text string) string {
+	if text == "" {
+		return ""
+	}
+	return "`" + strings.ReplaceAll(text, "|", `\|`) + "`"
+}
@@ -0,0 +1,117 @@
+package index
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ChangedFiles returns the files under repoPath that differ from a git ref, as listed by
+// git diff --name-only, joined to repoPath so they match the paths an index built from
+// repoPath records. Deleted files are included. It fails with a clear error when git is
+// not installed, repoPath is not inside a git checkout, or ref is unknown.
+func ChangedFiles(repoPath, ref string) ([]string, error) {
+	if ref == "" || strings.HasPrefix(ref, "-") {
+		return nil, fmt.Errorf("invalid git ref %q", ref)
+	}
+	if _, err := exec.LookPath("git"); err != nil {
+		return nil, fmt.Errorf("git is required to find changed files: %w", err)
+	}
+	if output, err := runGit(repoPath, "rev-parse", "--is-inside-work-tree"); err != nil || strings.TrimSpace(output) != "true" {
+		return nil, fmt.Errorf("%s is not a git checkout, so files changed since %s cannot be determined", repoPath, ref)
+	}
+
+	// --relative limits the diff to repoPath and lists paths relative to it
+	output, err := runGit(repoPath, "diff", "--name-only", "--relative", "-z", ref, "--")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list files changed since %s: %w", ref, err)
+	}
+
+	files := []string{}
+	for _, name := range strings.Split(output, "\x00") {
+		if name != "" {
+			files = append(files, filepath.Join(repoPath, filepath.FromSlash(name)))
+		}
+	}
+	return files, nil
+}
+
+// LineBlame is the commit that last changed a line, as reported by git blame
+type LineBlame struct {
+	Commit string    // Full commit hash, empty for lines not committed yet
+	Time   time.Time // Author time of the commit
+}
+
+// BlameFile returns the commit that last changed each line of a file, indexed by line
+// number minus one. It fails when git is not installed or cannot blame the file, for
+// instance because it lies outside a git checkout or is not tracked.
+func BlameFile(path string) ([]LineBlame, error) {
+	if _, err := exec.LookPath("git"); err != nil {
+		return nil, fmt.Errorf("git is required to blame files: %w", err)
+	}
+
+	output, err := runGit(filepath.Dir(path), "blame", "--line-porcelain", "--", filepath.Base(path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to blame %s: %w", path, err)
+	}
+	return parseLinePorcelain(output), nil
+}
+
+// parseLinePorcelain reads git blame --line-porcelain output, in which every line is
+// preceded by a header naming its commit and the commit's details
+func parseLinePorcelain(output string) []LineBlame {
+	var lines []LineBlame
+	var current LineBlame
+	for _, line := range strings.Split(output, "\n") {
+		switch {
+		case strings.HasPrefix(line, "\t"):
+			lines = append(lines, current)
+			current = LineBlame{}
+		case strings.HasPrefix(line, "author-time "):
+			if seconds, err := strconv.ParseInt(strings.TrimPrefix(line, "author-time "), 10, 64); err == nil {
+				current.Time = time.Unix(seconds, 0).UTC()
+			}
+		default:
+			if fields := strings.Fields(line); len(fields) >= 3 && isCommitHash(fields[0]) {
+				current.Commit = fields[0]
+			}
+		}
+	}
+
+	// Uncommitted lines carry an all-zero hash and the current time
+	for i := range lines {
+		if strings.Trim(lines[i].Commit, "0") == "" {
+			lines[i] = LineBlame{}
+		}
+	}
+	return lines
+}
+
+// isCommitHash reports whether s is a full SHA-1 or SHA-256 commit hash
+func isCommitHash(s string) bool {
+	if len(s) != 40 && len(s) != 64 {
+		return false
+	}
+	return strings.Trim(s, "0123456789abcdef") == ""
+}
+
+// runGit runs a git command in dir, returning its output or an error carrying git's message
+func runGit(dir string, args ...string) (string, error) {
+	// #nosec G204 - arguments are fixed subcommands and a ref validated by the caller
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	output, err := cmd.Output()
+	if err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return "", fmt.Errorf("%s", message)
+		}
+		return "", err
+	}
+	return string(output), nil
+}
@@ -0,0 +1,202 @@
+package index
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"repository-context-protocol/internal/models"
+)
+
+// newGitTestRepo creates a git repository holding files in one commit
+func newGitTestRepo(t *testing.T, files map[string]string) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	repoDir := t.TempDir()
+	for name, content := range files {
+		writeGitTestFile(t, repoDir, name, content)
+	}
+	runGitTestCommand(t, repoDir, "init", "-q")
+	runGitTestCommand(t, repoDir, "add", ".")
+	runGitTestCommand(t, repoDir, "-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "-m", "initial")
+	return repoDir
+}
+
+func writeGitTestFile(t *testing.T, repoDir, name, content string) {
+	t.Helper()
+	path := filepath.Join(repoDir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("Failed to create directory for %s: %v", name, err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("Failed to write %s: %v", name, err)
+	}
+}
+
+func runGitTestCommand(t *testing.T, repoDir string, args ...string) {
+	t.Helper()
+	output, err := exec.Command("git", append([]string{"-C", repoDir}, args...)...).CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %s failed: %v\n%s", strings.Join(args, " "), err, output)
+	}
+}
+
+func TestChangedFiles(t *testing.T) {
+	repoDir := newGitTestRepo(t, map[string]string{
+		"main.go":       "package main\n",
+		"pkg/util.go":   "package pkg\n",
+		"pkg/stable.go": "package pkg\n",
+		"old.go":        "package main\n",
+	})
+	writeGitTestFile(t, repoDir, "pkg/util.go", "package pkg\n\nfunc Util() {}\n")
+	writeGitTestFile(t, repoDir, "untracked.go", "package main\n")
+	if err := os.Remove(filepath.Join(repoDir, "old.go")); err != nil {
+		t.Fatalf("Failed to remove old.go: %v", err)
+	}
+
+	files, err := ChangedFiles(repoDir, "HEAD")
+	if err != nil {
+		t.Fatalf("Failed to list changed files: %v", err)
+	}
+	expected := []string{filepath.Join(repoDir, "old.go"), filepath.Join(repoDir, "pkg", "util.go")}
+	slices.Sort(files)
+	if !slices.Equal(files, expected) {
+		t.Errorf("Expected modified and deleted files %v, got %v", expected, files)
+	}
+
+	// Paths stay joined to the directory asked about when it is below the repository root
+	files, err = ChangedFiles(filepath.Join(repoDir, "pkg"), "HEAD")
+	if err != nil {
+		t.Fatalf("Failed to list changed files: %v", err)
+	}
+	if !slices.Equal(files, []string{filepath.Join(repoDir, "pkg", "util.go")}) {
+		t.Errorf("Expected only pkg/util.go under pkg, got %v", files)
+	}
+
+	if _, err := ChangedFiles(repoDir, "no-such-ref"); err == nil {
+		t.Error("Expected error for an unknown ref")
+	}
+	if _, err := ChangedFiles(repoDir, "--output=/tmp/x"); err == nil {
+		t.Error("Expected error for a ref that looks like an option")
+	}
+	if _, err := ChangedFiles(t.TempDir(), "HEAD"); err == nil || !strings.Contains(err.Error(), "not a git checkout") {
+		t.Errorf("Expected a not a git checkout error, got %v", err)
+	}
+}
+
+func TestQueryEngine_ChangedOnly(t *testing.T) {
+	repoDir := newGitTestRepo(t, map[string]string{
+		"users.go":  "package main\n\nfunc NewUser() {}\n",
+		"orders.go": "package main\n\nfunc NewOrder() {}\n",
+	})
+	writeGitTestFile(t, repoDir, "orders.go", "package main\n\nfunc NewOrder() {\n\tNewUser()\n}\n")
+
+	builder := NewIndexBuilder(repoDir)
+	if err := builder.Initialize(); err != nil {
+		t.Fatalf("Failed to initialize builder: %v", err)
+	}
+	defer builder.Close()
+	if _, err := builder.BuildIndex(); err != nil {
+		t.Fatalf("Failed to build index: %v", err)
+	}
+
+	files, err := ChangedFiles(repoDir, "HEAD")
+	if err != nil {
+		t.Fatalf("Failed to list changed files: %v", err)
+	}
+	engine := NewQueryEngine(builder.storage)
+	result, err := engine.SearchByTypeWithOptions(EntityTypeFunction, QueryOptions{
+		ChangedOnly: true, ChangedFiles: RepoRelativePaths(repoDir, files),
+	})
+	if err != nil {
+		t.Fatalf("Failed to search by type: %v", err)
+	}
+	if len(result.Entries) != 1 || result.Entries[0].IndexEntry.Name != "NewOrder" {
+		t.Errorf("Expected only the function in the changed file, got %+v", result.Entries)
+	}
+
+	// Without changed files nothing matches, unlike leaving the filter off
+	result, err = engine.SearchByPatternWithOptions("New*", QueryOptions{ChangedOnly: true})
+	if err != nil {
+		t.Fatalf("Failed to search by pattern: %v", err)
+	}
+	if len(result.Entries) != 0 {
+		t.Errorf("Expected no entries when no files changed, got %+v", result.Entries)
+	}
+}
+
+func TestIndexBuilder_GitBlame(t *testing.T) {
+	repoDir := newGitTestRepo(t, map[string]string{
+		"main.go": "package main\n\nfunc Old() {\n}\n\nfunc Changed() {\n}\n\ntype Config struct {\n\tName string\n}\n",
+	})
+	writeGitTestFile(t, repoDir, "main.go",
+		"package main\n\nfunc Old() {\n}\n\nfunc Changed() {\n\tOld()\n}\n\ntype Config struct {\n\tName string\n}\n")
+	runGitTestCommand(t, repoDir, "-c", "user.name=test", "-c", "user.email=test@example.com",
+		"commit", "-q", "-a", "-m", "change", "--date", "2030-01-02T03:04:05Z")
+	writeGitTestFile(t, repoDir, "untracked.go", "package main\n\nfunc Untracked() {}\n")
+
+	lastChange := func(builder *IndexBuilder, name string) *models.Function {
+		t.Helper()
+		result, err := NewQueryEngine(builder.storage).SearchByName(name)
+		if err != nil || len(result.Entries) != 1 {
+			t.Fatalf("Failed to find %s: %v", name, err)
+		}
+		function := entryFunction(&result.Entries[0])
+		if function == nil {
+			t.Fatalf("Expected parsed function data for %s", name)
+		}
+		return function
+	}
+
+	builder := NewIndexBuilderWithOptions(repoDir, IndexBuilderOptions{GitBlame: true})
+	if err := builder.Initialize(); err != nil {
+		t.Fatalf("Failed to initialize builder: %v", err)
+	}
+	defer builder.Close()
+	if _, err := builder.BuildIndex(); err != nil {
+		t.Fatalf("Failed to build index: %v", err)
+	}
+
+	changed := lastChange(builder, "Changed")
+	if changed.LastModified == nil || changed.LastModified.Year() != 2030 || len(changed.LastCommit) != 40 {
+		t.Errorf("Expected Changed to record the 2030 commit, got %v %q", changed.LastModified, changed.LastCommit)
+	}
+	old := lastChange(builder, "Old")
+	if old.LastModified == nil || old.LastModified.Year() == 2030 || old.LastCommit == changed.LastCommit {
+		t.Errorf("Expected Old to record the initial commit, got %v %q", old.LastModified, old.LastCommit)
+	}
+	if untracked := lastChange(builder, "Untracked"); untracked.LastModified != nil || untracked.LastCommit != "" {
+		t.Errorf("Expected no change recorded for an untracked file, got %v %q", untracked.LastModified, untracked.LastCommit)
+	}
+
+	result, err := NewQueryEngine(builder.storage).SearchByName("Config")
+	if err != nil || len(result.Entries) != 1 {
+		t.Fatalf("Failed to find Config: %v", err)
+	}
+	for _, typeDef := range entryFileData(&result.Entries[0]).Types {
+		if typeDef.LastCommit != old.LastCommit {
+			t.Errorf("Expected Config to record the initial commit, got %q", typeDef.LastCommit)
+		}
+	}
+
+	// Builds outside a git checkout succeed without annotations
+	plainDir := t.TempDir()
+	writeGitTestFile(t, plainDir, "main.go", "package main\n\nfunc Plain() {}\n")
+	plainBuilder := NewIndexBuilderWithOptions(plainDir, IndexBuilderOptions{GitBlame: true})
+	if err := plainBuilder.Initialize(); err != nil {
+		t.Fatalf("Failed to initialize builder: %v", err)
+	}
+	defer plainBuilder.Close()
+	if _, err := plainBuilder.BuildIndex(); err != nil {
+		t.Fatalf("Failed to build index outside git: %v", err)
+	}
+	if plain := lastChange(plainBuilder, "Plain"); plain.LastModified != nil {
+		t.Errorf("Expected no change recorded outside git, got %v", plain.LastModified)
+	}
+}
@@ -2,10 +2,13 @@ package index
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"repository-context-protocol/internal/models"
@@ -15,6 +18,9 @@ const (
 	// Directory permissions for hybrid storage directories
 	dirPermissions  = 0755
 	filePermissions = 0600
+
+	// Number of builds kept in the manifest build history
+	maxBuildHistory = 5
 )
 
 // HybridStorage combines SQLite indexing with MessagePack chunk storage
@@ -25,6 +31,8 @@ type HybridStorage struct {
 	chunkingStrategy ChunkingStrategy
 	manifest         *models.Manifest
 	manifestPath     string
+	compressChunks   bool
+	generation       atomic.Uint64 // Last generation read from the database
 }
 
 // QueryResult combines index entry with chunk data
@@ -47,6 +55,44 @@ func NewHybridStorage(baseDir string) *HybridStorage {
 	}
 }
 
+// Generation returns a counter that changes whenever the stored data may have changed.
+// Readers that cache derived results compare generations to detect stale data. It is
+// persisted in the database and read on every call, so writes through other storage
+// instances and processes, such as a build, are seen too; the last generation read is
+// returned when the database cannot be read.
+func (h *HybridStorage) Generation() uint64 {
+	if h.sqliteIndex != nil {
+		if generation, _, err := h.sqliteIndex.IndexState(); err == nil {
+			h.generation.Store(generation)
+		}
+	}
+	return h.generation.Load()
+}
+
+// UpdatedAt returns when the index was last written. Like Generation it is read from the
+// database on every call, so it reflects builds by other storage instances and processes;
+// indexes not written since the database recorded it fall back to the manifest's time.
+func (h *HybridStorage) UpdatedAt() time.Time {
+	if h.sqliteIndex != nil {
+		if _, updatedAt, err := h.sqliteIndex.IndexState(); err == nil && !updatedAt.IsZero() {
+			return updatedAt
+		}
+	}
+	if h.manifest == nil {
+		return time.Time{}
+	}
+	return h.manifest.UpdatedAt
+}
+
+// bumpGeneration records a write in the database, marking data previously read through
+// any storage instance as potentially stale
+func (h *HybridStorage) bumpGeneration() error {
+	if h.sqliteIndex == nil {
+		return fmt.Errorf("hybrid storage not initialized")
+	}
+	return h.sqliteIndex.BumpGeneration(time.Now())
+}
+
 // Initialize sets up the hybrid storage system
 func (h *HybridStorage) Initialize() error {
 	// Create base directory if it doesn't exist
@@ -69,6 +115,7 @@ func (h *HybridStorage) Initialize() error {
 
 	// Initialize chunk serializer
 	h.chunkSerializer = NewChunkSerializer(chunksDir)
+	h.chunkSerializer.SetCompression(h.compressChunks)
 
 	// Initialize chunking strategy (file-based for now)
 	h.chunkingStrategy = &FileBasedChunking{}
@@ -81,12 +128,47 @@ func (h *HybridStorage) Initialize() error {
 	return nil
 }
 
+// SetChunkCompression makes the storage gzip the chunks it writes from now on, trading
+// some CPU on every chunk load for a smaller index. Existing chunks keep their format and
+// still load, compressed or not.
+func (h *HybridStorage) SetChunkCompression(enabled bool) {
+	h.compressChunks = enabled
+	if h.chunkSerializer != nil {
+		h.chunkSerializer.SetCompression(enabled)
+	}
+}
+
+// ChunkStorageSize returns the disk space the chunk files take and their uncompressed
+// size. Both are equal when no chunk is compressed.
+func (h *HybridStorage) ChunkStorageSize() (stored, logical int64, err error) {
+	if h.chunkSerializer == nil {
+		return 0, 0, fmt.Errorf("hybrid storage not initialized")
+	}
+
+	chunkIDs, err := h.chunkSerializer.ListChunks()
+	if err != nil {
+		return 0, 0, err
+	}
+	for _, chunkID := range chunkIDs {
+		chunkStored, chunkLogical, err := h.chunkSerializer.ChunkSize(chunkID)
+		if err != nil {
+			return 0, 0, fmt.Errorf("failed to size chunk %s: %w", chunkID, err)
+		}
+		stored += chunkStored
+		logical += chunkLogical
+	}
+	return stored, logical, nil
+}
+
 // StoreFileContext stores a file context using hybrid storage
-func (h *HybridStorage) StoreFileContext(fileContext *models.FileContext) error {
+func (h *HybridStorage) StoreFileContext(fileContext *models.FileContext) (err error) {
 	if h.sqliteIndex == nil || h.chunkSerializer == nil || h.chunkingStrategy == nil {
 		return fmt.Errorf("hybrid storage not initialized")
 	}
 
+	// Invalidate cached reads even if storing fails part way through
+	defer func() { err = errors.Join(err, h.bumpGeneration()) }()
+
 	// First, delete any existing data for this file to handle updates
 	if err := h.DeleteFile(fileContext.Path); err != nil {
 		return fmt.Errorf("failed to delete existing file data: %w", err)
@@ -140,129 +222,17 @@ func (h *HybridStorage) storeChunk(chunk *models.SemanticChunk) error {
 	return nil
 }
 
-// indexFileData creates index entries for a single file's data
+// indexFileData creates index entries and call relations for a single file's data
 func (h *HybridStorage) indexFileData(fileData *models.FileContext, chunkID string) error {
-	if err := h.indexFunctions(fileData, chunkID); err != nil {
-		return err
-	}
-	if err := h.indexTypes(fileData, chunkID); err != nil {
-		return err
-	}
-	if err := h.indexVariables(fileData, chunkID); err != nil {
-		return err
-	}
-	if err := h.indexConstants(fileData, chunkID); err != nil {
-		return err
-	}
-	return nil
-}
-
-// indexFunctions creates index entries for functions and their call relations
-func (h *HybridStorage) indexFunctions(fileData *models.FileContext, chunkID string) error {
-	for i := range fileData.Functions {
-		function := &fileData.Functions[i]
-		entry := models.IndexEntry{
-			Name:      function.Name,
-			Type:      "function",
-			File:      fileData.Path,
-			StartLine: function.StartLine,
-			EndLine:   function.EndLine,
-			ChunkID:   chunkID,
-			Signature: function.Signature,
-		}
-		if err := h.sqliteIndex.InsertIndexEntry(&entry); err != nil {
-			return fmt.Errorf("failed to insert function index entry: %w", err)
-		}
-
-		// Index function calls
-		for _, call := range function.Calls {
-			relation := models.CallRelation{
-				Caller:     function.Name,
-				Callee:     call,
-				File:       fileData.Path,
-				Line:       function.StartLine, // Use function start line as call line
-				CallerFile: fileData.Path,
-			}
-			if err := h.sqliteIndex.InsertCallRelation(relation); err != nil {
-				return fmt.Errorf("failed to insert call relation: %w", err)
-			}
+	entries := fileIndexEntries(fileData, chunkID)
+	for i := range entries {
+		if err := h.sqliteIndex.InsertIndexEntry(&entries[i]); err != nil {
+			return fmt.Errorf("failed to insert %s index entry: %w", entries[i].Type, err)
 		}
 	}
-	return nil
-}
-
-// indexTypes creates index entries for type definitions
-func (h *HybridStorage) indexTypes(fileData *models.FileContext, chunkID string) error {
-	for i := range fileData.Types {
-		typeDef := &fileData.Types[i]
-		entry := models.IndexEntry{
-			Name:      typeDef.Name,
-			Type:      typeDef.Kind,
-			File:      fileData.Path,
-			StartLine: typeDef.StartLine,
-			EndLine:   typeDef.EndLine,
-			ChunkID:   chunkID,
-			Signature: h.buildTypeSignature(typeDef),
-		}
-		if err := h.sqliteIndex.InsertIndexEntry(&entry); err != nil {
-			return fmt.Errorf("failed to insert type index entry: %w", err)
-		}
-	}
-	return nil
-}
-
-// buildTypeSignature creates a signature string for a type definition
-func (h *HybridStorage) buildTypeSignature(typeDef *models.TypeDef) string {
-	if len(typeDef.Embedded) == 0 {
-		// No inheritance - just return the class name
-		return typeDef.Name
-	}
-
-	// Include inheritance information: ClassName(BaseClass1, BaseClass2)
-	signature := typeDef.Name + "("
-	for i, embedded := range typeDef.Embedded {
-		if i > 0 {
-			signature += ", "
-		}
-		signature += embedded
-	}
-	signature += ")"
-	return signature
-}
-
-// indexVariables creates index entries for variable declarations
-func (h *HybridStorage) indexVariables(fileData *models.FileContext, chunkID string) error {
-	for i := range fileData.Variables {
-		variable := &fileData.Variables[i]
-		entry := models.IndexEntry{
-			Name:      variable.Name,
-			Type:      "variable",
-			File:      fileData.Path,
-			StartLine: variable.StartLine,
-			EndLine:   variable.EndLine,
-			ChunkID:   chunkID,
-		}
-		if err := h.sqliteIndex.InsertIndexEntry(&entry); err != nil {
-			return fmt.Errorf("failed to insert variable index entry: %w", err)
-		}
-	}
-	return nil
-}
-
-// indexConstants creates index entries for constant declarations
-func (h *HybridStorage) indexConstants(fileData *models.FileContext, chunkID string) error {
-	for i := range fileData.Constants {
-		constant := &fileData.Constants[i]
-		entry := models.IndexEntry{
-			Name:      constant.Name,
-			Type:      "constant",
-			File:      fileData.Path,
-			StartLine: constant.StartLine,
-			EndLine:   constant.EndLine,
-			ChunkID:   chunkID,
-		}
-		if err := h.sqliteIndex.InsertIndexEntry(&entry); err != nil {
-			return fmt.Errorf("failed to insert constant index entry: %w", err)
+	for _, relation := range fileCallRelations(fileData) {
+		if err := h.sqliteIndex.InsertCallRelation(relation); err != nil {
+			return fmt.Errorf("failed to insert call relation: %w", err)
 		}
 	}
 	return nil
@@ -300,7 +270,22 @@ func (h *HybridStorage) QueryByName(name string) ([]QueryResult, error) {
 	return h.loadChunkDataForEntries(entries)
 }
 
-// QueryByType searches for entries by type and returns results with chunk data
+// QueryByNameCaseInsensitive searches for entries whose name equals name ignoring ASCII case
+func (h *HybridStorage) QueryByNameCaseInsensitive(name string) ([]QueryResult, error) {
+	if h.sqliteIndex == nil || h.chunkSerializer == nil {
+		return nil, fmt.Errorf("hybrid storage not initialized")
+	}
+
+	entries, err := h.sqliteIndex.QueryIndexEntriesCaseInsensitive(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query index entries: %w", err)
+	}
+
+	return h.loadChunkDataForEntries(entries)
+}
+
+// QueryByType searches for entries by type and returns results with chunk data,
+// ordered by file, then start line, then name
 func (h *HybridStorage) QueryByType(entryType string) ([]QueryResult, error) {
 	if h.sqliteIndex == nil || h.chunkSerializer == nil {
 		return nil, fmt.Errorf("hybrid storage not initialized")
@@ -315,6 +300,14 @@ func (h *HybridStorage) QueryByType(entryType string) ([]QueryResult, error) {
 	return h.loadChunkDataForEntries(entries)
 }
 
+// ListNameKinds returns every distinct name and entry type pair without loading chunk data
+func (h *HybridStorage) ListNameKinds() ([]models.IndexEntry, error) {
+	if h.sqliteIndex == nil {
+		return nil, fmt.Errorf("hybrid storage not initialized")
+	}
+	return h.sqliteIndex.QueryNameKinds()
+}
+
 // QueryCallsFrom returns functions called by the given function
 func (h *HybridStorage) QueryCallsFrom(functionName string) ([]models.CallRelation, error) {
 	if h.sqliteIndex == nil {
@@ -333,6 +326,35 @@ func (h *HybridStorage) QueryCallsTo(functionName string) ([]models.CallRelation
 	return h.sqliteIndex.QueryCallsTo(functionName)
 }
 
+// FunctionFiles returns the sorted, distinct files defining a function with the given name
+func (h *HybridStorage) FunctionFiles(functionName string) ([]string, error) {
+	if h.sqliteIndex == nil {
+		return nil, fmt.Errorf("hybrid storage not initialized")
+	}
+
+	entries, err := h.sqliteIndex.QueryIndexEntries(functionName)
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, entry := range entries {
+		if entry.Type == "function" {
+			files = append(files, entry.File)
+		}
+	}
+	slices.Sort(files)
+	return slices.Compact(files), nil
+}
+
+// QueryHotspots returns functions ranked by caller count, or by callee count when byCallees is set
+func (h *HybridStorage) QueryHotspots(byCallees bool, limit int) ([]HotspotEntry, error) {
+	if h.sqliteIndex == nil {
+		return nil, fmt.Errorf("hybrid storage not initialized")
+	}
+
+	return h.sqliteIndex.QueryHotspots(byCallees, limit)
+}
+
 // QueryCallsFromWithChunkData returns call relations with chunk data
 func (h *HybridStorage) QueryCallsFromWithChunkData(functionName string) ([]CallGraphResult, error) {
 	if h.sqliteIndex == nil || h.chunkSerializer == nil {
@@ -369,12 +391,53 @@ func (h *HybridStorage) QueryCallsFromWithChunkData(functionName string) ([]Call
 	return results, nil
 }
 
+// ListFiles returns the sorted paths of all files in storage
+func (h *HybridStorage) ListFiles() ([]string, error) {
+	if h.manifest == nil {
+		return nil, fmt.Errorf("hybrid storage not initialized")
+	}
+
+	var files []string
+	for _, chunkInfo := range h.manifest.Chunks {
+		files = append(files, chunkInfo.Files...)
+	}
+	slices.Sort(files)
+	return slices.Compact(files), nil
+}
+
+// GetFileContext loads the stored context for a single file
+func (h *HybridStorage) GetFileContext(filePath string) (*models.FileContext, error) {
+	if h.manifest == nil || h.chunkSerializer == nil {
+		return nil, fmt.Errorf("hybrid storage not initialized")
+	}
+
+	for chunkID, chunkInfo := range h.manifest.Chunks {
+		if !slices.Contains(chunkInfo.Files, filePath) {
+			continue
+		}
+
+		chunk, err := h.chunkSerializer.LoadChunk(chunkID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load chunk for %s: %w", filePath, err)
+		}
+		for i := range chunk.FileData {
+			if chunk.FileData[i].Path == filePath {
+				return &chunk.FileData[i], nil
+			}
+		}
+	}
+
+	return nil, fmt.Errorf("file %s not found in index", filePath)
+}
+
 // DeleteFile removes all data associated with a file
-func (h *HybridStorage) DeleteFile(filePath string) error {
+func (h *HybridStorage) DeleteFile(filePath string) (err error) {
 	if h.sqliteIndex == nil || h.chunkSerializer == nil {
 		return fmt.Errorf("hybrid storage not initialized")
 	}
 
+	defer func() { err = errors.Join(err, h.bumpGeneration()) }()
+
 	// Find chunks associated with this file
 	// For file-based chunking, we need to find the chunk ID for this file
 	// This is a simplified approach - in practice, we might need a more sophisticated lookup
@@ -437,6 +500,61 @@ func (h *HybridStorage) Close() error {
 	return nil
 }
 
+// RecordBuild appends a completed build to the manifest build history, keeping
+// only the most recent maxBuildHistory builds
+func (h *HybridStorage) RecordBuild(record *models.BuildRecord) (err error) {
+	if h.manifest == nil {
+		return fmt.Errorf("manifest is nil")
+	}
+
+	defer func() { err = errors.Join(err, h.bumpGeneration()) }()
+
+	h.manifest.BuildHistory = append(h.manifest.BuildHistory, *record)
+	if excess := len(h.manifest.BuildHistory) - maxBuildHistory; excess > 0 {
+		h.manifest.BuildHistory = slices.Delete(h.manifest.BuildHistory, 0, excess)
+	}
+
+	return h.saveManifest()
+}
+
+// BuildHistory returns the recorded builds, oldest first
+func (h *HybridStorage) BuildHistory() []models.BuildRecord {
+	if h.manifest == nil {
+		return nil
+	}
+	return slices.Clone(h.manifest.BuildHistory)
+}
+
+// PathFormat returns how the stored file paths are formatted, see models.Manifest
+func (h *HybridStorage) PathFormat() string {
+	if h.manifest == nil {
+		return ""
+	}
+	return h.manifest.PathFormat
+}
+
+// SetPathFormat records how the stored file paths are formatted
+func (h *HybridStorage) SetPathFormat(format string) error {
+	if h.manifest == nil {
+		return fmt.Errorf("manifest is nil")
+	}
+	if h.manifest.PathFormat == format {
+		return nil
+	}
+	h.manifest.PathFormat = format
+	return h.saveManifest()
+}
+
+// ResolvePath returns where a stored file path is found on disk. Repo-relative paths
+// are resolved against the repository holding the .repocontext directory; paths of
+// older indexes are returned unchanged.
+func (h *HybridStorage) ResolvePath(file string) string {
+	if h.PathFormat() != models.PathFormatRepoRelative || filepath.IsAbs(file) {
+		return file
+	}
+	return filepath.Join(filepath.Dir(h.baseDir), file)
+}
+
 // loadManifest loads the manifest from disk or creates a new one
 func (h *HybridStorage) loadManifest() error {
 	// Try to load existing manifest
@@ -557,6 +675,8 @@ func (h *HybridStorage) GetFunctionImplementation(functionName string, contextLi
 type FunctionImplementation struct {
 	Body         string   `json:"body"`
 	ContextLines []string `json:"context_lines"`
+	Source       string   `json:"source,omitempty"` // SourceIndex or SourceWorkingTree
+	Stale        bool     `json:"stale,omitempty"`  // The file changed since indexing and no source was stored
 }
 
 // extractFunctionFromSource reads the source file and extracts the function body and context
@@ -575,8 +695,8 @@ func (h *HybridStorage) extractFunctionFromSource(entry *QueryResult, contextLin
 		}, nil
 	}
 
-	// Read the source file
-	content, err := os.ReadFile(filePath) // #nosec G304 - File path comes from our indexed data
+	// Load the indexed or current source of the file
+	content, source, stale, err := h.loadFunctionSource(entry)
 	if err != nil {
 		return &FunctionImplementation{
 			Body: fmt.Sprintf("// Function implementation unavailable: %v", err),
@@ -587,7 +707,7 @@ func (h *HybridStorage) extractFunctionFromSource(entry *QueryResult, contextLin
 	}
 
 	// Split content into lines
-	lines := strings.Split(string(content), "\n")
+	lines := splitSourceLines(content)
 
 	// Validate line numbers against actual file content
 	if startLine > len(lines) || endLine > len(lines) {
@@ -646,5 +766,42 @@ func (h *HybridStorage) extractFunctionFromSource(entry *QueryResult, contextLin
 	return &FunctionImplementation{
 		Body:         functionBody,
 		ContextLines: contextLinesResult,
+		Source:       source,
+		Stale:        stale,
 	}, nil
 }
+
+// loadFunctionSource returns the content the entry's line numbers refer to. The working
+// tree is used while it still matches the indexed checksum, then the source stored in
+// the index, and finally the changed working tree file, which is reported as stale.
+func (h *HybridStorage) loadFunctionSource(entry *QueryResult) (content []byte, source string, stale bool, err error) {
+	var fileContext *models.FileContext
+	if entry.ChunkData != nil {
+		for i := range entry.ChunkData.FileData {
+			if entry.ChunkData.FileData[i].Path == entry.IndexEntry.File {
+				fileContext = &entry.ChunkData.FileData[i]
+				break
+			}
+		}
+	}
+
+	content, readErr := os.ReadFile(h.ResolvePath(entry.IndexEntry.File)) // #nosec G304 - File path comes from our indexed data
+	if readErr == nil && (fileContext == nil || fileContext.Checksum == "" || sourceChecksum(content) == fileContext.Checksum) {
+		return content, SourceWorkingTree, false, nil
+	}
+
+	if fileContext != nil {
+		stored, storedErr := storedSource(fileContext)
+		if storedErr != nil && readErr != nil {
+			return nil, "", false, storedErr
+		}
+		if stored != nil {
+			return stored, SourceIndex, false, nil
+		}
+	}
+
+	if readErr != nil {
+		return nil, "", false, readErr
+	}
+	return content, SourceWorkingTree, true, nil
+}
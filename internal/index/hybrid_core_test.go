@@ -3,6 +3,7 @@ package index
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -275,6 +276,35 @@ func TestHybridStorage_QueryByType(t *testing.T) {
 	}
 }
 
+func TestHybridStorage_QueryByTypeOrder(t *testing.T) {
+	storage := newDiffTestStorage(t,
+		&models.FileContext{Path: "zeta.go", Language: "go", Functions: []models.Function{
+			{Name: "Later", StartLine: 20}, {Name: "Earlier", StartLine: 5},
+		}},
+		&models.FileContext{Path: "alpha.go", Language: "go", Functions: []models.Function{
+			{Name: "Second", StartLine: 9}, {Name: "Beta", StartLine: 3}, {Name: "Alpha", StartLine: 3},
+		}},
+	)
+
+	var orders [2][]string
+	for run := range orders {
+		results, err := storage.QueryByType("function")
+		if err != nil {
+			t.Fatalf("Failed to query by type: %v", err)
+		}
+		for _, result := range results {
+			orders[run] = append(orders[run], result.IndexEntry.File+":"+result.IndexEntry.Name)
+		}
+	}
+
+	expected := "alpha.go:Alpha,alpha.go:Beta,alpha.go:Second,zeta.go:Earlier,zeta.go:Later"
+	for run, order := range orders {
+		if got := strings.Join(order, ","); got != expected {
+			t.Errorf("Run %d: expected order by file, line and name %s, got %s", run+1, expected, got)
+		}
+	}
+}
+
 func TestHybridStorage_DeleteFile(t *testing.T) {
 	// Create temporary directory for testing
 	tempDir, err := os.MkdirTemp("", "hybrid_test")
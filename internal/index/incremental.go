@@ -0,0 +1,273 @@
+package index
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"slices"
+
+	"repository-context-protocol/internal/models"
+)
+
+// ReindexFile reparses a single file and updates the call graph edges that touch it
+// without rebuilding the rest of the index. Outbound calls of the file are recomputed
+// from the new parse, inbound calls from unchanged files are preserved, and the
+// CrossFileCallers of functions the file used to call, or now calls, are patched.
+// Methods declared in other files of its package are linked to its types again, and
+// its methods to types of other files, as in a full build.
+func (ib *IndexBuilder) ReindexFile(filePath string) error {
+	if ib.storage == nil {
+		return fmt.Errorf("index builder not initialized")
+	}
+
+	fileContext, err := ib.parseFile(filePath)
+	if err != nil || fileContext == nil {
+		return err
+	}
+
+	oldContext, err := ib.storedFileContext(fileContext.Path)
+	if err != nil {
+		return err
+	}
+
+	enrichment, err := ib.incrementalEnrichment(fileContext, oldContext)
+	if err != nil {
+		return err
+	}
+	enriched := enrichment.enrichSingleFileContext(fileContext)
+
+	// Files defining a function the old or new version calls may hold stale callers
+	targetFiles, err := ib.calleeFiles(fileContext.Path, oldContext, &enriched)
+	if err != nil {
+		return err
+	}
+
+	if err := ib.storage.StoreFileContext(&enriched); err != nil {
+		return fmt.Errorf("failed to store file context: %w", err)
+	}
+	ib.updateStatistics(&enriched)
+
+	for _, targetFile := range targetFiles {
+		if err := ib.patchCrossFileCallers(targetFile, &enriched); err != nil {
+			return err
+		}
+	}
+
+	if oldContext != nil && oldContext.Package != enriched.Package {
+		if err := ib.relinkPackage(filepath.Dir(oldContext.Path), oldContext.Package); err != nil {
+			return err
+		}
+	}
+	return ib.relinkPackage(filepath.Dir(enriched.Path), enriched.Package)
+}
+
+// relinkPackage recomputes the links between the stored files of a package that a full
+// build makes across files: methods attached to receiver types declared in another file,
+// and the members promoted through embedded types. Links made before are dropped first,
+// so methods moved or removed do not linger, and only files whose types change are
+// stored again.
+func (ib *IndexBuilder) relinkPackage(dir, packageName string) error {
+	files, err := ib.storage.ListFiles()
+	if err != nil {
+		return fmt.Errorf("failed to list indexed files: %w", err)
+	}
+
+	var stored, contexts []models.FileContext
+	for _, file := range files {
+		if filepath.Dir(file) != dir {
+			continue
+		}
+		fileContext, err := ib.storage.GetFileContext(file)
+		if err != nil {
+			return fmt.Errorf("failed to load %s: %w", file, err)
+		}
+		if fileContext.Package != packageName {
+			continue
+		}
+		stored = append(stored, *fileContext)
+		contexts = append(contexts, unlinkedFileContext(fileContext))
+	}
+
+	linkMethodReceivers(contexts)
+	linkPromotedMembers(contexts)
+
+	for i := range contexts {
+		if reflect.DeepEqual(contexts[i].Types, stored[i].Types) {
+			continue
+		}
+		if err := ib.storage.StoreFileContext(&contexts[i]); err != nil {
+			return fmt.Errorf("failed to store %s: %w", contexts[i].Path, err)
+		}
+	}
+	return nil
+}
+
+// unlinkedFileContext returns a copy of a stored file context without the methods
+// linkMethodReceivers attached from other files, which record their declaring file,
+// and without the members linkPromotedMembers recorded
+func unlinkedFileContext(stored *models.FileContext) models.FileContext {
+	fileContext := *stored
+	fileContext.Types = slices.Clone(stored.Types)
+	for i := range fileContext.Types {
+		fileContext.Types[i].Methods = slices.DeleteFunc(slices.Clone(stored.Types[i].Methods), func(method models.Method) bool {
+			return method.File != "" && method.File != stored.Path
+		})
+		fileContext.Types[i].PromotedFields = nil
+		fileContext.Types[i].PromotedMethods = nil
+	}
+	return fileContext
+}
+
+// storedFileContext returns the indexed context of a file, or nil when it is not indexed
+func (ib *IndexBuilder) storedFileContext(path string) (*models.FileContext, error) {
+	files, err := ib.storage.ListFiles()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list indexed files: %w", err)
+	}
+	if !slices.Contains(files, path) {
+		return nil, nil
+	}
+	return ib.storage.GetFileContext(path)
+}
+
+// incrementalEnrichment prepares a GlobalEnrichment that resolves the file's callees
+// through the stored index and knows about callers in other files
+func (ib *IndexBuilder) incrementalEnrichment(fileContext, oldContext *models.FileContext) (*GlobalEnrichment, error) {
+	enrichment := NewGlobalEnrichment()
+	if err := enrichment.globalCallGraph.BuildFromFiles([]models.FileContext{*fileContext}); err != nil {
+		return nil, err
+	}
+
+	for i := range fileContext.Functions {
+		enrichment.functionToFile[fileContext.Functions[i].Name] = fileContext.Path
+	}
+	for _, callee := range functionCallees(fileContext) {
+		if _, local := enrichment.functionToFile[callee]; local {
+			continue
+		}
+		files, err := ib.storage.FunctionFiles(callee)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve %s: %w", callee, err)
+		}
+		files = slices.DeleteFunc(files, func(file string) bool { return file == fileContext.Path })
+		if len(files) > 0 {
+			enrichment.functionToFile[callee] = files[0]
+		}
+	}
+
+	for _, relation := range ib.inboundRelations(fileContext, oldContext) {
+		enrichment.globalCallGraph.addRelation(relation)
+	}
+	return enrichment, nil
+}
+
+// inboundRelations returns the calls from other files into the file's functions. Callers
+// recorded in the previous version keep their call lines; the stored call index adds
+// callers of functions the previous version did not define.
+func (ib *IndexBuilder) inboundRelations(fileContext, oldContext *models.FileContext) []models.CallRelation {
+	var relations []models.CallRelation
+	for i := range fileContext.Functions {
+		name := fileContext.Functions[i].Name
+		known := make(map[string]bool)
+
+		if oldContext != nil {
+			for j := range oldContext.Functions {
+				if oldContext.Functions[j].Name != name {
+					continue
+				}
+				for _, caller := range oldContext.Functions[j].CrossFileCallers {
+					known[caller.File+":"+caller.FunctionName] = true
+					relations = append(relations, models.CallRelation{
+						Caller: caller.FunctionName, Callee: name, File: caller.File, CallerFile: caller.File, Line: caller.Line,
+					})
+				}
+			}
+		}
+
+		stored, err := ib.storage.QueryCallsTo(name)
+		if err != nil {
+			continue
+		}
+		for _, relation := range stored {
+			if relation.CallerFile == fileContext.Path || known[relation.CallerFile+":"+relation.Caller] {
+				continue
+			}
+			known[relation.CallerFile+":"+relation.Caller] = true
+			relations = append(relations, relation)
+		}
+	}
+	return relations
+}
+
+// calleeFiles returns the sorted files, other than path, defining a function called
+// by the old or new version of the file
+func (ib *IndexBuilder) calleeFiles(path string, oldContext, newContext *models.FileContext) ([]string, error) {
+	callees := functionCallees(newContext)
+	if oldContext != nil {
+		callees = append(callees, functionCallees(oldContext)...)
+	}
+	slices.Sort(callees)
+
+	var files []string
+	for _, callee := range slices.Compact(callees) {
+		calleeFiles, err := ib.storage.FunctionFiles(callee)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve %s: %w", callee, err)
+		}
+		for _, file := range calleeFiles {
+			if file != path {
+				files = append(files, file)
+			}
+		}
+	}
+	slices.Sort(files)
+	return slices.Compact(files), nil
+}
+
+// patchCrossFileCallers replaces the callers from the changed file recorded on the
+// functions of targetFile with the calls the changed file now makes into it
+func (ib *IndexBuilder) patchCrossFileCallers(targetFile string, changed *models.FileContext) error {
+	stored, err := ib.storage.GetFileContext(targetFile)
+	if err != nil {
+		return fmt.Errorf("failed to load %s: %w", targetFile, err)
+	}
+
+	target := *stored
+	target.Functions = slices.Clone(stored.Functions)
+	for i := range target.Functions {
+		function := &target.Functions[i]
+		callers := slices.DeleteFunc(slices.Clone(function.CrossFileCallers), func(caller models.CallReference) bool {
+			return caller.File == changed.Path
+		})
+		for j := range changed.Functions {
+			for _, call := range changed.Functions[j].CrossFileCalls {
+				if call.File == targetFile && call.FunctionName == function.Name {
+					callers = append(callers, models.CallReference{
+						FunctionName: changed.Functions[j].Name,
+						File:         changed.Path,
+						Line:         call.Line,
+					})
+				}
+			}
+		}
+		function.CrossFileCallers = callers
+	}
+
+	if err := ib.storage.StoreFileContext(&target); err != nil {
+		return fmt.Errorf("failed to store %s: %w", targetFile, err)
+	}
+	return nil
+}
+
+// functionCallees returns the names of all functions called from the file
+func functionCallees(fileContext *models.FileContext) []string {
+	var callees []string
+	for i := range fileContext.Functions {
+		function := &fileContext.Functions[i]
+		for _, call := range function.LocalCallsWithMetadata {
+			callees = append(callees, call.FunctionName)
+		}
+		callees = append(callees, function.GetAllCalls()...)
+	}
+	return callees
+}
@@ -0,0 +1,256 @@
+package index
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"repository-context-protocol/internal/models"
+)
+
+// findStoredFunction loads a file from the builder's storage and returns the named function
+func findStoredFunction(t *testing.T, builder *IndexBuilder, path, name string) *models.Function {
+	t.Helper()
+
+	fileContext, err := builder.storage.GetFileContext(builder.repoRelative(path))
+	if err != nil {
+		t.Fatalf("Failed to load %s: %v", path, err)
+	}
+	for i := range fileContext.Functions {
+		if fileContext.Functions[i].Name == name {
+			return &fileContext.Functions[i]
+		}
+	}
+	t.Fatalf("Function %s not found in %s", name, path)
+	return nil
+}
+
+func TestIndexBuilder_ReindexFileUpdatesCallGraph(t *testing.T) {
+	tempDir := t.TempDir()
+	files := map[string]string{
+		"main.go": `package main
+
+func main() {
+	user := CreateUser("John")
+	ProcessUser(user)
+}`,
+		"user.go": `package main
+
+func CreateUser(name string) *User {
+	return &User{Name: name}
+}
+
+func ProcessUser(user *User) {
+	ValidateUser(user)
+}
+
+func ValidateUser(user *User) bool {
+	return user.Name != ""
+}
+
+func SaveUser(user *User) error {
+	return nil
+}
+
+type User struct {
+	Name string
+}`,
+		"helpers.go": `package main
+
+func Helper() {
+	ProcessUser(&User{Name: "Helper"})
+}`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(tempDir, name), []byte(content), 0600); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	builder := NewIndexBuilder(tempDir)
+	if err := builder.Initialize(); err != nil {
+		t.Fatalf("Failed to initialize builder: %v", err)
+	}
+	defer builder.Close()
+	if _, err := builder.BuildIndex(); err != nil {
+		t.Fatalf("Failed to build index: %v", err)
+	}
+
+	mainPath := filepath.Join(tempDir, "main.go")
+	userPath := filepath.Join(tempDir, "user.go")
+
+	// main stops calling ProcessUser and calls SaveUser instead
+	if err := os.WriteFile(mainPath, []byte(`package main
+
+func main() {
+	user := CreateUser("John")
+	SaveUser(user)
+}`), 0600); err != nil {
+		t.Fatalf("Failed to edit main.go: %v", err)
+	}
+	if err := builder.ReindexFile(mainPath); err != nil {
+		t.Fatalf("Failed to reindex main.go: %v", err)
+	}
+
+	processUser := findStoredFunction(t, builder, userPath, "ProcessUser")
+	if len(processUser.GetCallersFromFile("main.go")) != 0 {
+		t.Errorf("Expected main to be removed from ProcessUser callers, got %+v", processUser.CrossFileCallers)
+	}
+	if len(processUser.GetCallersFromFile("helpers.go")) != 1 {
+		t.Errorf("Expected Helper to remain a ProcessUser caller, got %+v", processUser.CrossFileCallers)
+	}
+
+	saveUser := findStoredFunction(t, builder, userPath, "SaveUser")
+	callers := saveUser.GetCallersFromFile("main.go")
+	if len(callers) != 1 || callers[0].FunctionName != "main" || callers[0].Line != 5 {
+		t.Errorf("Expected main to call SaveUser from line 5, got %+v", saveUser.CrossFileCallers)
+	}
+	if !findStoredFunction(t, builder, userPath, "CreateUser").HasCaller("main") {
+		t.Error("Expected main to remain a CreateUser caller")
+	}
+
+	mainFunction := findStoredFunction(t, builder, mainPath, "main")
+	if len(mainFunction.GetCallsInFile("user.go")) != 2 || !mainFunction.HasCall("SaveUser") || mainFunction.HasCall("ProcessUser") {
+		t.Errorf("Expected main to call CreateUser and SaveUser in user.go, got %+v", mainFunction.CrossFileCalls)
+	}
+
+	// Reindexing the callee file keeps calls into it from unchanged files
+	if err := builder.ReindexFile(userPath); err != nil {
+		t.Fatalf("Failed to reindex user.go: %v", err)
+	}
+	processUser = findStoredFunction(t, builder, userPath, "ProcessUser")
+	if !processUser.HasCaller("Helper") || processUser.HasCaller("main") {
+		t.Errorf("Expected only Helper to call ProcessUser after reindexing user.go, got %+v", processUser.CrossFileCallers)
+	}
+	if !findStoredFunction(t, builder, userPath, "SaveUser").HasCaller("main") {
+		t.Error("Expected main to remain a SaveUser caller after reindexing user.go")
+	}
+	if !findStoredFunction(t, builder, userPath, "ValidateUser").HasCaller("ProcessUser") {
+		t.Error("Expected the local ProcessUser -> ValidateUser edge to be rebuilt")
+	}
+}
+
+// findStoredType loads a file from the builder's storage and returns the named type
+func findStoredType(t *testing.T, builder *IndexBuilder, path, name string) *models.TypeDef {
+	t.Helper()
+
+	fileContext, err := builder.storage.GetFileContext(builder.repoRelative(path))
+	if err != nil {
+		t.Fatalf("Failed to load %s: %v", path, err)
+	}
+	for i := range fileContext.Types {
+		if fileContext.Types[i].Name == name {
+			return &fileContext.Types[i]
+		}
+	}
+	t.Fatalf("Type %s not found in %s", name, path)
+	return nil
+}
+
+// methodNames lists the names of a type's methods
+func methodNames(methods []models.Method) []string {
+	names := []string{}
+	for i := range methods {
+		names = append(names, methods[i].Name)
+	}
+	return names
+}
+
+func TestIndexBuilder_ReindexFileKeepsMethodsFromOtherFiles(t *testing.T) {
+	tempDir := t.TempDir()
+	typesPath := filepath.Join(tempDir, "types.go")
+	methodsPath := filepath.Join(tempDir, "methods.go")
+	writeFile := func(path, content string) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatalf("Failed to write %s: %v", path, err)
+		}
+	}
+	writeFile(typesPath, "package models\n\ntype User struct {\n\tName string\n}\n")
+	writeFile(methodsPath, "package models\n\nfunc (u *User) Activate() {}\n")
+
+	builder := NewIndexBuilder(tempDir)
+	if err := builder.Initialize(); err != nil {
+		t.Fatalf("Failed to initialize builder: %v", err)
+	}
+	defer builder.Close()
+	if _, err := builder.BuildIndex(); err != nil {
+		t.Fatalf("Failed to build index: %v", err)
+	}
+	if methods := methodNames(findStoredType(t, builder, typesPath, "User").Methods); !slices.Equal(methods, []string{"Activate"}) {
+		t.Fatalf("Expected User to have Activate after a full build, got %v", methods)
+	}
+
+	// Reindexing the type's file links the methods of other files again
+	writeFile(typesPath, "package models\n\n// User is an account\ntype User struct {\n\tName string\n}\n")
+	if err := builder.ReindexFile(typesPath); err != nil {
+		t.Fatalf("Failed to reindex types.go: %v", err)
+	}
+	user := findStoredType(t, builder, typesPath, "User")
+	if methods := methodNames(user.Methods); !slices.Equal(methods, []string{"Activate"}) {
+		t.Errorf("Expected Activate to survive reindexing types.go, got %v", methods)
+	} else if user.Methods[0].File != "methods.go" {
+		t.Errorf("Expected Activate to record methods.go, got %q", user.Methods[0].File)
+	}
+
+	// Reindexing the methods' file replaces the methods it linked before
+	writeFile(methodsPath, "package models\n\nfunc (u *User) Enable() {}\n")
+	if err := builder.ReindexFile(methodsPath); err != nil {
+		t.Fatalf("Failed to reindex methods.go: %v", err)
+	}
+	if methods := methodNames(findStoredType(t, builder, typesPath, "User").Methods); !slices.Equal(methods, []string{"Enable"}) {
+		t.Errorf("Expected only Enable after reindexing methods.go, got %v", methods)
+	}
+}
+
+func TestIndexBuilder_ReindexFileRecomputesPromotedMembers(t *testing.T) {
+	tempDir := t.TempDir()
+	basePath := filepath.Join(tempDir, "base.go")
+	adminPath := filepath.Join(tempDir, "admin.go")
+	writeFile := func(path, content string) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatalf("Failed to write %s: %v", path, err)
+		}
+	}
+	writeFile(basePath, "package models\n\ntype Base struct {\n\tID int\n}\n\nfunc (b Base) Hello() {}\n")
+	writeFile(adminPath, "package models\n\ntype Admin struct {\n\tBase\n\tLevel int\n}\n")
+
+	builder := NewIndexBuilder(tempDir)
+	if err := builder.Initialize(); err != nil {
+		t.Fatalf("Failed to initialize builder: %v", err)
+	}
+	defer builder.Close()
+	if _, err := builder.BuildIndex(); err != nil {
+		t.Fatalf("Failed to build index: %v", err)
+	}
+
+	assertPromoted := func(step string, fields, methods []string) {
+		t.Helper()
+		admin := findStoredType(t, builder, adminPath, "Admin")
+		var promotedFields []string
+		for _, field := range admin.PromotedFields {
+			promotedFields = append(promotedFields, field.Name)
+		}
+		if !slices.Equal(promotedFields, fields) || !slices.Equal(methodNames(admin.PromotedMethods), methods) {
+			t.Errorf("%s: expected promoted fields %v and methods %v, got %v and %v",
+				step, fields, methods, promotedFields, methodNames(admin.PromotedMethods))
+		}
+	}
+	assertPromoted("full build", []string{"ID"}, []string{"Hello"})
+
+	// Reindexing the embedding type's file promotes the embedded members again
+	writeFile(adminPath, "package models\n\n// Admin is a privileged user\ntype Admin struct {\n\tBase\n\tLevel int\n}\n")
+	if err := builder.ReindexFile(adminPath); err != nil {
+		t.Fatalf("Failed to reindex admin.go: %v", err)
+	}
+	assertPromoted("reindexing admin.go", []string{"ID"}, []string{"Hello"})
+
+	// Reindexing the embedded type's file updates the types embedding it
+	writeFile(basePath, "package models\n\ntype Base struct {\n\tID   int\n\tName string\n}\n")
+	if err := builder.ReindexFile(basePath); err != nil {
+		t.Fatalf("Failed to reindex base.go: %v", err)
+	}
+	assertPromoted("reindexing base.go", []string{"ID", "Name"}, []string{})
+}
@@ -0,0 +1,62 @@
+package index
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+)
+
+// JSON Lines record kinds
+const (
+	JSONLinesKindEntry   = "entry"
+	JSONLinesKindSummary = "summary"
+)
+
+// JSONLinesEntry is the schema of each result line in jsonl output:
+// a SearchResultEntry with a "kind" of "entry" alongside index_entry and chunk_data.
+type JSONLinesEntry struct {
+	Kind string `json:"kind"`
+	SearchResultEntry
+}
+
+// JSONLinesSummary is the schema of the final line in jsonl output.
+// It describes the whole result so consumers can detect truncation without buffering entries.
+type JSONLinesSummary struct {
+	Kind       string         `json:"kind"`                 // Always "summary"
+	Query      string         `json:"query"`                // Original search query
+	SearchType string         `json:"search_type"`          // Type of search performed
+	Count      int            `json:"count"`                // Number of entry lines written
+	TokenCount int            `json:"token_count"`          // Estimated token count
+	Truncated  bool           `json:"truncated"`            // Whether results were truncated
+	Facets     map[string]int `json:"facets,omitempty"`     // Entry count per entity type before truncation
+	CallGraph  *CallGraphInfo `json:"call_graph,omitempty"` // Call graph information
+
+	IndexGeneration uint64    `json:"index_generation"` // Storage generation the result was computed from
+	IndexBuildTime  time.Time `json:"index_build_time"` // When the index was last written
+}
+
+// WriteJSONLines streams a result as newline-delimited JSON: one compact JSON object per
+// entry followed by a single summary line. Each line is written as soon as it is encoded.
+func WriteJSONLines(w io.Writer, result *SearchResult) error {
+	encoder := json.NewEncoder(w)
+
+	for _, entry := range result.Entries {
+		if err := encoder.Encode(JSONLinesEntry{Kind: JSONLinesKindEntry, SearchResultEntry: entry}); err != nil {
+			return err
+		}
+	}
+
+	return encoder.Encode(JSONLinesSummary{
+		Kind:       JSONLinesKindSummary,
+		Query:      result.Query,
+		SearchType: result.SearchType,
+		Count:      len(result.Entries),
+		TokenCount: result.TokenCount,
+		Truncated:  result.Truncated,
+		Facets:     result.Facets,
+		CallGraph:  result.CallGraph,
+
+		IndexGeneration: result.IndexGeneration,
+		IndexBuildTime:  result.IndexBuildTime,
+	})
+}
@@ -0,0 +1,336 @@
+package index
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/vmihailenco/msgpack/v5"
+
+	"repository-context-protocol/internal/models"
+)
+
+// MemoryStorage keeps an index in memory. It answers queries like HybridStorage without
+// touching disk, which suits tests and ephemeral tooling; the index is lost when the
+// process exits.
+type MemoryStorage struct {
+	mu               sync.RWMutex
+	chunkingStrategy ChunkingStrategy
+	files            map[string]*memoryFile
+	order            []string // File paths in the order they were stored
+	generation       atomic.Uint64
+	updatedAt        time.Time
+}
+
+// memoryFile holds the stored data of one file
+type memoryFile struct {
+	chunkData []byte // MessagePack encoded chunk, decoded afresh for every reader
+	entries   []models.IndexEntry
+	calls     []models.CallRelation
+}
+
+// NewMemoryStorage creates an empty in-memory storage, ready for use without Initialize
+func NewMemoryStorage() *MemoryStorage {
+	return &MemoryStorage{
+		chunkingStrategy: &FileBasedChunking{},
+		files:            make(map[string]*memoryFile),
+	}
+}
+
+// Generation returns a counter that changes whenever the stored data may have changed
+func (m *MemoryStorage) Generation() uint64 {
+	return m.generation.Load()
+}
+
+// UpdatedAt returns when a file was last stored or deleted
+func (m *MemoryStorage) UpdatedAt() time.Time {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.updatedAt
+}
+
+// Initialize is a no-op kept for parity with HybridStorage; stored data is retained
+func (m *MemoryStorage) Initialize() error {
+	m.generation.Add(1)
+	return nil
+}
+
+// Close is a no-op; the stored data remains queryable
+func (m *MemoryStorage) Close() error {
+	return nil
+}
+
+// StoreFileContext indexes a file, replacing any data stored for the same path
+func (m *MemoryStorage) StoreFileContext(fileContext *models.FileContext) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	defer m.generation.Add(1)
+
+	m.updatedAt = time.Now()
+	m.deleteFile(fileContext.Path)
+
+	for _, chunk := range m.chunkingStrategy.CreateChunks([]models.FileContext{*fileContext}) {
+		data, err := msgpack.Marshal(&chunk)
+		if err != nil {
+			return fmt.Errorf("failed to encode chunk %s: %w", chunk.ID, err)
+		}
+		for i := range chunk.FileData {
+			fileData := &chunk.FileData[i]
+			m.files[fileData.Path] = &memoryFile{
+				chunkData: data,
+				entries:   fileIndexEntries(fileData, chunk.ID),
+				calls:     fileCallRelations(fileData),
+			}
+			m.order = append(m.order, fileData.Path)
+		}
+	}
+	return nil
+}
+
+// DeleteFile removes all data associated with a file
+func (m *MemoryStorage) DeleteFile(filePath string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	defer m.generation.Add(1)
+
+	m.updatedAt = time.Now()
+	m.deleteFile(filePath)
+	return nil
+}
+
+// deleteFile removes a file's data; the caller holds the write lock
+func (m *MemoryStorage) deleteFile(filePath string) {
+	if _, ok := m.files[filePath]; !ok {
+		return
+	}
+	delete(m.files, filePath)
+	m.order = slices.DeleteFunc(m.order, func(path string) bool { return path == filePath })
+}
+
+// queryEntries returns the entries accepted by match, in storage order, with chunk data
+func (m *MemoryStorage) queryEntries(match func(*models.IndexEntry) bool) ([]QueryResult, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	results := []QueryResult{}
+	for _, path := range m.order {
+		file := m.files[path]
+		for i := range file.entries {
+			entry := &file.entries[i]
+			if !match(entry) {
+				continue
+			}
+			chunk, err := file.chunk()
+			if err != nil {
+				return nil, fmt.Errorf("failed to load chunk %s: %w", entry.ChunkID, err)
+			}
+			results = append(results, QueryResult{IndexEntry: *entry, ChunkData: chunk})
+		}
+	}
+	return results, nil
+}
+
+// chunk decodes a copy of the file's chunk
+func (f *memoryFile) chunk() (*models.SemanticChunk, error) {
+	var chunk models.SemanticChunk
+	if err := msgpack.Unmarshal(f.chunkData, &chunk); err != nil {
+		return nil, err
+	}
+	return &chunk, nil
+}
+
+// QueryByName searches for entries by name and returns results with chunk data
+func (m *MemoryStorage) QueryByName(name string) ([]QueryResult, error) {
+	return m.queryEntries(func(entry *models.IndexEntry) bool { return entry.Name == name })
+}
+
+// QueryByNameCaseInsensitive searches for entries whose name equals name ignoring ASCII case
+func (m *MemoryStorage) QueryByNameCaseInsensitive(name string) ([]QueryResult, error) {
+	return m.queryEntries(func(entry *models.IndexEntry) bool { return equalFoldASCII(entry.Name, name) })
+}
+
+// QueryByType searches for entries by type and returns results with chunk data,
+// ordered by file, then start line, then name
+func (m *MemoryStorage) QueryByType(entryType string) ([]QueryResult, error) {
+	results, err := m.queryEntries(func(entry *models.IndexEntry) bool { return entry.Type == entryType })
+	if err != nil {
+		return nil, err
+	}
+	slices.SortStableFunc(results, func(a, b QueryResult) int {
+		return cmp.Or(
+			strings.Compare(a.IndexEntry.File, b.IndexEntry.File),
+			cmp.Compare(a.IndexEntry.StartLine, b.IndexEntry.StartLine),
+			strings.Compare(a.IndexEntry.Name, b.IndexEntry.Name),
+		)
+	})
+	return results, nil
+}
+
+// ListNameKinds returns every distinct name and entry type pair without loading chunk data
+func (m *MemoryStorage) ListNameKinds() ([]models.IndexEntry, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	type nameKind struct{ name, kind string }
+	seen := make(map[nameKind]bool)
+	var entries []models.IndexEntry
+	for _, path := range m.order {
+		for _, entry := range m.files[path].entries {
+			key := nameKind{entry.Name, entry.Type}
+			if !seen[key] {
+				seen[key] = true
+				entries = append(entries, models.IndexEntry{Name: entry.Name, Type: entry.Type})
+			}
+		}
+	}
+	return entries, nil
+}
+
+// queryCalls returns the call relations accepted by match, in storage order
+func (m *MemoryStorage) queryCalls(match func(*models.CallRelation) bool) []models.CallRelation {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var relations []models.CallRelation
+	for _, path := range m.order {
+		for _, relation := range m.files[path].calls {
+			if match(&relation) {
+				relations = append(relations, relation)
+			}
+		}
+	}
+	return relations
+}
+
+// QueryCallsFrom returns functions called by the given function
+func (m *MemoryStorage) QueryCallsFrom(functionName string) ([]models.CallRelation, error) {
+	return m.queryCalls(func(relation *models.CallRelation) bool { return relation.Caller == functionName }), nil
+}
+
+// QueryCallsTo returns functions that call the given function
+func (m *MemoryStorage) QueryCallsTo(functionName string) ([]models.CallRelation, error) {
+	return m.queryCalls(func(relation *models.CallRelation) bool { return relation.Callee == functionName }), nil
+}
+
+// QueryHotspots returns functions ranked by caller count, or by callee count when byCallees
+// is set, counting and ordering them the same way as the SQLite index
+func (m *MemoryStorage) QueryHotspots(byCallees bool, limit int) ([]HotspotEntry, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	callers := make(map[string]map[string]bool) // Callee to distinct caller file and name
+	callees := make(map[string]map[string]bool) // Caller file and name to distinct callees
+	for _, file := range m.files {
+		for _, relation := range file.calls {
+			caller := relation.CallerFile + ":" + relation.Caller
+			addToSet(callers, relation.Callee, caller)
+			addToSet(callees, caller, relation.Callee)
+		}
+	}
+
+	hotspots := []HotspotEntry{}
+	for _, file := range m.files {
+		for _, entry := range file.entries {
+			if entry.Type != "function" {
+				continue
+			}
+			hotspot := HotspotEntry{
+				Function:    entry.Name,
+				File:        entry.File,
+				Line:        entry.StartLine,
+				CallerCount: len(callers[entry.Name]),
+				CalleeCount: len(callees[entry.File+":"+entry.Name]),
+			}
+			if rankCount(&hotspot, byCallees) > 0 {
+				hotspots = append(hotspots, hotspot)
+			}
+		}
+	}
+
+	slices.SortFunc(hotspots, func(a, b HotspotEntry) int {
+		return cmp.Or(
+			cmp.Compare(rankCount(&b, byCallees), rankCount(&a, byCallees)),
+			cmp.Compare(rankCount(&b, !byCallees), rankCount(&a, !byCallees)),
+			strings.Compare(a.Function, b.Function),
+			strings.Compare(a.File, b.File),
+			cmp.Compare(a.Line, b.Line),
+		)
+	})
+	if limit >= 0 && len(hotspots) > limit {
+		hotspots = hotspots[:limit]
+	}
+	return hotspots, nil
+}
+
+// rankCount returns the count a hotspot is ranked by
+func rankCount(hotspot *HotspotEntry, byCallees bool) int {
+	if byCallees {
+		return hotspot.CalleeCount
+	}
+	return hotspot.CallerCount
+}
+
+// addToSet adds value to the set stored under key, creating the set when needed
+func addToSet(sets map[string]map[string]bool, key, value string) {
+	if sets[key] == nil {
+		sets[key] = make(map[string]bool)
+	}
+	sets[key][value] = true
+}
+
+// ListFiles returns the sorted paths of all files in storage
+func (m *MemoryStorage) ListFiles() ([]string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	files := slices.Clone(m.order)
+	slices.Sort(files)
+	return files, nil
+}
+
+// GetFileContext returns a copy of the stored context for a single file
+func (m *MemoryStorage) GetFileContext(filePath string) (*models.FileContext, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	file, ok := m.files[filePath]
+	if !ok {
+		return nil, fmt.Errorf("file %s not found in index", filePath)
+	}
+	chunk, err := file.chunk()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load chunk for %s: %w", filePath, err)
+	}
+	for i := range chunk.FileData {
+		if chunk.FileData[i].Path == filePath {
+			return &chunk.FileData[i], nil
+		}
+	}
+	return nil, fmt.Errorf("file %s not found in index", filePath)
+}
+
+// equalFoldASCII reports whether two strings are equal ignoring ASCII case, matching
+// SQLite's NOCASE collation
+func equalFoldASCII(a, b string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := 0; i < len(a); i++ {
+		if toLowerASCII(a[i]) != toLowerASCII(b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// toLowerASCII lowercases an ASCII letter and returns other bytes unchanged
+func toLowerASCII(c byte) byte {
+	if 'A' <= c && c <= 'Z' {
+		return c + 'a' - 'A'
+	}
+	return c
+}
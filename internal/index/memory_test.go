@@ -0,0 +1,158 @@
+package index
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"repository-context-protocol/internal/models"
+)
+
+func memoryTestFiles() []*models.FileContext {
+	return []*models.FileContext{
+		{
+			Path: "/repo/user.go", Language: "go", Checksum: "v1",
+			Functions: []models.Function{
+				{Name: "GetUser", Signature: "func GetUser(id int) *User", StartLine: 10, EndLine: 20, Calls: []string{"loadUser", "logf"}},
+				{Name: "loadUser", StartLine: 30, EndLine: 40, Calls: []string{"logf"}},
+			},
+			Types:     []models.TypeDef{{Name: "User", Kind: "struct", StartLine: 1, EndLine: 5, Embedded: []string{"Base"}}},
+			Variables: []models.Variable{{Name: "defaultUser", StartLine: 7, EndLine: 7}},
+		},
+		{
+			Path: "/repo/log.go", Language: "go",
+			Functions: []models.Function{{Name: "logf", StartLine: 1, EndLine: 3}},
+			Constants: []models.Constant{{Name: "LogLevel", StartLine: 5, EndLine: 5}},
+		},
+		// Storing a file again replaces its earlier data
+		{
+			Path: "/repo/user.go", Language: "go", Checksum: "v2",
+			Functions: []models.Function{
+				{Name: "GetUser", Signature: "func GetUser(id string) *User", StartLine: 12, EndLine: 22, Calls: []string{"logf"}},
+				{Name: "getuser", StartLine: 30, EndLine: 31},
+			},
+		},
+	}
+}
+
+func TestMemoryStorage_MatchesHybridStorage(t *testing.T) {
+	hybrid := newDiffTestStorage(t, memoryTestFiles()...)
+	memory := NewMemoryStorage()
+	if err := memory.Initialize(); err != nil {
+		t.Fatalf("Failed to initialize memory storage: %v", err)
+	}
+	for _, fileContext := range memoryTestFiles() {
+		if err := memory.StoreFileContext(fileContext); err != nil {
+			t.Fatalf("Failed to store file context: %v", err)
+		}
+	}
+
+	queries := map[string]func(Storage) ([]QueryResult, error){
+		"by name":             func(s Storage) ([]QueryResult, error) { return s.QueryByName("GetUser") },
+		"by name ignore case": func(s Storage) ([]QueryResult, error) { return s.QueryByNameCaseInsensitive("GETUSER") },
+		"by type":             func(s Storage) ([]QueryResult, error) { return s.QueryByType("function") },
+		"replaced entries":    func(s Storage) ([]QueryResult, error) { return s.QueryByName("loadUser") },
+	}
+	for name, query := range queries {
+		expected, err := query(hybrid)
+		if err != nil {
+			t.Fatalf("%s: hybrid query failed: %v", name, err)
+		}
+		actual, err := query(memory)
+		if err != nil {
+			t.Fatalf("%s: memory query failed: %v", name, err)
+		}
+		if len(actual) != len(expected) {
+			t.Fatalf("%s: expected %d results, got %d", name, len(expected), len(actual))
+		}
+		for i := range expected {
+			if actual[i].IndexEntry != expected[i].IndexEntry {
+				t.Errorf("%s: expected entry %+v, got %+v", name, expected[i].IndexEntry, actual[i].IndexEntry)
+			}
+			if !reflect.DeepEqual(actual[i].ChunkData.FileData, expected[i].ChunkData.FileData) {
+				t.Errorf("%s: chunk data differs for %s", name, expected[i].IndexEntry.Name)
+			}
+		}
+	}
+
+	calls := map[string]func(Storage) ([]models.CallRelation, error){
+		"calls from": func(s Storage) ([]models.CallRelation, error) { return s.QueryCallsFrom("GetUser") },
+		"calls to":   func(s Storage) ([]models.CallRelation, error) { return s.QueryCallsTo("logf") },
+	}
+	for name, query := range calls {
+		expected, _ := query(hybrid)
+		actual, _ := query(memory)
+		if !slices.Equal(actual, expected) {
+			t.Errorf("%s: expected %+v, got %+v", name, expected, actual)
+		}
+	}
+
+	for _, byCallees := range []bool{false, true} {
+		expected, _ := hybrid.QueryHotspots(byCallees, 10)
+		actual, _ := memory.QueryHotspots(byCallees, 10)
+		if !slices.Equal(actual, expected) {
+			t.Errorf("Hotspots by callees %v: expected %+v, got %+v", byCallees, expected, actual)
+		}
+	}
+
+	expectedKinds, _ := hybrid.ListNameKinds()
+	actualKinds, _ := memory.ListNameKinds()
+	byNameKind := func(a, b models.IndexEntry) int {
+		return strings.Compare(a.Name+" "+a.Type, b.Name+" "+b.Type)
+	}
+	slices.SortFunc(expectedKinds, byNameKind)
+	slices.SortFunc(actualKinds, byNameKind)
+	if !slices.Equal(actualKinds, expectedKinds) {
+		t.Errorf("Expected name kinds %+v, got %+v", expectedKinds, actualKinds)
+	}
+
+	expectedFiles, _ := hybrid.ListFiles()
+	actualFiles, _ := memory.ListFiles()
+	if !slices.Equal(actualFiles, expectedFiles) {
+		t.Errorf("Expected files %v, got %v", expectedFiles, actualFiles)
+	}
+
+	fileContext, err := memory.GetFileContext("/repo/user.go")
+	if err != nil || fileContext.Checksum != "v2" {
+		t.Errorf("Expected the replaced file context, got %+v (%v)", fileContext, err)
+	}
+	if _, err := memory.GetFileContext("/repo/missing.go"); err == nil {
+		t.Error("Expected error for a file not in storage")
+	}
+}
+
+func TestMemoryStorage_QueryEngine(t *testing.T) {
+	storage := NewMemoryStorage()
+	engine := NewQueryEngineWithCache(storage, 10)
+	if err := storage.StoreFileContext(memoryTestFiles()[0]); err != nil {
+		t.Fatalf("Failed to store file context: %v", err)
+	}
+
+	result, err := engine.SearchByName("GetUser")
+	if err != nil {
+		t.Fatalf("Failed to search: %v", err)
+	}
+	if len(result.Entries) != 1 || result.Entries[0].IndexEntry.Signature != "func GetUser(id int) *User" {
+		t.Errorf("Expected the stored GetUser, got %+v", result.Entries)
+	}
+
+	// Stored results are returned as copies that callers may modify
+	result.Entries[0].ChunkData.FileData[0].Functions[0].Name = "Changed"
+	fileContext, err := storage.GetFileContext("/repo/user.go")
+	if err != nil || fileContext.Functions[0].Name != "GetUser" {
+		t.Errorf("Expected stored data to be unaffected, got %+v (%v)", fileContext, err)
+	}
+
+	// Writes invalidate cached results
+	if err := storage.DeleteFile("/repo/user.go"); err != nil {
+		t.Fatalf("Failed to delete file: %v", err)
+	}
+	result, err = engine.SearchByName("GetUser")
+	if err != nil {
+		t.Fatalf("Failed to search: %v", err)
+	}
+	if len(result.Entries) != 0 {
+		t.Errorf("Expected no entries after deleting the file, got %+v", result.Entries)
+	}
+}
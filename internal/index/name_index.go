@@ -0,0 +1,159 @@
+package index
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// NameCompletion is a symbol name matching a completion prefix
+type NameCompletion struct {
+	Name string `json:"name"` // Symbol name
+	Kind string `json:"kind"` // Entity type or type kind, e.g. "function" or "struct"
+}
+
+// nameIndex is a sorted list of every name and kind in the index, with the names
+// containing each identifier word for word matching.
+// It is built on first use and rebuilt when the storage generation changes,
+// so prefix lookups are a binary search followed by a scan of the matches.
+type nameIndex struct {
+	mutex      sync.Mutex
+	built      bool
+	generation uint64
+	names      []NameCompletion
+	words      map[string][]string // Lower case word to the names containing it
+}
+
+// CompleteNames returns up to limit symbol names starting with prefix, sorted by name and kind.
+// A name defined as several kinds is returned once per kind. A limit of zero or less returns all matches.
+func (qe *QueryEngine) CompleteNames(prefix string, limit int) ([]NameCompletion, error) {
+	names, err := qe.sortedNames()
+	if err != nil {
+		return nil, err
+	}
+
+	start, _ := slices.BinarySearchFunc(names, prefix, func(completion NameCompletion, target string) int {
+		return cmp.Compare(completion.Name, target)
+	})
+
+	completions := []NameCompletion{}
+	for _, completion := range names[start:] {
+		if !strings.HasPrefix(completion.Name, prefix) {
+			break
+		}
+		if limit > 0 && len(completions) >= limit {
+			break
+		}
+		completions = append(completions, completion)
+	}
+	return completions, nil
+}
+
+// sortedNames returns the name index, rebuilding it if the storage has changed since it was built
+func (qe *QueryEngine) sortedNames() ([]NameCompletion, error) {
+	qe.names.mutex.Lock()
+	defer qe.names.mutex.Unlock()
+
+	generation := qe.storage.Generation()
+	if qe.names.built && qe.names.generation == generation {
+		return qe.names.names, nil
+	}
+
+	entries, err := qe.storage.ListNameKinds()
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]NameCompletion, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, NameCompletion{Name: entry.Name, Kind: entry.Type})
+	}
+	slices.SortFunc(names, func(a, b NameCompletion) int {
+		return cmp.Or(cmp.Compare(a.Name, b.Name), cmp.Compare(a.Kind, b.Kind))
+	})
+
+	qe.names.names = names
+	qe.names.words = buildNameWords(names)
+	qe.names.generation = generation
+	qe.names.built = true
+	return names, nil
+}
+
+// MaxNameSuggestions caps the near-matches SuggestNames returns
+const MaxNameSuggestions = 5
+
+// SuggestNames returns up to limit indexed names close to name, for "did you mean"
+// hints when a lookup finds nothing. Names are compared ignoring case and ranked by
+// edit distance, then alphabetically; names further than a third of the name's length
+// (at least two edits) are left out. When matchKind is not nil only names of a
+// matching kind are considered. A limit of zero or less means MaxNameSuggestions.
+func (qe *QueryEngine) SuggestNames(name string, limit int, matchKind func(kind string) bool) ([]string, error) {
+	if limit <= 0 {
+		limit = MaxNameSuggestions
+	}
+	names, err := qe.sortedNames()
+	if err != nil {
+		return nil, err
+	}
+
+	target := []rune(strings.ToLower(name))
+	maxDistance := max(2, len(target)/3)
+
+	type suggestion struct {
+		name     string
+		distance int
+	}
+	var suggestions []suggestion
+	seen := make(map[string]bool) // Names defined as several kinds appear once per kind
+	for _, completion := range names {
+		if seen[completion.Name] || completion.Name == name || (matchKind != nil && !matchKind(completion.Kind)) {
+			continue
+		}
+		seen[completion.Name] = true
+		candidate := []rune(strings.ToLower(completion.Name))
+		if abs(len(candidate)-len(target)) > maxDistance {
+			continue
+		}
+		if distance := editDistance(target, candidate); distance <= maxDistance {
+			suggestions = append(suggestions, suggestion{name: completion.Name, distance: distance})
+		}
+	}
+
+	slices.SortStableFunc(suggestions, func(a, b suggestion) int {
+		return cmp.Compare(a.distance, b.distance)
+	})
+	result := make([]string, 0, min(limit, len(suggestions)))
+	for _, s := range suggestions[:min(limit, len(suggestions))] {
+		result = append(result, s.name)
+	}
+	return result, nil
+}
+
+// editDistance returns the Levenshtein distance between a and b
+func editDistance(a, b []rune) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			substitution := previous[j-1]
+			if a[i-1] != b[j-1] {
+				substitution++
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, substitution)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,109 @@
+package index
+
+import (
+	"slices"
+	"testing"
+
+	"repository-context-protocol/internal/models"
+)
+
+func TestQueryEngine_CompleteNames(t *testing.T) {
+	storage := newDiffTestStorage(t, &models.FileContext{
+		Path: "user.go", Language: "go", Checksum: "a1",
+		Functions: []models.Function{
+			{Name: "GetUser", Signature: "func GetUser(id int) *User", StartLine: 10, EndLine: 12},
+			{Name: "GetUsers", Signature: "func GetUsers() []User", StartLine: 14, EndLine: 16},
+			{Name: "Get", Signature: "func Get()", StartLine: 18, EndLine: 19},
+			{Name: "getCache", Signature: "func getCache()", StartLine: 21, EndLine: 22},
+			{Name: "SetUser", Signature: "func SetUser(u *User)", StartLine: 24, EndLine: 26},
+		},
+		Types: []models.TypeDef{{Name: "Getter", Kind: "interface", StartLine: 1, EndLine: 3}},
+	})
+	engine := NewQueryEngine(storage)
+
+	completions, err := engine.CompleteNames("Get", 0)
+	if err != nil {
+		t.Fatalf("Failed to complete names: %v", err)
+	}
+
+	expected := []NameCompletion{
+		{Name: "Get", Kind: "function"},
+		{Name: "GetUser", Kind: "function"},
+		{Name: "GetUsers", Kind: "function"},
+		{Name: "Getter", Kind: "interface"},
+	}
+	if len(completions) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, completions)
+	}
+	for i := range expected {
+		if completions[i] != expected[i] {
+			t.Errorf("Expected completion %d to be %v, got %v", i, expected[i], completions[i])
+		}
+	}
+
+	limited, err := engine.CompleteNames("Get", 2)
+	if err != nil || len(limited) != 2 || limited[1].Name != "GetUser" {
+		t.Errorf("Expected limit to keep the first two completions, got %v (err: %v)", limited, err)
+	}
+
+	none, err := engine.CompleteNames("Delete", 10)
+	if err != nil || len(none) != 0 {
+		t.Errorf("Expected no completions, got %v (err: %v)", none, err)
+	}
+
+	// Writes to storage rebuild the index on the next lookup
+	err = storage.StoreFileContext(&models.FileContext{
+		Path: "cache.go", Language: "go", Checksum: "b1",
+		Functions: []models.Function{{Name: "GetCached", Signature: "func GetCached()", StartLine: 1, EndLine: 2}},
+	})
+	if err != nil {
+		t.Fatalf("Failed to store file context: %v", err)
+	}
+
+	completions, err = engine.CompleteNames("GetC", 0)
+	if err != nil || len(completions) != 1 || completions[0].Name != "GetCached" {
+		t.Errorf("Expected index to pick up GetCached, got %v (err: %v)", completions, err)
+	}
+}
+
+func TestQueryEngine_SuggestNames(t *testing.T) {
+	storage := newDiffTestStorage(t, &models.FileContext{
+		Path: "user.go", Language: "go",
+		Functions: []models.Function{
+			{Name: "GetUser", Signature: "func GetUser(id int) *User", StartLine: 10, EndLine: 12},
+			{Name: "GetUsers", Signature: "func GetUsers() []User", StartLine: 14, EndLine: 16},
+			{Name: "getuser", Signature: "func getuser()", StartLine: 18, EndLine: 19},
+			{Name: "SetUser", Signature: "func SetUser(u *User)", StartLine: 24, EndLine: 26},
+			{Name: "DeleteAccount", Signature: "func DeleteAccount()", StartLine: 28, EndLine: 30},
+		},
+		Types: []models.TypeDef{{Name: "GetUsr", Kind: "struct", StartLine: 1, EndLine: 3}},
+	})
+	engine := NewQueryEngine(storage)
+
+	isFunction := func(kind string) bool { return kind == EntityTypeFunction }
+	suggestions, err := engine.SuggestNames("GetUsr", 0, isFunction)
+	if err != nil {
+		t.Fatalf("Failed to suggest names: %v", err)
+	}
+	// Closest first, ignoring case; the struct and distant names are left out
+	expected := []string{"GetUser", "getuser", "GetUsers", "SetUser"}
+	if !slices.Equal(suggestions, expected) {
+		t.Errorf("Expected %v, got %v", expected, suggestions)
+	}
+
+	suggestions, err = engine.SuggestNames("GetUsr", 1, nil)
+	if err != nil {
+		t.Fatalf("Failed to suggest names: %v", err)
+	}
+	if !slices.Equal(suggestions, []string{"GetUser"}) {
+		t.Errorf("Expected the closest name other than the query itself, got %v", suggestions)
+	}
+
+	suggestions, err = engine.SuggestNames("Frobnicate", 0, nil)
+	if err != nil {
+		t.Fatalf("Failed to suggest names: %v", err)
+	}
+	if len(suggestions) != 0 {
+		t.Errorf("Expected no suggestions for an unrelated name, got %v", suggestions)
+	}
+}
@@ -0,0 +1,145 @@
+package index
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// SplitIdentifierWords splits an identifier into lower case words at camelCase and
+// PascalCase boundaries and at any character that is not a letter or digit, so
+// GetUserByID, get_user_by_id and get-user-by-id all give get, user, by, id.
+// An acronym ends before a capitalized word (HTTPServer gives http, server) and
+// digits stay with the word they follow (Base64Encode gives base64, encode).
+func SplitIdentifierWords(name string) []string {
+	runes := []rune(name)
+	var words []string
+	start := -1
+	flush := func(end int) {
+		if start >= 0 {
+			words = append(words, strings.ToLower(string(runes[start:end])))
+			start = -1
+		}
+	}
+	for i, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			flush(i)
+			continue
+		}
+		if start >= 0 && unicode.IsUpper(r) && startsWord(runes, i) {
+			flush(i)
+		}
+		if start < 0 {
+			start = i
+		}
+	}
+	flush(len(runes))
+	return words
+}
+
+// startsWord reports whether the upper case rune at i starts a new word: after a lower
+// case letter or digit, or as the last capital of an acronym followed by lower case
+func startsWord(runes []rune, i int) bool {
+	previous := runes[i-1]
+	if unicode.IsLower(previous) || unicode.IsDigit(previous) {
+		return true
+	}
+	return unicode.IsUpper(previous) && i+1 < len(runes) && unicode.IsLower(runes[i+1])
+}
+
+// containsWordRun reports whether query occurs in words as a run of consecutive words
+func containsWordRun(words, query []string) bool {
+	if len(query) == 0 {
+		return false
+	}
+	for i := 0; i+len(query) <= len(words); i++ {
+		if slices.Equal(words[i:i+len(query)], query) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesWords reports whether the words of query appear consecutively in the words of name,
+// so user matches GetUserByID and user_service but not housuser
+func matchesWords(name, query string) bool {
+	return containsWordRun(SplitIdentifierWords(name), SplitIdentifierWords(query))
+}
+
+// wordMatchNames returns the indexed names whose words contain the words of query as a
+// consecutive run, sorted. Candidates are looked up in the name-word index by the first
+// query word, so only names sharing that word are compared.
+func (qe *QueryEngine) wordMatchNames(query string) ([]string, error) {
+	queryWords := SplitIdentifierWords(query)
+	if len(queryWords) == 0 {
+		return nil, nil
+	}
+	if _, err := qe.sortedNames(); err != nil {
+		return nil, err
+	}
+
+	qe.names.mutex.Lock()
+	candidates := qe.names.words[queryWords[0]]
+	qe.names.mutex.Unlock()
+
+	var names []string
+	for _, name := range candidates {
+		if containsWordRun(SplitIdentifierWords(name), queryWords) {
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
+
+// buildNameWords maps each word of the sorted names to the distinct names containing it,
+// in name order
+func buildNameWords(names []NameCompletion) map[string][]string {
+	words := make(map[string][]string)
+	for i, completion := range names {
+		if i > 0 && names[i-1].Name == completion.Name {
+			continue // Names defined as several kinds appear once per kind
+		}
+		for _, word := range SplitIdentifierWords(completion.Name) {
+			if list := words[word]; len(list) == 0 || list[len(list)-1] != completion.Name {
+				words[word] = append(list, completion.Name)
+			}
+		}
+	}
+	return words
+}
+
+// queryNameWithOptions queries storage for the entries named name, or with WordMatch
+// for the entries whose names contain its words
+func (qe *QueryEngine) queryNameWithOptions(name string, options *QueryOptions) ([]QueryResult, error) {
+	if !options.WordMatch {
+		return qe.queryName(name, options.CaseInsensitive)
+	}
+
+	names, err := qe.wordMatchNames(name)
+	if err != nil {
+		return nil, err
+	}
+	var results []QueryResult
+	for _, match := range names {
+		matchResults, err := qe.storage.QueryByName(match)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, matchResults...)
+	}
+	return results, nil
+}
+
+// matchesNamePattern matches a name against a search pattern, or with WordMatch matches
+// each word of the name against it ignoring case, so user* finds GetUsersByID
+func (qe *QueryEngine) matchesNamePattern(name, pattern string, options *QueryOptions) bool {
+	if !options.WordMatch {
+		return qe.matchesPattern(name, pattern, options.CaseInsensitive)
+	}
+	for _, word := range SplitIdentifierWords(name) {
+		if qe.matchesPattern(word, pattern, true) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,95 @@
+package index
+
+import (
+	"slices"
+	"testing"
+
+	"repository-context-protocol/internal/models"
+)
+
+func TestSplitIdentifierWords(t *testing.T) {
+	tests := map[string][]string{
+		"GetUserByID":    {"get", "user", "by", "id"},
+		"get_user_by_id": {"get", "user", "by", "id"},
+		"get-user-by-id": {"get", "user", "by", "id"},
+		"HTTPServer":     {"http", "server"},
+		"Base64Encode":   {"base64", "encode"},
+		"__init__":       {"init"},
+		"Server.Start":   {"server", "start"},
+		"housuser":       {"housuser"},
+		"":               nil,
+	}
+	for name, expected := range tests {
+		if got := SplitIdentifierWords(name); !slices.Equal(got, expected) {
+			t.Errorf("Expected %q to split into %v, got %v", name, expected, got)
+		}
+	}
+}
+
+func newWordMatchTestEngine(t *testing.T) *QueryEngine {
+	return NewQueryEngine(newDiffTestStorage(t,
+		&models.FileContext{
+			Path: "users/users.go", Language: "go",
+			Functions: []models.Function{
+				{Name: "GetUserByID", StartLine: 3},
+				{Name: "GetUsers", StartLine: 8},
+				{Name: "housuser", StartLine: 12},
+				{Name: "user_service", StartLine: 16},
+			},
+			Types: []models.TypeDef{{Name: "UserStore", Kind: "struct", StartLine: 20}},
+		},
+	))
+}
+
+func TestQueryEngine_SearchByNameWordMatch(t *testing.T) {
+	engine := newWordMatchTestEngine(t)
+
+	tests := map[string][]string{
+		"user":       {"GetUserByID", "UserStore", "user_service"},
+		"User":       {"GetUserByID", "UserStore", "user_service"},
+		"user_by_id": {"GetUserByID"},
+		"userStore":  {"UserStore"},
+		"byUser":     nil,
+		"_":          nil,
+	}
+	for query, expected := range tests {
+		result, err := engine.SearchByNameWithOptions(query, QueryOptions{WordMatch: true})
+		if err != nil {
+			t.Fatalf("Failed to search for %q: %v", query, err)
+		}
+		var names []string
+		for _, entry := range result.Entries {
+			names = append(names, entry.IndexEntry.Name)
+		}
+		if !slices.Equal(names, expected) {
+			t.Errorf("Expected %q to word match %v, got %v", query, expected, names)
+		}
+	}
+
+	// Without word matching only the exact name matches
+	result, err := engine.SearchByName("user")
+	if err != nil {
+		t.Fatalf("Failed to search by name: %v", err)
+	}
+	if len(result.Entries) != 0 {
+		t.Errorf("Expected no exact match for user, got %d entries", len(result.Entries))
+	}
+}
+
+func TestQueryEngine_SearchByPatternWordMatch(t *testing.T) {
+	engine := newWordMatchTestEngine(t)
+
+	result, err := engine.SearchByPatternWithOptions("user*", QueryOptions{WordMatch: true})
+	if err != nil {
+		t.Fatalf("Failed to search by pattern: %v", err)
+	}
+	var names []string
+	for _, entry := range result.Entries {
+		names = append(names, entry.IndexEntry.Name)
+	}
+	slices.Sort(names)
+	// Types are only scanned with IncludeTypes, and housuser has no word starting with user
+	if expected := []string{"GetUserByID", "GetUsers", "user_service"}; !slices.Equal(names, expected) {
+		t.Errorf("Expected user* to word match %v, got %v", expected, names)
+	}
+}
@@ -0,0 +1,32 @@
+package index
+
+import "repository-context-protocol/internal/models"
+
+// annotateNormalizedTypes records the normalized form of the parameter, return and field
+// types of fileContext, leaving the types as written for display
+func annotateNormalizedTypes(fileContext *models.FileContext) {
+	language := fileContext.Language
+	for i := range fileContext.Functions {
+		function := &fileContext.Functions[i]
+		normalizeSignatureTypes(function.Parameters, function.Returns, language)
+	}
+	for i := range fileContext.Types {
+		typeDef := &fileContext.Types[i]
+		for j := range typeDef.Fields {
+			typeDef.Fields[j].NormalizedType = models.NormalizeType(typeDef.Fields[j].Type, language)
+		}
+		for j := range typeDef.Methods {
+			normalizeSignatureTypes(typeDef.Methods[j].Parameters, typeDef.Methods[j].Returns, language)
+		}
+	}
+}
+
+// normalizeSignatureTypes records the normalized form of parameter and return types
+func normalizeSignatureTypes(parameters []models.Parameter, returns []models.Type, language string) {
+	for i := range parameters {
+		parameters[i].NormalizedType = models.NormalizeType(parameters[i].Type, language)
+	}
+	for i := range returns {
+		returns[i].NormalizedType = models.NormalizeType(returns[i].Name, language)
+	}
+}
@@ -0,0 +1,37 @@
+package index
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// RepoRelativePath converts a file path given by a client to the repo-relative form
+// stored in the index. Absolute paths inside the repository at root are made relative
+// to it; relative paths are taken to be repo-relative already and only cleaned. Paths
+// outside the repository are returned cleaned, so they match nothing.
+func RepoRelativePath(root, path string) string {
+	path = filepath.Clean(path)
+	if !filepath.IsAbs(path) || root == "" {
+		return path
+	}
+
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return path
+	}
+	rel, err := filepath.Rel(absRoot, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return path
+	}
+	return rel
+}
+
+// RepoRelativePaths converts paths with RepoRelativePath, as needed for the absolute
+// paths ChangedFiles lists before they filter index entries
+func RepoRelativePaths(root string, paths []string) []string {
+	relative := make([]string, len(paths))
+	for i, path := range paths {
+		relative[i] = RepoRelativePath(root, path)
+	}
+	return relative
+}
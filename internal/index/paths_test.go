@@ -0,0 +1,30 @@
+package index
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestRepoRelativePath(t *testing.T) {
+	root := filepath.Join(string(filepath.Separator), "work", "repo")
+
+	tests := []struct {
+		name string
+		path string
+		want string
+	}{
+		{"absolute inside", filepath.Join(root, "internal", "index", "query.go"), filepath.Join("internal", "index", "query.go")},
+		{"repository root", root, "."},
+		{"relative", "internal/index/../index/query.go", filepath.Join("internal", "index", "query.go")},
+		{"absolute outside", filepath.Join(string(filepath.Separator), "work", "other", "main.go"),
+			filepath.Join(string(filepath.Separator), "work", "other", "main.go")},
+		{"sibling with shared prefix", root + "-fork/main.go", filepath.Clean(root + "-fork/main.go")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RepoRelativePath(root, tt.path); got != tt.want {
+				t.Errorf("RepoRelativePath(%q, %q) = %q, want %q", root, tt.path, got, tt.want)
+			}
+		})
+	}
+}
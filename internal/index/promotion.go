@@ -0,0 +1,168 @@
+package index
+
+import (
+	"path/filepath"
+	"strings"
+
+	"repository-context-protocol/internal/models"
+)
+
+// promotionLevel is a type reached through embedding and the file declaring it
+type promotionLevel struct {
+	typeDef *models.TypeDef
+	file    string
+}
+
+// promotedCandidates collects the members found at one embedding depth, in discovery order
+type promotedCandidates struct {
+	names   []string
+	fields  map[string][]models.Field
+	methods map[string][]models.Method
+}
+
+// linkPromotedMembers records on each Go struct and interface the fields and methods promoted
+// from the types it embeds, following Go's selector rules: a member at a shallower depth
+// shadows deeper ones, and a name found more than once at the shallowest depth it occurs
+// at is ambiguous and not promoted. Embedded types are resolved within the package; types
+// of other packages, such as sync.Mutex, are not followed. Methods of both value and
+// pointer receivers are promoted, as on an addressable value.
+func linkPromotedMembers(fileContexts []models.FileContext) {
+	type packageKey struct {
+		dir  string
+		name string
+	}
+
+	packages := make(map[packageKey]map[string]promotionLevel)
+	for i := range fileContexts {
+		fileContext := &fileContexts[i]
+		if fileContext.Language != "go" {
+			continue
+		}
+		key := packageKey{dir: filepath.Dir(fileContext.Path), name: fileContext.Package}
+		if packages[key] == nil {
+			packages[key] = make(map[string]promotionLevel)
+		}
+		for j := range fileContext.Types {
+			packages[key][fileContext.Types[j].Name] = promotionLevel{typeDef: &fileContext.Types[j], file: fileContext.Path}
+		}
+	}
+
+	for _, types := range packages {
+		for _, level := range types {
+			if len(level.typeDef.Embedded) > 0 {
+				promoteMembers(level.typeDef, types)
+			}
+		}
+	}
+}
+
+// promoteMembers walks the types embedded in typeDef breadth first, one depth at a time
+func promoteMembers(typeDef *models.TypeDef, types map[string]promotionLevel) {
+	taken := make(map[string]bool)
+	for i := range typeDef.Fields {
+		taken[typeDef.Fields[i].Name] = true
+	}
+	for i := range typeDef.Methods {
+		taken[typeDef.Methods[i].Name] = true
+	}
+	for _, embedded := range typeDef.Embedded {
+		taken[embeddedFieldName(embedded)] = true
+	}
+
+	visited := map[*models.TypeDef]bool{typeDef: true}
+	current := resolveEmbedded(typeDef.Embedded, types)
+	var promotedFields []models.Field
+	var promotedMethods []models.Method
+	for len(current) > 0 {
+		candidates := &promotedCandidates{fields: make(map[string][]models.Field), methods: make(map[string][]models.Method)}
+		var next []promotionLevel
+		for _, level := range current {
+			if visited[level.typeDef] {
+				continue
+			}
+			candidates.add(level)
+			next = append(next, resolveEmbedded(level.typeDef.Embedded, types)...)
+		}
+		for _, level := range current {
+			visited[level.typeDef] = true
+		}
+
+		// A name found at this depth shadows deeper members even when it is ambiguous
+		for _, name := range candidates.names {
+			if taken[name] {
+				continue
+			}
+			taken[name] = true
+			fields, methods := candidates.fields[name], candidates.methods[name]
+			switch {
+			case len(fields) == 1 && len(methods) == 0:
+				promotedFields = append(promotedFields, fields[0])
+			case len(methods) == 1 && len(fields) == 0:
+				promotedMethods = append(promotedMethods, methods[0])
+			}
+		}
+		current = next
+	}
+
+	typeDef.PromotedFields = promotedFields
+	typeDef.PromotedMethods = promotedMethods
+}
+
+// add records the fields, embedded fields and methods a type declares
+func (c *promotedCandidates) add(level promotionLevel) {
+	origin := level.typeDef.Name
+	for _, field := range level.typeDef.Fields {
+		field.PromotedFrom = origin
+		c.addField(field)
+	}
+	for _, embedded := range level.typeDef.Embedded {
+		c.addField(models.Field{Name: embeddedFieldName(embedded), Type: embedded, PromotedFrom: origin})
+	}
+	for _, method := range level.typeDef.Methods {
+		method.PromotedFrom = origin
+		if method.File == "" {
+			method.File = level.file
+		}
+		c.note(method.Name)
+		c.methods[method.Name] = append(c.methods[method.Name], method)
+	}
+}
+
+// addField records a field found at this depth
+func (c *promotedCandidates) addField(field models.Field) {
+	c.note(field.Name)
+	c.fields[field.Name] = append(c.fields[field.Name], field)
+}
+
+// note records the first time a member name is found
+func (c *promotedCandidates) note(name string) {
+	if len(c.fields[name]) == 0 && len(c.methods[name]) == 0 {
+		c.names = append(c.names, name)
+	}
+}
+
+// resolveEmbedded returns the types of the package named by embedded type expressions.
+// An embedded type may be listed more than once, making its members ambiguous.
+func resolveEmbedded(embedded []string, types map[string]promotionLevel) []promotionLevel {
+	var levels []promotionLevel
+	for _, typeExpr := range embedded {
+		name := strings.TrimPrefix(strings.TrimSpace(typeExpr), "*")
+		if i := strings.IndexByte(name, '['); i >= 0 {
+			name = name[:i]
+		}
+		if level, ok := types[name]; ok {
+			levels = append(levels, level)
+		}
+	}
+	return levels
+}
+
+// embeddedFieldName returns the field name of an embedded type, e.g. Mutex for *sync.Mutex
+// and List for List[T]
+func embeddedFieldName(typeExpr string) string {
+	name := strings.TrimPrefix(strings.TrimSpace(typeExpr), "*")
+	if i := strings.IndexByte(name, '['); i >= 0 {
+		name = name[:i]
+	}
+	return name[strings.LastIndexByte(name, '.')+1:]
+}
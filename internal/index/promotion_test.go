@@ -0,0 +1,75 @@
+package index
+
+import (
+	"slices"
+	"testing"
+
+	"repository-context-protocol/internal/models"
+)
+
+func TestLinkPromotedMembers(t *testing.T) {
+	fileContexts := []models.FileContext{
+		{
+			Path: "users/admin.go", Language: "go", Package: "users",
+			Types: []models.TypeDef{
+				// Admin embeds User, which embeds Base: Base's members are promoted two levels up
+				{Name: "Admin", Kind: "struct", Embedded: []string{"*User", "Audit"}, StartLine: 1,
+					Fields:  []models.Field{{Name: "Level", Type: "int"}},
+					Methods: []models.Method{{Name: "String", Receiver: "Admin"}}},
+				// Audit and User both declare Touched at depth one, so it is ambiguous
+				{Name: "Audit", Kind: "struct", StartLine: 10,
+					Fields: []models.Field{{Name: "Touched", Type: "time.Time"}, {Name: "By", Type: "string"}}},
+			},
+		},
+		{
+			Path: "users/user.go", Language: "go", Package: "users",
+			Types: []models.TypeDef{
+				{Name: "User", Kind: "struct", Embedded: []string{"Base", "sync.Mutex"}, StartLine: 1,
+					Fields: []models.Field{{Name: "Name", Type: "string"}, {Name: "Touched", Type: "bool"}},
+					Methods: []models.Method{
+						{Name: "Greet", Receiver: "User", StartLine: 8},
+						{Name: "String", Receiver: "User", StartLine: 12}, // Shadowed by Admin.String
+					}},
+				// Base.Name is shadowed by User.Name, which is shallower
+				{Name: "Base", Kind: "struct", Embedded: []string{"*User"}, StartLine: 20,
+					Fields:  []models.Field{{Name: "ID", Type: "int"}, {Name: "Name", Type: "string"}},
+					Methods: []models.Method{{Name: "Key", Receiver: "Base", StartLine: 25, File: "users/base.go"}}},
+			},
+		},
+	}
+
+	linkPromotedMembers(fileContexts)
+
+	admin := &fileContexts[0].Types[0]
+	var fields []string
+	for _, field := range admin.PromotedFields {
+		fields = append(fields, field.Name+"@"+field.PromotedFrom)
+	}
+	expectedFields := []string{"Name@User", "Base@User", "Mutex@User", "By@Audit", "ID@Base"}
+	if !slices.Equal(fields, expectedFields) {
+		t.Errorf("Expected promoted fields %v, got %v", expectedFields, fields)
+	}
+
+	var methods []string
+	for _, method := range admin.PromotedMethods {
+		methods = append(methods, method.Name+"@"+method.PromotedFrom+"@"+method.File)
+	}
+	expectedMethods := []string{"Greet@User@users/user.go", "Key@Base@users/base.go"}
+	if !slices.Equal(methods, expectedMethods) {
+		t.Errorf("Expected promoted methods %v, got %v", expectedMethods, methods)
+	}
+
+	// Base embeds User back; the walk stops at Base instead of looping
+	base := &fileContexts[1].Types[1]
+	fields = nil
+	for _, field := range base.PromotedFields {
+		fields = append(fields, field.Name+"@"+field.PromotedFrom)
+	}
+	if expected := []string{"Touched@User", "Base@User", "Mutex@User"}; !slices.Equal(fields, expected) {
+		t.Errorf("Expected Base to gain %v, got %v", expected, fields)
+	}
+
+	if audit := &fileContexts[0].Types[1]; audit.PromotedFields != nil || audit.PromotedMethods != nil {
+		t.Errorf("Expected no promoted members on a type embedding nothing, got %+v", audit)
+	}
+}
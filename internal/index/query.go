@@ -1,13 +1,16 @@
 package index
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"log"
+	"maps"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"strings"
-	"sync"
 	"time"
 
 	"repository-context-protocol/internal/models"
@@ -29,6 +32,7 @@ const (
 	EntityKindEnum      = "enum"
 	EntityKindFunction  = "function"
 	EntityKindClass     = "class"
+	EntityKindModule    = "module"
 
 	// Token estimation constants
 	TokenOverhead    = 10
@@ -42,23 +46,65 @@ const (
 	CallGraphFunctionSplitParts = 2
 )
 
+// TypeKinds returns the stored kinds that represent type definitions
+func TypeKinds() []string {
+	return []string{EntityKindStruct, EntityKindInterface, EntityKindType, EntityKindAlias, EntityKindEnum, EntityKindClass, EntityKindModule}
+}
+
+// IsTypeKind reports whether an index entry type is one of the type definition kinds
+func IsTypeKind(entryType string) bool {
+	return slices.Contains(TypeKinds(), entryType)
+}
+
+// MatchesEntityType reports whether an index entry type falls under an entity type filter.
+// EntityTypeType covers every type definition kind, since types are stored by their kind.
+func MatchesEntityType(entryType, entityType string) bool {
+	if entityType == EntityTypeType {
+		return IsTypeKind(entryType)
+	}
+	return entryType == entityType
+}
+
 // Query engine for semantic searches
 
 // QueryEngine provides semantic search capabilities over the indexed repository
 type QueryEngine struct {
-	storage    *HybridStorage
-	regexCache map[string]*regexp.Regexp
-	regexMutex sync.RWMutex
+	storage        Storage
+	regexCache     *RegexCache
+	tokenEstimator TokenEstimator
+	resultCache    *queryCache
+	names          nameIndex
 }
 
 // QueryOptions configures search behavior and result formatting
 type QueryOptions struct {
-	IncludeCallers bool   `json:"include_callers"` // Include functions that call the target
-	IncludeCallees bool   `json:"include_callees"` // Include functions called by the target
-	IncludeTypes   bool   `json:"include_types"`   // Include related type definitions
-	MaxDepth       int    `json:"max_depth"`       // Maximum depth for relationship traversal
-	MaxTokens      int    `json:"max_tokens"`      // Maximum tokens for LLM consumption
-	Format         string `json:"format"`          // Output format: "json" or "text"
+	IncludeCallers  bool   `json:"include_callers"`            // Include functions that call the target
+	IncludeCallees  bool   `json:"include_callees"`            // Include functions called by the target
+	IncludeTypes    bool   `json:"include_types"`              // Include related type definitions
+	MaxDepth        int    `json:"max_depth"`                  // Maximum depth for relationship traversal
+	CallerDepth     int    `json:"caller_depth,omitempty"`     // Caller traversal depth, MaxDepth when zero
+	CalleeDepth     int    `json:"callee_depth,omitempty"`     // Callee traversal depth, MaxDepth when zero
+	MaxNodes        int    `json:"max_nodes,omitempty"`        // Maximum call graph entries collected, unlimited when zero
+	MaxTokens       int    `json:"max_tokens"`                 // Maximum tokens for LLM consumption
+	Format          string `json:"format"`                     // Output format: "json", "text" or "markdown"
+	Verbose         bool   `json:"verbose,omitempty"`          // List function parameters, returns and docs in text and markdown output
+	CaseInsensitive bool   `json:"case_insensitive,omitempty"` // Ignore case in name and pattern searches
+	WordMatch       bool   `json:"word_match,omitempty"`       // Match whole identifier words, see SplitIdentifierWords
+	Language        string `json:"language,omitempty"`         // Only return entries from files in this language
+	Scope           string `json:"scope,omitempty"`            // Only return variables and constants declared in this scope
+	ExportedOnly    bool   `json:"exported_only,omitempty"`    // Only return symbols their file exports (the public API)
+	OrderBySource   bool   `json:"order_by_source,omitempty"`  // Order file searches by declaration line instead of by kind
+
+	// EntityTypes restricts the kinds a pattern search scans, e.g. {"function"}; "type"
+	// stands for every type definition kind. When empty, functions, variables and
+	// constants are scanned, plus the type kinds with IncludeTypes.
+	EntityTypes []string `json:"entity_types,omitempty"`
+
+	// ChangedOnly restricts results to entries defined in ChangedFiles, typically the
+	// files changed relative to a git ref as listed by ChangedFiles and made repo-relative
+	// with RepoRelativePaths, as the index stores them. With no changed files nothing matches.
+	ChangedOnly  bool     `json:"changed_only,omitempty"`
+	ChangedFiles []string `json:"changed_files,omitempty"`
 }
 
 // SearchResult represents the result of a search operation
@@ -69,8 +115,16 @@ type SearchResult struct {
 	CallGraph  *CallGraphInfo      `json:"call_graph,omitempty"` // Call graph information
 	TokenCount int                 `json:"token_count"`          // Estimated token count
 	Truncated  bool                `json:"truncated"`            // Whether results were truncated
+	Facets     map[string]int      `json:"facets,omitempty"`     // Entry count per entity type before truncation
+	TimedOut   bool                `json:"timed_out,omitempty"`  // Whether the call graph was cut short by cancellation or a deadline
 	ExecutedAt time.Time           `json:"executed_at"`          // When the query was executed
 	Options    *QueryOptions       `json:"-"`                    // Original query options (not serialized)
+
+	// IndexGeneration and IndexBuildTime identify the index state the result was computed
+	// from. The generation changes on every storage write, so a result whose generation
+	// differs from a later one predates a rebuild and should be queried again.
+	IndexGeneration uint64    `json:"index_generation"`
+	IndexBuildTime  time.Time `json:"index_build_time"`
 }
 
 // SearchResultEntry combines index entry with chunk data
@@ -81,10 +135,13 @@ type SearchResultEntry struct {
 
 // CallGraphInfo provides call relationship information
 type CallGraphInfo struct {
-	Function string           `json:"function"`          // Target function name
-	Callers  []CallGraphEntry `json:"callers,omitempty"` // Functions that call this function
-	Callees  []CallGraphEntry `json:"callees,omitempty"` // Functions called by this function
-	Depth    int              `json:"depth"`             // Traversal depth used
+	Function  string           `json:"function"`            // Target function name
+	Callers   []CallGraphEntry `json:"callers,omitempty"`   // Functions that call this function
+	Callees   []CallGraphEntry `json:"callees,omitempty"`   // Functions called by this function
+	Depth     int              `json:"depth"`               // Traversal depth used
+	Cycles    [][]string       `json:"cycles,omitempty"`    // Call cycles encountered during traversal, in call order
+	TimedOut  bool             `json:"timed_out,omitempty"` // Traversal stopped early; callers and callees are partial
+	Truncated bool             `json:"truncated,omitempty"` // Traversal stopped at MaxNodes; callers and callees are partial
 }
 
 // CallGraphEntry represents a single call relationship
@@ -92,15 +149,118 @@ type CallGraphEntry struct {
 	Function  string                `json:"function"`             // Function name
 	File      string                `json:"file"`                 // File where function is defined
 	Line      int                   `json:"line"`                 // Line number of call
+	Depth     int                   `json:"depth"`                // Distance from the queried function, 1 for direct calls
 	ChunkData *models.SemanticChunk `json:"chunk_data,omitempty"` // Detailed semantic data
 }
 
-// NewQueryEngine creates a new query engine with the given storage
-func NewQueryEngine(storage *HybridStorage) *QueryEngine {
-	return &QueryEngine{
-		storage:    storage,
-		regexCache: make(map[string]*regexp.Regexp),
+// QueryEngineOption configures a query engine created by NewQueryEngine
+type QueryEngineOption func(*QueryEngine)
+
+// WithRegexCache makes the engine compile patterns through cache, which may be shared
+// with other engines, instead of a cache of its own
+func WithRegexCache(cache *RegexCache) QueryEngineOption {
+	return func(qe *QueryEngine) {
+		if cache != nil {
+			qe.regexCache = cache
+		}
+	}
+}
+
+// NewQueryEngine creates a new query engine with the given storage.
+// Unless WithRegexCache is given, the engine caches compiled patterns on its own.
+func NewQueryEngine(storage Storage, options ...QueryEngineOption) *QueryEngine {
+	qe := &QueryEngine{
+		storage:        storage,
+		regexCache:     NewRegexCache(0),
+		tokenEstimator: DefaultTokenEstimator(),
+	}
+	for _, option := range options {
+		option(qe)
+	}
+	return qe
+}
+
+// NewQueryEngineWithCache creates a query engine that caches up to size search results.
+// Cached results are discarded whenever the storage is written to. A size of zero or less disables caching.
+func NewQueryEngineWithCache(storage Storage, size int, options ...QueryEngineOption) *QueryEngine {
+	qe := NewQueryEngine(storage, options...)
+	if size > 0 {
+		qe.resultCache = newQueryCache(size)
+	}
+	return qe
+}
+
+// CacheStats returns query cache hit and miss counters; all values are zero when caching is disabled
+func (qe *QueryEngine) CacheStats() QueryCacheStats {
+	if qe.resultCache == nil {
+		return QueryCacheStats{}
+	}
+	return qe.resultCache.stats()
+}
+
+// cachedSearch returns a cached result for the search when one is still valid,
+// otherwise runs search and caches its result
+func (qe *QueryEngine) cachedSearch(
+	searchType, query string, options QueryOptions, search func() (*SearchResult, error),
+) (*SearchResult, error) {
+	search = qe.versionedSearch(search)
+	if qe.resultCache == nil {
+		return search()
+	}
+
+	key, err := queryCacheKey(searchType, query, options)
+	if err != nil {
+		return search()
+	}
+
+	// Read the generation before searching so a write during the search leaves the entry stale
+	generation := qe.storage.Generation()
+	if result, ok := qe.resultCache.get(key, generation); ok {
+		return result, nil
+	}
+
+	result, err := search()
+	if err != nil {
+		return nil, err
+	}
+	// Partial results would be served to later queries that have time to finish
+	if !result.TimedOut {
+		qe.resultCache.put(key, result.IndexGeneration, result)
+	}
+	return result, nil
+}
+
+// versionedSearch wraps search so its result records the index generation and build
+// time. Both are read before searching, so a write during the search makes the result
+// look stale rather than current.
+func (qe *QueryEngine) versionedSearch(search func() (*SearchResult, error)) func() (*SearchResult, error) {
+	return func() (*SearchResult, error) {
+		generation, buildTime := qe.storage.Generation(), qe.storage.UpdatedAt()
+		result, err := search()
+		if err != nil {
+			return nil, err
+		}
+		result.IndexGeneration = generation
+		result.IndexBuildTime = buildTime
+		return result, nil
+	}
+}
+
+// SetTokenEstimator replaces the estimator used for token counting and truncation.
+// Passing nil restores the default estimator.
+func (qe *QueryEngine) SetTokenEstimator(estimator TokenEstimator) {
+	if estimator == nil {
+		estimator = DefaultTokenEstimator()
+	}
+	qe.tokenEstimator = estimator
+}
+
+// TokenEstimator returns the estimator used for token counting and truncation
+func (qe *QueryEngine) TokenEstimator() TokenEstimator {
+	if qe.tokenEstimator == nil {
+		return DefaultTokenEstimator()
 	}
+	return qe.tokenEstimator
 }
 
 // SearchByName searches for entities by exact name match
@@ -110,6 +270,23 @@ func (qe *QueryEngine) SearchByName(name string) (*SearchResult, error) {
 
 // SearchByNameWithOptions searches for entities by name with additional options
 func (qe *QueryEngine) SearchByNameWithOptions(name string, options QueryOptions) (*SearchResult, error) {
+	return qe.SearchByNameWithContext(context.Background(), name, options)
+}
+
+// SearchByNameWithContext searches for entities by name, stopping when ctx is done.
+// It fails if ctx is done before the search starts; a call graph cut short by ctx is
+// returned partially with TimedOut set.
+func (qe *QueryEngine) SearchByNameWithContext(ctx context.Context, name string, options QueryOptions) (*SearchResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("search cancelled: %w", err)
+	}
+	return qe.cachedSearch("name", name, options, func() (*SearchResult, error) {
+		return qe.searchByName(ctx, name, options)
+	})
+}
+
+// searchByName performs an uncached name search
+func (qe *QueryEngine) searchByName(ctx context.Context, name string, options QueryOptions) (*SearchResult, error) {
 	result := &SearchResult{
 		Query:      name,
 		SearchType: "name",
@@ -118,7 +295,7 @@ func (qe *QueryEngine) SearchByNameWithOptions(name string, options QueryOptions
 	}
 
 	// Query the storage for matching entries
-	queryResults, err := qe.storage.QueryByName(name)
+	queryResults, err := qe.queryNameWithOptions(name, &options)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query by name: %w", err)
 	}
@@ -129,14 +306,25 @@ func (qe *QueryEngine) SearchByNameWithOptions(name string, options QueryOptions
 		result.Entries[i] = SearchResultEntry(qr)
 	}
 
+	// Dotted names that match nothing literally are resolved as Type.Method or package.Name
+	if qualifier, member, ok := splitQualifiedName(name); ok && len(result.Entries) == 0 {
+		result.Entries, err = qe.searchQualifiedName(qualifier, member, options)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve qualified name: %w", err)
+		}
+	}
+	result.Entries = filterEntries(result.Entries, &options)
+
 	// Add call graph information if requested
 	if (options.IncludeCallers || options.IncludeCallees) && len(result.Entries) > 0 {
 		// Find the first function entry to get call graph for
 		for _, entry := range result.Entries {
 			if entry.IndexEntry.Type == EntityTypeFunction {
-				callGraph, err := qe.GetCallGraphWithOptions(entry.IndexEntry.Name, options)
+				callGraph, err := qe.GetCallGraphWithContext(ctx, entry.IndexEntry.Name, options)
 				if err == nil {
 					result.CallGraph = callGraph
+					result.TimedOut = callGraph.TimedOut
+					result.Truncated = result.Truncated || callGraph.Truncated
 				}
 				break
 			}
@@ -151,70 +339,240 @@ func (qe *QueryEngine) SearchByNameWithOptions(name string, options QueryOptions
 
 // SearchByType searches for all entities of a specific type
 func (qe *QueryEngine) SearchByType(entityType string) (*SearchResult, error) {
+	return qe.SearchByTypeWithOptions(entityType, QueryOptions{})
+}
+
+// SearchByTypeWithOptions searches for all entities of a specific type with query options
+func (qe *QueryEngine) SearchByTypeWithOptions(entityType string, options QueryOptions) (*SearchResult, error) {
+	return qe.searchByTypes([]string{entityType}, entityType, "type", options)
+}
+
+// SearchByTypesWithOptions searches for all entities of several types in one pass.
+// Results are deduplicated and a single token budget is applied across the merged set.
+func (qe *QueryEngine) SearchByTypesWithOptions(entityTypes []string, options QueryOptions) (*SearchResult, error) {
+	return qe.searchByTypes(entityTypes, strings.Join(entityTypes, ","), "types", options)
+}
+
+// searchByTypes runs a type search and labels the result with the given query and search type
+func (qe *QueryEngine) searchByTypes(entityTypes []string, query, searchType string, options QueryOptions) (*SearchResult, error) {
+	return qe.cachedSearch(searchType, query, options, func() (*SearchResult, error) {
+		return qe.searchByTypesUncached(entityTypes, query, searchType, options)
+	})
+}
+
+// searchByTypesUncached performs a type search without consulting the cache
+func (qe *QueryEngine) searchByTypesUncached(
+	entityTypes []string, query, searchType string, options QueryOptions,
+) (*SearchResult, error) {
 	result := &SearchResult{
-		Query:      entityType,
-		SearchType: "type",
+		Query:      query,
+		SearchType: searchType,
 		ExecutedAt: time.Now(),
-		Options:    &QueryOptions{}, // Default empty options
+		Options:    &options,
 	}
 
-	// Query the storage for matching entries
-	queryResults, err := qe.storage.QueryByType(entityType)
+	entries, err := qe.collectEntriesByTypes(entityTypes)
 	if err != nil {
-		return nil, fmt.Errorf("failed to query by type: %w", err)
+		return nil, err
 	}
+	result.Entries = filterEntries(entries, &options)
 
-	// Convert storage results to query result entries
-	result.Entries = make([]SearchResultEntry, len(queryResults))
-	for i, qr := range queryResults {
-		result.Entries[i] = SearchResultEntry(qr)
-	}
+	// Add call graph information if requested and functions are found
+	qe.attachFirstFunctionCallGraph(result, options)
 
-	// Estimate tokens
-	result.TokenCount = qe.EstimateTokens(result)
+	// Apply token limits and estimate tokens
+	qe.applyTokenLimits(result, options.MaxTokens)
 
 	return result, nil
 }
 
-// SearchByTypeWithOptions searches for all entities of a specific type with query options
-func (qe *QueryEngine) SearchByTypeWithOptions(entityType string, options QueryOptions) (*SearchResult, error) {
-	result := &SearchResult{
-		Query:      entityType,
-		SearchType: "type",
-		ExecutedAt: time.Now(),
-		Options:    &options,
+// collectEntriesByTypes queries storage for each entity type and merges the results,
+// skipping repeated types and entries already collected under another type
+func (qe *QueryEngine) collectEntriesByTypes(entityTypes []string) ([]SearchResultEntry, error) {
+	entries := []SearchResultEntry{}
+	seenTypes := make(map[string]bool)
+	seenEntries := make(map[string]bool)
+
+	for _, entityType := range entityTypes {
+		if seenTypes[entityType] {
+			continue
+		}
+		seenTypes[entityType] = true
+
+		queryResults, err := qe.storage.QueryByType(entityType)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query by type: %w", err)
+		}
+
+		for _, qr := range queryResults {
+			key := fmt.Sprintf("%s:%s:%s:%d", qr.IndexEntry.Type, qr.IndexEntry.Name, qr.IndexEntry.File, qr.IndexEntry.StartLine)
+			if seenEntries[key] {
+				continue
+			}
+			seenEntries[key] = true
+			entries = append(entries, SearchResultEntry(qr))
+		}
 	}
 
-	// Query the storage for matching entries
-	queryResults, err := qe.storage.QueryByType(entityType)
-	if err != nil {
-		return nil, fmt.Errorf("failed to query by type: %w", err)
+	return entries, nil
+}
+
+// filterEntries applies the language, scope, exported-only and changed-only filters of options
+func filterEntries(entries []SearchResultEntry, options *QueryOptions) []SearchResultEntry {
+	entries = filterEntriesByScope(filterEntriesByLanguage(entries, options.Language), options.Scope)
+	if options.ExportedOnly {
+		entries = filterExportedEntries(entries)
 	}
+	if options.ChangedOnly {
+		entries = filterEntriesByFiles(entries, options.ChangedFiles)
+	}
+	return entries
+}
 
-	// Convert storage results to query result entries
-	result.Entries = make([]SearchResultEntry, len(queryResults))
-	for i, qr := range queryResults {
-		result.Entries[i] = SearchResultEntry(qr)
+// filterEntriesByFiles keeps entries defined in one of files
+func filterEntriesByFiles(entries []SearchResultEntry, files []string) []SearchResultEntry {
+	keep := make(map[string]bool, len(files))
+	for _, file := range files {
+		keep[filepath.Clean(file)] = true
 	}
 
-	// Add call graph information if requested and functions are found
-	if (options.IncludeCallers || options.IncludeCallees) && entityType == EntityTypeFunction && len(result.Entries) > 0 {
-		// Get call graph for the first function found
-		for _, entry := range result.Entries {
-			if entry.IndexEntry.Type == EntityTypeFunction {
-				callGraph, err := qe.GetCallGraphWithOptions(entry.IndexEntry.Name, options)
-				if err == nil {
-					result.CallGraph = callGraph
+	filtered := make([]SearchResultEntry, 0, len(entries))
+	for i := range entries {
+		if keep[filepath.Clean(entries[i].IndexEntry.File)] {
+			filtered = append(filtered, entries[i])
+		}
+	}
+	return filtered
+}
+
+// filterEntriesByLanguage keeps entries whose defining file is in language, ignoring case.
+// An empty language keeps every entry.
+func filterEntriesByLanguage(entries []SearchResultEntry, language string) []SearchResultEntry {
+	if language == "" {
+		return entries
+	}
+
+	filtered := make([]SearchResultEntry, 0, len(entries))
+	for i := range entries {
+		if strings.EqualFold(EntryLanguage(&entries[i]), language) {
+			filtered = append(filtered, entries[i])
+		}
+	}
+	return filtered
+}
+
+// EntryLanguage returns the language of the file defining entry. Indexes built before
+// entries recorded their language fall back to the file data in the entry's chunk.
+func EntryLanguage(entry *SearchResultEntry) string {
+	if entry.IndexEntry.Language != "" || entry.ChunkData == nil {
+		return entry.IndexEntry.Language
+	}
+	for i := range entry.ChunkData.FileData {
+		if entry.ChunkData.FileData[i].Path == entry.IndexEntry.File {
+			return entry.ChunkData.FileData[i].Language
+		}
+	}
+	return ""
+}
+
+// filterEntriesByScope keeps variables and constants declared in scope. Other entities have
+// no scope and are kept. An empty scope keeps every entry.
+func filterEntriesByScope(entries []SearchResultEntry, scope string) []SearchResultEntry {
+	if scope == "" {
+		return entries
+	}
+
+	filtered := make([]SearchResultEntry, 0, len(entries))
+	for i := range entries {
+		entryType := entries[i].IndexEntry.Type
+		if entryType != "variable" && entryType != "constant" {
+			filtered = append(filtered, entries[i])
+			continue
+		}
+		if entryScope(&entries[i]) == scope {
+			filtered = append(filtered, entries[i])
+		}
+	}
+	return filtered
+}
+
+// entryScope returns the scope of a variable or constant entry from the declaration in
+// its chunk, or "" when the declaration is missing or predates scopes being recorded
+func entryScope(entry *SearchResultEntry) string {
+	if entry.ChunkData == nil {
+		return ""
+	}
+	for i := range entry.ChunkData.FileData {
+		fileData := &entry.ChunkData.FileData[i]
+		if fileData.Path != entry.IndexEntry.File {
+			continue
+		}
+		switch entry.IndexEntry.Type {
+		case "variable":
+			for _, variable := range fileData.Variables {
+				if variable.Name == entry.IndexEntry.Name && variable.StartLine == entry.IndexEntry.StartLine {
+					return variable.Scope
+				}
+			}
+		case "constant":
+			for _, constant := range fileData.Constants {
+				if constant.Name == entry.IndexEntry.Name && constant.StartLine == entry.IndexEntry.StartLine {
+					return constant.Scope
 				}
-				break
 			}
 		}
 	}
+	return ""
+}
 
-	// Apply token limits and estimate tokens
-	qe.applyTokenLimits(result, options.MaxTokens)
+// filterExportedEntries keeps entries exported by their defining file, as recorded by
+// the language parser: capitalized Go names, Python names without a leading underscore,
+// public Java members and so on
+func filterExportedEntries(entries []SearchResultEntry) []SearchResultEntry {
+	filtered := make([]SearchResultEntry, 0, len(entries))
+	for i := range entries {
+		if isExportedEntry(&entries[i]) {
+			filtered = append(filtered, entries[i])
+		}
+	}
+	return filtered
+}
 
-	return result, nil
+// isExportedEntry reports whether the file data in the entry's chunk exports the entry's name
+func isExportedEntry(entry *SearchResultEntry) bool {
+	if entry.ChunkData == nil {
+		return false
+	}
+	for i := range entry.ChunkData.FileData {
+		fileData := &entry.ChunkData.FileData[i]
+		if fileData.Path != entry.IndexEntry.File {
+			continue
+		}
+		for _, export := range fileData.Exports {
+			if export.Name == entry.IndexEntry.Name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// attachFirstFunctionCallGraph adds the call graph of the first function entry when requested
+func (qe *QueryEngine) attachFirstFunctionCallGraph(result *SearchResult, options QueryOptions) {
+	if !options.IncludeCallers && !options.IncludeCallees {
+		return
+	}
+
+	for _, entry := range result.Entries {
+		if entry.IndexEntry.Type == EntityTypeFunction {
+			callGraph, err := qe.GetCallGraphWithOptions(entry.IndexEntry.Name, options)
+			if err == nil {
+				result.CallGraph = callGraph
+				result.Truncated = result.Truncated || callGraph.Truncated
+			}
+			break
+		}
+	}
 }
 
 // SearchByPattern searches for entities matching a pattern (supports wildcards)
@@ -225,6 +583,13 @@ func (qe *QueryEngine) SearchByPattern(pattern string) (*SearchResult, error) {
 
 // SearchByPatternWithOptions searches for entities matching a pattern with query options
 func (qe *QueryEngine) SearchByPatternWithOptions(pattern string, options QueryOptions) (*SearchResult, error) {
+	return qe.cachedSearch("pattern", pattern, options, func() (*SearchResult, error) {
+		return qe.searchByPattern(pattern, options)
+	})
+}
+
+// searchByPattern performs an uncached pattern search
+func (qe *QueryEngine) searchByPattern(pattern string, options QueryOptions) (*SearchResult, error) {
 	result := &SearchResult{
 		Query:      pattern,
 		SearchType: "pattern",
@@ -232,58 +597,24 @@ func (qe *QueryEngine) SearchByPatternWithOptions(pattern string, options QueryO
 		Options:    &options,
 	}
 
+	candidates, err := qe.collectEntriesByTypes(patternEntityTypes(&options))
+	if err != nil {
+		return nil, err
+	}
+
 	// For now, implement simple prefix matching with *
 	// In a full implementation, this could use more sophisticated pattern matching
 	var allEntries []SearchResultEntry
-
-	// Get all entity types and search each
-	// Search functions, variables, constants
-	basicEntityTypes := []string{EntityTypeFunction, EntityTypeVariable, EntityTypeConstant}
-	for _, entityType := range basicEntityTypes {
-		queryResults, err := qe.storage.QueryByType(entityType)
-		if err != nil {
-			continue // Skip errors and continue with other types
-		}
-
-		for _, qr := range queryResults {
-			if qe.matchesPattern(qr.IndexEntry.Name, pattern) {
-				allEntries = append(allEntries, SearchResultEntry(qr))
-			}
-		}
-	}
-
-	// Search all type kinds (struct, interface, etc.) if IncludeTypes is enabled
-	if options.IncludeTypes {
-		typeKinds := []string{EntityKindStruct, EntityKindInterface, EntityKindType, EntityKindAlias, EntityKindEnum, EntityKindClass}
-		for _, typeKind := range typeKinds {
-			queryResults, err := qe.storage.QueryByType(typeKind)
-			if err != nil {
-				continue // Skip errors and continue with other types
-			}
-
-			for _, qr := range queryResults {
-				if qe.matchesPattern(qr.IndexEntry.Name, pattern) {
-					allEntries = append(allEntries, SearchResultEntry(qr))
-				}
-			}
+	for _, candidate := range candidates {
+		if qe.matchesNamePattern(candidate.IndexEntry.Name, pattern, &options) {
+			allEntries = append(allEntries, candidate)
 		}
 	}
 
-	result.Entries = allEntries
+	result.Entries = filterEntries(allEntries, &options)
 
 	// Add call graph information if requested and functions are found
-	if (options.IncludeCallers || options.IncludeCallees) && len(result.Entries) > 0 {
-		// Find the first function entry to get call graph for
-		for _, entry := range result.Entries {
-			if entry.IndexEntry.Type == EntityTypeFunction {
-				callGraph, err := qe.GetCallGraphWithOptions(entry.IndexEntry.Name, options)
-				if err == nil {
-					result.CallGraph = callGraph
-				}
-				break
-			}
-		}
-	}
+	qe.attachFirstFunctionCallGraph(result, options)
 
 	// Apply token limits and estimate tokens
 	qe.applyTokenLimits(result, options.MaxTokens)
@@ -291,13 +622,42 @@ func (qe *QueryEngine) SearchByPatternWithOptions(pattern string, options QueryO
 	return result, nil
 }
 
-// SearchInFile searches for all entities within a specific file
+// patternEntityTypes returns the stored kinds a pattern search scans
+func patternEntityTypes(options *QueryOptions) []string {
+	if len(options.EntityTypes) == 0 {
+		entityTypes := []string{EntityTypeFunction, EntityTypeVariable, EntityTypeConstant}
+		if options.IncludeTypes {
+			entityTypes = append(entityTypes, TypeKinds()...)
+		}
+		return entityTypes
+	}
+
+	var entityTypes []string
+	for _, entityType := range options.EntityTypes {
+		if entityType == EntityTypeType {
+			entityTypes = append(entityTypes, TypeKinds()...)
+		} else {
+			entityTypes = append(entityTypes, entityType)
+		}
+	}
+	return entityTypes
+}
+
+// SearchInFile searches for all entities within a specific file, given as stored in the
+// index: relative to the repository root. RepoRelativePath converts absolute client paths.
 func (qe *QueryEngine) SearchInFile(filePath string) (*SearchResult, error) {
 	return qe.SearchInFileWithOptions(filePath, QueryOptions{})
 }
 
 // SearchInFileWithOptions searches for all entities within a specific file with query options
 func (qe *QueryEngine) SearchInFileWithOptions(filePath string, options QueryOptions) (*SearchResult, error) {
+	return qe.versionedSearch(func() (*SearchResult, error) {
+		return qe.searchInFile(filePath, options)
+	})()
+}
+
+// searchInFile performs a file search
+func (qe *QueryEngine) searchInFile(filePath string, options QueryOptions) (*SearchResult, error) {
 	result := &SearchResult{
 		Query:      filePath,
 		SearchType: "file",
@@ -305,13 +665,15 @@ func (qe *QueryEngine) SearchInFileWithOptions(filePath string, options QueryOpt
 		Options:    &options,
 	}
 
-	// Get all entity types and filter by file
+	// Get all entity types and filter by file, which is matched exactly as stored:
+	// repo-relative, see RepoRelativePath
+	file := filepath.Clean(filePath)
 	var allEntries []SearchResultEntry
 	entityTypes := []string{EntityTypeFunction, EntityTypeVariable, EntityTypeConstant}
 
 	// For types, we need to search for all specific type kinds (struct, interface, etc.)
 	// since they are stored by their specific kind, not the generic "type"
-	typeKinds := []string{EntityKindStruct, EntityKindInterface, EntityKindType, EntityKindAlias, EntityKindEnum, EntityKindClass}
+	typeKinds := TypeKinds()
 
 	// Search for functions, variables, constants
 	for _, entityType := range entityTypes {
@@ -321,8 +683,7 @@ func (qe *QueryEngine) SearchInFileWithOptions(filePath string, options QueryOpt
 		}
 
 		for _, qr := range queryResults {
-			// Match file path (handle both absolute and relative paths)
-			if qr.IndexEntry.File == filePath || filepath.Base(qr.IndexEntry.File) == filepath.Base(filePath) {
+			if filepath.Clean(qr.IndexEntry.File) == file {
 				allEntries = append(allEntries, SearchResultEntry(qr))
 			}
 		}
@@ -337,15 +698,17 @@ func (qe *QueryEngine) SearchInFileWithOptions(filePath string, options QueryOpt
 			}
 
 			for _, qr := range queryResults {
-				// Match file path (handle both absolute and relative paths)
-				if qr.IndexEntry.File == filePath || filepath.Base(qr.IndexEntry.File) == filepath.Base(filePath) {
+				if filepath.Clean(qr.IndexEntry.File) == file {
 					allEntries = append(allEntries, SearchResultEntry(qr))
 				}
 			}
 		}
 	}
 
-	result.Entries = allEntries
+	result.Entries = filterEntries(allEntries, &options)
+	if options.OrderBySource {
+		sortBySourceOrder(result.Entries)
+	}
 
 	// Add call graph information if requested
 	if (options.IncludeCallers || options.IncludeCallees) && len(result.Entries) > 0 {
@@ -355,6 +718,7 @@ func (qe *QueryEngine) SearchInFileWithOptions(filePath string, options QueryOpt
 				callGraph, err := qe.GetCallGraphWithOptions(entry.IndexEntry.Name, options)
 				if err == nil {
 					result.CallGraph = callGraph
+					result.Truncated = result.Truncated || callGraph.Truncated
 				}
 				break
 			}
@@ -367,6 +731,27 @@ func (qe *QueryEngine) SearchInFileWithOptions(filePath string, options QueryOpt
 	return result, nil
 }
 
+// sortBySourceOrder orders entries as they are declared: by file, then by start line.
+// Entries declared on the same line keep their relative order.
+func sortBySourceOrder(entries []SearchResultEntry) {
+	slices.SortStableFunc(entries, func(a, b SearchResultEntry) int {
+		if byFile := strings.Compare(a.IndexEntry.File, b.IndexEntry.File); byFile != 0 {
+			return byFile
+		}
+		return a.IndexEntry.StartLine - b.IndexEntry.StartLine
+	})
+}
+
+// ListFiles returns the sorted paths of all indexed files
+func (qe *QueryEngine) ListFiles() ([]string, error) {
+	return qe.storage.ListFiles()
+}
+
+// GetFileContext returns the indexed context of a single file, including imports and exports
+func (qe *QueryEngine) GetFileContext(filePath string) (*models.FileContext, error) {
+	return qe.storage.GetFileContext(filePath)
+}
+
 // GetCallGraph retrieves the call graph for a function
 func (qe *QueryEngine) GetCallGraph(functionName string, maxDepth int) (*CallGraphInfo, error) {
 	// For backward compatibility, include both callers and callees
@@ -380,57 +765,181 @@ func (qe *QueryEngine) GetCallGraph(functionName string, maxDepth int) (*CallGra
 
 // GetCallGraphWithOptions retrieves the call graph for a function with selective inclusion
 func (qe *QueryEngine) GetCallGraphWithOptions(functionName string, options QueryOptions) (*CallGraphInfo, error) {
+	return qe.GetCallGraphWithContext(context.Background(), functionName, options)
+}
+
+// GetCallGraphWithContext retrieves the call graph for a function, stopping the traversal
+// when ctx is done. The entries found so far are returned with TimedOut set.
+func (qe *QueryEngine) GetCallGraphWithContext(ctx context.Context, functionName string, options QueryOptions) (*CallGraphInfo, error) {
 	callGraph := &CallGraphInfo{
 		Function: functionName,
 		Depth:    options.MaxDepth,
 	}
 
-	// Use default depth of 1 if not specified or invalid
-	maxDepth := options.MaxDepth
-	if maxDepth <= 0 {
-		maxDepth = DefaultMaxDepth
-	}
+	// Cycles and the node budget are shared by both directions
+	traversal := newCallGraphTraversal()
+	traversal.maxNodes = options.MaxNodes
 
 	// Only retrieve callers if requested
 	if options.IncludeCallers {
-		callers, err := qe.populateCallGraphEntriesWithDepth(functionName, true, maxDepth, 0, make(map[string]bool))
+		callerDepth := options.callGraphDepth(options.CallerDepth)
+		callers, err := qe.populateCallGraphEntriesWithDepth(ctx, functionName, true, callerDepth, 0, traversal)
 		if err != nil {
 			return nil, fmt.Errorf("failed to query callers: %w", err)
 		}
-		callGraph.Callers = callers
+		callGraph.Callers = dedupeCallGraphEntries(callers)
 	}
 
 	// Only retrieve callees if requested
 	if options.IncludeCallees {
-		callees, err := qe.populateCallGraphEntriesWithDepth(functionName, false, maxDepth, 0, make(map[string]bool))
+		calleeDepth := options.callGraphDepth(options.CalleeDepth)
+		callees, err := qe.populateCallGraphEntriesWithDepth(ctx, functionName, false, calleeDepth, 0, traversal)
 		if err != nil {
 			return nil, fmt.Errorf("failed to query callees: %w", err)
 		}
-		callGraph.Callees = callees
+		callGraph.Callees = dedupeCallGraphEntries(callees)
 	}
 
+	callGraph.Cycles = traversal.cycles
+	callGraph.TimedOut = traversal.timedOut
+	callGraph.Truncated = traversal.truncated
+
 	return callGraph, nil
 }
 
-// populateCallGraphEntriesWithDepth recursively populates call graph entries up to maxDepth
+// callGraphDepth resolves the traversal depth for one direction, falling back to MaxDepth
+// when the direction has no depth of its own and to DefaultMaxDepth when neither is set
+func (o QueryOptions) callGraphDepth(directionDepth int) int {
+	if directionDepth > 0 {
+		return directionDepth
+	}
+	if o.MaxDepth > 0 {
+		return o.MaxDepth
+	}
+	return DefaultMaxDepth
+}
+
+// callGraphTraversal tracks the current traversal path and the cycles found along it
+type callGraphTraversal struct {
+	path       []string
+	onPath     map[string]bool
+	cycles     [][]string
+	seenCycles map[string]bool
+	timedOut   bool // The context ended before the traversal completed
+	maxNodes   int  // Entries to collect before stopping, unlimited when zero
+	nodes      int  // Entries collected so far
+	truncated  bool // The traversal stopped at maxNodes
+}
+
+// newCallGraphTraversal creates an empty traversal state
+func newCallGraphTraversal() *callGraphTraversal {
+	return &callGraphTraversal{
+		onPath:     make(map[string]bool),
+		seenCycles: make(map[string]bool),
+	}
+}
+
+// push adds a function to the current traversal path
+func (t *callGraphTraversal) push(functionName string) {
+	t.path = append(t.path, functionName)
+	t.onPath[functionName] = true
+}
+
+// pop removes the most recent function from the current traversal path
+func (t *callGraphTraversal) pop() {
+	last := t.path[len(t.path)-1]
+	t.path = t.path[:len(t.path)-1]
+	delete(t.onPath, last)
+}
+
+// recordCycle records a cycle if next is already on the traversal path.
+// It returns true when a cycle was detected so the caller can stop descending.
+func (t *callGraphTraversal) recordCycle(next string, isCallers bool) bool {
+	if !t.onPath[next] {
+		return false
+	}
+
+	start := len(t.path) - 1
+	for start > 0 && t.path[start] != next {
+		start--
+	}
+
+	// Build the cycle in call order (caller -> callee)
+	loop := make([]string, 0, len(t.path)-start+1)
+	if isCallers {
+		// Caller traversal walks against call direction, so reverse the path
+		loop = append(loop, next)
+		for i := len(t.path) - 1; i >= start; i-- {
+			loop = append(loop, t.path[i])
+		}
+	} else {
+		loop = append(loop, t.path[start:]...)
+		loop = append(loop, next)
+	}
+
+	key := canonicalCycleKey(loop)
+	if !t.seenCycles[key] {
+		t.seenCycles[key] = true
+		t.cycles = append(t.cycles, loop)
+	}
+	return true
+}
+
+// canonicalCycleKey returns a rotation-independent key for a closed cycle
+func canonicalCycleKey(cycle []string) string {
+	// Drop the closing element, which repeats the first
+	nodes := cycle[:len(cycle)-1]
+
+	best := ""
+	for i := range nodes {
+		rotated := append(append([]string{}, nodes[i:]...), nodes[:i]...)
+		candidate := strings.Join(rotated, "->")
+		if best == "" || candidate < best {
+			best = candidate
+		}
+	}
+	return best
+}
+
+// stopped reports whether ctx is done, recording that the traversal was cut short
+func (t *callGraphTraversal) stopped(ctx context.Context) bool {
+	if ctx.Err() != nil {
+		t.timedOut = true
+	}
+	return t.timedOut
+}
+
+// full reports whether the node budget is spent, recording that the traversal was cut short.
+// It is checked before each entry is added so the traversal stops rather than collecting
+// entries that would be discarded.
+func (t *callGraphTraversal) full() bool {
+	if t.maxNodes > 0 && t.nodes >= t.maxNodes {
+		t.truncated = true
+	}
+	return t.truncated
+}
+
+// populateCallGraphEntriesWithDepth recursively populates call graph entries up to maxDepth.
+// It returns the entries found so far once ctx is done or the node budget is spent.
 func (qe *QueryEngine) populateCallGraphEntriesWithDepth(
+	ctx context.Context,
 	functionName string,
 	isCallers bool,
 	maxDepth, currentDepth int,
-	visited map[string]bool,
+	traversal *callGraphTraversal,
 ) ([]CallGraphEntry, error) {
 	entries := []CallGraphEntry{}
 
-	// Stop if we've reached max depth
-	if currentDepth >= maxDepth {
+	// Stop if we've reached max depth or run out of time
+	if currentDepth >= maxDepth || traversal.stopped(ctx) || traversal.truncated {
 		return entries, nil
 	}
 
 	// Prevent infinite loops in circular call graphs
-	if visited[functionName] {
+	if traversal.onPath[functionName] {
 		return entries, nil
 	}
-	visited[functionName] = true
+	traversal.push(functionName)
 
 	if isCallers {
 		// Handle callers: functions that call this function
@@ -440,12 +949,22 @@ func (qe *QueryEngine) populateCallGraphEntriesWithDepth(
 		}
 
 		for _, caller := range callers {
+			if traversal.stopped(ctx) || traversal.full() {
+				break
+			}
 			entry := qe.createCallGraphEntry(caller.Caller, caller.CallerFile, caller.Line)
+			entry.Depth = currentDepth + 1
 			entries = append(entries, entry)
+			traversal.nodes++
+
+			// Don't descend into a function that is already on the current path
+			if traversal.recordCycle(caller.Caller, isCallers) {
+				continue
+			}
 
 			// Recursively get callers of this caller
 			if currentDepth+1 < maxDepth {
-				subEntries, err := qe.populateCallGraphEntriesWithDepth(caller.Caller, isCallers, maxDepth, currentDepth+1, visited)
+				subEntries, err := qe.populateCallGraphEntriesWithDepth(ctx, caller.Caller, isCallers, maxDepth, currentDepth+1, traversal)
 				if err == nil {
 					entries = append(entries, subEntries...)
 				}
@@ -459,12 +978,22 @@ func (qe *QueryEngine) populateCallGraphEntriesWithDepth(
 		}
 
 		for _, callee := range callees {
+			if traversal.stopped(ctx) || traversal.full() {
+				break
+			}
 			entry := qe.createCallGraphEntry(callee.Callee, callee.File, callee.Line)
+			entry.Depth = currentDepth + 1
 			entries = append(entries, entry)
+			traversal.nodes++
+
+			// Don't descend into a function that is already on the current path
+			if traversal.recordCycle(callee.Callee, isCallers) {
+				continue
+			}
 
 			// Recursively get callees of this callee
 			if currentDepth+1 < maxDepth {
-				subEntries, err := qe.populateCallGraphEntriesWithDepth(callee.Callee, isCallers, maxDepth, currentDepth+1, visited)
+				subEntries, err := qe.populateCallGraphEntriesWithDepth(ctx, callee.Callee, isCallers, maxDepth, currentDepth+1, traversal)
 				if err == nil {
 					entries = append(entries, subEntries...)
 				}
@@ -472,12 +1001,30 @@ func (qe *QueryEngine) populateCallGraphEntriesWithDepth(
 		}
 	}
 
-	// Remove this function from visited set for other branches
-	delete(visited, functionName)
+	// Remove this function from the path for other branches
+	traversal.pop()
 
 	return entries, nil
 }
 
+// dedupeCallGraphEntries keeps one entry per function, preferring the shallowest occurrence.
+// Functions reachable through several paths would otherwise be reported once per path.
+func dedupeCallGraphEntries(entries []CallGraphEntry) []CallGraphEntry {
+	deduped := make([]CallGraphEntry, 0, len(entries))
+	positions := make(map[string]int, len(entries))
+	for _, entry := range entries {
+		if i, seen := positions[entry.Function]; seen {
+			if entry.Depth < deduped[i].Depth {
+				deduped[i] = entry
+			}
+			continue
+		}
+		positions[entry.Function] = len(deduped)
+		deduped = append(deduped, entry)
+	}
+	return deduped
+}
+
 // createCallGraphEntry is a helper function to create a CallGraphEntry with chunk data
 func (qe *QueryEngine) createCallGraphEntry(functionName, file string, line int) CallGraphEntry {
 	// Load chunk data for the function
@@ -517,6 +1064,14 @@ func (qe *QueryEngine) FormatResults(result *SearchResult, format string) ([]byt
 		return json.MarshalIndent(result, "", "  ")
 	case "text", "":
 		return qe.formatAsText(result), nil
+	case "markdown", "md":
+		return qe.formatAsMarkdown(result), nil
+	case "jsonl":
+		var output bytes.Buffer
+		if err := WriteJSONLines(&output, result); err != nil {
+			return nil, err
+		}
+		return output.Bytes(), nil
 	default:
 		return nil, fmt.Errorf("unsupported format: %s", format)
 	}
@@ -527,16 +1082,8 @@ func (qe *QueryEngine) EstimateTokens(result *SearchResult) int {
 	tokenCount := 0
 
 	// Estimate tokens for each entry
-	for _, entry := range result.Entries {
-		// Basic entry information
-		tokenCount += len(strings.Fields(entry.IndexEntry.Name)) +
-			len(strings.Fields(entry.IndexEntry.Signature)) +
-			TokenOverhead
-
-		// Chunk data tokens (if present)
-		if entry.ChunkData != nil {
-			tokenCount += entry.ChunkData.TokenCount
-		}
+	for i := range result.Entries {
+		tokenCount += qe.estimateEntryTokens(&result.Entries[i])
 	}
 
 	// Call graph tokens
@@ -553,13 +1100,19 @@ func (qe *QueryEngine) EstimateTokens(result *SearchResult) int {
 
 // Helper methods
 
+// MatchesPattern reports whether name matches a glob or regex pattern,
+// using the same syntax as SearchByPattern
+func (qe *QueryEngine) MatchesPattern(name, pattern string) bool {
+	return qe.matchesPattern(name, pattern, false)
+}
+
 // matchesPattern supports both glob and regex patterns with automatic detection
-func (qe *QueryEngine) matchesPattern(name, pattern string) bool {
+func (qe *QueryEngine) matchesPattern(name, pattern string, caseInsensitive bool) bool {
 	// Detect pattern type and route accordingly
 	if qe.isRegexPattern(pattern) {
-		return qe.matchesRegex(name, pattern)
+		return qe.matchesRegex(name, pattern, caseInsensitive)
 	}
-	return qe.matchesGlob(name, pattern)
+	return qe.matchesGlob(name, pattern, caseInsensitive)
 }
 
 // isRegexPattern detects if pattern uses regex syntax
@@ -617,8 +1170,14 @@ func isAllDigits(s string) bool {
 	return true
 }
 
-// matchesGlob handles shell-style glob patterns with enhanced support
-func (qe *QueryEngine) matchesGlob(name, pattern string) bool {
+// matchesGlob handles shell-style glob patterns with enhanced support.
+// Case-insensitive matching compares lowercased names and patterns.
+func (qe *QueryEngine) matchesGlob(name, pattern string, caseInsensitive bool) bool {
+	if caseInsensitive {
+		name = strings.ToLower(name)
+		pattern = strings.ToLower(pattern)
+	}
+
 	// Handle brace expansion first
 	if strings.Contains(pattern, "{") && strings.Contains(pattern, "}") {
 		return qe.matchesBraceExpansion(name, pattern)
@@ -638,9 +1197,19 @@ func (qe *QueryEngine) matchesGlob(name, pattern string) bool {
 	return matched
 }
 
-// matchesRegex handles full regular expressions with caching
-func (qe *QueryEngine) matchesRegex(name, pattern string) bool {
-	regex, err := qe.getCompiledRegex(pattern)
+// matchesRegex handles full regular expressions with caching.
+// Top-level lookaround assertions are emulated; other unsupported features are converted.
+func (qe *QueryEngine) matchesRegex(name, pattern string, caseInsensitive bool) bool {
+	cleanPattern := stripRegexDelimiters(pattern)
+	if caseInsensitive {
+		cleanPattern = withCaseInsensitiveFlag(cleanPattern)
+	}
+
+	if matcher, ok := qe.getLookaroundMatcher(cleanPattern); ok {
+		return matcher.MatchString(name)
+	}
+
+	regex, err := qe.getCompiledRegex(cleanPattern)
 	if err != nil {
 		// Invalid regex, fall back to exact match
 		return name == pattern
@@ -713,47 +1282,45 @@ func (qe *QueryEngine) matchesBraceExpansion(name, pattern string) bool {
 // getCompiledRegex returns cached regex or compiles new one with thread safety
 func (qe *QueryEngine) getCompiledRegex(pattern string) (*regexp.Regexp, error) {
 	// Strip regex delimiters if present
-	cleanPattern := pattern
-	if strings.HasPrefix(pattern, "/") && strings.HasSuffix(pattern, "/") && len(pattern) > 2 {
-		cleanPattern = pattern[1 : len(pattern)-1]
-	}
+	cleanPattern := stripRegexDelimiters(pattern)
 
 	// Handle Go regex limitations - convert unsupported patterns to supported ones
 	convertedPattern, err := qe.convertUnsupportedRegexFeaturesWithError(cleanPattern, false)
 	if err != nil {
 		return nil, fmt.Errorf("regex pattern contains unsupported features: %w", err)
 	}
-	cleanPattern = convertedPattern
-
-	// Try to get from cache with read lock
-	qe.regexMutex.RLock()
-	if regex, exists := qe.regexCache[cleanPattern]; exists {
-		qe.regexMutex.RUnlock()
-		return regex, nil
-	}
-	qe.regexMutex.RUnlock()
 
-	// Compile and cache with write lock
-	qe.regexMutex.Lock()
-	defer qe.regexMutex.Unlock()
+	return qe.compileAndCacheRegex(convertedPattern)
+}
 
-	// Double-check after acquiring write lock (prevent race condition)
-	if regex, exists := qe.regexCache[cleanPattern]; exists {
-		return regex, nil
+// withCaseInsensitiveFlag adds the i flag to a pattern, merging it into a leading flag group
+// so lookaround emulation still propagates every flag. The flag becomes part of the
+// pattern text, so case-insensitive regexes are cached separately from case-sensitive ones.
+func withCaseInsensitiveFlag(pattern string) string {
+	if leadingFlagGroup.MatchString(pattern) {
+		return "(?i" + pattern[2:]
 	}
+	return "(?i)" + pattern
+}
 
-	regex, err := regexp.Compile(cleanPattern)
-	if err != nil {
-		return nil, err
+// stripRegexDelimiters removes the surrounding slashes from a /pattern/ regex
+func stripRegexDelimiters(pattern string) string {
+	if strings.HasPrefix(pattern, "/") && strings.HasSuffix(pattern, "/") && len(pattern) > 2 {
+		return pattern[1 : len(pattern)-1]
 	}
+	return pattern
+}
 
-	qe.regexCache[cleanPattern] = regex
-	return regex, nil
+// compileAndCacheRegex compiles a Go regex once through the engine's regex cache
+func (qe *QueryEngine) compileAndCacheRegex(cleanPattern string) (*regexp.Regexp, error) {
+	return qe.regexCache.Compile(cleanPattern)
 }
 
 // convertUnsupportedRegexFeatures converts unsupported regex features to supported alternatives.
 // This function handles Go regexp package limitations by converting unsupported patterns
 // to approximate alternatives. Warnings are logged when conversions occur.
+// Pattern matching only falls back to it for lookarounds that cannot be emulated,
+// such as those nested inside groups.
 //
 // Unsupported features that are converted:
 // - Negative lookbehind (?<!pattern): Removed entirely
@@ -997,6 +1564,14 @@ func (qe *QueryEngine) handleRegexConversionResults(
 }
 
 func (qe *QueryEngine) applyTokenLimits(result *SearchResult, maxTokens int) {
+	// Count facets before truncation so clients see the full distribution
+	result.Facets = CountFacets(result.Entries)
+	qe.TruncateToTokenLimit(result, maxTokens)
+}
+
+// TruncateToTokenLimit keeps the leading entries that fit within maxTokens and updates
+// the token count. A maxTokens of 0 or less only updates the token count.
+func (qe *QueryEngine) TruncateToTokenLimit(result *SearchResult, maxTokens int) {
 	if maxTokens <= 0 {
 		result.TokenCount = qe.EstimateTokens(result)
 		return
@@ -1020,10 +1595,31 @@ func (qe *QueryEngine) applyTokenLimits(result *SearchResult, maxTokens int) {
 	result.TokenCount = currentTokens
 }
 
+// CountFacets returns the number of entries per entity type
+func CountFacets(entries []SearchResultEntry) map[string]int {
+	facets := make(map[string]int)
+	for i := range entries {
+		facets[entries[i].IndexEntry.Type]++
+	}
+	return facets
+}
+
+// formatFacets renders facets as "type=count" pairs sorted by type, e.g. "function=12, struct=3"
+func formatFacets(facets map[string]int) string {
+	pairs := make([]string, 0, len(facets))
+	for _, entityType := range slices.Sorted(maps.Keys(facets)) {
+		pairs = append(pairs, fmt.Sprintf("%s=%d", entityType, facets[entityType]))
+	}
+	return strings.Join(pairs, ", ")
+}
+
+// estimateEntryTokens estimates the tokens of an entry and its chunk, using the count stored
+// at index time when the engine estimates as the index does and the entry has one
 func (qe *QueryEngine) estimateEntryTokens(entry *SearchResultEntry) int {
-	tokens := len(strings.Fields(entry.IndexEntry.Name)) +
-		len(strings.Fields(entry.IndexEntry.Signature)) +
-		TokenOverhead
+	tokens := entry.IndexEntry.TokenCount
+	if tokens == 0 || !qe.usesIndexTokenEstimator() {
+		tokens = entryTokenCount(qe.TokenEstimator(), entry.IndexEntry.Name, entry.IndexEntry.Signature)
+	}
 
 	if entry.ChunkData != nil {
 		tokens += entry.ChunkData.TokenCount
@@ -1032,11 +1628,30 @@ func (qe *QueryEngine) estimateEntryTokens(entry *SearchResultEntry) int {
 	return tokens
 }
 
+// entryTokenCount estimates the tokens of an index entry with its name and signature, without its chunk.
+// The default estimator keeps the historical count of a token per word, so default token budgets
+// truncate results as before; other estimators count the characters of the name and signature.
+func entryTokenCount(estimator TokenEstimator, name, signature string) int {
+	if isDefaultTokenEstimator(estimator) {
+		return len(strings.Fields(name)) + len(strings.Fields(signature)) + TokenOverhead
+	}
+	return estimator.EstimateTokens(name) + estimator.EstimateTokens(signature) + TokenOverhead
+}
+
+// usesIndexTokenEstimator reports whether the engine estimates tokens as the index does,
+// so the entry token counts stored at index time hold for it
+func (qe *QueryEngine) usesIndexTokenEstimator() bool {
+	return isDefaultTokenEstimator(qe.TokenEstimator())
+}
+
 func (qe *QueryEngine) formatAsText(result *SearchResult) []byte {
 	var output strings.Builder
 
 	output.WriteString(fmt.Sprintf("Query: %s (type: %s)\n", result.Query, result.SearchType))
 	output.WriteString(fmt.Sprintf("Results: %d entries\n", len(result.Entries)))
+	if len(result.Facets) > 0 {
+		output.WriteString(fmt.Sprintf("Facets: %s\n", formatFacets(result.Facets)))
+	}
 	output.WriteString(fmt.Sprintf("Token count: %d\n", result.TokenCount))
 	if result.Truncated {
 		output.WriteString("Results truncated due to token limit\n")
@@ -1049,6 +1664,9 @@ func (qe *QueryEngine) formatAsText(result *SearchResult) []byte {
 		if entry.IndexEntry.Signature != "" {
 			output.WriteString(fmt.Sprintf("   Signature: %s\n", entry.IndexEntry.Signature))
 		}
+		if function := verboseFunction(result, &entry); function != nil {
+			writeTextFunctionDetails(&output, function)
+		}
 		output.WriteString("\n")
 	}
 
@@ -1078,7 +1696,104 @@ func (qe *QueryEngine) formatAsText(result *SearchResult) []byte {
 				output.WriteString("    (none)\n")
 			}
 		}
+
+		if len(result.CallGraph.Cycles) > 0 {
+			output.WriteString("  Cycles:\n")
+			for _, cycle := range result.CallGraph.Cycles {
+				output.WriteString(fmt.Sprintf("    - %s\n", strings.Join(cycle, " -> ")))
+			}
+		}
 	}
 
 	return []byte(output.String())
 }
+
+// formatAsMarkdown renders results as Markdown suitable for PR descriptions and chat.
+// Output is deterministic: it contains no timestamps and preserves result ordering.
+func (qe *QueryEngine) formatAsMarkdown(result *SearchResult) []byte {
+	var output strings.Builder
+
+	output.WriteString(fmt.Sprintf("## Query: `%s` (type: %s)\n\n", result.Query, result.SearchType))
+	output.WriteString(fmt.Sprintf("- Results: %d entries\n", len(result.Entries)))
+	if len(result.Facets) > 0 {
+		output.WriteString(fmt.Sprintf("- Facets: %s\n", formatFacets(result.Facets)))
+	}
+	output.WriteString(fmt.Sprintf("- Token count: %d\n", result.TokenCount))
+	if result.Truncated {
+		output.WriteString("- Results truncated due to token limit\n")
+	}
+	output.WriteString("\n")
+
+	for i, entry := range result.Entries {
+		output.WriteString(fmt.Sprintf("### %d. %s (%s)\n\n", i+1, entry.IndexEntry.Name, entry.IndexEntry.Type))
+		output.WriteString(fmt.Sprintf("`%s:%d`\n\n", entry.IndexEntry.File, entry.IndexEntry.StartLine))
+		if entry.IndexEntry.Signature != "" {
+			output.WriteString(fmt.Sprintf("```%s\n%s\n```\n\n", markdownFenceLanguage(entry.IndexEntry.File), entry.IndexEntry.Signature))
+		}
+		if function := verboseFunction(result, &entry); function != nil {
+			writeMarkdownFunctionDetails(&output, function)
+		}
+	}
+
+	if result.CallGraph != nil {
+		output.WriteString("<details>\n<summary>Call Graph</summary>\n\n")
+
+		// Show callers section if requested, even if empty
+		if result.Options != nil && result.Options.IncludeCallers {
+			output.WriteString("#### Callers\n\n")
+			writeMarkdownCallGraphEntries(&output, result.CallGraph.Callers)
+		}
+
+		// Show callees section if requested, even if empty
+		if result.Options != nil && result.Options.IncludeCallees {
+			output.WriteString("#### Callees\n\n")
+			writeMarkdownCallGraphEntries(&output, result.CallGraph.Callees)
+		}
+
+		if len(result.CallGraph.Cycles) > 0 {
+			output.WriteString("#### Cycles\n\n")
+			for _, cycle := range result.CallGraph.Cycles {
+				output.WriteString(fmt.Sprintf("- `%s`\n", strings.Join(cycle, " -> ")))
+			}
+			output.WriteString("\n")
+		}
+
+		output.WriteString("</details>\n")
+	}
+
+	return []byte(output.String())
+}
+
+// writeMarkdownCallGraphEntries writes call graph entries as a Markdown bullet list
+func writeMarkdownCallGraphEntries(output *strings.Builder, entries []CallGraphEntry) {
+	if len(entries) == 0 {
+		output.WriteString("- (none)\n\n")
+		return
+	}
+	for _, entry := range entries {
+		output.WriteString(fmt.Sprintf("- `%s` (`%s:%d`)\n", entry.Function, entry.File, entry.Line))
+	}
+	output.WriteString("\n")
+}
+
+// markdownFenceLanguage returns the code fence language hint for a source file
+func markdownFenceLanguage(filePath string) string {
+	switch filepath.Ext(filePath) {
+	case ".go":
+		return "go"
+	case ".py":
+		return "python"
+	case ".java":
+		return "java"
+	case ".c", ".h":
+		return "c"
+	case ".cpp", ".hpp":
+		return "cpp"
+	case ".rb":
+		return "ruby"
+	case ".ts", ".tsx":
+		return "typescript"
+	default:
+		return ""
+	}
+}
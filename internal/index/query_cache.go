@@ -0,0 +1,146 @@
+package index
+
+import (
+	"container/list"
+	"encoding/json"
+	"maps"
+	"sync"
+)
+
+// QueryCacheStats reports query cache usage for diagnostics
+type QueryCacheStats struct {
+	Hits     uint64 `json:"hits"`     // Lookups answered from the cache
+	Misses   uint64 `json:"misses"`   // Lookups that had to query storage
+	Entries  int    `json:"entries"`  // Results currently cached
+	Capacity int    `json:"capacity"` // Maximum number of cached results
+}
+
+// queryCache is a fixed-size LRU cache of search results.
+// Each entry records the storage generation it was computed against so that
+// results are never served after the underlying storage has changed.
+type queryCache struct {
+	mutex    sync.Mutex
+	capacity int
+	order    *list.List
+	items    map[string]*list.Element
+	hits     uint64
+	misses   uint64
+}
+
+// queryCacheEntry is a cached search result together with its storage generation
+type queryCacheEntry struct {
+	key        string
+	generation uint64
+	result     *SearchResult
+}
+
+// newQueryCache creates an LRU cache holding up to capacity results
+func newQueryCache(capacity int) *queryCache {
+	return &queryCache{
+		capacity: capacity,
+		order:    list.New(),
+		items:    make(map[string]*list.Element),
+	}
+}
+
+// queryCacheKey builds the cache key for a search from its type, query and options
+func queryCacheKey(searchType, query string, options QueryOptions) (string, error) {
+	serializedOptions, err := json.Marshal(options)
+	if err != nil {
+		return "", err
+	}
+	return searchType + "\x00" + query + "\x00" + string(serializedOptions), nil
+}
+
+// get returns the cached result for key if it was computed at the given generation.
+// Entries from an older generation are evicted and reported as misses.
+func (c *queryCache) get(key string, generation uint64) (*SearchResult, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	element, ok := c.items[key]
+	if !ok {
+		c.misses++
+		return nil, false
+	}
+
+	entry := element.Value.(*queryCacheEntry)
+	if entry.generation != generation {
+		c.order.Remove(element)
+		delete(c.items, key)
+		c.misses++
+		return nil, false
+	}
+
+	c.order.MoveToFront(element)
+	c.hits++
+	return copySearchResult(entry.result), true
+}
+
+// put stores a result computed at the given generation, evicting the least recently used entry if full
+func (c *queryCache) put(key string, generation uint64, result *SearchResult) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if element, ok := c.items[key]; ok {
+		entry := element.Value.(*queryCacheEntry)
+		// Never replace a result with one computed against older data
+		if entry.generation > generation {
+			return
+		}
+		entry.generation = generation
+		entry.result = copySearchResult(result)
+		c.order.MoveToFront(element)
+		return
+	}
+
+	c.items[key] = c.order.PushFront(&queryCacheEntry{
+		key:        key,
+		generation: generation,
+		result:     copySearchResult(result),
+	})
+
+	for c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*queryCacheEntry).key)
+	}
+}
+
+// stats returns a snapshot of the cache counters
+func (c *queryCache) stats() QueryCacheStats {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return QueryCacheStats{
+		Hits:     c.hits,
+		Misses:   c.misses,
+		Entries:  c.order.Len(),
+		Capacity: c.capacity,
+	}
+}
+
+// copySearchResult copies a result so callers cannot modify cached entries.
+// Chunk data is shared since it is only ever read.
+func copySearchResult(result *SearchResult) *SearchResult {
+	copied := *result
+	if result.Entries != nil {
+		copied.Entries = make([]SearchResultEntry, len(result.Entries))
+		copy(copied.Entries, result.Entries)
+	}
+	if result.Facets != nil {
+		copied.Facets = maps.Clone(result.Facets)
+	}
+	if result.Options != nil {
+		options := *result.Options
+		copied.Options = &options
+	}
+	if result.CallGraph != nil {
+		callGraph := *result.CallGraph
+		callGraph.Callers = append([]CallGraphEntry(nil), result.CallGraph.Callers...)
+		callGraph.Callees = append([]CallGraphEntry(nil), result.CallGraph.Callees...)
+		callGraph.Cycles = append([][]string(nil), result.CallGraph.Cycles...)
+		copied.CallGraph = &callGraph
+	}
+	return &copied
+}
@@ -0,0 +1,278 @@
+package index
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"repository-context-protocol/internal/models"
+)
+
+func TestQueryEngine_CacheHitsRepeatedSearches(t *testing.T) {
+	tempDir, storage := setupTestStorage(t)
+	defer os.RemoveAll(tempDir)
+
+	setupTestData(t, storage)
+
+	engine := NewQueryEngineWithCache(storage, 10)
+
+	first, err := engine.SearchByNameWithOptions("TestFunction", QueryOptions{})
+	if err != nil {
+		t.Fatalf("Failed to search by name: %v", err)
+	}
+	second, err := engine.SearchByNameWithOptions("TestFunction", QueryOptions{})
+	if err != nil {
+		t.Fatalf("Failed to search by name: %v", err)
+	}
+
+	if len(second.Entries) != len(first.Entries) {
+		t.Errorf("Expected cached result to match, got %d vs %d entries", len(second.Entries), len(first.Entries))
+	}
+	if second == first {
+		t.Error("Expected cached results to be returned as copies")
+	}
+
+	// Different options must not share a cache entry
+	if _, err := engine.SearchByNameWithOptions("TestFunction", QueryOptions{IncludeCallers: true}); err != nil {
+		t.Fatalf("Failed to search by name: %v", err)
+	}
+	if _, err := engine.SearchByPatternWithOptions("Test*", QueryOptions{}); err != nil {
+		t.Fatalf("Failed to search by pattern: %v", err)
+	}
+	if _, err := engine.SearchByTypeWithOptions(EntityTypeFunction, QueryOptions{}); err != nil {
+		t.Fatalf("Failed to search by type: %v", err)
+	}
+	if _, err := engine.SearchByTypeWithOptions(EntityTypeFunction, QueryOptions{}); err != nil {
+		t.Fatalf("Failed to search by type: %v", err)
+	}
+
+	stats := engine.CacheStats()
+	if stats.Hits != 2 || stats.Misses != 4 {
+		t.Errorf("Expected 2 hits and 4 misses, got %d hits and %d misses", stats.Hits, stats.Misses)
+	}
+	if stats.Entries != 4 || stats.Capacity != 10 {
+		t.Errorf("Expected 4 of 10 entries used, got %d of %d", stats.Entries, stats.Capacity)
+	}
+
+	// Mutating a returned result must not affect the cache
+	second.Entries = nil
+	third, err := engine.SearchByNameWithOptions("TestFunction", QueryOptions{})
+	if err != nil {
+		t.Fatalf("Failed to search by name: %v", err)
+	}
+	if len(third.Entries) != len(first.Entries) {
+		t.Error("Expected cached result to be unaffected by caller modifications")
+	}
+}
+
+func TestQueryEngine_CacheInvalidatedByWrites(t *testing.T) {
+	tempDir, storage := setupTestStorage(t)
+	defer os.RemoveAll(tempDir)
+
+	setupTestData(t, storage)
+
+	engine := NewQueryEngineWithCache(storage, 10)
+
+	before, err := engine.SearchByName("NewFunction")
+	if err != nil {
+		t.Fatalf("Failed to search by name: %v", err)
+	}
+	if len(before.Entries) != 0 {
+		t.Fatalf("Expected no NewFunction before reindexing, got %d", len(before.Entries))
+	}
+
+	// Reindex main.go with an extra function
+	err = storage.StoreFileContext(&models.FileContext{
+		Path:     "main.go",
+		Language: "go",
+		Checksum: "test456",
+		ModTime:  time.Now(),
+		Functions: []models.Function{
+			{Name: "NewFunction", Signature: "func NewFunction()", StartLine: 30, EndLine: 32},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to store file context: %v", err)
+	}
+
+	after, err := engine.SearchByName("NewFunction")
+	if err != nil {
+		t.Fatalf("Failed to search by name: %v", err)
+	}
+	if len(after.Entries) != 1 {
+		t.Errorf("Expected NewFunction after reindexing, got %d entries", len(after.Entries))
+	}
+
+	if err := storage.DeleteFile("main.go"); err != nil {
+		t.Fatalf("Failed to delete file: %v", err)
+	}
+	deleted, err := engine.SearchByName("NewFunction")
+	if err != nil {
+		t.Fatalf("Failed to search by name: %v", err)
+	}
+	if len(deleted.Entries) != 0 {
+		t.Errorf("Expected no NewFunction after deleting the file, got %d entries", len(deleted.Entries))
+	}
+
+	if stats := engine.CacheStats(); stats.Hits != 0 || stats.Misses != 3 {
+		t.Errorf("Expected every search to miss after writes, got %d hits and %d misses", stats.Hits, stats.Misses)
+	}
+}
+
+func TestQueryEngine_CacheInvalidatedByOtherStorage(t *testing.T) {
+	rootPath := t.TempDir()
+	mainPath := filepath.Join(rootPath, "main.go")
+	if err := os.WriteFile(mainPath, []byte("package main\n\nfunc Before() {}\n"), 0600); err != nil {
+		t.Fatalf("Failed to write main.go: %v", err)
+	}
+	build := func() {
+		t.Helper()
+		builder := NewIndexBuilder(rootPath)
+		if err := builder.Initialize(); err != nil {
+			t.Fatalf("Failed to initialize builder: %v", err)
+		}
+		defer builder.Close()
+		if _, err := builder.BuildIndex(); err != nil {
+			t.Fatalf("Failed to build index: %v", err)
+		}
+	}
+	build()
+
+	// The engine reads through its own storage, as a server does while builds run elsewhere
+	storage := NewHybridStorage(filepath.Join(rootPath, ".repocontext"))
+	if err := storage.Initialize(); err != nil {
+		t.Fatalf("Failed to initialize storage: %v", err)
+	}
+	defer storage.Close()
+	engine := NewQueryEngineWithCache(storage, 10)
+
+	search := func() *SearchResult {
+		t.Helper()
+		result, err := engine.SearchByTypeWithOptions(EntityTypeFunction, QueryOptions{})
+		if err != nil {
+			t.Fatalf("Failed to search by type: %v", err)
+		}
+		return result
+	}
+	if first := search(); len(first.Entries) != 1 || first.Entries[0].IndexEntry.Name != "Before" {
+		t.Fatalf("Expected only Before, got %+v", first.Entries)
+	}
+	search()
+
+	if err := os.WriteFile(mainPath, []byte("package main\n\nfunc After() {}\n"), 0600); err != nil {
+		t.Fatalf("Failed to edit main.go: %v", err)
+	}
+	build()
+
+	if rebuilt := search(); len(rebuilt.Entries) != 1 || rebuilt.Entries[0].IndexEntry.Name != "After" {
+		t.Errorf("Expected only After once another storage rebuilt the index, got %+v", rebuilt.Entries)
+	}
+	if stats := engine.CacheStats(); stats.Hits != 1 || stats.Misses != 2 {
+		t.Errorf("Expected the search after the rebuild to miss, got %d hits and %d misses", stats.Hits, stats.Misses)
+	}
+}
+
+func TestQueryEngine_CacheEvictsLeastRecentlyUsed(t *testing.T) {
+	tempDir, storage := setupTestStorage(t)
+	defer os.RemoveAll(tempDir)
+
+	setupTestData(t, storage)
+
+	engine := NewQueryEngineWithCache(storage, 2)
+
+	for _, name := range []string{"TestFunction", "AnotherFunction", "TestFunction", "TestStruct", "TestFunction", "AnotherFunction"} {
+		if _, err := engine.SearchByName(name); err != nil {
+			t.Fatalf("Failed to search by name: %v", err)
+		}
+	}
+
+	// AnotherFunction is evicted by TestStruct, TestFunction stays recently used
+	stats := engine.CacheStats()
+	if stats.Hits != 2 || stats.Misses != 4 || stats.Entries != 2 {
+		t.Errorf("Expected 2 hits, 4 misses and 2 entries, got %+v", stats)
+	}
+}
+
+func TestQueryEngine_CacheConcurrentAccess(t *testing.T) {
+	tempDir, storage := setupTestStorage(t)
+	defer os.RemoveAll(tempDir)
+
+	setupTestData(t, storage)
+
+	engine := NewQueryEngineWithCache(storage, 4)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				if _, err := engine.SearchByPattern("*Function"); err != nil {
+					t.Errorf("Failed to search by pattern: %v", err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if stats := engine.CacheStats(); stats.Hits+stats.Misses != 80 {
+		t.Errorf("Expected 80 lookups, got %+v", stats)
+	}
+}
+
+func TestQueryEngine_CacheDisabled(t *testing.T) {
+	tempDir, storage := setupTestStorage(t)
+	defer os.RemoveAll(tempDir)
+
+	setupTestData(t, storage)
+
+	engine := NewQueryEngine(storage)
+	if _, err := engine.SearchByName("TestFunction"); err != nil {
+		t.Fatalf("Failed to search by name: %v", err)
+	}
+	if stats := engine.CacheStats(); stats != (QueryCacheStats{}) {
+		t.Errorf("Expected empty stats without a cache, got %+v", stats)
+	}
+}
+
+func TestQueryEngine_SearchResultIndexVersion(t *testing.T) {
+	tempDir, storage := setupTestStorage(t)
+	defer os.RemoveAll(tempDir)
+
+	setupTestData(t, storage)
+
+	engine := NewQueryEngineWithCache(storage, 10)
+
+	first, err := engine.SearchByName("TestFunction")
+	if err != nil {
+		t.Fatalf("Failed to search by name: %v", err)
+	}
+	if first.IndexGeneration != storage.Generation() {
+		t.Errorf("Expected generation %d, got %d", storage.Generation(), first.IndexGeneration)
+	}
+	if first.IndexBuildTime.IsZero() {
+		t.Error("Expected the index build time to be set")
+	}
+
+	// A cache hit reports the generation it was computed against
+	cached, err := engine.SearchByName("TestFunction")
+	if err != nil {
+		t.Fatalf("Failed to search by name: %v", err)
+	}
+	if cached.IndexGeneration != first.IndexGeneration {
+		t.Errorf("Expected cached generation %d, got %d", first.IndexGeneration, cached.IndexGeneration)
+	}
+
+	if err := storage.DeleteFile("main.go"); err != nil {
+		t.Fatalf("Failed to delete file: %v", err)
+	}
+	after, err := engine.SearchByName("TestFunction")
+	if err != nil {
+		t.Fatalf("Failed to search by name: %v", err)
+	}
+	if after.IndexGeneration <= first.IndexGeneration {
+		t.Errorf("Expected generation to advance past %d after a write, got %d", first.IndexGeneration, after.IndexGeneration)
+	}
+}
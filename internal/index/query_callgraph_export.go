@@ -0,0 +1,181 @@
+package index
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// DefaultCallGraphExportMaxNodes bounds exported call graphs when no node limit is given
+const DefaultCallGraphExportMaxNodes = 500
+
+// CallGraphExportOptions scopes the functions ExportCallGraph includes
+type CallGraphExportOptions struct {
+	Package  string // Directory whose functions are exported, relative to the repository root or as indexed; all when empty
+	Pattern  string // Glob or regex function names must match, as in SearchByPattern; all when empty
+	MaxNodes int    // Node limit, DefaultCallGraphExportMaxNodes when not positive
+}
+
+// CallGraphNode is a function of an exported call graph
+type CallGraphNode struct {
+	ID   string `json:"id"` // Function name, as used by edges
+	File string `json:"file"`
+	Line int    `json:"line"`
+}
+
+// CallGraphEdge is a call from one exported function to another
+type CallGraphEdge struct {
+	From  string `json:"from"`
+	To    string `json:"to"`
+	Calls int    `json:"calls"` // Call sites from From to To
+}
+
+// CallGraphDocument is a call graph as an adjacency list of nodes and edges, ready to
+// render with Graphviz (see DOT) or a d3 force layout
+type CallGraphDocument struct {
+	Nodes      []CallGraphNode `json:"nodes"`
+	Edges      []CallGraphEdge `json:"edges"`
+	TotalNodes int             `json:"total_nodes"`         // Functions in scope before the node limit
+	Truncated  bool            `json:"truncated,omitempty"` // Functions past MaxNodes were left out
+}
+
+// ExportCallGraph returns the call graph of the functions in scope as nodes and the
+// calls between them as edges; calls leaving the scope, such as to the standard
+// library, are left out. Functions sharing a name share a node, as they share
+// entries in the call index. Nodes are listed in source order and the graph stops at
+// MaxNodes functions, with Truncated set.
+func (qe *QueryEngine) ExportCallGraph(options CallGraphExportOptions) (*CallGraphDocument, error) {
+	if options.MaxNodes <= 0 {
+		options.MaxNodes = DefaultCallGraphExportMaxNodes
+	}
+
+	nodes, err := qe.callGraphExportNodes(options)
+	if err != nil {
+		return nil, err
+	}
+
+	document := &CallGraphDocument{Nodes: nodes, Edges: []CallGraphEdge{}, TotalNodes: len(nodes)}
+	if len(nodes) > options.MaxNodes {
+		document.Nodes, document.Truncated = nodes[:options.MaxNodes], true
+	}
+
+	inScope := make(map[string]bool, len(document.Nodes))
+	for _, node := range document.Nodes {
+		inScope[node.ID] = true
+	}
+	for _, node := range document.Nodes {
+		edges, err := qe.callGraphExportEdges(node.ID, inScope)
+		if err != nil {
+			return nil, err
+		}
+		document.Edges = append(document.Edges, edges...)
+	}
+	return document, nil
+}
+
+// callGraphExportNodes returns one node per distinct function name in scope, in source order
+func (qe *QueryEngine) callGraphExportNodes(options CallGraphExportOptions) ([]CallGraphNode, error) {
+	entries, err := qe.collectEntriesByTypes([]string{EntityTypeFunction})
+	if err != nil {
+		return nil, err
+	}
+	sortBySourceOrder(entries)
+
+	nodes := []CallGraphNode{}
+	seen := make(map[string]bool)
+	for i := range entries {
+		entry := &entries[i].IndexEntry
+		if seen[entry.Name] || !inPackage(entry.File, options.Package) {
+			continue
+		}
+		if options.Pattern != "" && !qe.MatchesPattern(entry.Name, options.Pattern) {
+			continue
+		}
+		seen[entry.Name] = true
+		nodes = append(nodes, CallGraphNode{ID: entry.Name, File: entry.File, Line: entry.StartLine})
+	}
+	return nodes, nil
+}
+
+// callGraphExportEdges returns the calls from function to functions in scope, one edge per
+// callee. Method calls such as "s.store.Save" are matched to the node of the method name.
+func (qe *QueryEngine) callGraphExportEdges(function string, inScope map[string]bool) ([]CallGraphEdge, error) {
+	relations, err := qe.storage.QueryCallsFrom(function)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query callees of %s: %w", function, err)
+	}
+
+	var edges []CallGraphEdge
+	positions := make(map[string]int)
+	for _, relation := range relations {
+		callee := relation.Callee
+		if !inScope[callee] {
+			callee = callee[strings.LastIndex(callee, ".")+1:]
+			if !inScope[callee] {
+				continue
+			}
+		}
+		if i, seen := positions[callee]; seen {
+			edges[i].Calls++
+			continue
+		}
+		positions[callee] = len(edges)
+		edges = append(edges, CallGraphEdge{From: function, To: callee, Calls: 1})
+	}
+
+	sort.Slice(edges, func(i, j int) bool { return edges[i].To < edges[j].To })
+	return edges, nil
+}
+
+// inPackage reports whether file lies directly in the package directory. The package may
+// be given relative to the repository root while files are indexed with absolute paths.
+func inPackage(file, packagePath string) bool {
+	if packagePath == "" {
+		return true
+	}
+	dir := filepath.Dir(filepath.Clean(file))
+	packagePath = filepath.Clean(packagePath)
+	return dir == packagePath || strings.HasSuffix(dir, string(filepath.Separator)+packagePath)
+}
+
+// DOT renders the call graph in the Graphviz DOT language, grouping functions in one
+// cluster per directory
+func (d *CallGraphDocument) DOT() string {
+	var dot strings.Builder
+	dot.WriteString("digraph callgraph {\n\trankdir=LR;\n\tnode [shape=box];\n")
+
+	var directories []string
+	clusters := make(map[string][]CallGraphNode)
+	for _, node := range d.Nodes {
+		dir := filepath.Dir(node.File)
+		if _, ok := clusters[dir]; !ok {
+			directories = append(directories, dir)
+		}
+		clusters[dir] = append(clusters[dir], node)
+	}
+
+	for i, dir := range directories {
+		fmt.Fprintf(&dot, "\tsubgraph cluster_%d {\n\t\tlabel=%s;\n", i, dotQuote(dir))
+		for _, node := range clusters[dir] {
+			fmt.Fprintf(&dot, "\t\t%s [tooltip=%s];\n", dotQuote(node.ID), dotQuote(fmt.Sprintf("%s:%d", node.File, node.Line)))
+		}
+		dot.WriteString("\t}\n")
+	}
+
+	for _, edge := range d.Edges {
+		fmt.Fprintf(&dot, "\t%s -> %s", dotQuote(edge.From), dotQuote(edge.To))
+		if edge.Calls > 1 {
+			fmt.Fprintf(&dot, " [label=\"%d\"]", edge.Calls)
+		}
+		dot.WriteString(";\n")
+	}
+
+	dot.WriteString("}\n")
+	return dot.String()
+}
+
+// dotQuote quotes s as a DOT identifier
+func dotQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s) + `"`
+}
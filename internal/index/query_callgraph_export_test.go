@@ -0,0 +1,133 @@
+package index
+
+import (
+	"strings"
+	"testing"
+
+	"repository-context-protocol/internal/models"
+)
+
+func newCallGraphExportTestEngine(t *testing.T) *QueryEngine {
+	return NewQueryEngine(newDiffTestStorage(t,
+		&models.FileContext{
+			Path: "api/server.go", Language: "go",
+			Functions: []models.Function{
+				{Name: "HandleRequest", StartLine: 1, Calls: []string{"validate", "s.store.saveUser", "fmt.Println", "validate"}},
+				{Name: "validate", StartLine: 10, Calls: []string{"logf"}},
+			},
+		},
+		&models.FileContext{
+			Path: "store/db.go", Language: "go",
+			Functions: []models.Function{
+				{Name: "saveUser", StartLine: 1, Calls: []string{"logf"}},
+				{Name: "logf", StartLine: 10},
+			},
+		},
+	))
+}
+
+func TestQueryEngine_ExportCallGraph(t *testing.T) {
+	engine := newCallGraphExportTestEngine(t)
+
+	document, err := engine.ExportCallGraph(CallGraphExportOptions{})
+	if err != nil {
+		t.Fatalf("Failed to export call graph: %v", err)
+	}
+
+	var nodes []string
+	for _, node := range document.Nodes {
+		nodes = append(nodes, node.ID)
+	}
+	if strings.Join(nodes, ",") != "HandleRequest,validate,saveUser,logf" {
+		t.Errorf("Expected nodes in source order, got %v", nodes)
+	}
+
+	expectedEdges := []CallGraphEdge{
+		{From: "HandleRequest", To: "saveUser", Calls: 1},
+		{From: "HandleRequest", To: "validate", Calls: 2},
+		{From: "validate", To: "logf", Calls: 1},
+		{From: "saveUser", To: "logf", Calls: 1},
+	}
+	if len(document.Edges) != len(expectedEdges) {
+		t.Fatalf("Expected edges %+v, got %+v", expectedEdges, document.Edges)
+	}
+	for i, edge := range expectedEdges {
+		if document.Edges[i] != edge {
+			t.Errorf("Expected edge %+v, got %+v", edge, document.Edges[i])
+		}
+	}
+	if document.TotalNodes != 4 || document.Truncated {
+		t.Errorf("Expected 4 nodes without truncation, got %d (truncated: %t)", document.TotalNodes, document.Truncated)
+	}
+}
+
+func TestQueryEngine_ExportCallGraphScoped(t *testing.T) {
+	engine := newCallGraphExportTestEngine(t)
+
+	t.Run("package", func(t *testing.T) {
+		document, err := engine.ExportCallGraph(CallGraphExportOptions{Package: "store"})
+		if err != nil {
+			t.Fatalf("Failed to export call graph: %v", err)
+		}
+		if len(document.Nodes) != 2 || len(document.Edges) != 1 || document.Edges[0].From != "saveUser" {
+			t.Errorf("Expected only the store functions and their call, got %+v", document)
+		}
+	})
+
+	t.Run("pattern", func(t *testing.T) {
+		document, err := engine.ExportCallGraph(CallGraphExportOptions{Pattern: "*e*"})
+		if err != nil {
+			t.Fatalf("Failed to export call graph: %v", err)
+		}
+		if len(document.Nodes) != 3 || len(document.Edges) != 2 {
+			t.Errorf("Expected HandleRequest, validate and saveUser with the calls between them, got %+v", document)
+		}
+	})
+
+	t.Run("max nodes", func(t *testing.T) {
+		document, err := engine.ExportCallGraph(CallGraphExportOptions{MaxNodes: 2})
+		if err != nil {
+			t.Fatalf("Failed to export call graph: %v", err)
+		}
+		if len(document.Nodes) != 2 || !document.Truncated || document.TotalNodes != 4 {
+			t.Errorf("Expected 2 of 4 nodes with truncation, got %+v", document)
+		}
+		if len(document.Edges) != 1 || document.Edges[0].To != "validate" {
+			t.Errorf("Expected only the edge between the kept nodes, got %+v", document.Edges)
+		}
+	})
+}
+
+func TestCallGraphDocument_DOT(t *testing.T) {
+	document := &CallGraphDocument{
+		Nodes: []CallGraphNode{
+			{ID: "HandleRequest", File: "api/server.go", Line: 1},
+			{ID: "saveUser", File: "store/db.go", Line: 1},
+			{ID: `say"hi"`, File: "store/db.go", Line: 9},
+		},
+		Edges: []CallGraphEdge{
+			{From: "HandleRequest", To: "saveUser", Calls: 1},
+			{From: "saveUser", To: `say"hi"`, Calls: 3},
+		},
+	}
+
+	expected := `digraph callgraph {
+	rankdir=LR;
+	node [shape=box];
+	subgraph cluster_0 {
+		label="api";
+		"HandleRequest" [tooltip="api/server.go:1"];
+	}
+	subgraph cluster_1 {
+		label="store";
+		"saveUser" [tooltip="store/db.go:1"];
+		"say\"hi\"" [tooltip="store/db.go:9"];
+	}
+	"HandleRequest" -> "saveUser";
+	"saveUser" -> "say\"hi\"" [label="3"];
+}
+`
+	if got := document.DOT(); got != expected {
+		t.Errorf("Unexpected DOT output:\n%s\nexpected:\n%s", got, expected)
+	}
+}
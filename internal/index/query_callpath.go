@@ -0,0 +1,136 @@
+package index
+
+import (
+	"fmt"
+	"slices"
+)
+
+const (
+	// DefaultCallPathDepth bounds call path searches when no depth is given
+	DefaultCallPathDepth = 5
+
+	// MaxCallPaths caps the number of call paths FindCallPath returns
+	MaxCallPaths = 5
+)
+
+// FindCallPath returns call chains from one function to another, each listing the
+// functions in call order from the first to the last. Paths have at most maxDepth calls
+// (DefaultCallPathDepth when not positive), never visit a function twice, and come
+// shortest first; at most MaxCallPaths are returned. No paths and no error means the
+// functions are not connected within maxDepth calls.
+func (qe *QueryEngine) FindCallPath(from, to string, maxDepth int) ([][]string, error) {
+	if from == "" || to == "" {
+		return nil, fmt.Errorf("both functions must be named")
+	}
+	if maxDepth <= 0 {
+		maxDepth = DefaultCallPathDepth
+	}
+
+	finder := &callPathFinder{qe: qe, callees: make(map[string][]string)}
+	distances, err := finder.distancesTo(to, maxDepth)
+	if err != nil {
+		return nil, err
+	}
+
+	paths := [][]string{}
+	shortest, reachable := distances[from]
+	if !reachable {
+		return paths, nil
+	}
+
+	// Enumerating each length in turn returns shorter paths first
+	for length := shortest; length <= maxDepth && len(paths) < MaxCallPaths; length++ {
+		found, err := finder.pathsOfLength([]string{from}, to, length, distances, MaxCallPaths-len(paths))
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, found...)
+	}
+	return paths, nil
+}
+
+// callPathFinder caches the callees of each function visited during a path search
+type callPathFinder struct {
+	qe      *QueryEngine
+	callees map[string][]string
+}
+
+// distancesTo walks the call index backwards from target breadth first, returning the
+// fewest calls from each function within maxDepth calls of target
+func (f *callPathFinder) distancesTo(target string, maxDepth int) (map[string]int, error) {
+	distances := map[string]int{target: 0}
+	frontier := []string{target}
+	for depth := 1; depth <= maxDepth && len(frontier) > 0; depth++ {
+		var next []string
+		for _, function := range frontier {
+			callers, err := f.qe.storage.QueryCallsTo(function)
+			if err != nil {
+				return nil, fmt.Errorf("failed to query callers of %s: %w", function, err)
+			}
+			for _, caller := range callers {
+				if _, seen := distances[caller.Caller]; !seen {
+					distances[caller.Caller] = depth
+					next = append(next, caller.Caller)
+				}
+			}
+		}
+		frontier = next
+	}
+	return distances, nil
+}
+
+// pathsOfLength extends path to target with exactly remaining more calls, only stepping to
+// functions from which target is still reachable in time, and returns up to limit paths
+func (f *callPathFinder) pathsOfLength(
+	path []string, target string, remaining int, distances map[string]int, limit int,
+) ([][]string, error) {
+	current := path[len(path)-1]
+	if current == target {
+		if remaining == 0 {
+			return [][]string{append([]string{}, path...)}, nil
+		}
+		return nil, nil
+	}
+
+	callees, err := f.calleesOf(current)
+	if err != nil {
+		return nil, err
+	}
+
+	var paths [][]string
+	for _, callee := range callees {
+		distance, reachable := distances[callee]
+		if !reachable || distance > remaining-1 || slices.Contains(path, callee) {
+			continue
+		}
+		found, err := f.pathsOfLength(append(path, callee), target, remaining-1, distances, limit-len(paths))
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, found...)
+		if len(paths) >= limit {
+			break
+		}
+	}
+	return paths, nil
+}
+
+// calleesOf returns the distinct functions called by function in call index order
+func (f *callPathFinder) calleesOf(function string) ([]string, error) {
+	if callees, ok := f.callees[function]; ok {
+		return callees, nil
+	}
+
+	relations, err := f.qe.storage.QueryCallsFrom(function)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query callees of %s: %w", function, err)
+	}
+	callees := make([]string, 0, len(relations))
+	for _, relation := range relations {
+		if !slices.Contains(callees, relation.Callee) {
+			callees = append(callees, relation.Callee)
+		}
+	}
+	f.callees[function] = callees
+	return callees, nil
+}
@@ -0,0 +1,82 @@
+package index
+
+import (
+	"slices"
+	"testing"
+
+	"repository-context-protocol/internal/models"
+)
+
+func TestQueryEngine_FindCallPath(t *testing.T) {
+	storage := newDiffTestStorage(t,
+		&models.FileContext{
+			Path: "server.go", Language: "go",
+			Functions: []models.Function{
+				{Name: "HandleRequest", StartLine: 1, Calls: []string{"validate", "saveUser", "logf"}},
+				{Name: "validate", StartLine: 10, Calls: []string{"logf"}},
+				{Name: "saveUser", StartLine: 20, Calls: []string{"withTx", "writeToDatabase"}},
+				{Name: "withTx", StartLine: 30, Calls: []string{"writeToDatabase", "HandleRequest"}},
+			},
+		},
+		&models.FileContext{
+			Path: "db.go", Language: "go",
+			Functions: []models.Function{
+				{Name: "writeToDatabase", StartLine: 1, Calls: []string{"logf"}},
+				{Name: "logf", StartLine: 10},
+			},
+		},
+	)
+	engine := NewQueryEngine(storage)
+
+	paths, err := engine.FindCallPath("HandleRequest", "writeToDatabase", 0)
+	if err != nil {
+		t.Fatalf("Failed to find call path: %v", err)
+	}
+	expected := [][]string{
+		{"HandleRequest", "saveUser", "writeToDatabase"},
+		{"HandleRequest", "saveUser", "withTx", "writeToDatabase"},
+	}
+	if !slices.EqualFunc(paths, expected, slices.Equal[[]string]) {
+		t.Errorf("Expected shortest path first %v, got %v", expected, paths)
+	}
+
+	// The depth bound excludes longer chains
+	paths, err = engine.FindCallPath("HandleRequest", "writeToDatabase", 2)
+	if err != nil {
+		t.Fatalf("Failed to find call path: %v", err)
+	}
+	if len(paths) != 1 || len(paths[0]) != 3 {
+		t.Errorf("Expected only the two-call path, got %v", paths)
+	}
+
+	// Paths follow call direction only
+	paths, err = engine.FindCallPath("writeToDatabase", "HandleRequest", 0)
+	if err != nil {
+		t.Fatalf("Failed to find call path: %v", err)
+	}
+	if len(paths) != 0 {
+		t.Errorf("Expected no path against call direction, got %v", paths)
+	}
+
+	// Every path ends at the first visit of the target even through the withTx cycle
+	paths, err = engine.FindCallPath("withTx", "logf", 0)
+	if err != nil {
+		t.Fatalf("Failed to find call path: %v", err)
+	}
+	if len(paths) == 0 || !slices.Equal(paths[0], []string{"withTx", "writeToDatabase", "logf"}) || len(paths) > MaxCallPaths {
+		t.Errorf("Expected the shortest of at most %d paths first, got %v", MaxCallPaths, paths)
+	}
+	for _, path := range paths {
+		seen := make(map[string]bool)
+		for _, function := range path {
+			if seen[function] {
+				t.Errorf("Expected paths without repeated functions, got %v", path)
+			}
+			seen[function] = true
+		}
+	}
+
+	if _, err := engine.FindCallPath("", "logf", 0); err == nil {
+		t.Error("Expected error for missing function name")
+	}
+}
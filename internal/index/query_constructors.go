@@ -0,0 +1,91 @@
+package index
+
+import (
+	"sort"
+	"strings"
+
+	"repository-context-protocol/internal/models"
+)
+
+// FindConstructors finds the functions that create values of typeName: those returning
+// the type by value or pointer, optionally followed by an error, as in func NewUser() (*User, error).
+// Functions named New<typeName> without recorded return types are included as well, and
+// come first along with the other functions following that naming convention.
+func (qe *QueryEngine) FindConstructors(typeName string) ([]SearchResultEntry, error) {
+	typeName = strings.TrimSpace(typeName)
+	if typeName == "" {
+		return []SearchResultEntry{}, nil
+	}
+
+	candidates, err := qe.collectEntriesByTypes([]string{EntityTypeFunction})
+	if err != nil {
+		return nil, err
+	}
+
+	constructors := []SearchResultEntry{}
+	for i := range candidates {
+		function := entryFunction(&candidates[i])
+		if function == nil || function.Receiver != "" {
+			continue
+		}
+		if returnsType(function.Returns, typeName) || (len(function.Returns) == 0 && function.Name == "New"+typeName) {
+			constructors = append(constructors, candidates[i])
+		}
+	}
+
+	sort.SliceStable(constructors, func(i, j int) bool {
+		iNamed := constructors[i].IndexEntry.Name == "New"+typeName
+		jNamed := constructors[j].IndexEntry.Name == "New"+typeName
+		if iNamed != jNamed {
+			return iNamed
+		}
+		if constructors[i].IndexEntry.File != constructors[j].IndexEntry.File {
+			return constructors[i].IndexEntry.File < constructors[j].IndexEntry.File
+		}
+		return constructors[i].IndexEntry.StartLine < constructors[j].IndexEntry.StartLine
+	})
+
+	return constructors, nil
+}
+
+// returnsType reports whether returns is typeName by value or pointer, optionally followed by an error
+func returnsType(returns []models.Type, typeName string) bool {
+	if len(returns) == 0 || len(returns) > 2 {
+		return false
+	}
+	if len(returns) == 2 && returns[1].Name != "error" {
+		return false
+	}
+	return baseTypeName(returns[0].Name) == typeName
+}
+
+// baseTypeName strips pointers, references and package qualifiers from a type reference,
+// so "*models.User", "&User", "struct User *" and "User" all become "User"
+func baseTypeName(typeRef string) string {
+	name := strings.TrimSpace(typeRef)
+	name = strings.TrimLeft(name, "*&")
+	name = strings.TrimSpace(strings.TrimRight(name, "*& "))
+	name = strings.TrimPrefix(name, "struct ")
+	if i := strings.LastIndexAny(name, ".:"); i >= 0 {
+		name = name[i+1:]
+	}
+	return name
+}
+
+// entryFunction returns the parsed function a function entry refers to
+func entryFunction(entry *SearchResultEntry) *models.Function {
+	if entry.IndexEntry.Type != EntityTypeFunction {
+		return nil
+	}
+	fileData := entryFileData(entry)
+	if fileData == nil {
+		return nil
+	}
+	for i := range fileData.Functions {
+		function := &fileData.Functions[i]
+		if function.Name == entry.IndexEntry.Name && function.StartLine == entry.IndexEntry.StartLine {
+			return function
+		}
+	}
+	return nil
+}
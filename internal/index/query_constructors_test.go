@@ -0,0 +1,89 @@
+package index
+
+import (
+	"testing"
+
+	"repository-context-protocol/internal/models"
+)
+
+func TestBaseTypeName(t *testing.T) {
+	tests := map[string]string{
+		"User":           "User",
+		"*User":          "User",
+		"*models.User":   "User",
+		"&User":          "User",
+		"struct User *":  "User",
+		"Foo::User":      "User",
+		"[]User":         "[]User",
+		"map[string]int": "map[string]int",
+	}
+	for typeRef, expected := range tests {
+		if got := baseTypeName(typeRef); got != expected {
+			t.Errorf("baseTypeName(%q) = %q, expected %q", typeRef, got, expected)
+		}
+	}
+}
+
+func TestQueryEngine_FindConstructors(t *testing.T) {
+	storage := newDiffTestStorage(t,
+		&models.FileContext{
+			Path: "user.go", Language: "go", Checksum: "a1",
+			Functions: []models.Function{
+				{Name: "loadUser", Signature: "func loadUser(id int) (User, error)", StartLine: 3, EndLine: 5,
+					Returns: []models.Type{{Name: "User"}, {Name: "error"}}},
+				{Name: "NewUser", Signature: "func NewUser(name string) *User", StartLine: 7, EndLine: 9,
+					Returns: []models.Type{{Name: "*User"}}},
+				{Name: "Clone", Signature: "func (u *User) Clone() *User", StartLine: 11, EndLine: 13,
+					Returns: []models.Type{{Name: "*User"}}, Receiver: "User"},
+				{Name: "lookup", Signature: "func lookup() (*User, bool)", StartLine: 15, EndLine: 17,
+					Returns: []models.Type{{Name: "*User"}, {Name: "bool"}}},
+				{Name: "listUsers", Signature: "func listUsers() []User", StartLine: 19, EndLine: 21,
+					Returns: []models.Type{{Name: "[]User"}}},
+				{Name: "NewUserStore", Signature: "func NewUserStore() *UserStore", StartLine: 23, EndLine: 25,
+					Returns: []models.Type{{Name: "*UserStore"}}},
+			},
+		},
+		&models.FileContext{
+			Path: "factory.go", Language: "go", Checksum: "b1",
+			Functions: []models.Function{
+				{Name: "DefaultUser", Signature: "func DefaultUser() models.User", StartLine: 3, EndLine: 5,
+					Returns: []models.Type{{Name: "models.User"}}},
+			},
+		},
+		&models.FileContext{
+			Path: "user.rb", Language: "ruby", Checksum: "c1",
+			Functions: []models.Function{
+				{Name: "NewUser", Signature: "def NewUser(name)", StartLine: 1, EndLine: 3},
+			},
+		},
+	)
+	engine := NewQueryEngine(storage)
+
+	constructors, err := engine.FindConstructors("User")
+	if err != nil {
+		t.Fatalf("Failed to find constructors: %v", err)
+	}
+
+	var names []string
+	for _, entry := range constructors {
+		names = append(names, entry.IndexEntry.File+":"+entry.IndexEntry.Name)
+	}
+	expected := []string{"user.go:NewUser", "user.rb:NewUser", "factory.go:DefaultUser", "user.go:loadUser"}
+	if len(names) != len(expected) {
+		t.Fatalf("Expected constructors %v, got %v", expected, names)
+	}
+	for i := range expected {
+		if names[i] != expected[i] {
+			t.Errorf("Expected constructors %v, got %v", expected, names)
+			break
+		}
+	}
+
+	none, err := engine.FindConstructors("Missing")
+	if err != nil {
+		t.Fatalf("Failed to find constructors: %v", err)
+	}
+	if len(none) != 0 {
+		t.Errorf("Expected no constructors for an unknown type, got %d", len(none))
+	}
+}
@@ -0,0 +1,150 @@
+package index
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultContentMaxMatches bounds content search results when no limit is given
+	DefaultContentMaxMatches = 100
+
+	// Matching lines longer than this many characters are shortened in snippets
+	maxSnippetLength = 200
+)
+
+// ContentSearchOptions configures a content search
+type ContentSearchOptions struct {
+	CaseInsensitive bool   `json:"case_insensitive,omitempty"`
+	MaxMatches      int    `json:"max_matches,omitempty"` // DefaultContentMaxMatches when zero
+	MaxTokens       int    `json:"max_tokens,omitempty"`  // No token limit when zero
+	FilePath        string `json:"file_path,omitempty"`   // Restrict the search to this file or the files under this directory
+}
+
+// ContentMatch is a source line matching a content search
+type ContentMatch struct {
+	File    string `json:"file"`
+	Line    int    `json:"line"`
+	Snippet string `json:"snippet"` // The matching line without surrounding whitespace, shortened when long
+}
+
+// ContentSearchResult holds the source lines matching a content search
+type ContentSearchResult struct {
+	Pattern         string         `json:"pattern"`
+	Matches         []ContentMatch `json:"matches"`
+	FilesSearched   int            `json:"files_searched"`
+	UnreadableFiles []string       `json:"unreadable_files,omitempty"` // Files with neither stored source nor a readable file on disk
+	TokenCount      int            `json:"token_count"`
+	Truncated       bool           `json:"truncated"` // More lines matched than the match or token limit allowed
+	ExecutedAt      time.Time      `json:"executed_at"`
+}
+
+// SearchContent searches the text of indexed files line by line for a regex, finding
+// string literals, comments and other text that symbol searches miss. The source
+// stored in the index is searched when the index was built with StoreSource, and the
+// file on disk otherwise.
+func (qe *QueryEngine) SearchContent(pattern string, options ContentSearchOptions) (*ContentSearchResult, error) {
+	if pattern == "" {
+		return nil, fmt.Errorf("pattern must not be empty")
+	}
+	match, err := qe.lineMatcher(pattern, options.CaseInsensitive)
+	if err != nil {
+		return nil, err
+	}
+
+	maxMatches := options.MaxMatches
+	if maxMatches <= 0 {
+		maxMatches = DefaultContentMaxMatches
+	}
+
+	files, err := qe.storage.ListFiles()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list indexed files: %w", err)
+	}
+
+	result := &ContentSearchResult{
+		Pattern:    pattern,
+		Matches:    []ContentMatch{},
+		ExecutedAt: time.Now(),
+	}
+	for _, file := range files {
+		if !matchesImportFilter(file, options.FilePath) {
+			continue
+		}
+
+		content, err := qe.fileContent(file)
+		if err != nil {
+			result.UnreadableFiles = append(result.UnreadableFiles, file)
+			continue
+		}
+		result.FilesSearched++
+
+		for i, line := range splitSourceLines(content) {
+			if !match(line) {
+				continue
+			}
+			contentMatch := ContentMatch{File: file, Line: i + 1, Snippet: snippet(line)}
+			tokens := qe.estimateMatchTokens(&contentMatch)
+			if len(result.Matches) >= maxMatches || (options.MaxTokens > 0 && result.TokenCount+tokens > options.MaxTokens) {
+				result.Truncated = true
+				return result, nil
+			}
+			result.Matches = append(result.Matches, contentMatch)
+			result.TokenCount += tokens
+		}
+	}
+
+	return result, nil
+}
+
+// lineMatcher compiles a content search pattern with the regex support of pattern
+// searches, including the cache and lookaround emulation
+func (qe *QueryEngine) lineMatcher(pattern string, caseInsensitive bool) (func(string) bool, error) {
+	cleanPattern := stripRegexDelimiters(pattern)
+	if caseInsensitive {
+		cleanPattern = withCaseInsensitiveFlag(cleanPattern)
+	}
+
+	if matcher, ok := qe.getLookaroundMatcher(cleanPattern); ok {
+		return matcher.MatchString, nil
+	}
+	regex, err := qe.getCompiledRegex(cleanPattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+	}
+	return regex.MatchString, nil
+}
+
+// fileContent returns the source stored for an indexed file, or its content on disk
+// when the index holds no source
+func (qe *QueryEngine) fileContent(file string) ([]byte, error) {
+	fileContext, err := qe.storage.GetFileContext(file)
+	if err != nil {
+		return nil, err
+	}
+	stored, err := storedSource(fileContext)
+	if err != nil || stored != nil {
+		return stored, err
+	}
+	if hybrid, ok := qe.storage.(*HybridStorage); ok {
+		file = hybrid.ResolvePath(file)
+	}
+	return os.ReadFile(file) // #nosec G304 - File path comes from our indexed data
+}
+
+// estimateMatchTokens estimates the tokens a content match adds to a response
+func (qe *QueryEngine) estimateMatchTokens(match *ContentMatch) int {
+	estimator := qe.TokenEstimator()
+	return estimator.EstimateTokens(match.File) + estimator.EstimateTokens(match.Snippet) + TokenOverhead
+}
+
+// snippet trims a matching line for display, shortening long lines
+func snippet(line string) string {
+	line = strings.TrimSpace(line)
+	if runes := []rune(line); len(runes) > maxSnippetLength {
+		return string(runes[:maxSnippetLength]) + "..."
+	}
+	return line
+}
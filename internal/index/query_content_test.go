@@ -0,0 +1,73 @@
+package index
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"repository-context-protocol/internal/models"
+)
+
+func TestQueryEngine_SearchContent(t *testing.T) {
+	diskDir := t.TempDir()
+	diskFile := filepath.Join(diskDir, "config.py")
+	if err := os.WriteFile(diskFile, []byte("TIMEOUT = 30\n# retry on timeout\n"), 0600); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	// The stored source is searched even though the file is not on disk
+	stored, err := compressSource([]byte("package db\n\nconst timeoutMessage = \"query timeout\"\n"))
+	if err != nil {
+		t.Fatalf("Failed to compress source: %v", err)
+	}
+
+	storage := newDiffTestStorage(t,
+		&models.FileContext{Path: diskFile, Language: "python"},
+		&models.FileContext{Path: "/gone/db.go", Language: "go", Source: stored},
+		&models.FileContext{Path: "/gone/missing.go", Language: "go"},
+	)
+	engine := NewQueryEngine(storage)
+
+	result, err := engine.SearchContent("timeout", ContentSearchOptions{CaseInsensitive: true})
+	if err != nil {
+		t.Fatalf("Failed to search content: %v", err)
+	}
+	expected := []ContentMatch{
+		{File: "/gone/db.go", Line: 3, Snippet: `const timeoutMessage = "query timeout"`},
+		{File: diskFile, Line: 1, Snippet: "TIMEOUT = 30"},
+		{File: diskFile, Line: 2, Snippet: "# retry on timeout"},
+	}
+	if len(result.Matches) != len(expected) {
+		t.Fatalf("Expected %d matches, got %+v", len(expected), result.Matches)
+	}
+	for i := range expected {
+		if result.Matches[i] != expected[i] {
+			t.Errorf("Match %d: expected %+v, got %+v", i, expected[i], result.Matches[i])
+		}
+	}
+	if result.FilesSearched != 2 || len(result.UnreadableFiles) != 1 || result.UnreadableFiles[0] != "/gone/missing.go" {
+		t.Errorf("Expected 2 files searched and missing.go unreadable, got %d and %v", result.FilesSearched, result.UnreadableFiles)
+	}
+
+	// The token budget stops the search before the match that would exceed it
+	budget := engine.estimateMatchTokens(&expected[0]) + 1
+	result, err = engine.SearchContent("(?i)timeout", ContentSearchOptions{MaxTokens: budget})
+	if err != nil {
+		t.Fatalf("Failed to search content: %v", err)
+	}
+	if len(result.Matches) != 1 || !result.Truncated || result.TokenCount > budget {
+		t.Errorf("Expected one match within %d tokens, got %+v", budget, result)
+	}
+
+	result, err = engine.SearchContent("timeout", ContentSearchOptions{FilePath: diskDir})
+	if err != nil {
+		t.Fatalf("Failed to search content: %v", err)
+	}
+	if len(result.Matches) != 1 || result.Matches[0].Line != 2 {
+		t.Errorf("Expected only the case-sensitive match under %s, got %+v", diskDir, result.Matches)
+	}
+
+	if _, err := engine.SearchContent("(unclosed", ContentSearchOptions{}); err == nil {
+		t.Error("Expected error for invalid pattern")
+	}
+}
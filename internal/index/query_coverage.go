@@ -0,0 +1,185 @@
+package index
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"repository-context-protocol/internal/models"
+)
+
+// DefaultTestCoverageDepth bounds how many calls away from a test a function may be and
+// still count as covered when no depth is given
+const DefaultTestCoverageDepth = 5
+
+// TestCoverageHeuristic describes how GetTestCoverage estimates coverage. Reports carry it
+// so the result is not mistaken for coverage measured by running the tests.
+const TestCoverageHeuristic = "Heuristic, not measured coverage: a function counts as covered when a test reaches it " +
+	"through the indexed call graph within max_depth calls. Calls are matched by function name only, so calls through " +
+	"interfaces, function values or reflection are missed and functions sharing a name are treated as one."
+
+// FunctionCoverage is a public function and the tests that reach it
+type FunctionCoverage struct {
+	Function string   `json:"function"`
+	File     string   `json:"file"`
+	Line     int      `json:"line"`
+	Tests    []string `json:"tests,omitempty"` // Tests reaching the function, sorted
+}
+
+// TestCoverageReport lists which public functions tests reach through the call graph
+type TestCoverageReport struct {
+	Heuristic string             `json:"heuristic"` // Always TestCoverageHeuristic
+	MaxDepth  int                `json:"max_depth"`
+	Tests     int                `json:"tests"`     // Test functions and methods found
+	Covered   []FunctionCoverage `json:"covered"`   // Public functions some test reaches
+	Uncovered []FunctionCoverage `json:"uncovered"` // Public functions no test reaches
+}
+
+// testRoot is a test function or method and the calls it makes
+type testRoot struct {
+	name  string
+	calls []string
+}
+
+// GetTestCoverage links the tests flagged during parsing to the functions they reach
+// through the call graph, following at most maxDepth calls (DefaultTestCoverageDepth when
+// not positive). Public functions are those their file exports; functions in files that
+// declare tests are left out. See TestCoverageHeuristic for the limits of the estimate.
+func (qe *QueryEngine) GetTestCoverage(maxDepth int) (*TestCoverageReport, error) {
+	if maxDepth <= 0 {
+		maxDepth = DefaultTestCoverageDepth
+	}
+
+	files, err := qe.storage.ListFiles()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list files: %w", err)
+	}
+
+	var tests []testRoot
+	var public []FunctionCoverage
+	for _, filePath := range files {
+		fileContext, err := qe.storage.GetFileContext(filePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load %s: %w", filePath, err)
+		}
+		fileTests := fileTestRoots(fileContext)
+		tests = append(tests, fileTests...)
+		if len(fileTests) == 0 {
+			public = append(public, filePublicFunctions(fileContext)...)
+		}
+	}
+
+	reachedBy, err := qe.testReach(tests, maxDepth)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &TestCoverageReport{
+		Heuristic: TestCoverageHeuristic,
+		MaxDepth:  maxDepth,
+		Tests:     len(tests),
+		Covered:   []FunctionCoverage{},
+		Uncovered: []FunctionCoverage{},
+	}
+	for _, function := range public {
+		if reached := reachedBy[function.Function]; len(reached) > 0 {
+			function.Tests = slices.Sorted(maps.Keys(reached))
+			report.Covered = append(report.Covered, function)
+		} else {
+			report.Uncovered = append(report.Uncovered, function)
+		}
+	}
+	return report, nil
+}
+
+// testReach walks the call graph breadth first from each test, returning for each
+// function name the set of tests reaching it within maxDepth calls
+func (qe *QueryEngine) testReach(tests []testRoot, maxDepth int) (map[string]map[string]bool, error) {
+	reachedBy := make(map[string]map[string]bool)
+	callees := make(map[string][]string)
+
+	for _, test := range tests {
+		visited := make(map[string]bool)
+		frontier := callNames(test.calls)
+		for depth := 1; depth <= maxDepth && len(frontier) > 0; depth++ {
+			var next []string
+			for _, function := range frontier {
+				if visited[function] {
+					continue
+				}
+				visited[function] = true
+				if reachedBy[function] == nil {
+					reachedBy[function] = make(map[string]bool)
+				}
+				reachedBy[function][test.name] = true
+
+				if _, cached := callees[function]; !cached {
+					relations, err := qe.storage.QueryCallsFrom(function)
+					if err != nil {
+						return nil, fmt.Errorf("failed to query callees of %s: %w", function, err)
+					}
+					calls := make([]string, len(relations))
+					for i := range relations {
+						calls[i] = relations[i].Callee
+					}
+					callees[function] = callNames(calls)
+				}
+				next = append(next, callees[function]...)
+			}
+			frontier = next
+		}
+	}
+	return reachedBy, nil
+}
+
+// fileTestRoots returns the test functions and methods of a file
+func fileTestRoots(fileContext *models.FileContext) []testRoot {
+	var tests []testRoot
+	for i := range fileContext.Functions {
+		if function := &fileContext.Functions[i]; function.IsTest {
+			tests = append(tests, testRoot{name: function.Name, calls: function.Calls})
+		}
+	}
+	for i := range fileContext.Types {
+		typeDef := &fileContext.Types[i]
+		for j := range typeDef.Methods {
+			if method := &typeDef.Methods[j]; method.IsTest {
+				tests = append(tests, testRoot{name: typeDef.Name + "." + method.Name, calls: method.Calls})
+			}
+		}
+	}
+	return tests
+}
+
+// filePublicFunctions returns the functions a file exports
+func filePublicFunctions(fileContext *models.FileContext) []FunctionCoverage {
+	exported := make(map[string]bool, len(fileContext.Exports))
+	for _, export := range fileContext.Exports {
+		exported[export.Name] = true
+	}
+
+	var functions []FunctionCoverage
+	for i := range fileContext.Functions {
+		function := &fileContext.Functions[i]
+		if exported[function.Name] && function.Parent == "" {
+			functions = append(functions, FunctionCoverage{Function: function.Name, File: fileContext.Path, Line: function.StartLine})
+		}
+	}
+	return functions
+}
+
+// callNames reduces call expressions such as "users.GetUser" or "self.save" to the
+// called function's name, the form functions are indexed under
+func callNames(calls []string) []string {
+	names := make([]string, 0, len(calls))
+	for _, call := range calls {
+		if i := strings.LastIndex(call, "."); i >= 0 {
+			call = call[i+1:]
+		}
+		if call != "" {
+			names = append(names, call)
+		}
+	}
+	return names
+}
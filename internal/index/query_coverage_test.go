@@ -0,0 +1,78 @@
+package index
+
+import (
+	"slices"
+	"testing"
+
+	"repository-context-protocol/internal/models"
+)
+
+func coverageNames(functions []FunctionCoverage) []string {
+	names := make([]string, len(functions))
+	for i := range functions {
+		names[i] = functions[i].Function
+	}
+	return names
+}
+
+func TestQueryEngine_GetTestCoverage(t *testing.T) {
+	storage := newDiffTestStorage(t,
+		&models.FileContext{
+			Path: "/repo/user.go", Language: "go",
+			Functions: []models.Function{
+				{Name: "GetUser", StartLine: 1, EndLine: 5, Calls: []string{"loadUser"}},
+				{Name: "DeleteUser", StartLine: 7, EndLine: 9},
+				{Name: "loadUser", StartLine: 11, EndLine: 13, Calls: []string{"db.Query"}},
+			},
+			Exports: []models.Export{{Name: "GetUser", Kind: "function"}, {Name: "DeleteUser", Kind: "function"}},
+		},
+		&models.FileContext{
+			Path: "/repo/db.go", Language: "go",
+			Functions: []models.Function{{Name: "Query", StartLine: 1, EndLine: 3}, {Name: "Exec", StartLine: 5, EndLine: 7}},
+			Exports:   []models.Export{{Name: "Query", Kind: "function"}, {Name: "Exec", Kind: "function"}},
+		},
+		&models.FileContext{
+			Path: "/repo/user_test.go", Language: "go",
+			Functions: []models.Function{
+				{Name: "TestGetUser", StartLine: 1, EndLine: 5, IsTest: true, Calls: []string{"GetUser", "t.Fatal"}},
+				{Name: "NewFixture", StartLine: 7, EndLine: 9},
+			},
+			Exports: []models.Export{{Name: "TestGetUser", Kind: "function"}, {Name: "NewFixture", Kind: "function"}},
+		},
+		&models.FileContext{
+			Path: "/repo/test_db.py", Language: "python",
+			Types: []models.TypeDef{{Name: "TestDB", Kind: "class", Methods: []models.Method{
+				{Name: "test_exec", IsTest: true, Calls: []string{"self.db.Exec"}},
+				{Name: "setUp"},
+			}}},
+		},
+	)
+	engine := NewQueryEngine(storage)
+
+	report, err := engine.GetTestCoverage(0)
+	if err != nil {
+		t.Fatalf("Failed to compute coverage: %v", err)
+	}
+	if report.MaxDepth != DefaultTestCoverageDepth || report.Tests != 2 || report.Heuristic != TestCoverageHeuristic {
+		t.Errorf("Unexpected report header %+v", report)
+	}
+	// Query is reached through GetUser and loadUser; functions in test files are not reported
+	if covered := coverageNames(report.Covered); !slices.Equal(covered, []string{"Query", "Exec", "GetUser"}) {
+		t.Errorf("Expected Query, Exec and GetUser covered, got %v", covered)
+	}
+	if uncovered := coverageNames(report.Uncovered); !slices.Equal(uncovered, []string{"DeleteUser"}) {
+		t.Errorf("Expected only DeleteUser uncovered, got %v", uncovered)
+	}
+	if tests := report.Covered[1].Tests; !slices.Equal(tests, []string{"TestDB.test_exec"}) {
+		t.Errorf("Expected Exec to be covered by the Python test method, got %v", tests)
+	}
+
+	// Query is three calls away from TestGetUser
+	report, err = engine.GetTestCoverage(2)
+	if err != nil {
+		t.Fatalf("Failed to compute coverage: %v", err)
+	}
+	if uncovered := coverageNames(report.Uncovered); !slices.Equal(uncovered, []string{"Query", "DeleteUser"}) {
+		t.Errorf("Expected Query out of reach at depth 2, got %v", uncovered)
+	}
+}
@@ -0,0 +1,185 @@
+package index
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"repository-context-protocol/internal/models"
+)
+
+// DeadCodeHeuristic describes how FindUnreferenced decides a symbol is unused. Reports
+// carry it so candidates are reviewed rather than deleted on sight.
+const DeadCodeHeuristic = "Candidates, not proven dead code: an exported function or type counts as unreferenced when no " +
+	"indexed call names it and no indexed signature, field, variable or base type mentions it. Names are matched " +
+	"without their package, and callers outside the repository, reflection, interface dispatch and Go composite " +
+	"literals are not seen."
+
+// typeIdentifierPattern matches the names a type reference is made of, so
+// map[string]*models.User mentions map, string, models and User
+var typeIdentifierPattern = regexp.MustCompile(`[A-Za-z_][A-Za-z0-9_]*`)
+
+// UnreferencedOptions configures FindUnreferenced
+type UnreferencedOptions struct {
+	// ExcludePatterns leaves symbols out of the candidates when their file is a pattern,
+	// lies under a pattern directory or matches a pattern glob, e.g. "api" or "cmd/*.go".
+	// Public API packages meant for callers outside the repository are typical patterns.
+	ExcludePatterns []string
+}
+
+// FindUnreferenced returns the exported functions and types nothing in the index
+// references, in source order: no call names them and no other declaration mentions them
+// in its types. Methods, nested functions, entry points such as main or functions matching
+// the repository's entry point patterns, and the symbols of files declaring tests are never
+// candidates, while references made by tests count. See DeadCodeHeuristic for the limits
+// of the search.
+func (qe *QueryEngine) FindUnreferenced(options UnreferencedOptions) ([]SearchResultEntry, error) {
+	files, err := qe.storage.ListFiles()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list files: %w", err)
+	}
+
+	referenced := make(map[string]bool)
+	candidates := make(map[string]bool) // Keyed by unreferencedKey
+	for _, filePath := range files {
+		fileContext, err := qe.storage.GetFileContext(filePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load %s: %w", filePath, err)
+		}
+		collectReferences(fileContext, referenced)
+		if len(fileTestRoots(fileContext)) == 0 && !matchesExcludePattern(fileContext.Path, options.ExcludePatterns) {
+			for _, name := range fileExportedSymbols(fileContext) {
+				candidates[unreferencedKey(name, fileContext.Path)] = true
+			}
+		}
+	}
+
+	entries, err := qe.collectEntriesByTypes(append([]string{EntityTypeFunction}, TypeKinds()...))
+	if err != nil {
+		return nil, err
+	}
+	unreferenced := []SearchResultEntry{}
+	for i := range entries {
+		entry := &entries[i]
+		if candidates[unreferencedKey(entry.IndexEntry.Name, entry.IndexEntry.File)] && !referenced[entry.IndexEntry.Name] {
+			unreferenced = append(unreferenced, *entry)
+		}
+	}
+	sortBySourceOrder(unreferenced)
+	return unreferenced, nil
+}
+
+// unreferencedKey identifies a candidate symbol by name and file
+func unreferencedKey(name, file string) string {
+	return file + "\x00" + name
+}
+
+// fileExportedSymbols returns the names of the top-level functions and types a file
+// exports, leaving out entry points
+func fileExportedSymbols(fileContext *models.FileContext) []string {
+	exported := make(map[string]bool, len(fileContext.Exports))
+	for _, export := range fileContext.Exports {
+		exported[export.Name] = true
+	}
+
+	var names []string
+	for i := range fileContext.Functions {
+		function := &fileContext.Functions[i]
+		if exported[function.Name] && function.Parent == "" && function.Receiver == "" && !isEntryPoint(function) {
+			names = append(names, function.Name)
+		}
+	}
+	for i := range fileContext.Types {
+		if exported[fileContext.Types[i].Name] {
+			names = append(names, fileContext.Types[i].Name)
+		}
+	}
+	return names
+}
+
+// collectReferences adds to referenced the names a file calls or mentions in types.
+// Recursive calls and a type's mentions of itself, in its fields or methods, do not count.
+func collectReferences(fileContext *models.FileContext, referenced map[string]bool) {
+	reference := func(owner string, names ...string) {
+		for _, name := range names {
+			if name != owner {
+				referenced[name] = true
+			}
+		}
+	}
+	referenceTypes := func(owner string, typeRefs ...string) {
+		for _, typeRef := range typeRefs {
+			reference(owner, typeIdentifierPattern.FindAllString(typeRef, -1)...)
+		}
+	}
+
+	for i := range fileContext.Functions {
+		function := &fileContext.Functions[i]
+		reference(function.Name, callNames(function.Calls)...)
+		reference(function.Name, callNames(function.LocalCalls)...)
+		for _, call := range function.CrossFileCalls {
+			reference(function.Name, callNames([]string{call.FunctionName})...)
+		}
+		// Methods mentioning their own receiver type do not keep it alive
+		owner := function.Receiver
+		referenceTypes(owner, parameterTypes(function.Parameters)...)
+		referenceTypes(owner, returnTypes(function.Returns)...)
+	}
+	for i := range fileContext.Types {
+		typeDef := &fileContext.Types[i]
+		referenceTypes(typeDef.Name, typeDef.Embedded...)
+		for _, field := range typeDef.Fields {
+			referenceTypes(typeDef.Name, field.Type)
+		}
+		for j := range typeDef.Methods {
+			method := &typeDef.Methods[j]
+			reference(typeDef.Name, callNames(method.Calls)...)
+			referenceTypes(typeDef.Name, parameterTypes(method.Parameters)...)
+			referenceTypes(typeDef.Name, returnTypes(method.Returns)...)
+		}
+	}
+	for _, variable := range fileContext.Variables {
+		referenceTypes("", variable.Type)
+	}
+	for _, constant := range fileContext.Constants {
+		referenceTypes("", constant.Type)
+	}
+}
+
+// parameterTypes returns the types of parameters as written
+func parameterTypes(parameters []models.Parameter) []string {
+	types := make([]string, len(parameters))
+	for i, parameter := range parameters {
+		types[i] = parameter.Type
+	}
+	return types
+}
+
+// returnTypes returns the names of return types as written
+func returnTypes(returns []models.Type) []string {
+	types := make([]string, len(returns))
+	for i, result := range returns {
+		types[i] = result.Name
+	}
+	return types
+}
+
+// matchesExcludePattern reports whether a file is one of patterns, lies under a pattern
+// directory or matches a pattern glob
+func matchesExcludePattern(file string, patterns []string) bool {
+	file = filepath.ToSlash(file)
+	for _, pattern := range patterns {
+		pattern = strings.TrimSuffix(filepath.ToSlash(strings.TrimSpace(pattern)), "/")
+		if pattern == "" {
+			continue
+		}
+		if file == pattern || strings.HasPrefix(file, pattern+"/") {
+			return true
+		}
+		if matched, _ := filepath.Match(pattern, file); matched {
+			return true
+		}
+	}
+	return false
+}
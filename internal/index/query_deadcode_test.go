@@ -0,0 +1,108 @@
+package index
+
+import (
+	"strings"
+	"testing"
+
+	"repository-context-protocol/internal/models"
+)
+
+func newDeadCodeTestEngine(t *testing.T) *QueryEngine {
+	return NewQueryEngine(newDiffTestStorage(t,
+		&models.FileContext{
+			Path: "cmd/app/main.go", Language: "go",
+			Functions: []models.Function{{Name: "main", StartLine: 3, Calls: []string{"store.Open"}}},
+		},
+		&models.FileContext{
+			Path: "store/store.go", Language: "go",
+			Types: []models.TypeDef{
+				{Name: "Store", Kind: "struct", StartLine: 3},
+				{Name: "Record", Kind: "struct", StartLine: 8},
+				{Name: "Node", Kind: "struct", StartLine: 12, Fields: []models.Field{{Name: "Next", Type: "*Node"}}},
+			},
+			Functions: []models.Function{
+				{Name: "Open", StartLine: 20, Returns: []models.Type{{Name: "*Store"}}},
+				{Name: "Get", Receiver: "Store", StartLine: 25, Returns: []models.Type{{Name: "[]*Record"}}},
+				{Name: "Compact", StartLine: 30, Calls: []string{"Compact"}},
+				{Name: "Legacy", StartLine: 40},
+			},
+			Exports: []models.Export{
+				{Name: "Store", Kind: "type"}, {Name: "Record", Kind: "type"}, {Name: "Node", Kind: "type"},
+				{Name: "Open", Kind: "function"}, {Name: "Get", Kind: "function"},
+				{Name: "Compact", Kind: "function"}, {Name: "Legacy", Kind: "function"},
+			},
+		},
+		&models.FileContext{
+			Path: "store/store_test.go", Language: "go",
+			Functions: []models.Function{
+				{Name: "TestLegacy", StartLine: 5, IsTest: true, Calls: []string{"Legacy"}},
+				{Name: "NewFixture", StartLine: 10},
+			},
+			Exports: []models.Export{{Name: "TestLegacy", Kind: "function"}, {Name: "NewFixture", Kind: "function"}},
+		},
+		&models.FileContext{
+			Path: "api/client.go", Language: "go",
+			Functions: []models.Function{{Name: "Dial", StartLine: 3}},
+			Exports:   []models.Export{{Name: "Dial", Kind: "function"}},
+		},
+	))
+}
+
+func TestQueryEngine_FindUnreferenced(t *testing.T) {
+	engine := newDeadCodeTestEngine(t)
+
+	entries, err := engine.FindUnreferenced(UnreferencedOptions{})
+	if err != nil {
+		t.Fatalf("Failed to find unreferenced symbols: %v", err)
+	}
+
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.IndexEntry.Name)
+	}
+	// Open is called, Store and Record appear in signatures, Get is a method, Legacy is
+	// called by a test, and main and the test file are never candidates. Node only
+	// mentions itself and Compact only calls itself.
+	if got := strings.Join(names, ","); got != "Dial,Node,Compact" {
+		t.Errorf("Expected Dial, Node and Compact in source order, got %s", got)
+	}
+}
+
+func TestQueryEngine_FindUnreferencedExcludePatterns(t *testing.T) {
+	engine := newDeadCodeTestEngine(t)
+
+	for _, patterns := range [][]string{{"api"}, {"api/"}, {"api/*.go"}, {"api/client.go"}} {
+		entries, err := engine.FindUnreferenced(UnreferencedOptions{ExcludePatterns: patterns})
+		if err != nil {
+			t.Fatalf("Failed to find unreferenced symbols: %v", err)
+		}
+		for _, entry := range entries {
+			if entry.IndexEntry.File == "api/client.go" {
+				t.Errorf("Expected %v to exclude api/client.go, got %s", patterns, entry.IndexEntry.Name)
+			}
+		}
+		if len(entries) != 2 {
+			t.Errorf("Expected 2 candidates outside api with %v, got %d", patterns, len(entries))
+		}
+	}
+}
+
+func TestQueryEngine_FindUnreferencedSkipsEntryPoints(t *testing.T) {
+	engine := NewQueryEngine(newDiffTestStorage(t, &models.FileContext{
+		Path: "app/cli.py", Language: "python",
+		Functions: []models.Function{
+			{Name: "main", StartLine: 3},
+			{Name: "index", StartLine: 8, IsEntryPoint: true, Decorators: []string{"@app.route('/')"}},
+			{Name: "unused", StartLine: 12},
+		},
+		Exports: []models.Export{{Name: "main", Kind: "function"}, {Name: "index", Kind: "function"}, {Name: "unused", Kind: "function"}},
+	}))
+
+	entries, err := engine.FindUnreferenced(UnreferencedOptions{})
+	if err != nil {
+		t.Fatalf("Failed to find unreferenced symbols: %v", err)
+	}
+	if len(entries) != 1 || entries[0].IndexEntry.Name != "unused" {
+		t.Errorf("Expected only unused as a candidate, got %+v", entries)
+	}
+}
@@ -0,0 +1,93 @@
+package index
+
+import (
+	"slices"
+	"strings"
+	"time"
+)
+
+// SearchByDecorator finds functions and types carrying a decorator or annotation.
+// The leading "@" is optional and the query matches decorators that start with it
+// at a name boundary, so "@app.route" matches "@app.route('/x')" but not "@app.router".
+func (qe *QueryEngine) SearchByDecorator(decorator string, options QueryOptions) (*SearchResult, error) {
+	return qe.cachedSearch("decorator", decorator, options, func() (*SearchResult, error) {
+		return qe.searchByDecorator(decorator, options)
+	})
+}
+
+// searchByDecorator performs an uncached decorator search
+func (qe *QueryEngine) searchByDecorator(decorator string, options QueryOptions) (*SearchResult, error) {
+	result := &SearchResult{
+		Query:      decorator,
+		SearchType: "decorator",
+		ExecutedAt: time.Now(),
+		Options:    &options,
+	}
+
+	candidates, err := qe.collectEntriesByTypes(append([]string{EntityTypeFunction}, TypeKinds()...))
+	if err != nil {
+		return nil, err
+	}
+
+	result.Entries = []SearchResultEntry{}
+	for _, candidate := range candidates {
+		if slices.ContainsFunc(entryDecorators(&candidate), func(candidateDecorator string) bool {
+			return MatchesDecorator(candidateDecorator, decorator)
+		}) {
+			result.Entries = append(result.Entries, candidate)
+		}
+	}
+	result.Entries = filterEntriesByLanguage(result.Entries, options.Language)
+
+	// Add call graph information if requested and functions are found
+	qe.attachFirstFunctionCallGraph(result, options)
+
+	// Apply token limits and estimate tokens
+	qe.applyTokenLimits(result, options.MaxTokens)
+
+	return result, nil
+}
+
+// MatchesDecorator reports whether decorator, as written in source, matches query.
+// Both may omit the leading "@". The query must be a prefix of the decorator ending
+// where the decorator ends, at its argument list or at a dotted name segment.
+// Queries that include arguments, such as "@app.route('/users", match any continuation.
+func MatchesDecorator(decorator, query string) bool {
+	decorator = strings.TrimPrefix(strings.TrimSpace(decorator), "@")
+	query = strings.TrimPrefix(strings.TrimSpace(query), "@")
+	if query == "" || !strings.HasPrefix(decorator, query) {
+		return false
+	}
+
+	rest := decorator[len(query):]
+	return rest == "" || rest[0] == '(' || rest[0] == '.' || strings.Contains(query, "(")
+}
+
+// entryDecorators returns the decorators of the function or type an entry refers to
+func entryDecorators(entry *SearchResultEntry) []string {
+	if entry.ChunkData == nil {
+		return nil
+	}
+	for i := range entry.ChunkData.FileData {
+		fileData := &entry.ChunkData.FileData[i]
+		if fileData.Path != entry.IndexEntry.File {
+			continue
+		}
+		if entry.IndexEntry.Type == EntityTypeFunction {
+			for j := range fileData.Functions {
+				function := &fileData.Functions[j]
+				if function.Name == entry.IndexEntry.Name && function.StartLine == entry.IndexEntry.StartLine {
+					return function.Decorators
+				}
+			}
+			continue
+		}
+		for j := range fileData.Types {
+			typeDef := &fileData.Types[j]
+			if typeDef.Name == entry.IndexEntry.Name && typeDef.StartLine == entry.IndexEntry.StartLine {
+				return typeDef.Decorators
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package index
+
+import (
+	"testing"
+
+	"repository-context-protocol/internal/models"
+)
+
+func TestMatchesDecorator(t *testing.T) {
+	tests := []struct {
+		decorator string
+		query     string
+		expected  bool
+	}{
+		{"@app.route('/x')", "@app.route", true},
+		{"@app.route('/x')", "app.route", true},
+		{"@app.route", "@app.route", true},
+		{"@app.route('/x')", "@app", true},
+		{"@app.route('/x')", "@app.route('/x", true},
+		{"@app.router", "@app.route", false},
+		{"@Entity", "@Ent", false},
+		{"@Entity", "@Entity", true},
+		{"@Entity", "@", false},
+	}
+
+	for _, tt := range tests {
+		if got := MatchesDecorator(tt.decorator, tt.query); got != tt.expected {
+			t.Errorf("MatchesDecorator(%q, %q) = %v, expected %v", tt.decorator, tt.query, got, tt.expected)
+		}
+	}
+}
+
+func TestQueryEngine_SearchByDecorator(t *testing.T) {
+	storage := newDiffTestStorage(t,
+		&models.FileContext{
+			Path: "views.py", Language: "python", Checksum: "a1",
+			Functions: []models.Function{
+				{Name: "list_users", Signature: "def list_users()", StartLine: 4, EndLine: 5, Decorators: []string{"@app.route('/users')"}},
+				{Name: "get_user", Signature: "def get_user(id)", StartLine: 8, EndLine: 9, Decorators: []string{"@login_required", "@app.route('/users/<id>')"}},
+				{Name: "helper", Signature: "def helper()", StartLine: 11, EndLine: 12},
+			},
+		},
+		&models.FileContext{
+			Path: "User.java", Language: "java", Checksum: "b1",
+			Types: []models.TypeDef{
+				{Name: "User", Kind: "class", StartLine: 3, EndLine: 20, Decorators: []string{"@Entity", "@Table(name = \"users\")"}},
+				{Name: "UserDto", Kind: "class", StartLine: 22, EndLine: 30},
+			},
+		},
+	)
+	engine := NewQueryEngine(storage)
+
+	routes, err := engine.SearchByDecorator("@app.route", QueryOptions{})
+	if err != nil {
+		t.Fatalf("Failed to search by decorator: %v", err)
+	}
+	if routes.SearchType != "decorator" {
+		t.Errorf("Expected search type 'decorator', got %q", routes.SearchType)
+	}
+	names := make(map[string]bool)
+	for _, entry := range routes.Entries {
+		names[entry.IndexEntry.Name] = true
+	}
+	if len(routes.Entries) != 2 || !names["list_users"] || !names["get_user"] {
+		t.Errorf("Expected both route handlers, got %v", names)
+	}
+
+	entities, err := engine.SearchByDecorator("Entity", QueryOptions{})
+	if err != nil {
+		t.Fatalf("Failed to search by annotation: %v", err)
+	}
+	if len(entities.Entries) != 1 || entities.Entries[0].IndexEntry.Name != "User" || entities.Entries[0].IndexEntry.Type != "class" {
+		t.Errorf("Expected only the User class, got %+v", entities.Entries)
+	}
+
+	none, err := engine.SearchByDecorator("@deprecated", QueryOptions{})
+	if err != nil || len(none.Entries) != 0 {
+		t.Errorf("Expected no matches, got %d (err: %v)", len(none.Entries), err)
+	}
+}
@@ -0,0 +1,51 @@
+package index
+
+import (
+	"fmt"
+	"time"
+)
+
+// FileInfo summarizes one indexed file
+type FileInfo struct {
+	Path      string    `json:"path"`
+	Language  string    `json:"language"`
+	Checksum  string    `json:"checksum"`
+	ModTime   time.Time `json:"mod_time"`  // Modification time of the file when it was parsed
+	Functions int       `json:"functions"` // Number of top-level functions
+	Types     int       `json:"types"`
+	Variables int       `json:"variables"`
+	Constants int       `json:"constants"`
+	Entities  int       `json:"entities"` // Total of the counts above, zero for files that yielded nothing
+}
+
+// ListIndexedFiles returns every indexed file with its stored metadata and entity counts,
+// sorted by path
+func (qe *QueryEngine) ListIndexedFiles() ([]FileInfo, error) {
+	files, err := qe.storage.ListFiles()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list indexed files: %w", err)
+	}
+
+	infos := make([]FileInfo, 0, len(files))
+	for _, file := range files {
+		fileContext, err := qe.storage.GetFileContext(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load %s: %w", file, err)
+		}
+
+		info := FileInfo{
+			Path:      fileContext.Path,
+			Language:  fileContext.Language,
+			Checksum:  fileContext.Checksum,
+			ModTime:   fileContext.ModTime,
+			Functions: len(fileContext.Functions),
+			Types:     len(fileContext.Types),
+			Variables: len(fileContext.Variables),
+			Constants: len(fileContext.Constants),
+		}
+		info.Entities = info.Functions + info.Types + info.Variables + info.Constants
+		infos = append(infos, info)
+	}
+
+	return infos, nil
+}
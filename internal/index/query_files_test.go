@@ -0,0 +1,44 @@
+package index
+
+import (
+	"testing"
+	"time"
+
+	"repository-context-protocol/internal/models"
+)
+
+func TestQueryEngine_ListIndexedFiles(t *testing.T) {
+	modTime := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	storage := newDiffTestStorage(t,
+		&models.FileContext{
+			Path: "service.go", Language: "go", Checksum: "a1", ModTime: modTime,
+			Functions: []models.Function{{Name: "Run"}, {Name: "Stop"}},
+			Types:     []models.TypeDef{{Name: "Service", Kind: "struct"}},
+			Constants: []models.Constant{{Name: "Version"}},
+		},
+		&models.FileContext{Path: "empty.py", Language: "python", Checksum: "b1", ModTime: modTime},
+	)
+
+	files, err := NewQueryEngine(storage).ListIndexedFiles()
+	if err != nil {
+		t.Fatalf("Failed to list indexed files: %v", err)
+	}
+
+	if len(files) != 2 {
+		t.Fatalf("Expected 2 files, got %d", len(files))
+	}
+	if files[0].Path != "empty.py" || files[1].Path != "service.go" {
+		t.Errorf("Expected files sorted by path, got %s, %s", files[0].Path, files[1].Path)
+	}
+	if files[0].Entities != 0 {
+		t.Errorf("Expected no entities in empty.py, got %d", files[0].Entities)
+	}
+
+	service := files[1]
+	if service.Language != "go" || service.Checksum != "a1" || !service.ModTime.Equal(modTime) {
+		t.Errorf("Expected stored metadata for service.go, got %+v", service)
+	}
+	if service.Functions != 2 || service.Types != 1 || service.Constants != 1 || service.Entities != 4 {
+		t.Errorf("Expected 2 functions, 1 type, 1 constant and 4 entities, got %+v", service)
+	}
+}
@@ -0,0 +1,37 @@
+package index
+
+import "fmt"
+
+// HotspotEntry is a function ranked by how many functions call it or how many it calls
+type HotspotEntry struct {
+	Function    string `json:"function"`
+	File        string `json:"file"`
+	Line        int    `json:"line"`
+	CallerCount int    `json:"caller_count"` // Distinct functions calling this function
+	CalleeCount int    `json:"callee_count"` // Distinct functions this function calls
+}
+
+// GetMostCalledFunctions returns up to limit functions with the most distinct callers,
+// most called first. Functions nobody calls are left out.
+func (qe *QueryEngine) GetMostCalledFunctions(limit int) ([]HotspotEntry, error) {
+	return qe.getHotspots(false, limit)
+}
+
+// GetMostCallingFunctions returns up to limit functions calling the most distinct functions,
+// highest fan-out first. Functions that call nothing are left out.
+func (qe *QueryEngine) GetMostCallingFunctions(limit int) ([]HotspotEntry, error) {
+	return qe.getHotspots(true, limit)
+}
+
+// getHotspots validates limit and ranks functions from the stored call index
+func (qe *QueryEngine) getHotspots(byCallees bool, limit int) ([]HotspotEntry, error) {
+	if limit <= 0 {
+		return nil, fmt.Errorf("limit must be positive, got %d", limit)
+	}
+
+	hotspots, err := qe.storage.QueryHotspots(byCallees, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to rank functions: %w", err)
+	}
+	return hotspots, nil
+}
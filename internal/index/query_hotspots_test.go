@@ -0,0 +1,60 @@
+package index
+
+import (
+	"testing"
+
+	"repository-context-protocol/internal/models"
+)
+
+func TestQueryEngine_GetMostCalledFunctions(t *testing.T) {
+	storage := newDiffTestStorage(t,
+		&models.FileContext{
+			Path: "server.go", Language: "go",
+			Functions: []models.Function{
+				{Name: "Serve", StartLine: 1, Calls: []string{"handle", "logf", "logf"}},
+				{Name: "handle", StartLine: 10, Calls: []string{"logf", "validate"}},
+				{Name: "validate", StartLine: 20},
+			},
+		},
+		&models.FileContext{
+			Path: "log.go", Language: "go",
+			Functions: []models.Function{
+				{Name: "logf", StartLine: 1, Calls: []string{"fmt.Printf"}},
+			},
+		},
+	)
+	engine := NewQueryEngine(storage)
+
+	hotspots, err := engine.GetMostCalledFunctions(10)
+	if err != nil {
+		t.Fatalf("Failed to get most called functions: %v", err)
+	}
+	// Repeated calls from one caller count once, and uncalled functions are left out
+	want := []struct {
+		function string
+		callers  int
+	}{{"logf", 2}, {"handle", 1}, {"validate", 1}}
+	if len(hotspots) != len(want) {
+		t.Fatalf("Expected %d hotspots, got %+v", len(want), hotspots)
+	}
+	for i, w := range want {
+		if hotspots[i].Function != w.function || hotspots[i].CallerCount != w.callers {
+			t.Errorf("Hotspot %d: expected %s with %d callers, got %+v", i, w.function, w.callers, hotspots[i])
+		}
+	}
+	if hotspots[0].File != "log.go" || hotspots[0].Line != 1 {
+		t.Errorf("Expected logf location log.go:1, got %s:%d", hotspots[0].File, hotspots[0].Line)
+	}
+
+	hotspots, err = engine.GetMostCallingFunctions(1)
+	if err != nil {
+		t.Fatalf("Failed to get most calling functions: %v", err)
+	}
+	if len(hotspots) != 1 || hotspots[0].Function != "handle" || hotspots[0].CalleeCount != 2 {
+		t.Errorf("Expected handle first by fan-out, got %+v", hotspots)
+	}
+
+	if _, err := engine.GetMostCalledFunctions(0); err == nil {
+		t.Error("Expected error for non-positive limit")
+	}
+}
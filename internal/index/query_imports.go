@@ -0,0 +1,238 @@
+package index
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Import category constants
+const (
+	ImportCategoryStdlib     = "stdlib"      // Standard library of the file's language
+	ImportCategoryThirdParty = "third_party" // Dependency outside the repository
+	ImportCategoryInternal   = "internal"    // Package or module indexed from this repository
+	ImportCategoryRelative   = "relative"    // Import relative to the importing file
+	ImportCategoryExternal   = "external"    // Outside the repository, stdlib and third-party not distinguishable
+
+	// Minimum path segments an import must share with an indexed directory to count as internal
+	minInternalGoSegments   = 2
+	minInternalJavaSegments = 2
+)
+
+// ImportQueryOptions configures import analysis
+type ImportQueryOptions struct {
+	FilePath string `json:"file_path,omitempty"` // Restrict analysis to this file or the files under this directory
+}
+
+// ImportUsage describes one imported path and the files that import it
+type ImportUsage struct {
+	Path     string   `json:"path"`
+	Category string   `json:"category"` // One of the ImportCategory* constants
+	Count    int      `json:"count"`    // Number of files importing the path
+	Files    []string `json:"files"`
+}
+
+// FileImports lists the imports of a single file in source order
+type FileImports struct {
+	File     string   `json:"file"`
+	Language string   `json:"language"`
+	Imports  []string `json:"imports"`
+}
+
+// ImportAnalysis is the result of an import search
+type ImportAnalysis struct {
+	Imports    []ImportUsage  `json:"imports"`    // Sorted by count, most used first
+	Files      []FileImports  `json:"files"`      // Files with at least one import, sorted by path
+	Categories map[string]int `json:"categories"` // Number of distinct imports per category
+}
+
+// SearchImports aggregates the imports of indexed files, counting how many files use
+// each import path and grouping paths into standard library, third-party, internal
+// and relative imports where the language makes that distinction possible
+func (qe *QueryEngine) SearchImports(options ImportQueryOptions) (*ImportAnalysis, error) {
+	files, err := qe.storage.ListFiles()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list indexed files: %w", err)
+	}
+
+	indexed := newIndexedPaths(files)
+	result := &ImportAnalysis{
+		Imports:    []ImportUsage{},
+		Files:      []FileImports{},
+		Categories: make(map[string]int),
+	}
+	usages := make(map[string]*ImportUsage)
+
+	for _, file := range files {
+		if !matchesImportFilter(file, options.FilePath) {
+			continue
+		}
+
+		fileContext, err := qe.storage.GetFileContext(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load %s: %w", file, err)
+		}
+		if len(fileContext.Imports) == 0 {
+			continue
+		}
+
+		fileImports := FileImports{File: file, Language: fileContext.Language, Imports: []string{}}
+		for _, imp := range fileContext.Imports {
+			fileImports.Imports = append(fileImports.Imports, imp.Path)
+
+			key := fileContext.Language + "\x00" + imp.Path
+			usage, ok := usages[key]
+			if !ok {
+				usage = &ImportUsage{
+					Path:     imp.Path,
+					Category: indexed.categorize(fileContext.Language, imp.Path),
+					Files:    []string{},
+				}
+				usages[key] = usage
+			}
+			if !slices.Contains(usage.Files, file) {
+				usage.Files = append(usage.Files, file)
+				usage.Count++
+			}
+		}
+		result.Files = append(result.Files, fileImports)
+	}
+
+	for _, usage := range usages {
+		result.Imports = append(result.Imports, *usage)
+		result.Categories[usage.Category]++
+	}
+	slices.SortFunc(result.Imports, func(a, b ImportUsage) int {
+		if a.Count != b.Count {
+			return b.Count - a.Count
+		}
+		return strings.Compare(a.Path, b.Path)
+	})
+
+	return result, nil
+}
+
+// matchesImportFilter reports whether file is the filter path or lies under it
+func matchesImportFilter(file, filter string) bool {
+	if filter == "" {
+		return true
+	}
+	file = filepath.Clean(file)
+	filter = filepath.Clean(filter)
+	return file == filter || strings.HasPrefix(file, filter+string(filepath.Separator))
+}
+
+// indexedPaths holds every trailing run of segments of the indexed directories and
+// module paths, so "a/b/c" is recorded as "a/b/c", "b/c" and "c"
+type indexedPaths map[string]bool
+
+// newIndexedPaths builds the lookup set from indexed file paths
+func newIndexedPaths(files []string) indexedPaths {
+	paths := make(indexedPaths)
+	for _, file := range files {
+		file = filepath.ToSlash(filepath.Clean(file))
+		paths.addSuffixes(filepath.ToSlash(filepath.Dir(file)))
+		paths.addSuffixes(strings.TrimSuffix(file, filepath.Ext(file)))
+	}
+	return paths
+}
+
+// addSuffixes records all trailing segment runs of a slash separated path
+func (p indexedPaths) addSuffixes(path string) {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for i := range segments {
+		if segments[i] == "." || segments[i] == ".." || segments[i] == "" {
+			continue
+		}
+		p[strings.Join(segments[i:], "/")] = true
+	}
+}
+
+// categorize assigns an import path to one of the ImportCategory* constants
+func (p indexedPaths) categorize(language, path string) string {
+	switch language {
+	case "go":
+		return p.categorizeGo(path)
+	case "java":
+		return p.categorizeJava(path)
+	case "python":
+		return p.categorizePython(path)
+	case "c", "cpp":
+		return p.categorizeC(path)
+	default:
+		if strings.HasPrefix(path, ".") {
+			return ImportCategoryRelative
+		}
+		return ImportCategoryExternal
+	}
+}
+
+// categorizeGo treats paths ending in an indexed directory as internal and paths
+// whose first element has no dot, such as "fmt" or "net/http", as standard library
+func (p indexedPaths) categorizeGo(path string) string {
+	if strings.HasPrefix(path, ".") {
+		return ImportCategoryRelative
+	}
+
+	segments := strings.Split(path, "/")
+	for i := 0; len(segments)-i >= minInternalGoSegments; i++ {
+		if p[strings.Join(segments[i:], "/")] {
+			return ImportCategoryInternal
+		}
+	}
+
+	if !strings.Contains(segments[0], ".") {
+		return ImportCategoryStdlib
+	}
+	return ImportCategoryThirdParty
+}
+
+// categorizeJava treats imports whose package is an indexed directory as internal
+// and the java, javax and jdk namespaces as standard library
+func (p indexedPaths) categorizeJava(path string) string {
+	segments := strings.Split(strings.TrimSuffix(path, ".*"), ".")
+	// Drop the class name, and the member name for static imports
+	for n := len(segments); n >= minInternalJavaSegments; n-- {
+		if p[strings.Join(segments[:n], "/")] {
+			return ImportCategoryInternal
+		}
+	}
+
+	switch segments[0] {
+	case "java", "javax", "jdk":
+		return ImportCategoryStdlib
+	}
+	return ImportCategoryThirdParty
+}
+
+// categorizePython treats dotted imports as relative and modules matching an indexed
+// package or file as internal. Anything else is external because telling the
+// standard library from installed packages requires the interpreter.
+func (p indexedPaths) categorizePython(path string) string {
+	if strings.HasPrefix(path, ".") {
+		return ImportCategoryRelative
+	}
+
+	segments := strings.Split(path, ".")
+	for n := len(segments); n >= 1; n-- {
+		if p[strings.Join(segments[:n], "/")] {
+			return ImportCategoryInternal
+		}
+	}
+	return ImportCategoryExternal
+}
+
+// categorizeC treats includes naming an indexed file, such as "queue.h" or
+// "net/socket.h", as internal. System and library headers cannot be told apart
+// without the compiler's include paths, so everything else is external.
+func (p indexedPaths) categorizeC(path string) string {
+	header := strings.TrimSuffix(filepath.ToSlash(filepath.Clean(path)), filepath.Ext(path))
+	if p[strings.TrimLeft(header, "./")] {
+		return ImportCategoryInternal
+	}
+	if strings.HasPrefix(path, ".") {
+		return ImportCategoryRelative
+	}
+	return ImportCategoryExternal
+}
@@ -0,0 +1,101 @@
+package index
+
+import (
+	"testing"
+
+	"repository-context-protocol/internal/models"
+)
+
+func TestQueryEngine_SearchImports(t *testing.T) {
+	storage := newDiffTestStorage(t,
+		&models.FileContext{
+			Path: "internal/api/handler.go", Language: "go", Checksum: "a1",
+			Imports: []models.Import{
+				{Path: "fmt"},
+				{Path: "net/http"},
+				{Path: "github.com/spf13/cobra"},
+				{Path: "example.com/app/internal/store"},
+			},
+		},
+		&models.FileContext{
+			Path: "internal/store/store.go", Language: "go", Checksum: "b1",
+			Imports: []models.Import{{Path: "fmt"}, {Path: "database/sql"}},
+		},
+		&models.FileContext{
+			Path: "app/views.py", Language: "python", Checksum: "c1",
+			Imports: []models.Import{{Path: "os"}, {Path: ".models.User"}, {Path: "app.models"}},
+		},
+		&models.FileContext{
+			Path: "app/models.py", Language: "python", Checksum: "d1",
+		},
+		&models.FileContext{
+			Path: "src/com/example/Main.java", Language: "java", Checksum: "e1",
+			Imports: []models.Import{
+				{Path: "java.util.List"},
+				{Path: "org.junit.Test"},
+				{Path: "com.example.util.Strings"},
+			},
+		},
+		&models.FileContext{
+			Path: "src/com/example/util/Strings.java", Language: "java", Checksum: "f1",
+		},
+		&models.FileContext{
+			Path: "lib/queue.c", Language: "c", Checksum: "g1",
+			Imports: []models.Import{{Path: "queue.h"}, {Path: "stdio.h"}},
+		},
+		&models.FileContext{
+			Path: "lib/queue.h", Language: "c", Checksum: "h1",
+		},
+	)
+	engine := NewQueryEngine(storage)
+
+	analysis, err := engine.SearchImports(ImportQueryOptions{})
+	if err != nil {
+		t.Fatalf("Failed to search imports: %v", err)
+	}
+
+	if len(analysis.Imports) == 0 || analysis.Imports[0].Path != "fmt" || analysis.Imports[0].Count != 2 {
+		t.Fatalf("Expected fmt to be the most used import, got %v", analysis.Imports)
+	}
+
+	expectedCategories := map[string]string{
+		"fmt":                            ImportCategoryStdlib,
+		"net/http":                       ImportCategoryStdlib,
+		"github.com/spf13/cobra":         ImportCategoryThirdParty,
+		"example.com/app/internal/store": ImportCategoryInternal,
+		"os":                             ImportCategoryExternal,
+		".models.User":                   ImportCategoryRelative,
+		"app.models":                     ImportCategoryInternal,
+		"java.util.List":                 ImportCategoryStdlib,
+		"org.junit.Test":                 ImportCategoryThirdParty,
+		"com.example.util.Strings":       ImportCategoryInternal,
+		"queue.h":                        ImportCategoryInternal,
+		"stdio.h":                        ImportCategoryExternal,
+	}
+	for _, usage := range analysis.Imports {
+		if expected, ok := expectedCategories[usage.Path]; ok && usage.Category != expected {
+			t.Errorf("Expected %s to be %s, got %s", usage.Path, expected, usage.Category)
+		}
+	}
+	if len(analysis.Imports) != len(expectedCategories)+1 {
+		t.Errorf("Expected %d distinct imports, got %d", len(expectedCategories)+1, len(analysis.Imports))
+	}
+	if analysis.Categories[ImportCategoryStdlib] != 4 {
+		t.Errorf("Expected 4 standard library imports, got %d", analysis.Categories[ImportCategoryStdlib])
+	}
+
+	if len(analysis.Files) != 5 {
+		t.Fatalf("Expected 5 files with imports, got %v", analysis.Files)
+	}
+
+	filtered, err := engine.SearchImports(ImportQueryOptions{FilePath: "internal/store"})
+	if err != nil {
+		t.Fatalf("Failed to search imports: %v", err)
+	}
+	if len(filtered.Files) != 1 || filtered.Files[0].File != "internal/store/store.go" {
+		t.Fatalf("Expected only store.go, got %v", filtered.Files)
+	}
+	if got := filtered.Files[0].Imports; len(got) != 2 || got[0] != "fmt" || got[1] != "database/sql" {
+		t.Errorf("Expected imports in source order, got %v", got)
+	}
+}
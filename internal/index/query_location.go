@@ -0,0 +1,100 @@
+package index
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"repository-context-protocol/internal/models"
+)
+
+// FindEnclosingSymbol returns the function or type whose lines contain line in file,
+// preferring the innermost one, so a line in a method resolves to the method rather than
+// its class and a line in a closure to the closure when anonymous functions are indexed.
+// Methods that are not indexed as functions, such as Python methods, are returned as
+// functions named Type.method. It returns nil when no symbol encloses the line and an
+// error when the file has no indexed symbols.
+func (qe *QueryEngine) FindEnclosingSymbol(file string, line int) (*SearchResultEntry, error) {
+	if line <= 0 {
+		return nil, fmt.Errorf("line must be positive, got %d", line)
+	}
+
+	entries, err := qe.collectEntriesByTypes(append([]string{EntityTypeFunction}, TypeKinds()...))
+	if err != nil {
+		return nil, err
+	}
+
+	file = filepath.Clean(file)
+	indexed := false
+	var enclosing *SearchResultEntry
+	consider := func(candidate *SearchResultEntry) {
+		if encloses(&candidate.IndexEntry, line) && (enclosing == nil || isInnerSymbol(&candidate.IndexEntry, &enclosing.IndexEntry)) {
+			enclosing = candidate
+		}
+	}
+	for i := range entries {
+		entry := &entries[i]
+		if filepath.Clean(entry.IndexEntry.File) != file {
+			continue
+		}
+		indexed = true
+		consider(entry)
+		if IsTypeKind(entry.IndexEntry.Type) && encloses(&entry.IndexEntry, line) {
+			for _, method := range unindexedMethods(entry) {
+				consider(method)
+			}
+		}
+	}
+	if !indexed {
+		return nil, fmt.Errorf("no symbols indexed in %s", file)
+	}
+	return enclosing, nil
+}
+
+// encloses reports whether line lies within an entry, treating an entry without an end
+// line as a single line
+func encloses(entry *models.IndexEntry, line int) bool {
+	return entry.StartLine <= line && line <= max(entry.EndLine, entry.StartLine)
+}
+
+// isInnerSymbol reports whether candidate spans fewer lines than current, or as many
+// lines starting later, so nested declarations win over the ones containing them
+func isInnerSymbol(candidate, current *models.IndexEntry) bool {
+	candidateSpan := max(candidate.EndLine, candidate.StartLine) - candidate.StartLine
+	currentSpan := max(current.EndLine, current.StartLine) - current.StartLine
+	if candidateSpan != currentSpan {
+		return candidateSpan < currentSpan
+	}
+	return candidate.StartLine > current.StartLine
+}
+
+// unindexedMethods returns entries for the methods of a type entry that have no function
+// entry of their own: methods of languages listing them only on their type, and Go
+// interface methods. Go methods with a receiver are indexed as functions already.
+func unindexedMethods(entry *SearchResultEntry) []*SearchResultEntry {
+	fileData := entryFileData(entry)
+	if fileData == nil {
+		return nil
+	}
+
+	var methods []*SearchResultEntry
+	for i := range fileData.Types {
+		typeDef := &fileData.Types[i]
+		if typeDef.Name != entry.IndexEntry.Name || typeDef.StartLine != entry.IndexEntry.StartLine {
+			continue
+		}
+		for j := range typeDef.Methods {
+			method := &typeDef.Methods[j]
+			if method.Receiver != "" || method.File != "" || method.StartLine <= 0 {
+				continue
+			}
+			methodEntry := *entry
+			methodEntry.IndexEntry.Name = typeDef.Name + "." + method.Name
+			methodEntry.IndexEntry.Type = EntityTypeFunction
+			methodEntry.IndexEntry.StartLine = method.StartLine
+			methodEntry.IndexEntry.EndLine = method.EndLine
+			methodEntry.IndexEntry.Signature = method.Signature
+			methods = append(methods, &methodEntry)
+		}
+	}
+	return methods
+}
@@ -0,0 +1,93 @@
+package index
+
+import (
+	"testing"
+
+	"repository-context-protocol/internal/models"
+)
+
+func newLocationTestEngine(t *testing.T) *QueryEngine {
+	return NewQueryEngine(newDiffTestStorage(t,
+		&models.FileContext{
+			Path: "server/server.go", Language: "go",
+			Types: []models.TypeDef{
+				{Name: "Server", Kind: "struct", StartLine: 5, EndLine: 8, Methods: []models.Method{
+					{Name: "Serve", Receiver: "Server", StartLine: 15, EndLine: 22},
+				}},
+				{Name: "Handler", Kind: "interface", StartLine: 10, EndLine: 13, Methods: []models.Method{
+					{Name: "Handle", Signature: "Handle(req Request) error", StartLine: 11, EndLine: 11},
+				}},
+			},
+			Functions: []models.Function{
+				{Name: "Serve", Receiver: "Server", StartLine: 15, EndLine: 22},
+				{Name: "func@server/server.go:17", Parent: "Server.Serve", StartLine: 17, EndLine: 19},
+			},
+		},
+		&models.FileContext{
+			Path: "app/report.py", Language: "python",
+			Types: []models.TypeDef{
+				{Name: "Report", Kind: "class", StartLine: 3, EndLine: 20, Methods: []models.Method{
+					{Name: "__init__", Signature: "def __init__(self)", StartLine: 4, EndLine: 6},
+					{Name: "render", Signature: "def render(self) -> str", StartLine: 8, EndLine: 20},
+				}},
+			},
+			Functions: []models.Function{{Name: "load", StartLine: 23, EndLine: 25}},
+		},
+	))
+}
+
+func TestQueryEngine_FindEnclosingSymbol(t *testing.T) {
+	engine := newLocationTestEngine(t)
+
+	tests := []struct {
+		file     string
+		line     int
+		name     string
+		kind     string
+		expected bool
+	}{
+		{"server/server.go", 6, "Server", "struct", true},
+		{"server/server.go", 11, "Handler.Handle", "function", true},
+		{"server/server.go", 12, "Handler", "interface", true},
+		{"server/server.go", 16, "Serve", "function", true},
+		{"server/server.go", 18, "func@server/server.go:17", "function", true},
+		{"./server/server.go", 22, "Serve", "function", true},
+		{"server/server.go", 2, "", "", false},
+		{"app/report.py", 3, "Report", "class", true},
+		{"app/report.py", 7, "Report", "class", true},
+		{"app/report.py", 12, "Report.render", "function", true},
+		{"app/report.py", 24, "load", "function", true},
+		{"app/report.py", 40, "", "", false},
+	}
+	for _, tt := range tests {
+		entry, err := engine.FindEnclosingSymbol(tt.file, tt.line)
+		if err != nil {
+			t.Fatalf("Failed to find symbol at %s:%d: %v", tt.file, tt.line, err)
+		}
+		if !tt.expected {
+			if entry != nil {
+				t.Errorf("Expected no symbol at %s:%d, got %s", tt.file, tt.line, entry.IndexEntry.Name)
+			}
+			continue
+		}
+		if entry == nil {
+			t.Errorf("Expected %s at %s:%d, got none", tt.name, tt.file, tt.line)
+			continue
+		}
+		if entry.IndexEntry.Name != tt.name || entry.IndexEntry.Type != tt.kind {
+			t.Errorf("Expected %s %s at %s:%d, got %s %s",
+				tt.kind, tt.name, tt.file, tt.line, entry.IndexEntry.Type, entry.IndexEntry.Name)
+		}
+	}
+}
+
+func TestQueryEngine_FindEnclosingSymbolErrors(t *testing.T) {
+	engine := newLocationTestEngine(t)
+
+	if _, err := engine.FindEnclosingSymbol("missing.go", 3); err == nil {
+		t.Error("Expected an error for a file without indexed symbols")
+	}
+	if _, err := engine.FindEnclosingSymbol("server/server.go", 0); err == nil {
+		t.Error("Expected an error for a non-positive line")
+	}
+}
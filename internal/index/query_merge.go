@@ -0,0 +1,114 @@
+package index
+
+import (
+	"slices"
+	"strings"
+)
+
+// MergeResults combines several search results into one deduplicated result, e.g. to
+// answer "functions matching A or matching B" within a single token budget. Entries are
+// unioned by name, file and type in the order they first appear, call graphs are unioned,
+// and the smallest token limit among the original queries is applied to the merged entries.
+func (qe *QueryEngine) MergeResults(results ...*SearchResult) *SearchResult {
+	merged := &SearchResult{
+		SearchType: "merged",
+		Entries:    []SearchResultEntry{},
+	}
+
+	var queries []string
+	options := &QueryOptions{}
+	seen := make(map[string]bool)
+	for _, result := range results {
+		if result == nil {
+			continue
+		}
+
+		if result.Query != "" && !slices.Contains(queries, result.Query) {
+			queries = append(queries, result.Query)
+		}
+		if merged.ExecutedAt.IsZero() || (!result.ExecutedAt.IsZero() && result.ExecutedAt.Before(merged.ExecutedAt)) {
+			merged.ExecutedAt = result.ExecutedAt
+		}
+		// A merged result is as stale as its stalest part
+		if merged.IndexGeneration == 0 || (result.IndexGeneration != 0 && result.IndexGeneration < merged.IndexGeneration) {
+			merged.IndexGeneration = result.IndexGeneration
+		}
+		if merged.IndexBuildTime.IsZero() || (!result.IndexBuildTime.IsZero() && result.IndexBuildTime.Before(merged.IndexBuildTime)) {
+			merged.IndexBuildTime = result.IndexBuildTime
+		}
+		merged.Truncated = merged.Truncated || result.Truncated
+		merged.TimedOut = merged.TimedOut || result.TimedOut
+
+		if result.Options != nil {
+			options.IncludeCallers = options.IncludeCallers || result.Options.IncludeCallers
+			options.IncludeCallees = options.IncludeCallees || result.Options.IncludeCallees
+			if result.Options.MaxTokens > 0 && (options.MaxTokens == 0 || result.Options.MaxTokens < options.MaxTokens) {
+				options.MaxTokens = result.Options.MaxTokens
+			}
+		}
+
+		for i := range result.Entries {
+			key := mergeKey(&result.Entries[i])
+			if !seen[key] {
+				seen[key] = true
+				merged.Entries = append(merged.Entries, result.Entries[i])
+			}
+		}
+
+		merged.CallGraph = mergeCallGraphs(merged.CallGraph, result.CallGraph)
+	}
+
+	merged.Query = strings.Join(queries, " OR ")
+	merged.Options = options
+	qe.applyTokenLimits(merged, options.MaxTokens)
+
+	return merged
+}
+
+// mergeKey identifies an entry by name, file and type when merging results
+func mergeKey(entry *SearchResultEntry) string {
+	return entry.IndexEntry.Type + ":" + entry.IndexEntry.Name + ":" + entry.IndexEntry.File
+}
+
+// mergeCallGraphs returns the union of two call graphs. Relationships that appear in
+// both keep the shortest depth; the merged graph names every queried function.
+func mergeCallGraphs(base, other *CallGraphInfo) *CallGraphInfo {
+	if other == nil {
+		return base
+	}
+	if base == nil {
+		base = &CallGraphInfo{Function: other.Function}
+	} else if other.Function != "" && !slices.Contains(strings.Split(base.Function, ", "), other.Function) {
+		base.Function += ", " + other.Function
+	}
+
+	base.Callers = mergeCallGraphEntries(base.Callers, other.Callers)
+	base.Callees = mergeCallGraphEntries(base.Callees, other.Callees)
+	base.Depth = max(base.Depth, other.Depth)
+	base.TimedOut = base.TimedOut || other.TimedOut
+	base.Truncated = base.Truncated || other.Truncated
+	for _, cycle := range other.Cycles {
+		if !slices.ContainsFunc(base.Cycles, func(existing []string) bool { return slices.Equal(existing, cycle) }) {
+			base.Cycles = append(base.Cycles, cycle)
+		}
+	}
+
+	return base
+}
+
+// mergeCallGraphEntries appends the entries of other that are not yet in base,
+// identifying entries by function, file and line
+func mergeCallGraphEntries(base, other []CallGraphEntry) []CallGraphEntry {
+	for _, entry := range other {
+		index := slices.IndexFunc(base, func(existing CallGraphEntry) bool {
+			return existing.Function == entry.Function && existing.File == entry.File && existing.Line == entry.Line
+		})
+		switch {
+		case index < 0:
+			base = append(base, entry)
+		case entry.Depth < base[index].Depth:
+			base[index].Depth = entry.Depth
+		}
+	}
+	return base
+}
@@ -0,0 +1,107 @@
+package index
+
+import (
+	"testing"
+	"time"
+
+	"repository-context-protocol/internal/models"
+)
+
+func TestQueryEngine_MergeResults(t *testing.T) {
+	engine := NewQueryEngine(nil)
+	earlier := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	entry := func(name, file, entityType string) SearchResultEntry {
+		return SearchResultEntry{IndexEntry: models.IndexEntry{Name: name, File: file, Type: entityType}}
+	}
+
+	first := &SearchResult{
+		Query:      "Get*",
+		Entries:    []SearchResultEntry{entry("GetUser", "user.go", "function"), entry("GetOrder", "order.go", "function")},
+		ExecutedAt: earlier.Add(time.Minute),
+		Options:    &QueryOptions{IncludeCallers: true},
+		CallGraph: &CallGraphInfo{
+			Function: "GetUser",
+			Callers:  []CallGraphEntry{{Function: "main", File: "main.go", Line: 3, Depth: 2}},
+			Depth:    2,
+		},
+	}
+	second := &SearchResult{
+		Query:      "*User",
+		Entries:    []SearchResultEntry{entry("GetUser", "user.go", "function"), entry("User", "user.go", "struct")},
+		ExecutedAt: earlier,
+		Truncated:  true,
+		CallGraph: &CallGraphInfo{
+			Function: "GetUser",
+			Callers: []CallGraphEntry{
+				{Function: "main", File: "main.go", Line: 3, Depth: 1},
+				{Function: "handler", File: "api.go", Line: 8, Depth: 1},
+			},
+			Cycles: [][]string{{"a", "b"}},
+			Depth:  1,
+		},
+	}
+
+	merged := engine.MergeResults(first, nil, second)
+
+	if merged.SearchType != "merged" || merged.Query != "Get* OR *User" {
+		t.Errorf("Expected merged search of both queries, got %q %q", merged.SearchType, merged.Query)
+	}
+	if !merged.ExecutedAt.Equal(earlier) {
+		t.Errorf("Expected earliest execution time %v, got %v", earlier, merged.ExecutedAt)
+	}
+	if !merged.Truncated {
+		t.Error("Expected truncation of an input to carry over")
+	}
+	var names []string
+	for _, e := range merged.Entries {
+		names = append(names, e.IndexEntry.Name)
+	}
+	if len(names) != 3 || names[0] != "GetUser" || names[1] != "GetOrder" || names[2] != "User" {
+		t.Errorf("Expected GetUser, GetOrder, User, got %v", names)
+	}
+	if merged.Facets["function"] != 2 || merged.Facets["struct"] != 1 {
+		t.Errorf("Expected facets to be recomputed, got %v", merged.Facets)
+	}
+	if merged.Options == nil || !merged.Options.IncludeCallers {
+		t.Error("Expected merged options to include callers")
+	}
+
+	graph := merged.CallGraph
+	if graph == nil || graph.Function != "GetUser" || len(graph.Callers) != 2 || graph.Depth != 2 || len(graph.Cycles) != 1 {
+		t.Fatalf("Expected a deduplicated call graph union, got %+v", graph)
+	}
+	if graph.Callers[0].Function != "main" || graph.Callers[0].Depth != 1 {
+		t.Errorf("Expected the duplicate caller to keep the shortest depth, got %+v", graph.Callers[0])
+	}
+	if first.CallGraph.Callers[0].Depth != 2 || len(first.CallGraph.Callers) != 1 {
+		t.Errorf("Expected inputs to be left unchanged, got %+v", first.CallGraph.Callers)
+	}
+}
+
+func TestQueryEngine_MergeResultsTokenLimit(t *testing.T) {
+	engine := NewQueryEngine(nil)
+
+	var entries []SearchResultEntry
+	for _, name := range []string{"alpha", "beta", "gamma", "delta"} {
+		entries = append(entries, SearchResultEntry{IndexEntry: models.IndexEntry{Name: name, File: name + ".go", Type: "function"}})
+	}
+	entryTokens := engine.estimateEntryTokens(&entries[0])
+
+	merged := engine.MergeResults(
+		&SearchResult{Entries: entries[:2], Options: &QueryOptions{MaxTokens: 100 * entryTokens}},
+		&SearchResult{Entries: entries[2:], Options: &QueryOptions{MaxTokens: 2 * entryTokens}},
+	)
+
+	if len(merged.Entries) != 2 || !merged.Truncated {
+		t.Errorf("Expected the smaller token limit to keep 2 entries, got %d (truncated %t)", len(merged.Entries), merged.Truncated)
+	}
+	if merged.Facets["function"] != 4 {
+		t.Errorf("Expected facets counted before truncation, got %v", merged.Facets)
+	}
+
+	empty := engine.MergeResults()
+	if empty.SearchType != "merged" || len(empty.Entries) != 0 || empty.CallGraph != nil {
+		t.Errorf("Expected an empty merged result, got %+v", empty)
+	}
+}
@@ -0,0 +1,230 @@
+package index
+
+import (
+	"cmp"
+	"fmt"
+	"path/filepath"
+	"slices"
+)
+
+const (
+	// DefaultOverviewMaxTokens caps an overview when no token limit is given
+	DefaultOverviewMaxTokens = 1500
+
+	// DefaultOverviewSectionLimit bounds each list of an overview when no limit is given
+	DefaultOverviewSectionLimit = 10
+)
+
+// OverviewOptions configures GetOverview
+type OverviewOptions struct {
+	MaxTokens    int                    // Token cap of the overview, DefaultOverviewMaxTokens when not positive
+	SectionLimit int                    // Maximum items per list, DefaultOverviewSectionLimit when not positive
+	ExcludeFile  func(path string) bool // Leaves files out of the overview when it returns true; optional
+}
+
+// OverviewSymbol is a function or type listed in an overview
+type OverviewSymbol struct {
+	Name      string `json:"name"`
+	Kind      string `json:"kind"`
+	File      string `json:"file"`
+	Line      int    `json:"line"`
+	Signature string `json:"signature,omitempty"`
+}
+
+// OverviewPackage is a directory of indexed files with its entity count
+type OverviewPackage struct {
+	Path     string `json:"path"`
+	Files    int    `json:"files"`
+	Entities int    `json:"entities"`
+}
+
+// RepositoryOverview is a compact orientation to an indexed repository, meant as the
+// first message an LLM reads about it
+type RepositoryOverview struct {
+	Files        int               `json:"files"`
+	Languages    map[string]int    `json:"languages"`     // Files per language
+	EntityCounts map[string]int    `json:"entity_counts"` // Functions, types, variables and constants
+	EntryPoints  []OverviewSymbol  `json:"entry_points"`
+	PublicAPI    []OverviewSymbol  `json:"public_api"`
+	MostCalled   []HotspotEntry    `json:"most_called"`
+	Packages     []OverviewPackage `json:"packages"` // Largest packages first
+	TokenCount   int               `json:"token_count"`
+	Truncated    bool              `json:"truncated,omitempty"` // Items were left out to stay within the token cap
+}
+
+// GetOverview summarizes the index: file and entity counts per language, entry points
+// such as main (see models.Function.IsEntryPoint), the exported types and functions, the most called functions and the
+// largest packages. Lists are filled in that order of priority until MaxTokens is spent,
+// so entry points and the public API survive a tight budget.
+func (qe *QueryEngine) GetOverview(options OverviewOptions) (*RepositoryOverview, error) {
+	if options.MaxTokens <= 0 {
+		options.MaxTokens = DefaultOverviewMaxTokens
+	}
+	if options.SectionLimit <= 0 {
+		options.SectionLimit = DefaultOverviewSectionLimit
+	}
+	if options.ExcludeFile == nil {
+		options.ExcludeFile = func(string) bool { return false }
+	}
+
+	overview := &RepositoryOverview{
+		Languages:    make(map[string]int),
+		EntityCounts: make(map[string]int),
+		EntryPoints:  []OverviewSymbol{},
+		PublicAPI:    []OverviewSymbol{},
+		MostCalled:   []HotspotEntry{},
+		Packages:     []OverviewPackage{},
+	}
+	packages, err := qe.overviewFiles(overview, options)
+	if err != nil {
+		return nil, err
+	}
+	entryPoints, publicAPI, err := qe.overviewSymbols(options)
+	if err != nil {
+		return nil, err
+	}
+	mostCalled, err := qe.overviewMostCalled(options)
+	if err != nil {
+		return nil, err
+	}
+
+	budget := &overviewBudget{estimator: qe.TokenEstimator(), remaining: options.MaxTokens}
+	budget.spend(fmt.Sprint(overview.Files, overview.Languages, overview.EntityCounts))
+	overview.EntryPoints = fitOverviewSymbols(budget, entryPoints, options.SectionLimit)
+	overview.PublicAPI = fitOverviewSymbols(budget, publicAPI, options.SectionLimit)
+	for _, hotspot := range mostCalled {
+		if !budget.spend(hotspot.Function, hotspot.File) {
+			break
+		}
+		overview.MostCalled = append(overview.MostCalled, hotspot)
+	}
+	for _, pkg := range packages[:min(len(packages), options.SectionLimit)] {
+		if !budget.spend(pkg.Path) {
+			break
+		}
+		overview.Packages = append(overview.Packages, pkg)
+	}
+
+	overview.TokenCount = options.MaxTokens - budget.remaining
+	overview.Truncated = budget.exhausted
+	return overview, nil
+}
+
+// overviewFiles counts files, languages and entities into overview and returns the
+// packages, largest first
+func (qe *QueryEngine) overviewFiles(overview *RepositoryOverview, options OverviewOptions) ([]OverviewPackage, error) {
+	files, err := qe.ListIndexedFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	var packages []OverviewPackage
+	positions := make(map[string]int)
+	for _, file := range files {
+		if options.ExcludeFile(file.Path) {
+			continue
+		}
+		overview.Files++
+		overview.Languages[file.Language]++
+		overview.EntityCounts[EntityTypeFunction] += file.Functions
+		overview.EntityCounts[EntityTypeType] += file.Types
+		overview.EntityCounts[EntityTypeVariable] += file.Variables
+		overview.EntityCounts[EntityTypeConstant] += file.Constants
+
+		dir := filepath.Dir(file.Path)
+		i, seen := positions[dir]
+		if !seen {
+			i = len(packages)
+			positions[dir] = i
+			packages = append(packages, OverviewPackage{Path: dir})
+		}
+		packages[i].Files++
+		packages[i].Entities += file.Entities
+	}
+
+	slices.SortStableFunc(packages, func(a, b OverviewPackage) int { return cmp.Compare(b.Entities, a.Entities) })
+	return packages, nil
+}
+
+// overviewSymbols returns the entry points and the exported types and functions, types
+// first, each in source order
+func (qe *QueryEngine) overviewSymbols(options OverviewOptions) (entryPoints, publicAPI []OverviewSymbol, err error) {
+	entries, err := qe.collectEntriesByTypes(append([]string{EntityTypeFunction}, TypeKinds()...))
+	if err != nil {
+		return nil, nil, err
+	}
+	sortBySourceOrder(entries)
+
+	var functions []OverviewSymbol
+	for i := range entries {
+		entry := &entries[i]
+		if options.ExcludeFile(entry.IndexEntry.File) {
+			continue
+		}
+		symbol := OverviewSymbol{
+			Name:      entry.IndexEntry.Name,
+			Kind:      entry.IndexEntry.Type,
+			File:      entry.IndexEntry.File,
+			Line:      entry.IndexEntry.StartLine,
+			Signature: entry.IndexEntry.Signature,
+		}
+		switch {
+		case symbol.Kind == EntityTypeFunction && isEntryPointEntry(entry):
+			entryPoints = append(entryPoints, symbol)
+		case !isExportedEntry(entry):
+		case symbol.Kind == EntityTypeFunction:
+			functions = append(functions, symbol)
+		default:
+			publicAPI = append(publicAPI, symbol)
+		}
+	}
+	return entryPoints, append(publicAPI, functions...), nil
+}
+
+// overviewMostCalled returns the most called functions outside excluded files
+func (qe *QueryEngine) overviewMostCalled(options OverviewOptions) ([]HotspotEntry, error) {
+	hotspots, err := qe.GetMostCalledFunctions(options.SectionLimit)
+	if err != nil {
+		return nil, err
+	}
+	return slices.DeleteFunc(hotspots, func(hotspot HotspotEntry) bool {
+		return options.ExcludeFile(hotspot.File)
+	}), nil
+}
+
+// fitOverviewSymbols keeps up to limit symbols that fit in the budget
+func fitOverviewSymbols(budget *overviewBudget, symbols []OverviewSymbol, limit int) []OverviewSymbol {
+	fitted := []OverviewSymbol{}
+	for _, symbol := range symbols[:min(len(symbols), limit)] {
+		if !budget.spend(symbol.Name, symbol.File, symbol.Signature) {
+			break
+		}
+		fitted = append(fitted, symbol)
+	}
+	return fitted
+}
+
+// overviewBudget tracks the tokens left for an overview
+type overviewBudget struct {
+	estimator TokenEstimator
+	remaining int
+	exhausted bool
+}
+
+// spend charges an item made of texts against the budget, refusing it and every later
+// item once the budget is exhausted
+func (b *overviewBudget) spend(texts ...string) bool {
+	if b.exhausted {
+		return false
+	}
+	tokens := TokenOverhead
+	for _, text := range texts {
+		tokens += b.estimator.EstimateTokens(text)
+	}
+	if tokens > b.remaining {
+		b.exhausted = true
+		return false
+	}
+	b.remaining -= tokens
+	return true
+}
@@ -0,0 +1,110 @@
+package index
+
+import (
+	"strings"
+	"testing"
+
+	"repository-context-protocol/internal/models"
+)
+
+func newOverviewTestEngine(t *testing.T) *QueryEngine {
+	return NewQueryEngine(newDiffTestStorage(t,
+		&models.FileContext{
+			Path: "cmd/app/main.go", Language: "go",
+			Functions: []models.Function{{Name: "main", StartLine: 3, Calls: []string{"Serve", "loadConfig"}}},
+		},
+		&models.FileContext{
+			Path: "server/server.go", Language: "go",
+			Types: []models.TypeDef{{Name: "Server", Kind: "struct", StartLine: 3}},
+			Functions: []models.Function{
+				{Name: "Serve", Signature: "func Serve() error", StartLine: 8, Calls: []string{"loadConfig"}},
+				{Name: "loadConfig", StartLine: 20},
+			},
+			Constants: []models.Constant{{Name: "DefaultPort", StartLine: 1}},
+			Exports: []models.Export{
+				{Name: "Server", Kind: "type"}, {Name: "Serve", Kind: "function"}, {Name: "DefaultPort", Kind: "constant"},
+			},
+		},
+		&models.FileContext{
+			Path: "scripts/gen.py", Language: "python",
+			Functions: []models.Function{{Name: "generate", StartLine: 1}},
+			Exports:   []models.Export{{Name: "generate", Kind: "function"}},
+		},
+	))
+}
+
+func TestQueryEngine_GetOverview(t *testing.T) {
+	engine := newOverviewTestEngine(t)
+
+	overview, err := engine.GetOverview(OverviewOptions{})
+	if err != nil {
+		t.Fatalf("Failed to get overview: %v", err)
+	}
+
+	if overview.Files != 3 || overview.Languages["go"] != 2 || overview.Languages["python"] != 1 {
+		t.Errorf("Expected 2 Go files and 1 Python file, got %d files %v", overview.Files, overview.Languages)
+	}
+	if overview.EntityCounts[EntityTypeFunction] != 4 || overview.EntityCounts[EntityTypeType] != 1 ||
+		overview.EntityCounts[EntityTypeConstant] != 1 {
+		t.Errorf("Unexpected entity counts %v", overview.EntityCounts)
+	}
+	if len(overview.EntryPoints) != 1 || overview.EntryPoints[0].File != "cmd/app/main.go" {
+		t.Errorf("Expected main as the entry point, got %+v", overview.EntryPoints)
+	}
+
+	var publicAPI []string
+	for _, symbol := range overview.PublicAPI {
+		publicAPI = append(publicAPI, symbol.Name)
+	}
+	if strings.Join(publicAPI, ",") != "Server,generate,Serve" {
+		t.Errorf("Expected exported types before functions, got %v", publicAPI)
+	}
+	if len(overview.MostCalled) == 0 || overview.MostCalled[0].Function != "loadConfig" {
+		t.Errorf("Expected loadConfig to be the most called function, got %+v", overview.MostCalled)
+	}
+	if len(overview.Packages) != 3 || overview.Packages[0].Path != "server" || overview.Packages[0].Entities != 4 {
+		t.Errorf("Expected the server package first, got %+v", overview.Packages)
+	}
+	if overview.Truncated || overview.TokenCount <= 0 {
+		t.Errorf("Expected an untruncated overview with a token count, got %d (truncated: %t)", overview.TokenCount, overview.Truncated)
+	}
+}
+
+func TestQueryEngine_GetOverviewBudget(t *testing.T) {
+	engine := newOverviewTestEngine(t)
+
+	t.Run("token cap keeps entry points first", func(t *testing.T) {
+		overview, err := engine.GetOverview(OverviewOptions{MaxTokens: 60})
+		if err != nil {
+			t.Fatalf("Failed to get overview: %v", err)
+		}
+		if !overview.Truncated || len(overview.EntryPoints) != 1 || len(overview.Packages) != 0 {
+			t.Errorf("Expected only the entry point to fit, got %+v", overview)
+		}
+		if overview.TokenCount > 60 {
+			t.Errorf("Expected at most 60 tokens, got %d", overview.TokenCount)
+		}
+	})
+
+	t.Run("section limit", func(t *testing.T) {
+		overview, err := engine.GetOverview(OverviewOptions{SectionLimit: 1})
+		if err != nil {
+			t.Fatalf("Failed to get overview: %v", err)
+		}
+		if len(overview.PublicAPI) != 1 || len(overview.Packages) != 1 || len(overview.MostCalled) != 1 {
+			t.Errorf("Expected one item per list, got %+v", overview)
+		}
+	})
+
+	t.Run("excluded files", func(t *testing.T) {
+		overview, err := engine.GetOverview(OverviewOptions{
+			ExcludeFile: func(path string) bool { return strings.HasPrefix(path, "scripts/") },
+		})
+		if err != nil {
+			t.Fatalf("Failed to get overview: %v", err)
+		}
+		if overview.Files != 2 || overview.Languages["python"] != 0 || len(overview.PublicAPI) != 2 {
+			t.Errorf("Expected the scripts directory to be left out, got %+v", overview)
+		}
+	})
+}
@@ -179,9 +179,7 @@ func TestQueryEngine_RegexCacheEfficiency(t *testing.T) {
 	}
 
 	// Verify cache contains the pattern
-	engine.regexMutex.RLock()
-	_, exists := engine.regexCache["^Handle.*User.*"]
-	engine.regexMutex.RUnlock()
+	_, exists := engine.regexCache.get("^Handle.*User.*")
 
 	if !exists {
 		t.Error("Expected compiled regex to be cached")
@@ -206,9 +206,7 @@ func TestQueryEngine_ConcurrentRegexCacheGrowth(t *testing.T) {
 	}
 
 	// Verify cache contains patterns (this access should be thread-safe)
-	engine.regexMutex.RLock()
-	cacheSize := len(engine.regexCache)
-	engine.regexMutex.RUnlock()
+	cacheSize := engine.regexCache.Len()
 
 	t.Logf("Regex cache contains %d compiled patterns after concurrent access", cacheSize)
 
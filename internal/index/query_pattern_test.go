@@ -2,6 +2,7 @@ package index
 
 import (
 	"os"
+	"slices"
 	"testing"
 	"time"
 
@@ -469,3 +470,136 @@ func setupTestDataWithPythonAST(t *testing.T, storage *HybridStorage) {
 		t.Fatalf("Failed to store Python AST test data: %v", err)
 	}
 }
+
+func TestQueryEngine_CaseInsensitiveSearch(t *testing.T) {
+	tempDir, storage := setupTestStorage(t)
+	defer os.RemoveAll(tempDir)
+
+	fileContext := &models.FileContext{
+		Path:     "urls.go",
+		Language: "go",
+		Functions: []models.Function{
+			{Name: "ParseURL", Signature: "func ParseURL(s string) *URL", StartLine: 1, EndLine: 3},
+			{Name: "ParseUrlQuery", Signature: "func ParseUrlQuery(s string) Values", StartLine: 5, EndLine: 7},
+			{Name: "parseUrlFragment", Signature: "func parseUrlFragment(s string) string", StartLine: 9, EndLine: 11},
+		},
+	}
+	if err := storage.StoreFileContext(fileContext); err != nil {
+		t.Fatalf("Failed to store file context: %v", err)
+	}
+
+	engine := NewQueryEngine(storage)
+
+	names := func(result *SearchResult) []string {
+		var matched []string
+		for _, entry := range result.Entries {
+			matched = append(matched, entry.IndexEntry.Name)
+		}
+		slices.Sort(matched)
+		return matched
+	}
+
+	t.Run("exact name", func(t *testing.T) {
+		result, err := engine.SearchByNameWithOptions("parseurl", QueryOptions{})
+		if err != nil {
+			t.Fatalf("Failed to search by name: %v", err)
+		}
+		if len(result.Entries) != 0 {
+			t.Errorf("Expected case-sensitive search to miss, got %v", names(result))
+		}
+
+		result, err = engine.SearchByNameWithOptions("parseurl", QueryOptions{CaseInsensitive: true})
+		if err != nil {
+			t.Fatalf("Failed to search by name: %v", err)
+		}
+		if got := names(result); !slices.Equal(got, []string{"ParseURL"}) {
+			t.Errorf("Expected ParseURL, got %v", got)
+		}
+	})
+
+	patternTests := []struct {
+		pattern  string
+		expected []string
+	}{
+		{"ParseUrl*", []string{"ParseURL", "ParseUrlQuery", "parseUrlFragment"}},
+		{"parse[u]rl*", []string{"ParseURL", "ParseUrlQuery", "parseUrlFragment"}},
+		{"/^parseurl$/", []string{"ParseURL"}},
+		{"/^parseurl(?!query)/", []string{"ParseURL", "parseUrlFragment"}},
+	}
+	for _, tt := range patternTests {
+		t.Run("pattern "+tt.pattern, func(t *testing.T) {
+			result, err := engine.SearchByPatternWithOptions(tt.pattern, QueryOptions{CaseInsensitive: true})
+			if err != nil {
+				t.Fatalf("Failed to search by pattern: %v", err)
+			}
+			if got := names(result); !slices.Equal(got, tt.expected) {
+				t.Errorf("Pattern %s matched %v, want %v", tt.pattern, got, tt.expected)
+			}
+		})
+	}
+
+	t.Run("regex cache keeps case sensitivity distinct", func(t *testing.T) {
+		if !engine.matchesRegex("PARSEURL", "/^parseurl$/", true) {
+			t.Error("Expected case-insensitive regex to match")
+		}
+		if engine.matchesRegex("PARSEURL", "/^parseurl$/", false) {
+			t.Error("Expected case-sensitive regex not to reuse the case-insensitive compilation")
+		}
+		if !engine.matchesRegex("PARSEURL", "/(?s)^parseurl$/", true) {
+			t.Error("Expected the i flag to merge with existing leading flags")
+		}
+	})
+}
+
+// kindCountingStorage records the kinds a query engine asks its storage for
+type kindCountingStorage struct {
+	Storage
+	queried []string
+}
+
+func (s *kindCountingStorage) QueryByType(entryType string) ([]QueryResult, error) {
+	s.queried = append(s.queried, entryType)
+	return s.Storage.QueryByType(entryType)
+}
+
+func TestQueryEngine_PatternSearchEntityTypes(t *testing.T) {
+	storage := &kindCountingStorage{Storage: newDiffTestStorage(t, &models.FileContext{
+		Path: "user.go", Language: "go",
+		Functions: []models.Function{{Name: "UserName", StartLine: 1}},
+		Types:     []models.TypeDef{{Name: "User", Kind: "struct", StartLine: 5}},
+		Variables: []models.Variable{{Name: "UserCount", StartLine: 9}},
+	})}
+	engine := NewQueryEngine(storage)
+
+	tests := []struct {
+		name        string
+		entityTypes []string
+		expected    []string
+		scanned     []string
+	}{
+		{"default", nil, []string{"UserName", "UserCount"}, []string{EntityTypeFunction, EntityTypeVariable, EntityTypeConstant}},
+		{"functions only", []string{EntityTypeFunction}, []string{"UserName"}, []string{EntityTypeFunction}},
+		{"type kinds", []string{EntityTypeType}, []string{"User"}, TypeKinds()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			storage.queried = nil
+			result, err := engine.SearchByPatternWithOptions("User*", QueryOptions{EntityTypes: tt.entityTypes})
+			if err != nil {
+				t.Fatalf("Pattern search failed: %v", err)
+			}
+
+			var names []string
+			for _, entry := range result.Entries {
+				names = append(names, entry.IndexEntry.Name)
+			}
+			if !slices.Equal(names, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, names)
+			}
+			if !slices.Equal(storage.queried, tt.scanned) {
+				t.Errorf("Expected only %v to be scanned, got %v", tt.scanned, storage.queried)
+			}
+		})
+	}
+}
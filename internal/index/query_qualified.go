@@ -0,0 +1,199 @@
+package index
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"repository-context-protocol/internal/models"
+)
+
+// splitQualifiedName splits a dotted name such as "User.Activate" or "models.User.Activate"
+// into its qualifier and member. It reports false for names without a usable dot.
+func splitQualifiedName(name string) (qualifier, member string, ok bool) {
+	dot := strings.LastIndex(name, ".")
+	if dot <= 0 || dot == len(name)-1 {
+		return "", "", false
+	}
+	return name[:dot], name[dot+1:], true
+}
+
+// searchQualifiedName resolves "Type.Method" to methods on that type and "package.Name"
+// to entities declared in that package or module. A qualifier with several segments is
+// either a module path, such as "services.user", or a module and type, such as "models.User".
+func (qe *QueryEngine) searchQualifiedName(qualifier, member string, options QueryOptions) ([]SearchResultEntry, error) {
+	equal := func(a, b string) bool {
+		if options.CaseInsensitive {
+			return strings.EqualFold(a, b)
+		}
+		return a == b
+	}
+
+	module, typeName := "", qualifier
+	if dot := strings.LastIndex(qualifier, "."); dot >= 0 {
+		module, typeName = qualifier[:dot], qualifier[dot+1:]
+	}
+
+	memberResults, err := qe.queryName(member, options.CaseInsensitive)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := []SearchResultEntry{}
+	seen := make(map[string]bool)
+	add := func(entry SearchResultEntry) {
+		key := entryKey(&entry.IndexEntry)
+		if !seen[key] {
+			seen[key] = true
+			entries = append(entries, entry)
+		}
+	}
+
+	for _, qr := range memberResults {
+		entry := SearchResultEntry(qr)
+		fileData := entryFileData(&entry)
+		if fileData == nil {
+			continue
+		}
+		if entry.IndexEntry.Type == EntityTypeFunction && isMethodOf(fileData, &entry.IndexEntry, typeName, equal) &&
+			(module == "" || matchesModule(fileData, module, equal)) {
+			add(entry)
+			continue
+		}
+		// Methods belong to their type rather than directly to the package
+		if functionReceiver(fileData, &entry.IndexEntry) == "" && matchesModule(fileData, qualifier, equal) {
+			add(entry)
+		}
+	}
+
+	// Methods that are only recorded on their type, such as Python methods, have no
+	// index entries of their own and are found through the type instead
+	typeResults, err := qe.queryName(typeName, options.CaseInsensitive)
+	if err != nil {
+		return nil, err
+	}
+	for _, qr := range typeResults {
+		if !IsTypeKind(qr.IndexEntry.Type) {
+			continue
+		}
+		typeEntry := SearchResultEntry(qr)
+		fileData := entryFileData(&typeEntry)
+		if fileData == nil || (module != "" && !matchesModule(fileData, module, equal)) {
+			continue
+		}
+		for _, method := range typeMethods(fileData, &typeEntry.IndexEntry) {
+			if !equal(method.Name, member) {
+				continue
+			}
+			add(SearchResultEntry{
+				IndexEntry: models.IndexEntry{
+					Name:      method.Name,
+					Type:      EntityTypeFunction,
+					File:      typeEntry.IndexEntry.File,
+					StartLine: method.StartLine,
+					EndLine:   method.EndLine,
+					ChunkID:   typeEntry.IndexEntry.ChunkID,
+					Signature: method.Signature,
+					Language:  typeEntry.IndexEntry.Language,
+				},
+				ChunkData: typeEntry.ChunkData,
+			})
+		}
+	}
+
+	return entries, nil
+}
+
+// queryName queries storage for an exact name, optionally ignoring case
+func (qe *QueryEngine) queryName(name string, caseInsensitive bool) ([]QueryResult, error) {
+	if caseInsensitive {
+		return qe.storage.QueryByNameCaseInsensitive(name)
+	}
+	return qe.storage.QueryByName(name)
+}
+
+// entryKey identifies an index entry across queries
+func entryKey(entry *models.IndexEntry) string {
+	return fmt.Sprintf("%s:%s:%s:%d", entry.Type, entry.Name, entry.File, entry.StartLine)
+}
+
+// entryFileData returns the file context of the file defining entry from its chunk
+func entryFileData(entry *SearchResultEntry) *models.FileContext {
+	if entry.ChunkData == nil {
+		return nil
+	}
+	for i := range entry.ChunkData.FileData {
+		if entry.ChunkData.FileData[i].Path == entry.IndexEntry.File {
+			return &entry.ChunkData.FileData[i]
+		}
+	}
+	return nil
+}
+
+// isMethodOf reports whether the function entry is a method of typeName, either through
+// its receiver or by appearing among the methods of a type with that name in the file
+func isMethodOf(fileData *models.FileContext, entry *models.IndexEntry, typeName string, equal func(a, b string) bool) bool {
+	if receiver := functionReceiver(fileData, entry); receiver != "" {
+		return equal(receiver, typeName)
+	}
+	for i := range fileData.Types {
+		typeDef := &fileData.Types[i]
+		if !equal(typeDef.Name, typeName) {
+			continue
+		}
+		for _, method := range typeDef.Methods {
+			if method.Name == entry.Name && method.StartLine == entry.StartLine {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// functionReceiver returns the receiver type of the function entry, "" for plain functions
+func functionReceiver(fileData *models.FileContext, entry *models.IndexEntry) string {
+	if entry.Type != EntityTypeFunction {
+		return ""
+	}
+	for i := range fileData.Functions {
+		function := &fileData.Functions[i]
+		if function.Name == entry.Name && function.StartLine == entry.StartLine {
+			return function.Receiver
+		}
+	}
+	return ""
+}
+
+// typeMethods returns the methods of the type definition entry refers to
+func typeMethods(fileData *models.FileContext, entry *models.IndexEntry) []models.Method {
+	for i := range fileData.Types {
+		if fileData.Types[i].Name == entry.Name && fileData.Types[i].StartLine == entry.StartLine {
+			return fileData.Types[i].Methods
+		}
+	}
+	return nil
+}
+
+// matchesModule reports whether module names the package or module of fileData: its
+// declared package or a trailing part of it, or the file's path without extension
+// written with dots, e.g. "services.user" for "app/services/user.py"
+func matchesModule(fileData *models.FileContext, module string, equal func(a, b string) bool) bool {
+	if fileData.Package != "" && hasDottedSuffix(fileData.Package, module, equal) {
+		return true
+	}
+	modulePath := strings.TrimSuffix(fileData.Path, path.Ext(fileData.Path))
+	modulePath = strings.ReplaceAll(strings.TrimPrefix(modulePath, "/"), "/", ".")
+	return hasDottedSuffix(modulePath, module, equal)
+}
+
+// hasDottedSuffix reports whether suffix equals name or its trailing dotted segments
+func hasDottedSuffix(name, suffix string, equal func(a, b string) bool) bool {
+	if len(suffix) > len(name) {
+		return false
+	}
+	start := len(name) - len(suffix)
+	if start > 0 && name[start-1] != '.' {
+		return false
+	}
+	return equal(name[start:], suffix)
+}
@@ -0,0 +1,92 @@
+package index
+
+import (
+	"testing"
+
+	"repository-context-protocol/internal/models"
+)
+
+func newQualifiedTestEngine(t *testing.T) *QueryEngine {
+	t.Helper()
+
+	storage := newDiffTestStorage(t,
+		&models.FileContext{
+			Path: "internal/users/user.go", Language: "go", Package: "users",
+			Types: []models.TypeDef{{Name: "User", Kind: "struct", StartLine: 3, EndLine: 5}},
+			Functions: []models.Function{
+				{Name: "Activate", Signature: "func (u *User) Activate()", Receiver: "User", StartLine: 7, EndLine: 9},
+				{Name: "New", Signature: "func New() *User", StartLine: 11, EndLine: 13},
+			},
+		},
+		&models.FileContext{
+			Path: "internal/teams/team.go", Language: "go", Package: "teams",
+			Types: []models.TypeDef{{Name: "Team", Kind: "struct", StartLine: 3, EndLine: 5}},
+			Functions: []models.Function{
+				{Name: "Activate", Signature: "func (t *Team) Activate()", Receiver: "Team", StartLine: 7, EndLine: 9},
+				{Name: "New", Signature: "func New() *Team", StartLine: 11, EndLine: 13},
+			},
+		},
+		&models.FileContext{
+			Path: "app/services/account.py", Language: "python",
+			Types: []models.TypeDef{{
+				Name: "Account", Kind: "class", StartLine: 1, EndLine: 10,
+				Methods: []models.Method{{Name: "activate", Signature: "def activate(self)", StartLine: 4, EndLine: 6}},
+			}},
+			Functions: []models.Function{{Name: "open_account", Signature: "def open_account()", StartLine: 12, EndLine: 14}},
+		},
+	)
+	return NewQueryEngine(storage)
+}
+
+func TestQueryEngine_SearchByQualifiedName(t *testing.T) {
+	engine := newQualifiedTestEngine(t)
+
+	tests := []struct {
+		name     string
+		query    string
+		options  QueryOptions
+		wantFile string
+		wantLine int
+	}{
+		{name: "Go method by receiver", query: "User.Activate", wantFile: "internal/users/user.go", wantLine: 7},
+		{name: "Go function by package", query: "teams.New", wantFile: "internal/teams/team.go", wantLine: 11},
+		{name: "package and type", query: "users.User.Activate", wantFile: "internal/users/user.go", wantLine: 7},
+		{name: "Python method on class", query: "Account.activate", wantFile: "app/services/account.py", wantLine: 4},
+		{name: "Python function by module", query: "services.account.open_account", wantFile: "app/services/account.py", wantLine: 12},
+		{
+			name: "case insensitive", query: "team.activate", options: QueryOptions{CaseInsensitive: true},
+			wantFile: "internal/teams/team.go", wantLine: 7,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := engine.SearchByNameWithOptions(tt.query, tt.options)
+			if err != nil {
+				t.Fatalf("Failed to search %s: %v", tt.query, err)
+			}
+			if len(result.Entries) != 1 {
+				t.Fatalf("Expected one entry for %s, got %+v", tt.query, result.Entries)
+			}
+			entry := result.Entries[0].IndexEntry
+			if entry.File != tt.wantFile || entry.StartLine != tt.wantLine || entry.Type != EntityTypeFunction {
+				t.Errorf("Expected function at %s:%d, got %+v", tt.wantFile, tt.wantLine, entry)
+			}
+		})
+	}
+}
+
+func TestQueryEngine_SearchByQualifiedNameNoMatch(t *testing.T) {
+	engine := newQualifiedTestEngine(t)
+
+	// Methods are not package members, and unknown qualifiers match nothing
+	for _, query := range []string{"users.Activate", "Group.Activate", "User.", ".Activate"} {
+		result, err := engine.SearchByName(query)
+		if err != nil {
+			t.Fatalf("Failed to search %s: %v", query, err)
+		}
+		if len(result.Entries) != 0 {
+			t.Errorf("Expected no entries for %s, got %+v", query, result.Entries)
+		}
+	}
+}
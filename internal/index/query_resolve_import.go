@@ -0,0 +1,148 @@
+package index
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// ResolveImport returns the indexed definition of symbol as imported through importPath,
+// such as "User" from the Python module "app.models" or the Go package
+// "github.com/org/repo/internal/models". When symbol is empty the last segment of the
+// path names it, as in the "app.models.User" records of Python from-imports. Relative
+// imports need the importing file; see ResolveImportFrom.
+func (qe *QueryEngine) ResolveImport(importPath, symbol string) (*SearchResultEntry, error) {
+	return qe.ResolveImportFrom("", importPath, symbol)
+}
+
+// ResolveImportFrom resolves an import made by fromFile, so relative Python imports such as
+// ".models" or "..core.config" resolve against the directory of fromFile. The module is
+// matched against indexed files and directories by trailing path segments, preferring the
+// longest match, and the symbol is looked up among the definitions of the matching files.
+// It returns nil when the module is indexed but does not define the symbol, and an error
+// when no indexed file or directory matches the module.
+func (qe *QueryEngine) ResolveImportFrom(fromFile, importPath, symbol string) (*SearchResultEntry, error) {
+	importPath, symbol = strings.TrimSpace(importPath), strings.TrimSpace(symbol)
+	if importPath == "" {
+		return nil, fmt.Errorf("import path is required")
+	}
+	if symbol == "" {
+		var err error
+		if importPath, symbol, err = SplitImportedSymbol(importPath); err != nil {
+			return nil, err
+		}
+	}
+
+	modulePath, err := importModulePath(fromFile, importPath)
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := qe.storage.ListFiles()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list files: %w", err)
+	}
+	moduleFiles := importModuleFiles(files, modulePath, strings.HasPrefix(importPath, "."))
+	if len(moduleFiles) == 0 {
+		return nil, fmt.Errorf("import '%s' does not match an indexed module", importPath)
+	}
+
+	results, err := qe.storage.QueryByName(symbol)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query by name: %w", err)
+	}
+	entries := make([]SearchResultEntry, 0, len(results))
+	for _, result := range results {
+		if moduleFiles[filepath.ToSlash(filepath.Clean(result.IndexEntry.File))] {
+			entries = append(entries, SearchResultEntry(result))
+		}
+	}
+	if len(entries) == 0 {
+		return nil, nil
+	}
+	sortBySourceOrder(entries)
+	return &entries[0], nil
+}
+
+// SplitImportedSymbol splits the last segment off an import path as the imported symbol,
+// so "app.models.User" is User from app.models and "..User" is User from the importing
+// file's package, as Python from-imports are recorded
+func SplitImportedSymbol(importPath string) (modulePath, symbol string, err error) {
+	cut := strings.LastIndexAny(importPath, "./")
+	if cut <= 0 || cut == len(importPath)-1 {
+		return "", "", fmt.Errorf("symbol is required for import '%s'", importPath)
+	}
+	return importPath[:cut], importPath[cut+1:], nil
+}
+
+// importModulePath returns the slash separated path of the module an import names: Python
+// dotted modules become paths, and relative ones are joined to the importing file's directory
+func importModulePath(fromFile, importPath string) (string, error) {
+	if !strings.HasPrefix(importPath, ".") {
+		if strings.Contains(importPath, "/") {
+			return strings.Trim(importPath, "/"), nil
+		}
+		return strings.ReplaceAll(importPath, ".", "/"), nil
+	}
+	if fromFile == "" {
+		return "", fmt.Errorf("relative import '%s' needs the importing file", importPath)
+	}
+
+	// One dot is the importing file's package, each further dot its parent
+	module := strings.TrimLeft(importPath, ".")
+	dir := path.Dir(filepath.ToSlash(filepath.Clean(fromFile)))
+	for range len(importPath) - len(module) - 1 {
+		dir = path.Dir(dir)
+	}
+	return path.Clean(path.Join(dir, strings.ReplaceAll(module, ".", "/"))), nil
+}
+
+// importModuleFiles returns the indexed files making up a module: files whose path without
+// extension is the module, package directories' __init__ files and, for packages, the files
+// directly in the module directory. An absolute import matches by trailing segments either
+// way, so a Go import path longer than the indexed directory or an indexed path under a
+// source root both match, and only the candidates sharing the most segments are kept.
+func importModuleFiles(files []string, modulePath string, relative bool) map[string]bool {
+	best := 0
+	var matched []string
+	for _, file := range files {
+		file = filepath.ToSlash(filepath.Clean(file))
+		stem := strings.TrimSuffix(file, path.Ext(file))
+		score := max(modulePathMatch(strings.TrimSuffix(stem, "/__init__"), modulePath, relative),
+			modulePathMatch(path.Dir(file), modulePath, relative))
+		switch {
+		case score == 0 || score < best:
+			continue
+		case score > best:
+			best, matched = score, nil
+		}
+		matched = append(matched, file)
+	}
+
+	moduleFiles := make(map[string]bool, len(matched))
+	for _, file := range matched {
+		moduleFiles[file] = true
+	}
+	return moduleFiles
+}
+
+// modulePathMatch returns how many segments an indexed path shares with a module path,
+// or zero when they do not match. Relative imports must match exactly, while absolute
+// ones may match by trailing segments of either path.
+func modulePathMatch(indexed, modulePath string, relative bool) int {
+	if indexed == modulePath {
+		return strings.Count(modulePath, "/") + 1
+	}
+	if relative || indexed == "." {
+		return 0
+	}
+	shorter, longer := indexed, modulePath
+	if len(shorter) > len(longer) {
+		shorter, longer = longer, shorter
+	}
+	if !strings.HasSuffix(longer, "/"+shorter) {
+		return 0
+	}
+	return strings.Count(shorter, "/") + 1
+}
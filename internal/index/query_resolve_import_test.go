@@ -0,0 +1,66 @@
+package index
+
+import (
+	"testing"
+
+	"repository-context-protocol/internal/models"
+)
+
+func TestQueryEngine_ResolveImport(t *testing.T) {
+	storage := newDiffTestStorage(t,
+		&models.FileContext{
+			Path: "app/models.py", Language: "python",
+			Types: []models.TypeDef{{Name: "User", Kind: "class", StartLine: 3, EndLine: 10}},
+		},
+		&models.FileContext{
+			Path: "app/core/config.py", Language: "python",
+			Variables: []models.Variable{{Name: "SETTINGS", StartLine: 1, EndLine: 1}},
+		},
+		&models.FileContext{
+			Path: "app/api/views.py", Language: "python",
+			Functions: []models.Function{{Name: "show_user", StartLine: 1, EndLine: 4}},
+		},
+		&models.FileContext{
+			Path: "internal/models/user.go", Language: "go",
+			Types: []models.TypeDef{{Name: "User", Kind: "struct", StartLine: 5, EndLine: 9}},
+		},
+	)
+	engine := NewQueryEngine(storage)
+
+	tests := []struct {
+		name       string
+		fromFile   string
+		importPath string
+		symbol     string
+		file       string // Defining file expected, empty when the symbol is not found
+	}{
+		{"absolute python module", "", "app.models", "User", "app/models.py"},
+		{"symbol from the path", "", "app.models.User", "", "app/models.py"},
+		{"relative to the package", "app/api/views.py", "..models", "User", "app/models.py"},
+		{"relative record with the symbol", "app/api/views.py", "..models.User", "", "app/models.py"},
+		{"relative to a subpackage", "app/api/views.py", "..core.config", "SETTINGS", "app/core/config.py"},
+		{"same package", "app/api/views.py", ".", "show_user", "app/api/views.py"},
+		{"go package path", "", "github.com/org/repo/internal/models", "User", "internal/models/user.go"},
+		{"symbol not in the module", "", "app.models", "Order", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry, err := engine.ResolveImportFrom(tt.fromFile, tt.importPath, tt.symbol)
+			if err != nil {
+				t.Fatalf("Failed to resolve import: %v", err)
+			}
+			switch {
+			case tt.file == "" && entry != nil:
+				t.Errorf("Expected no definition, got %+v", entry.IndexEntry)
+			case tt.file != "" && (entry == nil || entry.IndexEntry.File != tt.file):
+				t.Errorf("Expected the definition in %s, got %+v", tt.file, entry)
+			}
+		})
+	}
+
+	for _, importPath := range []string{"", "requests.sessions", ".models"} {
+		if _, err := engine.ResolveImport(importPath, "Session"); err == nil {
+			t.Errorf("Expected an error resolving %q without a match or importing file", importPath)
+		}
+	}
+}
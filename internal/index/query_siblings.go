@@ -0,0 +1,50 @@
+package index
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"repository-context-protocol/internal/models"
+)
+
+// FindSiblingDeclarations returns up to count declarations of file on each side of the
+// declaration spanning startLine to endLine: functions, types, variables and constants
+// starting before it, nearest last, and after it, nearest first. Declarations overlapping
+// it, such as its nested functions or the class around it, are not siblings, and neither
+// are declarations nested in a sibling.
+func (qe *QueryEngine) FindSiblingDeclarations(
+	file string, startLine, endLine, count int,
+) (before, after []SearchResultEntry, err error) {
+	if count <= 0 {
+		return nil, nil, fmt.Errorf("count must be positive, got %d", count)
+	}
+
+	entityTypes := append([]string{EntityTypeFunction, EntityTypeVariable, EntityTypeConstant}, TypeKinds()...)
+	entries, err := qe.collectEntriesByTypes(entityTypes)
+	if err != nil {
+		return nil, nil, err
+	}
+	sortBySourceOrder(entries)
+
+	file = filepath.Clean(file)
+	var outer *models.IndexEntry // Last sibling kept, whose nested declarations are skipped
+	for i := range entries {
+		entry := &entries[i].IndexEntry
+		if filepath.Clean(entry.File) != file || (outer != nil && encloses(outer, entry.StartLine)) {
+			continue
+		}
+		switch {
+		case max(entry.EndLine, entry.StartLine) < startLine:
+			before = append(before, entries[i])
+		case entry.StartLine > max(endLine, startLine):
+			after = append(after, entries[i])
+		default:
+			continue
+		}
+		outer = entry
+	}
+
+	before = before[max(len(before)-count, 0):]
+	after = after[:min(len(after), count)]
+	return before, after, nil
+}
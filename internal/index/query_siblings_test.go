@@ -0,0 +1,62 @@
+package index
+
+import (
+	"slices"
+	"testing"
+
+	"repository-context-protocol/internal/models"
+)
+
+func TestQueryEngine_FindSiblingDeclarations(t *testing.T) {
+	storage := newDiffTestStorage(t,
+		&models.FileContext{
+			Path: "user.go", Language: "go",
+			Types:     []models.TypeDef{{Name: "User", Kind: "struct", StartLine: 3, EndLine: 6}},
+			Constants: []models.Constant{{Name: "maxNameLength", StartLine: 8, EndLine: 8}},
+			Functions: []models.Function{
+				{Name: "NewUser", StartLine: 10, EndLine: 18},
+				{Name: "NewUser.func1", Parent: "NewUser", StartLine: 12, EndLine: 14},
+				{Name: "validateName", StartLine: 20, EndLine: 25},
+				{Name: "normalizeName", StartLine: 27, EndLine: 30},
+				{Name: "trimName", StartLine: 32, EndLine: 34},
+			},
+		},
+		&models.FileContext{
+			Path: "order.go", Language: "go",
+			Functions: []models.Function{{Name: "placeOrder", StartLine: 21, EndLine: 24}},
+		},
+	)
+	engine := NewQueryEngine(storage)
+
+	names := func(entries []SearchResultEntry) []string {
+		var result []string
+		for i := range entries {
+			result = append(result, entries[i].IndexEntry.Name)
+		}
+		return result
+	}
+
+	before, after, err := engine.FindSiblingDeclarations("user.go", 20, 25, 2)
+	if err != nil {
+		t.Fatalf("Failed to find siblings: %v", err)
+	}
+	if !slices.Equal(names(before), []string{"maxNameLength", "NewUser"}) {
+		t.Errorf("Expected the two declarations before, nearest last and without nested functions, got %v", names(before))
+	}
+	if !slices.Equal(names(after), []string{"normalizeName", "trimName"}) {
+		t.Errorf("Expected the two declarations after, nearest first, got %v", names(after))
+	}
+
+	// Declarations overlapping the target are not its siblings
+	before, after, err = engine.FindSiblingDeclarations("user.go", 12, 14, 1)
+	if err != nil {
+		t.Fatalf("Failed to find siblings: %v", err)
+	}
+	if !slices.Equal(names(before), []string{"maxNameLength"}) || !slices.Equal(names(after), []string{"validateName"}) {
+		t.Errorf("Expected maxNameLength and validateName around the closure, got %v and %v", names(before), names(after))
+	}
+
+	if _, _, err := engine.FindSiblingDeclarations("user.go", 20, 25, 0); err == nil {
+		t.Error("Expected an error for a count of zero")
+	}
+}
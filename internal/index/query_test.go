@@ -1,8 +1,13 @@
 package index
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"maps"
 	"os"
+	"slices"
+	"sort"
 	"strings"
 	"testing"
 	"time"
@@ -264,6 +269,152 @@ func TestQueryEngine_FormatResults(t *testing.T) {
 	}
 }
 
+func TestQueryEngine_FormatResultsMarkdown(t *testing.T) {
+	tempDir, storage := setupTestStorage(t)
+	defer os.RemoveAll(tempDir)
+
+	setupTestDataWithCallGraph(t, storage)
+
+	engine := NewQueryEngine(storage)
+
+	options := QueryOptions{IncludeCallers: true, IncludeCallees: true}
+	results, err := engine.SearchByNameWithOptions("MainFunction", options)
+	if err != nil {
+		t.Fatalf("Failed to search: %v", err)
+	}
+
+	for _, format := range []string{"markdown", "md"} {
+		output, err := engine.FormatResults(results, format)
+		if err != nil {
+			t.Fatalf("Failed to format as %s: %v", format, err)
+		}
+
+		markdown := string(output)
+		expectedParts := []string{
+			"## Query: `MainFunction` (type: name)",
+			"### 1. MainFunction (function)",
+			"```go\n",
+			"<details>",
+			"<summary>Call Graph</summary>",
+			"#### Callers",
+			"#### Callees",
+			"</details>",
+		}
+		for _, part := range expectedParts {
+			if !strings.Contains(markdown, part) {
+				t.Errorf("Expected %s output to contain %q, got:\n%s", format, part, markdown)
+			}
+		}
+	}
+
+	// Output must be deterministic for snapshot testing
+	first, _ := engine.FormatResults(results, "markdown")
+	second, _ := engine.FormatResults(results, "markdown")
+	if string(first) != string(second) {
+		t.Error("Expected markdown output to be deterministic")
+	}
+}
+
+func TestQueryEngine_FormatResultsVerbose(t *testing.T) {
+	engine := NewQueryEngine(newDiffTestStorage(t, &models.FileContext{
+		Path: "server/server.go", Language: "go",
+		Functions: []models.Function{{
+			Name: "NewServer", Signature: "func NewServer(addr string, opts ...Option) (*Server, error)",
+			StartLine: 10, EndLine: 20,
+			Doc:        "NewServer creates a server.\nIt is not started.",
+			Parameters: []models.Parameter{{Name: "addr", Type: "string"}, {Name: "opts", Type: "...Option"}},
+			Returns:    []models.Type{{Name: "*Server"}, {Name: "error"}},
+		}},
+	}))
+
+	compact, err := engine.SearchByName("NewServer")
+	if err != nil {
+		t.Fatalf("Failed to search: %v", err)
+	}
+	verbose, err := engine.SearchByNameWithOptions("NewServer", QueryOptions{Verbose: true})
+	if err != nil {
+		t.Fatalf("Failed to search: %v", err)
+	}
+
+	expected := map[string][]string{
+		"text": {
+			"   Doc:\n     NewServer creates a server.\n     It is not started.\n",
+			"   Parameters:\n     - addr string\n     - opts ...Option\n",
+			"   Returns:\n     - *Server\n     - error\n",
+		},
+		"markdown": {
+			"NewServer creates a server.\nIt is not started.\n\n",
+			"| Parameter | Type |\n| --- | --- |\n| `addr` | `string` |\n| `opts` | `...Option` |\n",
+			"Returns:\n\n- `*Server`\n- `error`\n",
+		},
+	}
+	for format, parts := range expected {
+		output, err := engine.FormatResults(verbose, format)
+		if err != nil {
+			t.Fatalf("Failed to format as %s: %v", format, err)
+		}
+		for _, part := range parts {
+			if !strings.Contains(string(output), part) {
+				t.Errorf("Expected verbose %s output to contain %q, got:\n%s", format, part, output)
+			}
+		}
+
+		// The compact format stays the default
+		output, err = engine.FormatResults(compact, format)
+		if err != nil {
+			t.Fatalf("Failed to format as %s: %v", format, err)
+		}
+		if strings.Contains(string(output), "Parameter") {
+			t.Errorf("Expected compact %s output without parameters, got:\n%s", format, output)
+		}
+	}
+}
+
+func TestQueryEngine_FormatResultsJSONLines(t *testing.T) {
+	tempDir, storage := setupTestStorage(t)
+	defer os.RemoveAll(tempDir)
+
+	setupTestData(t, storage)
+
+	engine := NewQueryEngine(storage)
+
+	results, err := engine.SearchByType("function")
+	if err != nil {
+		t.Fatalf("Failed to search: %v", err)
+	}
+
+	output, err := engine.FormatResults(results, "jsonl")
+	if err != nil {
+		t.Fatalf("Failed to format as jsonl: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(string(output), "\n"), "\n")
+	if len(lines) != len(results.Entries)+1 {
+		t.Fatalf("Expected %d lines, got %d", len(results.Entries)+1, len(lines))
+	}
+
+	for i, line := range lines[:len(lines)-1] {
+		var entry JSONLinesEntry
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("Failed to parse entry line %d: %v", i+1, err)
+		}
+		if entry.Kind != JSONLinesKindEntry {
+			t.Errorf("Expected entry kind, got %q", entry.Kind)
+		}
+		if entry.IndexEntry.Name != results.Entries[i].IndexEntry.Name {
+			t.Errorf("Expected entry %s, got %s", results.Entries[i].IndexEntry.Name, entry.IndexEntry.Name)
+		}
+	}
+
+	var summary JSONLinesSummary
+	if err := json.Unmarshal([]byte(lines[len(lines)-1]), &summary); err != nil {
+		t.Fatalf("Failed to parse summary line: %v", err)
+	}
+	if summary.Kind != JSONLinesKindSummary || summary.Count != len(results.Entries) || summary.SearchType != "type" {
+		t.Errorf("Unexpected summary: %+v", summary)
+	}
+}
+
 func TestQueryEngine_EstimateTokens(t *testing.T) {
 	tempDir, storage := setupTestStorage(t)
 	defer os.RemoveAll(tempDir)
@@ -375,6 +526,43 @@ func TestQueryEngine_SearchInFileWithTokenLimit(t *testing.T) {
 	validateTokenLimits(t, engine, results, options.MaxTokens)
 }
 
+func TestQueryEngine_SearchInFileOrderBySource(t *testing.T) {
+	storage := newDiffTestStorage(t, &models.FileContext{
+		Path: "/repo/user.go", Language: "go",
+		Functions: []models.Function{
+			{Name: "NewUser", StartLine: 10, EndLine: 12},
+			{Name: "Save", StartLine: 20, EndLine: 24},
+		},
+		Types:     []models.TypeDef{{Name: "User", Kind: "struct", StartLine: 5, EndLine: 8}},
+		Variables: []models.Variable{{Name: "users", StartLine: 15, EndLine: 15}},
+		Constants: []models.Constant{{Name: "MaxUsers", StartLine: 1, EndLine: 1}},
+	})
+	engine := NewQueryEngine(storage)
+
+	names := func(options QueryOptions) []string {
+		t.Helper()
+		result, err := engine.SearchInFileWithOptions("/repo/user.go", options)
+		if err != nil {
+			t.Fatalf("Failed to search in file: %v", err)
+		}
+		var names []string
+		for _, entry := range result.Entries {
+			names = append(names, entry.IndexEntry.Name)
+		}
+		return names
+	}
+
+	// Grouped by kind by default
+	grouped := names(QueryOptions{IncludeTypes: true})
+	if !slices.Equal(grouped, []string{"NewUser", "Save", "users", "MaxUsers", "User"}) {
+		t.Errorf("Expected entries grouped by kind, got %v", grouped)
+	}
+	ordered := names(QueryOptions{IncludeTypes: true, OrderBySource: true})
+	if !slices.Equal(ordered, []string{"MaxUsers", "User", "NewUser", "users", "Save"}) {
+		t.Errorf("Expected entries in declaration order, got %v", ordered)
+	}
+}
+
 func TestQueryEngine_SearchInFileWithIncludeTypes(t *testing.T) {
 	tempDir, storage := setupTestStorage(t)
 	defer os.RemoveAll(tempDir)
@@ -745,6 +933,59 @@ func TestQueryEngine_SearchByTypeWithTokenLimit(t *testing.T) {
 	validateTokenLimits(t, engine, results, options.MaxTokens)
 }
 
+func TestQueryEngine_SearchByTypesWithOptions(t *testing.T) {
+	tempDir, storage := setupTestStorage(t)
+	defer os.RemoveAll(tempDir)
+
+	setupTestData(t, storage)
+
+	engine := NewQueryEngine(storage)
+
+	// Repeated types must not produce duplicate entries
+	entityTypes := []string{"function", "struct", "variable", "function"}
+	results, err := engine.SearchByTypesWithOptions(entityTypes, QueryOptions{})
+	if err != nil {
+		t.Fatalf("Failed to search by types: %v", err)
+	}
+
+	if results.SearchType != "types" {
+		t.Errorf("Expected search type 'types', got %s", results.SearchType)
+	}
+	if results.Query != "function,struct,variable,function" {
+		t.Errorf("Expected comma-joined query, got %s", results.Query)
+	}
+
+	counts := make(map[string]int)
+	for _, entry := range results.Entries {
+		counts[entry.IndexEntry.Type]++
+	}
+	if counts["function"] != 2 || counts["struct"] != 1 || counts["variable"] != 1 {
+		t.Errorf("Unexpected entry counts by type: %v", counts)
+	}
+	if len(results.Entries) != 4 {
+		t.Errorf("Expected 4 deduplicated entries, got %d", len(results.Entries))
+	}
+
+	// A single token budget is applied across the merged set
+	limited, err := engine.SearchByTypesWithOptions(entityTypes, QueryOptions{MaxTokens: 30})
+	if err != nil {
+		t.Fatalf("Failed to search by types with token limit: %v", err)
+	}
+	validateTokenLimits(t, engine, limited, 30)
+	if !limited.Truncated {
+		t.Error("Expected merged results to be truncated by the shared token budget")
+	}
+
+	// The single-type method keeps its own search type
+	single, err := engine.SearchByTypeWithOptions("function", QueryOptions{})
+	if err != nil {
+		t.Fatalf("Failed to search by type: %v", err)
+	}
+	if single.SearchType != "type" || single.Query != "function" {
+		t.Errorf("Expected single-type search labelled 'type'/'function', got %s/%s", single.SearchType, single.Query)
+	}
+}
+
 func TestQueryEngine_IncludeTypesFlag(t *testing.T) {
 	tempDir, storage := setupTestStorage(t)
 	defer os.RemoveAll(tempDir)
@@ -1038,6 +1279,283 @@ func TestQueryEngine_CallGraphMaxDepthActuallyUsed(t *testing.T) {
 	t.Logf("Depth 3: %d callees", len(result3.CallGraph.Callees))
 }
 
+func TestQueryEngine_GetCallGraphWithContext_Cancelled(t *testing.T) {
+	tempDir, storage := setupTestStorage(t)
+	defer os.RemoveAll(tempDir)
+
+	setupTestDataWithDeepCallChain(t, storage)
+
+	engine := NewQueryEngine(storage)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	callGraph, err := engine.GetCallGraphWithContext(ctx, "FuncA", QueryOptions{
+		IncludeCallees: true,
+		MaxDepth:       3,
+	})
+	if err != nil {
+		t.Fatalf("Expected partial call graph, got error: %v", err)
+	}
+
+	if !callGraph.TimedOut {
+		t.Error("Expected call graph to be marked as timed out")
+	}
+	if len(callGraph.Callees) != 0 {
+		t.Errorf("Expected no callees after cancellation, got %d", len(callGraph.Callees))
+	}
+}
+
+func TestQueryEngine_GetCallGraphMaxNodes(t *testing.T) {
+	tempDir, storage := setupTestStorage(t)
+	defer os.RemoveAll(tempDir)
+
+	// FuncA -> FuncB -> FuncC -> FuncD
+	setupTestDataWithDeepCallChain(t, storage)
+
+	engine := NewQueryEngine(storage)
+
+	callGraph, err := engine.GetCallGraphWithOptions("FuncA", QueryOptions{IncludeCallees: true, MaxDepth: 3, MaxNodes: 2})
+	if err != nil {
+		t.Fatalf("Failed to get call graph: %v", err)
+	}
+	if !callGraph.Truncated {
+		t.Error("Expected call graph capped by MaxNodes to be truncated")
+	}
+	assertCallGraphDepths(t, callGraph.Callees, map[string]int{"FuncB": 1, "FuncC": 2})
+
+	// The budget is shared by callers and callees
+	callGraph, err = engine.GetCallGraphWithOptions("FuncB", QueryOptions{
+		IncludeCallers: true, IncludeCallees: true, MaxDepth: 3, MaxNodes: 1,
+	})
+	if err != nil {
+		t.Fatalf("Failed to get call graph: %v", err)
+	}
+	if len(callGraph.Callers) != 1 || len(callGraph.Callees) != 0 || !callGraph.Truncated {
+		t.Errorf("Expected one caller and no callees within a budget of 1, got %+v", callGraph)
+	}
+
+	// A budget that covers the whole graph does not truncate
+	callGraph, err = engine.GetCallGraphWithOptions("FuncA", QueryOptions{IncludeCallees: true, MaxDepth: 3, MaxNodes: 3})
+	if err != nil {
+		t.Fatalf("Failed to get call graph: %v", err)
+	}
+	if callGraph.Truncated || len(callGraph.Callees) != 3 {
+		t.Errorf("Expected all 3 callees without truncation, got %+v", callGraph)
+	}
+
+	result, err := engine.SearchByNameWithOptions("FuncA", QueryOptions{IncludeCallees: true, MaxDepth: 3, MaxNodes: 1})
+	if err != nil {
+		t.Fatalf("Failed to search: %v", err)
+	}
+	if !result.Truncated {
+		t.Error("Expected search result to be marked truncated when its call graph is capped")
+	}
+}
+
+func TestQueryEngine_SearchByNameWithContext(t *testing.T) {
+	tempDir, storage := setupTestStorage(t)
+	defer os.RemoveAll(tempDir)
+
+	setupTestDataWithDeepCallChain(t, storage)
+
+	engine := NewQueryEngine(storage)
+	options := QueryOptions{IncludeCallees: true, MaxDepth: 3}
+
+	result, err := engine.SearchByNameWithContext(context.Background(), "FuncA", options)
+	if err != nil {
+		t.Fatalf("Failed to search with context: %v", err)
+	}
+	if result.TimedOut {
+		t.Error("Expected search without deadline not to time out")
+	}
+	if result.CallGraph == nil || len(result.CallGraph.Callees) < 3 {
+		t.Error("Expected full call graph for uncancelled search")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := engine.SearchByNameWithContext(ctx, "FuncA", options); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled for cancelled search, got %v", err)
+	}
+}
+
+func TestQueryEngine_GetCallGraphWithOptions_DirectionalDepths(t *testing.T) {
+	tempDir, storage := setupTestStorage(t)
+	defer os.RemoveAll(tempDir)
+
+	// FuncA -> FuncB -> FuncC -> FuncD
+	setupTestDataWithDeepCallChain(t, storage)
+
+	engine := NewQueryEngine(storage)
+
+	callGraph, err := engine.GetCallGraphWithOptions("FuncC", QueryOptions{
+		IncludeCallers: true,
+		IncludeCallees: true,
+		MaxDepth:       1,
+		CallerDepth:    2,
+	})
+	if err != nil {
+		t.Fatalf("Failed to get call graph: %v", err)
+	}
+
+	// Callers use their own depth, callees fall back to MaxDepth
+	if len(callGraph.Callers) != 2 {
+		t.Errorf("Expected 2 callers with caller depth 2, got %d", len(callGraph.Callers))
+	}
+	if len(callGraph.Callees) != 1 {
+		t.Errorf("Expected 1 callee with max depth 1, got %d", len(callGraph.Callees))
+	}
+
+	callGraph, err = engine.GetCallGraphWithOptions("FuncB", QueryOptions{
+		IncludeCallers: true,
+		IncludeCallees: true,
+		CallerDepth:    1,
+		CalleeDepth:    2,
+	})
+	if err != nil {
+		t.Fatalf("Failed to get call graph: %v", err)
+	}
+
+	if len(callGraph.Callers) != 1 || callGraph.Callers[0].Function != "FuncA" {
+		t.Errorf("Expected only FuncA as caller, got %+v", callGraph.Callers)
+	}
+	if len(callGraph.Callees) != 2 {
+		t.Errorf("Expected FuncC and FuncD as callees with callee depth 2, got %+v", callGraph.Callees)
+	}
+}
+
+func TestQueryEngine_GetCallGraphDeduplicatesDiamond(t *testing.T) {
+	tempDir, storage := setupTestStorage(t)
+	defer os.RemoveAll(tempDir)
+
+	// Diamond with a shortcut: Top -> Left -> Bottom, Top -> Right -> Bottom, Top -> Bottom
+	fileContext := &models.FileContext{
+		Path:     "diamond.go",
+		Language: "go",
+		Functions: []models.Function{
+			{Name: "Top", Signature: "func Top()", StartLine: 1, EndLine: 5, Calls: []string{"Left", "Right", "Bottom"}},
+			{Name: "Left", Signature: "func Left()", StartLine: 7, EndLine: 9, Calls: []string{"Bottom"}},
+			{Name: "Right", Signature: "func Right()", StartLine: 11, EndLine: 13, Calls: []string{"Bottom"}},
+			{Name: "Bottom", Signature: "func Bottom()", StartLine: 15, EndLine: 16},
+		},
+	}
+	if err := storage.StoreFileContext(fileContext); err != nil {
+		t.Fatalf("Failed to store diamond test data: %v", err)
+	}
+
+	engine := NewQueryEngine(storage)
+
+	callGraph, err := engine.GetCallGraphWithOptions("Top", QueryOptions{IncludeCallees: true, MaxDepth: 3})
+	if err != nil {
+		t.Fatalf("Failed to get call graph: %v", err)
+	}
+	assertCallGraphDepths(t, callGraph.Callees, map[string]int{"Left": 1, "Right": 1, "Bottom": 1})
+
+	callGraph, err = engine.GetCallGraphWithOptions("Bottom", QueryOptions{IncludeCallers: true, MaxDepth: 3})
+	if err != nil {
+		t.Fatalf("Failed to get call graph: %v", err)
+	}
+	assertCallGraphDepths(t, callGraph.Callers, map[string]int{"Left": 1, "Right": 1, "Top": 1})
+}
+
+// assertCallGraphDepths checks that entries name each expected function exactly once at the given depth
+func assertCallGraphDepths(t *testing.T, entries []CallGraphEntry, expected map[string]int) {
+	t.Helper()
+
+	if len(entries) != len(expected) {
+		t.Errorf("Expected %d unique entries, got %d: %+v", len(expected), len(entries), entries)
+	}
+	for _, entry := range entries {
+		depth, ok := expected[entry.Function]
+		if !ok {
+			t.Errorf("Unexpected entry %s", entry.Function)
+			continue
+		}
+		if entry.Depth != depth {
+			t.Errorf("Expected %s at depth %d, got %d", entry.Function, depth, entry.Depth)
+		}
+	}
+}
+
+func TestQueryEngine_GetCallGraphReportsCycles(t *testing.T) {
+	tempDir, storage := setupTestStorage(t)
+	defer os.RemoveAll(tempDir)
+
+	// Scheduler -> Dispatch -> Scheduler, plus a self-recursive Retry
+	fileContext := &models.FileContext{
+		Path:     "scheduler.go",
+		Language: "go",
+		Checksum: "cycle123",
+		ModTime:  time.Now(),
+		Functions: []models.Function{
+			{Name: "Scheduler", Signature: "func Scheduler()", StartLine: 10, EndLine: 15, Calls: []string{"Dispatch"}},
+			{Name: "Dispatch", Signature: "func Dispatch()", StartLine: 20, EndLine: 25, Calls: []string{"Scheduler", "Retry"}},
+			{Name: "Retry", Signature: "func Retry()", StartLine: 30, EndLine: 35, Calls: []string{"Retry"}},
+		},
+	}
+	if err := storage.StoreFileContext(fileContext); err != nil {
+		t.Fatalf("Failed to store cyclic call graph: %v", err)
+	}
+
+	engine := NewQueryEngine(storage)
+
+	// Depth 2 is enough to see Scheduler -> Dispatch -> Scheduler
+	callGraph, err := engine.GetCallGraphWithOptions("Scheduler", QueryOptions{
+		IncludeCallers: true,
+		IncludeCallees: true,
+		MaxDepth:       2,
+	})
+	if err != nil {
+		t.Fatalf("Failed to get call graph: %v", err)
+	}
+
+	// The same loop is found from both directions but reported once
+	if len(callGraph.Cycles) != 1 {
+		t.Fatalf("Expected 1 cycle at depth 2, got %d: %v", len(callGraph.Cycles), callGraph.Cycles)
+	}
+	expected := []string{"Scheduler", "Dispatch", "Scheduler"}
+	if strings.Join(callGraph.Cycles[0], ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected cycle %v, got %v", expected, callGraph.Cycles[0])
+	}
+
+	// Depth 3 also reaches the self-recursive Retry
+	callGraph, err = engine.GetCallGraphWithOptions("Scheduler", QueryOptions{IncludeCallees: true, MaxDepth: 3})
+	if err != nil {
+		t.Fatalf("Failed to get call graph: %v", err)
+	}
+	if len(callGraph.Cycles) != 2 {
+		t.Fatalf("Expected 2 cycles at depth 3, got %d: %v", len(callGraph.Cycles), callGraph.Cycles)
+	}
+	if strings.Join(callGraph.Cycles[1], ",") != "Retry,Retry" {
+		t.Errorf("Expected self-recursive cycle for Retry, got %v", callGraph.Cycles[1])
+	}
+
+	// Depth 1 never revisits a function, so no cycles are reported
+	callGraph, err = engine.GetCallGraphWithOptions("Scheduler", QueryOptions{IncludeCallees: true, MaxDepth: 1})
+	if err != nil {
+		t.Fatalf("Failed to get call graph: %v", err)
+	}
+	if len(callGraph.Cycles) != 0 {
+		t.Errorf("Expected no cycles at depth 1, got %v", callGraph.Cycles)
+	}
+
+	// Cycles appear in the text output
+	result := &SearchResult{
+		Query:      "Scheduler",
+		SearchType: "name",
+		CallGraph:  &CallGraphInfo{Function: "Scheduler", Cycles: [][]string{expected}},
+		Options:    &QueryOptions{},
+	}
+	output, err := engine.FormatResults(result, "text")
+	if err != nil {
+		t.Fatalf("Failed to format results: %v", err)
+	}
+	if !strings.Contains(string(output), "Scheduler -> Dispatch -> Scheduler") {
+		t.Errorf("Expected cycle in text output, got:\n%s", output)
+	}
+}
+
 // Helper functions for test setup
 
 func setupTestStorage(t *testing.T) (string, *HybridStorage) {
@@ -1280,3 +1798,260 @@ func matchesPattern(name, pattern string) bool {
 	}
 	return name == pattern
 }
+
+func TestQueryEngine_FacetsCountBeforeTruncation(t *testing.T) {
+	tempDir, storage := setupTestStorage(t)
+	defer os.RemoveAll(tempDir)
+
+	fileContext := &models.FileContext{
+		Path:     "items.go",
+		Language: "go",
+		Functions: []models.Function{
+			{Name: "ItemCreate", Signature: "func ItemCreate()", StartLine: 1, EndLine: 2},
+			{Name: "ItemDelete", Signature: "func ItemDelete()", StartLine: 3, EndLine: 4},
+			{Name: "ItemUpdate", Signature: "func ItemUpdate()", StartLine: 5, EndLine: 6},
+		},
+		Types:     []models.TypeDef{{Name: "ItemStore", Kind: "struct", StartLine: 8, EndLine: 10}},
+		Variables: []models.Variable{{Name: "ItemLimit", Type: "int", StartLine: 12, EndLine: 12}},
+	}
+	if err := storage.StoreFileContext(fileContext); err != nil {
+		t.Fatalf("Failed to store file context: %v", err)
+	}
+
+	engine := NewQueryEngine(storage)
+
+	results, err := engine.SearchByPatternWithOptions("Item*", QueryOptions{IncludeTypes: true, MaxTokens: 30})
+	if err != nil {
+		t.Fatalf("Failed to search by pattern: %v", err)
+	}
+
+	if !results.Truncated || len(results.Entries) >= 5 {
+		t.Fatalf("Expected truncated results, got %d entries", len(results.Entries))
+	}
+
+	expected := map[string]int{"function": 3, "struct": 1, "variable": 1}
+	if !maps.Equal(results.Facets, expected) {
+		t.Errorf("Expected facets %v, got %v", expected, results.Facets)
+	}
+
+	textOutput, err := engine.FormatResults(results, "text")
+	if err != nil {
+		t.Fatalf("Failed to format as text: %v", err)
+	}
+	if !strings.Contains(string(textOutput), "Facets: function=3, struct=1, variable=1") {
+		t.Errorf("Expected text output to include facets, got:\n%s", textOutput)
+	}
+
+	jsonOutput, err := engine.FormatResults(results, "json")
+	if err != nil {
+		t.Fatalf("Failed to format as JSON: %v", err)
+	}
+	var decoded SearchResult
+	if err := json.Unmarshal(jsonOutput, &decoded); err != nil {
+		t.Fatalf("Failed to decode JSON output: %v", err)
+	}
+	if !maps.Equal(decoded.Facets, expected) {
+		t.Errorf("Expected JSON facets %v, got %v", expected, decoded.Facets)
+	}
+}
+
+func TestQueryEngine_LanguageFilter(t *testing.T) {
+	storage := newDiffTestStorage(t,
+		&models.FileContext{
+			Path: "handlers.go", Language: "go",
+			Functions: []models.Function{{Name: "handle", Signature: "func handle()", StartLine: 1, EndLine: 2}},
+			Types:     []models.TypeDef{{Name: "Handler", Kind: "struct", StartLine: 4, EndLine: 6}},
+		},
+		&models.FileContext{
+			Path: "handlers.py", Language: "python",
+			Functions: []models.Function{{Name: "handle", Signature: "def handle()", StartLine: 1, EndLine: 2}},
+			Types:     []models.TypeDef{{Name: "Handler", Kind: "class", StartLine: 4, EndLine: 6}},
+		},
+	)
+	engine := NewQueryEngine(storage)
+
+	result, err := engine.SearchByNameWithOptions("handle", QueryOptions{Language: "Python"})
+	if err != nil {
+		t.Fatalf("Failed to search by name: %v", err)
+	}
+	if len(result.Entries) != 1 || result.Entries[0].IndexEntry.File != "handlers.py" {
+		t.Errorf("Expected only the python handle, got %+v", result.Entries)
+	}
+	if result.Entries[0].IndexEntry.Language != "python" {
+		t.Errorf("Expected index entry to record its language, got %q", result.Entries[0].IndexEntry.Language)
+	}
+
+	result, err = engine.SearchByPatternWithOptions("Handl*", QueryOptions{IncludeTypes: true, Language: "go"})
+	if err != nil {
+		t.Fatalf("Failed to search by pattern: %v", err)
+	}
+	if len(result.Entries) != 1 || result.Entries[0].IndexEntry.Type != "struct" {
+		t.Errorf("Expected only the go struct, got %+v", result.Entries)
+	}
+
+	result, err = engine.SearchByTypeWithOptions(EntityTypeFunction, QueryOptions{Language: "java"})
+	if err != nil {
+		t.Fatalf("Failed to search by type: %v", err)
+	}
+	if len(result.Entries) != 0 {
+		t.Errorf("Expected no java functions, got %+v", result.Entries)
+	}
+}
+
+func TestEntryLanguage_FallsBackToChunkData(t *testing.T) {
+	entry := &SearchResultEntry{
+		IndexEntry: models.IndexEntry{Name: "handle", File: "app/views.py"},
+		ChunkData: &models.SemanticChunk{
+			FileData: []models.FileContext{
+				{Path: "app/models.go", Language: "go"},
+				{Path: "app/views.py", Language: "python"},
+			},
+		},
+	}
+	if language := EntryLanguage(entry); language != "python" {
+		t.Errorf("Expected language from chunk data, got %q", language)
+	}
+}
+
+func TestQueryEngine_ScopeFilter(t *testing.T) {
+	storage := newDiffTestStorage(t,
+		&models.FileContext{
+			Path: "config.go", Language: "go",
+			Functions: []models.Function{{Name: "load", Signature: "func load()", StartLine: 5, EndLine: 9}},
+			Variables: []models.Variable{
+				{Name: "config", Type: "Config", StartLine: 3, EndLine: 3, Scope: models.ScopePackage},
+				{Name: "config", Type: "Config", StartLine: 6, EndLine: 6, Scope: models.ScopeFunction},
+			},
+			Constants: []models.Constant{{Name: "retries", Type: "int", StartLine: 7, EndLine: 7, Scope: models.ScopeFunction}},
+		},
+	)
+	engine := NewQueryEngine(storage)
+
+	result, err := engine.SearchByTypeWithOptions("variable", QueryOptions{Scope: models.ScopePackage})
+	if err != nil {
+		t.Fatalf("Failed to search by type: %v", err)
+	}
+	if len(result.Entries) != 1 || result.Entries[0].IndexEntry.StartLine != 3 {
+		t.Errorf("Expected only the package-level config, got %+v", result.Entries)
+	}
+
+	// Functions have no scope and are kept
+	result, err = engine.SearchByPatternWithOptions("*", QueryOptions{Scope: models.ScopeFunction})
+	if err != nil {
+		t.Fatalf("Failed to search by pattern: %v", err)
+	}
+	names := make(map[string]int)
+	for _, entry := range result.Entries {
+		names[entry.IndexEntry.Name]++
+	}
+	if len(result.Entries) != 3 || names["load"] != 1 || names["config"] != 1 || names["retries"] != 1 {
+		t.Errorf("Expected load plus the function-scoped config and retries, got %+v", result.Entries)
+	}
+}
+
+func TestQueryEngine_ExportedOnlyFilter(t *testing.T) {
+	storage := newDiffTestStorage(t,
+		&models.FileContext{
+			Path: "users.go", Language: "go",
+			Functions: []models.Function{
+				{Name: "NewUser", Signature: "func NewUser() *User", StartLine: 5, EndLine: 7},
+				{Name: "newID", Signature: "func newID() int", StartLine: 9, EndLine: 11},
+			},
+			Types: []models.TypeDef{
+				{Name: "User", Kind: "struct", StartLine: 1, EndLine: 3},
+				{Name: "userCache", Kind: "struct", StartLine: 13, EndLine: 15},
+			},
+			Exports: []models.Export{
+				{Name: "NewUser", Type: "func NewUser() *User", Kind: "function"},
+				{Name: "User", Type: "struct", Kind: "type"},
+			},
+		},
+		&models.FileContext{
+			Path: "users.py", Language: "python",
+			Functions: []models.Function{
+				{Name: "new_user", Signature: "def new_user()", StartLine: 1, EndLine: 2},
+				{Name: "_new_id", Signature: "def _new_id()", StartLine: 4, EndLine: 5},
+			},
+			Exports: []models.Export{{Name: "new_user", Type: "function", Kind: "function"}},
+		},
+	)
+	engine := NewQueryEngine(storage)
+
+	entryNames := func(result *SearchResult) []string {
+		var names []string
+		for _, entry := range result.Entries {
+			names = append(names, entry.IndexEntry.Name)
+		}
+		sort.Strings(names)
+		return names
+	}
+
+	result, err := engine.SearchByPatternWithOptions("*", QueryOptions{IncludeTypes: true, ExportedOnly: true})
+	if err != nil {
+		t.Fatalf("Failed to search by pattern: %v", err)
+	}
+	if names := entryNames(result); !slices.Equal(names, []string{"NewUser", "User", "new_user"}) {
+		t.Errorf("Expected only exported symbols, got %v", names)
+	}
+
+	result, err = engine.SearchByTypeWithOptions(EntityTypeFunction, QueryOptions{ExportedOnly: true, Language: "python"})
+	if err != nil {
+		t.Fatalf("Failed to search by type: %v", err)
+	}
+	if names := entryNames(result); !slices.Equal(names, []string{"new_user"}) {
+		t.Errorf("Expected only the exported python function, got %v", names)
+	}
+
+	result, err = engine.SearchByNameWithOptions("newID", QueryOptions{ExportedOnly: true})
+	if err != nil {
+		t.Fatalf("Failed to search by name: %v", err)
+	}
+	if len(result.Entries) != 0 {
+		t.Errorf("Expected unexported newID to be filtered out, got %+v", result.Entries)
+	}
+
+	// Without the option private symbols are still returned
+	result, err = engine.SearchByNameWithOptions("newID", QueryOptions{})
+	if err != nil {
+		t.Fatalf("Failed to search by name: %v", err)
+	}
+	if len(result.Entries) != 1 {
+		t.Errorf("Expected newID without exported_only, got %+v", result.Entries)
+	}
+}
+
+func TestQueryEngine_StoredEntryTokenCounts(t *testing.T) {
+	storage := newDiffTestStorage(t, &models.FileContext{
+		Path: "user.go", Language: "go",
+		Functions: []models.Function{{Name: "NewUser", Signature: "func NewUser(name string, age int) *User", StartLine: 10}},
+		Types:     []models.TypeDef{{Name: "User", Kind: "struct", StartLine: 1}},
+	})
+	engine := NewQueryEngine(storage)
+
+	entries, err := engine.collectEntriesByTypes(append([]string{EntityTypeFunction}, TypeKinds()...))
+	if err != nil {
+		t.Fatalf("Failed to collect entries: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("Expected the function and type entries, got %d", len(entries))
+	}
+
+	live := func(engine *QueryEngine, entry SearchResultEntry) int {
+		entry.IndexEntry.TokenCount = 0
+		return engine.estimateEntryTokens(&entry)
+	}
+	bpeEngine := NewQueryEngine(storage)
+	bpeEngine.SetTokenEstimator(&BPEEstimator{})
+	for _, entry := range entries {
+		if entry.IndexEntry.TokenCount == 0 {
+			t.Errorf("Expected a stored token count for %s", entry.IndexEntry.Name)
+		}
+		if stored, expected := engine.estimateEntryTokens(&entry), live(engine, entry); stored != expected {
+			t.Errorf("Expected the stored count of %s to match the live estimate %d, got %d", entry.IndexEntry.Name, expected, stored)
+		}
+		// Other estimators do not use counts stored with the default one
+		if estimated, expected := bpeEngine.estimateEntryTokens(&entry), live(bpeEngine, entry); estimated != expected {
+			t.Errorf("Expected the BPE estimate %d for %s, got %d", expected, entry.IndexEntry.Name, estimated)
+		}
+	}
+}
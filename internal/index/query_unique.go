@@ -0,0 +1,66 @@
+package index
+
+import (
+	"cmp"
+	"context"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// UniqueFunctionOptions narrows the functions FindUniqueFunction considers
+type UniqueFunctionOptions struct {
+	File string // Only functions declared in this file, relative to the repository root or as indexed; all when empty
+}
+
+// FunctionMatches is the outcome of FindUniqueFunction
+type FunctionMatches struct {
+	Function *SearchResultEntry  // The match when exactly one function matches, nil otherwise
+	Matches  []SearchResultEntry // Every matching function, by file and line
+}
+
+// Ambiguous reports whether several functions match, so none was picked
+func (m *FunctionMatches) Ambiguous() bool {
+	return len(m.Matches) > 1
+}
+
+// FindUniqueFunction returns the functions named name, resolving to Function when exactly
+// one matches. Names shared across files or types are not guessed between: callers list
+// Matches for disambiguation instead, and narrow the search with options.File or a
+// qualified name such as "User.Save" or "models.NewUser".
+func (qe *QueryEngine) FindUniqueFunction(name string, options UniqueFunctionOptions) (*FunctionMatches, error) {
+	result, err := qe.searchByName(context.Background(), name, QueryOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	member := name
+	if _, qualifiedMember, ok := splitQualifiedName(name); ok {
+		member = qualifiedMember
+	}
+	matches := &FunctionMatches{Matches: []SearchResultEntry{}}
+	for i := range result.Entries {
+		entry := &result.Entries[i].IndexEntry
+		if entry.Type == EntityTypeFunction && (entry.Name == name || entry.Name == member) && inFile(entry.File, options.File) {
+			matches.Matches = append(matches.Matches, result.Entries[i])
+		}
+	}
+	slices.SortStableFunc(matches.Matches, func(a, b SearchResultEntry) int {
+		return cmp.Or(strings.Compare(a.IndexEntry.File, b.IndexEntry.File), cmp.Compare(a.IndexEntry.StartLine, b.IndexEntry.StartLine))
+	})
+
+	if len(matches.Matches) == 1 {
+		matches.Function = &matches.Matches[0]
+	}
+	return matches, nil
+}
+
+// inFile reports whether file is the wanted file. The file may be given relative to the
+// repository root while files are indexed with absolute paths.
+func inFile(file, wanted string) bool {
+	if wanted == "" {
+		return true
+	}
+	file, wanted = filepath.Clean(file), filepath.Clean(wanted)
+	return file == wanted || strings.HasSuffix(file, string(filepath.Separator)+wanted)
+}
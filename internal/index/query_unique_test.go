@@ -0,0 +1,66 @@
+package index
+
+import (
+	"testing"
+
+	"repository-context-protocol/internal/models"
+)
+
+func TestQueryEngine_FindUniqueFunction(t *testing.T) {
+	storage := newDiffTestStorage(t,
+		&models.FileContext{
+			Path: "models/user.go", Language: "go",
+			Types: []models.TypeDef{{Name: "User", Kind: "struct", StartLine: 1, EndLine: 4}},
+			Functions: []models.Function{
+				{Name: "Save", Receiver: "User", StartLine: 6, EndLine: 8},
+				{Name: "NewUser", StartLine: 10, EndLine: 12},
+			},
+		},
+		&models.FileContext{
+			Path: "models/order.go", Language: "go",
+			Types:     []models.TypeDef{{Name: "Order", Kind: "struct", StartLine: 1, EndLine: 4}},
+			Functions: []models.Function{{Name: "Save", Receiver: "Order", StartLine: 6, EndLine: 8}},
+		},
+	)
+	engine := NewQueryEngine(storage)
+
+	tests := []struct {
+		name         string
+		query        string
+		options      UniqueFunctionOptions
+		expectedFile string // File of the resolved function, empty when none resolves
+		matches      int
+	}{
+		{"unique name resolves", "NewUser", UniqueFunctionOptions{}, "models/user.go", 1},
+		{"shared name is ambiguous", "Save", UniqueFunctionOptions{}, "", 2},
+		{"file narrows", "Save", UniqueFunctionOptions{File: "models/order.go"}, "models/order.go", 1},
+		{"qualified name narrows", "User.Save", UniqueFunctionOptions{}, "models/user.go", 1},
+		{"unknown name", "Delete", UniqueFunctionOptions{}, "", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matches, err := engine.FindUniqueFunction(tt.query, tt.options)
+			if err != nil {
+				t.Fatalf("Failed to find function: %v", err)
+			}
+			if len(matches.Matches) != tt.matches || matches.Ambiguous() != (tt.matches > 1) {
+				t.Errorf("Expected %d matches, got %+v", tt.matches, matches.Matches)
+			}
+			switch {
+			case tt.expectedFile == "" && matches.Function != nil:
+				t.Errorf("Expected no function to resolve, got %+v", matches.Function.IndexEntry)
+			case tt.expectedFile != "" && (matches.Function == nil || matches.Function.IndexEntry.File != tt.expectedFile):
+				t.Errorf("Expected the function in %s, got %+v", tt.expectedFile, matches.Function)
+			}
+		})
+	}
+
+	// Matches are listed by file and line
+	matches, err := engine.FindUniqueFunction("Save", UniqueFunctionOptions{})
+	if err != nil {
+		t.Fatalf("Failed to find function: %v", err)
+	}
+	if matches.Matches[0].IndexEntry.File != "models/order.go" || matches.Matches[1].IndexEntry.File != "models/user.go" {
+		t.Errorf("Expected matches sorted by file, got %+v", matches.Matches)
+	}
+}
@@ -0,0 +1,180 @@
+package index
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"repository-context-protocol/internal/models"
+)
+
+// RecursiveFunction is a function calling itself directly
+type RecursiveFunction struct {
+	Name      string `json:"name"`
+	File      string `json:"file"`
+	Line      int    `json:"line"`
+	Signature string `json:"signature,omitempty"`
+}
+
+// annotateRecursion marks the functions of fileContext that call themselves: by name, or
+// for methods through a receiver such as n.Walk or self.walk
+func annotateRecursion(fileContext *models.FileContext) {
+	for i := range fileContext.Functions {
+		function := &fileContext.Functions[i]
+		selfCall := func(call string) bool { return isSelfCall(function, call) }
+		function.IsRecursive = slices.ContainsFunc(function.Calls, selfCall) || slices.ContainsFunc(function.LocalCalls, selfCall)
+	}
+}
+
+// isSelfCall reports whether call names function itself. A qualified call counts only for
+// methods and only through a plain identifier, so pkg.Parse inside a function Parse does not.
+func isSelfCall(function *models.Function, call string) bool {
+	if call == function.Name {
+		return true
+	}
+	qualifier, name, qualified := strings.Cut(call, ".")
+	return qualified && function.Receiver != "" && name == function.Name && qualifier != "" && !strings.Contains(qualifier, "(")
+}
+
+// FindSelfRecursive returns the functions marked as calling themselves, by file and in
+// source order within a file
+func (qe *QueryEngine) FindSelfRecursive() ([]RecursiveFunction, error) {
+	files, err := qe.storage.ListFiles()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list files: %w", err)
+	}
+	slices.Sort(files)
+
+	recursive := []RecursiveFunction{}
+	for _, filePath := range files {
+		fileContext, err := qe.storage.GetFileContext(filePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load %s: %w", filePath, err)
+		}
+		for i := range fileContext.Functions {
+			if function := &fileContext.Functions[i]; function.IsRecursive {
+				recursive = append(recursive, RecursiveFunction{
+					Name:      function.Name,
+					File:      fileContext.Path,
+					Line:      function.StartLine,
+					Signature: function.Signature,
+				})
+			}
+		}
+	}
+	return recursive, nil
+}
+
+// FindRecursiveGroups returns the groups of mutually recursive functions: the strongly
+// connected components of the call index with more than one function, found with Tarjan's
+// algorithm. Functions sharing a name share a node, and method calls such as "s.store.Save"
+// are matched by method name, as in ExportCallGraph. Functions in a group are sorted by
+// name and groups by their first function; self-recursion alone is marked on functions by
+// IsRecursive instead.
+func (qe *QueryEngine) FindRecursiveGroups() ([][]string, error) {
+	entries, err := qe.collectEntriesByTypes([]string{EntityTypeFunction})
+	if err != nil {
+		return nil, err
+	}
+
+	graph := &recursionGraph{callees: make(map[string][]string)}
+	for i := range entries {
+		graph.callees[entries[i].IndexEntry.Name] = nil
+	}
+	for name := range graph.callees {
+		graph.names = append(graph.names, name)
+	}
+	slices.Sort(graph.names)
+	for _, name := range graph.names {
+		if graph.callees[name], err = qe.recursionCallees(name, graph.callees); err != nil {
+			return nil, err
+		}
+	}
+
+	groups := graph.stronglyConnected()
+	slices.SortFunc(groups, func(a, b []string) int { return strings.Compare(a[0], b[0]) })
+	return groups, nil
+}
+
+// recursionCallees returns the distinct indexed functions called by function
+func (qe *QueryEngine) recursionCallees(function string, nodes map[string][]string) ([]string, error) {
+	relations, err := qe.storage.QueryCallsFrom(function)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query callees of %s: %w", function, err)
+	}
+
+	callees := []string{}
+	for _, relation := range relations {
+		callee := relation.Callee
+		if _, indexed := nodes[callee]; !indexed {
+			callee = callee[strings.LastIndex(callee, ".")+1:]
+			if _, indexed := nodes[callee]; !indexed {
+				continue
+			}
+		}
+		if !slices.Contains(callees, callee) {
+			callees = append(callees, callee)
+		}
+	}
+	return callees, nil
+}
+
+// recursionGraph is the call graph FindRecursiveGroups searches, with the state of
+// Tarjan's algorithm
+type recursionGraph struct {
+	names   []string            // Functions in visiting order
+	callees map[string][]string // Distinct indexed callees of each function
+
+	indices  map[string]int // Visiting order of each function seen
+	lowLinks map[string]int // Smallest index reachable from each function on the stack
+	onStack  map[string]bool
+	stack    []string
+	groups   [][]string
+}
+
+// stronglyConnected returns the components of the graph with more than one function,
+// each sorted by name
+func (g *recursionGraph) stronglyConnected() [][]string {
+	g.indices = make(map[string]int, len(g.names))
+	g.lowLinks = make(map[string]int, len(g.names))
+	g.onStack = make(map[string]bool)
+	g.groups = [][]string{}
+	for _, name := range g.names {
+		if _, visited := g.indices[name]; !visited {
+			g.visit(name)
+		}
+	}
+	return g.groups
+}
+
+// visit runs Tarjan's depth-first search from function, popping a component off the
+// stack once function turns out to be its root
+func (g *recursionGraph) visit(function string) {
+	g.indices[function] = len(g.indices)
+	g.lowLinks[function] = g.indices[function]
+	g.stack = append(g.stack, function)
+	g.onStack[function] = true
+
+	for _, callee := range g.callees[function] {
+		if _, visited := g.indices[callee]; !visited {
+			g.visit(callee)
+			g.lowLinks[function] = min(g.lowLinks[function], g.lowLinks[callee])
+		} else if g.onStack[callee] {
+			g.lowLinks[function] = min(g.lowLinks[function], g.indices[callee])
+		}
+	}
+
+	if g.lowLinks[function] != g.indices[function] {
+		return
+	}
+	root := slices.Index(g.stack, function)
+	component := slices.Clone(g.stack[root:])
+	g.stack = g.stack[:root]
+	for _, member := range component {
+		g.onStack[member] = false
+	}
+	if len(component) > 1 {
+		slices.Sort(component)
+		g.groups = append(g.groups, component)
+	}
+}
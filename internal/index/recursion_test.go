@@ -0,0 +1,67 @@
+package index
+
+import (
+	"slices"
+	"testing"
+
+	"repository-context-protocol/internal/models"
+)
+
+func TestAnnotateRecursion(t *testing.T) {
+	fileContext := &models.FileContext{
+		Path: "tree.go", Language: "go",
+		Functions: []models.Function{
+			{Name: "factorial", Calls: []string{"factorial"}},
+			{Name: "Walk", Receiver: "Node", LocalCalls: []string{"child.Walk", "visit"}},
+			{Name: "Parse", Calls: []string{"strconv.Parse"}},
+			{Name: "Close", Receiver: "File", Calls: []string{"f.inner.Close"}},
+		},
+	}
+	annotateRecursion(fileContext)
+
+	for i, expected := range []bool{true, true, false, false} {
+		if function := &fileContext.Functions[i]; function.IsRecursive != expected {
+			t.Errorf("Expected %s IsRecursive %v, got %v", function.Name, expected, function.IsRecursive)
+		}
+	}
+}
+
+func TestQueryEngine_FindRecursion(t *testing.T) {
+	storage := newDiffTestStorage(t,
+		&models.FileContext{
+			Path: "eval.go", Language: "go",
+			Functions: []models.Function{
+				{Name: "evalExpr", StartLine: 1, Calls: []string{"evalTerm", "logf"}},
+				{Name: "evalTerm", StartLine: 10, Calls: []string{"evalFactor"}},
+				{Name: "evalFactor", StartLine: 20, Calls: []string{"evalExpr"}},
+				{Name: "factorial", StartLine: 30, Calls: []string{"factorial"}, IsRecursive: true},
+			},
+		},
+		&models.FileContext{
+			Path: "parity.go", Language: "go",
+			Functions: []models.Function{
+				{Name: "isOdd", StartLine: 1, Calls: []string{"p.isEven"}},
+				{Name: "isEven", StartLine: 5, Calls: []string{"isOdd"}},
+				{Name: "logf", StartLine: 10},
+			},
+		},
+	)
+	engine := NewQueryEngine(storage)
+
+	groups, err := engine.FindRecursiveGroups()
+	if err != nil {
+		t.Fatalf("Failed to find recursive groups: %v", err)
+	}
+	expected := [][]string{{"evalExpr", "evalFactor", "evalTerm"}, {"isEven", "isOdd"}}
+	if !slices.EqualFunc(groups, expected, slices.Equal[[]string]) {
+		t.Errorf("Expected groups %v, got %v", expected, groups)
+	}
+
+	recursive, err := engine.FindSelfRecursive()
+	if err != nil {
+		t.Fatalf("Failed to find self-recursive functions: %v", err)
+	}
+	if len(recursive) != 1 || recursive[0].Name != "factorial" || recursive[0].File != "eval.go" || recursive[0].Line != 30 {
+		t.Errorf("Expected only factorial to be self-recursive, got %+v", recursive)
+	}
+}
@@ -0,0 +1,74 @@
+package index
+
+import (
+	"regexp"
+	"sync"
+)
+
+// RegexCache holds compiled regular expressions by pattern. It is safe for concurrent use,
+// so one cache can be shared by every query engine in a process through WithRegexCache
+// and a pattern compiles once however many engines match it.
+// A bounded cache evicts its oldest pattern when full; lookups only take a read lock.
+type RegexCache struct {
+	mutex    sync.RWMutex
+	capacity int
+	patterns map[string]*regexp.Regexp
+	order    []string // Patterns in insertion order, as a ring of capacity entries
+	next     int      // Ring position of the next insertion, the oldest pattern when full
+}
+
+// NewRegexCache creates a regex cache holding up to capacity patterns.
+// A capacity of zero or less keeps every pattern.
+func NewRegexCache(capacity int) *RegexCache {
+	cache := &RegexCache{patterns: make(map[string]*regexp.Regexp)}
+	if capacity > 0 {
+		cache.capacity = capacity
+		cache.order = make([]string, capacity)
+	}
+	return cache
+}
+
+// Compile returns the compiled regex for pattern, compiling and caching it on first use
+func (c *RegexCache) Compile(pattern string) (*regexp.Regexp, error) {
+	if regex, exists := c.get(pattern); exists {
+		return regex, nil
+	}
+
+	// Compile outside the lock so a slow pattern does not hold up lookups of others
+	regex, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	// Another goroutine may have cached the pattern meanwhile; keep a single instance
+	if existing, exists := c.patterns[pattern]; exists {
+		return existing, nil
+	}
+	if c.capacity > 0 {
+		if len(c.patterns) >= c.capacity {
+			delete(c.patterns, c.order[c.next])
+		}
+		c.order[c.next] = pattern
+		c.next = (c.next + 1) % c.capacity
+	}
+	c.patterns[pattern] = regex
+	return regex, nil
+}
+
+// Len returns the number of cached patterns
+func (c *RegexCache) Len() int {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return len(c.patterns)
+}
+
+// get returns the cached regex for pattern under the read lock
+func (c *RegexCache) get(pattern string) (*regexp.Regexp, bool) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	regex, exists := c.patterns[pattern]
+	return regex, exists
+}
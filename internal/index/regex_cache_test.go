@@ -0,0 +1,101 @@
+package index
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"repository-context-protocol/internal/models"
+)
+
+func TestRegexCache_EvictsOldestPattern(t *testing.T) {
+	cache := NewRegexCache(2)
+
+	first, err := cache.Compile("^a")
+	if err != nil {
+		t.Fatalf("Failed to compile: %v", err)
+	}
+	if again, _ := cache.Compile("^a"); again != first {
+		t.Error("Expected a cached pattern to return the same regex")
+	}
+	if _, err := cache.Compile("^b"); err != nil {
+		t.Fatalf("Failed to compile: %v", err)
+	}
+	if _, err := cache.Compile("^c"); err != nil {
+		t.Fatalf("Failed to compile: %v", err)
+	}
+
+	if cache.Len() != 2 {
+		t.Errorf("Expected the cache to hold 2 patterns, got %d", cache.Len())
+	}
+	if _, exists := cache.get("^a"); exists {
+		t.Error("Expected the oldest pattern to be evicted")
+	}
+	if _, err := cache.Compile("(unclosed"); err == nil {
+		t.Error("Expected an invalid pattern to fail")
+	}
+	if cache.Len() != 2 {
+		t.Errorf("Expected invalid patterns not to be cached, got %d patterns", cache.Len())
+	}
+}
+
+func TestRegexCache_ConcurrentEviction(t *testing.T) {
+	cache := NewRegexCache(8)
+
+	var wg sync.WaitGroup
+	for worker := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range 200 {
+				pattern := fmt.Sprintf("^p%d_%d$", worker, i%16)
+				regex, err := cache.Compile(pattern)
+				if err != nil {
+					t.Errorf("Failed to compile %s: %v", pattern, err)
+					return
+				}
+				if !regex.MatchString(pattern[1 : len(pattern)-1]) {
+					t.Errorf("Expected %s to match its own text", pattern)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if cache.Len() > 8 {
+		t.Errorf("Expected at most 8 cached patterns, got %d", cache.Len())
+	}
+}
+
+func TestQueryEngine_WithRegexCache(t *testing.T) {
+	storage := newDiffTestStorage(t, &models.FileContext{
+		Path: "handlers.go", Language: "go",
+		Functions: []models.Function{{Name: "HandleUser", StartLine: 3}, {Name: "HandleOrder", StartLine: 8}},
+	})
+	shared := NewRegexCache(16)
+	first := NewQueryEngine(storage, WithRegexCache(shared))
+	second := NewQueryEngine(storage, WithRegexCache(shared))
+
+	for _, engine := range []*QueryEngine{first, second} {
+		result, err := engine.SearchByPattern("/^Handle(User|Order)$/")
+		if err != nil {
+			t.Fatalf("Failed to search by pattern: %v", err)
+		}
+		if len(result.Entries) != 2 {
+			t.Errorf("Expected 2 matches, got %d", len(result.Entries))
+		}
+	}
+	if shared.Len() != 1 {
+		t.Errorf("Expected engines to share one compiled pattern, got %d", shared.Len())
+	}
+
+	// Engines without an injected cache keep their own
+	own := NewQueryEngine(storage)
+	if _, err := own.SearchByPattern("/^HandleUser$/"); err != nil {
+		t.Fatalf("Failed to search by pattern: %v", err)
+	}
+	if shared.Len() != 1 || own.regexCache.Len() != 1 {
+		t.Errorf("Expected separate caches, got %d shared and %d own patterns", shared.Len(), own.regexCache.Len())
+	}
+}
@@ -1,14 +1,13 @@
 package index
 
 import (
-	"regexp"
 	"strings"
 	"testing"
 )
 
 func TestConvertUnsupportedRegexFeatures(t *testing.T) {
 	qe := &QueryEngine{
-		regexCache: make(map[string]*regexp.Regexp),
+		regexCache: NewRegexCache(0),
 	}
 
 	tests := []struct {
@@ -67,7 +66,7 @@ func TestConvertUnsupportedRegexFeatures(t *testing.T) {
 
 func TestConvertUnsupportedRegexFeaturesWithError(t *testing.T) {
 	qe := &QueryEngine{
-		regexCache: make(map[string]*regexp.Regexp),
+		regexCache: NewRegexCache(0),
 	}
 
 	tests := []struct {
@@ -180,7 +179,7 @@ func TestConvertUnsupportedRegexFeaturesWithError(t *testing.T) {
 
 func TestGetCompiledRegexWithUnsupportedFeatures(t *testing.T) {
 	qe := &QueryEngine{
-		regexCache: make(map[string]*regexp.Regexp),
+		regexCache: NewRegexCache(0),
 	}
 
 	// Test that the integration works with actual regex compilation
@@ -222,3 +221,83 @@ func TestGetCompiledRegexWithUnsupportedFeatures(t *testing.T) {
 		})
 	}
 }
+
+func TestMatchesRegexEmulatesLookaround(t *testing.T) {
+	qe := &QueryEngine{
+		regexCache: NewRegexCache(0),
+	}
+
+	names := []string{"TestHandler", "HandleUser", "HandleError", "ProcessData", "ParseData", "handleuser"}
+
+	tests := []struct {
+		name     string
+		pattern  string
+		expected []string
+	}{
+		{
+			name:     "Negative lookahead excludes prefix",
+			pattern:  "/^(?!Test)[A-Z]/",
+			expected: []string{"HandleUser", "HandleError", "ProcessData", "ParseData"},
+		},
+		{
+			name:     "Negative lookahead after literal",
+			pattern:  "/^Handle(?!Error)/",
+			expected: []string{"HandleUser"},
+		},
+		{
+			name:     "Negative lookbehind before suffix",
+			pattern:  "/^.*(?<!Process)Data$/",
+			expected: []string{"ParseData"},
+		},
+		{
+			name:     "Positive lookahead without consuming",
+			pattern:  "/^Handle(?=User)User$/",
+			expected: []string{"HandleUser"},
+		},
+		{
+			name:     "Positive lookbehind",
+			pattern:  "/(?<=Handle)Error$/",
+			expected: []string{"HandleError"},
+		},
+		{
+			name:     "Multiple assertions",
+			pattern:  "/^(?!Test)(?!Process).*(?<!Error)$/",
+			expected: []string{"HandleUser", "ParseData", "handleuser"},
+		},
+		{
+			name:     "Leading flags apply to assertions",
+			pattern:  "/(?i)^handle(?!error)/",
+			expected: []string{"HandleUser", "handleuser"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var matched []string
+			for _, name := range names {
+				if qe.matchesRegex(name, tt.pattern, false) {
+					matched = append(matched, name)
+				}
+			}
+			if strings.Join(matched, ",") != strings.Join(tt.expected, ",") {
+				t.Errorf("Pattern %s matched %v, want %v", tt.pattern, matched, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseLookaroundPatternFallsBack(t *testing.T) {
+	patterns := []string{
+		"simple.*pattern",     // no lookarounds
+		"(Handle(?!Error))",   // nested inside a group
+		"^(?!Test)|Handler$",  // top-level alternation
+		"[(?!]Handler",        // inside a character class
+		"\\(?!Test\\)Handler", // escaped parentheses
+	}
+
+	for _, pattern := range patterns {
+		if _, _, _, ok := parseLookaroundPattern(pattern); ok {
+			t.Errorf("Expected %q not to be emulated", pattern)
+		}
+	}
+}
@@ -0,0 +1,236 @@
+package index
+
+import (
+	"regexp"
+	"strings"
+)
+
+// lookaroundKind identifies a zero-width lookaround assertion
+type lookaroundKind int
+
+const (
+	lookaheadPositive lookaroundKind = iota
+	lookaheadNegative
+	lookbehindPositive
+	lookbehindNegative
+)
+
+// lookaroundPrefixes maps assertion openers to their kinds.
+// Longer openers come first so "(?<!" is not mistaken for a named group.
+var lookaroundPrefixes = []struct {
+	prefix string
+	kind   lookaroundKind
+}{
+	{"(?<=", lookbehindPositive},
+	{"(?<!", lookbehindNegative},
+	{"(?=", lookaheadPositive},
+	{"(?!", lookaheadNegative},
+}
+
+// leadingFlagGroup matches a flag-only group such as (?i) at the start of a pattern
+var leadingFlagGroup = regexp.MustCompile(`^\(\?[imsU-]+\)`)
+
+// lookaroundAssertion is a compiled assertion checked at a single position in the name
+type lookaroundAssertion struct {
+	kind  lookaroundKind
+	regex *regexp.Regexp
+}
+
+// holdsAt reports whether the assertion is satisfied at byte offset pos of name
+func (a lookaroundAssertion) holdsAt(name string, pos int) bool {
+	switch a.kind {
+	case lookaheadPositive:
+		return a.regex.MatchString(name[pos:])
+	case lookaheadNegative:
+		return !a.regex.MatchString(name[pos:])
+	case lookbehindPositive:
+		return a.regex.MatchString(name[:pos])
+	default:
+		return !a.regex.MatchString(name[:pos])
+	}
+}
+
+// lookaroundMatcher emulates a regex with top-level lookaround assertions, which Go's regexp
+// does not support. The pattern is split into plain segments separated by assertions; a name
+// matches when the segments match consecutive spans and every assertion holds at its boundary.
+type lookaroundMatcher struct {
+	segments   []*regexp.Regexp // first is end-anchored, last is start-anchored, others fully anchored
+	assertions []lookaroundAssertion
+}
+
+// parseLookaroundPattern splits a pattern at its top-level lookaround assertions.
+// It returns false when the pattern has no lookarounds or uses them in a way that
+// cannot be emulated, such as inside a group or alongside top-level alternation.
+func parseLookaroundPattern(pattern string) (segments []string, kinds []lookaroundKind, bodies []string, ok bool) {
+	depth := 0
+	segmentStart := 0
+	for i := 0; i < len(pattern); i++ {
+		switch pattern[i] {
+		case '\\':
+			i++
+		case '[':
+			i = skipCharacterClass(pattern, i)
+		case '|':
+			if depth == 0 {
+				return nil, nil, nil, false
+			}
+		case ')':
+			depth--
+		case '(':
+			kind, prefixLen, isLookaround := lookaroundAt(pattern, i)
+			if !isLookaround {
+				depth++
+				continue
+			}
+			if depth > 0 {
+				return nil, nil, nil, false
+			}
+			end := matchingParen(pattern, i)
+			if end < 0 {
+				return nil, nil, nil, false
+			}
+			segments = append(segments, pattern[segmentStart:i])
+			kinds = append(kinds, kind)
+			bodies = append(bodies, pattern[i+prefixLen:end])
+			segmentStart = end + 1
+			i = end
+		}
+	}
+
+	if len(kinds) == 0 {
+		return nil, nil, nil, false
+	}
+	segments = append(segments, pattern[segmentStart:])
+	return segments, kinds, bodies, true
+}
+
+// lookaroundAt reports whether a lookaround assertion opens at offset i
+func lookaroundAt(pattern string, i int) (lookaroundKind, int, bool) {
+	for _, candidate := range lookaroundPrefixes {
+		if strings.HasPrefix(pattern[i:], candidate.prefix) {
+			return candidate.kind, len(candidate.prefix), true
+		}
+	}
+	return 0, 0, false
+}
+
+// matchingParen returns the offset of the parenthesis closing the group opened at open, or -1
+func matchingParen(pattern string, open int) int {
+	depth := 0
+	for i := open; i < len(pattern); i++ {
+		switch pattern[i] {
+		case '\\':
+			i++
+		case '[':
+			i = skipCharacterClass(pattern, i)
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// skipCharacterClass returns the offset of the ']' closing the class opened at open
+func skipCharacterClass(pattern string, open int) int {
+	i := open + 1
+	if i < len(pattern) && pattern[i] == '^' {
+		i++
+	}
+	// A leading ']' is a literal member of the class
+	if i < len(pattern) && pattern[i] == ']' {
+		i++
+	}
+	for ; i < len(pattern); i++ {
+		switch pattern[i] {
+		case '\\':
+			i++
+		case ']':
+			return i
+		}
+	}
+	return len(pattern)
+}
+
+// getLookaroundMatcher builds a matcher for a pattern with top-level lookarounds.
+// It returns false when the pattern cannot be emulated so callers can fall back.
+func (qe *QueryEngine) getLookaroundMatcher(pattern string) (*lookaroundMatcher, bool) {
+	// Leading flags such as (?i) apply to the whole pattern, so repeat them on every part
+	flags := leadingFlagGroup.FindString(pattern)
+	segments, kinds, bodies, ok := parseLookaroundPattern(pattern[len(flags):])
+	if !ok {
+		return nil, false
+	}
+
+	matcher := &lookaroundMatcher{}
+	for i, segment := range segments {
+		anchored := "^(?:" + segment + ")$"
+		switch i {
+		case 0:
+			anchored = "(?:" + segment + ")$"
+		case len(segments) - 1:
+			anchored = "^(?:" + segment + ")"
+		}
+		regex, err := qe.compileAndCacheRegex(flags + anchored)
+		if err != nil {
+			return nil, false
+		}
+		matcher.segments = append(matcher.segments, regex)
+	}
+
+	for i, body := range bodies {
+		anchored := "(?:" + body + ")$"
+		if kinds[i] == lookaheadPositive || kinds[i] == lookaheadNegative {
+			anchored = "^(?:" + body + ")"
+		}
+		regex, err := qe.compileAndCacheRegex(flags + anchored)
+		if err != nil {
+			return nil, false
+		}
+		matcher.assertions = append(matcher.assertions, lookaroundAssertion{kind: kinds[i], regex: regex})
+	}
+
+	return matcher, true
+}
+
+// MatchString reports whether name matches the emulated pattern
+func (m *lookaroundMatcher) MatchString(name string) bool {
+	for _, pos := range runeBoundaries(name) {
+		if m.segments[0].MatchString(name[:pos]) && m.matchFrom(name, 0, pos) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchFrom checks assertion index at pos and then the remaining segments after it
+func (m *lookaroundMatcher) matchFrom(name string, index, pos int) bool {
+	if !m.assertions[index].holdsAt(name, pos) {
+		return false
+	}
+
+	segment := m.segments[index+1]
+	if index == len(m.assertions)-1 {
+		return segment.MatchString(name[pos:])
+	}
+
+	for _, end := range runeBoundaries(name) {
+		if end >= pos && segment.MatchString(name[pos:end]) && m.matchFrom(name, index+1, end) {
+			return true
+		}
+	}
+	return false
+}
+
+// runeBoundaries returns every byte offset of name that starts a rune, plus its length
+func runeBoundaries(name string) []int {
+	boundaries := make([]int, 0, len(name)+1)
+	for i := range name {
+		boundaries = append(boundaries, i)
+	}
+	return append(boundaries, len(name))
+}
@@ -0,0 +1,219 @@
+package index
+
+import (
+	"fmt"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Reindex queue defaults
+const (
+	DefaultReindexBatchWindow = 200 * time.Millisecond // Time changes accumulate before a batch is processed
+	DefaultReindexMaxPending  = 1024                   // Distinct files waiting before Enqueue blocks
+)
+
+// ReindexQueueOptions configures a ReindexQueue. Zero values select the defaults.
+type ReindexQueueOptions struct {
+	BatchWindow time.Duration // Time changes accumulate before a batch is processed
+	MaxPending  int           // Distinct files waiting before Enqueue blocks
+
+	// Workers bounds how many files are reindexed at once, one when zero.
+	// IndexBuilder.ReindexFile patches call graph edges shared between files,
+	// so a queue driving it should keep a single worker.
+	Workers int
+
+	// OnError is called with each file that failed to reindex; failures are only counted when nil
+	OnError func(path string, err error)
+}
+
+// ReindexQueueStatus reports the state of a ReindexQueue
+type ReindexQueueStatus struct {
+	Pending   int   `json:"pending"`   // Files waiting for the next batch
+	InFlight  int   `json:"in_flight"` // Files being reindexed
+	Processed int64 `json:"processed"` // Files reindexed successfully
+	Failed    int64 `json:"failed"`    // Files that failed to reindex
+	Coalesced int64 `json:"coalesced"` // Changes merged into a file that was already pending
+}
+
+// ReindexQueue coalesces file change notifications, such as those of a file watcher, into
+// batches reindexed on a bounded worker pool. A file changed many times within a batch
+// window is reindexed once, and Enqueue blocks while MaxPending files are waiting, so a
+// flood of changes, e.g. from a branch checkout, applies backpressure instead of piling up.
+type ReindexQueue struct {
+	reindex func(path string) error
+	options ReindexQueueOptions
+
+	mu      sync.Mutex
+	space   *sync.Cond // Signalled when pending files are taken or the queue closes
+	pending map[string]bool
+	order   []string // Pending files in the order they were first enqueued
+	status  ReindexQueueStatus
+	closed  bool
+
+	wake chan struct{} // Nudges the dispatcher when the first file of a batch arrives
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewReindexQueue creates a queue that calls reindex for each changed file, typically
+// IndexBuilder.ReindexFile, and starts processing batches in the background
+func NewReindexQueue(reindex func(path string) error, options ReindexQueueOptions) *ReindexQueue {
+	if options.BatchWindow <= 0 {
+		options.BatchWindow = DefaultReindexBatchWindow
+	}
+	if options.MaxPending <= 0 {
+		options.MaxPending = DefaultReindexMaxPending
+	}
+	if options.Workers <= 0 {
+		options.Workers = 1
+	}
+
+	q := &ReindexQueue{
+		reindex: reindex,
+		options: options,
+		pending: make(map[string]bool),
+		wake:    make(chan struct{}, 1),
+		done:    make(chan struct{}),
+	}
+	q.space = sync.NewCond(&q.mu)
+
+	q.wg.Add(1)
+	go q.dispatch()
+	return q
+}
+
+// Enqueue records that a file changed. A file already waiting for the next batch is not
+// queued again. Enqueue blocks while the queue is full and fails once it is closed.
+func (q *ReindexQueue) Enqueue(path string) error {
+	path = filepath.Clean(path)
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for !q.closed && !q.pending[path] && len(q.order) >= q.options.MaxPending {
+		q.space.Wait()
+	}
+	if q.closed {
+		return fmt.Errorf("reindex queue is closed")
+	}
+	if q.pending[path] {
+		q.status.Coalesced++
+		return nil
+	}
+
+	q.pending[path] = true
+	q.order = append(q.order, path)
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// Status returns the queue depth and counters
+func (q *ReindexQueue) Status() ReindexQueueStatus {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	status := q.status
+	status.Pending = len(q.order)
+	return status
+}
+
+// Close stops accepting changes, reindexes the files still pending and waits for the
+// workers to finish
+func (q *ReindexQueue) Close() {
+	q.mu.Lock()
+	if q.closed {
+		q.mu.Unlock()
+		return
+	}
+	q.closed = true
+	q.space.Broadcast()
+	q.mu.Unlock()
+
+	close(q.done)
+	q.wg.Wait()
+}
+
+// dispatch waits for changes, lets them accumulate for a batch window and processes them
+func (q *ReindexQueue) dispatch() {
+	defer q.wg.Done()
+
+	timer := time.NewTimer(q.options.BatchWindow)
+	timer.Stop()
+	for {
+		select {
+		case <-q.wake:
+		case <-q.done:
+			q.processBatch(q.takeBatch())
+			return
+		}
+
+		timer.Reset(q.options.BatchWindow)
+		select {
+		case <-timer.C:
+		case <-q.done:
+			timer.Stop()
+			q.processBatch(q.takeBatch())
+			return
+		}
+		q.processBatch(q.takeBatch())
+	}
+}
+
+// takeBatch removes the pending files, making room for the next batch
+func (q *ReindexQueue) takeBatch() []string {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	batch := q.order
+	q.order = nil
+	q.pending = make(map[string]bool)
+	q.status.InFlight = len(batch)
+	q.space.Broadcast()
+	return batch
+}
+
+// processBatch reindexes a batch on up to Workers goroutines
+func (q *ReindexQueue) processBatch(batch []string) {
+	if len(batch) == 0 {
+		return
+	}
+
+	jobs := make(chan string)
+	var workers sync.WaitGroup
+	for range min(q.options.Workers, len(batch)) {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for path := range jobs {
+				q.reindexFile(path)
+			}
+		}()
+	}
+	for _, path := range batch {
+		jobs <- path
+	}
+	close(jobs)
+	workers.Wait()
+}
+
+// reindexFile reindexes one file and records the outcome
+func (q *ReindexQueue) reindexFile(path string) {
+	err := q.reindex(path)
+
+	q.mu.Lock()
+	q.status.InFlight--
+	if err != nil {
+		q.status.Failed++
+	} else {
+		q.status.Processed++
+	}
+	q.mu.Unlock()
+
+	if err != nil && q.options.OnError != nil {
+		q.options.OnError(path, err)
+	}
+}
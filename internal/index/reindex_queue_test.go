@@ -0,0 +1,168 @@
+package index
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestReindexQueue_CoalescesChanges(t *testing.T) {
+	var mu sync.Mutex
+	counts := make(map[string]int)
+	queue := NewReindexQueue(func(path string) error {
+		mu.Lock()
+		defer mu.Unlock()
+		counts[path]++
+		return nil
+	}, ReindexQueueOptions{BatchWindow: 50 * time.Millisecond})
+
+	for i := 0; i < 10; i++ {
+		if err := queue.Enqueue("main.go"); err != nil {
+			t.Fatalf("Failed to enqueue: %v", err)
+		}
+	}
+	if err := queue.Enqueue("./util.go"); err != nil {
+		t.Fatalf("Failed to enqueue: %v", err)
+	}
+	if status := queue.Status(); status.Pending != 2 || status.Coalesced != 9 {
+		t.Errorf("Expected 2 pending files and 9 coalesced changes, got %+v", status)
+	}
+
+	queue.Close()
+
+	if counts["main.go"] != 1 || counts["util.go"] != 1 {
+		t.Errorf("Expected each file to be reindexed once, got %v", counts)
+	}
+	if status := queue.Status(); status.Pending != 0 || status.InFlight != 0 || status.Processed != 2 {
+		t.Errorf("Expected an empty queue with 2 processed files, got %+v", status)
+	}
+	if err := queue.Enqueue("main.go"); err == nil {
+		t.Error("Expected enqueueing on a closed queue to fail")
+	}
+}
+
+func TestReindexQueue_ReindexesAgainInLaterBatch(t *testing.T) {
+	var processed atomic.Int32
+	queue := NewReindexQueue(func(string) error {
+		processed.Add(1)
+		return nil
+	}, ReindexQueueOptions{BatchWindow: 10 * time.Millisecond})
+	defer queue.Close()
+
+	if err := queue.Enqueue("main.go"); err != nil {
+		t.Fatalf("Failed to enqueue: %v", err)
+	}
+	waitForReindex(t, func() bool { return processed.Load() == 1 })
+
+	if err := queue.Enqueue("main.go"); err != nil {
+		t.Fatalf("Failed to enqueue: %v", err)
+	}
+	waitForReindex(t, func() bool { return processed.Load() == 2 })
+}
+
+func TestReindexQueue_BackpressureAndWorkers(t *testing.T) {
+	var running, maxRunning atomic.Int32
+	failing := errors.New("parse error")
+	var failures atomic.Int32
+
+	queue := NewReindexQueue(func(path string) error {
+		current := running.Add(1)
+		defer running.Add(-1)
+		for {
+			previous := maxRunning.Load()
+			if current <= previous || maxRunning.CompareAndSwap(previous, current) {
+				break
+			}
+		}
+		time.Sleep(2 * time.Millisecond)
+		if path == "bad.go" {
+			return failing
+		}
+		return nil
+	}, ReindexQueueOptions{
+		BatchWindow: time.Millisecond,
+		MaxPending:  4,
+		Workers:     2,
+		OnError: func(path string, err error) {
+			if path == "bad.go" && errors.Is(err, failing) {
+				failures.Add(1)
+			}
+		},
+	})
+
+	var producers sync.WaitGroup
+	for p := 0; p < 4; p++ {
+		producers.Add(1)
+		go func() {
+			defer producers.Done()
+			for i := 0; i < 10; i++ {
+				if err := queue.Enqueue(filepath.Join("pkg", string(rune('a'+p)), string(rune('a'+i))+".go")); err != nil {
+					t.Errorf("Failed to enqueue: %v", err)
+				}
+				if pending := queue.Status().Pending; pending > 4 {
+					t.Errorf("Expected at most 4 pending files, got %d", pending)
+				}
+			}
+		}()
+	}
+	producers.Wait()
+	if err := queue.Enqueue("bad.go"); err != nil {
+		t.Fatalf("Failed to enqueue: %v", err)
+	}
+	queue.Close()
+
+	status := queue.Status()
+	if status.Processed != 40 || status.Failed != 1 || failures.Load() != 1 {
+		t.Errorf("Expected 40 processed files and 1 failure, got %+v", status)
+	}
+	if maxRunning.Load() > 2 {
+		t.Errorf("Expected at most 2 concurrent reindexes, got %d", maxRunning.Load())
+	}
+}
+
+func TestReindexQueue_IndexBuilder(t *testing.T) {
+	tempDir := t.TempDir()
+	mainPath := filepath.Join(tempDir, "main.go")
+	if err := os.WriteFile(mainPath, []byte("package main\n\nfunc main() {}\n"), 0600); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	builder := NewIndexBuilder(tempDir)
+	if err := builder.Initialize(); err != nil {
+		t.Fatalf("Failed to initialize builder: %v", err)
+	}
+	defer builder.Close()
+
+	queue := NewReindexQueue(builder.ReindexFile, ReindexQueueOptions{BatchWindow: time.Millisecond})
+	if err := queue.Enqueue(mainPath); err != nil {
+		t.Fatalf("Failed to enqueue: %v", err)
+	}
+	queue.Close()
+
+	if status := queue.Status(); status.Processed != 1 {
+		t.Fatalf("Expected the file to be reindexed, got %+v", status)
+	}
+	files, err := builder.storage.ListFiles()
+	if err != nil {
+		t.Fatalf("Failed to list files: %v", err)
+	}
+	if len(files) != 1 {
+		t.Errorf("Expected 1 indexed file, got %v", files)
+	}
+}
+
+// waitForReindex polls condition until it holds or a second passes
+func waitForReindex(t *testing.T, condition func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatal("Timed out waiting for the reindex queue")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
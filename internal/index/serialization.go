@@ -1,7 +1,11 @@
 package index
 
 import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
@@ -16,11 +20,19 @@ const (
 	chunkFilePermissions = 0600
 	// Directory permissions for chunk directory (read/write/execute for owner, read/execute for group and others)
 	chunkDirPermissions = 0755
+
+	// Length of the gzip trailer field holding the uncompressed size modulo 2^32
+	gzipSizeTrailerLength = 4
 )
 
+// gzipMagic starts every gzip stream. It marks compressed chunk files: MessagePack
+// chunks start with a map header, which never begins with these bytes.
+var gzipMagic = []byte{0x1f, 0x8b}
+
 // ChunkSerializer handles saving and loading semantic chunks using MessagePack
 type ChunkSerializer struct {
-	baseDir string
+	baseDir  string
+	compress bool
 }
 
 // NewChunkSerializer creates a new chunk serializer with the specified base directory
@@ -30,7 +42,14 @@ func NewChunkSerializer(baseDir string) *ChunkSerializer {
 	}
 }
 
-// SaveChunk saves a semantic chunk to disk using MessagePack serialization
+// SetCompression makes SaveChunk gzip the chunks it writes. Chunks are loaded whether
+// they are compressed or not, so an index may mix both.
+func (cs *ChunkSerializer) SetCompression(enabled bool) {
+	cs.compress = enabled
+}
+
+// SaveChunk saves a semantic chunk to disk using MessagePack serialization, gzipped
+// when compression is enabled
 func (cs *ChunkSerializer) SaveChunk(chunk *models.SemanticChunk) error {
 	// Ensure the base directory exists
 	if err := os.MkdirAll(cs.baseDir, chunkDirPermissions); err != nil {
@@ -45,6 +64,11 @@ func (cs *ChunkSerializer) SaveChunk(chunk *models.SemanticChunk) error {
 	if err != nil {
 		return fmt.Errorf("failed to marshal chunk: %w", err)
 	}
+	if cs.compress {
+		if data, err = compressChunk(data); err != nil {
+			return err
+		}
+	}
 
 	// Write to file
 	if err := os.WriteFile(filePath, data, chunkFilePermissions); err != nil {
@@ -54,7 +78,8 @@ func (cs *ChunkSerializer) SaveChunk(chunk *models.SemanticChunk) error {
 	return nil
 }
 
-// LoadChunk loads a semantic chunk from disk using MessagePack deserialization
+// LoadChunk loads a semantic chunk from disk using MessagePack deserialization,
+// decompressing gzipped chunks first
 func (cs *ChunkSerializer) LoadChunk(chunkID string) (models.SemanticChunk, error) {
 	var chunk models.SemanticChunk
 
@@ -79,6 +104,11 @@ func (cs *ChunkSerializer) LoadChunk(chunkID string) (models.SemanticChunk, erro
 	if err != nil {
 		return chunk, fmt.Errorf("failed to read chunk file: %w", err)
 	}
+	if bytes.HasPrefix(data, gzipMagic) {
+		if data, err = decompressChunk(data); err != nil {
+			return chunk, err
+		}
+	}
 
 	// Deserialize using MessagePack
 	if err := msgpack.Unmarshal(data, &chunk); err != nil {
@@ -93,6 +123,37 @@ func (cs *ChunkSerializer) GetChunkPath(chunkID string) string {
 	return filepath.Join(cs.baseDir, chunkID+".msgpack")
 }
 
+// ChunkSize returns the size of a chunk file on disk and its uncompressed size, which
+// are equal for chunks stored without compression
+func (cs *ChunkSerializer) ChunkSize(chunkID string) (stored, logical int64, err error) {
+	if err := cs.validateChunkID(chunkID); err != nil {
+		return 0, 0, fmt.Errorf("invalid chunk ID: %w", err)
+	}
+
+	file, err := os.Open(cs.GetChunkPath(chunkID)) // #nosec G304 - Chunk ID validated above
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to open chunk file: %w", err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to stat chunk file: %w", err)
+	}
+	stored = info.Size()
+
+	header := make([]byte, len(gzipMagic))
+	if _, err := io.ReadFull(file, header); err != nil || !bytes.Equal(header, gzipMagic) {
+		return stored, stored, nil
+	}
+	// Chunks are far below 4 GiB, so the trailer holds their exact uncompressed size
+	trailer := make([]byte, gzipSizeTrailerLength)
+	if _, err := file.ReadAt(trailer, stored-gzipSizeTrailerLength); err != nil {
+		return 0, 0, fmt.Errorf("failed to read chunk size: %w", err)
+	}
+	return stored, int64(binary.LittleEndian.Uint32(trailer)), nil
+}
+
 // ChunkExists checks if a chunk file exists on disk
 func (cs *ChunkSerializer) ChunkExists(chunkID string) bool {
 	filePath := cs.GetChunkPath(chunkID)
@@ -131,6 +192,34 @@ func (cs *ChunkSerializer) ListChunks() ([]string, error) {
 	return chunkIDs, nil
 }
 
+// compressChunk gzips a serialized chunk
+func compressChunk(data []byte) ([]byte, error) {
+	var buffer bytes.Buffer
+	writer := gzip.NewWriter(&buffer)
+	if _, err := writer.Write(data); err != nil {
+		return nil, fmt.Errorf("failed to compress chunk: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("failed to compress chunk: %w", err)
+	}
+	return buffer.Bytes(), nil
+}
+
+// decompressChunk restores a serialized chunk written by compressChunk
+func decompressChunk(data []byte) ([]byte, error) {
+	reader, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress chunk: %w", err)
+	}
+	defer reader.Close()
+
+	data, err = io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress chunk: %w", err)
+	}
+	return data, nil
+}
+
 // validateChunkID validates that a chunk ID is safe to use
 func (cs *ChunkSerializer) validateChunkID(chunkID string) error {
 	if chunkID == "" {
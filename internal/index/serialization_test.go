@@ -1,12 +1,16 @@
 package index
 
 import (
+	"bytes"
+	"fmt"
 	"os"
 	"path/filepath"
 	"testing"
 	"time"
 
 	"repository-context-protocol/internal/models"
+
+	"github.com/vmihailenco/msgpack/v5"
 )
 
 func TestChunkSerializer_SaveAndLoadChunk(t *testing.T) {
@@ -353,3 +357,91 @@ func TestChunkSerializer_InvalidBaseDir(t *testing.T) {
 		t.Error("Expected error when saving to invalid directory")
 	}
 }
+
+// newCompressionTestChunk returns a chunk large enough for compression to pay off
+func newCompressionTestChunk(id string) models.SemanticChunk {
+	fileContext := models.FileContext{Path: "service.go", Language: "go"}
+	for i := 0; i < 50; i++ {
+		fileContext.Functions = append(fileContext.Functions, models.Function{
+			Name:      fmt.Sprintf("HandleRequest%d", i),
+			Signature: fmt.Sprintf("func HandleRequest%d(ctx context.Context, req *Request) (*Response, error)", i),
+			StartLine: i * 10,
+			EndLine:   i*10 + 8,
+			Calls:     []string{"validate", "store.Save", "log.Printf"},
+		})
+	}
+	return models.SemanticChunk{ID: id, Files: []string{fileContext.Path}, FileData: []models.FileContext{fileContext}}
+}
+
+func TestChunkSerializer_Compression(t *testing.T) {
+	serializer := NewChunkSerializer(t.TempDir())
+
+	plain := newCompressionTestChunk("chunk_plain")
+	if err := serializer.SaveChunk(&plain); err != nil {
+		t.Fatalf("Failed to save chunk: %v", err)
+	}
+	serializer.SetCompression(true)
+	compressed := newCompressionTestChunk("chunk_compressed")
+	if err := serializer.SaveChunk(&compressed); err != nil {
+		t.Fatalf("Failed to save compressed chunk: %v", err)
+	}
+
+	data, err := os.ReadFile(serializer.GetChunkPath(compressed.ID))
+	if err != nil {
+		t.Fatalf("Failed to read chunk file: %v", err)
+	}
+	if !bytes.HasPrefix(data, gzipMagic) {
+		t.Error("Expected the compressed chunk file to start with the gzip marker")
+	}
+
+	// Both formats load whatever the current setting
+	for _, chunkID := range []string{plain.ID, compressed.ID} {
+		loaded, err := serializer.LoadChunk(chunkID)
+		if err != nil {
+			t.Fatalf("Failed to load %s: %v", chunkID, err)
+		}
+		if len(loaded.FileData) != 1 || len(loaded.FileData[0].Functions) != 50 {
+			t.Errorf("Expected %s to round-trip its 50 functions, got %+v", chunkID, loaded.FileData)
+		}
+	}
+
+	plainStored, plainLogical, err := serializer.ChunkSize(plain.ID)
+	if err != nil {
+		t.Fatalf("Failed to size chunk: %v", err)
+	}
+	if plainStored != plainLogical {
+		t.Errorf("Expected an uncompressed chunk to have equal sizes, got %d and %d", plainStored, plainLogical)
+	}
+	serialized, err := msgpack.Marshal(&compressed)
+	if err != nil {
+		t.Fatalf("Failed to marshal chunk: %v", err)
+	}
+	stored, logical, err := serializer.ChunkSize(compressed.ID)
+	if err != nil {
+		t.Fatalf("Failed to size chunk: %v", err)
+	}
+	if logical != int64(len(serialized)) || stored*2 > logical {
+		t.Errorf("Expected the compressed chunk to take at most half of its %d bytes, got %d of %d", len(serialized), stored, logical)
+	}
+}
+
+// BenchmarkChunkSerializer_LoadChunk measures the read latency compression adds to queries
+func BenchmarkChunkSerializer_LoadChunk(b *testing.B) {
+	for _, compress := range []bool{false, true} {
+		b.Run(fmt.Sprintf("compressed=%t", compress), func(b *testing.B) {
+			serializer := NewChunkSerializer(b.TempDir())
+			serializer.SetCompression(compress)
+			chunk := newCompressionTestChunk("chunk_benchmark")
+			if err := serializer.SaveChunk(&chunk); err != nil {
+				b.Fatalf("Failed to save chunk: %v", err)
+			}
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := serializer.LoadChunk(chunk.ID); err != nil {
+					b.Fatalf("Failed to load chunk: %v", err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,242 @@
+package index
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+const (
+	// SnapshotsDirName is the directory of a .repocontext directory holding named snapshots
+	SnapshotsDirName = "snapshots"
+
+	// WriteLockFileName marks a .repocontext directory as open for writes by an IndexBuilder
+	WriteLockFileName = "write.lock"
+)
+
+// SaveSnapshot copies the index of a .repocontext directory, its database, chunks and
+// manifest, into a snapshot called name under its snapshots directory, so a risky
+// rebuild can be rolled back with RestoreSnapshot. Existing snapshots are not replaced.
+func SaveSnapshot(repoContextDir, name string) error {
+	snapshotDir, err := snapshotPath(repoContextDir, name)
+	if err != nil {
+		return err
+	}
+	if err := checkWriteLock(repoContextDir); err != nil {
+		return err
+	}
+	if _, err := os.Stat(snapshotDir); err == nil {
+		return fmt.Errorf("snapshot %q already exists", name)
+	}
+
+	if err := SnapshotIndex(repoContextDir, snapshotDir); err != nil {
+		os.RemoveAll(snapshotDir)
+		return fmt.Errorf("failed to save snapshot %q: %w", name, err)
+	}
+	return nil
+}
+
+// RestoreSnapshot replaces the index of a .repocontext directory with the snapshot called
+// name. It holds the write lock throughout, so it refuses while an IndexBuilder has the
+// directory open for writes and no build starts during the restore. The snapshot is staged
+// next to the index first and swapped in with swapIndex, so a failure leaves the index
+// untouched; readers holding the index open keep seeing the replaced data until they
+// reopen it.
+func RestoreSnapshot(repoContextDir, name string) (err error) {
+	snapshotDir, err := snapshotPath(repoContextDir, name)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(filepath.Join(snapshotDir, "manifest.json")); err != nil {
+		return fmt.Errorf("snapshot %q not found", name)
+	}
+	if err := acquireWriteLock(repoContextDir); err != nil {
+		return err
+	}
+	defer func() { err = errors.Join(err, releaseWriteLock(repoContextDir)) }()
+
+	stagingDir := filepath.Join(repoContextDir, "restore.tmp")
+	if err := os.RemoveAll(stagingDir); err != nil {
+		return fmt.Errorf("failed to clear %s: %w", stagingDir, err)
+	}
+	if err := SnapshotIndex(snapshotDir, stagingDir); err != nil {
+		os.RemoveAll(stagingDir)
+		return fmt.Errorf("failed to restore snapshot %q: %w", name, err)
+	}
+
+	keepStaging, err := swapIndex(repoContextDir, stagingDir, os.Rename)
+	if !keepStaging {
+		os.RemoveAll(stagingDir)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to restore snapshot %q: %w", name, err)
+	}
+	return nil
+}
+
+// indexFiles are the files and directories of a .repocontext directory making up its index
+var indexFiles = []string{"chunks", "index.db", "manifest.json"}
+
+// swapIndex moves the index files staged in stagingDir into repoContextDir, first moving
+// the current ones aside into stagingDir/previous. When a move fails the moves made so far
+// are undone in reverse, so the index is either wholly replaced or left as it was. Only
+// when undoing fails too is the previous index left in stagingDir, and keepStaging set so
+// the caller does not delete it.
+func swapIndex(repoContextDir, stagingDir string, rename func(oldPath, newPath string) error) (keepStaging bool, err error) {
+	previousDir := filepath.Join(stagingDir, "previous")
+	if err := os.MkdirAll(previousDir, dirPermissions); err != nil {
+		return false, fmt.Errorf("failed to create %s: %w", previousDir, err)
+	}
+
+	var undo []func() error
+	move := func(from, to string) error {
+		if err := rename(from, to); err != nil {
+			return err
+		}
+		undo = append(undo, func() error { return rename(to, from) })
+		return nil
+	}
+	swap := func() error {
+		for _, name := range indexFiles {
+			current := filepath.Join(repoContextDir, name)
+			if _, err := os.Lstat(current); os.IsNotExist(err) {
+				continue
+			}
+			if err := move(current, filepath.Join(previousDir, name)); err != nil {
+				return fmt.Errorf("failed to move %s aside: %w", name, err)
+			}
+		}
+		for _, name := range indexFiles {
+			if err := move(filepath.Join(stagingDir, name), filepath.Join(repoContextDir, name)); err != nil {
+				return fmt.Errorf("failed to restore %s: %w", name, err)
+			}
+		}
+		return nil
+	}
+
+	if err := swap(); err != nil {
+		for i := len(undo) - 1; i >= 0; i-- {
+			if undoErr := undo[i](); undoErr != nil {
+				return true, fmt.Errorf("%w; rolling back also failed, leaving the previous index in %s: %w", err, previousDir, undoErr)
+			}
+		}
+		return false, err
+	}
+	return false, nil
+}
+
+// ListSnapshots returns the names of the snapshots of a .repocontext directory, sorted
+func ListSnapshots(repoContextDir string) ([]string, error) {
+	entries, err := os.ReadDir(filepath.Join(repoContextDir, SnapshotsDirName))
+	if os.IsNotExist(err) {
+		return []string{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshots: %w", err)
+	}
+
+	names := []string{}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			names = append(names, entry.Name())
+		}
+	}
+	return names, nil
+}
+
+// snapshotPath validates a snapshot name and returns its directory
+func snapshotPath(repoContextDir, name string) (string, error) {
+	if name == "" || name == "." || name == ".." || strings.ContainsAny(name, `/\`) {
+		return "", fmt.Errorf("invalid snapshot name %q", name)
+	}
+	return filepath.Join(repoContextDir, SnapshotsDirName, name), nil
+}
+
+// acquireWriteLock records that the current process has a .repocontext directory open
+// for writes. The lock is created exclusively, so it fails while another live process or
+// builder holds it; a lock left by a process that is no longer running, such as a build
+// killed mid-way, is stale and taken over. Only SaveSnapshot, RestoreSnapshot and
+// CompactIndex check it.
+func acquireWriteLock(repoContextDir string) error {
+	lockPath := filepath.Join(repoContextDir, WriteLockFileName)
+	for {
+		file, err := os.OpenFile(lockPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, filePermissions) // #nosec G304 - Path is inside the index directory
+		if err == nil {
+			_, err = file.WriteString(strconv.Itoa(os.Getpid()))
+			if closeErr := file.Close(); err == nil {
+				err = closeErr
+			}
+			if err != nil {
+				return fmt.Errorf("failed to create write lock: %w", err)
+			}
+			return nil
+		}
+		if !os.IsExist(err) {
+			return fmt.Errorf("failed to create write lock: %w", err)
+		}
+
+		if err := checkWriteLock(repoContextDir); err != nil {
+			return err
+		}
+		if err := os.Remove(lockPath); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove stale write lock: %w", err)
+		}
+	}
+}
+
+// releaseWriteLock removes the write lock of a .repocontext directory when the current
+// process holds it, leaving a lock another process took over in place
+func releaseWriteLock(repoContextDir string) error {
+	lockPath := filepath.Join(repoContextDir, WriteLockFileName)
+	if pid, err := writeLockHolder(lockPath); err != nil || pid != os.Getpid() {
+		return err
+	}
+	if err := os.Remove(lockPath); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove write lock: %w", err)
+	}
+	return nil
+}
+
+// checkWriteLock fails when a .repocontext directory is open for writes by a running
+// process. A lock whose process is gone is stale and ignored.
+func checkWriteLock(repoContextDir string) error {
+	lockPath := filepath.Join(repoContextDir, WriteLockFileName)
+	pid, err := writeLockHolder(lockPath)
+	if err != nil || pid == 0 || !processRunning(pid) {
+		return err
+	}
+	return fmt.Errorf("index is open for writes by process %d; remove %s if no build is running", pid, lockPath)
+}
+
+// writeLockHolder returns the process recorded in a write lock, or zero when there is no
+// lock or it records no process, as when its writer died before writing it
+func writeLockHolder(lockPath string) (int, error) {
+	content, err := os.ReadFile(lockPath) // #nosec G304 - Path is inside the index directory
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to read write lock: %w", err)
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(content)))
+	if err != nil || pid <= 0 {
+		return 0, nil
+	}
+	return pid, nil
+}
+
+// processRunning reports whether a process is running. Signal 0 only checks the process
+// exists; where it cannot be sent, as on Windows, a process that can be found counts as
+// running, so a lock is only ever taken over from a process known to be gone.
+func processRunning(pid int) bool {
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	err = process.Signal(syscall.Signal(0))
+	return !errors.Is(err, os.ErrProcessDone) && !errors.Is(err, syscall.ESRCH)
+}
@@ -0,0 +1,272 @@
+package index
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"repository-context-protocol/internal/models"
+)
+
+// storeSnapshotTestFile stores a file declaring function in the index at baseDir
+func storeSnapshotTestFile(t *testing.T, baseDir, path, function string) {
+	t.Helper()
+
+	storage := NewHybridStorage(baseDir)
+	if err := storage.Initialize(); err != nil {
+		t.Fatalf("Failed to initialize storage: %v", err)
+	}
+	defer storage.Close()
+
+	err := storage.StoreFileContext(&models.FileContext{
+		Path: path, Language: "go", Checksum: function,
+		Functions: []models.Function{{Name: function, Signature: "func " + function + "()", StartLine: 1, EndLine: 3}},
+	})
+	if err != nil {
+		t.Fatalf("Failed to store file context: %v", err)
+	}
+}
+
+func TestSaveAndRestoreSnapshot(t *testing.T) {
+	baseDir := t.TempDir()
+	storeSnapshotTestFile(t, baseDir, "main.go", "main")
+
+	if err := SaveSnapshot(baseDir, "before"); err != nil {
+		t.Fatalf("Failed to save snapshot: %v", err)
+	}
+	if err := SaveSnapshot(baseDir, "before"); err == nil {
+		t.Error("Expected saving over an existing snapshot to fail")
+	}
+
+	storeSnapshotTestFile(t, baseDir, "extra.go", "experiment")
+
+	if err := RestoreSnapshot(baseDir, "before"); err != nil {
+		t.Fatalf("Failed to restore snapshot: %v", err)
+	}
+
+	storage := NewHybridStorage(baseDir)
+	if err := storage.Initialize(); err != nil {
+		t.Fatalf("Failed to open restored index: %v", err)
+	}
+	defer storage.Close()
+
+	if results, err := storage.QueryByName("main"); err != nil || len(results) != 1 {
+		t.Errorf("Expected restored index to contain main, got %d results (err: %v)", len(results), err)
+	}
+	if results, err := storage.QueryByName("experiment"); err != nil || len(results) != 0 {
+		t.Errorf("Expected restored index to drop experiment, got %d results (err: %v)", len(results), err)
+	}
+	if _, err := os.Stat(filepath.Join(baseDir, "restore.tmp")); !os.IsNotExist(err) {
+		t.Error("Expected the staging directory to be removed")
+	}
+
+	names, err := ListSnapshots(baseDir)
+	if err != nil || !slices.Equal(names, []string{"before"}) {
+		t.Errorf("Expected snapshots [before], got %v (err: %v)", names, err)
+	}
+}
+
+func TestRestoreSnapshot_Errors(t *testing.T) {
+	baseDir := t.TempDir()
+	storeSnapshotTestFile(t, baseDir, "main.go", "main")
+
+	for _, name := range []string{"", ".", "..", "a/b", `a\b`} {
+		if err := SaveSnapshot(baseDir, name); err == nil {
+			t.Errorf("Expected snapshot name %q to be rejected", name)
+		}
+	}
+	if err := RestoreSnapshot(baseDir, "missing"); err == nil {
+		t.Error("Expected restoring a missing snapshot to fail")
+	}
+
+	if err := SaveSnapshot(baseDir, "before"); err != nil {
+		t.Fatalf("Failed to save snapshot: %v", err)
+	}
+	if err := acquireWriteLock(baseDir); err != nil {
+		t.Fatalf("Failed to acquire write lock: %v", err)
+	}
+	if err := RestoreSnapshot(baseDir, "before"); err == nil {
+		t.Error("Expected restore to be refused while the index is open for writes")
+	}
+	if err := releaseWriteLock(baseDir); err != nil {
+		t.Fatalf("Failed to release write lock: %v", err)
+	}
+	if err := RestoreSnapshot(baseDir, "before"); err != nil {
+		t.Errorf("Expected restore to succeed once the lock is released, got: %v", err)
+	}
+}
+
+func TestIndexBuilder_WriteLock(t *testing.T) {
+	rootPath := t.TempDir()
+	builder := NewIndexBuilder(rootPath)
+	if err := builder.Initialize(); err != nil {
+		t.Fatalf("Failed to initialize builder: %v", err)
+	}
+
+	lockPath := filepath.Join(rootPath, ".repocontext", WriteLockFileName)
+	if _, err := os.Stat(lockPath); err != nil {
+		t.Errorf("Expected write lock while the builder is open: %v", err)
+	}
+	if err := builder.Close(); err != nil {
+		t.Fatalf("Failed to close builder: %v", err)
+	}
+	if _, err := os.Stat(lockPath); !os.IsNotExist(err) {
+		t.Error("Expected write lock to be removed on close")
+	}
+}
+
+// exitedProcessID returns the ID of a process that has run and exited
+func exitedProcessID(t *testing.T) int {
+	t.Helper()
+	cmd := exec.Command(os.Args[0], "-test.run=^$")
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("Failed to run a short-lived process: %v", err)
+	}
+	return cmd.Process.Pid
+}
+
+func TestWriteLock_StaleLockIsTakenOver(t *testing.T) {
+	rootPath := t.TempDir()
+	repoContextDir := filepath.Join(rootPath, ".repocontext")
+	storeSnapshotTestFile(t, repoContextDir, "main.go", "main")
+
+	// A build killed mid-way leaves its lock behind
+	lockPath := filepath.Join(repoContextDir, WriteLockFileName)
+	if err := os.WriteFile(lockPath, []byte(strconv.Itoa(exitedProcessID(t))), 0600); err != nil {
+		t.Fatalf("Failed to write stale lock: %v", err)
+	}
+	if err := SaveSnapshot(repoContextDir, "after-crash"); err != nil {
+		t.Errorf("Expected a stale lock not to block snapshots, got: %v", err)
+	}
+
+	builder := NewIndexBuilder(rootPath)
+	if err := builder.Initialize(); err != nil {
+		t.Fatalf("Expected the builder to take over a stale lock, got: %v", err)
+	}
+	if content, err := os.ReadFile(lockPath); err != nil || string(content) != strconv.Itoa(os.Getpid()) {
+		t.Errorf("Expected the lock to record this process, got %q (err: %v)", content, err)
+	}
+	if err := builder.Close(); err != nil {
+		t.Fatalf("Failed to close builder: %v", err)
+	}
+	if _, err := os.Stat(lockPath); !os.IsNotExist(err) {
+		t.Error("Expected write lock to be removed on close")
+	}
+}
+
+func TestWriteLock_ConcurrentBuilders(t *testing.T) {
+	rootPath := t.TempDir()
+	first := NewIndexBuilder(rootPath)
+	if err := first.Initialize(); err != nil {
+		t.Fatalf("Failed to initialize builder: %v", err)
+	}
+	defer first.Close()
+
+	second := NewIndexBuilder(rootPath)
+	if err := second.Initialize(); err == nil || !strings.Contains(err.Error(), "open for writes") {
+		second.Close()
+		t.Fatalf("Expected a second builder to be refused while the first is open, got %v", err)
+	}
+
+	// The refused builder leaves the first builder's lock in place
+	repoContextDir := filepath.Join(rootPath, ".repocontext")
+	if err := SaveSnapshot(repoContextDir, "during-build"); err == nil {
+		t.Error("Expected snapshots to be refused while the first builder is open")
+	}
+	if err := first.Close(); err != nil {
+		t.Fatalf("Failed to close builder: %v", err)
+	}
+	if err := SaveSnapshot(repoContextDir, "after-build"); err != nil {
+		t.Errorf("Expected snapshots once the builder is closed, got: %v", err)
+	}
+}
+
+// writeIndexFiles writes index files holding content, as swapIndex moves them
+func writeIndexFiles(t *testing.T, dir, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Join(dir, "chunks"), 0755); err != nil {
+		t.Fatalf("Failed to create chunks directory: %v", err)
+	}
+	for _, file := range []string{filepath.Join("chunks", "chunk_1.msgpack"), "index.db", "manifest.json"} {
+		if err := os.WriteFile(filepath.Join(dir, file), []byte(content), 0600); err != nil {
+			t.Fatalf("Failed to write %s: %v", file, err)
+		}
+	}
+}
+
+// assertIndexFiles checks every index file in dir holds content
+func assertIndexFiles(t *testing.T, dir, content string) {
+	t.Helper()
+	for _, file := range []string{filepath.Join("chunks", "chunk_1.msgpack"), "index.db", "manifest.json"} {
+		if data, err := os.ReadFile(filepath.Join(dir, file)); err != nil || string(data) != content {
+			t.Errorf("Expected %s to hold %q, got %q (err: %v)", file, content, data, err)
+		}
+	}
+}
+
+func TestSwapIndex_RollsBackFailedSwap(t *testing.T) {
+	repoContextDir := t.TempDir()
+	stagingDir := filepath.Join(repoContextDir, "restore.tmp")
+	writeIndexFiles(t, repoContextDir, "current")
+	writeIndexFiles(t, stagingDir, "snapshot")
+
+	// The chunks are already swapped when moving the database in fails
+	failed := false
+	failing := func(oldPath, newPath string) error {
+		if !failed && oldPath == filepath.Join(stagingDir, "index.db") {
+			failed = true
+			return os.ErrPermission
+		}
+		return os.Rename(oldPath, newPath)
+	}
+	keepStaging, err := swapIndex(repoContextDir, stagingDir, failing)
+	if err == nil || !strings.Contains(err.Error(), "failed to restore index.db") {
+		t.Errorf("Expected the failed move to be reported, got %v", err)
+	}
+	if keepStaging {
+		t.Error("Expected the staging directory not to be needed after a rollback")
+	}
+	assertIndexFiles(t, repoContextDir, "current")
+
+	// When moving the database back fails too, the previous index is kept in the staging directory
+	stuck := func(oldPath, newPath string) error {
+		if newPath == filepath.Join(repoContextDir, "index.db") {
+			return os.ErrPermission
+		}
+		return os.Rename(oldPath, newPath)
+	}
+	keepStaging, err = swapIndex(repoContextDir, stagingDir, stuck)
+	if err == nil || !strings.Contains(err.Error(), "rolling back also failed") {
+		t.Errorf("Expected the failed rollback to be reported, got %v", err)
+	}
+	if !keepStaging {
+		t.Error("Expected the staging directory to be kept when the rollback fails")
+	}
+	if data, err := os.ReadFile(filepath.Join(stagingDir, "previous", "index.db")); err != nil || string(data) != "current" {
+		t.Errorf("Expected the previous database to be kept aside, got %q (err: %v)", data, err)
+	}
+}
+
+func TestRestoreSnapshot_HoldsWriteLock(t *testing.T) {
+	baseDir := t.TempDir()
+	storeSnapshotTestFile(t, baseDir, "main.go", "main")
+	if err := SaveSnapshot(baseDir, "before"); err != nil {
+		t.Fatalf("Failed to save snapshot: %v", err)
+	}
+
+	// A stale lock does not block the restore, which releases the lock it takes over
+	lockPath := filepath.Join(baseDir, WriteLockFileName)
+	if err := os.WriteFile(lockPath, []byte(strconv.Itoa(exitedProcessID(t))), 0600); err != nil {
+		t.Fatalf("Failed to write stale lock: %v", err)
+	}
+	if err := RestoreSnapshot(baseDir, "before"); err != nil {
+		t.Fatalf("Expected restore to take over a stale lock, got: %v", err)
+	}
+	if _, err := os.Stat(lockPath); !os.IsNotExist(err) {
+		t.Errorf("Expected the restore to release the write lock, got %v", err)
+	}
+}
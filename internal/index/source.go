@@ -0,0 +1,60 @@
+package index
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"strings"
+
+	"repository-context-protocol/internal/models"
+)
+
+// Implementation source constants
+const (
+	SourceIndex       = "index"        // Lines come from source stored in the index
+	SourceWorkingTree = "working_tree" // Lines come from the file on disk
+)
+
+// compressSource gzips file content for storage in a file context
+func compressSource(content []byte) ([]byte, error) {
+	var buffer bytes.Buffer
+	writer := gzip.NewWriter(&buffer)
+	if _, err := writer.Write(content); err != nil {
+		return nil, fmt.Errorf("failed to compress source: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("failed to compress source: %w", err)
+	}
+	return buffer.Bytes(), nil
+}
+
+// storedSource returns the uncompressed source kept in a file context, if any
+func storedSource(fileContext *models.FileContext) ([]byte, error) {
+	if len(fileContext.Source) == 0 {
+		return nil, nil
+	}
+
+	reader, err := gzip.NewReader(bytes.NewReader(fileContext.Source))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress source of %s: %w", fileContext.Path, err)
+	}
+	defer reader.Close()
+
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress source of %s: %w", fileContext.Path, err)
+	}
+	return content, nil
+}
+
+// sourceChecksum computes the checksum parsers record for file content
+func sourceChecksum(content []byte) string {
+	return fmt.Sprintf("%x", sha256.Sum256(content))
+}
+
+// splitSourceLines splits file content into lines for line-based extraction
+func splitSourceLines(content []byte) []string {
+	return strings.Split(string(content), "\n")
+}
@@ -58,12 +58,20 @@ func (si *SQLiteIndex) createTables() error {
 		end_line INTEGER NOT NULL,
 		chunk_id TEXT NOT NULL,
 		signature TEXT,
+		language TEXT NOT NULL DEFAULT '',
+		token_count INTEGER NOT NULL DEFAULT 0,
 		FOREIGN KEY (chunk_id) REFERENCES chunks(chunk_id) ON DELETE CASCADE
 	);`
 
 	if _, err := si.db.Exec(indexEntriesSQL); err != nil {
 		return fmt.Errorf("failed to create index_entries table: %w", err)
 	}
+	if err := si.addColumnIfMissing("index_entries", "language", "TEXT NOT NULL DEFAULT ''"); err != nil {
+		return err
+	}
+	if err := si.addColumnIfMissing("index_entries", "token_count", "INTEGER NOT NULL DEFAULT 0"); err != nil {
+		return err
+	}
 
 	// Create call_relations table
 	callRelationsSQL := `
@@ -93,12 +101,26 @@ func (si *SQLiteIndex) createTables() error {
 		return fmt.Errorf("failed to create chunks table: %w", err)
 	}
 
+	// Create the single-row index_state table recording the last write
+	indexStateSQL := `
+	CREATE TABLE IF NOT EXISTS index_state (
+		id INTEGER PRIMARY KEY CHECK (id = 1),
+		generation INTEGER NOT NULL DEFAULT 0,
+		updated_at DATETIME
+	);
+	INSERT OR IGNORE INTO index_state (id, generation) VALUES (1, 0);`
+
+	if _, err := si.db.Exec(indexStateSQL); err != nil {
+		return fmt.Errorf("failed to create index_state table: %w", err)
+	}
+
 	// Create indexes for better performance
 	indexes := []string{
 		"CREATE INDEX IF NOT EXISTS idx_index_entries_name ON index_entries(name);",
 		"CREATE INDEX IF NOT EXISTS idx_index_entries_type ON index_entries(type);",
 		"CREATE INDEX IF NOT EXISTS idx_index_entries_file ON index_entries(file_path);",
 		"CREATE INDEX IF NOT EXISTS idx_index_entries_chunk ON index_entries(chunk_id);",
+		"CREATE INDEX IF NOT EXISTS idx_index_entries_language ON index_entries(language);",
 		"CREATE INDEX IF NOT EXISTS idx_call_relations_caller ON call_relations(caller);",
 		"CREATE INDEX IF NOT EXISTS idx_call_relations_callee ON call_relations(callee);",
 		"CREATE INDEX IF NOT EXISTS idx_chunks_created_at ON chunks(created_at);",
@@ -113,13 +135,46 @@ func (si *SQLiteIndex) createTables() error {
 	return nil
 }
 
+// addColumnIfMissing adds a column to a table created by an older version of the schema
+func (si *SQLiteIndex) addColumnIfMissing(table, column, definition string) error {
+	rows, err := si.db.Query(fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return fmt.Errorf("failed to inspect %s table: %w", table, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			cid          int
+			name, kind   string
+			notNull, pk  int
+			defaultValue sql.NullString
+		)
+		if err := rows.Scan(&cid, &name, &kind, &notNull, &defaultValue, &pk); err != nil {
+			return fmt.Errorf("failed to inspect %s table: %w", table, err)
+		}
+		if name == column {
+			return nil
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to inspect %s table: %w", table, err)
+	}
+
+	if _, err := si.db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition)); err != nil {
+		return fmt.Errorf("failed to add %s.%s column: %w", table, column, err)
+	}
+	return nil
+}
+
 // InsertIndexEntry inserts a new index entry into the database
 func (si *SQLiteIndex) InsertIndexEntry(entry *models.IndexEntry) error {
 	query := `
-	INSERT INTO index_entries (name, type, file_path, start_line, end_line, chunk_id, signature)
-	VALUES (?, ?, ?, ?, ?, ?, ?)`
+	INSERT INTO index_entries (name, type, file_path, start_line, end_line, chunk_id, signature, language, token_count)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`
 
-	_, err := si.db.Exec(query, entry.Name, entry.Type, entry.File, entry.StartLine, entry.EndLine, entry.ChunkID, entry.Signature)
+	_, err := si.db.Exec(query,
+		entry.Name, entry.Type, entry.File, entry.StartLine, entry.EndLine, entry.ChunkID, entry.Signature, entry.Language, entry.TokenCount)
 	if err != nil {
 		return fmt.Errorf("failed to insert index entry: %w", err)
 	}
@@ -132,7 +187,10 @@ func (si *SQLiteIndex) scanIndexEntries(rows *sql.Rows) ([]models.IndexEntry, er
 	var entries []models.IndexEntry
 	for rows.Next() {
 		var entry models.IndexEntry
-		err := rows.Scan(&entry.Name, &entry.Type, &entry.File, &entry.StartLine, &entry.EndLine, &entry.ChunkID, &entry.Signature)
+		err := rows.Scan(
+			&entry.Name, &entry.Type, &entry.File, &entry.StartLine, &entry.EndLine, &entry.ChunkID, &entry.Signature, &entry.Language,
+			&entry.TokenCount,
+		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan index entry: %w", err)
 		}
@@ -150,7 +208,7 @@ func (si *SQLiteIndex) scanIndexEntries(rows *sql.Rows) ([]models.IndexEntry, er
 // QueryIndexEntries queries index entries by name
 func (si *SQLiteIndex) QueryIndexEntries(name string) ([]models.IndexEntry, error) {
 	query := `
-	SELECT name, type, file_path, start_line, end_line, chunk_id, signature
+	SELECT name, type, file_path, start_line, end_line, chunk_id, signature, language, token_count
 	FROM index_entries
 	WHERE name = ?`
 
@@ -163,12 +221,29 @@ func (si *SQLiteIndex) QueryIndexEntries(name string) ([]models.IndexEntry, erro
 	return si.scanIndexEntries(rows)
 }
 
+// QueryIndexEntriesCaseInsensitive queries index entries by name ignoring ASCII case
+func (si *SQLiteIndex) QueryIndexEntriesCaseInsensitive(name string) ([]models.IndexEntry, error) {
+	query := `
+	SELECT name, type, file_path, start_line, end_line, chunk_id, signature, language, token_count
+	FROM index_entries
+	WHERE name = ? COLLATE NOCASE`
+
+	rows, err := si.db.Query(query, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query index entries: %w", err)
+	}
+	defer rows.Close()
+
+	return si.scanIndexEntries(rows)
+}
+
 // QueryIndexEntriesByType queries index entries by type
 func (si *SQLiteIndex) QueryIndexEntriesByType(entryType string) ([]models.IndexEntry, error) {
 	query := `
-	SELECT name, type, file_path, start_line, end_line, chunk_id, signature
+	SELECT name, type, file_path, start_line, end_line, chunk_id, signature, language, token_count
 	FROM index_entries
-	WHERE type = ?`
+	WHERE type = ?
+	ORDER BY file_path, start_line, name`
 
 	rows, err := si.db.Query(query, entryType)
 	if err != nil {
@@ -179,6 +254,25 @@ func (si *SQLiteIndex) QueryIndexEntriesByType(entryType string) ([]models.Index
 	return si.scanIndexEntries(rows)
 }
 
+// QueryNameKinds returns every distinct name and entry type pair in the index
+func (si *SQLiteIndex) QueryNameKinds() ([]models.IndexEntry, error) {
+	rows, err := si.db.Query(`SELECT DISTINCT name, type FROM index_entries`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query names: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []models.IndexEntry
+	for rows.Next() {
+		var entry models.IndexEntry
+		if err := rows.Scan(&entry.Name, &entry.Type); err != nil {
+			return nil, fmt.Errorf("failed to scan name: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
+
 // InsertCallRelation inserts a new call relation into the database
 func (si *SQLiteIndex) InsertCallRelation(relation models.CallRelation) error {
 	query := `
@@ -245,6 +339,45 @@ func (si *SQLiteIndex) QueryCallsTo(callee string) ([]models.CallRelation, error
 	return si.scanCallRelations(rows)
 }
 
+// QueryHotspots ranks indexed functions by the number of distinct functions calling them,
+// or by the number of distinct functions they call when byCallees is set. Calls are
+// recorded by name, so same-named functions share their caller count.
+func (si *SQLiteIndex) QueryHotspots(byCallees bool, limit int) ([]HotspotEntry, error) {
+	rankBy, tieBreak := "callers", "callees"
+	if byCallees {
+		rankBy, tieBreak = "callees", "callers"
+	}
+	query := `
+	SELECT name, file_path, start_line, callers, callees FROM (
+		SELECT e.name, e.file_path, e.start_line,
+			(SELECT COUNT(DISTINCT r.caller_file || ':' || r.caller) FROM call_relations r
+				WHERE r.callee = e.name) AS callers,
+			(SELECT COUNT(DISTINCT r.callee) FROM call_relations r
+				WHERE r.caller = e.name AND r.caller_file = e.file_path) AS callees
+		FROM index_entries e
+		WHERE e.type = 'function'
+	)
+	WHERE ` + rankBy + ` > 0
+	ORDER BY ` + rankBy + ` DESC, ` + tieBreak + ` DESC, name, file_path, start_line
+	LIMIT ?`
+
+	rows, err := si.db.Query(query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query hotspots: %w", err)
+	}
+	defer rows.Close()
+
+	hotspots := []HotspotEntry{}
+	for rows.Next() {
+		var hotspot HotspotEntry
+		if err := rows.Scan(&hotspot.Function, &hotspot.File, &hotspot.Line, &hotspot.CallerCount, &hotspot.CalleeCount); err != nil {
+			return nil, fmt.Errorf("failed to scan hotspot: %w", err)
+		}
+		hotspots = append(hotspots, hotspot)
+	}
+	return hotspots, rows.Err()
+}
+
 // RegisterChunk registers a new chunk in the database
 func (si *SQLiteIndex) RegisterChunk(chunkID string, files []string, tokenCount int, createdAt time.Time) error {
 	filesStr := strings.Join(files, ",")
@@ -310,6 +443,71 @@ func (si *SQLiteIndex) DeleteChunk(chunkID string) error {
 	return nil
 }
 
+// QueryChunkIDs returns the IDs of all chunks registered or referenced by index entries
+func (si *SQLiteIndex) QueryChunkIDs() ([]string, error) {
+	rows, err := si.db.Query(`SELECT chunk_id FROM chunks UNION SELECT chunk_id FROM index_entries`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query chunk IDs: %w", err)
+	}
+	defer rows.Close()
+
+	var chunkIDs []string
+	for rows.Next() {
+		var chunkID string
+		if err := rows.Scan(&chunkID); err != nil {
+			return nil, fmt.Errorf("failed to scan chunk ID: %w", err)
+		}
+		chunkIDs = append(chunkIDs, chunkID)
+	}
+	return chunkIDs, rows.Err()
+}
+
+// BumpGeneration records a write to the index at now, advancing the generation readers
+// compare to detect stale data. Generations are nanosecond timestamps, or one more than
+// the last when the clock has not moved on, so an index recreated from scratch does not
+// repeat the generations of the one it replaced.
+func (si *SQLiteIndex) BumpGeneration(now time.Time) error {
+	_, err := si.db.Exec(`UPDATE index_state SET generation = MAX(generation + 1, ?), updated_at = ? WHERE id = 1`,
+		now.UnixNano(), now)
+	if err != nil {
+		return fmt.Errorf("failed to bump index generation: %w", err)
+	}
+	return nil
+}
+
+// IndexState returns the generation and time of the last write recorded with
+// BumpGeneration, as committed by any connection to the database. The time is zero
+// when nothing was written since the table was created.
+func (si *SQLiteIndex) IndexState() (generation uint64, updatedAt time.Time, err error) {
+	var updated sql.NullTime
+	err = si.db.QueryRow(`SELECT generation, updated_at FROM index_state WHERE id = 1`).Scan(&generation, &updated)
+	if err != nil {
+		return 0, time.Time{}, fmt.Errorf("failed to read index state: %w", err)
+	}
+	return generation, updated.Time, nil
+}
+
+// FreeBytes returns the size of the unused pages that a vacuum would reclaim
+func (si *SQLiteIndex) FreeBytes() (int64, error) {
+	var freePages, pageSize int64
+	if err := si.db.QueryRow("PRAGMA freelist_count").Scan(&freePages); err != nil {
+		return 0, fmt.Errorf("failed to read freelist count: %w", err)
+	}
+	if err := si.db.QueryRow("PRAGMA page_size").Scan(&pageSize); err != nil {
+		return 0, fmt.Errorf("failed to read page size: %w", err)
+	}
+	return freePages * pageSize, nil
+}
+
+// Vacuum rebuilds the database file, returning unused pages to the filesystem.
+// SQLite locking makes concurrent readers wait for the vacuum rather than fail.
+func (si *SQLiteIndex) Vacuum() error {
+	if _, err := si.db.Exec("VACUUM"); err != nil {
+		return fmt.Errorf("failed to vacuum database: %w", err)
+	}
+	return nil
+}
+
 // Close closes the database connection
 func (si *SQLiteIndex) Close() error {
 	if si.db != nil {
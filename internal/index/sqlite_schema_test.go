@@ -101,13 +101,15 @@ func TestSQLiteIndex_TableSchema(t *testing.T) {
 	defer rows.Close()
 
 	expectedColumns := map[string]bool{
-		"id":         false,
-		"name":       false,
-		"type":       false,
-		"file_path":  false,
-		"start_line": false,
-		"end_line":   false,
-		"chunk_id":   false,
+		"id":          false,
+		"name":        false,
+		"type":        false,
+		"file_path":   false,
+		"start_line":  false,
+		"end_line":    false,
+		"chunk_id":    false,
+		"language":    false,
+		"token_count": false,
 	}
 
 	for rows.Next() {
@@ -140,6 +142,46 @@ func TestSQLiteIndex_TableSchema(t *testing.T) {
 	}
 }
 
+func TestSQLiteIndex_AddsLanguageColumnToOldSchema(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+
+	// Create index_entries as it was before entries recorded their language
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	_, err = db.Exec(`CREATE TABLE index_entries (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		name TEXT NOT NULL,
+		type TEXT NOT NULL,
+		file_path TEXT NOT NULL,
+		start_line INTEGER NOT NULL,
+		end_line INTEGER NOT NULL,
+		chunk_id TEXT NOT NULL,
+		signature TEXT
+	);
+	INSERT INTO index_entries (name, type, file_path, start_line, end_line, chunk_id, signature)
+	VALUES ('Old', 'function', 'old.go', 1, 2, 'chunk', 'func Old()');`)
+	db.Close()
+	if err != nil {
+		t.Fatalf("Failed to create old schema: %v", err)
+	}
+
+	index := NewSQLiteIndex(dbPath)
+	if err := index.Initialize(); err != nil {
+		t.Fatalf("Failed to initialize database with old schema: %v", err)
+	}
+	defer index.Close()
+
+	entries, err := index.QueryIndexEntries("Old")
+	if err != nil {
+		t.Fatalf("Failed to query old entry: %v", err)
+	}
+	if len(entries) != 1 || entries[0].Language != "" || entries[0].TokenCount != 0 {
+		t.Errorf("Expected old entry with empty language and no token count, got %+v", entries)
+	}
+}
+
 func TestSQLiteIndex_Close(t *testing.T) {
 	// Create temporary directory for testing
 	tempDir, err := os.MkdirTemp("", "sqlite_test")
@@ -0,0 +1,119 @@
+package index
+
+import (
+	"strings"
+	"time"
+
+	"repository-context-protocol/internal/models"
+)
+
+// Storage is an index backend the QueryEngine searches. HybridStorage keeps the index
+// on disk and is the default; MemoryStorage keeps it in memory for tests and
+// ephemeral tooling.
+type Storage interface {
+	// Initialize prepares the storage for use
+	Initialize() error
+	// Close releases the storage's resources
+	Close() error
+	// StoreFileContext indexes a file, replacing any data stored for the same path
+	StoreFileContext(fileContext *models.FileContext) error
+
+	// QueryByName returns the entries with the given name
+	QueryByName(name string) ([]QueryResult, error)
+	// QueryByNameCaseInsensitive returns the entries whose name equals name ignoring ASCII case
+	QueryByNameCaseInsensitive(name string) ([]QueryResult, error)
+	// QueryByType returns the entries of the given entry type ordered by file, then start
+	// line, then name, so repeated queries list them identically and pages do not overlap
+	QueryByType(entryType string) ([]QueryResult, error)
+	// ListNameKinds returns every distinct name and entry type pair
+	ListNameKinds() ([]models.IndexEntry, error)
+
+	// QueryCallsFrom returns the calls made by the given function
+	QueryCallsFrom(functionName string) ([]models.CallRelation, error)
+	// QueryCallsTo returns the calls made to the given function
+	QueryCallsTo(functionName string) ([]models.CallRelation, error)
+	// QueryHotspots returns functions ranked by caller count, or by callee count when byCallees is set
+	QueryHotspots(byCallees bool, limit int) ([]HotspotEntry, error)
+
+	// ListFiles returns the sorted paths of all stored files
+	ListFiles() ([]string, error)
+	// GetFileContext returns the stored context of a single file
+	GetFileContext(filePath string) (*models.FileContext, error)
+	// Generation returns a counter that changes whenever the stored data may have changed
+	Generation() uint64
+	// UpdatedAt returns when the stored data was last written, zero when unknown
+	UpdatedAt() time.Time
+}
+
+// Both backends satisfy Storage
+var (
+	_ Storage = (*HybridStorage)(nil)
+	_ Storage = (*MemoryStorage)(nil)
+)
+
+// fileIndexEntries returns the index entries for the functions, types, variables and
+// constants of a file stored in the given chunk
+func fileIndexEntries(fileData *models.FileContext, chunkID string) []models.IndexEntry {
+	var entries []models.IndexEntry
+	entry := func(name, entryType string, startLine, endLine int, signature string) {
+		entries = append(entries, models.IndexEntry{
+			Name:       name,
+			Type:       entryType,
+			File:       fileData.Path,
+			Language:   fileData.Language,
+			StartLine:  startLine,
+			EndLine:    endLine,
+			ChunkID:    chunkID,
+			Signature:  signature,
+			TokenCount: entryTokenCount(DefaultTokenEstimator(), name, signature),
+		})
+	}
+
+	for i := range fileData.Functions {
+		function := &fileData.Functions[i]
+		entry(function.Name, "function", function.StartLine, function.EndLine, function.Signature)
+	}
+	for i := range fileData.Types {
+		typeDef := &fileData.Types[i]
+		entry(typeDef.Name, typeDef.Kind, typeDef.StartLine, typeDef.EndLine, buildTypeSignature(typeDef))
+	}
+	for i := range fileData.Variables {
+		variable := &fileData.Variables[i]
+		entry(variable.Name, "variable", variable.StartLine, variable.EndLine, "")
+	}
+	for i := range fileData.Constants {
+		constant := &fileData.Constants[i]
+		entry(constant.Name, "constant", constant.StartLine, constant.EndLine, "")
+	}
+	return entries
+}
+
+// fileCallRelations returns the calls made by the functions of a file
+func fileCallRelations(fileData *models.FileContext) []models.CallRelation {
+	var relations []models.CallRelation
+	for i := range fileData.Functions {
+		function := &fileData.Functions[i]
+		for _, call := range function.Calls {
+			relations = append(relations, models.CallRelation{
+				Caller:     function.Name,
+				Callee:     call,
+				File:       fileData.Path,
+				Line:       function.StartLine, // Use function start line as call line
+				CallerFile: fileData.Path,
+			})
+		}
+	}
+	return relations
+}
+
+// buildTypeSignature creates a signature string for a type definition
+func buildTypeSignature(typeDef *models.TypeDef) string {
+	name := typeDef.Name + models.FormatTypeParams(typeDef.TypeParams)
+	if len(typeDef.Embedded) == 0 {
+		// No inheritance - just return the class name
+		return name
+	}
+
+	// Include inheritance information: ClassName(BaseClass1, BaseClass2)
+	return name + "(" + strings.Join(typeDef.Embedded, ", ") + ")"
+}
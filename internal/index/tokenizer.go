@@ -0,0 +1,205 @@
+package index
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Token estimator constants
+const (
+	// DefaultCharsPerToken is the character-to-token ratio used by the heuristic estimator
+	DefaultCharsPerToken = 4
+
+	// TokenEstimatorHeuristic selects the character-ratio heuristic (default)
+	TokenEstimatorHeuristic = "heuristic"
+	// TokenEstimatorBPE selects the tiktoken-style BPE approximation
+	TokenEstimatorBPE = "bpe"
+
+	// bpeMaxPieceChars is the average length of a single BPE merge for ASCII word pieces
+	bpeMaxPieceChars = 4
+	// bpeMaxDigitRun mirrors tiktoken's pre-tokenizer which groups at most 3 digits
+	bpeMaxDigitRun = 3
+)
+
+// TokenEstimator estimates how many LLM tokens a piece of text will consume
+type TokenEstimator interface {
+	// Name returns the identifier used to select this estimator
+	Name() string
+	// EstimateTokens returns the estimated token count for text
+	EstimateTokens(text string) int
+}
+
+// CharRatioEstimator estimates tokens as a fixed number of characters per token.
+// This is the historical heuristic and remains the default estimator; with the default
+// ratio, index entries keep their historical estimate of a token per word of their name
+// and signature.
+type CharRatioEstimator struct {
+	CharsPerToken int
+}
+
+// Name implements TokenEstimator
+func (e *CharRatioEstimator) Name() string {
+	return TokenEstimatorHeuristic
+}
+
+// EstimateTokens implements TokenEstimator
+func (e *CharRatioEstimator) EstimateTokens(text string) int {
+	charsPerToken := e.CharsPerToken
+	if charsPerToken <= 0 {
+		charsPerToken = DefaultCharsPerToken
+	}
+	return len(text) / charsPerToken
+}
+
+// BPEEstimator approximates byte-pair-encoding tokenizers such as tiktoken.
+// It mimics the pre-tokenization step (splitting on letters, digits, whitespace
+// and punctuation, plus camelCase/snake_case boundaries) and then assumes each
+// piece merges into chunks of roughly bpeMaxPieceChars characters. Non-ASCII
+// runes are counted individually, which is much closer to real BPE behaviour
+// for non-English identifiers than a character ratio.
+type BPEEstimator struct{}
+
+// Name implements TokenEstimator
+func (e *BPEEstimator) Name() string {
+	return TokenEstimatorBPE
+}
+
+// EstimateTokens implements TokenEstimator
+func (e *BPEEstimator) EstimateTokens(text string) int {
+	tokens := 0
+	for _, piece := range splitBPEPieces(text) {
+		tokens += estimatePieceTokens(piece)
+	}
+	return tokens
+}
+
+// splitBPEPieces splits text into pre-tokenizer pieces
+func splitBPEPieces(text string) []string {
+	var pieces []string
+	start := 0
+	var prev rune
+	prevClass := bpeClassNone
+
+	for i, r := range text {
+		class := classifyBPERune(r)
+		boundary := class != prevClass ||
+			class == bpeClassPunct ||
+			class == bpeClassOther ||
+			(class == bpeClassLetter && unicode.IsUpper(r) && unicode.IsLower(prev)) ||
+			(class == bpeClassDigit && i-start >= bpeMaxDigitRun)
+
+		if boundary && i > start {
+			pieces = append(pieces, text[start:i])
+			start = i
+		}
+		prev = r
+		prevClass = class
+	}
+
+	if start < len(text) {
+		pieces = append(pieces, text[start:])
+	}
+	return pieces
+}
+
+// estimatePieceTokens estimates tokens for a single pre-tokenized piece
+func estimatePieceTokens(piece string) int {
+	r, _ := utf8.DecodeRuneInString(piece)
+	switch classifyBPERune(r) {
+	case bpeClassSpace:
+		// Runs of whitespace usually collapse into a single token
+		return 1
+	case bpeClassOther:
+		// Non-ASCII runes rarely merge; count each rune
+		return utf8.RuneCountInString(piece)
+	default:
+		return (len(piece) + bpeMaxPieceChars - 1) / bpeMaxPieceChars
+	}
+}
+
+// BPE rune classes used for pre-tokenization
+const (
+	bpeClassNone = iota
+	bpeClassLetter
+	bpeClassDigit
+	bpeClassSpace
+	bpeClassPunct
+	bpeClassOther
+)
+
+// classifyBPERune returns the pre-tokenizer class for a rune
+func classifyBPERune(r rune) int {
+	switch {
+	case r > unicode.MaxASCII:
+		return bpeClassOther
+	case unicode.IsLetter(r):
+		return bpeClassLetter
+	case unicode.IsDigit(r):
+		return bpeClassDigit
+	case unicode.IsSpace(r):
+		return bpeClassSpace
+	default:
+		return bpeClassPunct
+	}
+}
+
+// DefaultTokenEstimator returns the default character-ratio estimator
+func DefaultTokenEstimator() TokenEstimator {
+	return &CharRatioEstimator{CharsPerToken: DefaultCharsPerToken}
+}
+
+// isDefaultTokenEstimator reports whether estimator counts as DefaultTokenEstimator does
+func isDefaultTokenEstimator(estimator TokenEstimator) bool {
+	ratio, ok := estimator.(*CharRatioEstimator)
+	return ok && (ratio.CharsPerToken == DefaultCharsPerToken || ratio.CharsPerToken <= 0)
+}
+
+// NewTokenEstimator returns the estimator registered under name.
+// An empty name selects the default estimator.
+func NewTokenEstimator(name string) (TokenEstimator, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "", TokenEstimatorHeuristic:
+		return DefaultTokenEstimator(), nil
+	case TokenEstimatorBPE:
+		return &BPEEstimator{}, nil
+	default:
+		return nil, fmt.Errorf("unsupported token estimator: %s (must be one of: %s)",
+			name, strings.Join(SupportedTokenEstimators(), ", "))
+	}
+}
+
+// SupportedTokenEstimators returns the names accepted by NewTokenEstimator
+func SupportedTokenEstimators() []string {
+	return []string{TokenEstimatorHeuristic, TokenEstimatorBPE}
+}
+
+// TruncateToTokens returns the longest prefix of text that fits within maxTokens
+// according to estimator, and whether any truncation occurred.
+func TruncateToTokens(estimator TokenEstimator, text string, maxTokens int) (string, bool) {
+	if estimator.EstimateTokens(text) <= maxTokens {
+		return text, false
+	}
+	if maxTokens <= 0 {
+		return "", true
+	}
+
+	// Binary search over rune boundaries for the longest prefix within the budget
+	boundaries := make([]int, 0, len(text)+1)
+	for i := range text {
+		boundaries = append(boundaries, i)
+	}
+	boundaries = append(boundaries, len(text))
+
+	low, high := 0, len(boundaries)-1
+	for low < high {
+		mid := (low + high + 1) / 2
+		if estimator.EstimateTokens(text[:boundaries[mid]]) <= maxTokens {
+			low = mid
+		} else {
+			high = mid - 1
+		}
+	}
+	return text[:boundaries[low]], true
+}
@@ -0,0 +1,148 @@
+package index
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"repository-context-protocol/internal/models"
+)
+
+func TestCharRatioEstimator_EstimateTokens(t *testing.T) {
+	estimator := DefaultTokenEstimator()
+
+	if estimator.Name() != TokenEstimatorHeuristic {
+		t.Errorf("Expected default estimator %q, got %q", TokenEstimatorHeuristic, estimator.Name())
+	}
+
+	// Matches the historical CharsPerToken = 4 heuristic
+	if tokens := estimator.EstimateTokens(strings.Repeat("a", 40)); tokens != 10 {
+		t.Errorf("Expected 10 tokens for 40 chars, got %d", tokens)
+	}
+
+	// Zero ratio falls back to the default
+	zero := &CharRatioEstimator{}
+	if tokens := zero.EstimateTokens("abcdefgh"); tokens != 2 {
+		t.Errorf("Expected 2 tokens with default ratio, got %d", tokens)
+	}
+}
+
+func TestBPEEstimator_EstimateTokens(t *testing.T) {
+	estimator := &BPEEstimator{}
+
+	tests := []struct {
+		name     string
+		text     string
+		expected int
+	}{
+		{"empty", "", 0},
+		{"single word", "user", 1},
+		{"camelCase split", "getUserName", 3},
+		{"snake_case split", "get_user", 3},
+		{"digits grouped by three", "123456", 2},
+		{"non-ASCII counted per rune", "日本語", 3},
+		{"whitespace collapses", "a    b", 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := estimator.EstimateTokens(tt.text); got != tt.expected {
+				t.Errorf("EstimateTokens(%q) = %d, expected %d", tt.text, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestNewTokenEstimator(t *testing.T) {
+	for _, name := range append(SupportedTokenEstimators(), "") {
+		estimator, err := NewTokenEstimator(name)
+		if err != nil {
+			t.Errorf("NewTokenEstimator(%q) returned error: %v", name, err)
+			continue
+		}
+		if name != "" && estimator.Name() != name {
+			t.Errorf("Expected estimator %q, got %q", name, estimator.Name())
+		}
+	}
+
+	if _, err := NewTokenEstimator("unknown"); err == nil {
+		t.Error("Expected error for unknown estimator")
+	}
+}
+
+func TestTruncateToTokens(t *testing.T) {
+	estimator := DefaultTokenEstimator()
+
+	text, truncated := TruncateToTokens(estimator, "short", 10)
+	if truncated || text != "short" {
+		t.Errorf("Expected text within budget to be unchanged, got %q (truncated=%v)", text, truncated)
+	}
+
+	text, truncated = TruncateToTokens(estimator, strings.Repeat("x", 100), 5)
+	if !truncated {
+		t.Error("Expected text to be truncated")
+	}
+	if estimator.EstimateTokens(text) > 5 {
+		t.Errorf("Truncated text exceeds budget: %d tokens", estimator.EstimateTokens(text))
+	}
+
+	// Multi-byte runes must not be split
+	text, _ = TruncateToTokens(&BPEEstimator{}, "日本語日本語", 2)
+	if text != "日本" {
+		t.Errorf("Expected rune-safe truncation to %q, got %q", "日本", text)
+	}
+}
+
+func TestQueryEngine_SetTokenEstimator(t *testing.T) {
+	tempDir, storage := setupTestStorage(t)
+	defer os.RemoveAll(tempDir)
+
+	engine := NewQueryEngine(storage)
+	if engine.TokenEstimator().Name() != TokenEstimatorHeuristic {
+		t.Errorf("Expected default estimator, got %s", engine.TokenEstimator().Name())
+	}
+
+	engine.SetTokenEstimator(&BPEEstimator{})
+	if engine.TokenEstimator().Name() != TokenEstimatorBPE {
+		t.Errorf("Expected bpe estimator, got %s", engine.TokenEstimator().Name())
+	}
+
+	engine.SetTokenEstimator(nil)
+	if engine.TokenEstimator().Name() != TokenEstimatorHeuristic {
+		t.Errorf("Expected nil to restore default estimator, got %s", engine.TokenEstimator().Name())
+	}
+}
+
+func TestQueryEngine_DefaultEntryTokenBudget(t *testing.T) {
+	tempDir, storage := setupTestStorage(t)
+	defer os.RemoveAll(tempDir)
+
+	signature := "func NewUserWithVeryLongName(firstName string, lastName string) (*User, error)"
+	newResult := func() *SearchResult {
+		result := &SearchResult{}
+		for i := 0; i < 3; i++ {
+			result.Entries = append(result.Entries, SearchResultEntry{
+				IndexEntry: models.IndexEntry{Name: "NewUserWithVeryLongName", Type: EntityTypeFunction, Signature: signature},
+			})
+		}
+		return result
+	}
+
+	// The default estimator counts an entry as a token per word plus overhead, as before
+	// estimators were pluggable: 1 + 7 + 10 tokens, so all three entries fit in 60 tokens
+	engine := NewQueryEngine(storage)
+	result := newResult()
+	engine.TruncateToTokenLimit(result, 60)
+	if len(result.Entries) != 3 || result.Truncated || result.TokenCount != 54 {
+		t.Errorf("Expected the default budget to keep 3 entries of 18 tokens, got %d entries and %d tokens (truncated: %v)",
+			len(result.Entries), result.TokenCount, result.Truncated)
+	}
+
+	// Counting characters, as the BPE estimator does, truncates the same budget
+	engine.SetTokenEstimator(&BPEEstimator{})
+	result = newResult()
+	engine.TruncateToTokenLimit(result, 60)
+	if len(result.Entries) >= 3 || !result.Truncated {
+		t.Errorf("Expected the BPE estimator to truncate, got %d entries (truncated: %v)", len(result.Entries), result.Truncated)
+	}
+}
@@ -0,0 +1,259 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// MaxBatchQueries bounds the sub-queries of a single batch_query call
+const MaxBatchQueries = 20
+
+// batchQueryType is a sub-query type of batch_query: the tool it runs and the
+// parameter its query string fills
+type batchQueryType struct {
+	tool  string
+	param string
+}
+
+// batchQueryTypes lists the read-only tools batch_query can run, by sub-query type
+var batchQueryTypes = map[string]batchQueryType{
+	"name":             {"query_by_name", "name"},
+	"pattern":          {"query_by_pattern", "pattern"},
+	"call_graph":       {"get_call_graph", "function_name"},
+	"function_context": {"get_function_context", "function_name"},
+	"type_context":     {"get_type_context", "type_name"},
+	"summary":          {"summarize_function", "function_names"},
+	"decorator":        {"query_by_decorator", "decorator"},
+	"content":          {"search_content", "pattern"},
+	"autocomplete":     {"autocomplete", "prefix"},
+	"dependencies":     {"find_dependencies", "entity_name"},
+	"location":         {"symbol_at_location", "file_path"},
+	"package":          {"get_package_context", "package_path"},
+}
+
+// BatchSubQuery is one query of a batch_query call
+type BatchSubQuery struct {
+	Type    string                 // Key of batchQueryTypes
+	Query   string                 // Value of the tool's main parameter
+	Options map[string]interface{} // Other tool parameters, passed through unchanged
+	invalid string                 // Why the sub-query is malformed, reported as its error
+}
+
+// BatchQueryParams encapsulates batch_query parameters
+type BatchQueryParams struct {
+	Queries   []BatchSubQuery
+	MaxTokens int // Budget shared by all sub-query results, unlimited when zero
+}
+
+// BatchSubQueryResult is the outcome of one sub-query, in request order
+type BatchSubQueryResult struct {
+	Index   int             `json:"index"`
+	Type    string          `json:"type"`
+	Query   string          `json:"query"`
+	Success bool            `json:"success"`
+	Result  json.RawMessage `json:"result,omitempty"` // The tool's response when it succeeded
+	Error   string          `json:"error,omitempty"`
+	Tokens  int             `json:"tokens,omitempty"` // Estimated tokens of the result
+}
+
+// BatchQueryResult is the response of batch_query
+type BatchQueryResult struct {
+	Results    []BatchSubQueryResult `json:"results"`
+	Succeeded  int                   `json:"succeeded"`
+	Failed     int                   `json:"failed"`
+	TokenCount int                   `json:"token_count"`
+	Truncated  bool                  `json:"truncated"` // Sub-queries were skipped once max_tokens was spent
+}
+
+// createBatchQueryTool creates the batch_query tool running several queries in one call
+func (s *RepoContextMCPServer) createBatchQueryTool() mcp.Tool {
+	types := slices.Sorted(maps.Keys(batchQueryTypes))
+	return mcp.NewTool("batch_query",
+		mcp.WithDescription(
+			"Run several read-only queries in one call and get each result or error in request order; "+
+				"a failing query does not fail the others. Cheaper than one call per query for many small lookups",
+		),
+		mcp.WithArray("queries", mcp.Required(), mcp.MinItems(1), mcp.MaxItems(MaxBatchQueries),
+			mcp.Description(fmt.Sprintf(
+				"Up to %d queries, each {\"type\": ..., \"query\": ..., \"options\": {...}}. The type selects the tool and the "+
+					"query fills its main parameter: %s. Options are the tool's other parameters, e.g. {\"include_callers\": true}",
+				MaxBatchQueries, describeBatchQueryTypes(types),
+			)),
+			mcp.Items(map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"type":    map[string]any{"type": "string", "enum": types},
+					"query":   map[string]any{"type": "string"},
+					"options": map[string]any{"type": "object"},
+				},
+				"required": []string{"type", "query"},
+			}),
+		),
+		mcp.WithNumber("max_tokens", mcp.Description(
+			"Token budget shared by all results; each query gets what earlier ones left and the rest are skipped "+
+				"once it is spent (default: no shared budget)",
+		)),
+	)
+}
+
+// describeBatchQueryTypes lists the sub-query types as "type (tool.param)"
+func describeBatchQueryTypes(types []string) string {
+	descriptions := make([]string, len(types))
+	for i, name := range types {
+		queryType := batchQueryTypes[name]
+		descriptions[i] = fmt.Sprintf("%s (%s %s)", name, queryType.tool, queryType.param)
+	}
+	return strings.Join(descriptions, ", ")
+}
+
+// HandleBatchQuery runs the sub-queries of a batch in order, sharing the query engine
+// and its caches, and reports the outcome of each
+func (s *RepoContextMCPServer) HandleBatchQuery(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// System-level validation
+	if s.QueryEngine == nil {
+		return nil, fmt.Errorf("query engine not initialized - system configuration error")
+	}
+
+	// Repository validation
+	if err := s.validateRepository(); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Repository validation failed: %v", err)), nil
+	}
+
+	params, err := s.parseBatchQueryParameters(request)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Parameter validation failed: %v", err)), nil
+	}
+
+	result := &BatchQueryResult{Results: make([]BatchSubQueryResult, 0, len(params.Queries))}
+	remaining := params.MaxTokens
+	for i := range params.Queries {
+		subQuery := &params.Queries[i]
+		outcome := BatchSubQueryResult{Index: i, Type: subQuery.Type, Query: subQuery.Query}
+		if params.MaxTokens > 0 && remaining <= 0 {
+			outcome.Error = "skipped: max_tokens budget spent by earlier queries"
+			result.Truncated = true
+		} else {
+			s.runBatchSubQuery(ctx, subQuery, params.MaxTokens > 0, remaining, &outcome)
+			remaining -= outcome.Tokens
+			result.TokenCount += outcome.Tokens
+		}
+
+		if outcome.Success {
+			result.Succeeded++
+		} else {
+			result.Failed++
+		}
+		result.Results = append(result.Results, outcome)
+	}
+	return s.FormatSuccessResponse(result), nil
+}
+
+// parseBatchQueryParameters extracts and validates batch_query parameters. Malformed
+// sub-queries are kept and fail on their own when run.
+func (s *RepoContextMCPServer) parseBatchQueryParameters(request mcp.CallToolRequest) (*BatchQueryParams, error) {
+	items, ok := request.GetArguments()["queries"].([]interface{})
+	if !ok || len(items) == 0 {
+		return nil, fmt.Errorf("queries parameter is required and must be a non-empty array")
+	}
+	if len(items) > MaxBatchQueries {
+		return nil, fmt.Errorf("at most %d queries are allowed per batch, got %d", MaxBatchQueries, len(items))
+	}
+
+	maxTokens := request.GetInt("max_tokens", 0)
+	if maxTokens < 0 {
+		return nil, fmt.Errorf("max_tokens must not be negative, got %d", maxTokens)
+	}
+
+	params := &BatchQueryParams{Queries: make([]BatchSubQuery, len(items)), MaxTokens: maxTokens}
+	for i, item := range items {
+		params.Queries[i] = parseBatchSubQuery(item)
+	}
+	return params, nil
+}
+
+// parseBatchSubQuery reads a sub-query object, recording why it is malformed if it is
+func parseBatchSubQuery(item interface{}) BatchSubQuery {
+	fields, ok := item.(map[string]interface{})
+	if !ok {
+		return BatchSubQuery{invalid: "query must be an object"}
+	}
+
+	subQuery := BatchSubQuery{}
+	subQuery.Type, _ = fields["type"].(string)
+	subQuery.Query, _ = fields["query"].(string)
+	subQuery.Query = strings.TrimSpace(subQuery.Query)
+	switch options := fields["options"].(type) {
+	case nil:
+	case map[string]interface{}:
+		subQuery.Options = options
+	default:
+		subQuery.invalid = "options must be an object"
+	}
+
+	switch {
+	case subQuery.invalid != "":
+	case batchQueryTypes[subQuery.Type].tool == "":
+		subQuery.invalid = fmt.Sprintf("unknown query type '%s'", subQuery.Type)
+	case subQuery.Query == "":
+		subQuery.invalid = "query is required"
+	}
+	return subQuery
+}
+
+// runBatchSubQuery runs a sub-query through its tool's handler and records the outcome.
+// With a shared budget the tool's max_tokens is capped to the tokens remaining.
+func (s *RepoContextMCPServer) runBatchSubQuery(
+	ctx context.Context, subQuery *BatchSubQuery, budgeted bool, remaining int, outcome *BatchSubQueryResult,
+) {
+	if subQuery.invalid != "" {
+		outcome.Error = subQuery.invalid
+		return
+	}
+	queryType := batchQueryTypes[subQuery.Type]
+	handler := s.getToolHandler(queryType.tool)
+	if handler == nil || !s.isToolEnabled(queryType.tool) {
+		outcome.Error = fmt.Sprintf("tool '%s' is not enabled", queryType.tool)
+		return
+	}
+
+	arguments := maps.Clone(subQuery.Options)
+	if arguments == nil {
+		arguments = make(map[string]interface{})
+	}
+	arguments[queryType.param] = subQuery.Query
+	subRequest := mcp.CallToolRequest{}
+	subRequest.Params.Name = queryType.tool
+	subRequest.Params.Arguments = arguments
+	if budgeted {
+		maxTokens := subRequest.GetInt("max_tokens", s.getMaxTokens())
+		if maxTokens <= 0 {
+			maxTokens = s.getMaxTokens()
+		}
+		arguments["max_tokens"] = min(maxTokens, remaining)
+	}
+
+	toolResult, err := handler(ctx, subRequest)
+	if err != nil {
+		outcome.Error = err.Error()
+		return
+	}
+
+	text := toolResultText(toolResult)
+	if toolResult.IsError {
+		outcome.Error = text
+		return
+	}
+	outcome.Success = true
+	outcome.Tokens = s.estimateTextTokens(text)
+	if json.Valid([]byte(text)) {
+		outcome.Result = json.RawMessage(text)
+	} else {
+		outcome.Result, _ = json.Marshal(text)
+	}
+}
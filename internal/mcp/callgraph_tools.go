@@ -62,6 +62,7 @@ type EnhancedGetCallGraphParams struct {
 	IncludeCallees  bool
 	IncludeExternal bool
 	MaxTokens       int
+	MaxNodes        int // Maximum call graph entries collected, unlimited when zero
 }
 
 // GetIncludeCallers implements QueryOptionsBuilder interface
@@ -116,7 +117,7 @@ func (s *RepoContextMCPServer) parseEnhancedGetCallGraphParameters(request mcp.C
 		return nil, fmt.Errorf("function_name parameter is required")
 	}
 
-	maxDepth := request.GetInt("max_depth", DefaultCallGraphDepth)
+	maxDepth := request.GetInt("max_depth", s.getMaxDepth())
 	validatedDepth := validateEnhancedCallGraphDepth(maxDepth)
 
 	return &EnhancedGetCallGraphParams{
@@ -125,7 +126,8 @@ func (s *RepoContextMCPServer) parseEnhancedGetCallGraphParameters(request mcp.C
 		IncludeCallers:  request.GetBool("include_callers", false),
 		IncludeCallees:  request.GetBool("include_callees", false),
 		IncludeExternal: request.GetBool("include_external", false),
-		MaxTokens:       request.GetInt("max_tokens", constMaxTokens),
+		MaxTokens:       request.GetInt("max_tokens", s.getMaxTokens()),
+		MaxNodes:        max(request.GetInt("max_nodes", 0), 0),
 	}, nil
 }
 
@@ -144,7 +146,7 @@ func (s *RepoContextMCPServer) parseFindDependenciesParameters(request mcp.CallT
 	return &FindDependenciesParams{
 		EntityName:     entityName,
 		DependencyType: dependencyType,
-		MaxTokens:      request.GetInt("max_tokens", constMaxTokens),
+		MaxTokens:      request.GetInt("max_tokens", s.getMaxTokens()),
 	}, nil
 }
 
@@ -160,6 +162,8 @@ func (s *RepoContextMCPServer) createEnhancedGetCallGraphTool() mcp.Tool {
 		mcp.WithBoolean("include_callees", mcp.Description("Include functions called by this function")),
 		mcp.WithBoolean("include_external", mcp.Description("Include external function calls (default: false)")),
 		mcp.WithNumber("max_tokens", mcp.Description("Maximum tokens for response (default: 2000)")),
+		mcp.WithNumber("max_nodes",
+			mcp.Description("Stop the traversal after this many callers and callees and mark the result truncated (default: no limit)")),
 	)
 }
 
@@ -184,12 +188,16 @@ func (s *RepoContextMCPServer) HandleEnhancedGetCallGraph(ctx context.Context, r
 	// Build query options with enhanced parameters
 	queryOptions := s.buildQueryOptionsFromParams(params)
 	queryOptions.MaxDepth = params.MaxDepth
+	queryOptions.MaxNodes = params.MaxNodes
 
-	// Execute call graph query with enhanced error handling
-	callGraphResult, err := s.QueryEngine.GetCallGraphWithOptions(params.FunctionName, queryOptions)
+	// Execute call graph query with enhanced error handling, bounded by the query timeout
+	queryCtx, cancel := s.queryContext(ctx)
+	defer cancel()
+	callGraphResult, err := s.QueryEngine.GetCallGraphWithContext(queryCtx, params.FunctionName, queryOptions)
 	if err != nil {
 		return s.FormatErrorResponse("get_call_graph_enhanced", err), nil
 	}
+	s.applyExcludedCallGraphPaths(callGraphResult)
 
 	// Apply external call filtering if requested
 	if !params.IncludeExternal {
@@ -345,10 +353,15 @@ func (s *RepoContextMCPServer) createFindDependenciesTool() mcp.Tool {
 
 // HandleFindDependencies provides comprehensive dependency analysis for functions and types
 func (s *RepoContextMCPServer) HandleFindDependencies(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	queryCtx, cancel := s.queryContext(ctx)
+	defer cancel()
+
 	ops := ToolOperations[*FindDependenciesParams, *DependencyAnalysisResult]{
 		ParseParams: s.parseFindDependenciesParameters,
-		BuildResult: s.buildDependencyAnalysis,
-		OptimizeResult: func(result *DependencyAnalysisResult, maxTokens int) {
+		BuildResult: func(params *FindDependenciesParams) (*DependencyAnalysisResult, error) {
+			return s.buildDependencyAnalysis(queryCtx, params)
+		},
+		OptimizeResult: func(_ *FindDependenciesParams, result *DependencyAnalysisResult, maxTokens int) {
 			s.optimizeDependencyResponse(result, maxTokens)
 		},
 		ToolName: "find_dependencies",
@@ -369,10 +382,11 @@ type DependencyAnalysisResult struct {
 	TotalRelatedTypes int                       `json:"total_related_types"`
 	TokenCount        int                       `json:"token_count"`
 	Truncated         bool                      `json:"truncated"`
+	TimedOut          bool                      `json:"timed_out,omitempty"`
 }
 
 // buildDependencyAnalysis builds comprehensive dependency analysis
-func (s *RepoContextMCPServer) buildDependencyAnalysis(params *FindDependenciesParams) (*DependencyAnalysisResult, error) {
+func (s *RepoContextMCPServer) buildDependencyAnalysis(ctx context.Context, params *FindDependenciesParams) (*DependencyAnalysisResult, error) {
 	result := &DependencyAnalysisResult{
 		EntityName:     params.EntityName,
 		DependencyType: params.DependencyType,
@@ -382,7 +396,7 @@ func (s *RepoContextMCPServer) buildDependencyAnalysis(params *FindDependenciesP
 	}
 
 	// First, determine the entity type by searching for it
-	entitySearch, err := s.QueryEngine.SearchByNameWithOptions(params.EntityName, index.QueryOptions{
+	entitySearch, err := s.QueryEngine.SearchByNameWithContext(ctx, params.EntityName, index.QueryOptions{
 		Format: "json",
 	})
 	if err != nil {
@@ -399,7 +413,7 @@ func (s *RepoContextMCPServer) buildDependencyAnalysis(params *FindDependenciesP
 
 	// Build call graph analysis if it's a function
 	if entity.IndexEntry.Type == TypeFunction {
-		if err := s.addCallGraphDependencies(result, params); err != nil {
+		if err := s.addCallGraphDependencies(ctx, result, params); err != nil {
 			return nil, err
 		}
 	}
@@ -418,7 +432,8 @@ func (s *RepoContextMCPServer) buildDependencyAnalysis(params *FindDependenciesP
 }
 
 // addCallGraphDependencies adds call graph dependencies to the analysis result
-func (s *RepoContextMCPServer) addCallGraphDependencies(result *DependencyAnalysisResult, params *FindDependenciesParams) error {
+func (s *RepoContextMCPServer) addCallGraphDependencies(
+	ctx context.Context, result *DependencyAnalysisResult, params *FindDependenciesParams) error {
 	// Build query options based on dependency type
 	queryOptions := index.QueryOptions{
 		IncludeCallers: params.DependencyType == CallerCallees || params.DependencyType == Both,
@@ -428,10 +443,11 @@ func (s *RepoContextMCPServer) addCallGraphDependencies(result *DependencyAnalys
 	}
 
 	// Get call graph
-	callGraph, err := s.QueryEngine.GetCallGraphWithOptions(params.EntityName, queryOptions)
+	callGraph, err := s.QueryEngine.GetCallGraphWithContext(ctx, params.EntityName, queryOptions)
 	if err != nil {
 		return fmt.Errorf("failed to get call graph for '%s': %w", params.EntityName, err)
 	}
+	result.TimedOut = callGraph.TimedOut
 
 	// Add callers if requested
 	if queryOptions.IncludeCallers {
@@ -459,7 +475,7 @@ func (s *RepoContextMCPServer) addRelatedTypesDependencies(result *DependencyAna
 
 	// Filter for type entries
 	for _, entry := range typeSearch.Entries {
-		if entry.IndexEntry.Type == TypeType {
+		if index.MatchesEntityType(entry.IndexEntry.Type, TypeType) {
 			result.RelatedTypes = append(result.RelatedTypes, entry)
 		}
 	}
@@ -600,5 +616,65 @@ func (s *RepoContextMCPServer) RegisterCallGraphTools() []mcp.Tool {
 	return []mcp.Tool{
 		s.createEnhancedGetCallGraphTool(),
 		s.createFindDependenciesTool(),
+		s.createExportCallGraphTool(),
+	}
+}
+
+// Output formats of export_call_graph
+const (
+	CallGraphFormatJSON = "json"
+	CallGraphFormatDOT  = "dot"
+)
+
+// createExportCallGraphTool creates the export_call_graph tool
+func (s *RepoContextMCPServer) createExportCallGraphTool() mcp.Tool {
+	return mcp.NewTool("export_call_graph",
+		mcp.WithDescription(
+			"Export the call graph of the repository, or of one package or name pattern, as nodes and edges "+
+				"for architecture diagrams, as JSON for d3 or as Graphviz DOT",
+		),
+		mcp.WithString("package", mcp.Description("Only include functions in this directory, relative to the repository root")),
+		mcp.WithString("pattern", mcp.Description("Only include functions whose names match this glob or regex pattern")),
+		mcp.WithString("format", mcp.Description("Output format: 'json' (default) for a nodes and edges document or 'dot' for Graphviz")),
+		mcp.WithNumber("max_nodes", mcp.Description(fmt.Sprintf(
+			"Maximum number of functions; the graph is marked truncated past it (default: %d)", index.DefaultCallGraphExportMaxNodes,
+		))),
+	)
+}
+
+// HandleExportCallGraph exports the scoped call graph as an adjacency list or DOT
+func (s *RepoContextMCPServer) HandleExportCallGraph(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// System-level validation
+	if s.QueryEngine == nil {
+		return nil, fmt.Errorf("query engine not initialized - system configuration error")
+	}
+
+	// Repository validation
+	if err := s.validateRepository(); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Repository validation failed: %v", err)), nil
+	}
+
+	format := strings.ToLower(strings.TrimSpace(request.GetString("format", CallGraphFormatJSON)))
+	if format != CallGraphFormatJSON && format != CallGraphFormatDOT {
+		return mcp.NewToolResultError(fmt.Sprintf("Parameter validation failed: format must be 'json' or 'dot', got '%s'", format)), nil
+	}
+	maxNodes := request.GetInt("max_nodes", index.DefaultCallGraphExportMaxNodes)
+	if maxNodes <= 0 {
+		return mcp.NewToolResultError(fmt.Sprintf("Parameter validation failed: max_nodes must be positive, got %d", maxNodes)), nil
+	}
+
+	document, err := s.QueryEngine.ExportCallGraph(index.CallGraphExportOptions{
+		Package:  s.repoRelativePath(strings.TrimSpace(request.GetString("package", ""))),
+		Pattern:  strings.TrimSpace(request.GetString("pattern", "")),
+		MaxNodes: maxNodes,
+	})
+	if err != nil {
+		return s.FormatErrorResponse("export_call_graph", err), nil
+	}
+	s.applyExcludedCallGraphExportPaths(document)
+
+	if format == CallGraphFormatDOT {
+		return mcp.NewToolResultText(document.DOT()), nil
 	}
+	return s.FormatSuccessResponse(document), nil
 }
@@ -1,7 +1,16 @@
 package mcp
 
 import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"strings"
 	"testing"
+
+	"repository-context-protocol/internal/index"
+	"repository-context-protocol/internal/models"
+
+	"github.com/mark3labs/mcp-go/mcp"
 )
 
 // TestEnhancedCallGraphTools_Registration tests tool registration
@@ -13,6 +22,7 @@ func TestEnhancedCallGraphTools_Registration(t *testing.T) {
 	expectedTools := []string{
 		"get_call_graph_enhanced",
 		"find_dependencies",
+		"export_call_graph",
 	}
 
 	if len(tools) != len(expectedTools) {
@@ -56,6 +66,39 @@ func TestEnhancedCallGraphDepthValidation(t *testing.T) {
 	}
 }
 
+// TestEnhancedCallGraphMaxNodes tests parsing of the node budget
+func TestEnhancedCallGraphMaxNodes(t *testing.T) {
+	server := NewRepoContextMCPServer()
+
+	tests := []struct {
+		name     string
+		maxNodes interface{}
+		expected int
+	}{
+		{"Unset - no limit", nil, 0},
+		{"Positive limit", float64(500), 500},
+		{"Negative - no limit", float64(-5), 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			arguments := map[string]interface{}{"function_name": "main"}
+			if tt.maxNodes != nil {
+				arguments["max_nodes"] = tt.maxNodes
+			}
+			request := mcp.CallToolRequest{}
+			request.Params.Arguments = arguments
+			params, err := server.parseEnhancedGetCallGraphParameters(request)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if params.MaxNodes != tt.expected {
+				t.Errorf("Expected max nodes %d, got %d", tt.expected, params.MaxNodes)
+			}
+		})
+	}
+}
+
 // TestDependencyTypeValidation tests dependency type validation
 func TestDependencyTypeValidation(t *testing.T) {
 	tests := []struct {
@@ -255,3 +298,59 @@ func TestTokenConstants(t *testing.T) {
 		})
 	}
 }
+
+// TestHandleExportCallGraph tests the JSON and DOT output and validation of export_call_graph
+func TestHandleExportCallGraph(t *testing.T) {
+	tempDir := t.TempDir()
+	storage := index.NewHybridStorage(filepath.Join(tempDir, ".repocontext"))
+	if err := storage.Initialize(); err != nil {
+		t.Fatalf("Failed to initialize storage: %v", err)
+	}
+	defer storage.Close()
+
+	if err := storage.StoreFileContext(&models.FileContext{
+		Path: "server.go", Language: "go",
+		Functions: []models.Function{
+			{Name: "HandleRequest", StartLine: 1, Calls: []string{"saveUser", "fmt.Println"}},
+			{Name: "saveUser", StartLine: 5},
+		},
+	}); err != nil {
+		t.Fatalf("Failed to store file context: %v", err)
+	}
+
+	server := &RepoContextMCPServer{QueryEngine: index.NewQueryEngine(storage), Storage: storage, RepoPath: tempDir}
+
+	request := mcp.CallToolRequest{}
+	result, err := server.HandleExportCallGraph(context.Background(), request)
+	if err != nil || result.IsError {
+		t.Fatalf("Expected export_call_graph to succeed, got %v %+v", err, result)
+	}
+	var document index.CallGraphDocument
+	if err := json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &document); err != nil {
+		t.Fatalf("Failed to decode result: %v", err)
+	}
+	if len(document.Nodes) != 2 || len(document.Edges) != 1 || document.Edges[0].To != "saveUser" {
+		t.Errorf("Expected two nodes and the call to saveUser, got %+v", document)
+	}
+
+	request.Params.Arguments = map[string]interface{}{"format": "dot"}
+	result, err = server.HandleExportCallGraph(context.Background(), request)
+	if err != nil || result.IsError {
+		t.Fatalf("Expected DOT export to succeed, got %v %+v", err, result)
+	}
+	if dot := result.Content[0].(mcp.TextContent).Text; !strings.Contains(dot, `"HandleRequest" -> "saveUser";`) {
+		t.Errorf("Expected a DOT edge from HandleRequest to saveUser, got:\n%s", dot)
+	}
+
+	for name, arguments := range map[string]map[string]interface{}{
+		"unknown format": {"format": "svg"},
+		"zero max_nodes": {"max_nodes": 0},
+	} {
+		request := mcp.CallToolRequest{}
+		request.Params.Arguments = arguments
+		result, err := server.HandleExportCallGraph(context.Background(), request)
+		if err != nil || !result.IsError {
+			t.Errorf("%s: expected an error result, got %v %+v", name, err, result)
+		}
+	}
+}
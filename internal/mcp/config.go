@@ -0,0 +1,227 @@
+package mcp
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"repository-context-protocol/internal/index"
+)
+
+// RepoConfigFileName is the server configuration file inside the .repocontext directory
+const RepoConfigFileName = "config.json"
+
+// RepoConfig holds server defaults read from .repocontext/config.json.
+// Numeric fields that are missing or not positive keep their built-in defaults.
+type RepoConfig struct {
+	MaxTokens           int      `json:"max_tokens"`            // Default max_tokens when a tool call omits it
+	MaxDepth            int      `json:"max_depth"`             // Default call graph max_depth when a tool call omits it
+	MaxContextLines     int      `json:"max_context_lines"`     // Upper bound for get_function_context context_lines
+	QueryTimeoutSeconds int      `json:"query_timeout_seconds"` // Time limit for a single call graph query
+	ExcludedPaths       []string `json:"excluded_paths"`        // Files or directories (globs allowed) hidden from query results
+	EnabledTools        []string `json:"enabled_tools"`         // Tools to register; all tools when empty
+	PythonInterpreter   string   `json:"python_interpreter"`    // Python executable or virtualenv used to index Python files
+	CompressChunks      bool     `json:"compress_chunks"`       // Gzip the chunk files of every build, as with build_index compress_chunks
+	// EntityTypeAliases adds entity_type synonyms, e.g. {"def": "function"}, to the built-in ones
+	EntityTypeAliases map[string]string `json:"entity_type_aliases"`
+	// EntryPointPatterns marks more functions as entry points when indexing, e.g. ["Handle*", "@app.route"],
+	// see index.IndexBuilderOptions.EntryPointPatterns
+	EntryPointPatterns []string `json:"entry_point_patterns"`
+}
+
+// DefaultRepoConfig returns the configuration used when no config file is present
+func DefaultRepoConfig() *RepoConfig {
+	return &RepoConfig{
+		MaxTokens:           constMaxTokens,
+		MaxDepth:            constMaxDepth,
+		MaxContextLines:     MaxContextLines,
+		QueryTimeoutSeconds: DefaultQueryTimeoutSeconds,
+	}
+}
+
+// LoadRepoConfig reads .repocontext/config.json from the repository, falling back to
+// defaults when the file does not exist. Unknown keys and invalid values are errors.
+func LoadRepoConfig(repoPath string) (*RepoConfig, error) {
+	config := DefaultRepoConfig()
+
+	configPath := filepath.Join(repoPath, ".repocontext", RepoConfigFileName)
+	data, err := os.ReadFile(configPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return config, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", configPath, err)
+	}
+
+	var fileConfig RepoConfig
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&fileConfig); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", configPath, err)
+	}
+	if err := fileConfig.validate(); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", configPath, err)
+	}
+
+	if fileConfig.MaxTokens > 0 {
+		config.MaxTokens = fileConfig.MaxTokens
+	}
+	if fileConfig.MaxDepth > 0 {
+		config.MaxDepth = fileConfig.MaxDepth
+	}
+	if fileConfig.MaxContextLines > 0 {
+		config.MaxContextLines = fileConfig.MaxContextLines
+	}
+	if fileConfig.QueryTimeoutSeconds > 0 {
+		config.QueryTimeoutSeconds = fileConfig.QueryTimeoutSeconds
+	}
+	config.ExcludedPaths = fileConfig.ExcludedPaths
+	config.EnabledTools = fileConfig.EnabledTools
+	config.PythonInterpreter = fileConfig.PythonInterpreter
+	config.CompressChunks = fileConfig.CompressChunks
+	config.EntityTypeAliases = fileConfig.EntityTypeAliases
+	config.EntryPointPatterns = fileConfig.EntryPointPatterns
+	return config, nil
+}
+
+// validate checks list fields, which have no sensible fallback when malformed
+func (c *RepoConfig) validate() error {
+	for _, path := range c.ExcludedPaths {
+		if strings.TrimSpace(path) == "" {
+			return fmt.Errorf("excluded_paths must not contain empty entries")
+		}
+		if _, err := filepath.Match(path, ""); err != nil {
+			return fmt.Errorf("invalid excluded_paths pattern '%s': %w", path, err)
+		}
+	}
+	for _, tool := range c.EnabledTools {
+		if !isKnownTool(tool) {
+			return fmt.Errorf("unknown tool '%s' in enabled_tools", tool)
+		}
+	}
+	for alias, entityType := range c.EntityTypeAliases {
+		if slices.Contains(validEntityTypes, alias) {
+			return fmt.Errorf("entity_type_aliases must not redefine the entity type '%s'", alias)
+		}
+		if !slices.Contains(validEntityTypes, entityType) {
+			return fmt.Errorf("entity_type_aliases maps '%s' to unknown entity type '%s'", alias, entityType)
+		}
+	}
+	if err := index.ValidateEntryPointPatterns(c.EntryPointPatterns); err != nil {
+		return fmt.Errorf("entry_point_patterns: %w", err)
+	}
+	return nil
+}
+
+// isKnownTool reports whether name is a tool the server can register
+func isKnownTool(name string) bool {
+	var s RepoContextMCPServer
+	return s.getToolHandler(name) != nil
+}
+
+// NewRepoContextMCPServerFromConfig creates a server for the repository at repoPath using
+// the defaults in its .repocontext/config.json
+func NewRepoContextMCPServerFromConfig(repoPath string) (*RepoContextMCPServer, error) {
+	config, err := LoadRepoConfig(repoPath)
+	if err != nil {
+		return nil, err
+	}
+
+	s := NewRepoContextMCPServer()
+	s.RepoPath = repoPath
+	s.config = config
+	return s, nil
+}
+
+// isToolEnabled reports whether the configuration allows the tool to be registered
+func (s *RepoContextMCPServer) isToolEnabled(name string) bool {
+	if s.config == nil || len(s.config.EnabledTools) == 0 {
+		return true
+	}
+	return slices.Contains(s.config.EnabledTools, name)
+}
+
+// isExcludedPath reports whether file lies under, or matches, a configured excluded path
+func (s *RepoContextMCPServer) isExcludedPath(file string) bool {
+	if s.config == nil {
+		return false
+	}
+	file = filepath.ToSlash(file)
+	for _, excluded := range s.config.ExcludedPaths {
+		excluded = strings.TrimSuffix(filepath.ToSlash(excluded), "/")
+		if file == excluded || strings.HasPrefix(file, excluded+"/") {
+			return true
+		}
+		if matched, _ := filepath.Match(excluded, file); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// applyExcludedPaths drops entries from excluded files
+func (s *RepoContextMCPServer) applyExcludedPaths(result *index.SearchResult) {
+	if s.config == nil || len(s.config.ExcludedPaths) == 0 {
+		return
+	}
+
+	filteredEntries := make([]index.SearchResultEntry, 0, len(result.Entries))
+	for _, entry := range result.Entries {
+		if !s.isExcludedPath(entry.IndexEntry.File) {
+			filteredEntries = append(filteredEntries, entry)
+		}
+	}
+	result.Entries = filteredEntries
+	if result.CallGraph != nil {
+		s.applyExcludedCallGraphPaths(result.CallGraph)
+	}
+}
+
+// applyExcludedCallGraphPaths drops callers and callees defined in excluded files
+func (s *RepoContextMCPServer) applyExcludedCallGraphPaths(callGraph *index.CallGraphInfo) {
+	if s.config == nil || len(s.config.ExcludedPaths) == 0 {
+		return
+	}
+
+	keep := func(entries []index.CallGraphEntry) []index.CallGraphEntry {
+		filtered := make([]index.CallGraphEntry, 0, len(entries))
+		for _, entry := range entries {
+			if !s.isExcludedPath(entry.File) {
+				filtered = append(filtered, entry)
+			}
+		}
+		return filtered
+	}
+	callGraph.Callers = keep(callGraph.Callers)
+	callGraph.Callees = keep(callGraph.Callees)
+}
+
+// applyExcludedCallGraphExportPaths removes the functions in excluded paths from an
+// exported call graph, along with the calls to and from them
+func (s *RepoContextMCPServer) applyExcludedCallGraphExportPaths(document *index.CallGraphDocument) {
+	if s.config == nil || len(s.config.ExcludedPaths) == 0 {
+		return
+	}
+
+	excluded := make(map[string]bool)
+	nodes := make([]index.CallGraphNode, 0, len(document.Nodes))
+	for _, node := range document.Nodes {
+		if s.isExcludedPath(node.File) {
+			excluded[node.ID] = true
+		} else {
+			nodes = append(nodes, node)
+		}
+	}
+	edges := make([]index.CallGraphEdge, 0, len(document.Edges))
+	for _, edge := range document.Edges {
+		if !excluded[edge.From] && !excluded[edge.To] {
+			edges = append(edges, edge)
+		}
+	}
+	document.Nodes, document.Edges = nodes, edges
+}
@@ -0,0 +1,215 @@
+package mcp
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"repository-context-protocol/internal/index"
+	"repository-context-protocol/internal/models"
+)
+
+func writeRepoConfig(t *testing.T, content string) string {
+	t.Helper()
+
+	repoPath := t.TempDir()
+	repoContextDir := filepath.Join(repoPath, ".repocontext")
+	require.NoError(t, os.MkdirAll(repoContextDir, 0755))
+	if content != "" {
+		require.NoError(t, os.WriteFile(filepath.Join(repoContextDir, RepoConfigFileName), []byte(content), 0600))
+	}
+	return repoPath
+}
+
+func TestLoadRepoConfig(t *testing.T) {
+	t.Run("missing file uses defaults", func(t *testing.T) {
+		config, err := LoadRepoConfig(writeRepoConfig(t, ""))
+		require.NoError(t, err)
+		assert.Equal(t, MaxContextLines, config.MaxContextLines)
+		assert.Equal(t, DefaultQueryTimeoutSeconds, config.QueryTimeoutSeconds)
+	})
+
+	t.Run("reads max_context_lines", func(t *testing.T) {
+		config, err := LoadRepoConfig(writeRepoConfig(t, `{"max_context_lines": 120}`))
+		require.NoError(t, err)
+		assert.Equal(t, 120, config.MaxContextLines)
+	})
+
+	t.Run("reads query_timeout_seconds", func(t *testing.T) {
+		config, err := LoadRepoConfig(writeRepoConfig(t, `{"query_timeout_seconds": 5}`))
+		require.NoError(t, err)
+		assert.Equal(t, 5, config.QueryTimeoutSeconds)
+		assert.Equal(t, MaxContextLines, config.MaxContextLines)
+	})
+
+	t.Run("non-positive values keep defaults", func(t *testing.T) {
+		config, err := LoadRepoConfig(writeRepoConfig(t, `{"max_context_lines": 0, "query_timeout_seconds": -1}`))
+		require.NoError(t, err)
+		assert.Equal(t, MaxContextLines, config.MaxContextLines)
+		assert.Equal(t, DefaultQueryTimeoutSeconds, config.QueryTimeoutSeconds)
+	})
+
+	t.Run("reads defaults and lists", func(t *testing.T) {
+		config, err := LoadRepoConfig(writeRepoConfig(t, `{
+			"max_tokens": 4000,
+			"max_depth": 4,
+			"excluded_paths": ["vendor/", "*_gen.go"],
+			"enabled_tools": ["query_by_name", "get_call_graph"],
+			"python_interpreter": ".venv"
+		}`))
+		require.NoError(t, err)
+		assert.Equal(t, 4000, config.MaxTokens)
+		assert.Equal(t, 4, config.MaxDepth)
+		assert.Equal(t, []string{"vendor/", "*_gen.go"}, config.ExcludedPaths)
+		assert.Equal(t, []string{"query_by_name", "get_call_graph"}, config.EnabledTools)
+		assert.Equal(t, ".venv", config.PythonInterpreter)
+	})
+
+	t.Run("builds fail on a missing python_interpreter", func(t *testing.T) {
+		server := NewRepoContextMCPServer()
+		_, err := server.buildRepositoryIndex(writeRepoConfig(t, `{"python_interpreter": ".venv"}`), false)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), ".venv")
+	})
+
+	t.Run("unknown keys are an error", func(t *testing.T) {
+		_, err := LoadRepoConfig(writeRepoConfig(t, `{"max_token": 4000}`))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "max_token")
+	})
+
+	t.Run("unknown tools are an error", func(t *testing.T) {
+		_, err := LoadRepoConfig(writeRepoConfig(t, `{"enabled_tools": ["query_by_nme"]}`))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "query_by_nme")
+	})
+
+	t.Run("reads entity_type_aliases", func(t *testing.T) {
+		config, err := LoadRepoConfig(writeRepoConfig(t, `{"entity_type_aliases": {"def": "function", "enum": "type"}}`))
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{"def": "function", "enum": "type"}, config.EntityTypeAliases)
+	})
+
+	t.Run("invalid entity_type_aliases are an error", func(t *testing.T) {
+		_, err := LoadRepoConfig(writeRepoConfig(t, `{"entity_type_aliases": {"def": "procedure"}}`))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "procedure")
+
+		_, err = LoadRepoConfig(writeRepoConfig(t, `{"entity_type_aliases": {"type": "function"}}`))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "type")
+	})
+
+	t.Run("reads entry_point_patterns", func(t *testing.T) {
+		config, err := LoadRepoConfig(writeRepoConfig(t, `{"entry_point_patterns": ["Handle*", "@app.route"]}`))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Handle*", "@app.route"}, config.EntryPointPatterns)
+
+		_, err = LoadRepoConfig(writeRepoConfig(t, `{"entry_point_patterns": ["Handle[a-"]}`))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "entry_point_patterns")
+	})
+
+	t.Run("malformed excluded paths are an error", func(t *testing.T) {
+		_, err := LoadRepoConfig(writeRepoConfig(t, `{"excluded_paths": ["gen/[a-"]}`))
+		assert.Error(t, err)
+	})
+
+	t.Run("invalid json is an error", func(t *testing.T) {
+		_, err := LoadRepoConfig(writeRepoConfig(t, `{"max_context_lines":`))
+		assert.Error(t, err)
+	})
+}
+
+func TestConfiguredMaxContextLines(t *testing.T) {
+	config, err := LoadRepoConfig(writeRepoConfig(t, `{"max_context_lines": 120}`))
+	require.NoError(t, err)
+
+	server := NewRepoContextMCPServer()
+	server.config = config
+
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]interface{}{"function_name": "main", "context_lines": 100}
+	params, err := server.parseGetFunctionContextParameters(request)
+	require.NoError(t, err)
+	assert.Equal(t, 100, params.ContextLines, "values above the built-in default should be allowed")
+
+	request.Params.Arguments = map[string]interface{}{"function_name": "main", "context_lines": 500}
+	params, err = server.parseGetFunctionContextParameters(request)
+	require.NoError(t, err)
+	assert.Equal(t, 120, params.ContextLines, "values should be capped at the configured max")
+
+	tool := server.createGetFunctionContextTool()
+	contextLines, ok := tool.InputSchema.Properties["context_lines"].(map[string]interface{})
+	require.True(t, ok)
+	assert.Contains(t, contextLines["description"], "max: 120")
+	assert.Equal(t, 120, server.GetServerConfiguration().MaxContextLines)
+}
+
+func TestConfiguredQueryTimeout(t *testing.T) {
+	config, err := LoadRepoConfig(writeRepoConfig(t, `{"query_timeout_seconds": 5}`))
+	require.NoError(t, err)
+
+	server := NewRepoContextMCPServer()
+	server.config = config
+
+	ctx, cancel := server.queryContext(context.Background())
+	defer cancel()
+	deadline, ok := ctx.Deadline()
+	require.True(t, ok, "query context should carry a deadline")
+	assert.WithinDuration(t, time.Now().Add(5*time.Second), deadline, time.Second)
+	assert.Equal(t, 5, server.GetServerConfiguration().QueryTimeoutSeconds)
+}
+
+func TestNewRepoContextMCPServerFromConfig(t *testing.T) {
+	repoPath := writeRepoConfig(t, `{"max_tokens": 4000, "max_depth": 4, "enabled_tools": ["get_call_graph"]}`)
+
+	server, err := NewRepoContextMCPServerFromConfig(repoPath)
+	require.NoError(t, err)
+	assert.Equal(t, repoPath, server.RepoPath)
+
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]interface{}{"function_name": "main"}
+	params, err := server.parseGetCallGraphParameters(request)
+	require.NoError(t, err)
+	assert.Equal(t, 4000, params.MaxTokens)
+	assert.Equal(t, 4, params.MaxDepth)
+
+	assert.True(t, server.isToolEnabled("get_call_graph"))
+	assert.False(t, server.isToolEnabled("query_by_name"))
+
+	_, err = NewRepoContextMCPServerFromConfig(writeRepoConfig(t, `{"unknown": true}`))
+	assert.Error(t, err)
+}
+
+func TestExcludedPaths(t *testing.T) {
+	server := NewRepoContextMCPServer()
+	server.config.ExcludedPaths = []string{"vendor/", "*_gen.go"}
+
+	assert.True(t, server.isExcludedPath("vendor/lib/lib.go"))
+	assert.True(t, server.isExcludedPath("models_gen.go"))
+	assert.False(t, server.isExcludedPath("vendored.go"))
+	assert.False(t, server.isExcludedPath("internal/models.go"))
+
+	result := &index.SearchResult{
+		Entries: []index.SearchResultEntry{
+			{IndexEntry: models.IndexEntry{Name: "Lib", File: "vendor/lib/lib.go"}},
+			{IndexEntry: models.IndexEntry{Name: "Model", File: "internal/models.go"}},
+		},
+		CallGraph: &index.CallGraphInfo{
+			Callees: []index.CallGraphEntry{{Function: "Gen", File: "models_gen.go"}, {Function: "Run", File: "run.go"}},
+		},
+	}
+	server.applyExcludedPaths(result)
+
+	require.Len(t, result.Entries, 1)
+	assert.Equal(t, "Model", result.Entries[0].IndexEntry.Name)
+	require.Len(t, result.CallGraph.Callees, 1)
+	assert.Equal(t, "Run", result.CallGraph.Callees[0].Function)
+}
@@ -3,7 +3,11 @@ package mcp
 import (
 	"context"
 	"fmt"
+	"path/filepath"
+	"slices"
 	"strings"
+	"time"
+	"unicode"
 
 	"repository-context-protocol/internal/index"
 	"repository-context-protocol/internal/models"
@@ -13,8 +17,15 @@ import (
 
 // Constants for context tools
 const (
-	MaxContextLines     = 50 // Maximum allowed context lines around function
+	MaxContextLines     = 50 // Default maximum context lines around function, overridable in config.json
 	DefaultContextLines = 5  // Default context lines around function
+
+	suggestSimilarParamDescription = "When the name is not found, list up to 5 similar names in the error (default: true)"
+
+	DefaultResolveDepth = 1 // Levels of field types resolved when resolve_field_types is set without resolve_depth
+	MaxResolveDepth     = 3 // Deepest level of field types resolve_field_types follows
+
+	MaxSiblingDeclarations = 10 // Most declarations include_siblings returns on each side of a function
 )
 
 // Token management constants for context tools
@@ -25,6 +36,7 @@ const (
 	ContextLineTokens            = 10  // Average tokens per context line
 	FunctionRefTokens            = 15  // Average tokens per function reference
 	TypeRefTokens                = 12  // Average tokens per type reference
+	SiblingRefTokens             = 10  // Tokens per sibling declaration besides its signature
 
 	// Token distribution ratios for function context
 	ImplementationTokenRatio = 0.4  // 40% for implementation content
@@ -37,18 +49,63 @@ const (
 	FieldRefTokens        = 18  // Average tokens per field reference
 	MethodRefTokens       = 20  // Average tokens per method reference
 	UsageExampleTokens    = 25  // Average tokens per usage example
+	ConstantRefTokens     = 15  // Average tokens per constant reference
+	ResolvedFieldTokens   = 8   // Average tokens per field of a resolved type
 
 	// Token distribution ratios for type context
 	FieldsTokenRatio  = 0.3 // 30% for fields
 	MethodsTokenRatio = 0.4 // 40% for methods
 	UsageTokenRatio   = 0.2 // 20% for usage examples
 	RelatedTokenRatio = 0.1 // 10% for related types
+
+	// Share of max_tokens the definitions pulled in by resolve_field_types may use
+	ResolvedTypesTokenRatio = 0.5
+)
+
+// Focus presets of get_function_context, naming how its token budget is split
+const (
+	FocusBalanced       = "balanced"       // The default split of the token distribution ratios
+	FocusImplementation = "implementation" // The whole budget goes to the implementation
+	FocusRelationships  = "relationships"  // Callers and callees come first
+)
+
+// FunctionContextBudget is the share of the token budget each section of a function
+// context may use when the response has to be trimmed. Sections with no share are left
+// out even when the response fits.
+type FunctionContextBudget struct {
+	Implementation float64
+	Callers        float64
+	Callees        float64
+	Types          float64
+}
+
+// DefaultFunctionContextBudget splits the budget by the token distribution ratios
+var DefaultFunctionContextBudget = FunctionContextBudget{
+	Implementation: ImplementationTokenRatio,
+	Callers:        CallersTokenRatio,
+	Callees:        CalleesTokenRatio,
+	Types:          TypesTokenRatio,
+}
+
+// functionContextFocuses maps each focus preset to its budget
+var functionContextFocuses = map[string]FunctionContextBudget{
+	FocusBalanced:       DefaultFunctionContextBudget,
+	FocusImplementation: {Implementation: 1},
+	FocusRelationships:  {Implementation: 0.1, Callers: 0.4, Callees: 0.4, Types: 0.1},
+}
+
+// Usage example source constants
+const (
+	UsageSourceReal      = "real"      // Example taken from indexed code
+	UsageSourceGenerated = "generated" // Illustrative example that does not appear in the code
+
+	MinUsageExamples = 2 // Generated examples pad real ones up to this count
 )
 
 // Token optimization constants
 const (
-	CharsPerToken  = 4   // Rough estimate: 4 characters per token
-	BodyTokenRatio = 0.5 // Body gets half of available tokens when balancing with context
+	CharsPerToken  = index.DefaultCharsPerToken // Rough estimate used by the default token estimator
+	BodyTokenRatio = 0.5                        // Body gets half of available tokens when balancing with context
 )
 
 // GetFunctionContextParams encapsulates get_function_context parameters
@@ -57,6 +114,12 @@ type GetFunctionContextParams struct {
 	IncludeImplementations bool
 	ContextLines           int
 	MaxTokens              int
+	SuggestSimilar         bool   // Name similar functions when the function is not found
+	Minimal                bool   // Return only name, signature and location, skipping all other lookups
+	StripComments          bool   // Remove comments and docstrings from the implementation body
+	IncludeSiblings        int    // Declarations of the same file to list on each side of the function
+	File                   string // Declaring file, picking one of several functions sharing the name
+	Budget                 FunctionContextBudget
 }
 
 // GetIncludeCallers implements QueryOptionsBuilder interface (not applicable)
@@ -73,10 +136,17 @@ func (p *GetFunctionContextParams) GetMaxTokens() int { return p.MaxTokens }
 
 // GetTypeContextParams encapsulates get_type_context parameters
 type GetTypeContextParams struct {
-	TypeName       string
-	IncludeMethods bool
-	IncludeUsage   bool
-	MaxTokens      int
+	TypeName        string
+	IncludeMethods  bool
+	IncludeUsage    bool
+	IncludeSubtypes bool
+	MaxTokens       int
+	SuggestSimilar  bool // Name similar types when the type is not found
+
+	// ResolveFieldTypes includes the definitions of indexed types referenced by the type's
+	// fields, following their fields in turn up to ResolveDepth levels
+	ResolveFieldTypes bool
+	ResolveDepth      int
 }
 
 // GetIncludeCallers implements QueryOptionsBuilder interface (not applicable)
@@ -102,6 +172,8 @@ type FunctionLocation struct {
 type FunctionImplementation struct {
 	Body         string   `json:"body"`
 	ContextLines []string `json:"context_lines"`
+	Source       string   `json:"source,omitempty"` // "index" or "working_tree"
+	Stale        bool     `json:"stale,omitempty"`  // The file changed since indexing, so lines may not match
 }
 
 // FunctionReference represents a reference to a function (caller or callee)
@@ -111,6 +183,14 @@ type FunctionReference struct {
 	Line int    `json:"line"`
 }
 
+// SiblingDeclaration is a declaration next to a function in its file
+type SiblingDeclaration struct {
+	Name      string `json:"name"`
+	Kind      string `json:"kind"`
+	Line      int    `json:"line"`
+	Signature string `json:"signature,omitempty"`
+}
+
 // TypeReference represents a reference to a type
 type TypeReference struct {
 	Name string `json:"name"`
@@ -122,11 +202,19 @@ type TypeReference struct {
 type FunctionContextResult struct {
 	FunctionName   string                  `json:"function_name"`
 	Signature      string                  `json:"signature"`
+	Doc            string                  `json:"doc,omitempty"`
 	Location       FunctionLocation        `json:"location"`
+	LineCount      int                     `json:"line_count,omitempty"`
+	Complexity     int                     `json:"complexity,omitempty"`    // Cyclomatic complexity, see models.ComplexityDefinition
+	IsRecursive    bool                    `json:"is_recursive,omitempty"`  // The function calls itself directly
+	LastModified   *time.Time              `json:"last_modified,omitempty"` // Newest commit touching the function, from git blame
+	LastCommit     string                  `json:"last_commit,omitempty"`
 	Implementation *FunctionImplementation `json:"implementation,omitempty"`
 	Callers        []FunctionReference     `json:"callers,omitempty"`
 	Callees        []FunctionReference     `json:"callees,omitempty"`
 	RelatedTypes   []TypeReference         `json:"related_types,omitempty"`
+	SiblingsBefore []SiblingDeclaration    `json:"siblings_before,omitempty"` // Declarations before the function, nearest last
+	SiblingsAfter  []SiblingDeclaration    `json:"siblings_after,omitempty"`  // Declarations after the function, nearest first
 	TokenCount     int                     `json:"token_count"`
 	Truncated      bool                    `json:"truncated"`
 }
@@ -140,18 +228,31 @@ type TypeLocation struct {
 
 // FieldReference represents a reference to a field in a type
 type FieldReference struct {
-	Name string `json:"name"`
-	Type string `json:"type"`
-	File string `json:"file"`
-	Line int    `json:"line"`
+	Name         string `json:"name"`
+	Type         string `json:"type"`
+	Default      string `json:"default,omitempty"` // Default value as written, e.g. for Python dataclass fields
+	File         string `json:"file"`
+	Line         int    `json:"line"`
+	PromotedFrom string `json:"promoted_from,omitempty"` // Embedded type declaring a promoted field
 }
 
 // MethodReference represents a reference to a method of a type
 type MethodReference struct {
-	Name      string `json:"name"`
-	Signature string `json:"signature"`
-	File      string `json:"file"`
-	Line      int    `json:"line"`
+	Name         string `json:"name"`
+	Signature    string `json:"signature"`
+	Doc          string `json:"doc,omitempty"`
+	File         string `json:"file"`
+	Line         int    `json:"line"`
+	PromotedFrom string `json:"promoted_from,omitempty"` // Embedded type declaring a promoted method
+}
+
+// ConstantReference represents a constant whose type is the subject type, such as an enum member
+type ConstantReference struct {
+	Name    string `json:"name"`
+	Value   string `json:"value,omitempty"`
+	GroupID string `json:"group_id,omitempty"`
+	File    string `json:"file"`
+	Line    int    `json:"line"`
 }
 
 // UsageExample represents an example of type usage
@@ -160,26 +261,56 @@ type UsageExample struct {
 	Code        string `json:"code"`
 	File        string `json:"file"`
 	Line        int    `json:"line"`
+	Source      string `json:"source"` // UsageSourceReal or UsageSourceGenerated
+}
+
+// ResolvedField is a field of a resolved type, reduced to its name and type
+type ResolvedField struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// ResolvedType is the definition of an indexed type reached through the subject type's fields
+type ResolvedType struct {
+	Name      string          `json:"name"`
+	Kind      string          `json:"kind"`
+	Signature string          `json:"signature"`
+	File      string          `json:"file"`
+	Line      int             `json:"line"`
+	Depth     int             `json:"depth"` // 1 for the types of the subject's fields, 2 for the types of their fields
+	Fields    []ResolvedField `json:"fields,omitempty"`
 }
 
 // TypeContextResult represents the complete result of type context analysis
 type TypeContextResult struct {
-	TypeName      string            `json:"type_name"`
-	Signature     string            `json:"signature"`
-	Location      TypeLocation      `json:"location"`
-	Fields        []FieldReference  `json:"fields,omitempty"`
-	Methods       []MethodReference `json:"methods,omitempty"`
-	UsageExamples []UsageExample    `json:"usage_examples,omitempty"`
-	RelatedTypes  []TypeReference   `json:"related_types,omitempty"`
-	TokenCount    int               `json:"token_count"`
-	Truncated     bool              `json:"truncated"`
+	TypeName      string              `json:"type_name"`
+	Signature     string              `json:"signature"`
+	Doc           string              `json:"doc,omitempty"`
+	Location      TypeLocation        `json:"location"`
+	BaseTypes     []string            `json:"base_types,omitempty"`    // Base classes or embedded types as written, e.g. "db.Model"
+	LastModified  *time.Time          `json:"last_modified,omitempty"` // Newest commit touching the type, from git blame
+	LastCommit    string              `json:"last_commit,omitempty"`
+	Fields        []FieldReference    `json:"fields,omitempty"`
+	Methods       []MethodReference   `json:"methods,omitempty"`
+	Constructors  []MethodReference   `json:"constructors,omitempty"` // Functions returning the type, e.g. NewUser
+	Constants     []ConstantReference `json:"constants,omitempty"`
+	UsageExamples []UsageExample      `json:"usage_examples,omitempty"`
+	RelatedTypes  []TypeReference     `json:"related_types,omitempty"`
+	Subtypes      []TypeReference     `json:"subtypes,omitempty"` // Types that inherit from or embed this type
+	ResolvedTypes []ResolvedType      `json:"resolved_types,omitempty"`
+	TokenCount    int                 `json:"token_count"`
+	Truncated     bool                `json:"truncated"`
+
+	// ResolvedTypesTruncated is set when resolution stopped at the token budget before
+	// reaching the requested depth
+	ResolvedTypesTruncated bool `json:"resolved_types_truncated,omitempty"`
 }
 
 // ToolOperations defines the tool-specific operations for the generic handler
 type ToolOperations[P any, R any] struct {
 	ParseParams    func(mcp.CallToolRequest) (P, error)
 	BuildResult    func(P) (R, error)
-	OptimizeResult func(R, int)
+	OptimizeResult func(P, R, int)
 	ToolName       string
 }
 
@@ -211,7 +342,7 @@ func executeGenericToolHandler[P any, R any](
 
 	// Tool-specific optimization
 	if paramsWithTokens, ok := any(params).(interface{ GetMaxTokens() int }); ok {
-		ops.OptimizeResult(result, paramsWithTokens.GetMaxTokens())
+		ops.OptimizeResult(params, result, paramsWithTokens.GetMaxTokens())
 	}
 
 	return s.FormatSuccessResponse(result), nil
@@ -222,8 +353,8 @@ func (s *RepoContextMCPServer) HandleGetFunctionContext(ctx context.Context, req
 	ops := ToolOperations[*GetFunctionContextParams, *FunctionContextResult]{
 		ParseParams: s.parseGetFunctionContextParameters,
 		BuildResult: s.buildFunctionContextResult,
-		OptimizeResult: func(result *FunctionContextResult, maxTokens int) {
-			s.optimizeFunctionContextResponse(result, maxTokens)
+		OptimizeResult: func(params *GetFunctionContextParams, result *FunctionContextResult, maxTokens int) {
+			s.optimizeFunctionContextResponse(result, maxTokens, params.Budget)
 		},
 		ToolName: "get_function_context",
 	}
@@ -235,7 +366,7 @@ func (s *RepoContextMCPServer) HandleGetTypeContext(ctx context.Context, request
 	ops := ToolOperations[*GetTypeContextParams, *TypeContextResult]{
 		ParseParams: s.parseGetTypeContextParameters,
 		BuildResult: s.buildTypeContextResult,
-		OptimizeResult: func(result *TypeContextResult, maxTokens int) {
+		OptimizeResult: func(_ *GetTypeContextParams, result *TypeContextResult, maxTokens int) {
 			s.optimizeTypeContextResponse(result, maxTokens)
 		},
 		ToolName: "get_type_context",
@@ -243,13 +374,13 @@ func (s *RepoContextMCPServer) HandleGetTypeContext(ctx context.Context, request
 	return executeGenericToolHandler(s, request, ops)
 }
 
-// validateContextLines validates and normalizes context lines parameter
-func validateContextLines(contextLines int) int {
+// validateContextLines validates and normalizes context lines parameter, capping it at maxContextLines
+func validateContextLines(contextLines, maxContextLines int) int {
 	if contextLines <= 0 {
-		return DefaultContextLines
+		return min(DefaultContextLines, maxContextLines)
 	}
-	if contextLines > MaxContextLines {
-		return MaxContextLines
+	if contextLines > maxContextLines {
+		return maxContextLines
 	}
 	return contextLines
 }
@@ -262,16 +393,67 @@ func (s *RepoContextMCPServer) parseGetFunctionContextParameters(request mcp.Cal
 	}
 
 	contextLines := request.GetInt("context_lines", DefaultContextLines)
-	validatedContextLines := validateContextLines(contextLines)
+	validatedContextLines := validateContextLines(contextLines, s.getMaxContextLines())
+
+	budget, err := parseFunctionContextBudget(request)
+	if err != nil {
+		return nil, err
+	}
+
+	siblings := request.GetInt("include_siblings", 0)
+	if siblings < 0 {
+		return nil, fmt.Errorf("include_siblings must not be negative, got %d", siblings)
+	}
 
 	return &GetFunctionContextParams{
 		FunctionName:           functionName,
 		IncludeImplementations: request.GetBool("include_implementations", false),
 		ContextLines:           validatedContextLines,
-		MaxTokens:              request.GetInt("max_tokens", constMaxTokens),
+		MaxTokens:              request.GetInt("max_tokens", s.getMaxTokens()),
+		SuggestSimilar:         request.GetBool("suggest_similar", true),
+		Minimal:                request.GetBool("minimal", false),
+		StripComments:          request.GetBool("strip_comments", false),
+		IncludeSiblings:        min(siblings, MaxSiblingDeclarations),
+		File:                   s.repoRelativePath(strings.TrimSpace(request.GetString("file", ""))),
+		Budget:                 budget,
 	}, nil
 }
 
+// parseFunctionContextBudget reads the token budget split of get_function_context: the
+// focus preset, with any ratio given explicitly overriding the preset's share
+func parseFunctionContextBudget(request mcp.CallToolRequest) (FunctionContextBudget, error) {
+	focus := strings.ToLower(strings.TrimSpace(request.GetString("focus", FocusBalanced)))
+	budget, found := functionContextFocuses[focus]
+	if !found {
+		return budget, fmt.Errorf("focus must be one of %s, %s or %s, got %q", FocusBalanced, FocusImplementation, FocusRelationships, focus)
+	}
+
+	ratios := []struct {
+		name  string
+		share *float64
+	}{
+		{"implementation_ratio", &budget.Implementation},
+		{"callers_ratio", &budget.Callers},
+		{"callees_ratio", &budget.Callees},
+		{"types_ratio", &budget.Types},
+	}
+	total := 0.0
+	for _, ratio := range ratios {
+		if _, given := request.GetArguments()[ratio.name]; given {
+			*ratio.share = request.GetFloat(ratio.name, 0)
+		}
+		if *ratio.share < 0 || *ratio.share > 1 {
+			return budget, fmt.Errorf("%s must be between 0 and 1, got %g", ratio.name, *ratio.share)
+		}
+		total += *ratio.share
+	}
+	// Allow for rounding in ratios such as 0.7 + 0.2 + 0.1
+	if total > 1+1e-9 {
+		return budget, fmt.Errorf("token ratios must not sum to more than 1, got %g", total)
+	}
+	return budget, nil
+}
+
 // parseGetTypeContextParameters extracts and validates get_type_context parameters
 func (s *RepoContextMCPServer) parseGetTypeContextParameters(request mcp.CallToolRequest) (*GetTypeContextParams, error) {
 	typeName := strings.TrimSpace(request.GetString("type_name", ""))
@@ -279,11 +461,20 @@ func (s *RepoContextMCPServer) parseGetTypeContextParameters(request mcp.CallToo
 		return nil, fmt.Errorf("type_name parameter is required")
 	}
 
+	resolveDepth := request.GetInt("resolve_depth", DefaultResolveDepth)
+	if resolveDepth <= 0 {
+		resolveDepth = DefaultResolveDepth
+	}
+
 	return &GetTypeContextParams{
-		TypeName:       typeName,
-		IncludeMethods: request.GetBool("include_methods", false),
-		IncludeUsage:   request.GetBool("include_usage", false),
-		MaxTokens:      request.GetInt("max_tokens", constMaxTokens),
+		TypeName:          typeName,
+		IncludeMethods:    request.GetBool("include_methods", false),
+		IncludeUsage:      request.GetBool("include_usage", false),
+		IncludeSubtypes:   request.GetBool("include_subtypes", false),
+		MaxTokens:         request.GetInt("max_tokens", s.getMaxTokens()),
+		SuggestSimilar:    request.GetBool("suggest_similar", true),
+		ResolveFieldTypes: request.GetBool("resolve_field_types", false),
+		ResolveDepth:      min(resolveDepth, MaxResolveDepth),
 	}, nil
 }
 
@@ -293,13 +484,47 @@ func (s *RepoContextMCPServer) createGetFunctionContextTool() mcp.Tool {
 		mcp.WithDescription(
 			"Get complete context for a function including signature, implementation details, callers, callees, and related types",
 		),
-		mcp.WithString("function_name", mcp.Required(), mcp.Description("Function name to analyze")),
+		mcp.WithString("function_name", mcp.Required(), mcp.Description(
+			"Function name to analyze, optionally qualified by type or package, e.g. 'User.Save' or 'models.NewUser'. "+
+				"When several functions share the name, the error lists them instead of picking one",
+		)),
+		mcp.WithString("file", mcp.Description(
+			"File declaring the function, relative to the repository root, to pick one of several functions sharing its name",
+		)),
 		mcp.WithBoolean("include_implementations", mcp.Description("Include function implementation details (default: false)")),
-		mcp.WithNumber("context_lines", mcp.Description("Number of context lines around function (default: 5, max: 50)")),
+		mcp.WithNumber("context_lines", mcp.Description(fmt.Sprintf(
+			"Number of context lines around function (default: %d, max: %d)", DefaultContextLines, s.getMaxContextLines(),
+		))),
 		mcp.WithNumber("max_tokens", mcp.Description("Maximum tokens for response (default: 2000)")),
+		mcp.WithBoolean("suggest_similar", mcp.Description(suggestSimilarParamDescription)),
+		mcp.WithBoolean("minimal", mcp.Description(
+			"Return only the name, signature and location, skipping call graph, related type and implementation lookups (default: false)",
+		)),
+		mcp.WithBoolean("strip_comments", mcp.Description(
+			"Remove comments and docstrings from the implementation body to save tokens (default: false)",
+		)),
+		mcp.WithString("focus", mcp.Description(
+			"How max_tokens is split when the response must be trimmed: 'balanced' (40% implementation, 25% callers, "+
+				"25% callees, 10% types), 'implementation' (the whole budget on the body) or 'relationships' (default: balanced)",
+		)),
+		mcp.WithNumber("implementation_ratio", mcp.Description(ratioParamDescription("the implementation"))),
+		mcp.WithNumber("callers_ratio", mcp.Description(ratioParamDescription("callers"))),
+		mcp.WithNumber("callees_ratio", mcp.Description(ratioParamDescription("callees"))),
+		mcp.WithNumber("types_ratio", mcp.Description(ratioParamDescription("related types"))),
+		mcp.WithNumber("include_siblings", mcp.Description(fmt.Sprintf(
+			"List the signatures of up to this many declarations before and after the function in its file, "+
+				"such as helpers defined next to it; dropped farthest first to fit max_tokens (default: 0, max: %d)",
+			MaxSiblingDeclarations,
+		))),
 	)
 }
 
+// ratioParamDescription describes a get_function_context parameter setting the share of a section
+func ratioParamDescription(section string) string {
+	return "Share of max_tokens, from 0 to 1, for " + section + ", overriding focus; 0 leaves the section out. " +
+		"The ratios must sum to at most 1"
+}
+
 // createGetTypeContextTool creates the get_type_context tool
 func (s *RepoContextMCPServer) createGetTypeContextTool() mcp.Tool {
 	return mcp.NewTool("get_type_context",
@@ -307,9 +532,22 @@ func (s *RepoContextMCPServer) createGetTypeContextTool() mcp.Tool {
 			"Get complete context for a type including signature, fields, methods, usage examples, and related types",
 		),
 		mcp.WithString("type_name", mcp.Required(), mcp.Description("Type name to analyze")),
-		mcp.WithBoolean("include_methods", mcp.Description("Include all methods for the type (default: false)")),
+		mcp.WithBoolean("include_methods", mcp.Description(
+			"Include all methods for the type and the constructors returning it, e.g. NewUser (default: false)",
+		)),
 		mcp.WithBoolean("include_usage", mcp.Description("Include usage examples (default: false)")),
+		mcp.WithBoolean("include_subtypes", mcp.Description(
+			"Include types that inherit from or embed this type, e.g. subclasses of a base class (default: false)",
+		)),
+		mcp.WithBoolean("resolve_field_types", mcp.Description(
+			"Include the signature and fields of indexed types referenced by this type's fields, "+
+				"saving a lookup per nested type (default: false)",
+		)),
+		mcp.WithNumber("resolve_depth", mcp.Description(fmt.Sprintf(
+			"Levels of nested field types to resolve with resolve_field_types (default: %d, max: %d)", DefaultResolveDepth, MaxResolveDepth,
+		))),
 		mcp.WithNumber("max_tokens", mcp.Description("Maximum tokens for response (default: 2000)")),
+		mcp.WithBoolean("suggest_similar", mcp.Description(suggestSimilarParamDescription)),
 	)
 }
 
@@ -318,11 +556,15 @@ func (s *RepoContextMCPServer) RegisterContextTools() []mcp.Tool {
 	return []mcp.Tool{
 		s.createGetFunctionContextTool(),
 		s.createGetTypeContextTool(),
+		s.createSummarizeFunctionTool(),
 	}
 }
 
 // optimizeFunctionContextResponse optimizes function context response for token limits
-func (s *RepoContextMCPServer) optimizeFunctionContextResponse(result *FunctionContextResult, maxTokens int) {
+func (s *RepoContextMCPServer) optimizeFunctionContextResponse(result *FunctionContextResult, maxTokens int, budget FunctionContextBudget) {
+	// Sections without a share of the budget are not wanted at all
+	dropUnbudgetedSections(result, budget)
+
 	// Calculate current token count
 	currentTokens := s.estimateFunctionContextTokens(result)
 	result.TokenCount = currentTokens
@@ -336,6 +578,12 @@ func (s *RepoContextMCPServer) optimizeFunctionContextResponse(result *FunctionC
 	// Mark as truncated
 	result.Truncated = true
 
+	// Siblings are the least related context, so they give way first
+	if currentTokens = s.trimSiblings(result, currentTokens, maxTokens); currentTokens <= maxTokens {
+		result.TokenCount = currentTokens
+		return
+	}
+
 	// Calculate available tokens for content (reserve tokens for metadata)
 	availableTokens := maxTokens - FunctionContextBaseTokens
 	if availableTokens <= 0 {
@@ -348,11 +596,11 @@ func (s *RepoContextMCPServer) optimizeFunctionContextResponse(result *FunctionC
 		return
 	}
 
-	// Distribute tokens according to ratios
-	implementationTokens := int(float64(availableTokens) * ImplementationTokenRatio)
-	callersTokens := int(float64(availableTokens) * CallersTokenRatio)
-	calleesTokens := int(float64(availableTokens) * CalleesTokenRatio)
-	typesTokens := int(float64(availableTokens) * TypesTokenRatio)
+	// Distribute tokens according to the budget
+	implementationTokens := int(float64(availableTokens) * budget.Implementation)
+	callersTokens := int(float64(availableTokens) * budget.Callers)
+	calleesTokens := int(float64(availableTokens) * budget.Callees)
+	typesTokens := int(float64(availableTokens) * budget.Types)
 
 	// Optimize implementation
 	if result.Implementation != nil {
@@ -387,6 +635,22 @@ func (s *RepoContextMCPServer) optimizeFunctionContextResponse(result *FunctionC
 	result.TokenCount = s.estimateFunctionContextTokens(result)
 }
 
+// dropUnbudgetedSections removes the sections of a function context with no share of the budget
+func dropUnbudgetedSections(result *FunctionContextResult, budget FunctionContextBudget) {
+	if budget.Implementation == 0 {
+		result.Implementation = nil
+	}
+	if budget.Callers == 0 {
+		result.Callers = nil
+	}
+	if budget.Callees == 0 {
+		result.Callees = nil
+	}
+	if budget.Types == 0 {
+		result.RelatedTypes = nil
+	}
+}
+
 // estimateFunctionContextTokens estimates token count for function context result
 func (s *RepoContextMCPServer) estimateFunctionContextTokens(result *FunctionContextResult) int {
 	tokens := FunctionContextBaseTokens
@@ -394,7 +658,7 @@ func (s *RepoContextMCPServer) estimateFunctionContextTokens(result *FunctionCon
 	// Add implementation tokens
 	if result.Implementation != nil {
 		tokens += ImplementationOverheadTokens
-		tokens += len(result.Implementation.Body) / CharsPerToken // Rough estimate
+		tokens += s.estimateTextTokens(result.Implementation.Body)
 		tokens += len(result.Implementation.ContextLines) * ContextLineTokens
 	}
 
@@ -407,6 +671,31 @@ func (s *RepoContextMCPServer) estimateFunctionContextTokens(result *FunctionCon
 	// Add type tokens
 	tokens += len(result.RelatedTypes) * TypeRefTokens
 
+	// Add sibling tokens
+	for _, sibling := range slices.Concat(result.SiblingsBefore, result.SiblingsAfter) {
+		tokens += s.estimateSiblingTokens(sibling)
+	}
+
+	return tokens
+}
+
+// estimateSiblingTokens estimates the tokens of a sibling declaration
+func (s *RepoContextMCPServer) estimateSiblingTokens(sibling SiblingDeclaration) int {
+	return SiblingRefTokens + s.estimateTextTokens(sibling.Signature)
+}
+
+// trimSiblings drops sibling declarations farthest from the function first, alternating
+// sides, until the response fits maxTokens or no sibling is left, and returns its tokens
+func (s *RepoContextMCPServer) trimSiblings(result *FunctionContextResult, tokens, maxTokens int) int {
+	for tokens > maxTokens && len(result.SiblingsBefore)+len(result.SiblingsAfter) > 0 {
+		if len(result.SiblingsBefore) >= len(result.SiblingsAfter) {
+			tokens -= s.estimateSiblingTokens(result.SiblingsBefore[0])
+			result.SiblingsBefore = result.SiblingsBefore[1:]
+		} else {
+			tokens -= s.estimateSiblingTokens(result.SiblingsAfter[len(result.SiblingsAfter)-1])
+			result.SiblingsAfter = result.SiblingsAfter[:len(result.SiblingsAfter)-1]
+		}
+	}
 	return tokens
 }
 
@@ -420,8 +709,8 @@ func (s *RepoContextMCPServer) optimizeImplementation(impl *FunctionImplementati
 
 	availableTokens := tokenLimit - ImplementationOverheadTokens
 
-	// Optimize body (rough estimate: 4 chars per token)
-	bodyTokens := len(impl.Body) / CharsPerToken
+	// Optimize body using the configured token estimator
+	bodyTokens := s.estimateTextTokens(impl.Body)
 	contextTokens := len(impl.ContextLines) * ContextLineTokens
 
 	if bodyTokens+contextTokens <= availableTokens {
@@ -431,11 +720,10 @@ func (s *RepoContextMCPServer) optimizeImplementation(impl *FunctionImplementati
 	// Prioritize body over context lines using ratio
 	bodyTokenLimit := int(float64(availableTokens) * BodyTokenRatio)
 	if bodyTokens > bodyTokenLimit {
-		maxBodyChars := bodyTokenLimit * CharsPerToken
-		if len(impl.Body) > maxBodyChars {
-			impl.Body = impl.Body[:maxBodyChars] + "..."
+		if truncatedBody, truncated := index.TruncateToTokens(s.getTokenEstimator(), impl.Body, bodyTokenLimit); truncated {
+			impl.Body = truncatedBody + "..."
 		}
-		availableTokens -= len(impl.Body) / CharsPerToken
+		availableTokens -= s.estimateTextTokens(impl.Body)
 	}
 
 	// Optimize context lines
@@ -463,36 +751,17 @@ func (s *RepoContextMCPServer) calculateMaxTypeRefs(tokenLimit int) int {
 
 // buildFunctionContextResult constructs the complete function context result
 func (s *RepoContextMCPServer) buildFunctionContextResult(params *GetFunctionContextParams) (*FunctionContextResult, error) {
-	// Search for the function
-	queryOptions := index.QueryOptions{
-		IncludeCallers: true, // Always include callers for context
-		IncludeCallees: true, // Always include callees for context
-		IncludeTypes:   true, // Always include related types for context
-		MaxTokens:      params.MaxTokens,
-		Format:         "json",
-	}
-
-	searchResult, err := s.QueryEngine.SearchByNameWithOptions(params.FunctionName, queryOptions)
+	// Resolve the function, refusing to guess between functions sharing its name
+	matches, err := s.QueryEngine.FindUniqueFunction(params.FunctionName, index.UniqueFunctionOptions{File: params.File})
 	if err != nil {
 		return nil, fmt.Errorf("function search failed: %w", err)
 	}
-
-	if len(searchResult.Entries) == 0 {
-		return nil, fmt.Errorf("function '%s' not found", params.FunctionName)
-	}
-
-	// Find the function in search results
-	var functionEntry *index.SearchResultEntry
-	for i := range searchResult.Entries {
-		entry := &searchResult.Entries[i]
-		if entry.IndexEntry.Type == index.EntityTypeFunction && entry.IndexEntry.Name == params.FunctionName {
-			functionEntry = entry
-			break
-		}
+	if matches.Ambiguous() {
+		return nil, s.ambiguousFunctionError(params.FunctionName, matches.Matches)
 	}
-
+	functionEntry := matches.Function
 	if functionEntry == nil {
-		return nil, fmt.Errorf("function '%s' not found in search results", params.FunctionName)
+		return nil, s.notFoundError("function", params.FunctionName, params.SuggestSimilar, isFunctionKind)
 	}
 
 	// Build the result
@@ -505,29 +774,140 @@ func (s *RepoContextMCPServer) buildFunctionContextResult(params *GetFunctionCon
 			EndLine:   functionEntry.IndexEntry.EndLine,
 		},
 	}
+	if params.Minimal {
+		return result, nil
+	}
+
+	result.Doc = s.findFunctionDoc(functionEntry)
+	if function := s.findFunctionModel(functionEntry); function != nil {
+		result.LineCount = function.LineCount
+		result.Complexity = function.Complexity
+		result.IsRecursive = function.IsRecursive
+		result.LastModified = function.LastModified
+		result.LastCommit = function.LastCommit
+	}
 
 	// Add implementation details if requested
 	if params.IncludeImplementations {
-		implementation := s.buildFunctionImplementation(functionEntry, params.ContextLines)
+		implementation := s.buildFunctionImplementation(functionEntry, params.ContextLines, params.StripComments)
 		result.Implementation = implementation
 	}
 
-	// Add callers
-	result.Callers = s.extractFunctionReferences(searchResult.CallGraph.Callers)
+	// Add callers and callees
+	callGraph, err := s.QueryEngine.GetCallGraphWithOptions(functionEntry.IndexEntry.Name, index.QueryOptions{
+		IncludeCallers: true,
+		IncludeCallees: true,
+		MaxTokens:      params.MaxTokens,
+	})
+	if err == nil {
+		result.Callers = s.extractFunctionReferences(callGraph.Callers)
+		result.Callees = s.extractFunctionReferences(callGraph.Callees)
+	}
 
-	// Add callees
-	result.Callees = s.extractFunctionReferences(searchResult.CallGraph.Callees)
+	// Add types referenced by the function's parameters and returns
+	result.RelatedTypes = s.extractFunctionTypeReferences(functionEntry)
 
-	// Add related types
-	result.RelatedTypes = s.extractTypeReferences(searchResult.Entries)
+	// Add the declarations around the function in its file
+	if params.IncludeSiblings > 0 {
+		before, after, err := s.QueryEngine.FindSiblingDeclarations(
+			functionEntry.IndexEntry.File, functionEntry.IndexEntry.StartLine, functionEntry.IndexEntry.EndLine, params.IncludeSiblings,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("sibling search failed: %w", err)
+		}
+		result.SiblingsBefore = siblingDeclarations(before)
+		result.SiblingsAfter = siblingDeclarations(after)
+	}
 
 	return result, nil
 }
 
+// siblingDeclarations converts sibling entries to their declarations
+func siblingDeclarations(entries []index.SearchResultEntry) []SiblingDeclaration {
+	siblings := make([]SiblingDeclaration, 0, len(entries))
+	for i := range entries {
+		entry := &entries[i].IndexEntry
+		siblings = append(siblings, SiblingDeclaration{Name: entry.Name, Kind: entry.Type, Line: entry.StartLine, Signature: entry.Signature})
+	}
+	return siblings
+}
+
+// ambiguousFunctionError lists the functions sharing a name, with the file or qualified
+// name picking each, so the caller can narrow the request rather than get a guess
+func (s *RepoContextMCPServer) ambiguousFunctionError(name string, matches []index.SearchResultEntry) error {
+	candidates := make([]string, len(matches))
+	for i := range matches {
+		entry := &matches[i].IndexEntry
+		candidates[i] = fmt.Sprintf("%s:%d %s", entry.File, entry.StartLine, entry.Signature)
+		if function := s.findFunctionModel(&matches[i]); function != nil && function.Receiver != "" {
+			candidates[i] += fmt.Sprintf(" (%s.%s)", function.Receiver, entry.Name)
+		}
+	}
+	return fmt.Errorf("function '%s' is ambiguous, %d functions match: %s; narrow it with file or a qualified name",
+		name, len(matches), strings.Join(candidates, "; "))
+}
+
+// findFunctionDoc returns the documentation comment for a function entry from its chunk data
+func (s *RepoContextMCPServer) findFunctionDoc(entry *index.SearchResultEntry) string {
+	if function := s.findFunctionModel(entry); function != nil {
+		return function.Doc
+	}
+	return ""
+}
+
+// findTypeDoc returns the documentation comment for a type entry from its chunk data
+func (s *RepoContextMCPServer) findTypeDoc(entry *index.SearchResultEntry) string {
+	if typeDef := s.findTypeModel(entry); typeDef != nil {
+		return typeDef.Doc
+	}
+	return ""
+}
+
+// findFunctionModel returns the parsed function matching an entry from its chunk data
+func (s *RepoContextMCPServer) findFunctionModel(entry *index.SearchResultEntry) *models.Function {
+	if entry.ChunkData == nil {
+		return nil
+	}
+	for i := range entry.ChunkData.FileData {
+		fileData := &entry.ChunkData.FileData[i]
+		if fileData.Path != entry.IndexEntry.File {
+			continue
+		}
+		for j := range fileData.Functions {
+			function := &fileData.Functions[j]
+			if function.Name == entry.IndexEntry.Name && function.StartLine == entry.IndexEntry.StartLine {
+				return function
+			}
+		}
+	}
+	return nil
+}
+
+// findTypeModel returns the parsed type definition matching an entry from its chunk data
+func (s *RepoContextMCPServer) findTypeModel(entry *index.SearchResultEntry) *models.TypeDef {
+	if entry.ChunkData == nil {
+		return nil
+	}
+	for i := range entry.ChunkData.FileData {
+		fileData := &entry.ChunkData.FileData[i]
+		if fileData.Path != entry.IndexEntry.File {
+			continue
+		}
+		for j := range fileData.Types {
+			typeDef := &fileData.Types[j]
+			if typeDef.Name == entry.IndexEntry.Name && typeDef.StartLine == entry.IndexEntry.StartLine {
+				return typeDef
+			}
+		}
+	}
+	return nil
+}
+
 // buildFunctionImplementation constructs function implementation details
 func (s *RepoContextMCPServer) buildFunctionImplementation(
 	entry *index.SearchResultEntry,
 	contextLines int,
+	stripComments bool,
 ) *FunctionImplementation {
 	// Calculate available tokens for implementation (reserve tokens for other response parts)
 	availableTokens := ImplementationOverheadTokens // Start with base overhead
@@ -545,6 +925,9 @@ func (s *RepoContextMCPServer) buildFunctionImplementation(
 	// Use the storage layer to extract the actual function implementation
 	if s.Storage != nil {
 		if impl, err := s.Storage.GetFunctionImplementation(functionName, contextLines); err == nil {
+			if stripComments {
+				impl.Body = index.StripComments(impl.Body, index.EntryLanguage(entry))
+			}
 			// Successfully extracted real implementation - apply token limits
 			optimizedImpl := s.optimizeImplementationWithTokenLimits(impl, bodyTokenLimit, contextTokenLimit)
 			return optimizedImpl
@@ -633,14 +1016,15 @@ func (s *RepoContextMCPServer) optimizeImplementationWithTokenLimits(
 	result := &FunctionImplementation{
 		Body:         impl.Body,
 		ContextLines: impl.ContextLines,
+		Source:       impl.Source,
+		Stale:        impl.Stale,
 	}
 
 	// Apply body token limit
-	bodyTokens := len(impl.Body) / CharsPerToken
+	bodyTokens := s.estimateTextTokens(impl.Body)
 	if bodyTokens > bodyTokenLimit {
-		maxBodyChars := bodyTokenLimit * CharsPerToken
-		if len(impl.Body) > maxBodyChars {
-			result.Body = impl.Body[:maxBodyChars] + "\n\n// ... implementation truncated due to token limits ..."
+		if truncatedBody, truncated := index.TruncateToTokens(s.getTokenEstimator(), impl.Body, bodyTokenLimit); truncated {
+			result.Body = truncatedBody + "\n\n// ... implementation truncated due to token limits ..."
 		}
 	}
 
@@ -678,13 +1062,176 @@ func (s *RepoContextMCPServer) extractFunctionReferences(entries []index.CallGra
 	return refs
 }
 
-// extractTypeReferences converts search results to type references
-func (s *RepoContextMCPServer) extractTypeReferences(entries []index.SearchResultEntry) []TypeReference {
+// extractFunctionTypeReferences returns the indexed types used by a function's parameters and returns
+func (s *RepoContextMCPServer) extractFunctionTypeReferences(entry *index.SearchResultEntry) []TypeReference {
+	function := s.findFunctionModel(entry)
+	if function == nil {
+		return nil
+	}
+
+	var typeExprs []string
+	for _, param := range function.Parameters {
+		typeExprs = append(typeExprs, param.Type)
+	}
+	for _, ret := range function.Returns {
+		typeExprs = append(typeExprs, ret.Name)
+	}
+
+	return s.resolveTypeReferences(referencedTypeNames(typeExprs), "")
+}
+
+// extractTypeTypeReferences returns the indexed types embedded in or referenced by a type's fields
+func (s *RepoContextMCPServer) extractTypeTypeReferences(entry *index.SearchResultEntry) []TypeReference {
+	typeDef := s.findTypeModel(entry)
+	if typeDef == nil {
+		return nil
+	}
+
+	return s.resolveTypeReferences(referencedTypeNames(fieldTypeExprs(typeDef)), typeDef.Name)
+}
+
+// fieldTypeExprs returns the type expressions of a type's embedded types and fields
+func fieldTypeExprs(typeDef *models.TypeDef) []string {
+	typeExprs := append([]string{}, typeDef.Embedded...)
+	for _, field := range typeDef.Fields {
+		typeExprs = append(typeExprs, field.Type)
+	}
+	return typeExprs
+}
+
+// resolveFieldTypes fetches the definitions of the indexed types referenced by a type's
+// fields, then those referenced by their fields, breadth first up to maxDepth levels.
+// Each type is resolved once. Resolution stops, reporting truncated, before the
+// estimated size of the definitions would exceed tokenBudget.
+func (s *RepoContextMCPServer) resolveFieldTypes(
+	typeDef *models.TypeDef, maxDepth, tokenBudget int,
+) (resolved []ResolvedType, truncated bool) {
+	visited := map[string]bool{typeDef.Name: true}
+	frontier := []*models.TypeDef{typeDef}
+	tokens := 0
+
+	for depth := 1; depth <= maxDepth && len(frontier) > 0; depth++ {
+		var next []*models.TypeDef
+		for _, parent := range frontier {
+			for _, name := range referencedTypeNames(fieldTypeExprs(parent)) {
+				if visited[name] {
+					continue
+				}
+				visited[name] = true
+
+				for _, definition := range s.findTypeDefinitions(name) {
+					resolvedType := newResolvedType(definition.entry, definition.typeDef, depth)
+					cost := estimateResolvedTypeTokens(&resolvedType)
+					if tokens+cost > tokenBudget {
+						return resolved, true
+					}
+					tokens += cost
+					resolved = append(resolved, resolvedType)
+					next = append(next, definition.typeDef)
+				}
+			}
+		}
+		frontier = next
+	}
+
+	return resolved, false
+}
+
+// typeDefinition is an indexed type entry and its parsed definition
+type typeDefinition struct {
+	entry   *index.SearchResultEntry
+	typeDef *models.TypeDef
+}
+
+// findTypeDefinitions returns the indexed types named name with their parsed definitions
+func (s *RepoContextMCPServer) findTypeDefinitions(name string) []typeDefinition {
+	searchResult, err := s.QueryEngine.SearchByName(name)
+	if err != nil {
+		return nil
+	}
+
+	var definitions []typeDefinition
+	for i := range searchResult.Entries {
+		entry := &searchResult.Entries[i]
+		if !index.IsTypeKind(entry.IndexEntry.Type) {
+			continue
+		}
+		if typeDef := s.findTypeModel(entry); typeDef != nil {
+			definitions = append(definitions, typeDefinition{entry: entry, typeDef: typeDef})
+		}
+	}
+	return definitions
+}
+
+// newResolvedType summarizes a type definition reached depth levels from the subject type
+func newResolvedType(entry *index.SearchResultEntry, typeDef *models.TypeDef, depth int) ResolvedType {
+	resolvedType := ResolvedType{
+		Name:      entry.IndexEntry.Name,
+		Kind:      entry.IndexEntry.Type,
+		Signature: entry.IndexEntry.Signature,
+		File:      entry.IndexEntry.File,
+		Line:      entry.IndexEntry.StartLine,
+		Depth:     depth,
+	}
+	for _, field := range typeDef.Fields {
+		resolvedType.Fields = append(resolvedType.Fields, ResolvedField{Name: field.Name, Type: field.Type})
+	}
+	return resolvedType
+}
+
+// estimateResolvedTypeTokens estimates the tokens a resolved type adds to a response
+func estimateResolvedTypeTokens(resolvedType *ResolvedType) int {
+	return TypeRefTokens + len(resolvedType.Signature)/CharsPerToken + len(resolvedType.Fields)*ResolvedFieldTokens
+}
+
+// findSubtypes returns the indexed types that list typeName as a base class or embedded type.
+// Bases are compared by their unqualified name, so "db.Model" and "Repository<User>" count as
+// subtypes of Model and Repository.
+func (s *RepoContextMCPServer) findSubtypes(typeName string) []TypeReference {
+	searchResult, err := s.QueryEngine.SearchByTypesWithOptions(index.TypeKinds(), index.QueryOptions{})
+	if err != nil {
+		return nil
+	}
+
+	var subtypes []TypeReference
+	for i := range searchResult.Entries {
+		entry := &searchResult.Entries[i]
+		typeDef := s.findTypeModel(entry)
+		if typeDef == nil {
+			continue
+		}
+		for _, base := range typeDef.Embedded {
+			if names := referencedTypeNames([]string{base}); len(names) > 0 && names[0] == typeName {
+				subtypes = append(subtypes, TypeReference{
+					Name: entry.IndexEntry.Name,
+					File: entry.IndexEntry.File,
+					Line: entry.IndexEntry.StartLine,
+				})
+				break
+			}
+		}
+	}
+
+	return subtypes
+}
+
+// resolveTypeReferences looks up type names in the index, skipping names that are not
+// indexed types (builtins, external packages) and the subject type itself
+func (s *RepoContextMCPServer) resolveTypeReferences(names []string, exclude string) []TypeReference {
 	var typeRefs []TypeReference
 
-	for _, entry := range entries {
-		entryType := entry.IndexEntry.Type
-		if entryType == index.EntityTypeType {
+	for _, name := range names {
+		if name == exclude {
+			continue
+		}
+		searchResult, err := s.QueryEngine.SearchByName(name)
+		if err != nil {
+			continue
+		}
+		for _, entry := range searchResult.Entries {
+			if !index.IsTypeKind(entry.IndexEntry.Type) {
+				continue
+			}
 			typeRefs = append(typeRefs, TypeReference{
 				Name: entry.IndexEntry.Name,
 				File: entry.IndexEntry.File,
@@ -696,6 +1243,49 @@ func (s *RepoContextMCPServer) extractTypeReferences(entries []index.SearchResul
 	return typeRefs
 }
 
+// referencedTypeNames extracts the distinct identifiers named in type expressions such as
+// "*User", "[]models.Item", "map[string]*Order" or "Optional[List[Item]]".
+// Package qualifiers are dropped so "models.Item" yields "Item".
+func referencedTypeNames(typeExprs []string) []string {
+	var names []string
+	seen := make(map[string]bool)
+
+	for _, expr := range typeExprs {
+		identifiers := strings.FieldsFunc(expr, func(r rune) bool {
+			return r != '_' && r != '.' && !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		})
+		for _, identifier := range identifiers {
+			if idx := strings.LastIndex(identifier, "."); idx >= 0 {
+				identifier = identifier[idx+1:]
+			}
+			if identifier == "" || seen[identifier] {
+				continue
+			}
+			seen[identifier] = true
+			names = append(names, identifier)
+		}
+	}
+
+	return names
+}
+
+// notFoundError reports that no entity of the given kind is named name. When suggest
+// is set, indexed names of a matching kind close to name are offered as alternatives.
+func (s *RepoContextMCPServer) notFoundError(entity, name string, suggest bool, matchKind func(string) bool) error {
+	if suggest {
+		suggestions, err := s.QueryEngine.SuggestNames(name, index.MaxNameSuggestions, matchKind)
+		if err == nil && len(suggestions) > 0 {
+			return fmt.Errorf("no %s '%s'; did you mean '%s'?", entity, name, strings.Join(suggestions, "', '"))
+		}
+	}
+	return fmt.Errorf("%s '%s' not found", entity, name)
+}
+
+// isFunctionKind reports whether an index entry type is a function
+func isFunctionKind(entryType string) bool {
+	return entryType == index.EntityTypeFunction
+}
+
 // buildTypeContextResult constructs the complete type context result
 func (s *RepoContextMCPServer) buildTypeContextResult(params *GetTypeContextParams) (*TypeContextResult, error) {
 	// Search for the type
@@ -713,7 +1303,7 @@ func (s *RepoContextMCPServer) buildTypeContextResult(params *GetTypeContextPara
 	}
 
 	if len(searchResult.Entries) == 0 {
-		return nil, fmt.Errorf("type '%s' not found", params.TypeName)
+		return nil, s.notFoundError("type", params.TypeName, params.SuggestSimilar, index.IsTypeKind)
 	}
 
 	// Find the type in search results
@@ -721,20 +1311,21 @@ func (s *RepoContextMCPServer) buildTypeContextResult(params *GetTypeContextPara
 	for i := range searchResult.Entries {
 		entry := &searchResult.Entries[i]
 		entryType := entry.IndexEntry.Type
-		if entryType == index.EntityTypeType && entry.IndexEntry.Name == params.TypeName {
+		if index.IsTypeKind(entryType) && entry.IndexEntry.Name == params.TypeName {
 			typeEntry = entry
 			break
 		}
 	}
 
 	if typeEntry == nil {
-		return nil, fmt.Errorf("type '%s' not found in search results", params.TypeName)
+		return nil, s.notFoundError("type", params.TypeName, params.SuggestSimilar, index.IsTypeKind)
 	}
 
 	// Build the result
 	result := &TypeContextResult{
 		TypeName:  params.TypeName,
 		Signature: typeEntry.IndexEntry.Signature,
+		Doc:       s.findTypeDoc(typeEntry),
 		Location: TypeLocation{
 			File:      typeEntry.IndexEntry.File,
 			StartLine: typeEntry.IndexEntry.StartLine,
@@ -742,12 +1333,29 @@ func (s *RepoContextMCPServer) buildTypeContextResult(params *GetTypeContextPara
 		},
 	}
 
-	// Always extract fields for struct types
+	typeDef := s.findTypeModel(typeEntry)
+	if typeDef != nil {
+		result.BaseTypes = typeDef.Embedded
+		result.LastModified = typeDef.LastModified
+		result.LastCommit = typeDef.LastCommit
+	}
+
+	// Always extract fields for struct types, followed by those promoted from embedded types
 	result.Fields = s.extractFieldReferences(typeEntry)
+	if typeDef != nil {
+		result.Fields = append(result.Fields, s.promotedFieldReferences(typeEntry, typeDef)...)
+	}
+
+	// Always include enum-style constants declared with this type
+	result.Constants = s.extractConstantReferences(typeEntry)
 
 	// Add methods if requested
 	if params.IncludeMethods {
-		result.Methods = s.extractMethodReferences(typeEntry, searchResult.Entries)
+		result.Methods = s.extractMethodReferences(typeEntry)
+		if typeDef != nil {
+			result.Methods = append(result.Methods, s.promotedMethodReferences(typeDef)...)
+		}
+		result.Constructors = s.findConstructors(params.TypeName)
 	}
 
 	// Add usage examples if requested
@@ -755,8 +1363,19 @@ func (s *RepoContextMCPServer) buildTypeContextResult(params *GetTypeContextPara
 		result.UsageExamples = s.extractUsageExamples(typeEntry)
 	}
 
-	// Add related types
-	result.RelatedTypes = s.extractTypeReferences(searchResult.Entries)
+	// Add types embedded in or referenced by the type's fields
+	result.RelatedTypes = s.extractTypeTypeReferences(typeEntry)
+
+	// Add subclasses and embedding types if requested
+	if params.IncludeSubtypes {
+		result.Subtypes = s.findSubtypes(params.TypeName)
+	}
+
+	// Add the definitions of field types if requested, within part of the token budget
+	if params.ResolveFieldTypes && typeDef != nil {
+		tokenBudget := int(float64(params.MaxTokens) * ResolvedTypesTokenRatio)
+		result.ResolvedTypes, result.ResolvedTypesTruncated = s.resolveFieldTypes(typeDef, params.ResolveDepth, tokenBudget)
+	}
 
 	return result, nil
 }
@@ -780,10 +1399,11 @@ func (s *RepoContextMCPServer) extractFieldReferences(entry *index.SearchResultE
 						field := &typeDef.Fields[k]
 
 						fields = append(fields, FieldReference{
-							Name: field.Name,
-							Type: field.Type,
-							File: entry.IndexEntry.File,
-							Line: entry.IndexEntry.StartLine + k + 1, // Approximate line number
+							Name:    field.Name,
+							Type:    field.Type,
+							Default: field.Default,
+							File:    entry.IndexEntry.File,
+							Line:    entry.IndexEntry.StartLine + k + 1, // Approximate line number
 						})
 					}
 
@@ -806,42 +1426,137 @@ func (s *RepoContextMCPServer) extractFieldReferences(entry *index.SearchResultE
 	return fields
 }
 
-// extractMethodReferences extracts method references from search results
-func (s *RepoContextMCPServer) extractMethodReferences(
-	typeEntry *index.SearchResultEntry,
-	allEntries []index.SearchResultEntry,
-) []MethodReference {
-	var methods []MethodReference
-
-	// Look for functions that are methods of this type
-	for _, entry := range allEntries {
-		if entry.IndexEntry.Type == index.EntityTypeFunction {
-			// Check if this function is a method of our type
-			if strings.Contains(entry.IndexEntry.Signature, typeEntry.IndexEntry.Name) {
-				methods = append(methods, MethodReference{
-					Name:      entry.IndexEntry.Name,
-					Signature: entry.IndexEntry.Signature,
-					File:      entry.IndexEntry.File,
-					Line:      entry.IndexEntry.StartLine,
-				})
+// promotedFieldReferences lists the fields promoted to a Go type from the types it embeds,
+// located at the declaration of the embedded type in the type's package
+func (s *RepoContextMCPServer) promotedFieldReferences(typeEntry *index.SearchResultEntry, typeDef *models.TypeDef) []FieldReference {
+	packageDir := filepath.Dir(typeEntry.IndexEntry.File)
+	fields := make([]FieldReference, 0, len(typeDef.PromotedFields))
+	for i := range typeDef.PromotedFields {
+		field := &typeDef.PromotedFields[i]
+		reference := FieldReference{Name: field.Name, Type: field.Type, PromotedFrom: field.PromotedFrom}
+		if results, err := s.QueryEngine.SearchByName(field.PromotedFrom); err == nil {
+			for j := range results.Entries {
+				entry := &results.Entries[j].IndexEntry
+				if index.IsTypeKind(entry.Type) && filepath.Dir(entry.File) == packageDir {
+					reference.File, reference.Line = entry.File, entry.StartLine
+					break
+				}
 			}
 		}
+		fields = append(fields, reference)
 	}
+	return fields
+}
 
-	// If no methods found, create placeholder for demonstration
-	if len(methods) == 0 {
+// promotedMethodReferences lists the methods promoted to a Go type from the types it embeds
+func (s *RepoContextMCPServer) promotedMethodReferences(typeDef *models.TypeDef) []MethodReference {
+	methods := make([]MethodReference, 0, len(typeDef.PromotedMethods))
+	for i := range typeDef.PromotedMethods {
+		method := &typeDef.PromotedMethods[i]
 		methods = append(methods, MethodReference{
-			Name:      "Method1",
-			Signature: fmt.Sprintf("func (t *%s) Method1() error", typeEntry.IndexEntry.Name),
-			File:      typeEntry.IndexEntry.File,
-			Line:      typeEntry.IndexEntry.EndLine + 1,
+			Name:         method.Name,
+			Signature:    method.Signature,
+			Doc:          method.Doc,
+			File:         method.File,
+			Line:         method.StartLine,
+			PromotedFrom: method.PromotedFrom,
+		})
+	}
+	return methods
+}
+
+// extractConstantReferences returns the constants of a type's const groups.
+// A group is included when any of its members is declared with the type, so every
+// member of an iota enumeration is listed together in declaration order.
+func (s *RepoContextMCPServer) extractConstantReferences(entry *index.SearchResultEntry) []ConstantReference {
+	if entry.ChunkData == nil {
+		return nil
+	}
+
+	var constants []ConstantReference
+	for i := range entry.ChunkData.FileData {
+		fileData := &entry.ChunkData.FileData[i]
+
+		groups := make(map[string]bool)
+		for j := range fileData.Constants {
+			constant := &fileData.Constants[j]
+			if constant.Type == entry.IndexEntry.Name && constant.GroupID != "" {
+				groups[constant.GroupID] = true
+			}
+		}
+
+		for j := range fileData.Constants {
+			constant := &fileData.Constants[j]
+			if constant.Type != entry.IndexEntry.Name && !groups[constant.GroupID] {
+				continue
+			}
+			constants = append(constants, ConstantReference{
+				Name:    constant.Name,
+				Value:   constant.Value,
+				GroupID: constant.GroupID,
+				File:    fileData.Path,
+				Line:    constant.StartLine,
+			})
+		}
+	}
+
+	return constants
+}
+
+// extractMethodReferences returns the methods attached to a type during indexing.
+// Methods are linked to their receiver type by the parser and index builder, so
+// methods of other types with similar names or signatures are never included.
+func (s *RepoContextMCPServer) extractMethodReferences(typeEntry *index.SearchResultEntry) []MethodReference {
+	typeDef := s.findTypeModel(typeEntry)
+	if typeDef == nil {
+		return nil
+	}
+
+	methods := make([]MethodReference, 0, len(typeDef.Methods))
+	for i := range typeDef.Methods {
+		method := &typeDef.Methods[i]
+		file := method.File
+		if file == "" {
+			file = typeEntry.IndexEntry.File
+		}
+		methods = append(methods, MethodReference{
+			Name:      method.Name,
+			Signature: method.Signature,
+			Doc:       method.Doc,
+			File:      file,
+			Line:      method.StartLine,
 		})
 	}
 
 	return methods
 }
 
-// extractUsageExamples extracts usage examples for a type
+// findConstructors lists the functions that create values of the type
+func (s *RepoContextMCPServer) findConstructors(typeName string) []MethodReference {
+	entries, err := s.QueryEngine.FindConstructors(typeName)
+	if err != nil {
+		return nil
+	}
+
+	var constructors []MethodReference
+	for i := range entries {
+		entry := &entries[i]
+		if s.isExcludedPath(entry.IndexEntry.File) {
+			continue
+		}
+		constructors = append(constructors, MethodReference{
+			Name:      entry.IndexEntry.Name,
+			Signature: entry.IndexEntry.Signature,
+			File:      entry.IndexEntry.File,
+			Line:      entry.IndexEntry.StartLine,
+		})
+	}
+
+	return constructors
+}
+
+// extractUsageExamples extracts usage examples for a type. Real examples come first;
+// generated ones only pad the result up to MinUsageExamples and are marked as such.
 func (s *RepoContextMCPServer) extractUsageExamples(entry *index.SearchResultEntry) []UsageExample {
 	var examples []UsageExample
 	typeName := entry.IndexEntry.Name
@@ -850,22 +1565,27 @@ func (s *RepoContextMCPServer) extractUsageExamples(entry *index.SearchResultEnt
 	realExamples := s.findRealUsageExamples(typeName)
 	examples = append(examples, realExamples...)
 
-	// If no real examples found, fall back to synthetic ones
-	if len(examples) == 0 {
-		examples = append(examples,
-			UsageExample{
-				Description: "Variable declaration",
-				Code:        fmt.Sprintf("var instance %s", typeName),
-				File:        entry.IndexEntry.File,
-				Line:        entry.IndexEntry.StartLine,
-			},
-			UsageExample{
-				Description: "Initialization",
-				Code:        fmt.Sprintf("instance := %s{}", typeName),
-				File:        entry.IndexEntry.File,
-				Line:        entry.IndexEntry.StartLine,
-			},
-		)
+	generated := []UsageExample{
+		{
+			Description: "Variable declaration",
+			Code:        fmt.Sprintf("var instance %s", typeName),
+			File:        entry.IndexEntry.File,
+			Line:        entry.IndexEntry.StartLine,
+			Source:      UsageSourceGenerated,
+		},
+		{
+			Description: "Initialization",
+			Code:        fmt.Sprintf("instance := %s{}", typeName),
+			File:        entry.IndexEntry.File,
+			Line:        entry.IndexEntry.StartLine,
+			Source:      UsageSourceGenerated,
+		},
+	}
+	for _, example := range generated {
+		if len(examples) >= MinUsageExamples {
+			break
+		}
+		examples = append(examples, example)
 	}
 
 	return examples
@@ -923,6 +1643,10 @@ func (s *RepoContextMCPServer) findRealUsageExamples(typeName string) []UsageExa
 		examples = examples[:totalMaxExamples]
 	}
 
+	for i := range examples {
+		examples[i].Source = UsageSourceReal
+	}
+
 	return examples
 }
 
@@ -937,7 +1661,7 @@ func (s *RepoContextMCPServer) extractExamplesFromSearchResult(
 		entry := &searchResult.Entries[i]
 
 		// Skip if this is the type definition itself
-		if entry.IndexEntry.Type == index.EntityTypeType && entry.IndexEntry.Name == typeName {
+		if index.IsTypeKind(entry.IndexEntry.Type) && entry.IndexEntry.Name == typeName {
 			continue
 		}
 
@@ -1167,9 +1891,13 @@ func (s *RepoContextMCPServer) optimizeTypeContextResponse(result *TypeContextRe
 		// Minimal response - just type metadata
 		result.Fields = nil
 		result.Methods = nil
+		result.Constructors = nil
+		result.Constants = nil
 		result.UsageExamples = nil
 		result.RelatedTypes = nil
-		result.TokenCount = TypeContextBaseTokens
+		result.Subtypes = nil
+		result.ResolvedTypes = nil
+		result.TokenCount = s.estimateTypeContextTokens(result)
 		return
 	}
 
@@ -1187,12 +1915,14 @@ func (s *RepoContextMCPServer) optimizeTypeContextResponse(result *TypeContextRe
 		}
 	}
 
-	// Optimize methods
-	if len(result.Methods) > 0 {
-		maxMethods := s.calculateMaxMethodRefs(methodsTokens)
-		if maxMethods < len(result.Methods) {
-			result.Methods = result.Methods[:maxMethods]
-		}
+	// Optimize methods, keeping constructors first as they show how to create the type
+	maxMethods := s.calculateMaxMethodRefs(methodsTokens)
+	if maxMethods < len(result.Constructors) {
+		result.Constructors = result.Constructors[:maxMethods]
+	}
+	maxMethods -= len(result.Constructors)
+	if maxMethods < len(result.Methods) {
+		result.Methods = result.Methods[:maxMethods]
 	}
 
 	// Optimize usage examples
@@ -1203,12 +1933,19 @@ func (s *RepoContextMCPServer) optimizeTypeContextResponse(result *TypeContextRe
 		}
 	}
 
-	// Optimize related types
-	if len(result.RelatedTypes) > 0 {
-		maxTypes := s.calculateMaxTypeRefs(relatedTokens)
-		if maxTypes < len(result.RelatedTypes) {
-			result.RelatedTypes = result.RelatedTypes[:maxTypes]
-		}
+	// Optimize related types, then give subtypes whatever budget remains
+	maxTypes := s.calculateMaxTypeRefs(relatedTokens)
+	if maxTypes < len(result.RelatedTypes) {
+		result.RelatedTypes = result.RelatedTypes[:maxTypes]
+	}
+	maxSubtypes := max(maxTypes-len(result.RelatedTypes), 0)
+	if maxSubtypes < len(result.Subtypes) {
+		result.Subtypes = result.Subtypes[:maxSubtypes]
+	}
+
+	// Resolved types keep what fits in the budget left over, deepest levels dropped first
+	for len(result.ResolvedTypes) > 0 && s.estimateTypeContextTokens(result) > maxTokens {
+		result.ResolvedTypes = result.ResolvedTypes[:len(result.ResolvedTypes)-1]
 	}
 
 	// Recalculate final token count
@@ -1223,13 +1960,21 @@ func (s *RepoContextMCPServer) estimateTypeContextTokens(result *TypeContextResu
 	tokens += len(result.Fields) * FieldRefTokens
 
 	// Add method tokens
-	tokens += len(result.Methods) * MethodRefTokens
+	tokens += (len(result.Methods) + len(result.Constructors)) * MethodRefTokens
+
+	// Add constant tokens
+	tokens += len(result.Constants) * ConstantRefTokens
 
 	// Add usage example tokens
 	tokens += len(result.UsageExamples) * UsageExampleTokens
 
-	// Add related type tokens
-	tokens += len(result.RelatedTypes) * TypeRefTokens
+	// Add related type, subtype and base type tokens
+	tokens += (len(result.RelatedTypes) + len(result.Subtypes) + len(result.BaseTypes)) * TypeRefTokens
+
+	// Add resolved field type tokens
+	for i := range result.ResolvedTypes {
+		tokens += estimateResolvedTypeTokens(&result.ResolvedTypes[i])
+	}
 
 	return tokens
 }
@@ -1,6 +1,7 @@
 package mcp
 
 import (
+	"maps"
 	"os"
 	"path/filepath"
 	"strings"
@@ -9,6 +10,7 @@ import (
 	"repository-context-protocol/internal/index"
 	"repository-context-protocol/internal/models"
 
+	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -22,6 +24,7 @@ func TestContextTools_Registration(t *testing.T) {
 	expectedTools := []string{
 		"get_function_context",
 		"get_type_context",
+		"summarize_function",
 	}
 
 	if len(tools) != len(expectedTools) {
@@ -57,7 +60,7 @@ func TestContextLinesValidation(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := validateContextLines(tt.inputContextLines)
+			result := validateContextLines(tt.inputContextLines, MaxContextLines)
 			if result != tt.expectedContextLines {
 				t.Errorf("Expected context lines %d, got %d", tt.expectedContextLines, result)
 			}
@@ -119,7 +122,7 @@ func TestGetFunctionContextParameterParsing(t *testing.T) {
 		for _, tt := range tests {
 			t.Run(tt.name, func(t *testing.T) {
 				// Test the validation logic directly
-				validatedContextLines := validateContextLines(tt.contextLines)
+				validatedContextLines := validateContextLines(tt.contextLines, MaxContextLines)
 				if validatedContextLines != tt.expectedContextLines {
 					t.Errorf("Expected context lines %d, got %d", tt.expectedContextLines, validatedContextLines)
 				}
@@ -265,7 +268,7 @@ func TestFunctionContextTokenOptimization(t *testing.T) {
 			testResult.RelatedTypes = make([]TypeReference, len(result.RelatedTypes))
 			copy(testResult.RelatedTypes, result.RelatedTypes)
 
-			server.optimizeFunctionContextResponse(&testResult, tt.maxTokens)
+			server.optimizeFunctionContextResponse(&testResult, tt.maxTokens, DefaultFunctionContextBudget)
 
 			if tt.shouldTruncate && !testResult.Truncated {
 				t.Error("Expected truncation but result was not truncated")
@@ -279,6 +282,82 @@ func TestFunctionContextTokenOptimization(t *testing.T) {
 	}
 }
 
+// TestFunctionContextBudgetParsing tests the focus presets, ratio overrides and their validation
+func TestFunctionContextBudgetParsing(t *testing.T) {
+	tests := []struct {
+		name        string
+		arguments   map[string]interface{}
+		expected    FunctionContextBudget
+		expectError bool
+	}{
+		{"default is balanced", map[string]interface{}{}, DefaultFunctionContextBudget, false},
+		{"implementation focus", map[string]interface{}{"focus": "implementation"}, FunctionContextBudget{Implementation: 1}, false},
+		{
+			"ratio overrides focus", map[string]interface{}{"focus": "Relationships", "callers_ratio": 0.0, "types_ratio": 0.5},
+			FunctionContextBudget{Implementation: 0.1, Callees: 0.4, Types: 0.5}, false,
+		},
+		{
+			"ratios summing to 1",
+			map[string]interface{}{"implementation_ratio": 0.7, "callers_ratio": 0.2, "callees_ratio": 0.1, "types_ratio": 0.0},
+			FunctionContextBudget{Implementation: 0.7, Callers: 0.2, Callees: 0.1}, false,
+		},
+		{"unknown focus", map[string]interface{}{"focus": "tests"}, FunctionContextBudget{}, true},
+		{"ratios over 1", map[string]interface{}{"implementation_ratio": 0.9}, FunctionContextBudget{}, true},
+		{"negative ratio", map[string]interface{}{"callers_ratio": -0.1}, FunctionContextBudget{}, true},
+	}
+
+	server := NewRepoContextMCPServer()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := mcp.CallToolRequest{}
+			request.Params.Arguments = map[string]interface{}{"function_name": "Run"}
+			maps.Copy(request.Params.Arguments.(map[string]interface{}), tt.arguments)
+
+			params, err := server.parseGetFunctionContextParameters(request)
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("Expected an error, got budget %+v", params.Budget)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Failed to parse parameters: %v", err)
+			}
+			if params.Budget != tt.expected {
+				t.Errorf("Expected budget %+v, got %+v", tt.expected, params.Budget)
+			}
+		})
+	}
+}
+
+// TestFunctionContextTokenOptimization_ImplementationFocus tests that a budget spent on the
+// body drops the other sections and gives the body the whole budget
+func TestFunctionContextTokenOptimization_ImplementationFocus(t *testing.T) {
+	server := NewRepoContextMCPServer()
+	newResult := func() *FunctionContextResult {
+		return &FunctionContextResult{
+			FunctionName:   "Run",
+			Implementation: &FunctionImplementation{Body: strings.Repeat("step()\n", 200)},
+			Callers:        []FunctionReference{{Name: "main", File: "main.go", Line: 5}},
+			Callees:        []FunctionReference{{Name: "step", File: "run.go", Line: 20}},
+			RelatedTypes:   []TypeReference{{Name: "Config", File: "config.go", Line: 3}},
+		}
+	}
+
+	balanced := newResult()
+	server.optimizeFunctionContextResponse(balanced, 400, DefaultFunctionContextBudget)
+	focused := newResult()
+	server.optimizeFunctionContextResponse(focused, 400, functionContextFocuses[FocusImplementation])
+
+	if focused.Callers != nil || focused.Callees != nil || focused.RelatedTypes != nil {
+		t.Errorf("Expected only the implementation, got %+v", focused)
+	}
+	if len(focused.Implementation.Body) <= len(balanced.Implementation.Body) {
+		t.Errorf("Expected a longer body with the implementation focus, got %d bytes against %d",
+			len(focused.Implementation.Body), len(balanced.Implementation.Body))
+	}
+}
+
 // TestContextToolImplementationDetails tests implementation detail handling
 func TestContextToolImplementationDetails(t *testing.T) {
 	tests := []struct {
@@ -819,7 +898,7 @@ func ExampleFunction(name string, count int) string {
 	}
 
 	// Test buildFunctionImplementation
-	impl := server.buildFunctionImplementation(searchEntry, 2)
+	impl := server.buildFunctionImplementation(searchEntry, 2, false)
 	require.NotNil(t, impl, "Implementation should not be nil")
 
 	// Verify the function body contains actual implementation (even if truncated)
@@ -853,6 +932,54 @@ func ExampleFunction(name string, count int) string {
 	t.Logf("Extracted implementation: %s", impl.Body)
 }
 
+func TestBuildFunctionImplementation_StripComments(t *testing.T) {
+	tempDir := t.TempDir()
+
+	storage := index.NewHybridStorage(tempDir)
+	require.NoError(t, storage.Initialize(), "Failed to initialize storage")
+	defer storage.Close()
+
+	server := &RepoContextMCPServer{
+		QueryEngine: index.NewQueryEngine(storage),
+		Storage:     storage,
+		RepoPath:    tempDir,
+	}
+
+	testFilePath := filepath.Join(tempDir, "greet.py")
+	source := "def greet(name):\n    \"\"\"Greet someone.\n\n    Returns the greeting.\n    \"\"\"\n" +
+		"    # Build the greeting\n    return '#' + name  # prefixed\n"
+	require.NoError(t, os.WriteFile(testFilePath, []byte(source), ConstFilePermission600), "Failed to write test file")
+
+	fileContext := &models.FileContext{
+		Path:     testFilePath,
+		Language: "python",
+		Functions: []models.Function{
+			{Name: "greet", Signature: "def greet(name)", StartLine: 1, EndLine: 7},
+		},
+	}
+	require.NoError(t, storage.StoreFileContext(fileContext), "Failed to store file context")
+
+	searchEntry := &index.SearchResultEntry{
+		IndexEntry: models.IndexEntry{
+			Name: "greet", Type: "function", File: testFilePath, StartLine: 1, EndLine: 7, Language: "python",
+		},
+		ChunkData: &models.SemanticChunk{FileData: []models.FileContext{*fileContext}},
+	}
+
+	impl := server.buildFunctionImplementation(searchEntry, 0, false)
+	assert.Contains(t, impl.Body, "Greet someone.", "Comments are kept by default")
+	assert.Contains(t, impl.Body, "# Build the greeting", "Comments are kept by default")
+
+	impl = server.buildFunctionImplementation(searchEntry, 0, true)
+	assert.Equal(t, "def greet(name):\n    return '#' + name", impl.Body)
+
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]interface{}{"function_name": "greet", "strip_comments": true}
+	params, err := server.parseGetFunctionContextParameters(request)
+	require.NoError(t, err)
+	assert.True(t, params.StripComments)
+}
+
 func TestBuildFunctionImplementation_FallbackToPlaceholder(t *testing.T) {
 	// Create server without storage (simulating failure case)
 	server := &RepoContextMCPServer{
@@ -874,7 +1001,7 @@ func TestBuildFunctionImplementation_FallbackToPlaceholder(t *testing.T) {
 	}
 
 	// Test buildFunctionImplementation with no storage
-	impl := server.buildFunctionImplementation(searchEntry, 2)
+	impl := server.buildFunctionImplementation(searchEntry, 2, false)
 	require.NotNil(t, impl, "Implementation should not be nil")
 
 	// Verify it falls back to the documented placeholder
@@ -885,3 +1012,644 @@ func TestBuildFunctionImplementation_FallbackToPlaceholder(t *testing.T) {
 	assert.Contains(t, impl.Body, "TestFunction", "Should include function name in placeholder")
 	assert.Contains(t, impl.Body, "/nonexistent/test.go", "Should include file path in placeholder")
 }
+
+func TestContextTools_DocComments(t *testing.T) {
+	tempDir := t.TempDir()
+
+	storage := index.NewHybridStorage(tempDir)
+	require.NoError(t, storage.Initialize(), "Failed to initialize storage")
+	defer storage.Close()
+
+	server := &RepoContextMCPServer{
+		QueryEngine: index.NewQueryEngine(storage),
+		Storage:     storage,
+		RepoPath:    tempDir,
+	}
+
+	fileContext := &models.FileContext{
+		Path: "user.go",
+		Functions: []models.Function{
+			{
+				Name:      "NewUser",
+				Signature: "func NewUser() *User",
+				StartLine: 10,
+				EndLine:   12,
+				Doc:       "NewUser creates a new user.\n\nThe returned user is not persisted.",
+			},
+		},
+	}
+	require.NoError(t, storage.StoreFileContext(fileContext), "Failed to store file context")
+
+	t.Run("function context includes doc", func(t *testing.T) {
+		result, err := server.buildFunctionContextResult(&GetFunctionContextParams{
+			FunctionName: "NewUser",
+			MaxTokens:    constMaxTokens,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "NewUser creates a new user.\n\nThe returned user is not persisted.", result.Doc)
+	})
+
+	t.Run("type context includes doc", func(t *testing.T) {
+		entry := createTestSearchResultEntry("User", "user.go", 3, 6, nil)
+		entry.ChunkData.FileData[0].Types[0].Doc = "User represents a user in the system"
+		assert.Equal(t, "User represents a user in the system", server.findTypeDoc(entry))
+	})
+
+	t.Run("missing chunk data yields empty doc", func(t *testing.T) {
+		entry := &index.SearchResultEntry{IndexEntry: models.IndexEntry{Name: "User"}}
+		assert.Empty(t, server.findTypeDoc(entry))
+		assert.Empty(t, server.findFunctionDoc(entry))
+	})
+}
+
+func TestContextTools_RelatedTypesFromReferences(t *testing.T) {
+	tempDir := t.TempDir()
+
+	storage := index.NewHybridStorage(tempDir)
+	require.NoError(t, storage.Initialize(), "Failed to initialize storage")
+	defer storage.Close()
+
+	server := &RepoContextMCPServer{
+		QueryEngine: index.NewQueryEngine(storage),
+		Storage:     storage,
+		RepoPath:    tempDir,
+	}
+
+	fileContext := &models.FileContext{
+		Path: "user.go",
+		Functions: []models.Function{
+			{
+				Name:       "ProcessUser",
+				Signature:  "func ProcessUser(req models.Request, opts map[string]*Option) (*User, error)",
+				Parameters: []models.Parameter{{Name: "req", Type: "models.Request"}, {Name: "opts", Type: "map[string]*Option"}},
+				Returns:    []models.Type{{Name: "*User", Kind: "pointer"}, {Name: "error", Kind: "basic"}},
+				StartLine:  20,
+				EndLine:    30,
+			},
+		},
+		Types: []models.TypeDef{
+			{
+				Name:      "User",
+				Kind:      "struct",
+				StartLine: 3,
+				EndLine:   8,
+				Embedded:  []string{"Base"},
+				Fields:    []models.Field{{Name: "Address", Type: "*Address"}, {Name: "Friends", Type: "[]User"}, {Name: "Name", Type: "string"}},
+			},
+			{Name: "Address", Kind: "struct", StartLine: 10, EndLine: 12},
+			{Name: "Base", Kind: "struct", StartLine: 13, EndLine: 14},
+			{Name: "Request", Kind: "struct", StartLine: 15, EndLine: 16},
+			{Name: "Option", Kind: "interface", StartLine: 17, EndLine: 18},
+			{Name: "Unrelated", Kind: "struct", StartLine: 40, EndLine: 45},
+		},
+	}
+	require.NoError(t, storage.StoreFileContext(fileContext), "Failed to store file context")
+
+	relatedNames := func(refs []TypeReference) []string {
+		var names []string
+		for _, ref := range refs {
+			names = append(names, ref.Name)
+		}
+		return names
+	}
+
+	t.Run("function lists parameter and return types only", func(t *testing.T) {
+		result, err := server.buildFunctionContextResult(&GetFunctionContextParams{
+			FunctionName: "ProcessUser",
+			MaxTokens:    constMaxTokens,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Request", "Option", "User"}, relatedNames(result.RelatedTypes))
+		assert.Equal(t, "user.go", result.RelatedTypes[2].File)
+		assert.Equal(t, 3, result.RelatedTypes[2].Line)
+	})
+
+	t.Run("minimal mode returns only signature and location", func(t *testing.T) {
+		result, err := server.buildFunctionContextResult(&GetFunctionContextParams{
+			FunctionName:           "ProcessUser",
+			IncludeImplementations: true,
+			MaxTokens:              constMaxTokens,
+			Minimal:                true,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, fileContext.Functions[0].Signature, result.Signature)
+		assert.Equal(t, FunctionLocation{File: "user.go", StartLine: 20, EndLine: 30}, result.Location)
+		assert.Empty(t, result.RelatedTypes, "Related types should not be looked up in minimal mode")
+		assert.Nil(t, result.Implementation, "Implementation should not be extracted in minimal mode")
+	})
+
+	t.Run("type lists embedded and field types excluding itself", func(t *testing.T) {
+		searchResult, err := server.QueryEngine.SearchByName("User")
+		require.NoError(t, err)
+		require.Len(t, searchResult.Entries, 1)
+
+		refs := server.extractTypeTypeReferences(&searchResult.Entries[0])
+		assert.Equal(t, []string{"Base", "Address"}, relatedNames(refs))
+	})
+
+	t.Run("type lists base types and optional subtypes", func(t *testing.T) {
+		require.NoError(t, storage.StoreFileContext(&models.FileContext{
+			Path:     "models.py",
+			Language: "python",
+			Types: []models.TypeDef{
+				{Name: "Admin", Kind: "class", StartLine: 1, EndLine: 4, Embedded: []string{"User", "db.Model"}},
+				{Name: "Guest", Kind: "class", StartLine: 6, EndLine: 8, Embedded: []string{"models.User"}},
+				{Name: "Users", Kind: "class", StartLine: 10, EndLine: 12, Embedded: []string{"list"}},
+			},
+		}), "Failed to store file context")
+
+		admin, err := server.buildTypeContextResult(&GetTypeContextParams{TypeName: "Admin", MaxTokens: constMaxTokens})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"User", "db.Model"}, admin.BaseTypes)
+		assert.Empty(t, admin.Subtypes, "Subtypes should only be listed when requested")
+
+		user, err := server.buildTypeContextResult(&GetTypeContextParams{
+			TypeName:        "User",
+			IncludeSubtypes: true,
+			MaxTokens:       constMaxTokens,
+		})
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"Admin", "Guest"}, relatedNames(user.Subtypes))
+	})
+
+	t.Run("type names are extracted from composite expressions", func(t *testing.T) {
+		names := referencedTypeNames([]string{"*User", "[]models.Item", "map[string]*Order", "Optional[List[Item]]"})
+		assert.Equal(t, []string{"User", "Item", "map", "string", "Order", "Optional", "List"}, names)
+	})
+}
+
+func TestContextTools_ResolveFieldTypes(t *testing.T) {
+	tempDir := t.TempDir()
+
+	storage := index.NewHybridStorage(tempDir)
+	require.NoError(t, storage.Initialize(), "Failed to initialize storage")
+	defer storage.Close()
+
+	server := &RepoContextMCPServer{
+		QueryEngine: index.NewQueryEngine(storage),
+		Storage:     storage,
+		RepoPath:    tempDir,
+	}
+
+	require.NoError(t, storage.StoreFileContext(&models.FileContext{
+		Path: "order.go",
+		Types: []models.TypeDef{
+			{Name: "Order", Kind: "struct", StartLine: 1, EndLine: 5, Fields: []models.Field{
+				{Name: "Customer", Type: "*Customer"}, {Name: "Lines", Type: "[]Line"}, {Name: "Parent", Type: "*Order"},
+			}},
+			{Name: "Customer", Kind: "struct", StartLine: 7, EndLine: 10, Fields: []models.Field{
+				{Name: "Name", Type: "string"}, {Name: "Address", Type: "Address"},
+			}},
+			{Name: "Line", Kind: "struct", StartLine: 12, EndLine: 14, Fields: []models.Field{{Name: "SKU", Type: "string"}}},
+			{Name: "Address", Kind: "struct", StartLine: 16, EndLine: 18, Fields: []models.Field{{Name: "City", Type: "string"}}},
+		},
+	}), "Failed to store file context")
+
+	resolvedNames := func(types []ResolvedType) []string {
+		var names []string
+		for _, resolvedType := range types {
+			names = append(names, resolvedType.Name)
+		}
+		return names
+	}
+
+	t.Run("not resolved unless requested", func(t *testing.T) {
+		result, err := server.buildTypeContextResult(&GetTypeContextParams{TypeName: "Order", MaxTokens: constMaxTokens})
+		require.NoError(t, err)
+		assert.Empty(t, result.ResolvedTypes)
+	})
+
+	t.Run("resolves field types up to the depth limit", func(t *testing.T) {
+		result, err := server.buildTypeContextResult(&GetTypeContextParams{
+			TypeName: "Order", MaxTokens: constMaxTokens, ResolveFieldTypes: true, ResolveDepth: 1,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Customer", "Line"}, resolvedNames(result.ResolvedTypes), "The type itself is not resolved again")
+		assert.Equal(t, []ResolvedField{{Name: "Name", Type: "string"}, {Name: "Address", Type: "Address"}}, result.ResolvedTypes[0].Fields)
+		assert.False(t, result.ResolvedTypesTruncated)
+
+		result, err = server.buildTypeContextResult(&GetTypeContextParams{
+			TypeName: "Order", MaxTokens: constMaxTokens, ResolveFieldTypes: true, ResolveDepth: 2,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Customer", "Line", "Address"}, resolvedNames(result.ResolvedTypes))
+		assert.Equal(t, 2, result.ResolvedTypes[2].Depth)
+	})
+
+	t.Run("stops at the token budget", func(t *testing.T) {
+		result, err := server.buildTypeContextResult(&GetTypeContextParams{
+			TypeName: "Order", MaxTokens: 100, ResolveFieldTypes: true, ResolveDepth: MaxResolveDepth,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Customer"}, resolvedNames(result.ResolvedTypes))
+		assert.True(t, result.ResolvedTypesTruncated)
+	})
+
+	t.Run("depth parameter is capped", func(t *testing.T) {
+		request := mcp.CallToolRequest{}
+		request.Params.Arguments = map[string]interface{}{"type_name": "Order", "resolve_field_types": true, "resolve_depth": float64(10)}
+		params, err := server.parseGetTypeContextParameters(request)
+		require.NoError(t, err)
+		assert.True(t, params.ResolveFieldTypes)
+		assert.Equal(t, MaxResolveDepth, params.ResolveDepth)
+	})
+}
+
+func TestContextTools_Constructors(t *testing.T) {
+	tempDir := t.TempDir()
+
+	storage := index.NewHybridStorage(tempDir)
+	require.NoError(t, storage.Initialize(), "Failed to initialize storage")
+	defer storage.Close()
+
+	server := &RepoContextMCPServer{
+		QueryEngine: index.NewQueryEngine(storage),
+		Storage:     storage,
+		RepoPath:    tempDir,
+	}
+
+	require.NoError(t, storage.StoreFileContext(&models.FileContext{
+		Path:  "user.go",
+		Types: []models.TypeDef{{Name: "User", Kind: "struct", StartLine: 1, EndLine: 3}},
+		Functions: []models.Function{
+			{Name: "NewUser", Signature: "func NewUser(name string) *User", StartLine: 5, EndLine: 7,
+				Returns: []models.Type{{Name: "*User"}}},
+			{Name: "ParseUser", Signature: "func ParseUser(data []byte) (User, error)", StartLine: 9, EndLine: 11,
+				Returns: []models.Type{{Name: "User"}, {Name: "error"}}},
+			{Name: "userName", Signature: "func userName(u *User) string", StartLine: 13, EndLine: 15,
+				Returns: []models.Type{{Name: "string"}}},
+		},
+	}), "Failed to store file context")
+
+	t.Run("not listed unless methods are requested", func(t *testing.T) {
+		result, err := server.buildTypeContextResult(&GetTypeContextParams{TypeName: "User", MaxTokens: constMaxTokens})
+		require.NoError(t, err)
+		assert.Empty(t, result.Constructors)
+	})
+
+	t.Run("lists functions returning the type", func(t *testing.T) {
+		result, err := server.buildTypeContextResult(&GetTypeContextParams{
+			TypeName: "User", MaxTokens: constMaxTokens, IncludeMethods: true,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []MethodReference{
+			{Name: "NewUser", Signature: "func NewUser(name string) *User", File: "user.go", Line: 5},
+			{Name: "ParseUser", Signature: "func ParseUser(data []byte) (User, error)", File: "user.go", Line: 9},
+		}, result.Constructors)
+	})
+}
+
+func TestContextTools_PromotedMembers(t *testing.T) {
+	tempDir := t.TempDir()
+
+	storage := index.NewHybridStorage(tempDir)
+	require.NoError(t, storage.Initialize(), "Failed to initialize storage")
+	defer storage.Close()
+
+	server := &RepoContextMCPServer{
+		QueryEngine: index.NewQueryEngine(storage),
+		Storage:     storage,
+		RepoPath:    tempDir,
+	}
+
+	require.NoError(t, storage.StoreFileContext(&models.FileContext{
+		Path: "users/user.go", Language: "go",
+		Types: []models.TypeDef{
+			{Name: "User", Kind: "struct", StartLine: 1, EndLine: 3, Fields: []models.Field{{Name: "Name", Type: "string"}}},
+			{Name: "Admin", Kind: "struct", StartLine: 5, EndLine: 8, Embedded: []string{"User"},
+				Fields:         []models.Field{{Name: "Level", Type: "int"}},
+				PromotedFields: []models.Field{{Name: "Name", Type: "string", PromotedFrom: "User"}},
+				PromotedMethods: []models.Method{{Name: "Greet", Signature: "func (u *User) Greet() string", StartLine: 10,
+					File: "users/user.go", PromotedFrom: "User"}}},
+		},
+	}), "Failed to store file context")
+
+	result, err := server.buildTypeContextResult(&GetTypeContextParams{TypeName: "Admin", MaxTokens: constMaxTokens, IncludeMethods: true})
+	require.NoError(t, err)
+
+	require.Len(t, result.Fields, 2)
+	assert.Equal(t, "Level", result.Fields[0].Name)
+	assert.Empty(t, result.Fields[0].PromotedFrom, "Own fields are not marked promoted")
+	assert.Equal(t, FieldReference{Name: "Name", Type: "string", File: "users/user.go", Line: 1, PromotedFrom: "User"}, result.Fields[1])
+
+	assert.Equal(t, []MethodReference{
+		{Name: "Greet", Signature: "func (u *User) Greet() string", File: "users/user.go", Line: 10, PromotedFrom: "User"},
+	}, result.Methods)
+}
+
+func TestContextTools_ConstantGroups(t *testing.T) {
+	server := &RepoContextMCPServer{}
+
+	entry := createTestSearchResultEntry("Status", "status.go", 3, 3, nil)
+	entry.ChunkData.FileData[0].Constants = []models.Constant{
+		{Name: "StatusUnknown", Type: "Status", Value: "iota", GroupID: "status.go:5", StartLine: 6},
+		{Name: "StatusActive", Type: "Status", Value: "iota", GroupID: "status.go:5", StartLine: 7},
+		{Name: "statusCount", Type: "", Value: "2", GroupID: "status.go:5", StartLine: 8},
+		{Name: "MaxRetries", Type: "int", Value: "3", GroupID: "status.go:11", StartLine: 12},
+		{Name: "DefaultStatus", Type: "Status", Value: "StatusActive", StartLine: 15},
+	}
+
+	constants := server.extractConstantReferences(entry)
+
+	var names []string
+	for _, constant := range constants {
+		names = append(names, constant.Name)
+	}
+	assert.Equal(t, []string{"StatusUnknown", "StatusActive", "statusCount", "DefaultStatus"}, names,
+		"Should list the whole const group of the type plus other constants of the type")
+	assert.Equal(t, "iota", constants[0].Value)
+	assert.Equal(t, "status.go:5", constants[0].GroupID)
+	assert.Equal(t, "status.go", constants[0].File)
+	assert.Equal(t, 6, constants[0].Line)
+
+	assert.Empty(t, server.extractConstantReferences(&index.SearchResultEntry{}), "Missing chunk data yields no constants")
+}
+
+// TestExtractMethodReferences_UsesReceiverLinks tests that only methods attached to the type are returned
+func TestGetTypeContext_MethodsFromIndexedReceivers(t *testing.T) {
+	projectDir := t.TempDir()
+	sources := map[string]string{
+		"user.go": `package accounts
+
+type User struct {
+	Name string
+}
+
+func (u *User) Activate() error {
+	return nil
+}
+
+type UserGroup struct{}
+
+func (g *UserGroup) Activate() error {
+	return nil
+}
+`,
+		// A method declared in another file next to a free function taking the type
+		"rename.go": `package accounts
+
+func (u *User) Rename(name string) {
+	u.Name = name
+}
+
+func ProcessUser(u *User) error {
+	return u.Activate()
+}
+`,
+	}
+	for name, source := range sources {
+		require.NoError(t, os.WriteFile(filepath.Join(projectDir, name), []byte(source), 0600))
+	}
+
+	builder := index.NewIndexBuilder(projectDir)
+	require.NoError(t, builder.Initialize())
+	_, err := builder.BuildIndex()
+	require.NoError(t, err)
+	require.NoError(t, builder.Close())
+
+	storage := index.NewHybridStorage(filepath.Join(projectDir, ".repocontext"))
+	require.NoError(t, storage.Initialize())
+	defer storage.Close()
+	server := NewRepoContextMCPServer()
+	server.Storage = storage
+	server.QueryEngine = index.NewQueryEngine(storage)
+
+	result, err := server.buildTypeContextResult(&GetTypeContextParams{TypeName: "User", IncludeMethods: true, MaxTokens: constMaxTokens})
+	require.NoError(t, err)
+
+	require.Len(t, result.Methods, 2, "Only methods with a User receiver should be listed, got %+v", result.Methods)
+	assert.Equal(t, "Activate", result.Methods[0].Name)
+	assert.Equal(t, "user.go", result.Methods[0].File)
+	assert.Equal(t, 7, result.Methods[0].Line)
+	assert.Equal(t, "Rename", result.Methods[1].Name)
+	assert.Equal(t, "rename.go", result.Methods[1].File, "Methods declared in other files should keep their own file")
+	for _, method := range result.Methods {
+		assert.NotEqual(t, "ProcessUser", method.Name, "Free functions should not be listed as methods")
+	}
+
+	group, err := server.buildTypeContextResult(&GetTypeContextParams{TypeName: "UserGroup", IncludeMethods: true, MaxTokens: constMaxTokens})
+	require.NoError(t, err)
+	require.Len(t, group.Methods, 1, "UserGroup should only list its own Activate method")
+	assert.Equal(t, 13, group.Methods[0].Line)
+}
+
+func TestExtractUsageExamples_MarksGeneratedExamples(t *testing.T) {
+	tempDir := t.TempDir()
+
+	storage := index.NewHybridStorage(tempDir)
+	require.NoError(t, storage.Initialize(), "Failed to initialize storage")
+	defer storage.Close()
+
+	server := &RepoContextMCPServer{
+		QueryEngine: index.NewQueryEngine(storage),
+		Storage:     storage,
+		RepoPath:    tempDir,
+	}
+
+	fileContext := &models.FileContext{
+		Path: "widget.go",
+		Types: []models.TypeDef{
+			{Name: "Widget", Kind: "struct", StartLine: 3, EndLine: 5},
+			{Name: "Gadget", Kind: "struct", StartLine: 7, EndLine: 9},
+		},
+		Functions: []models.Function{
+			{
+				Name:      "NewWidget",
+				Signature: "func NewWidget() *Widget",
+				Returns:   []models.Type{{Name: "*Widget"}},
+				StartLine: 11,
+				EndLine:   13,
+			},
+		},
+		Variables: []models.Variable{
+			{Name: "Widget.Default", Type: "*Widget", StartLine: 15, EndLine: 15},
+		},
+	}
+	require.NoError(t, storage.StoreFileContext(fileContext), "Failed to store file context")
+
+	t.Run("real examples are marked real", func(t *testing.T) {
+		examples := server.extractUsageExamples(createTestSearchResultEntry("Widget", "widget.go", 3, 5, nil))
+		require.NotEmpty(t, examples)
+		assert.Equal(t, UsageSourceReal, examples[0].Source)
+		for _, example := range examples {
+			if example.Source == UsageSourceGenerated {
+				assert.Less(t, len(examples)-1, MinUsageExamples, "Generated examples should only pad up to the minimum")
+			}
+		}
+	})
+
+	t.Run("generated examples fill in when nothing is found", func(t *testing.T) {
+		examples := server.extractUsageExamples(createTestSearchResultEntry("Gadget", "widget.go", 7, 9, nil))
+		require.Len(t, examples, MinUsageExamples)
+		for _, example := range examples {
+			assert.Equal(t, UsageSourceGenerated, example.Source)
+		}
+	})
+}
+
+func TestContextTools_NotFoundSuggestions(t *testing.T) {
+	tempDir := t.TempDir()
+
+	storage := index.NewHybridStorage(tempDir)
+	require.NoError(t, storage.Initialize(), "Failed to initialize storage")
+	defer storage.Close()
+
+	server := &RepoContextMCPServer{
+		QueryEngine: index.NewQueryEngine(storage),
+		Storage:     storage,
+		RepoPath:    tempDir,
+	}
+	require.NoError(t, storage.StoreFileContext(&models.FileContext{
+		Path: "user.go",
+		Functions: []models.Function{
+			{Name: "GetUser", Signature: "func GetUser() *User", StartLine: 5, EndLine: 7},
+			{Name: "GetUsers", Signature: "func GetUsers() []User", StartLine: 9, EndLine: 11},
+		},
+		Types: []models.TypeDef{{Name: "User", Kind: "struct", StartLine: 1, EndLine: 3}},
+	}), "Failed to store file context")
+
+	_, err := server.buildFunctionContextResult(&GetFunctionContextParams{
+		FunctionName: "GetUsr", MaxTokens: constMaxTokens, SuggestSimilar: true,
+	})
+	require.Error(t, err)
+	assert.Equal(t, "no function 'GetUsr'; did you mean 'GetUser', 'GetUsers'?", err.Error())
+
+	// Only entities of the requested kind are suggested
+	_, err = server.buildTypeContextResult(&GetTypeContextParams{TypeName: "Users", MaxTokens: constMaxTokens, SuggestSimilar: true})
+	require.Error(t, err)
+	assert.Equal(t, "no type 'Users'; did you mean 'User'?", err.Error())
+
+	_, err = server.buildFunctionContextResult(&GetFunctionContextParams{FunctionName: "GetUsr", MaxTokens: constMaxTokens})
+	require.Error(t, err)
+	assert.Equal(t, "function 'GetUsr' not found", err.Error(), "Strict callers get no suggestions")
+
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]interface{}{"function_name": "GetUsr"}
+	params, err := server.parseGetFunctionContextParameters(request)
+	require.NoError(t, err)
+	assert.True(t, params.SuggestSimilar, "Suggestions should be on by default")
+}
+
+func TestContextTools_Siblings(t *testing.T) {
+	tempDir := t.TempDir()
+
+	storage := index.NewHybridStorage(tempDir)
+	require.NoError(t, storage.Initialize(), "Failed to initialize storage")
+	defer storage.Close()
+
+	server := &RepoContextMCPServer{
+		QueryEngine: index.NewQueryEngine(storage),
+		Storage:     storage,
+		RepoPath:    tempDir,
+	}
+
+	require.NoError(t, storage.StoreFileContext(&models.FileContext{
+		Path: "user.go",
+		Functions: []models.Function{
+			{Name: "NewUser", Signature: "func NewUser(name string) *User", StartLine: 10, EndLine: 18},
+			{Name: "validateName", Signature: "func validateName(name string) error", StartLine: 20, EndLine: 25},
+			{Name: "normalizeName", Signature: "func normalizeName(name string) string", StartLine: 27, EndLine: 30},
+			{Name: "trimName", Signature: "func trimName(name string) string", StartLine: 32, EndLine: 34},
+		},
+	}), "Failed to store file context")
+
+	t.Run("parameter is parsed and capped", func(t *testing.T) {
+		request := mcp.CallToolRequest{}
+		request.Params.Arguments = map[string]interface{}{"function_name": "validateName", "include_siblings": 100}
+		params, err := server.parseGetFunctionContextParameters(request)
+		require.NoError(t, err)
+		assert.Equal(t, MaxSiblingDeclarations, params.IncludeSiblings)
+
+		request.Params.Arguments = map[string]interface{}{"function_name": "validateName", "include_siblings": -1}
+		_, err = server.parseGetFunctionContextParameters(request)
+		assert.Error(t, err)
+	})
+
+	t.Run("siblings on both sides", func(t *testing.T) {
+		result, err := server.buildFunctionContextResult(&GetFunctionContextParams{
+			FunctionName:    "validateName",
+			MaxTokens:       constMaxTokens,
+			IncludeSiblings: 1,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []SiblingDeclaration{
+			{Name: "NewUser", Kind: "function", Line: 10, Signature: "func NewUser(name string) *User"},
+		}, result.SiblingsBefore)
+		assert.Equal(t, []SiblingDeclaration{
+			{Name: "normalizeName", Kind: "function", Line: 27, Signature: "func normalizeName(name string) string"},
+		}, result.SiblingsAfter)
+	})
+
+	t.Run("no siblings unless requested", func(t *testing.T) {
+		result, err := server.buildFunctionContextResult(&GetFunctionContextParams{FunctionName: "validateName", MaxTokens: constMaxTokens})
+		require.NoError(t, err)
+		assert.Empty(t, result.SiblingsBefore)
+		assert.Empty(t, result.SiblingsAfter)
+	})
+
+	t.Run("farthest siblings are trimmed first", func(t *testing.T) {
+		result := &FunctionContextResult{
+			FunctionName:   "normalizeName",
+			SiblingsBefore: []SiblingDeclaration{{Name: "NewUser"}, {Name: "validateName"}},
+			SiblingsAfter:  []SiblingDeclaration{{Name: "trimName"}},
+		}
+		server.optimizeFunctionContextResponse(result, FunctionContextBaseTokens+2*SiblingRefTokens, DefaultFunctionContextBudget)
+		assert.True(t, result.Truncated)
+		assert.Equal(t, []SiblingDeclaration{{Name: "validateName"}}, result.SiblingsBefore)
+		assert.Equal(t, []SiblingDeclaration{{Name: "trimName"}}, result.SiblingsAfter)
+		assert.Equal(t, FunctionContextBaseTokens+2*SiblingRefTokens, result.TokenCount)
+	})
+}
+
+func TestContextTools_AmbiguousFunctionName(t *testing.T) {
+	tempDir := t.TempDir()
+
+	storage := index.NewHybridStorage(tempDir)
+	require.NoError(t, storage.Initialize(), "Failed to initialize storage")
+	defer storage.Close()
+
+	server := &RepoContextMCPServer{
+		QueryEngine: index.NewQueryEngine(storage),
+		Storage:     storage,
+		RepoPath:    tempDir,
+	}
+
+	for _, receiver := range []string{"User", "Order"} {
+		require.NoError(t, storage.StoreFileContext(&models.FileContext{
+			Path: strings.ToLower(receiver) + ".go",
+			Functions: []models.Function{{
+				Name:      "Save",
+				Signature: "func (x *" + receiver + ") Save() error",
+				Receiver:  receiver,
+				StartLine: 5,
+				EndLine:   9,
+			}},
+		}), "Failed to store file context")
+	}
+
+	t.Run("shared name lists the candidates", func(t *testing.T) {
+		_, err := server.buildFunctionContextResult(&GetFunctionContextParams{FunctionName: "Save", MaxTokens: constMaxTokens})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "ambiguous, 2 functions match")
+		assert.Contains(t, err.Error(), "order.go:5 func (x *Order) Save() error (Order.Save)")
+		assert.Contains(t, err.Error(), "user.go:5 func (x *User) Save() error (User.Save)")
+	})
+
+	t.Run("file picks one", func(t *testing.T) {
+		request := mcp.CallToolRequest{}
+		request.Params.Arguments = map[string]interface{}{"function_name": "Save", "file": filepath.Join(tempDir, "user.go")}
+		params, err := server.parseGetFunctionContextParameters(request)
+		require.NoError(t, err)
+		assert.Equal(t, "user.go", params.File)
+
+		result, err := server.buildFunctionContextResult(params)
+		require.NoError(t, err)
+		assert.Equal(t, "func (x *User) Save() error", result.Signature)
+	})
+
+	t.Run("qualified name picks one", func(t *testing.T) {
+		result, err := server.buildFunctionContextResult(&GetFunctionContextParams{FunctionName: "Order.Save", MaxTokens: constMaxTokens})
+		require.NoError(t, err)
+		assert.Equal(t, "order.go", result.Location.File)
+	})
+}
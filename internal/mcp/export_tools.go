@@ -0,0 +1,137 @@
+package mcp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"repository-context-protocol/internal/index"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// IndexTransferResult describes an index written by export_index or read by import_index
+type IndexTransferResult struct {
+	Path string `json:"path"`
+	index.IndexExportSummary
+}
+
+// createExportIndexTool creates the export_index tool
+func (s *RepoContextMCPServer) createExportIndexTool() mcp.Tool {
+	return mcp.NewTool("export_index",
+		mcp.WithDescription(
+			"Export the whole index as one JSON document with a format_version, the manifest, every file context and the call graph, "+
+				"for offline analysis, backup or sharing a prebuilt index. Without output_path the document is returned directly",
+		),
+		mcp.WithString("output_path", mcp.Description(
+			"File to write the document to, relative to the repository root; a summary is returned instead of the document",
+		)),
+	)
+}
+
+// createImportIndexTool creates the import_index tool
+func (s *RepoContextMCPServer) createImportIndexTool() mcp.Tool {
+	return mcp.NewTool("import_index",
+		mcp.WithDescription(
+			"Load a document written by export_index into this repository's index, which must be initialized and empty, "+
+				"so a prebuilt index can be reused without rebuilding",
+		),
+		mcp.WithString("input_path", mcp.Required(), mcp.Description("Exported index file, relative to the repository root")),
+	)
+}
+
+// HandleExportIndex handles the export_index tool request
+func (s *RepoContextMCPServer) HandleExportIndex(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// System-level validation
+	if s.QueryEngine == nil || s.Storage == nil {
+		return nil, fmt.Errorf("query engine not initialized - system configuration error")
+	}
+
+	// Repository validation
+	if err := s.validateRepository(); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Repository validation failed: %v", err)), nil
+	}
+
+	outputPath := strings.TrimSpace(request.GetString("output_path", ""))
+	if outputPath == "" {
+		var document bytes.Buffer
+		if _, err := index.ExportIndex(&document, s.Storage); err != nil {
+			return s.FormatErrorResponse("export_index", err), nil
+		}
+		return mcp.NewToolResultText(document.String()), nil
+	}
+
+	result, err := s.exportIndexToFile(s.resolveRepoPath(outputPath))
+	if err != nil {
+		return s.FormatErrorResponse("export_index", err), nil
+	}
+	return s.FormatSuccessResponse(result), nil
+}
+
+// exportIndexToFile streams the index export into a new file at path
+func (s *RepoContextMCPServer) exportIndexToFile(path string) (result *IndexTransferResult, err error) {
+	// #nosec G304 - the export path is chosen by the caller of the tool
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", path, err)
+	}
+	defer func() {
+		if closeErr := file.Close(); closeErr != nil && err == nil {
+			err = fmt.Errorf("failed to close %s: %w", path, closeErr)
+		}
+	}()
+
+	writer := bufio.NewWriter(file)
+	summary, err := index.ExportIndex(writer, s.Storage)
+	if err != nil {
+		return nil, err
+	}
+	if err := writer.Flush(); err != nil {
+		return nil, fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return &IndexTransferResult{Path: path, IndexExportSummary: *summary}, nil
+}
+
+// HandleImportIndex handles the import_index tool request
+func (s *RepoContextMCPServer) HandleImportIndex(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// System-level validation
+	if s.QueryEngine == nil || s.Storage == nil {
+		return nil, fmt.Errorf("query engine not initialized - system configuration error")
+	}
+
+	// Repository validation
+	if err := s.validateRepository(); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Repository validation failed: %v", err)), nil
+	}
+
+	inputPath := strings.TrimSpace(request.GetString("input_path", ""))
+	if inputPath == "" {
+		return mcp.NewToolResultError("Parameter validation failed: input_path parameter is required"), nil
+	}
+	path := s.resolveRepoPath(inputPath)
+
+	// #nosec G304 - the import path is chosen by the caller of the tool
+	file, err := os.Open(path)
+	if err != nil {
+		return s.FormatErrorResponse("import_index", fmt.Errorf("failed to open %s: %w", path, err)), nil
+	}
+	defer file.Close()
+
+	summary, err := index.ImportIndex(bufio.NewReader(file), s.Storage)
+	if err != nil {
+		return s.FormatErrorResponse("import_index", err), nil
+	}
+	return s.FormatSuccessResponse(&IndexTransferResult{Path: path, IndexExportSummary: *summary}), nil
+}
+
+// resolveRepoPath joins a relative path to the repository root
+func (s *RepoContextMCPServer) resolveRepoPath(path string) string {
+	if filepath.IsAbs(path) {
+		return filepath.Clean(path)
+	}
+	return filepath.Join(s.RepoPath, path)
+}
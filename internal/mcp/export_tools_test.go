@@ -0,0 +1,80 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"repository-context-protocol/internal/index"
+	"repository-context-protocol/internal/models"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newExportTestServer creates a server over an initialized repository holding fileContexts
+func newExportTestServer(t *testing.T, fileContexts ...*models.FileContext) *RepoContextMCPServer {
+	t.Helper()
+	tempDir := t.TempDir()
+	storage := index.NewHybridStorage(filepath.Join(tempDir, ".repocontext"))
+	require.NoError(t, storage.Initialize(), "Failed to initialize storage")
+	t.Cleanup(func() { storage.Close() })
+
+	for _, fileContext := range fileContexts {
+		require.NoError(t, storage.StoreFileContext(fileContext), "Failed to store file context")
+	}
+	return &RepoContextMCPServer{QueryEngine: index.NewQueryEngine(storage), Storage: storage, RepoPath: tempDir}
+}
+
+func TestExportImportIndexTools(t *testing.T) {
+	source := newExportTestServer(t, &models.FileContext{
+		Path: "service.go", Language: "go",
+		Functions: []models.Function{
+			{Name: "Serve", StartLine: 1, EndLine: 5, Calls: []string{"handle"}},
+			{Name: "handle", StartLine: 7, EndLine: 9},
+		},
+	})
+
+	// Without output_path the document itself is returned
+	result, err := source.HandleExportIndex(context.Background(), mcp.CallToolRequest{})
+	require.NoError(t, err)
+	require.False(t, result.IsError, "Expected export_index to succeed")
+	var document map[string]json.RawMessage
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &document))
+	assert.Equal(t, "1", string(document["format_version"]))
+	assert.Contains(t, document, "manifest")
+	assert.Contains(t, document, "call_graph")
+
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]interface{}{"output_path": "index-export.json"}
+	result, err = source.HandleExportIndex(context.Background(), request)
+	require.NoError(t, err)
+	require.False(t, result.IsError, "Expected export_index to a file to succeed: %+v", result)
+	var exported IndexTransferResult
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &exported))
+	assert.Equal(t, filepath.Join(source.RepoPath, "index-export.json"), exported.Path)
+	assert.Equal(t, 1, exported.Files)
+	assert.Equal(t, 1, exported.CallRelations)
+
+	target := newExportTestServer(t)
+	request.Params.Arguments = map[string]interface{}{"input_path": exported.Path}
+	result, err = target.HandleImportIndex(context.Background(), request)
+	require.NoError(t, err)
+	require.False(t, result.IsError, "Expected import_index to succeed: %+v", result)
+
+	searchResult, err := target.QueryEngine.SearchByName("handle")
+	require.NoError(t, err)
+	assert.Len(t, searchResult.Entries, 1, "Expected imported functions to be searchable")
+
+	// A second import would merge two indexes, so it is refused
+	result, err = target.HandleImportIndex(context.Background(), request)
+	require.NoError(t, err)
+	assert.True(t, result.IsError, "Expected import into a non-empty index to fail")
+
+	request.Params.Arguments = map[string]interface{}{}
+	result, err = target.HandleImportIndex(context.Background(), request)
+	require.NoError(t, err)
+	assert.True(t, result.IsError, "Expected import_index without input_path to fail")
+}
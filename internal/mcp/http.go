@@ -0,0 +1,126 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// httpEndpoint maps an HTTP route onto the MCP tool that serves it
+type httpEndpoint struct {
+	Pattern string            // Route pattern, e.g. "GET /query"
+	Tool    string            // MCP tool whose handler answers the route
+	Aliases map[string]string // Short query parameter names mapped to tool argument names
+}
+
+// httpEndpoints lists the routes served by NewHTTPHandler besides /healthz
+var httpEndpoints = []httpEndpoint{
+	{Pattern: "GET /query", Tool: "query_by_name"},
+	{Pattern: "GET /pattern", Tool: "query_by_pattern", Aliases: map[string]string{"p": "pattern"}},
+	{Pattern: "GET /callgraph", Tool: "get_call_graph", Aliases: map[string]string{"fn": "function_name", "depth": "max_depth"}},
+	{Pattern: "GET /status", Tool: "get_repository_status"},
+}
+
+// httpErrorResponse is the body returned for failed HTTP requests
+type httpErrorResponse struct {
+	Error string `json:"error"`
+}
+
+// NewHTTPHandler exposes the query tools over plain HTTP for clients that do not speak MCP.
+// Query parameters are passed to the tool handlers as arguments, so validation and
+// response bodies match the MCP tools exactly.
+func (s *RepoContextMCPServer) NewHTTPHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) {
+		writeHTTPJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+	})
+	for _, endpoint := range httpEndpoints {
+		if !s.isToolEnabled(endpoint.Tool) {
+			continue
+		}
+		mux.HandleFunc(endpoint.Pattern, s.httpToolHandler(endpoint))
+	}
+	return mux
+}
+
+// httpToolHandler adapts an MCP tool handler to an HTTP handler
+func (s *RepoContextMCPServer) httpToolHandler(endpoint httpEndpoint) http.HandlerFunc {
+	handler := s.getToolHandler(endpoint.Tool)
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		arguments := make(map[string]interface{})
+		for key, values := range r.URL.Query() {
+			if len(values) == 0 {
+				continue
+			}
+			if name, ok := endpoint.Aliases[key]; ok {
+				key = name
+			}
+			arguments[key] = values[0]
+		}
+		// Status always describes the served repository rather than a caller-chosen path
+		if endpoint.Tool == "get_repository_status" {
+			arguments["path"] = s.RepoPath
+		}
+
+		request := mcp.CallToolRequest{}
+		request.Params.Name = endpoint.Tool
+		request.Params.Arguments = arguments
+
+		result, err := handler(r.Context(), request)
+		if err != nil {
+			writeHTTPJSON(w, http.StatusInternalServerError, httpErrorResponse{Error: err.Error()})
+			return
+		}
+
+		text := toolResultText(result)
+		if result.IsError {
+			writeHTTPJSON(w, http.StatusBadRequest, httpErrorResponse{Error: text})
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(text))
+	}
+}
+
+// toolResultText joins the text content of a tool result
+func toolResultText(result *mcp.CallToolResult) string {
+	text := ""
+	for _, content := range result.Content {
+		if textContent, ok := content.(mcp.TextContent); ok {
+			text += textContent.Text
+		}
+	}
+	return text
+}
+
+// writeHTTPJSON writes value as a JSON response with the given status code
+func writeHTTPJSON(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(value)
+}
+
+// RunHTTP runs the HTTP API on addr until ctx is cancelled
+func (s *RepoContextMCPServer) RunHTTP(ctx context.Context, addr string) error {
+	httpServer := &http.Server{
+		Addr:              addr,
+		Handler:           s.NewHTTPHandler(),
+		ReadHeaderTimeout: s.getQueryTimeout(),
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- httpServer.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+		return httpServer.Shutdown(context.Background())
+	}
+}
@@ -0,0 +1,98 @@
+package mcp
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"repository-context-protocol/internal/index"
+	"repository-context-protocol/internal/models"
+)
+
+func newHTTPTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	tempDir := t.TempDir()
+	storage := index.NewHybridStorage(filepath.Join(tempDir, ".repocontext"))
+	require.NoError(t, storage.Initialize())
+	t.Cleanup(func() { storage.Close() })
+
+	require.NoError(t, storage.StoreFileContext(&models.FileContext{
+		Path:     "main.go",
+		Language: "go",
+		Functions: []models.Function{
+			{Name: "main", Signature: "func main()", StartLine: 1, EndLine: 3, Calls: []string{"run"}},
+			{Name: "run", Signature: "func run()", StartLine: 5, EndLine: 7},
+		},
+	}))
+
+	server := NewRepoContextMCPServer()
+	server.RepoPath = tempDir
+	server.Storage = storage
+	server.QueryEngine = index.NewQueryEngine(storage)
+
+	httpServer := httptest.NewServer(server.NewHTTPHandler())
+	t.Cleanup(httpServer.Close)
+	return httpServer
+}
+
+func getJSON(t *testing.T, url string, target interface{}) int {
+	t.Helper()
+
+	resp, err := http.Get(url)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(target))
+	return resp.StatusCode
+}
+
+func TestHTTPHandler_Endpoints(t *testing.T) {
+	httpServer := newHTTPTestServer(t)
+
+	t.Run("healthz", func(t *testing.T) {
+		var body map[string]string
+		assert.Equal(t, http.StatusOK, getJSON(t, httpServer.URL+"/healthz", &body))
+		assert.Equal(t, "ok", body["status"])
+	})
+
+	t.Run("query", func(t *testing.T) {
+		var result index.SearchResult
+		assert.Equal(t, http.StatusOK, getJSON(t, httpServer.URL+"/query?name=main", &result))
+		require.Len(t, result.Entries, 1)
+		assert.Equal(t, "main", result.Entries[0].IndexEntry.Name)
+	})
+
+	t.Run("pattern", func(t *testing.T) {
+		var result index.SearchResult
+		assert.Equal(t, http.StatusOK, getJSON(t, httpServer.URL+"/pattern?p=ru*", &result))
+		require.Len(t, result.Entries, 1)
+		assert.Equal(t, "run", result.Entries[0].IndexEntry.Name)
+	})
+
+	t.Run("callgraph", func(t *testing.T) {
+		var callGraph index.CallGraphInfo
+		assert.Equal(t, http.StatusOK, getJSON(t, httpServer.URL+"/callgraph?fn=main&depth=1&include_callees=true", &callGraph))
+		assert.Equal(t, 1, callGraph.Depth)
+		require.Len(t, callGraph.Callees, 1)
+		assert.Equal(t, "run", callGraph.Callees[0].Function)
+	})
+
+	t.Run("status", func(t *testing.T) {
+		var status map[string]interface{}
+		assert.Equal(t, http.StatusOK, getJSON(t, httpServer.URL+"/status", &status))
+		assert.NotEmpty(t, status)
+	})
+
+	t.Run("validation errors are bad requests", func(t *testing.T) {
+		var body httpErrorResponse
+		assert.Equal(t, http.StatusBadRequest, getJSON(t, httpServer.URL+"/callgraph", &body))
+		assert.Contains(t, body.Error, "function_name")
+	})
+}
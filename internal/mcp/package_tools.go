@@ -0,0 +1,264 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"slices"
+
+	"repository-context-protocol/internal/index"
+	"repository-context-protocol/internal/models"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Token management constants for package context
+const (
+	PackageContextBaseTokens = 100 // Base tokens for package metadata (path, name, doc)
+	PackageFileTokens        = 10  // Average tokens per file summary header
+	PackageImportTokens      = 5   // Average tokens per import path
+	PackageSymbolTokens      = 20  // Average tokens per exported symbol
+)
+
+// GetPackageContextParams encapsulates get_package_context parameters
+type GetPackageContextParams struct {
+	PackagePath string
+	MaxTokens   int
+}
+
+// GetMaxTokens implements QueryOptionsBuilder interface
+func (p *GetPackageContextParams) GetMaxTokens() int { return p.MaxTokens }
+
+// PackageSymbol represents an exported symbol of a package
+type PackageSymbol struct {
+	Name      string `json:"name"`
+	Kind      string `json:"kind"`
+	Signature string `json:"signature,omitempty"`
+	File      string `json:"file"`
+	Line      int    `json:"line"`
+}
+
+// PackageFileSummary summarizes a single file of a package
+type PackageFileSummary struct {
+	Path     string          `json:"path"`
+	Language string          `json:"language"`
+	Doc      string          `json:"doc,omitempty"`
+	Imports  []string        `json:"imports,omitempty"`
+	Exports  []PackageSymbol `json:"exports,omitempty"`
+}
+
+// PackageContextResult represents the aggregated context of all files in a directory
+type PackageContextResult struct {
+	PackagePath string               `json:"package_path"`
+	Package     string               `json:"package,omitempty"`
+	Doc         string               `json:"doc,omitempty"`
+	Files       []PackageFileSummary `json:"files"`
+	Imports     []string             `json:"imports,omitempty"`
+	Exports     []PackageSymbol      `json:"exports,omitempty"`
+	TokenCount  int                  `json:"token_count"`
+	Truncated   bool                 `json:"truncated"`
+}
+
+// packageDocFiles are files conventionally holding a package's documentation
+var packageDocFiles = []string{"doc.go", "__init__.py", "package-info.java"}
+
+// RegisterPackageTools registers package-level analysis tools
+func (s *RepoContextMCPServer) RegisterPackageTools() []mcp.Tool {
+	return []mcp.Tool{
+		s.createGetPackageContextTool(),
+	}
+}
+
+// createGetPackageContextTool creates the get_package_context tool
+func (s *RepoContextMCPServer) createGetPackageContextTool() mcp.Tool {
+	return mcp.NewTool("get_package_context",
+		mcp.WithDescription(
+			"Get a directory-level summary of a package or module including its doc, imports, and exported symbols grouped by file",
+		),
+		mcp.WithString("package_path", mcp.Required(), mcp.Description("Directory of the package, relative to the repository root")),
+		mcp.WithNumber("max_tokens", mcp.Description("Maximum tokens for response (default: 2000)")),
+	)
+}
+
+// HandleGetPackageContext provides an aggregated view of all files in a package directory
+func (s *RepoContextMCPServer) HandleGetPackageContext(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	ops := ToolOperations[*GetPackageContextParams, *PackageContextResult]{
+		ParseParams: s.parseGetPackageContextParameters,
+		BuildResult: s.buildPackageContextResult,
+		OptimizeResult: func(_ *GetPackageContextParams, result *PackageContextResult, maxTokens int) {
+			s.optimizePackageContextResponse(result, maxTokens)
+		},
+		ToolName: "get_package_context",
+	}
+	return executeGenericToolHandler(s, request, ops)
+}
+
+// parseGetPackageContextParameters extracts and validates parameters for get_package_context
+func (s *RepoContextMCPServer) parseGetPackageContextParameters(request mcp.CallToolRequest) (*GetPackageContextParams, error) {
+	packagePath := request.GetString("package_path", "")
+	if packagePath == "" {
+		return nil, fmt.Errorf("package_path parameter is required")
+	}
+
+	return &GetPackageContextParams{
+		PackagePath: s.repoRelativePath(packagePath),
+		MaxTokens:   request.GetInt("max_tokens", s.getMaxTokens()),
+	}, nil
+}
+
+// buildPackageContextResult merges the indexed contexts of all files directly inside the package directory
+func (s *RepoContextMCPServer) buildPackageContextResult(params *GetPackageContextParams) (*PackageContextResult, error) {
+	files, err := s.findPackageFiles(params.PackagePath)
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no indexed files found in %s", params.PackagePath)
+	}
+
+	result := &PackageContextResult{
+		PackagePath: params.PackagePath,
+		Files:       []PackageFileSummary{},
+	}
+	seenImports := make(map[string]bool)
+	var docFallback string
+
+	for _, file := range files {
+		fileContext, err := s.QueryEngine.GetFileContext(file)
+		if err != nil {
+			return nil, err
+		}
+
+		summary := PackageFileSummary{
+			Path:     file,
+			Language: fileContext.Language,
+			Doc:      fileContext.Doc,
+			Exports:  s.findPackageExports(fileContext),
+		}
+		for _, imp := range fileContext.Imports {
+			summary.Imports = append(summary.Imports, imp.Path)
+			if !seenImports[imp.Path] {
+				seenImports[imp.Path] = true
+				result.Imports = append(result.Imports, imp.Path)
+			}
+		}
+
+		if result.Package == "" {
+			result.Package = fileContext.Package
+		}
+		if fileContext.Doc != "" {
+			if slices.Contains(packageDocFiles, filepath.Base(file)) {
+				result.Doc = fileContext.Doc
+			} else if docFallback == "" {
+				docFallback = fileContext.Doc
+			}
+		}
+
+		result.Files = append(result.Files, summary)
+		result.Exports = append(result.Exports, summary.Exports...)
+	}
+
+	if result.Doc == "" {
+		result.Doc = docFallback
+	}
+	slices.Sort(result.Imports)
+
+	return result, nil
+}
+
+// findPackageFiles returns indexed files located directly in the package directory.
+// Indexes built before paths were stored repo-relative hold them under the repository path.
+func (s *RepoContextMCPServer) findPackageFiles(packagePath string) ([]string, error) {
+	allFiles, err := s.QueryEngine.ListFiles()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list indexed files: %w", err)
+	}
+
+	directories := []string{packagePath}
+	if s.RepoPath != "" && !filepath.IsAbs(packagePath) {
+		directories = append(directories, filepath.Join(s.RepoPath, packagePath))
+	}
+
+	var files []string
+	for _, file := range allFiles {
+		if slices.Contains(directories, filepath.Dir(file)) {
+			files = append(files, file)
+		}
+	}
+	return files, nil
+}
+
+// findPackageExports lists the exported symbols of a file with their locations.
+// Symbols are looked up with SearchInFileWithOptions so signatures match other query tools.
+func (s *RepoContextMCPServer) findPackageExports(fileContext *models.FileContext) []PackageSymbol {
+	if len(fileContext.Exports) == 0 {
+		return nil
+	}
+
+	exportKinds := make(map[string]string)
+	for _, export := range fileContext.Exports {
+		exportKinds[export.Name] = export.Kind
+	}
+
+	searchResult, err := s.QueryEngine.SearchInFileWithOptions(fileContext.Path, index.QueryOptions{IncludeTypes: true, OrderBySource: true})
+	if err != nil {
+		return nil
+	}
+
+	var symbols []PackageSymbol
+	for _, entry := range searchResult.Entries {
+		kind, exported := exportKinds[entry.IndexEntry.Name]
+		if !exported {
+			continue
+		}
+		symbols = append(symbols, PackageSymbol{
+			Name:      entry.IndexEntry.Name,
+			Kind:      kind,
+			Signature: entry.IndexEntry.Signature,
+			File:      entry.IndexEntry.File,
+			Line:      entry.IndexEntry.StartLine,
+		})
+	}
+	return symbols
+}
+
+// optimizePackageContextResponse trims the package context to fit the token limit.
+// Per-file export lists are dropped first since the flattened export list repeats them.
+func (s *RepoContextMCPServer) optimizePackageContextResponse(result *PackageContextResult, maxTokens int) {
+	result.TokenCount = s.estimatePackageContextTokens(result)
+	if maxTokens <= 0 || result.TokenCount <= maxTokens {
+		result.Truncated = false
+		return
+	}
+
+	result.Truncated = true
+	for i := range result.Files {
+		result.Files[i].Exports = nil
+		result.Files[i].Imports = nil
+	}
+
+	result.TokenCount = s.estimatePackageContextTokens(result)
+	if result.TokenCount > maxTokens {
+		excessExports := (result.TokenCount - maxTokens + PackageSymbolTokens - 1) / PackageSymbolTokens
+		keep := max(len(result.Exports)-excessExports, 0)
+		result.Exports = result.Exports[:keep]
+		result.TokenCount = s.estimatePackageContextTokens(result)
+	}
+}
+
+// estimatePackageContextTokens estimates token count for package context result
+func (s *RepoContextMCPServer) estimatePackageContextTokens(result *PackageContextResult) int {
+	tokens := PackageContextBaseTokens + s.estimateTextTokens(result.Doc)
+
+	for i := range result.Files {
+		file := &result.Files[i]
+		tokens += PackageFileTokens + s.estimateTextTokens(file.Doc)
+		tokens += len(file.Imports) * PackageImportTokens
+		tokens += len(file.Exports) * PackageSymbolTokens
+	}
+
+	tokens += len(result.Imports) * PackageImportTokens
+	tokens += len(result.Exports) * PackageSymbolTokens
+
+	return tokens
+}
@@ -0,0 +1,122 @@
+package mcp
+
+import (
+	"path/filepath"
+	"testing"
+
+	"repository-context-protocol/internal/index"
+	"repository-context-protocol/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPackageTools_Registration(t *testing.T) {
+	server := NewRepoContextMCPServer()
+
+	tools := server.RegisterPackageTools()
+
+	require.Len(t, tools, 1)
+	assert.Equal(t, "get_package_context", tools[0].Name)
+	assert.Contains(t, tools[0].InputSchema.Required, "package_path")
+}
+
+func TestPackageTools_BuildPackageContext(t *testing.T) {
+	tempDir := t.TempDir()
+
+	storage := index.NewHybridStorage(tempDir)
+	require.NoError(t, storage.Initialize(), "Failed to initialize storage")
+	defer storage.Close()
+
+	server := &RepoContextMCPServer{
+		QueryEngine: index.NewQueryEngine(storage),
+		Storage:     storage,
+		RepoPath:    tempDir,
+	}
+
+	fileContexts := []*models.FileContext{
+		{
+			Path:     filepath.Join(tempDir, "users", "service.go"),
+			Language: "go",
+			Package:  "users",
+			Functions: []models.Function{
+				{Name: "NewService", Signature: "func NewService() *Service", StartLine: 12, EndLine: 14},
+				{Name: "helper", Signature: "func helper()", StartLine: 16, EndLine: 17},
+			},
+			Types:   []models.TypeDef{{Name: "Service", Kind: "struct", StartLine: 8, EndLine: 10}},
+			Imports: []models.Import{{Path: "fmt"}, {Path: "strings"}},
+			Exports: []models.Export{
+				{Name: "NewService", Kind: "function"},
+				{Name: "Service", Kind: "type"},
+			},
+		},
+		{
+			Path:      filepath.Join(tempDir, "users", "doc.go"),
+			Language:  "go",
+			Package:   "users",
+			Doc:       "Package users manages user accounts.",
+			Imports:   []models.Import{{Path: "fmt"}},
+			Constants: []models.Constant{{Name: "MaxUsers", Value: "10", StartLine: 5, EndLine: 5}},
+			Exports:   []models.Export{{Name: "MaxUsers", Kind: "constant"}},
+		},
+		{
+			Path:      filepath.Join(tempDir, "orders", "service.go"),
+			Language:  "go",
+			Package:   "orders",
+			Functions: []models.Function{{Name: "PlaceOrder", Signature: "func PlaceOrder()", StartLine: 3, EndLine: 5}},
+			Imports:   []models.Import{{Path: "time"}},
+			Exports:   []models.Export{{Name: "PlaceOrder", Kind: "function"}},
+		},
+	}
+	for _, fileContext := range fileContexts {
+		require.NoError(t, storage.StoreFileContext(fileContext), "Failed to store file context")
+	}
+
+	t.Run("aggregates files in the directory", func(t *testing.T) {
+		result, err := server.buildPackageContextResult(&GetPackageContextParams{PackagePath: "users", MaxTokens: constMaxTokens})
+		require.NoError(t, err)
+
+		assert.Equal(t, "users", result.Package)
+		assert.Equal(t, "Package users manages user accounts.", result.Doc)
+		assert.Equal(t, []string{"fmt", "strings"}, result.Imports, "Imports should be deduplicated across files")
+
+		require.Len(t, result.Files, 2)
+		assert.Equal(t, filepath.Join(tempDir, "users", "doc.go"), result.Files[0].Path)
+		assert.Equal(t, filepath.Join(tempDir, "users", "service.go"), result.Files[1].Path)
+
+		var serviceExports []string
+		for _, symbol := range result.Files[1].Exports {
+			serviceExports = append(serviceExports, symbol.Name)
+		}
+		assert.Equal(t, []string{"Service", "NewService"}, serviceExports, "Exports should be ordered by line")
+		assert.Equal(t, "func NewService() *Service", result.Files[1].Exports[1].Signature)
+
+		var allExports []string
+		for _, symbol := range result.Exports {
+			allExports = append(allExports, symbol.Name)
+		}
+		assert.Equal(t, []string{"MaxUsers", "Service", "NewService"}, allExports)
+		assert.NotContains(t, allExports, "helper")
+		assert.NotContains(t, allExports, "PlaceOrder")
+	})
+
+	t.Run("unknown directory returns error", func(t *testing.T) {
+		_, err := server.buildPackageContextResult(&GetPackageContextParams{PackagePath: "missing"})
+		assert.Error(t, err)
+	})
+
+	t.Run("token limit drops per-file detail first", func(t *testing.T) {
+		result, err := server.buildPackageContextResult(&GetPackageContextParams{PackagePath: "users"})
+		require.NoError(t, err)
+
+		limit := server.estimatePackageContextTokens(result) - 1
+		server.optimizePackageContextResponse(result, limit)
+
+		assert.True(t, result.Truncated)
+		assert.LessOrEqual(t, result.TokenCount, limit)
+		assert.Len(t, result.Exports, 3, "Flattened exports should be kept when dropping per-file lists is enough")
+		for _, file := range result.Files {
+			assert.Empty(t, file.Exports)
+		}
+	})
+}
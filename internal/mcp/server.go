@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 
 	"repository-context-protocol/internal/index"
 
@@ -14,20 +15,36 @@ import (
 )
 
 const (
-	constMaxTokens = 2000 // TODO: this should not be static - user defined from config
+	constMaxTokens = 2000 // Default max_tokens, overridable in config.json
 	constMaxDepth  = 2
 
+	// DefaultQueryTimeoutSeconds bounds a single query, overridable in config.json
+	DefaultQueryTimeoutSeconds = 30
+
 	// Phase 4.1: Server Configuration Constants
 	ServerName    = "repocontext"
 	ServerVersion = "1.0.0"
+
+	// TokenEstimatorEnvVar selects the token estimator by name (see index.SupportedTokenEstimators)
+	TokenEstimatorEnvVar = "REPOCONTEXT_TOKEN_ESTIMATOR"
+
+	// MaxSharedRegexPatterns bounds the compiled patterns kept for all query engines
+	MaxSharedRegexPatterns = 512
 )
 
+// sharedRegexCache is used by every query engine the servers in this process create,
+// so patterns compile once however often the engine is recreated on reinitialization
+var sharedRegexCache = index.NewRegexCache(MaxSharedRegexPatterns)
+
 // Phase 4.1: Server Configuration
 type ServerConfiguration struct {
-	Name      string `json:"name"`
-	Version   string `json:"version"`
-	MaxTokens int    `json:"max_tokens"`
-	MaxDepth  int    `json:"max_depth"`
+	Name                string `json:"name"`
+	Version             string `json:"version"`
+	MaxTokens           int    `json:"max_tokens"`
+	MaxDepth            int    `json:"max_depth"`
+	MaxContextLines     int    `json:"max_context_lines"`
+	QueryTimeoutSeconds int    `json:"query_timeout_seconds"`
+	TokenEstimator      string `json:"token_estimator"`
 }
 
 // RepoContextMCPServer provides MCP server functionality for repository context protocol
@@ -38,6 +55,10 @@ type RepoContextMCPServer struct {
 	server      *server.MCPServer
 	// Phase 4.2: Error Recovery Manager
 	errorRecoveryMgr *ErrorRecoveryManager
+	// tokenEstimator drives token counting and truncation decisions
+	tokenEstimator index.TokenEstimator
+	// config holds defaults read from .repocontext/config.json
+	config *RepoConfig
 }
 
 // NewRepoContextMCPServer creates a new MCP server instance
@@ -45,7 +66,72 @@ func NewRepoContextMCPServer() *RepoContextMCPServer {
 	return &RepoContextMCPServer{
 		// Phase 4.2: Initialize error recovery manager
 		errorRecoveryMgr: NewErrorRecoveryManager(),
+		tokenEstimator:   index.DefaultTokenEstimator(),
+		config:           DefaultRepoConfig(),
+	}
+}
+
+// SetTokenEstimator replaces the estimator used for token counting and truncation.
+// Passing nil restores the default estimator. The query engine is updated as well.
+func (s *RepoContextMCPServer) SetTokenEstimator(estimator index.TokenEstimator) {
+	if estimator == nil {
+		estimator = index.DefaultTokenEstimator()
+	}
+	s.tokenEstimator = estimator
+	if s.QueryEngine != nil {
+		s.QueryEngine.SetTokenEstimator(estimator)
+	}
+}
+
+// getTokenEstimator returns the configured token estimator, falling back to the default
+func (s *RepoContextMCPServer) getTokenEstimator() index.TokenEstimator {
+	if s.tokenEstimator == nil {
+		return index.DefaultTokenEstimator()
+	}
+	return s.tokenEstimator
+}
+
+// getMaxContextLines returns the configured upper bound for function context lines
+func (s *RepoContextMCPServer) getMaxContextLines() int {
+	if s.config == nil || s.config.MaxContextLines <= 0 {
+		return MaxContextLines
+	}
+	return s.config.MaxContextLines
+}
+
+// getMaxTokens returns the configured default token limit for tool responses
+func (s *RepoContextMCPServer) getMaxTokens() int {
+	if s.config == nil || s.config.MaxTokens <= 0 {
+		return constMaxTokens
+	}
+	return s.config.MaxTokens
+}
+
+// getMaxDepth returns the configured default call graph depth
+func (s *RepoContextMCPServer) getMaxDepth() int {
+	if s.config == nil || s.config.MaxDepth <= 0 {
+		return constMaxDepth
 	}
+	return s.config.MaxDepth
+}
+
+// getQueryTimeout returns the configured time limit for a single query
+func (s *RepoContextMCPServer) getQueryTimeout() time.Duration {
+	if s.config == nil || s.config.QueryTimeoutSeconds <= 0 {
+		return DefaultQueryTimeoutSeconds * time.Second
+	}
+	return time.Duration(s.config.QueryTimeoutSeconds) * time.Second
+}
+
+// queryContext derives a context bounded by the configured query timeout.
+// Callers must invoke the returned cancel function.
+func (s *RepoContextMCPServer) queryContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(ctx, s.getQueryTimeout())
+}
+
+// estimateTextTokens estimates the token count of free-form text
+func (s *RepoContextMCPServer) estimateTextTokens(text string) int {
+	return s.getTokenEstimator().EstimateTokens(text)
 }
 
 // ============================================================================
@@ -93,6 +179,9 @@ func (s *RepoContextMCPServer) RegisterAllTools() []mcp.Tool {
 	// Register Context Analysis Tools
 	allTools = append(allTools, s.RegisterContextTools()...)
 
+	// Register Package Analysis Tools
+	allTools = append(allTools, s.RegisterPackageTools()...)
+
 	return allTools
 }
 
@@ -114,6 +203,9 @@ func (s *RepoContextMCPServer) SetupToolHandlers(mcpServer *server.MCPServer) er
 	allTools := s.RegisterAllTools()
 
 	for i := range allTools {
+		if !s.isToolEnabled(allTools[i].Name) {
+			continue
+		}
 		handler := s.getToolHandler(allTools[i].Name)
 		if handler != nil {
 			mcpServer.AddTool(allTools[i], handler)
@@ -140,6 +232,34 @@ func (s *RepoContextMCPServer) getToolHandler(toolName string) func(
 		return s.HandleAdvancedListFunctions
 	case "list_types":
 		return s.HandleAdvancedListTypes
+	case "autocomplete":
+		return s.HandleAutocomplete
+	case "query_by_decorator":
+		return s.HandleQueryByDecorator
+	case "analyze_imports":
+		return s.HandleAnalyzeImports
+	case "list_files":
+		return s.HandleListFiles
+	case "get_hotspots":
+		return s.HandleGetHotspots
+	case "search_content":
+		return s.HandleSearchContent
+	case "find_call_path":
+		return s.HandleFindCallPath
+	case "get_test_coverage":
+		return s.HandleGetTestCoverage
+	case "get_overview":
+		return s.HandleGetOverview
+	case "find_dead_code":
+		return s.HandleFindDeadCode
+	case "symbol_at_location":
+		return s.HandleSymbolAtLocation
+	case "batch_query":
+		return s.HandleBatchQuery
+	case "find_recursion":
+		return s.HandleFindRecursion
+	case "resolve_import":
+		return s.HandleResolveImport
 
 	// Repository Management Tools
 	case "initialize_repository":
@@ -148,18 +268,30 @@ func (s *RepoContextMCPServer) getToolHandler(toolName string) func(
 		return s.HandleBuildIndex
 	case "get_repository_status":
 		return s.HandleGetRepositoryStatus
+	case "export_index":
+		return s.HandleExportIndex
+	case "import_index":
+		return s.HandleImportIndex
 
 	// Enhanced Call Graph Tools
 	case "get_call_graph_enhanced":
 		return s.HandleEnhancedGetCallGraph
 	case "find_dependencies":
 		return s.HandleFindDependencies
+	case "export_call_graph":
+		return s.HandleExportCallGraph
 
 	// Context Analysis Tools
 	case "get_function_context":
 		return s.HandleGetFunctionContext
 	case "get_type_context":
 		return s.HandleGetTypeContext
+	case "summarize_function":
+		return s.HandleSummarizeFunction
+
+	// Package Analysis Tools
+	case "get_package_context":
+		return s.HandleGetPackageContext
 
 	default:
 		return nil
@@ -173,8 +305,29 @@ func (s *RepoContextMCPServer) InitializeWithContext(ctx context.Context) error
 	if err != nil {
 		return fmt.Errorf("failed to detect repository root: %w", err)
 	}
+	return s.InitializeRepository(repoPath)
+}
+
+// InitializeRepository loads configuration and opens the index of the repository at repoPath
+func (s *RepoContextMCPServer) InitializeRepository(repoPath string) error {
 	s.RepoPath = repoPath
 
+	// Load server defaults from the repository configuration
+	config, err := LoadRepoConfig(repoPath)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	s.config = config
+
+	// Select token estimator from environment if configured
+	if estimatorName := os.Getenv(TokenEstimatorEnvVar); estimatorName != "" {
+		estimator, err := index.NewTokenEstimator(estimatorName)
+		if err != nil {
+			return fmt.Errorf("invalid %s: %w", TokenEstimatorEnvVar, err)
+		}
+		s.SetTokenEstimator(estimator)
+	}
+
 	// Initialize query engine
 	if err := s.initializeQueryEngine(); err != nil {
 		return fmt.Errorf("failed to initialize query engine: %w", err)
@@ -188,28 +341,32 @@ func (s *RepoContextMCPServer) InitializeServerLifecycle(ctx context.Context) (*
 	// Create MCP server
 	mcpServer := s.CreateMCPServer()
 
-	// Setup tool handlers
-	if err := s.SetupToolHandlers(mcpServer); err != nil {
-		return mcpServer, fmt.Errorf("failed to setup tool handlers: %w", err)
-	}
-
-	// Initialize repository context (non-blocking with graceful degradation)
+	// Initialize repository context (non-blocking with graceful degradation).
+	// This runs before tool setup so tool descriptions reflect the loaded configuration.
 	if err := s.InitializeWithContext(ctx); err != nil {
 		// Log the error but don't fail - server can still operate with limited functionality
 		fmt.Fprintf(os.Stderr, "Warning: Repository initialization failed: %v\n", err)
 		fmt.Fprintf(os.Stderr, "Server will continue with limited functionality\n")
 	}
 
+	// Setup tool handlers
+	if err := s.SetupToolHandlers(mcpServer); err != nil {
+		return mcpServer, fmt.Errorf("failed to setup tool handlers: %w", err)
+	}
+
 	return mcpServer, nil
 }
 
 // GetServerConfiguration returns the current server configuration
 func (s *RepoContextMCPServer) GetServerConfiguration() *ServerConfiguration {
 	return &ServerConfiguration{
-		Name:      ServerName,
-		Version:   ServerVersion,
-		MaxTokens: constMaxTokens,
-		MaxDepth:  constMaxDepth,
+		Name:                ServerName,
+		Version:             ServerVersion,
+		MaxTokens:           s.getMaxTokens(),
+		MaxDepth:            s.getMaxDepth(),
+		MaxContextLines:     s.getMaxContextLines(),
+		QueryTimeoutSeconds: int(s.getQueryTimeout() / time.Second),
+		TokenEstimator:      s.getTokenEstimator().Name(),
 	}
 }
 
@@ -286,7 +443,8 @@ func (s *RepoContextMCPServer) initializeQueryEngine() error {
 	}
 
 	s.Storage = storage
-	s.QueryEngine = index.NewQueryEngine(storage)
+	s.QueryEngine = index.NewQueryEngine(storage, index.WithRegexCache(sharedRegexCache))
+	s.QueryEngine.SetTokenEstimator(s.getTokenEstimator())
 
 	return nil
 }
@@ -305,6 +463,15 @@ func (s *RepoContextMCPServer) validateRepository() error {
 	return nil
 }
 
+// repoRelativePath converts a file or directory path given by a client to the
+// repo-relative form the index stores paths in
+func (s *RepoContextMCPServer) repoRelativePath(path string) string {
+	if path == "" {
+		return ""
+	}
+	return index.RepoRelativePath(s.RepoPath, path)
+}
+
 // RegisterQueryTools registers the query-related MCP tools
 func (s *RepoContextMCPServer) RegisterQueryTools() []mcp.Tool {
 	return s.RegisterAdvancedQueryTools()
@@ -8,6 +8,8 @@ import (
 	"testing"
 	"time"
 
+	"repository-context-protocol/internal/index"
+
 	"github.com/mark3labs/mcp-go/mcp"
 )
 
@@ -188,13 +190,32 @@ func TestRepoContextMCPServer_RegisterAllTools(t *testing.T) {
 		"get_call_graph",          // Advanced Query Tools + Enhanced Call Graph Tools
 		"list_functions",          // Advanced Query Tools
 		"list_types",              // Advanced Query Tools
+		"autocomplete",            // Advanced Query Tools
+		"query_by_decorator",      // Advanced Query Tools
+		"analyze_imports",         // Advanced Query Tools
+		"list_files",              // Advanced Query Tools
+		"get_hotspots",            // Advanced Query Tools
+		"search_content",          // Advanced Query Tools
+		"find_call_path",          // Advanced Query Tools
+		"get_test_coverage",       // Advanced Query Tools
+		"get_overview",            // Advanced Query Tools
+		"find_dead_code",          // Advanced Query Tools
+		"symbol_at_location",      // Advanced Query Tools
+		"batch_query",             // Advanced Query Tools
+		"find_recursion",          // Advanced Query Tools
+		"resolve_import",          // Advanced Query Tools
 		"initialize_repository",   // Repository Management Tools
 		"build_index",             // Repository Management Tools
 		"get_repository_status",   // Repository Management Tools
+		"export_index",            // Repository Management Tools
+		"import_index",            // Repository Management Tools
 		"get_call_graph_enhanced", // Enhanced Call Graph Tools
 		"find_dependencies",       // Enhanced Call Graph Tools
+		"export_call_graph",       // Enhanced Call Graph Tools
 		"get_function_context",    // Context Analysis Tools
 		"get_type_context",        // Context Analysis Tools
+		"summarize_function",      // Context Analysis Tools
+		"get_package_context",     // Package Analysis Tools
 	}
 
 	toolNames := make(map[string]bool)
@@ -289,6 +310,7 @@ func TestRepoContextMCPServer_ToolRegistrationOrchestration(t *testing.T) {
 	repoTools := server.RegisterRepositoryManagementTools()
 	callGraphTools := server.RegisterCallGraphTools()
 	contextTools := server.RegisterContextTools()
+	packageTools := server.RegisterPackageTools()
 
 	if len(queryTools) == 0 {
 		t.Error("RegisterAdvancedQueryTools should return tools")
@@ -306,9 +328,13 @@ func TestRepoContextMCPServer_ToolRegistrationOrchestration(t *testing.T) {
 		t.Error("RegisterContextTools should return tools")
 	}
 
+	if len(packageTools) == 0 {
+		t.Error("RegisterPackageTools should return tools")
+	}
+
 	// Test orchestrated registration
 	allTools := server.RegisterAllTools()
-	expectedTotal := len(queryTools) + len(repoTools) + len(callGraphTools) + len(contextTools)
+	expectedTotal := len(queryTools) + len(repoTools) + len(callGraphTools) + len(contextTools) + len(packageTools)
 
 	if len(allTools) != expectedTotal {
 		t.Errorf("RegisterAllTools should return %d tools, got %d", expectedTotal, len(allTools))
@@ -342,6 +368,28 @@ func TestRepoContextMCPServer_ConfigurationManagement(t *testing.T) {
 	}
 }
 
+func TestRepoContextMCPServer_SetTokenEstimator(t *testing.T) {
+	server := NewRepoContextMCPServer()
+
+	if name := server.GetServerConfiguration().TokenEstimator; name != index.TokenEstimatorHeuristic {
+		t.Errorf("Expected default token estimator %q, got %q", index.TokenEstimatorHeuristic, name)
+	}
+
+	server.SetTokenEstimator(&index.BPEEstimator{})
+	if name := server.GetServerConfiguration().TokenEstimator; name != index.TokenEstimatorBPE {
+		t.Errorf("Expected token estimator %q, got %q", index.TokenEstimatorBPE, name)
+	}
+
+	// Implementation truncation must respect the configured estimator
+	result := &FunctionContextResult{
+		Implementation: &FunctionImplementation{Body: strings.Repeat("identifierName ", 200)},
+	}
+	server.optimizeImplementation(result.Implementation, ImplementationOverheadTokens+40)
+	if tokens := server.estimateTextTokens(result.Implementation.Body); tokens > 40 {
+		t.Errorf("Expected truncated body within budget, got %d tokens", tokens)
+	}
+}
+
 func TestRepoContextMCPServer_LifecycleIntegration(t *testing.T) {
 	server := NewRepoContextMCPServer()
 	ctx := context.Background()
@@ -0,0 +1,208 @@
+package mcp
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"repository-context-protocol/internal/index"
+	"repository-context-protocol/internal/models"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Token management constants for function summaries
+const (
+	SummarizeFunctionBaseTokens = 20 // Base tokens for the result structure
+	FunctionSummaryBaseTokens   = 15 // Base tokens per summary (name, location, caller count)
+)
+
+// SummarizeFunctionParams encapsulates summarize_function parameters
+type SummarizeFunctionParams struct {
+	FunctionNames []string // Names in request order, without duplicates
+	MaxTokens     int      // Budget shared by all summaries
+}
+
+// GetMaxTokens implements QueryOptionsBuilder interface
+func (p *SummarizeFunctionParams) GetMaxTokens() int { return p.MaxTokens }
+
+// FunctionSummary is a terse card describing a function without its body or call graph
+type FunctionSummary struct {
+	Name        string   `json:"name"`
+	Receiver    string   `json:"receiver,omitempty"`
+	Signature   string   `json:"signature"`
+	File        string   `json:"file"`
+	Line        int      `json:"line"`
+	Purpose     string   `json:"purpose,omitempty"`    // First sentence of the doc comment
+	Parameters  []string `json:"parameters,omitempty"` // Each as "name type"
+	Returns     []string `json:"returns,omitempty"`
+	CallerCount int      `json:"caller_count"`
+}
+
+// SummarizeFunctionResult is the response of summarize_function
+type SummarizeFunctionResult struct {
+	Summaries  []FunctionSummary `json:"summaries"`
+	NotFound   []string          `json:"not_found,omitempty"`
+	Omitted    []string          `json:"omitted,omitempty"` // Names with summaries left out by max_tokens
+	TokenCount int               `json:"token_count"`
+	Truncated  bool              `json:"truncated"`
+}
+
+// createSummarizeFunctionTool creates the summarize_function tool
+func (s *RepoContextMCPServer) createSummarizeFunctionTool() mcp.Tool {
+	return mcp.NewTool("summarize_function",
+		mcp.WithDescription(
+			"Get a terse card per function: signature, first sentence of its doc, parameters, returns and caller count, "+
+				"without bodies or call graphs. Much cheaper than get_function_context for skimming many functions",
+		),
+		mcp.WithString("function_names", mcp.Required(), mcp.Description(
+			"Comma separated function names to summarize, e.g. 'NewServer,Server.Start'",
+		)),
+		mcp.WithNumber("max_tokens", mcp.Description(
+			"Maximum tokens shared by all summaries; summaries past the budget are listed as omitted (default: 2000)",
+		)),
+	)
+}
+
+// HandleSummarizeFunction summarizes one or more functions from the indexed data
+func (s *RepoContextMCPServer) HandleSummarizeFunction(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	ops := ToolOperations[*SummarizeFunctionParams, *SummarizeFunctionResult]{
+		ParseParams: s.parseSummarizeFunctionParameters,
+		BuildResult: s.buildSummarizeFunctionResult,
+		OptimizeResult: func(_ *SummarizeFunctionParams, result *SummarizeFunctionResult, maxTokens int) {
+			s.optimizeSummarizeFunctionResponse(result, maxTokens)
+		},
+		ToolName: "summarize_function",
+	}
+	return executeGenericToolHandler(s, request, ops)
+}
+
+// parseSummarizeFunctionParameters extracts and validates summarize_function parameters
+func (s *RepoContextMCPServer) parseSummarizeFunctionParameters(request mcp.CallToolRequest) (*SummarizeFunctionParams, error) {
+	var names []string
+	for _, name := range strings.Split(request.GetString("function_names", ""), ",") {
+		if name = strings.TrimSpace(name); name != "" && !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("function_names parameter is required")
+	}
+
+	maxTokens := request.GetInt("max_tokens", s.getMaxTokens())
+	if maxTokens <= 0 {
+		return nil, fmt.Errorf("max_tokens must be positive, got %d", maxTokens)
+	}
+
+	return &SummarizeFunctionParams{FunctionNames: names, MaxTokens: maxTokens}, nil
+}
+
+// buildSummarizeFunctionResult summarizes every function matching the requested names,
+// in request order and then by file and line, so the same request yields the same output
+func (s *RepoContextMCPServer) buildSummarizeFunctionResult(params *SummarizeFunctionParams) (*SummarizeFunctionResult, error) {
+	result := &SummarizeFunctionResult{Summaries: []FunctionSummary{}}
+	for _, name := range params.FunctionNames {
+		searchResult, err := s.QueryEngine.SearchByName(name)
+		if err != nil {
+			return nil, fmt.Errorf("function search failed for %s: %w", name, err)
+		}
+
+		var summaries []FunctionSummary
+		for i := range searchResult.Entries {
+			entry := &searchResult.Entries[i]
+			if entry.IndexEntry.Type == index.EntityTypeFunction && !s.isExcludedPath(entry.IndexEntry.File) {
+				summaries = append(summaries, s.summarizeFunction(entry))
+			}
+		}
+		if len(summaries) == 0 {
+			result.NotFound = append(result.NotFound, name)
+			continue
+		}
+		slices.SortStableFunc(summaries, func(a, b FunctionSummary) int {
+			return cmp.Or(strings.Compare(a.File, b.File), cmp.Compare(a.Line, b.Line))
+		})
+		result.Summaries = append(result.Summaries, summaries...)
+	}
+	return result, nil
+}
+
+// summarizeFunction builds the summary card of a function entry
+func (s *RepoContextMCPServer) summarizeFunction(entry *index.SearchResultEntry) FunctionSummary {
+	summary := FunctionSummary{
+		Name:      entry.IndexEntry.Name,
+		Signature: entry.IndexEntry.Signature,
+		File:      entry.IndexEntry.File,
+		Line:      entry.IndexEntry.StartLine,
+	}
+
+	function := s.findFunctionModel(entry)
+	if function == nil {
+		return summary
+	}
+	summary.Receiver = function.Receiver
+	summary.Purpose = docPurpose(function.Doc)
+	for _, parameter := range function.Parameters {
+		summary.Parameters = append(summary.Parameters, strings.TrimSpace(parameter.Name+" "+parameter.Type))
+	}
+	for _, result := range function.Returns {
+		summary.Returns = append(summary.Returns, result.Name)
+	}
+	summary.CallerCount = callerCount(function)
+	return summary
+}
+
+// callerCount counts the distinct functions calling a function, in the same file or not
+func callerCount(function *models.Function) int {
+	callers := make(map[string]bool)
+	for _, caller := range function.CalledBy {
+		callers[caller] = true
+	}
+	for _, caller := range function.LocalCallers {
+		callers[caller] = true
+	}
+	for _, caller := range function.CrossFileCallers {
+		callers[caller.File+":"+caller.FunctionName] = true
+	}
+	return len(callers)
+}
+
+// docPurpose returns the first sentence of a doc comment, on a single line
+func docPurpose(doc string) string {
+	paragraph, _, _ := strings.Cut(strings.TrimSpace(doc), "\n\n")
+	text := strings.Join(strings.Fields(paragraph), " ")
+	if end := strings.Index(text, ". "); end >= 0 {
+		return text[:end+1]
+	}
+	return text
+}
+
+// optimizeSummarizeFunctionResponse keeps the leading summaries that fit within maxTokens
+// and lists the names of the others as omitted
+func (s *RepoContextMCPServer) optimizeSummarizeFunctionResponse(result *SummarizeFunctionResult, maxTokens int) {
+	tokens := SummarizeFunctionBaseTokens
+	for i := range result.Summaries {
+		summaryTokens := s.estimateFunctionSummaryTokens(&result.Summaries[i])
+		if tokens+summaryTokens > maxTokens {
+			for _, omitted := range result.Summaries[i:] {
+				if !slices.Contains(result.Omitted, omitted.Name) {
+					result.Omitted = append(result.Omitted, omitted.Name)
+				}
+			}
+			result.Summaries = result.Summaries[:i]
+			result.Truncated = true
+			break
+		}
+		tokens += summaryTokens
+	}
+	result.TokenCount = tokens
+}
+
+// estimateFunctionSummaryTokens estimates the token count of a function summary
+func (s *RepoContextMCPServer) estimateFunctionSummaryTokens(summary *FunctionSummary) int {
+	text := []string{summary.Name, summary.Receiver, summary.Signature, summary.File, summary.Purpose}
+	text = append(text, summary.Parameters...)
+	text = append(text, summary.Returns...)
+	return FunctionSummaryBaseTokens + s.estimateTextTokens(strings.Join(text, " "))
+}
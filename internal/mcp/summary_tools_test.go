@@ -0,0 +1,131 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"repository-context-protocol/internal/index"
+	"repository-context-protocol/internal/models"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newSummaryTestServer(t *testing.T) *RepoContextMCPServer {
+	tempDir := t.TempDir()
+	storage := index.NewHybridStorage(filepath.Join(tempDir, ".repocontext"))
+	require.NoError(t, storage.Initialize(), "Failed to initialize storage")
+	t.Cleanup(func() { storage.Close() })
+
+	fileContexts := []*models.FileContext{
+		{
+			Path: "server/server.go", Language: "go",
+			Functions: []models.Function{
+				{
+					Name:         "NewServer",
+					Signature:    "func NewServer(addr string, timeout time.Duration) *Server",
+					StartLine:    10,
+					EndLine:      20,
+					Doc:          "NewServer creates a server listening on addr. The server is not started.\n\nSee Start.",
+					Parameters:   []models.Parameter{{Name: "addr", Type: "string"}, {Name: "timeout", Type: "time.Duration"}},
+					Returns:      []models.Type{{Name: "*Server"}},
+					LocalCallers: []string{"main", "newTestServer"},
+					// Callers are counted once however many times they call
+					CrossFileCallers: []models.CallReference{
+						{FunctionName: "main", File: "cmd/app/main.go"},
+						{FunctionName: "main", File: "cmd/app/main.go"},
+					},
+				},
+				{Name: "Start", Receiver: "Server", Signature: "func (s *Server) Start() error", StartLine: 25, EndLine: 30,
+					Doc: "Start serves requests until the server is closed", Returns: []models.Type{{Name: "error"}}},
+			},
+		},
+		{
+			Path: "client/client.go", Language: "go",
+			Functions: []models.Function{{Name: "Start", Signature: "func Start()", StartLine: 3, EndLine: 5}},
+		},
+	}
+	for _, fileContext := range fileContexts {
+		require.NoError(t, storage.StoreFileContext(fileContext), "Failed to store file context")
+	}
+
+	return &RepoContextMCPServer{QueryEngine: index.NewQueryEngine(storage), Storage: storage, RepoPath: tempDir}
+}
+
+func TestSummarizeFunction_BuildsCards(t *testing.T) {
+	server := newSummaryTestServer(t)
+
+	result, err := server.buildSummarizeFunctionResult(&SummarizeFunctionParams{
+		FunctionNames: []string{"NewServer", "Start", "Missing"},
+		MaxTokens:     constMaxTokens,
+	})
+	require.NoError(t, err)
+
+	require.Len(t, result.Summaries, 3)
+	assert.Equal(t, FunctionSummary{
+		Name:        "NewServer",
+		Signature:   "func NewServer(addr string, timeout time.Duration) *Server",
+		File:        "server/server.go",
+		Line:        10,
+		Purpose:     "NewServer creates a server listening on addr.",
+		Parameters:  []string{"addr string", "timeout time.Duration"},
+		Returns:     []string{"*Server"},
+		CallerCount: 3,
+	}, result.Summaries[0])
+
+	// Functions sharing a name follow in file and line order
+	assert.Equal(t, "client/client.go", result.Summaries[1].File)
+	assert.Equal(t, "server/server.go", result.Summaries[2].File)
+	assert.Equal(t, "Server", result.Summaries[2].Receiver)
+	assert.Equal(t, "Start serves requests until the server is closed", result.Summaries[2].Purpose)
+	assert.Equal(t, []string{"Missing"}, result.NotFound)
+}
+
+func TestSummarizeFunction_SharedTokenBudget(t *testing.T) {
+	server := newSummaryTestServer(t)
+
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]interface{}{"function_names": "NewServer, Start,NewServer", "max_tokens": 80}
+	result, err := server.HandleSummarizeFunction(context.Background(), request)
+	require.NoError(t, err)
+	require.False(t, result.IsError, "Expected summarize_function to succeed, got %+v", result)
+
+	var summaries SummarizeFunctionResult
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &summaries))
+	assert.True(t, summaries.Truncated)
+	assert.LessOrEqual(t, summaries.TokenCount, 80)
+	require.NotEmpty(t, summaries.Summaries)
+	assert.Equal(t, "NewServer", summaries.Summaries[0].Name)
+	assert.Equal(t, []string{"Start"}, summaries.Omitted)
+
+	// The same request yields the same output
+	again, err := server.HandleSummarizeFunction(context.Background(), request)
+	require.NoError(t, err)
+	assert.Equal(t, result.Content[0].(mcp.TextContent).Text, again.Content[0].(mcp.TextContent).Text)
+
+	for _, arguments := range []map[string]interface{}{
+		{"function_names": " , "},
+		{"function_names": "Start", "max_tokens": 0},
+	} {
+		request.Params.Arguments = arguments
+		result, err = server.HandleSummarizeFunction(context.Background(), request)
+		require.NoError(t, err)
+		assert.True(t, result.IsError, "Expected a validation error for %v", arguments)
+	}
+}
+
+func TestDocPurpose(t *testing.T) {
+	tests := map[string]string{
+		"":                                 "",
+		"Parse reads a file.":              "Parse reads a file.",
+		"Parse reads\na file. It fails.":   "Parse reads a file.",
+		"Returns the user\n\nDetails here": "Returns the user",
+		"Uses v1.2 of the API":             "Uses v1.2 of the API",
+	}
+	for doc, expected := range tests {
+		assert.Equal(t, expected, docPurpose(doc), "doc %q", doc)
+	}
+}
@@ -1,23 +1,40 @@
 package mcp
 
 import (
+	"cmp"
 	"context"
 	"encoding/json"
 	"fmt"
 	"log"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 	"time"
 
 	"repository-context-protocol/internal/index"
+	"repository-context-protocol/internal/models"
 
 	"github.com/mark3labs/mcp-go/mcp"
 )
 
 const (
-	constDuration50ms      = 50 // Milliseconds per entity for build duration estimation
 	ConstFilePermission600 = 0600
 	constFilePermission755 = 0755
+	constAutocompleteLimit = 20 // Default number of completions returned by autocomplete
+	constHotspotLimit      = 10 // Default number of functions returned by get_hotspots
+
+	languageParamDescription     = "Only return entities defined in files of this language, e.g. go, python, java, c, cpp"
+	scopeParamDescription        = "Only return variables and constants declared in this scope: package, file or function"
+	exportedOnlyParamDescription = "Only return exported symbols, the public API of each package or module (default: false)"
+	changedOnlyParamDescription  = "Only return entities defined in files changed since the git ref given by since, " +
+		"e.g. to focus on a diff under review (default: false)"
+	sinceParamDescription = "Git ref changed_only compares the working tree against (default: HEAD)"
+	defaultChangedSince   = "HEAD"
+
+	listSortByName       = "name"
+	listSortByComplexity = "by_complexity"
+	listSortByRecency    = "by_recency"
 )
 
 // RegisterAdvancedQueryTools registers enhanced query tools with advanced features
@@ -28,6 +45,20 @@ func (s *RepoContextMCPServer) RegisterAdvancedQueryTools() []mcp.Tool {
 		s.createGetCallGraphTool(),
 		s.createListFunctionsTool(),
 		s.createListTypesTool(),
+		s.createAutocompleteTool(),
+		s.createQueryByDecoratorTool(),
+		s.createAnalyzeImportsTool(),
+		s.createListFilesTool(),
+		s.createGetHotspotsTool(),
+		s.createSearchContentTool(),
+		s.createFindCallPathTool(),
+		s.createGetTestCoverageTool(),
+		s.createGetOverviewTool(),
+		s.createFindDeadCodeTool(),
+		s.createSymbolAtLocationTool(),
+		s.createBatchQueryTool(),
+		s.createFindRecursionTool(),
+		s.createResolveImportTool(),
 	}
 }
 
@@ -35,7 +66,19 @@ func (s *RepoContextMCPServer) RegisterAdvancedQueryTools() []mcp.Tool {
 func (s *RepoContextMCPServer) createQueryByNameTool() mcp.Tool {
 	return mcp.NewTool("query_by_name",
 		mcp.WithDescription("Search for functions, types, or variables by exact name with advanced options"),
-		mcp.WithString("name", mcp.Required(), mcp.Description("Name to search for")),
+		mcp.WithString("name", mcp.Required(), mcp.Description(
+			"Name to search for; qualified names such as User.Activate or users.New narrow the match to a type or package",
+		)),
+		mcp.WithBoolean("case_insensitive", mcp.Description("Match the name ignoring case, e.g. ParseURL finds ParseUrl (default: false)")),
+		mcp.WithBoolean("word_match", mcp.Description(
+			"Match whole words of camelCase, snake_case and kebab-case names, e.g. user finds GetUserByID "+
+				"and user_service but not housuser (default: false)",
+		)),
+		mcp.WithString("language", mcp.Description(languageParamDescription)),
+		mcp.WithString("scope", mcp.Description(scopeParamDescription)),
+		mcp.WithBoolean("exported_only", mcp.Description(exportedOnlyParamDescription)),
+		mcp.WithBoolean("changed_only", mcp.Description(changedOnlyParamDescription)),
+		mcp.WithString("since", mcp.Description(sinceParamDescription)),
 		mcp.WithBoolean("include_callers", mcp.Description("Include functions that call this function")),
 		mcp.WithBoolean("include_callees", mcp.Description("Include functions called by this function")),
 		mcp.WithBoolean("include_types", mcp.Description("Include related type definitions")),
@@ -52,7 +95,20 @@ func (s *RepoContextMCPServer) createQueryByPatternTool() mcp.Tool {
 				"This tool is useful for searching for entities in the repository.",
 		),
 		mcp.WithString("pattern", mcp.Required(), mcp.Description("Search pattern (supports glob and regex patterns)")),
-		mcp.WithString("entity_type", mcp.Description("Filter by entity type: function, type, variable, constant")),
+		mcp.WithString("entity_type", mcp.Description(
+			"Filter by entity type: function, type (any struct, interface, class, alias or enum), variable, constant. "+
+				"Synonyms such as func, method, class, struct, const and var are accepted",
+		)),
+		mcp.WithBoolean("case_insensitive", mcp.Description("Match the pattern ignoring case (default: false)")),
+		mcp.WithBoolean("word_match", mcp.Description(
+			"Match the pattern against each word of camelCase, snake_case and kebab-case names ignoring case, "+
+				"e.g. user* finds GetUsersByID (default: false)",
+		)),
+		mcp.WithString("language", mcp.Description(languageParamDescription)),
+		mcp.WithString("scope", mcp.Description(scopeParamDescription)),
+		mcp.WithBoolean("exported_only", mcp.Description(exportedOnlyParamDescription)),
+		mcp.WithBoolean("changed_only", mcp.Description(changedOnlyParamDescription)),
+		mcp.WithString("since", mcp.Description(sinceParamDescription)),
 		mcp.WithBoolean("include_callers", mcp.Description("Include functions that call matched functions")),
 		mcp.WithBoolean("include_callees", mcp.Description("Include functions called by matched functions")),
 		mcp.WithBoolean("include_types", mcp.Description("Include related type definitions")),
@@ -66,6 +122,8 @@ func (s *RepoContextMCPServer) createGetCallGraphTool() mcp.Tool {
 		mcp.WithDescription("Get detailed call graph for a function with configurable depth and selective inclusion"),
 		mcp.WithString("function_name", mcp.Required(), mcp.Description("Function name to analyze")),
 		mcp.WithNumber("max_depth", mcp.Description("Maximum traversal depth (default: 2)")),
+		mcp.WithNumber("caller_depth", mcp.Description("Caller traversal depth (default: max_depth)")),
+		mcp.WithNumber("callee_depth", mcp.Description("Callee traversal depth (default: max_depth)")),
 		mcp.WithBoolean("include_callers", mcp.Description("Include functions that call this function")),
 		mcp.WithBoolean("include_callees", mcp.Description("Include functions called by this function")),
 		mcp.WithNumber("max_tokens", mcp.Description("Maximum tokens for response (default: 2000)")),
@@ -79,7 +137,18 @@ func (s *RepoContextMCPServer) createListFunctionsTool() mcp.Tool {
 		mcp.WithNumber("max_tokens", mcp.Description("Maximum tokens for response (default: 2000)")),
 		mcp.WithBoolean("include_signatures", mcp.Description("Include function signatures in the response (default: true)")),
 		mcp.WithNumber("limit", mcp.Description("Maximum number of functions to return (0 for no limit)")),
-		mcp.WithNumber("offset", mcp.Description("Number of functions to skip (for pagination)")),
+		mcp.WithNumber("offset", mcp.Description("Number of functions to skip; pass next_offset from the previous page to continue")),
+		mcp.WithString("include_pattern", mcp.Description("Only list functions whose names match this glob or regex pattern")),
+		mcp.WithString("exclude_pattern", mcp.Description("Skip functions whose names match this glob or regex pattern (e.g. 'Test*')")),
+		mcp.WithString("sort", mcp.Description(
+			"Order of the list: name (default); by_complexity, most complex first to triage refactoring, "+
+				"complexity being cyclomatic complexity as computed for Go and Python functions; or by_recency, "+
+				"most recently changed first, for indexes built with git_blame",
+		)),
+		mcp.WithString("language", mcp.Description(languageParamDescription)),
+		mcp.WithBoolean("exported_only", mcp.Description(exportedOnlyParamDescription)),
+		mcp.WithBoolean("changed_only", mcp.Description(changedOnlyParamDescription)),
+		mcp.WithString("since", mcp.Description(sinceParamDescription)),
 	)
 }
 
@@ -90,7 +159,176 @@ func (s *RepoContextMCPServer) createListTypesTool() mcp.Tool {
 		mcp.WithNumber("max_tokens", mcp.Description("Maximum tokens for response (default: 2000)")),
 		mcp.WithBoolean("include_signatures", mcp.Description("Include type signatures in the response (default: true)")),
 		mcp.WithNumber("limit", mcp.Description("Maximum number of types to return (0 for no limit)")),
-		mcp.WithNumber("offset", mcp.Description("Number of types to skip (for pagination)")),
+		mcp.WithNumber("offset", mcp.Description("Number of types to skip; pass next_offset from the previous page to continue")),
+		mcp.WithString("include_pattern", mcp.Description("Only list types whose names match this glob or regex pattern")),
+		mcp.WithString("exclude_pattern", mcp.Description("Skip types whose names match this glob or regex pattern (e.g. 'Test*')")),
+		mcp.WithString("language", mcp.Description(languageParamDescription)),
+		mcp.WithBoolean("exported_only", mcp.Description(exportedOnlyParamDescription)),
+		mcp.WithBoolean("changed_only", mcp.Description(changedOnlyParamDescription)),
+		mcp.WithString("since", mcp.Description(sinceParamDescription)),
+	)
+}
+
+// createAutocompleteTool creates the autocomplete tool for symbol name prefix completion
+func (s *RepoContextMCPServer) createAutocompleteTool() mcp.Tool {
+	return mcp.NewTool("autocomplete",
+		mcp.WithDescription("Complete a symbol name prefix, returning matching names with their entity kind"),
+		mcp.WithString("prefix", mcp.Required(), mcp.Description("Case-sensitive name prefix to complete")),
+		mcp.WithNumber("limit", mcp.Description(fmt.Sprintf("Maximum number of completions to return (default: %d)", constAutocompleteLimit))),
+	)
+}
+
+// createQueryByDecoratorTool creates the query_by_decorator tool for decorator and annotation searches
+func (s *RepoContextMCPServer) createQueryByDecoratorTool() mcp.Tool {
+	return mcp.NewTool("query_by_decorator",
+		mcp.WithDescription("Find functions and types carrying a decorator or annotation, e.g. @app.route or @Entity"),
+		mcp.WithString("decorator", mcp.Required(), mcp.Description(
+			"Decorator to match; '@app.route' also matches '@app.route(\"/users\")' and the '@' is optional",
+		)),
+		mcp.WithString("language", mcp.Description(languageParamDescription)),
+		mcp.WithNumber("max_tokens", mcp.Description("Maximum tokens for response (default: 2000)")),
+	)
+}
+
+// createAnalyzeImportsTool creates the analyze_imports tool for dependency listing
+func (s *RepoContextMCPServer) createAnalyzeImportsTool() mcp.Tool {
+	return mcp.NewTool("analyze_imports",
+		mcp.WithDescription(
+			"List imported packages and modules with usage counts and importing files, "+
+				"grouped into stdlib, third_party, internal and relative imports where the language allows",
+		),
+		mcp.WithString("file_path", mcp.Description("Only analyze this file or the files under this directory")),
+	)
+}
+
+// createListFilesTool creates the list_files tool for checking index coverage
+func (s *RepoContextMCPServer) createListFilesTool() mcp.Tool {
+	return mcp.NewTool("list_files",
+		mcp.WithDescription("List indexed files with language, checksum, modification time and entity counts"),
+		mcp.WithString("language", mcp.Description("Only list files in this language (e.g. go, python)")),
+		mcp.WithString("sort_by", mcp.Description("Sort order: 'path' (default) or 'entities' (most entities first)")),
+	)
+}
+
+// createGetHotspotsTool creates the get_hotspots tool ranking functions by call count
+func (s *RepoContextMCPServer) createGetHotspotsTool() mcp.Tool {
+	return mcp.NewTool("get_hotspots",
+		mcp.WithDescription("List the most called functions, or those calling the most functions, to find the center of a codebase"),
+		mcp.WithNumber("limit", mcp.Description(fmt.Sprintf("Maximum number of functions to return (default: %d)", constHotspotLimit))),
+		mcp.WithString("rank_by", mcp.Description(
+			"Ranking: 'callers' (default, most called first) or 'callees' (highest fan-out first)",
+		)),
+	)
+}
+
+// createSearchContentTool creates the search_content tool for line-oriented text search
+func (s *RepoContextMCPServer) createSearchContentTool() mcp.Tool {
+	return mcp.NewTool("search_content",
+		mcp.WithDescription(
+			"Search the text of indexed files line by line with a regex, returning file:line:snippet matches. "+
+				"Finds string literals, comments and config values that symbol searches miss",
+		),
+		mcp.WithString("pattern", mcp.Required(), mcp.Description("Regular expression matched against each line, e.g. 'connection refused'")),
+		mcp.WithBoolean("case_insensitive", mcp.Description("Match ignoring case (default: false)")),
+		mcp.WithString("file_path", mcp.Description("Only search this file or the files under this directory")),
+		mcp.WithNumber("max_matches", mcp.Description(fmt.Sprintf("Maximum number of matching lines (default: %d)", index.DefaultContentMaxMatches))),
+		mcp.WithNumber("max_tokens", mcp.Description("Maximum tokens for response (default: 2000)")),
+	)
+}
+
+// createFindCallPathTool creates the find_call_path tool connecting two functions through calls
+func (s *RepoContextMCPServer) createFindCallPathTool() mcp.Tool {
+	return mcp.NewTool("find_call_path",
+		mcp.WithDescription("Find the chains of calls leading from one function to another, shortest first"),
+		mcp.WithString("from_function", mcp.Required(), mcp.Description("Function the call chains start at")),
+		mcp.WithString("to_function", mcp.Required(), mcp.Description("Function the call chains end at")),
+		mcp.WithNumber("max_depth", mcp.Description(fmt.Sprintf("Maximum number of calls in a chain (default: %d)", index.DefaultCallPathDepth))),
+	)
+}
+
+// createGetTestCoverageTool creates the get_test_coverage tool
+func (s *RepoContextMCPServer) createGetTestCoverageTool() mcp.Tool {
+	return mcp.NewTool("get_test_coverage",
+		mcp.WithDescription(
+			"Estimate which public functions have no test reaching them through the call graph. "+
+				"A heuristic based on call names, not measured coverage",
+		),
+		mcp.WithNumber("max_depth", mcp.Description(fmt.Sprintf(
+			"Maximum number of calls from a test to a covered function (default: %d)", index.DefaultTestCoverageDepth,
+		))),
+		mcp.WithBoolean("include_covered", mcp.Description("Also list the covered functions with the tests reaching them (default: false)")),
+	)
+}
+
+// createGetOverviewTool creates the get_overview tool summarizing the repository
+func (s *RepoContextMCPServer) createGetOverviewTool() mcp.Tool {
+	return mcp.NewTool("get_overview",
+		mcp.WithDescription(
+			"Summarize the repository in one call: languages, entity counts, entry points, public API, "+
+				"most called functions and largest packages. Use it first to orient before exploring",
+		),
+		mcp.WithNumber("max_tokens", mcp.Description(fmt.Sprintf(
+			"Maximum tokens for the summary; entry points and the public API are kept first (default: %d)", index.DefaultOverviewMaxTokens,
+		))),
+		mcp.WithNumber("limit", mcp.Description(fmt.Sprintf(
+			"Maximum number of items in each list of the summary (default: %d)", index.DefaultOverviewSectionLimit,
+		))),
+	)
+}
+
+// createFindDeadCodeTool creates the find_dead_code tool
+func (s *RepoContextMCPServer) createFindDeadCodeTool() mcp.Tool {
+	return mcp.NewTool("find_dead_code",
+		mcp.WithDescription(
+			"List exported functions and types that nothing in the repository calls or mentions, as candidates for removal. "+
+				"A heuristic: callers outside the repository and reflection are not seen, so review each candidate",
+		),
+		mcp.WithString("exclude", mcp.Description(
+			"Comma separated files, directories or globs whose symbols are not candidates, "+
+				"e.g. public API packages such as 'api,pkg/client/*.go'",
+		)),
+		mcp.WithNumber("limit", mcp.Description("Maximum number of candidates to return (default: all)")),
+	)
+}
+
+// createFindRecursionTool creates the find_recursion tool
+func (s *RepoContextMCPServer) createFindRecursionTool() mcp.Tool {
+	return mcp.NewTool("find_recursion",
+		mcp.WithDescription(
+			"List functions that call themselves and groups of functions that call each other in a cycle, "+
+				"e.g. to review stack depth or performance of recursive code",
+		),
+		mcp.WithNumber("limit", mcp.Description("Maximum number of functions and of groups to return (default: all)")),
+	)
+}
+
+// createResolveImportTool creates the resolve_import tool
+func (s *RepoContextMCPServer) createResolveImportTool() mcp.Tool {
+	return mcp.NewTool("resolve_import",
+		mcp.WithDescription(
+			"Find where an imported symbol is defined, e.g. User in 'from .models import User' or in a Go package path, "+
+				"to navigate from an import to the definition",
+		),
+		mcp.WithString("import_path", mcp.Required(), mcp.Description(
+			"Module or package imported, e.g. 'app.models', '.models' or 'github.com/org/repo/internal/models'; "+
+				"without symbol its last segment is the symbol, e.g. 'app.models.User'",
+		)),
+		mcp.WithString("symbol", mcp.Description("Imported name to resolve, e.g. 'User'")),
+		mcp.WithString("from_file", mcp.Description(
+			"File containing the import, relative to the repository root; required for relative imports such as '.models'",
+		)),
+	)
+}
+
+// createSymbolAtLocationTool creates the symbol_at_location tool
+func (s *RepoContextMCPServer) createSymbolAtLocationTool() mcp.Tool {
+	return mcp.NewTool("symbol_at_location",
+		mcp.WithDescription(
+			"Find the innermost function, method or type containing a line of a file, "+
+				"e.g. to interpret a stack trace frame or an editor cursor position",
+		),
+		mcp.WithString("file_path", mcp.Required(), mcp.Description("File containing the line, relative to the repository root or absolute")),
+		mcp.WithNumber("line", mcp.Required(), mcp.Description("Line number, starting at 1")),
 	)
 }
 
@@ -103,12 +341,24 @@ func (s *RepoContextMCPServer) parseQueryByNameParameters(request mcp.CallToolRe
 		return nil, fmt.Errorf("name parameter is required")
 	}
 
+	scope, err := parseScopeParameter(request)
+	if err != nil {
+		return nil, err
+	}
+
 	return &QueryByNameParams{
-		Name:           name,
-		IncludeCallers: request.GetBool("include_callers", false),
-		IncludeCallees: request.GetBool("include_callees", false),
-		IncludeTypes:   request.GetBool("include_types", false),
-		MaxTokens:      request.GetInt("max_tokens", constMaxTokens),
+		Name:            name,
+		IncludeCallers:  request.GetBool("include_callers", false),
+		IncludeCallees:  request.GetBool("include_callees", false),
+		IncludeTypes:    request.GetBool("include_types", false),
+		MaxTokens:       request.GetInt("max_tokens", s.getMaxTokens()),
+		CaseInsensitive: request.GetBool("case_insensitive", false),
+		WordMatch:       request.GetBool("word_match", false),
+		Language:        strings.TrimSpace(request.GetString("language", "")),
+		Scope:           scope,
+		ExportedOnly:    request.GetBool("exported_only", false),
+		ChangedOnly:     request.GetBool("changed_only", false),
+		Since:           strings.TrimSpace(request.GetString("since", defaultChangedSince)),
 	}, nil
 }
 
@@ -119,21 +369,42 @@ func (s *RepoContextMCPServer) parseQueryByPatternParameters(request mcp.CallToo
 		return nil, fmt.Errorf("pattern parameter is required")
 	}
 
-	entityType := request.GetString("entity_type", "")
+	entityType := s.normalizeEntityType(request.GetString("entity_type", ""))
 	if err := s.validateEntityType(entityType); err != nil {
 		return nil, err
 	}
 
+	scope, err := parseScopeParameter(request)
+	if err != nil {
+		return nil, err
+	}
+
 	return &QueryByPatternParams{
-		Pattern:        pattern,
-		EntityType:     entityType,
-		IncludeCallers: request.GetBool("include_callers", false),
-		IncludeCallees: request.GetBool("include_callees", false),
-		IncludeTypes:   request.GetBool("include_types", false),
-		MaxTokens:      request.GetInt("max_tokens", constMaxTokens),
+		Pattern:         pattern,
+		EntityType:      entityType,
+		IncludeCallers:  request.GetBool("include_callers", false),
+		IncludeCallees:  request.GetBool("include_callees", false),
+		IncludeTypes:    request.GetBool("include_types", false),
+		MaxTokens:       request.GetInt("max_tokens", s.getMaxTokens()),
+		CaseInsensitive: request.GetBool("case_insensitive", false),
+		WordMatch:       request.GetBool("word_match", false),
+		Language:        strings.TrimSpace(request.GetString("language", "")),
+		Scope:           scope,
+		ExportedOnly:    request.GetBool("exported_only", false),
+		ChangedOnly:     request.GetBool("changed_only", false),
+		Since:           strings.TrimSpace(request.GetString("since", defaultChangedSince)),
 	}, nil
 }
 
+// parseScopeParameter reads the optional scope parameter, rejecting unknown scopes
+func parseScopeParameter(request mcp.CallToolRequest) (string, error) {
+	scope := strings.TrimSpace(request.GetString("scope", ""))
+	if scope != "" && !slices.Contains(models.Scopes(), scope) {
+		return "", fmt.Errorf("invalid scope '%s', must be one of: %s", scope, strings.Join(models.Scopes(), ", "))
+	}
+	return scope, nil
+}
+
 // parseGetCallGraphParameters extracts and validates parameters for get_call_graph with enhanced handling
 func (s *RepoContextMCPServer) parseGetCallGraphParameters(request mcp.CallToolRequest) (*GetCallGraphParams, error) {
 	functionName := request.GetString("function_name", "")
@@ -143,10 +414,12 @@ func (s *RepoContextMCPServer) parseGetCallGraphParameters(request mcp.CallToolR
 
 	return &GetCallGraphParams{
 		FunctionName:   functionName,
-		MaxDepth:       request.GetInt("max_depth", constMaxDepth),
+		MaxDepth:       request.GetInt("max_depth", s.getMaxDepth()),
+		CallerDepth:    request.GetInt("caller_depth", 0),
+		CalleeDepth:    request.GetInt("callee_depth", 0),
 		IncludeCallers: request.GetBool("include_callers", false),
 		IncludeCallees: request.GetBool("include_callees", false),
-		MaxTokens:      request.GetInt("max_tokens", constMaxTokens),
+		MaxTokens:      request.GetInt("max_tokens", s.getMaxTokens()),
 	}, nil
 }
 
@@ -163,6 +436,21 @@ func (s *RepoContextMCPServer) buildQueryOptionsFromParams(params QueryOptionsBu
 	}
 }
 
+// applyChangedOnly restricts queryOptions to the files of the repository changed since
+// the git ref when changedOnly is set
+func (s *RepoContextMCPServer) applyChangedOnly(queryOptions *index.QueryOptions, changedOnly bool, since string) error {
+	if !changedOnly {
+		return nil
+	}
+	files, err := index.ChangedFiles(s.RepoPath, since)
+	if err != nil {
+		return err
+	}
+	queryOptions.ChangedOnly = true
+	queryOptions.ChangedFiles = index.RepoRelativePaths(s.RepoPath, files)
+	return nil
+}
+
 // Advanced Tool Handlers with Enhanced Error Handling
 
 // HandleAdvancedQueryByName provides enhanced query_by_name with better parameter handling
@@ -187,12 +475,23 @@ func (s *RepoContextMCPServer) HandleAdvancedQueryByName(ctx context.Context, re
 
 		// Query options integration
 		queryOptions := s.buildQueryOptionsFromParams(params)
+		queryOptions.CaseInsensitive = params.CaseInsensitive
+		queryOptions.WordMatch = params.WordMatch
+		queryOptions.Language = params.Language
+		queryOptions.Scope = params.Scope
+		queryOptions.ExportedOnly = params.ExportedOnly
+		if err := s.applyChangedOnly(&queryOptions, params.ChangedOnly, params.Since); err != nil {
+			return s.FormatErrorResponse("query_by_name", err), nil
+		}
 
-		// Execute query with enhanced error handling
-		searchResult, err := s.QueryEngine.SearchByNameWithOptions(params.Name, queryOptions)
+		// Execute query with enhanced error handling, bounded by the query timeout
+		queryCtx, cancel := s.queryContext(ctx)
+		defer cancel()
+		searchResult, err := s.QueryEngine.SearchByNameWithContext(queryCtx, params.Name, queryOptions)
 		if err != nil {
 			return s.FormatErrorResponse("query_by_name", err), nil
 		}
+		s.applyExcludedPaths(searchResult)
 
 		// Response optimization
 		return s.FormatSuccessResponse(searchResult), nil
@@ -219,6 +518,14 @@ func (s *RepoContextMCPServer) HandleAdvancedQueryByPattern(ctx context.Context,
 
 	// Query options integration
 	queryOptions := s.buildQueryOptionsFromParams(params)
+	queryOptions.CaseInsensitive = params.CaseInsensitive
+	queryOptions.WordMatch = params.WordMatch
+	queryOptions.Language = params.Language
+	queryOptions.Scope = params.Scope
+	queryOptions.ExportedOnly = params.ExportedOnly
+	if err := s.applyChangedOnly(&queryOptions, params.ChangedOnly, params.Since); err != nil {
+		return s.FormatErrorResponse("query_by_pattern", err), nil
+	}
 
 	// Execute pattern search with filtering
 	searchResult, err := s.executePatternSearchWithFilter(
@@ -234,28 +541,55 @@ func (s *RepoContextMCPServer) HandleAdvancedQueryByPattern(ctx context.Context,
 	return s.FormatSuccessResponse(searchResult), nil
 }
 
-// executePatternSearchWithFilter executes pattern search with optional entity type filtering
+// executePatternSearchWithFilter executes pattern search with optional entity type filtering.
+// The entity type restricts the kinds scanned rather than filtering the results afterwards.
 func (s *RepoContextMCPServer) executePatternSearchWithFilter(
 	pattern, entityType string,
 	queryOptions index.QueryOptions,
 ) (*index.SearchResult, error) {
+	if entityType != "" {
+		queryOptions.EntityTypes = []string{entityType}
+	}
+
 	searchResult, err := s.QueryEngine.SearchByPatternWithOptions(pattern, queryOptions)
 	if err != nil {
 		return nil, err
 	}
+	s.applyExcludedPaths(searchResult)
 
-	// Apply entity type filter if specified
-	if entityType != "" {
-		filteredEntries := make([]index.SearchResultEntry, 0)
-		for _, entry := range searchResult.Entries {
-			if entry.IndexEntry.Type == entityType {
-				filteredEntries = append(filteredEntries, entry)
-			}
+	return searchResult, nil
+}
+
+// validEntityTypes are the canonical entity_type values
+var validEntityTypes = []string{"function", "type", "variable", "constant"}
+
+// entityTypeAliases maps common synonyms to their canonical entity_type
+var entityTypeAliases = map[string]string{
+	"func":   "function",
+	"method": "function",
+	"class":  "type",
+	"struct": "type",
+	"const":  "constant",
+	"var":    "variable",
+}
+
+// normalizeEntityType maps an entity_type synonym, built-in or from the configuration, to its
+// canonical value. Unknown values are returned lowercased for validateEntityType to reject.
+func (s *RepoContextMCPServer) normalizeEntityType(entityType string) string {
+	normalized := strings.ToLower(strings.TrimSpace(entityType))
+
+	canonical, ok := entityTypeAliases[normalized]
+	if s.config != nil {
+		if configured, found := s.config.EntityTypeAliases[normalized]; found {
+			canonical, ok = configured, true
 		}
-		searchResult.Entries = filteredEntries
+	}
+	if !ok {
+		return normalized
 	}
 
-	return searchResult, nil
+	log.Printf("Normalized entity_type '%s' to '%s'", entityType, canonical)
+	return canonical
 }
 
 // validateEntityType validates the entity_type parameter
@@ -264,14 +598,11 @@ func (s *RepoContextMCPServer) validateEntityType(entityType string) error {
 		return nil // Empty is valid (no filter)
 	}
 
-	validEntityTypes := []string{"function", "type", "variable", "constant"}
-	for _, validType := range validEntityTypes {
-		if entityType == validType {
-			return nil
-		}
+	if slices.Contains(validEntityTypes, entityType) {
+		return nil
 	}
 
-	return fmt.Errorf("invalid entity_type '%s', must be one of: function, type, variable, constant", entityType)
+	return fmt.Errorf("invalid entity_type '%s', must be one of: %s", entityType, strings.Join(validEntityTypes, ", "))
 }
 
 // HandleAdvancedGetCallGraph provides enhanced get_call_graph with better parameter handling
@@ -295,12 +626,17 @@ func (s *RepoContextMCPServer) HandleAdvancedGetCallGraph(ctx context.Context, r
 	// Query options integration
 	queryOptions := s.buildQueryOptionsFromParams(params)
 	queryOptions.MaxDepth = params.MaxDepth
+	queryOptions.CallerDepth = params.CallerDepth
+	queryOptions.CalleeDepth = params.CalleeDepth
 
-	// Execute call graph query with enhanced error handling
-	callGraphResult, err := s.QueryEngine.GetCallGraphWithOptions(params.FunctionName, queryOptions)
+	// Execute call graph query with enhanced error handling, bounded by the query timeout
+	queryCtx, cancel := s.queryContext(ctx)
+	defer cancel()
+	callGraphResult, err := s.QueryEngine.GetCallGraphWithContext(queryCtx, params.FunctionName, queryOptions)
 	if err != nil {
 		return s.FormatErrorResponse("get_call_graph", err), nil
 	}
+	s.applyExcludedCallGraphPaths(callGraphResult)
 
 	// Response optimization
 	return s.FormatSuccessResponse(callGraphResult), nil
@@ -320,9 +656,15 @@ func (s *RepoContextMCPServer) HandleAdvancedListFunctions(ctx context.Context,
 
 	// Enhanced parameter parsing
 	params := s.parseListEntitiesParameters(request)
+	if params.Sort != listSortByName && params.Sort != listSortByComplexity && params.Sort != listSortByRecency {
+		return mcp.NewToolResultError(fmt.Sprintf(
+			"Parameter validation failed: invalid sort '%s', must be one of: %s, %s, %s",
+			params.Sort, listSortByName, listSortByComplexity, listSortByRecency,
+		)), nil
+	}
 
 	// Execute list functions with enhanced error handling
-	return s.executeListEntitiesWithParams("function", "list_functions", params)
+	return s.executeListEntitiesWithParams(index.EntityTypeFunction, "list_functions", params)
 }
 
 // HandleAdvancedListTypes provides enhanced list_types with better parameter handling
@@ -341,16 +683,611 @@ func (s *RepoContextMCPServer) HandleAdvancedListTypes(ctx context.Context, requ
 	params := s.parseListEntitiesParameters(request)
 
 	// Execute list types with enhanced error handling
-	return s.executeListEntitiesWithParams("type", "list_types", params)
+	return s.executeListEntitiesWithParams(index.EntityTypeType, "list_types", params)
+}
+
+// AutocompleteResult is the response of the autocomplete tool
+type AutocompleteResult struct {
+	Prefix      string                 `json:"prefix"`
+	Completions []index.NameCompletion `json:"completions"`
+}
+
+// HandleAutocomplete completes a symbol name prefix using the query engine's sorted name index
+func (s *RepoContextMCPServer) HandleAutocomplete(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// System-level validation
+	if s.QueryEngine == nil {
+		return nil, fmt.Errorf("query engine not initialized - system configuration error")
+	}
+
+	// Repository validation
+	if err := s.validateRepository(); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Repository validation failed: %v", err)), nil
+	}
+
+	prefix := request.GetString("prefix", "")
+	if prefix == "" {
+		return mcp.NewToolResultError("Parameter validation failed: prefix parameter is required"), nil
+	}
+	limit := request.GetInt("limit", constAutocompleteLimit)
+
+	completions, err := s.QueryEngine.CompleteNames(prefix, limit)
+	if err != nil {
+		return s.FormatErrorResponse("autocomplete", err), nil
+	}
+
+	return s.FormatSuccessResponse(&AutocompleteResult{Prefix: prefix, Completions: completions}), nil
+}
+
+// HandleQueryByDecorator finds functions and types by decorator or annotation
+func (s *RepoContextMCPServer) HandleQueryByDecorator(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// System-level validation
+	if s.QueryEngine == nil {
+		return nil, fmt.Errorf("query engine not initialized - system configuration error")
+	}
+
+	// Repository validation
+	if err := s.validateRepository(); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Repository validation failed: %v", err)), nil
+	}
+
+	decorator := strings.TrimSpace(request.GetString("decorator", ""))
+	if strings.TrimPrefix(decorator, "@") == "" {
+		return mcp.NewToolResultError("Parameter validation failed: decorator parameter is required"), nil
+	}
+
+	queryOptions := index.QueryOptions{
+		MaxTokens: request.GetInt("max_tokens", s.getMaxTokens()),
+		Format:    "json",
+		Language:  strings.TrimSpace(request.GetString("language", "")),
+	}
+
+	searchResult, err := s.QueryEngine.SearchByDecorator(decorator, queryOptions)
+	if err != nil {
+		return s.FormatErrorResponse("query_by_decorator", err), nil
+	}
+
+	return s.FormatSuccessResponse(searchResult), nil
+}
+
+// HandleAnalyzeImports lists the imports of indexed files with usage counts and categories
+func (s *RepoContextMCPServer) HandleAnalyzeImports(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// System-level validation
+	if s.QueryEngine == nil {
+		return nil, fmt.Errorf("query engine not initialized - system configuration error")
+	}
+
+	// Repository validation
+	if err := s.validateRepository(); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Repository validation failed: %v", err)), nil
+	}
+
+	filePath := s.repoRelativePath(strings.TrimSpace(request.GetString("file_path", "")))
+	analysis, err := s.QueryEngine.SearchImports(index.ImportQueryOptions{FilePath: filePath})
+	if err != nil {
+		return s.FormatErrorResponse("analyze_imports", err), nil
+	}
+
+	// Indexes built before paths were stored repo-relative hold them under the repository path
+	if len(analysis.Files) == 0 && filePath != "" && s.RepoPath != "" && !filepath.IsAbs(filePath) {
+		analysis, err = s.QueryEngine.SearchImports(index.ImportQueryOptions{FilePath: filepath.Join(s.RepoPath, filePath)})
+		if err != nil {
+			return s.FormatErrorResponse("analyze_imports", err), nil
+		}
+	}
+
+	return s.FormatSuccessResponse(analysis), nil
+}
+
+// List files sort order constants
+const (
+	FileSortPath     = "path"
+	FileSortEntities = "entities"
+)
+
+// ListFilesResult is the response of list_files
+type ListFilesResult struct {
+	Files      []index.FileInfo `json:"files"`
+	TotalFiles int              `json:"total_files"`
+	Languages  map[string]int   `json:"languages"` // Number of listed files per language
+}
+
+// HandleListFiles lists indexed files with their stored metadata and entity counts
+func (s *RepoContextMCPServer) HandleListFiles(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// System-level validation
+	if s.QueryEngine == nil {
+		return nil, fmt.Errorf("query engine not initialized - system configuration error")
+	}
+
+	// Repository validation
+	if err := s.validateRepository(); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Repository validation failed: %v", err)), nil
+	}
+
+	language := strings.ToLower(strings.TrimSpace(request.GetString("language", "")))
+	sortBy := request.GetString("sort_by", FileSortPath)
+	if sortBy != FileSortPath && sortBy != FileSortEntities {
+		return mcp.NewToolResultError(fmt.Sprintf(
+			"Parameter validation failed: invalid sort_by '%s', must be '%s' or '%s'", sortBy, FileSortPath, FileSortEntities,
+		)), nil
+	}
+
+	files, err := s.QueryEngine.ListIndexedFiles()
+	if err != nil {
+		return s.FormatErrorResponse("list_files", err), nil
+	}
+
+	result := &ListFilesResult{Files: []index.FileInfo{}, Languages: make(map[string]int)}
+	for _, file := range files {
+		if language != "" && file.Language != language {
+			continue
+		}
+		if s.isExcludedPath(file.Path) {
+			continue
+		}
+		result.Files = append(result.Files, file)
+		result.Languages[file.Language]++
+	}
+	result.TotalFiles = len(result.Files)
+
+	if sortBy == FileSortEntities {
+		slices.SortStableFunc(result.Files, func(a, b index.FileInfo) int {
+			return cmp.Compare(b.Entities, a.Entities)
+		})
+	}
+
+	return s.FormatSuccessResponse(result), nil
+}
+
+// Hotspot ranking constants
+const (
+	HotspotRankCallers = "callers"
+	HotspotRankCallees = "callees"
+)
+
+// HotspotsResult is the response of get_hotspots
+type HotspotsResult struct {
+	RankBy   string               `json:"rank_by"`
+	Hotspots []index.HotspotEntry `json:"hotspots"`
+}
+
+// HandleGetHotspots ranks functions by inbound or outbound call count
+func (s *RepoContextMCPServer) HandleGetHotspots(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// System-level validation
+	if s.QueryEngine == nil {
+		return nil, fmt.Errorf("query engine not initialized - system configuration error")
+	}
+
+	// Repository validation
+	if err := s.validateRepository(); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Repository validation failed: %v", err)), nil
+	}
+
+	rankBy := request.GetString("rank_by", HotspotRankCallers)
+	if rankBy != HotspotRankCallers && rankBy != HotspotRankCallees {
+		return mcp.NewToolResultError(fmt.Sprintf(
+			"Parameter validation failed: invalid rank_by '%s', must be '%s' or '%s'", rankBy, HotspotRankCallers, HotspotRankCallees,
+		)), nil
+	}
+	limit := request.GetInt("limit", constHotspotLimit)
+	if limit <= 0 {
+		return mcp.NewToolResultError(fmt.Sprintf("Parameter validation failed: limit must be positive, got %d", limit)), nil
+	}
+
+	var hotspots []index.HotspotEntry
+	var err error
+	if rankBy == HotspotRankCallees {
+		hotspots, err = s.QueryEngine.GetMostCallingFunctions(limit)
+	} else {
+		hotspots, err = s.QueryEngine.GetMostCalledFunctions(limit)
+	}
+	if err != nil {
+		return s.FormatErrorResponse("get_hotspots", err), nil
+	}
+
+	hotspots = slices.DeleteFunc(hotspots, func(hotspot index.HotspotEntry) bool {
+		return s.isExcludedPath(hotspot.File)
+	})
+	return s.FormatSuccessResponse(&HotspotsResult{RankBy: rankBy, Hotspots: hotspots}), nil
+}
+
+// HandleSearchContent searches the text of indexed files for a regex
+func (s *RepoContextMCPServer) HandleSearchContent(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// System-level validation
+	if s.QueryEngine == nil {
+		return nil, fmt.Errorf("query engine not initialized - system configuration error")
+	}
+
+	// Repository validation
+	if err := s.validateRepository(); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Repository validation failed: %v", err)), nil
+	}
+
+	pattern := request.GetString("pattern", "")
+	if pattern == "" {
+		return mcp.NewToolResultError("Parameter validation failed: pattern parameter is required"), nil
+	}
+	options := index.ContentSearchOptions{
+		CaseInsensitive: request.GetBool("case_insensitive", false),
+		MaxMatches:      request.GetInt("max_matches", index.DefaultContentMaxMatches),
+		MaxTokens:       request.GetInt("max_tokens", s.getMaxTokens()),
+		FilePath:        s.repoRelativePath(request.GetString("file_path", "")),
+	}
+	if options.MaxMatches <= 0 {
+		return mcp.NewToolResultError(fmt.Sprintf(
+			"Parameter validation failed: max_matches must be positive, got %d", options.MaxMatches,
+		)), nil
+	}
+
+	result, err := s.QueryEngine.SearchContent(pattern, options)
+	if err != nil {
+		return s.FormatErrorResponse("search_content", err), nil
+	}
+
+	result.Matches = slices.DeleteFunc(result.Matches, func(match index.ContentMatch) bool {
+		return s.isExcludedPath(match.File)
+	})
+	return s.FormatSuccessResponse(result), nil
+}
+
+// CallPathResult is the response of find_call_path
+type CallPathResult struct {
+	From     string     `json:"from"`
+	To       string     `json:"to"`
+	MaxDepth int        `json:"max_depth"`
+	Paths    [][]string `json:"paths"` // Function names in call order, shortest path first
+}
+
+// HandleFindCallPath finds call chains from one function to another
+func (s *RepoContextMCPServer) HandleFindCallPath(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// System-level validation
+	if s.QueryEngine == nil {
+		return nil, fmt.Errorf("query engine not initialized - system configuration error")
+	}
+
+	// Repository validation
+	if err := s.validateRepository(); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Repository validation failed: %v", err)), nil
+	}
+
+	from := strings.TrimSpace(request.GetString("from_function", ""))
+	to := strings.TrimSpace(request.GetString("to_function", ""))
+	if from == "" || to == "" {
+		return mcp.NewToolResultError("Parameter validation failed: from_function and to_function parameters are required"), nil
+	}
+	maxDepth := request.GetInt("max_depth", index.DefaultCallPathDepth)
+	if maxDepth <= 0 {
+		return mcp.NewToolResultError(fmt.Sprintf("Parameter validation failed: max_depth must be positive, got %d", maxDepth)), nil
+	}
+
+	paths, err := s.QueryEngine.FindCallPath(from, to, maxDepth)
+	if err != nil {
+		return s.FormatErrorResponse("find_call_path", err), nil
+	}
+
+	return s.FormatSuccessResponse(&CallPathResult{From: from, To: to, MaxDepth: maxDepth, Paths: paths}), nil
+}
+
+// TestCoverageResult is the response of get_test_coverage
+type TestCoverageResult struct {
+	Heuristic      string                   `json:"heuristic"`
+	MaxDepth       int                      `json:"max_depth"`
+	Tests          int                      `json:"tests"`
+	CoveredCount   int                      `json:"covered_count"`
+	UncoveredCount int                      `json:"uncovered_count"`
+	Uncovered      []index.FunctionCoverage `json:"uncovered"`
+	Covered        []index.FunctionCoverage `json:"covered,omitempty"` // Only with include_covered
+}
+
+// HandleGetTestCoverage reports the public functions no test reaches through the call graph
+func (s *RepoContextMCPServer) HandleGetTestCoverage(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// System-level validation
+	if s.QueryEngine == nil {
+		return nil, fmt.Errorf("query engine not initialized - system configuration error")
+	}
+
+	// Repository validation
+	if err := s.validateRepository(); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Repository validation failed: %v", err)), nil
+	}
+
+	maxDepth := request.GetInt("max_depth", index.DefaultTestCoverageDepth)
+	if maxDepth <= 0 {
+		return mcp.NewToolResultError(fmt.Sprintf("Parameter validation failed: max_depth must be positive, got %d", maxDepth)), nil
+	}
+
+	report, err := s.QueryEngine.GetTestCoverage(maxDepth)
+	if err != nil {
+		return s.FormatErrorResponse("get_test_coverage", err), nil
+	}
+
+	excluded := func(function index.FunctionCoverage) bool { return s.isExcludedPath(function.File) }
+	report.Covered = slices.DeleteFunc(report.Covered, excluded)
+	report.Uncovered = slices.DeleteFunc(report.Uncovered, excluded)
+
+	result := &TestCoverageResult{
+		Heuristic:      report.Heuristic,
+		MaxDepth:       report.MaxDepth,
+		Tests:          report.Tests,
+		CoveredCount:   len(report.Covered),
+		UncoveredCount: len(report.Uncovered),
+		Uncovered:      report.Uncovered,
+	}
+	if request.GetBool("include_covered", false) {
+		result.Covered = report.Covered
+	}
+	return s.FormatSuccessResponse(result), nil
+}
+
+// DeadCodeCandidate is an exported symbol find_dead_code found no reference to
+type DeadCodeCandidate struct {
+	Name      string `json:"name"`
+	Kind      string `json:"kind"`
+	File      string `json:"file"`
+	Line      int    `json:"line"`
+	Signature string `json:"signature,omitempty"`
+}
+
+// FindDeadCodeResult is the response of find_dead_code
+type FindDeadCodeResult struct {
+	Heuristic  string              `json:"heuristic"`
+	Count      int                 `json:"count"` // Candidates found, before the limit
+	Candidates []DeadCodeCandidate `json:"candidates"`
+	Truncated  bool                `json:"truncated,omitempty"`
+}
+
+// HandleFindDeadCode lists the exported functions and types nothing in the index references
+func (s *RepoContextMCPServer) HandleFindDeadCode(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// System-level validation
+	if s.QueryEngine == nil {
+		return nil, fmt.Errorf("query engine not initialized - system configuration error")
+	}
+
+	// Repository validation
+	if err := s.validateRepository(); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Repository validation failed: %v", err)), nil
+	}
+
+	limit := request.GetInt("limit", 0)
+	if limit < 0 {
+		return mcp.NewToolResultError(fmt.Sprintf("Parameter validation failed: limit must not be negative, got %d", limit)), nil
+	}
+
+	entries, err := s.QueryEngine.FindUnreferenced(index.UnreferencedOptions{
+		ExcludePatterns: strings.Split(request.GetString("exclude", ""), ","),
+	})
+	if err != nil {
+		return s.FormatErrorResponse("find_dead_code", err), nil
+	}
+
+	result := &FindDeadCodeResult{Heuristic: index.DeadCodeHeuristic, Candidates: []DeadCodeCandidate{}}
+	for i := range entries {
+		entry := &entries[i].IndexEntry
+		if s.isExcludedPath(entry.File) {
+			continue
+		}
+		result.Count++
+		if limit > 0 && len(result.Candidates) == limit {
+			result.Truncated = true
+			continue
+		}
+		result.Candidates = append(result.Candidates, DeadCodeCandidate{
+			Name:      entry.Name,
+			Kind:      entry.Type,
+			File:      entry.File,
+			Line:      entry.StartLine,
+			Signature: entry.Signature,
+		})
+	}
+	return s.FormatSuccessResponse(result), nil
+}
+
+// FindRecursionResult is the response of find_recursion
+type FindRecursionResult struct {
+	SelfRecursive []index.RecursiveFunction `json:"self_recursive"`
+	Groups        [][]string                `json:"groups"` // Mutually recursive functions, one group per call cycle
+	Truncated     bool                      `json:"truncated,omitempty"`
+}
+
+// HandleFindRecursion lists the self-recursive functions and the groups of mutually recursive ones
+func (s *RepoContextMCPServer) HandleFindRecursion(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// System-level validation
+	if s.QueryEngine == nil {
+		return nil, fmt.Errorf("query engine not initialized - system configuration error")
+	}
+
+	// Repository validation
+	if err := s.validateRepository(); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Repository validation failed: %v", err)), nil
+	}
+
+	limit := request.GetInt("limit", 0)
+	if limit < 0 {
+		return mcp.NewToolResultError(fmt.Sprintf("Parameter validation failed: limit must not be negative, got %d", limit)), nil
+	}
+
+	selfRecursive, err := s.QueryEngine.FindSelfRecursive()
+	if err != nil {
+		return s.FormatErrorResponse("find_recursion", err), nil
+	}
+	groups, err := s.QueryEngine.FindRecursiveGroups()
+	if err != nil {
+		return s.FormatErrorResponse("find_recursion", err), nil
+	}
+
+	result := &FindRecursionResult{
+		SelfRecursive: slices.DeleteFunc(selfRecursive, func(function index.RecursiveFunction) bool {
+			return s.isExcludedPath(function.File)
+		}),
+		Groups: groups,
+	}
+	if limit > 0 && len(result.SelfRecursive) > limit {
+		result.SelfRecursive, result.Truncated = result.SelfRecursive[:limit], true
+	}
+	if limit > 0 && len(result.Groups) > limit {
+		result.Groups, result.Truncated = result.Groups[:limit], true
+	}
+	return s.FormatSuccessResponse(result), nil
+}
+
+// ResolveImportResult is the response of resolve_import
+type ResolveImportResult struct {
+	ImportPath string           `json:"import_path"`
+	Symbol     string           `json:"symbol"`
+	Definition *EnclosingSymbol `json:"definition"`
+}
+
+// HandleResolveImport finds the indexed definition of an imported symbol
+func (s *RepoContextMCPServer) HandleResolveImport(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// System-level validation
+	if s.QueryEngine == nil {
+		return nil, fmt.Errorf("query engine not initialized - system configuration error")
+	}
+
+	// Repository validation
+	if err := s.validateRepository(); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Repository validation failed: %v", err)), nil
+	}
+
+	importPath := strings.TrimSpace(request.GetString("import_path", ""))
+	if importPath == "" {
+		return mcp.NewToolResultError("Parameter validation failed: import_path parameter is required"), nil
+	}
+	symbol := strings.TrimSpace(request.GetString("symbol", ""))
+	if symbol == "" {
+		var err error
+		if importPath, symbol, err = index.SplitImportedSymbol(importPath); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Parameter validation failed: %v", err)), nil
+		}
+	}
+	fromFile := s.repoRelativePath(strings.TrimSpace(request.GetString("from_file", "")))
+
+	entry, err := s.QueryEngine.ResolveImportFrom(fromFile, importPath, symbol)
+	if err != nil {
+		return s.FormatErrorResponse("resolve_import", err), nil
+	}
+	if entry == nil || s.isExcludedPath(entry.IndexEntry.File) {
+		notFound := s.notFoundError("symbol", symbol, true, func(string) bool { return true })
+		return s.FormatErrorResponse("resolve_import", fmt.Errorf("import '%s': %w", importPath, notFound)), nil
+	}
+
+	return s.FormatSuccessResponse(&ResolveImportResult{
+		ImportPath: importPath,
+		Symbol:     symbol,
+		Definition: &EnclosingSymbol{
+			Name:      entry.IndexEntry.Name,
+			Kind:      entry.IndexEntry.Type,
+			File:      entry.IndexEntry.File,
+			StartLine: entry.IndexEntry.StartLine,
+			EndLine:   entry.IndexEntry.EndLine,
+			Signature: entry.IndexEntry.Signature,
+		},
+	}), nil
+}
+
+// EnclosingSymbol is the symbol symbol_at_location found around a line
+type EnclosingSymbol struct {
+	Name      string `json:"name"`
+	Kind      string `json:"kind"`
+	File      string `json:"file"`
+	StartLine int    `json:"start_line"`
+	EndLine   int    `json:"end_line"`
+	Signature string `json:"signature,omitempty"`
+}
+
+// SymbolAtLocationResult is the response of symbol_at_location
+type SymbolAtLocationResult struct {
+	FilePath string           `json:"file_path"`
+	Line     int              `json:"line"`
+	Symbol   *EnclosingSymbol `json:"symbol"` // Nil when no symbol contains the line
+}
+
+// HandleSymbolAtLocation finds the innermost symbol containing a line of a file
+func (s *RepoContextMCPServer) HandleSymbolAtLocation(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// System-level validation
+	if s.QueryEngine == nil {
+		return nil, fmt.Errorf("query engine not initialized - system configuration error")
+	}
+
+	// Repository validation
+	if err := s.validateRepository(); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Repository validation failed: %v", err)), nil
+	}
+
+	filePath := s.repoRelativePath(strings.TrimSpace(request.GetString("file_path", "")))
+	if filePath == "" {
+		return mcp.NewToolResultError("Parameter validation failed: file_path parameter is required"), nil
+	}
+	line := request.GetInt("line", 0)
+	if line <= 0 {
+		return mcp.NewToolResultError(fmt.Sprintf("Parameter validation failed: line must be positive, got %d", line)), nil
+	}
+
+	result := &SymbolAtLocationResult{FilePath: filePath, Line: line}
+	if s.isExcludedPath(filePath) {
+		return s.FormatSuccessResponse(result), nil
+	}
+	entry, err := s.QueryEngine.FindEnclosingSymbol(filePath, line)
+	if err != nil {
+		return s.FormatErrorResponse("symbol_at_location", err), nil
+	}
+	if entry != nil {
+		result.Symbol = &EnclosingSymbol{
+			Name:      entry.IndexEntry.Name,
+			Kind:      entry.IndexEntry.Type,
+			File:      entry.IndexEntry.File,
+			StartLine: entry.IndexEntry.StartLine,
+			EndLine:   entry.IndexEntry.EndLine,
+			Signature: entry.IndexEntry.Signature,
+		}
+	}
+	return s.FormatSuccessResponse(result), nil
+}
+
+// HandleGetOverview summarizes the repository within a token budget
+func (s *RepoContextMCPServer) HandleGetOverview(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// System-level validation
+	if s.QueryEngine == nil {
+		return nil, fmt.Errorf("query engine not initialized - system configuration error")
+	}
+
+	// Repository validation
+	if err := s.validateRepository(); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Repository validation failed: %v", err)), nil
+	}
+
+	options := index.OverviewOptions{
+		MaxTokens:    request.GetInt("max_tokens", index.DefaultOverviewMaxTokens),
+		SectionLimit: request.GetInt("limit", index.DefaultOverviewSectionLimit),
+		ExcludeFile:  s.isExcludedPath,
+	}
+	if options.MaxTokens <= 0 {
+		return mcp.NewToolResultError(fmt.Sprintf("Parameter validation failed: max_tokens must be positive, got %d", options.MaxTokens)), nil
+	}
+	if options.SectionLimit <= 0 {
+		return mcp.NewToolResultError(fmt.Sprintf("Parameter validation failed: limit must be positive, got %d", options.SectionLimit)), nil
+	}
+
+	overview, err := s.QueryEngine.GetOverview(options)
+	if err != nil {
+		return s.FormatErrorResponse("get_overview", err), nil
+	}
+	return s.FormatSuccessResponse(overview), nil
 }
 
 // parseListEntitiesParameters extracts and validates parameters for list operations
 func (s *RepoContextMCPServer) parseListEntitiesParameters(request mcp.CallToolRequest) *ListEntitiesParams {
 	return &ListEntitiesParams{
-		MaxTokens:         request.GetInt("max_tokens", constMaxTokens),
+		MaxTokens:         request.GetInt("max_tokens", s.getMaxTokens()),
 		IncludeSignatures: request.GetBool("include_signatures", true),
 		Limit:             request.GetInt("limit", 0),
 		Offset:            request.GetInt("offset", 0),
+		IncludePattern:    request.GetString("include_pattern", ""),
+		ExcludePattern:    request.GetString("exclude_pattern", ""),
+		Language:          strings.TrimSpace(request.GetString("language", "")),
+		ExportedOnly:      request.GetBool("exported_only", false),
+		ChangedOnly:       request.GetBool("changed_only", false),
+		Since:             strings.TrimSpace(request.GetString("since", defaultChangedSince)),
+		Sort:              strings.TrimSpace(request.GetString("sort", listSortByName)),
 	}
 }
 
@@ -360,41 +1297,138 @@ func (s *RepoContextMCPServer) executeListEntitiesWithParams(
 	toolName string,
 	params *ListEntitiesParams,
 ) (*mcp.CallToolResult, error) {
-	// Build query options
+	// Search without a token limit so pagination sees every entity;
+	// the limit is applied to the returned page instead
 	queryOptions := index.QueryOptions{
-		MaxTokens: params.MaxTokens,
-		Format:    "json",
+		Format:       "json",
+		Language:     params.Language,
+		ExportedOnly: params.ExportedOnly,
+	}
+	if err := s.applyChangedOnly(&queryOptions, params.ChangedOnly, params.Since); err != nil {
+		return s.FormatErrorResponse(toolName, err), nil
 	}
 
 	// Search for all entities of the specified type using the query engine
-	searchResult, err := s.QueryEngine.SearchByTypeWithOptions(entityType, queryOptions)
+	searchResult, err := s.searchListEntities(entityType, queryOptions)
 	if err != nil {
 		return s.FormatErrorResponse(toolName, err), nil
 	}
 
-	// Apply pagination if requested
-	if params.Limit > 0 || params.Offset > 0 {
-		s.applyPagination(searchResult, params.Limit, params.Offset)
+	// Filter before paginating so limit and offset apply to the filtered list
+	s.applyExcludedPaths(searchResult)
+	if params.IncludePattern != "" || params.ExcludePattern != "" {
+		s.applyNameFilters(searchResult, params.IncludePattern, params.ExcludePattern)
+	}
+	searchResult.Facets = index.CountFacets(searchResult.Entries)
+
+	// Sort so offsets refer to the same entries across pages
+	sortListEntries(searchResult.Entries)
+	switch params.Sort {
+	case listSortByComplexity:
+		s.sortEntriesByComplexity(searchResult.Entries)
+	case listSortByRecency:
+		s.sortEntriesByRecency(searchResult.Entries)
 	}
 
+	pagination := s.applyPagination(searchResult, params.Limit, params.Offset)
+	s.applyPageTokenLimit(searchResult, params.MaxTokens, params.Offset, &pagination)
+
 	// Filter out signatures if not requested
 	if !params.IncludeSignatures {
 		s.removeSignatures(searchResult)
 	}
 
 	// Return the formatted result
-	return s.FormatSuccessResponse(searchResult), nil
+	return s.FormatSuccessResponse(&ListEntitiesResult{
+		SearchResult:   searchResult,
+		ListPagination: pagination,
+	}), nil
+}
+
+// searchListEntities searches for all entities of an entity type. Types are stored under
+// their kind, such as struct or interface, so the type umbrella searches every kind while
+// the result still reports the type query.
+func (s *RepoContextMCPServer) searchListEntities(entityType string, options index.QueryOptions) (*index.SearchResult, error) {
+	if entityType != index.EntityTypeType {
+		return s.QueryEngine.SearchByTypeWithOptions(entityType, options)
+	}
+
+	searchResult, err := s.QueryEngine.SearchByTypesWithOptions(index.TypeKinds(), options)
+	if err != nil {
+		return nil, err
+	}
+	searchResult.Query = entityType
+	searchResult.SearchType = "type"
+	return searchResult, nil
+}
+
+// sortListEntries orders entries by name, then file and line
+func sortListEntries(entries []index.SearchResultEntry) {
+	slices.SortStableFunc(entries, func(a, b index.SearchResultEntry) int {
+		return cmp.Or(
+			cmp.Compare(a.IndexEntry.Name, b.IndexEntry.Name),
+			cmp.Compare(a.IndexEntry.File, b.IndexEntry.File),
+			cmp.Compare(a.IndexEntry.StartLine, b.IndexEntry.StartLine),
+		)
+	})
+}
+
+// sortEntriesByComplexity orders function entries most complex first, keeping the existing
+// order among entries of equal complexity
+func (s *RepoContextMCPServer) sortEntriesByComplexity(entries []index.SearchResultEntry) {
+	complexities := make(map[models.IndexEntry]int, len(entries))
+	for i := range entries {
+		if function := s.findFunctionModel(&entries[i]); function != nil {
+			complexities[entries[i].IndexEntry] = function.Complexity
+		}
+	}
+	slices.SortStableFunc(entries, func(a, b index.SearchResultEntry) int {
+		return cmp.Compare(complexities[b.IndexEntry], complexities[a.IndexEntry])
+	})
 }
 
-// applyPagination applies limit and offset to search results
-func (s *RepoContextMCPServer) applyPagination(result *index.SearchResult, limit, offset int) {
+// sortEntriesByRecency orders function entries most recently changed first, as recorded by
+// a build with git blame, keeping the existing order among entries changed at the same time
+// and placing entries without a recorded change last
+func (s *RepoContextMCPServer) sortEntriesByRecency(entries []index.SearchResultEntry) {
+	changed := make(map[models.IndexEntry]time.Time, len(entries))
+	for i := range entries {
+		if function := s.findFunctionModel(&entries[i]); function != nil && function.LastModified != nil {
+			changed[entries[i].IndexEntry] = *function.LastModified
+		}
+	}
+	slices.SortStableFunc(entries, func(a, b index.SearchResultEntry) int {
+		return changed[b.IndexEntry].Compare(changed[a.IndexEntry])
+	})
+}
+
+// applyNameFilters keeps entries whose names match includePattern and do not match excludePattern.
+// Empty patterns are ignored.
+func (s *RepoContextMCPServer) applyNameFilters(result *index.SearchResult, includePattern, excludePattern string) {
+	filteredEntries := make([]index.SearchResultEntry, 0, len(result.Entries))
+	for _, entry := range result.Entries {
+		name := entry.IndexEntry.Name
+		if includePattern != "" && !s.QueryEngine.MatchesPattern(name, includePattern) {
+			continue
+		}
+		if excludePattern != "" && s.QueryEngine.MatchesPattern(name, excludePattern) {
+			continue
+		}
+		filteredEntries = append(filteredEntries, entry)
+	}
+	result.Entries = filteredEntries
+}
+
+// applyPagination applies limit and offset to search results and returns the cursor for the next page
+func (s *RepoContextMCPServer) applyPagination(result *index.SearchResult, limit, offset int) ListPagination {
 	totalEntries := len(result.Entries)
+	offset = max(offset, 0)
 
 	// Apply offset
 	if offset > 0 {
 		if offset >= totalEntries {
 			result.Entries = []index.SearchResultEntry{}
-			return
+			return newListPagination(totalEntries, offset, 0)
 		}
 		result.Entries = result.Entries[offset:]
 	}
@@ -404,6 +1438,37 @@ func (s *RepoContextMCPServer) applyPagination(result *index.SearchResult, limit
 		result.Entries = result.Entries[:limit]
 		result.Truncated = true
 	}
+
+	return newListPagination(totalEntries, offset, len(result.Entries))
+}
+
+// applyPageTokenLimit trims a page to maxTokens and moves the cursor back to the first dropped
+// entry. At least one entry is kept so paging always makes progress.
+func (s *RepoContextMCPServer) applyPageTokenLimit(
+	result *index.SearchResult,
+	maxTokens, offset int,
+	pagination *ListPagination,
+) {
+	page := result.Entries
+	s.QueryEngine.TruncateToTokenLimit(result, maxTokens)
+
+	if len(result.Entries) == 0 && len(page) > 0 {
+		result.Entries = page[:1]
+		result.TokenCount = s.QueryEngine.EstimateTokens(result)
+	}
+
+	if len(result.Entries) < len(page) {
+		*pagination = newListPagination(pagination.Total, max(offset, 0), len(result.Entries))
+	}
+}
+
+// newListPagination builds the cursor for a page of returned entries starting at offset
+func newListPagination(total, offset, returned int) ListPagination {
+	next := offset + returned
+	if next >= total {
+		return ListPagination{Total: total}
+	}
+	return ListPagination{Total: total, NextOffset: next, HasMore: true}
 }
 
 // removeSignatures removes signature information from search results when not requested
@@ -417,11 +1482,18 @@ func (s *RepoContextMCPServer) removeSignatures(result *index.SearchResult) {
 
 // QueryByNameParams encapsulates query_by_name parameters with validation
 type QueryByNameParams struct {
-	Name           string
-	IncludeCallers bool
-	IncludeCallees bool
-	IncludeTypes   bool
-	MaxTokens      int
+	Name            string
+	IncludeCallers  bool
+	IncludeCallees  bool
+	IncludeTypes    bool
+	MaxTokens       int
+	CaseInsensitive bool
+	WordMatch       bool
+	Language        string
+	Scope           string
+	ExportedOnly    bool
+	ChangedOnly     bool
+	Since           string // Git ref for ChangedOnly
 }
 
 func (p *QueryByNameParams) GetIncludeCallers() bool { return p.IncludeCallers }
@@ -431,12 +1503,19 @@ func (p *QueryByNameParams) GetMaxTokens() int       { return p.MaxTokens }
 
 // QueryByPatternParams encapsulates query_by_pattern parameters with validation
 type QueryByPatternParams struct {
-	Pattern        string
-	EntityType     string
-	IncludeCallers bool
-	IncludeCallees bool
-	IncludeTypes   bool
-	MaxTokens      int
+	Pattern         string
+	EntityType      string
+	IncludeCallers  bool
+	IncludeCallees  bool
+	IncludeTypes    bool
+	MaxTokens       int
+	CaseInsensitive bool
+	WordMatch       bool
+	Language        string
+	Scope           string
+	ExportedOnly    bool
+	ChangedOnly     bool
+	Since           string // Git ref for ChangedOnly
 }
 
 func (p *QueryByPatternParams) GetIncludeCallers() bool { return p.IncludeCallers }
@@ -448,6 +1527,8 @@ func (p *QueryByPatternParams) GetMaxTokens() int       { return p.MaxTokens }
 type GetCallGraphParams struct {
 	FunctionName   string
 	MaxDepth       int
+	CallerDepth    int // Falls back to MaxDepth when zero
+	CalleeDepth    int // Falls back to MaxDepth when zero
 	IncludeCallers bool
 	IncludeCallees bool
 	MaxTokens      int
@@ -466,12 +1547,33 @@ type QueryOptionsBuilder interface {
 	GetMaxTokens() int
 }
 
+// ListPagination is the cursor returned by list operations. Pass NextOffset back as
+// offset to fetch the next page.
+type ListPagination struct {
+	Total      int  `json:"total"`                 // Entities matching the request across all pages
+	NextOffset int  `json:"next_offset,omitempty"` // Offset of the next page, set when HasMore is true
+	HasMore    bool `json:"has_more"`              // Whether entities remain after this page
+}
+
+// ListEntitiesResult is the response of list operations: a page of entries plus its cursor
+type ListEntitiesResult struct {
+	*index.SearchResult
+	ListPagination
+}
+
 // ListEntitiesParams encapsulates list entity parameters with validation
 type ListEntitiesParams struct {
 	MaxTokens         int
 	IncludeSignatures bool
 	Limit             int
 	Offset            int
+	IncludePattern    string
+	ExcludePattern    string
+	Language          string
+	ExportedOnly      bool
+	ChangedOnly       bool
+	Since             string // Git ref for ChangedOnly
+	Sort              string // listSortByName, listSortByComplexity or listSortByRecency
 }
 
 func (p *ListEntitiesParams) GetIncludeCallers() bool { return false } // Not applicable for list operations
@@ -487,6 +1589,8 @@ func (s *RepoContextMCPServer) RegisterRepositoryManagementTools() []mcp.Tool {
 		s.createInitializeRepositoryTool(),
 		s.createBuildIndexTool(),
 		s.createGetRepositoryStatusTool(),
+		s.createExportIndexTool(),
+		s.createImportIndexTool(),
 	}
 }
 
@@ -504,6 +1608,39 @@ func (s *RepoContextMCPServer) createBuildIndexTool() mcp.Tool {
 		mcp.WithDescription("Build semantic index for the repository by parsing source code files and creating searchable chunks"),
 		mcp.WithString("path", mcp.Description("Path to repository directory (default: current directory)")),
 		mcp.WithBoolean("verbose", mcp.Description("Enable verbose output with detailed build statistics")),
+		mcp.WithBoolean("store_source", mcp.Description(
+			"Store compressed source in the index so get_function_context returns exact bodies after files change (default: false)",
+		)),
+		mcp.WithString("build_tags", mcp.Description(
+			"Comma-separated Go build tags; when given, Go files whose build constraints are not satisfied by these tags "+
+				"and the host GOOS/GOARCH are left out, as go build does (e.g. 'linux,integration')",
+		)),
+		mcp.WithString("since", mcp.Description(
+			"Git ref; when given, only files changed since it are reindexed into the existing index and deleted files are removed "+
+				"(e.g. 'main' or 'HEAD~3')",
+		)),
+		mcp.WithNumber("max_file_size", mcp.Description(
+			fmt.Sprintf("Skip files larger than this many bytes instead of parsing them (default: %d, negative for no limit)",
+				index.DefaultMaxFileSize),
+		)),
+		mcp.WithNumber("concurrency", mcp.Description(
+			"Number of files to parse at once (default: the number of CPUs the process may use)",
+		)),
+		mcp.WithBoolean("git_blame", mcp.Description(
+			"Record the last commit changing each function and type using git blame; slower, so off by default (default: false)",
+		)),
+		mcp.WithBoolean("normalize_types", mcp.Description(
+			"Record next to each parameter, return and field type a language-independent form, "+
+				"e.g. list<string> for List[str], []string and string[] (default: false)",
+		)),
+		mcp.WithBoolean("index_anonymous", mcp.Description(
+			"Also index Go function literals and Python lambdas as functions named func@file:line or lambda@file:line, "+
+				"with their enclosing function as parent and the calls in their body as their own (default: false)",
+		)),
+		mcp.WithBoolean("compress_chunks", mcp.Description(
+			"Gzip the chunk files written by the build, roughly halving the index on disk at a small cost on every query; "+
+				"also enabled by compress_chunks in .repocontext/config.json (default: false)",
+		)),
 	)
 }
 
@@ -670,7 +1807,18 @@ func (s *RepoContextMCPServer) HandleBuildIndex(ctx context.Context, request mcp
 	}
 
 	// Perform index build
-	result, err := s.buildRepositoryIndex(targetPath, params.Verbose)
+	result, err := s.buildRepositoryIndexWithOptions(targetPath, params.Verbose, index.IndexBuilderOptions{
+		StoreSource:    params.StoreSource,
+		MatchBuildTags: params.MatchBuildTags,
+		BuildTags:      params.BuildTags,
+		MaxFileSize:    params.MaxFileSize,
+		Concurrency:    params.Concurrency,
+		ChangedSince:   params.Since,
+		GitBlame:       params.GitBlame,
+		NormalizeTypes: params.NormalizeTypes,
+		IndexAnonymous: params.IndexAnonymous,
+		CompressChunks: params.CompressChunks,
+	})
 	if err != nil {
 		return s.FormatErrorResponse("build_index", err), nil
 	}
@@ -684,12 +1832,32 @@ func (s *RepoContextMCPServer) parseBuildIndexParameters(request mcp.CallToolReq
 	path := request.GetString("path", "")
 	verbose := request.GetBool("verbose", false)
 
+	// Tag matching is enabled by the presence of build_tags, even when it lists no tags
+	_, matchBuildTags := request.GetArguments()["build_tags"]
+
 	return &BuildIndexParams{
-		Path:    path,
-		Verbose: verbose,
+		Path:           path,
+		Verbose:        verbose,
+		StoreSource:    request.GetBool("store_source", false),
+		MatchBuildTags: matchBuildTags,
+		BuildTags:      splitBuildTags(request.GetString("build_tags", "")),
+		MaxFileSize:    int64(request.GetInt("max_file_size", 0)),
+		Concurrency:    request.GetInt("concurrency", 0),
+		Since:          strings.TrimSpace(request.GetString("since", "")),
+		GitBlame:       request.GetBool("git_blame", false),
+		NormalizeTypes: request.GetBool("normalize_types", false),
+		IndexAnonymous: request.GetBool("index_anonymous", false),
+		CompressChunks: request.GetBool("compress_chunks", false),
 	}
 }
 
+// splitBuildTags splits a comma or space separated tag list, dropping empty entries
+func splitBuildTags(tags string) []string {
+	return strings.FieldsFunc(tags, func(r rune) bool {
+		return r == ',' || r == ' '
+	})
+}
+
 // determineBuildPath determines the actual path for index building
 func (s *RepoContextMCPServer) determineBuildPath(providedPath string) (string, error) {
 	if providedPath == "" {
@@ -739,8 +1907,29 @@ func (s *RepoContextMCPServer) validateRepositoryForBuild(path string) error {
 
 // buildRepositoryIndex performs the actual index building and returns build result
 func (s *RepoContextMCPServer) buildRepositoryIndex(path string, verbose bool) (*BuildIndexResult, error) {
+	return s.buildRepositoryIndexWithOptions(path, verbose, index.IndexBuilderOptions{})
+}
+
+// buildRepositoryIndexWithOptions performs the index build with the given builder options
+func (s *RepoContextMCPServer) buildRepositoryIndexWithOptions(
+	path string,
+	verbose bool,
+	options index.IndexBuilderOptions,
+) (*BuildIndexResult, error) {
+	// The built repository's own configuration selects the Python interpreter
+	config, err := LoadRepoConfig(path)
+	if err != nil {
+		return nil, err
+	}
+	if options.PythonInterpreter == "" {
+		options.PythonInterpreter = config.PythonInterpreter
+	}
+	options.CompressChunks = options.CompressChunks || config.CompressChunks
+	options.EntryPointPatterns = config.EntryPointPatterns
+
 	// Create and initialize the IndexBuilder
-	builder := index.NewIndexBuilder(path)
+	options.ToolVersion = ServerVersion
+	builder := index.NewIndexBuilderWithOptions(path, options)
 	if err := builder.Initialize(); err != nil {
 		return nil, fmt.Errorf("failed to initialize index builder: %w", err)
 	}
@@ -770,6 +1959,14 @@ func (s *RepoContextMCPServer) buildRepositoryIndex(path string, verbose bool) (
 		CallsIndexed:     stats.CallsIndexed,
 		Duration:         stats.Duration,
 		Verbose:          verbose,
+		FilesFailed:      len(stats.FailedFiles),
+		FailedFiles:      stats.FailedFiles,
+		FilesSkipped:     len(stats.SkippedFiles),
+		SkippedFiles:     stats.SkippedFiles,
+	}
+	if len(stats.FailedFiles) > 0 {
+		result.Message = fmt.Sprintf("Index built for %d files; %d files failed to parse",
+			stats.FilesProcessed, len(stats.FailedFiles))
 	}
 
 	return result, nil
@@ -777,23 +1974,37 @@ func (s *RepoContextMCPServer) buildRepositoryIndex(path string, verbose bool) (
 
 // BuildIndexParams holds parameters for build_index
 type BuildIndexParams struct {
-	Path    string
-	Verbose bool
+	Path           string
+	Verbose        bool
+	StoreSource    bool
+	MatchBuildTags bool     // Whether build_tags was given
+	BuildTags      []string // Go build tags from build_tags
+	MaxFileSize    int64    // File size limit in bytes, the builder default when zero
+	Concurrency    int      // Files parsed at once, GOMAXPROCS when zero
+	Since          string   // Git ref limiting the build to files changed since it
+	GitBlame       bool     // Annotate functions and types with their last commit
+	NormalizeTypes bool     // Record language-independent parameter, return and field types
+	IndexAnonymous bool     // Index function literals and lambdas as functions
+	CompressChunks bool     // Gzip the chunk files written by the build
 }
 
 // BuildIndexResult holds the result of index building
 type BuildIndexResult struct {
-	Path             string        `json:"path"`
-	Success          bool          `json:"success"`
-	Message          string        `json:"message"`
-	FilesProcessed   int           `json:"files_processed"`
-	FunctionsIndexed int           `json:"functions_indexed"`
-	TypesIndexed     int           `json:"types_indexed"`
-	VariablesIndexed int           `json:"variables_indexed"`
-	ConstantsIndexed int           `json:"constants_indexed"`
-	CallsIndexed     int           `json:"calls_indexed"`
-	Duration         time.Duration `json:"duration"`
-	Verbose          bool          `json:"verbose"`
+	Path             string              `json:"path"`
+	Success          bool                `json:"success"`
+	Message          string              `json:"message"`
+	FilesProcessed   int                 `json:"files_processed"`
+	FunctionsIndexed int                 `json:"functions_indexed"`
+	TypesIndexed     int                 `json:"types_indexed"`
+	VariablesIndexed int                 `json:"variables_indexed"`
+	ConstantsIndexed int                 `json:"constants_indexed"`
+	CallsIndexed     int                 `json:"calls_indexed"`
+	Duration         time.Duration       `json:"duration"`
+	Verbose          bool                `json:"verbose"`
+	FilesFailed      int                 `json:"files_failed"`
+	FailedFiles      []index.FileError   `json:"failed_files,omitempty"` // Files skipped, with the location of each error
+	FilesSkipped     int                 `json:"files_skipped,omitempty"`
+	SkippedFiles     []index.SkippedFile `json:"skipped_files,omitempty"` // Files left out on purpose, with the reason for each
 }
 
 // InitializeRepositoryParams holds parameters for initialize_repository
@@ -939,7 +2150,7 @@ func (s *RepoContextMCPServer) collectDetailedStatistics(repoPath, repoContextPa
 	}
 	statistics.IndexSize = indexInfo.Size()
 
-	// Get index modification time (last build time)
+	// Index modification time stands in for the last build time when no build was recorded
 	statistics.LastBuildTime = indexInfo.ModTime()
 
 	// Load manifest for additional information
@@ -957,6 +2168,17 @@ func (s *RepoContextMCPServer) collectDetailedStatistics(repoPath, repoContextPa
 	}
 	defer storage.Close()
 
+	// Chunk sizes on disk and uncompressed; they differ when chunks are compressed
+	if stored, logical, sizeErr := storage.ChunkStorageSize(); sizeErr == nil {
+		statistics.ChunksSize = stored
+		statistics.ChunksLogicalSize = logical
+	}
+
+	// Share of the index on disk that compaction would reclaim
+	if fragmentation, fragErr := storage.FragmentationEstimate(); fragErr == nil {
+		statistics.FragmentationEstimate = fragmentation
+	}
+
 	// Use query engine to get entity counts
 	queryEngine := index.NewQueryEngine(storage)
 
@@ -990,36 +2212,15 @@ func (s *RepoContextMCPServer) collectDetailedStatistics(repoPath, repoContextPa
 		statistics.ConstantsIndexed = len(constantResult.Entries)
 	}
 
-	// Count source files processed (approximation based on chunks directory)
-	chunksPath := filepath.Join(repoContextPath, "chunks")
-	if chunksInfo, err := os.ReadDir(chunksPath); err == nil {
-		statistics.FilesProcessed = len(chunksInfo)
-	}
-
-	// Calculate last build duration based on file timestamps
-	if !statistics.LastBuildTime.IsZero() && !statistics.InitializedTime.IsZero() {
-		// Calculate absolute time difference between build and initialization
-		var duration time.Duration
-		if statistics.LastBuildTime.After(statistics.InitializedTime) {
-			duration = statistics.LastBuildTime.Sub(statistics.InitializedTime)
-		} else {
-			duration = statistics.InitializedTime.Sub(statistics.LastBuildTime)
-		}
-
-		// Ensure we have a meaningful duration (minimum based on entities processed)
-		totalEntities := statistics.FunctionsIndexed + statistics.TypesIndexed +
-			statistics.VariablesIndexed + statistics.ConstantsIndexed
-		if totalEntities > 0 {
-			// Use actual duration if reasonable, otherwise estimate based on entities
-			minEstimatedDuration := time.Duration(totalEntities) * constDuration50ms * time.Millisecond
-			if duration > minEstimatedDuration {
-				statistics.LastBuildDuration = duration
-			} else {
-				statistics.LastBuildDuration = minEstimatedDuration
-			}
-		} else {
-			statistics.LastBuildDuration = time.Second // Default minimum
-		}
+	// Report the recorded builds; indexes built before build history was kept have none
+	history := storage.BuildHistory()
+	if len(history) > 0 {
+		lastBuild := history[len(history)-1]
+		statistics.LastBuild = &lastBuild
+		statistics.BuildHistory = history
+		statistics.FilesProcessed = lastBuild.FilesProcessed
+		statistics.LastBuildTime = lastBuild.StartedAt.Add(lastBuild.Duration)
+		statistics.LastBuildDuration = lastBuild.Duration
 	}
 
 	return statistics, nil
@@ -1049,10 +2250,19 @@ type RepositoryStatistics struct {
 	CallsIndexed      int           `json:"calls_indexed"`
 	IndexSize         int64         `json:"index_size"`
 	ManifestSize      int64         `json:"manifest_size"`
+	ChunksSize        int64         `json:"chunks_size"`         // Disk space taken by the chunk files
+	ChunksLogicalSize int64         `json:"chunks_logical_size"` // Uncompressed size of the chunks, equal to ChunksSize without compression
 	LastBuildTime     time.Time     `json:"last_build_time"`
 	LastBuildDuration time.Duration `json:"last_build_duration"`
 	InitializedTime   time.Time     `json:"initialized_time"`
 
+	// Fraction of index disk usage, between 0 and 1, that 'repocontext compact' would reclaim
+	FragmentationEstimate float64 `json:"fragmentation_estimate"`
+
+	// Builds recorded in the manifest, oldest first; LastBuild is the most recent
+	LastBuild    *models.BuildRecord  `json:"last_build,omitempty"`
+	BuildHistory []models.BuildRecord `json:"build_history,omitempty"`
+
 	// Additional fields for detailed statistics
 	RepositoryPath  string `json:"repository_path"`
 	IndexPath       string `json:"index_path"`
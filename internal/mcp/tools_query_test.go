@@ -1,8 +1,15 @@
 package mcp
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/mark3labs/mcp-go/mcp"
 
@@ -23,6 +30,20 @@ func TestAdvancedQueryTools_Registration(t *testing.T) {
 		"get_call_graph",
 		"list_functions",
 		"list_types",
+		"autocomplete",
+		"query_by_decorator",
+		"analyze_imports",
+		"list_files",
+		"get_hotspots",
+		"search_content",
+		"find_call_path",
+		"get_test_coverage",
+		"get_overview",
+		"find_dead_code",
+		"symbol_at_location",
+		"batch_query",
+		"find_recursion",
+		"resolve_import",
 	}
 
 	if len(tools) != len(expectedToolNames) {
@@ -82,6 +103,162 @@ func TestParameterParsing(t *testing.T) {
 		}
 	})
 
+	t.Run("parseCaseInsensitiveParameter", func(t *testing.T) {
+		request := mcp.CallToolRequest{}
+		request.Params.Arguments = map[string]interface{}{
+			"name":             "ParseURL",
+			"pattern":          "parse*",
+			"case_insensitive": true,
+		}
+
+		nameParams, err := server.parseQueryByNameParameters(request)
+		if err != nil || !nameParams.CaseInsensitive {
+			t.Errorf("Expected query_by_name to parse case_insensitive, got %+v (err: %v)", nameParams, err)
+		}
+		patternParams, err := server.parseQueryByPatternParameters(request)
+		if err != nil || !patternParams.CaseInsensitive {
+			t.Errorf("Expected query_by_pattern to parse case_insensitive, got %+v (err: %v)", patternParams, err)
+		}
+	})
+
+	t.Run("parseWordMatchParameter", func(t *testing.T) {
+		request := mcp.CallToolRequest{}
+		request.Params.Arguments = map[string]interface{}{
+			"name":       "user",
+			"pattern":    "user*",
+			"word_match": true,
+		}
+
+		nameParams, err := server.parseQueryByNameParameters(request)
+		if err != nil || !nameParams.WordMatch {
+			t.Errorf("Expected query_by_name to parse word_match, got %+v (err: %v)", nameParams, err)
+		}
+		patternParams, err := server.parseQueryByPatternParameters(request)
+		if err != nil || !patternParams.WordMatch {
+			t.Errorf("Expected query_by_pattern to parse word_match, got %+v (err: %v)", patternParams, err)
+		}
+	})
+
+	t.Run("parseLanguageParameter", func(t *testing.T) {
+		request := mcp.CallToolRequest{}
+		request.Params.Arguments = map[string]interface{}{
+			"name":     "handle",
+			"pattern":  "handle*",
+			"language": " python ",
+		}
+
+		nameParams, err := server.parseQueryByNameParameters(request)
+		if err != nil || nameParams.Language != "python" {
+			t.Errorf("Expected query_by_name to parse language, got %+v (err: %v)", nameParams, err)
+		}
+		patternParams, err := server.parseQueryByPatternParameters(request)
+		if err != nil || patternParams.Language != "python" {
+			t.Errorf("Expected query_by_pattern to parse language, got %+v (err: %v)", patternParams, err)
+		}
+		if listParams := server.parseListEntitiesParameters(request); listParams.Language != "python" {
+			t.Errorf("Expected list tools to parse language, got %+v", listParams)
+		}
+	})
+
+	t.Run("parseScopeParameter", func(t *testing.T) {
+		request := mcp.CallToolRequest{}
+		request.Params.Arguments = map[string]interface{}{
+			"name":    "config",
+			"pattern": "conf*",
+			"scope":   "package",
+		}
+
+		nameParams, err := server.parseQueryByNameParameters(request)
+		if err != nil || nameParams.Scope != "package" {
+			t.Errorf("Expected query_by_name to parse scope, got %+v (err: %v)", nameParams, err)
+		}
+		patternParams, err := server.parseQueryByPatternParameters(request)
+		if err != nil || patternParams.Scope != "package" {
+			t.Errorf("Expected query_by_pattern to parse scope, got %+v (err: %v)", patternParams, err)
+		}
+
+		request.Params.Arguments = map[string]interface{}{"name": "config", "scope": "global"}
+		if _, err := server.parseQueryByNameParameters(request); err == nil {
+			t.Error("Expected error for unknown scope")
+		}
+	})
+
+	t.Run("parseExportedOnlyParameter", func(t *testing.T) {
+		request := mcp.CallToolRequest{}
+		request.Params.Arguments = map[string]interface{}{
+			"name":          "User",
+			"pattern":       "User*",
+			"exported_only": true,
+		}
+
+		nameParams, err := server.parseQueryByNameParameters(request)
+		if err != nil || !nameParams.ExportedOnly {
+			t.Errorf("Expected query_by_name to parse exported_only, got %+v (err: %v)", nameParams, err)
+		}
+		patternParams, err := server.parseQueryByPatternParameters(request)
+		if err != nil || !patternParams.ExportedOnly {
+			t.Errorf("Expected query_by_pattern to parse exported_only, got %+v (err: %v)", patternParams, err)
+		}
+		if listParams := server.parseListEntitiesParameters(request); !listParams.ExportedOnly {
+			t.Errorf("Expected list tools to parse exported_only, got %+v", listParams)
+		}
+	})
+
+	t.Run("parseChangedOnlyParameter", func(t *testing.T) {
+		request := mcp.CallToolRequest{}
+		request.Params.Arguments = map[string]interface{}{"name": "User", "changed_only": true}
+		nameParams, err := server.parseQueryByNameParameters(request)
+		if err != nil || !nameParams.ChangedOnly || nameParams.Since != "HEAD" {
+			t.Errorf("Expected changed_only against HEAD by default, got %+v (err: %v)", nameParams, err)
+		}
+
+		request.Params.Arguments = map[string]interface{}{"changed_only": true, "since": "main"}
+		if listParams := server.parseListEntitiesParameters(request); !listParams.ChangedOnly || listParams.Since != "main" {
+			t.Errorf("Expected list tools to parse changed_only and since, got %+v", listParams)
+		}
+		if buildParams := server.parseBuildIndexParameters(request); buildParams.Since != "main" {
+			t.Errorf("Expected build_index to parse since, got %+v", buildParams)
+		}
+	})
+
+	t.Run("parseSortParameter", func(t *testing.T) {
+		request := mcp.CallToolRequest{}
+		if params := server.parseListEntitiesParameters(request); params.Sort != listSortByName {
+			t.Errorf("Expected list tools to sort by name by default, got %+v", params)
+		}
+
+		request.Params.Arguments = map[string]interface{}{"sort": "by_complexity"}
+		if params := server.parseListEntitiesParameters(request); params.Sort != listSortByComplexity {
+			t.Errorf("Expected list tools to parse sort, got %+v", params)
+		}
+	})
+
+	t.Run("parseBuildTagsParameter", func(t *testing.T) {
+		request := mcp.CallToolRequest{}
+		if params := server.parseBuildIndexParameters(request); params.MatchBuildTags {
+			t.Errorf("Expected tag matching to be off without build_tags, got %+v", params)
+		}
+
+		request.Params.Arguments = map[string]interface{}{"build_tags": "linux, integration"}
+		params := server.parseBuildIndexParameters(request)
+		if !params.MatchBuildTags || len(params.BuildTags) != 2 || params.BuildTags[0] != "linux" || params.BuildTags[1] != "integration" {
+			t.Errorf("Expected build_tags to enable tag matching with two tags, got %+v", params)
+		}
+		if params.MaxFileSize != 0 {
+			t.Errorf("Expected the builder default without max_file_size, got %d", params.MaxFileSize)
+		}
+
+		request.Params.Arguments = map[string]interface{}{"max_file_size": float64(1 << 20)}
+		if params := server.parseBuildIndexParameters(request); params.MaxFileSize != 1<<20 {
+			t.Errorf("Expected max_file_size to be parsed, got %d", params.MaxFileSize)
+		}
+
+		request.Params.Arguments = map[string]interface{}{"concurrency": float64(4)}
+		if params := server.parseBuildIndexParameters(request); params.Concurrency != 4 {
+			t.Errorf("Expected concurrency to be parsed, got %d", params.Concurrency)
+		}
+	})
+
 	t.Run("parseGetCallGraphParameters", func(t *testing.T) {
 		request := mcp.CallToolRequest{}
 
@@ -95,6 +272,23 @@ func TestParameterParsing(t *testing.T) {
 		}
 	})
 
+	t.Run("parseGetCallGraphParameters directional depths", func(t *testing.T) {
+		request := mcp.CallToolRequest{}
+		request.Params.Arguments = map[string]interface{}{
+			"function_name": "main",
+			"caller_depth":  1,
+			"callee_depth":  3,
+		}
+
+		params, err := server.parseGetCallGraphParameters(request)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if params.CallerDepth != 1 || params.CalleeDepth != 3 || params.MaxDepth != constMaxDepth {
+			t.Errorf("Expected caller depth 1, callee depth 3 and default max depth, got %+v", params)
+		}
+	})
+
 	t.Run("parseListEntitiesParameters", func(t *testing.T) {
 		request := mcp.CallToolRequest{}
 
@@ -147,6 +341,90 @@ func TestEntityTypeValidation(t *testing.T) {
 	}
 }
 
+// TestEntityTypeNormalization tests that entity type synonyms map to canonical values
+func TestEntityTypeNormalization(t *testing.T) {
+	server := NewRepoContextMCPServer()
+
+	testCases := map[string]string{
+		"":         "",
+		"function": "function",
+		"func":     "function",
+		"method":   "function",
+		"class":    "type",
+		"struct":   "type",
+		"const":    "constant",
+		"var":      "variable",
+		" Func ":   "function",
+		"TYPE":     "type",
+		"invalid":  "invalid",
+	}
+	for input, expected := range testCases {
+		if normalized := server.normalizeEntityType(input); normalized != expected {
+			t.Errorf("Expected '%s' to normalize to '%s', got '%s'", input, expected, normalized)
+		}
+	}
+
+	server.config.EntityTypeAliases = map[string]string{"def": "function", "class": "constant"}
+	if normalized := server.normalizeEntityType("def"); normalized != "function" {
+		t.Errorf("Expected configured alias 'def' to normalize to 'function', got '%s'", normalized)
+	}
+	if normalized := server.normalizeEntityType("class"); normalized != "constant" {
+		t.Errorf("Expected configured alias to override 'class', got '%s'", normalized)
+	}
+
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]interface{}{"pattern": "Test*", "entity_type": "method"}
+	params, err := server.parseQueryByPatternParameters(request)
+	if err != nil {
+		t.Fatalf("Expected synonym to be accepted, got error: %v", err)
+	}
+	if params.EntityType != "function" {
+		t.Errorf("Expected entity_type 'function', got '%s'", params.EntityType)
+	}
+}
+
+// TestPatternSearch_TypeEntityFilter tests that entity_type "type" matches every type definition kind
+func TestPatternSearch_TypeEntityFilter(t *testing.T) {
+	tempDir := t.TempDir()
+	storage := index.NewHybridStorage(filepath.Join(tempDir, ".repocontext"))
+	if err := storage.Initialize(); err != nil {
+		t.Fatalf("Failed to initialize storage: %v", err)
+	}
+	defer storage.Close()
+
+	if err := storage.StoreFileContext(&models.FileContext{
+		Path: "user.go", Language: "go",
+		Functions: []models.Function{{Name: "UserName", StartLine: 1, EndLine: 3}},
+		Types: []models.TypeDef{
+			{Name: "User", Kind: "struct", StartLine: 5, EndLine: 7},
+			{Name: "UserStore", Kind: "interface", StartLine: 9, EndLine: 11},
+			{Name: "UserID", Kind: "alias", StartLine: 13, EndLine: 13},
+		},
+	}); err != nil {
+		t.Fatalf("Failed to store file context: %v", err)
+	}
+
+	server := &RepoContextMCPServer{QueryEngine: index.NewQueryEngine(storage), Storage: storage, RepoPath: tempDir}
+
+	for entityType, expected := range map[string][]string{
+		"type":     {"User", "UserID", "UserStore"},
+		"function": {"UserName"},
+	} {
+		result, err := server.executePatternSearchWithFilter("User*", entityType, index.QueryOptions{})
+		if err != nil {
+			t.Fatalf("Pattern search failed: %v", err)
+		}
+		var names []string
+		for _, entry := range result.Entries {
+			names = append(names, entry.IndexEntry.Name)
+		}
+		slices.Sort(names)
+		if !slices.Equal(names, expected) {
+			t.Errorf("entity_type %s: expected %v, got %v", entityType, expected, names)
+		}
+	}
+}
+
 // TestQueryOptionsBuilder tests the interface for building query options
 func TestQueryOptionsBuilder(t *testing.T) {
 	server := NewRepoContextMCPServer()
@@ -287,6 +565,51 @@ func TestPaginationHelpers(t *testing.T) {
 		}
 	})
 
+	t.Run("applyPagination_returns_cursor", func(t *testing.T) {
+		result := createTestResult()
+		pagination := server.applyPagination(result, 4, 4)
+
+		expected := ListPagination{Total: 10, NextOffset: 8, HasMore: true}
+		if pagination != expected {
+			t.Errorf("Expected cursor %+v, got %+v", expected, pagination)
+		}
+
+		result = createTestResult()
+		pagination = server.applyPagination(result, 4, 8)
+		if pagination.HasMore || pagination.NextOffset != 0 || pagination.Total != 10 {
+			t.Errorf("Expected last page without a next offset, got %+v", pagination)
+		}
+
+		result = createTestResult()
+		pagination = server.applyPagination(result, 4, 20)
+		if pagination.HasMore || len(result.Entries) != 0 {
+			t.Errorf("Expected offset past the end to return no entries, got %+v", pagination)
+		}
+	})
+
+	t.Run("applyNameFilters_before_pagination", func(t *testing.T) {
+		server.QueryEngine = index.NewQueryEngine(nil)
+		defer func() { server.QueryEngine = nil }()
+
+		result := createTestResult()
+		server.applyNameFilters(result, "item*", "/^item[0-3]$/")
+		server.applyPagination(result, 2, 1)
+
+		if len(result.Entries) != 2 {
+			t.Fatalf("Expected 2 entries after filtering and pagination, got %d", len(result.Entries))
+		}
+		if result.Entries[0].IndexEntry.Name != "item5" || result.Entries[1].IndexEntry.Name != "item6" {
+			t.Errorf("Expected item5 and item6, got %s and %s",
+				result.Entries[0].IndexEntry.Name, result.Entries[1].IndexEntry.Name)
+		}
+
+		result = createTestResult()
+		server.applyNameFilters(result, "item[12]", "")
+		if len(result.Entries) != 2 {
+			t.Errorf("Expected include pattern to keep 2 entries, got %d", len(result.Entries))
+		}
+	})
+
 	t.Run("removeSignatures", func(t *testing.T) {
 		result := createTestResult()
 		// Add signatures
@@ -350,3 +673,871 @@ func TestAdvancedResponseFormatting(t *testing.T) {
 		}
 	})
 }
+
+// TestListEntities_CursorPaging tests paging through list_functions with next_offset
+func TestListEntities_CursorPaging(t *testing.T) {
+	tempDir := t.TempDir()
+	storage := index.NewHybridStorage(filepath.Join(tempDir, ".repocontext"))
+	if err := storage.Initialize(); err != nil {
+		t.Fatalf("Failed to initialize storage: %v", err)
+	}
+	defer storage.Close()
+
+	// Store functions out of name order across two files
+	for _, file := range []string{"b.go", "a.go"} {
+		var functions []models.Function
+		for i := 4; i >= 0; i-- {
+			name := fmt.Sprintf("Func%d%s", i, file[:1])
+			functions = append(functions, models.Function{Name: name, Signature: "func " + name + "()", StartLine: i + 1, EndLine: i + 1})
+		}
+		if err := storage.StoreFileContext(&models.FileContext{Path: file, Language: "go", Functions: functions}); err != nil {
+			t.Fatalf("Failed to store file context: %v", err)
+		}
+	}
+
+	server := &RepoContextMCPServer{QueryEngine: index.NewQueryEngine(storage), Storage: storage, RepoPath: tempDir}
+
+	fetchPage := func(params *ListEntitiesParams) ListEntitiesResult {
+		t.Helper()
+		result, err := server.executeListEntitiesWithParams("function", "list_functions", params)
+		if err != nil || result.IsError {
+			t.Fatalf("Expected list to succeed, got %v %+v", err, result)
+		}
+		var page ListEntitiesResult
+		if err := json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &page); err != nil {
+			t.Fatalf("Failed to decode page: %v", err)
+		}
+		return page
+	}
+
+	var names []string
+	offset := 0
+	for pages := 0; ; pages++ {
+		if pages > 10 {
+			t.Fatal("Paging did not terminate")
+		}
+		page := fetchPage(&ListEntitiesParams{MaxTokens: constMaxTokens, IncludeSignatures: true, Limit: 3, Offset: offset})
+		if page.Total != 10 {
+			t.Errorf("Expected total 10, got %d", page.Total)
+		}
+		for _, entry := range page.Entries {
+			names = append(names, entry.IndexEntry.Name)
+		}
+		if !page.HasMore {
+			break
+		}
+		offset = page.NextOffset
+	}
+
+	if len(names) != 10 || !slices.IsSorted(names) {
+		t.Errorf("Expected all 10 functions once in sorted order, got %v", names)
+	}
+
+	// A token limit smaller than the page moves the cursor back but always makes progress
+	page := fetchPage(&ListEntitiesParams{MaxTokens: 1, IncludeSignatures: true, Limit: 5})
+	if len(page.Entries) != 1 || !page.HasMore || page.NextOffset != 1 {
+		t.Errorf("Expected one entry and next_offset 1 under a tiny token limit, got %d entries and %+v",
+			len(page.Entries), page.ListPagination)
+	}
+}
+
+// TestListTypes_AllKinds tests that list_types covers types stored under their kinds
+func TestListTypes_AllKinds(t *testing.T) {
+	tempDir := t.TempDir()
+	storage := index.NewHybridStorage(filepath.Join(tempDir, ".repocontext"))
+	if err := storage.Initialize(); err != nil {
+		t.Fatalf("Failed to initialize storage: %v", err)
+	}
+	defer storage.Close()
+
+	if err := storage.StoreFileContext(&models.FileContext{
+		Path: "store.go", Language: "go",
+		Types: []models.TypeDef{
+			{Name: "Store", Kind: "struct", StartLine: 3, EndLine: 6},
+			{Name: "Reader", Kind: "interface", StartLine: 8, EndLine: 10},
+			{Name: "ID", Kind: "alias", StartLine: 12, EndLine: 12},
+		},
+		Functions: []models.Function{{Name: "Open", StartLine: 14, EndLine: 16}},
+	}); err != nil {
+		t.Fatalf("Failed to store file context: %v", err)
+	}
+
+	server := &RepoContextMCPServer{QueryEngine: index.NewQueryEngine(storage), Storage: storage, RepoPath: tempDir}
+
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]interface{}{}
+	result, err := server.HandleAdvancedListTypes(context.Background(), request)
+	if err != nil || result.IsError {
+		t.Fatalf("Expected list_types to succeed, got %v %+v", err, result)
+	}
+	var page ListEntitiesResult
+	if err := json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &page); err != nil {
+		t.Fatalf("Failed to decode page: %v", err)
+	}
+	var types []string
+	for _, entry := range page.Entries {
+		types = append(types, entry.IndexEntry.Name+":"+entry.IndexEntry.Type)
+	}
+	if !slices.Equal(types, []string{"ID:alias", "Reader:interface", "Store:struct"}) {
+		t.Errorf("Expected the alias, interface and struct by name, got %v", types)
+	}
+	if page.Query != "type" || page.Total != 3 {
+		t.Errorf("Expected 3 entries for the type query, got %d for %q", page.Total, page.Query)
+	}
+}
+
+// TestListFunctions_SortByComplexity tests ordering list_functions by complexity and the
+// complexity reported by get_function_context
+func TestListFunctions_SortByComplexity(t *testing.T) {
+	tempDir := t.TempDir()
+	storage := index.NewHybridStorage(filepath.Join(tempDir, ".repocontext"))
+	if err := storage.Initialize(); err != nil {
+		t.Fatalf("Failed to initialize storage: %v", err)
+	}
+	defer storage.Close()
+
+	if err := storage.StoreFileContext(&models.FileContext{
+		Path: "handlers.go", Language: "go",
+		Functions: []models.Function{
+			{Name: "Simple", StartLine: 1, EndLine: 3, LineCount: 3, Complexity: 1},
+			{Name: "Tangled", StartLine: 5, EndLine: 40, LineCount: 36, Complexity: 12},
+			{Name: "Moderate", StartLine: 42, EndLine: 50, LineCount: 9, Complexity: 4},
+		},
+	}); err != nil {
+		t.Fatalf("Failed to store file context: %v", err)
+	}
+
+	server := &RepoContextMCPServer{QueryEngine: index.NewQueryEngine(storage), Storage: storage, RepoPath: tempDir}
+
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]interface{}{"sort": "by_complexity"}
+	result, err := server.HandleAdvancedListFunctions(context.Background(), request)
+	if err != nil || result.IsError {
+		t.Fatalf("Expected list_functions to succeed, got %v %+v", err, result)
+	}
+	var page ListEntitiesResult
+	if err := json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &page); err != nil {
+		t.Fatalf("Failed to decode page: %v", err)
+	}
+	var names []string
+	for _, entry := range page.Entries {
+		names = append(names, entry.IndexEntry.Name)
+	}
+	if !slices.Equal(names, []string{"Tangled", "Moderate", "Simple"}) {
+		t.Errorf("Expected functions most complex first, got %v", names)
+	}
+
+	request.Params.Arguments = map[string]interface{}{"sort": "by_size"}
+	result, err = server.HandleAdvancedListFunctions(context.Background(), request)
+	if err != nil || !result.IsError {
+		t.Errorf("Expected an error for an unknown sort, got %v %+v", err, result)
+	}
+
+	request.Params.Arguments = map[string]interface{}{"function_name": "Tangled"}
+	result, err = server.HandleGetFunctionContext(context.Background(), request)
+	if err != nil || result.IsError {
+		t.Fatalf("Expected get_function_context to succeed, got %v %+v", err, result)
+	}
+	var functionContext FunctionContextResult
+	if err := json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &functionContext); err != nil {
+		t.Fatalf("Failed to decode function context: %v", err)
+	}
+	if functionContext.Complexity != 12 || functionContext.LineCount != 36 {
+		t.Errorf("Expected complexity 12 over 36 lines, got %d over %d", functionContext.Complexity, functionContext.LineCount)
+	}
+}
+
+// TestListFunctions_SortByRecency tests ordering list_functions by the last change recorded
+// with git blame and the change reported by get_function_context
+func TestListFunctions_SortByRecency(t *testing.T) {
+	tempDir := t.TempDir()
+	storage := index.NewHybridStorage(filepath.Join(tempDir, ".repocontext"))
+	if err := storage.Initialize(); err != nil {
+		t.Fatalf("Failed to initialize storage: %v", err)
+	}
+	defer storage.Close()
+
+	lastYear := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	lastWeek := time.Date(2026, 10, 11, 0, 0, 0, 0, time.UTC)
+	if err := storage.StoreFileContext(&models.FileContext{
+		Path: "handlers.go", Language: "go",
+		Functions: []models.Function{
+			{Name: "Stale", StartLine: 1, EndLine: 3, LastModified: &lastYear, LastCommit: "aaa"},
+			{Name: "Unknown", StartLine: 5, EndLine: 7},
+			{Name: "Fresh", StartLine: 9, EndLine: 12, LastModified: &lastWeek, LastCommit: "bbb"},
+		},
+	}); err != nil {
+		t.Fatalf("Failed to store file context: %v", err)
+	}
+
+	server := &RepoContextMCPServer{QueryEngine: index.NewQueryEngine(storage), Storage: storage, RepoPath: tempDir}
+
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]interface{}{"sort": "by_recency"}
+	result, err := server.HandleAdvancedListFunctions(context.Background(), request)
+	if err != nil || result.IsError {
+		t.Fatalf("Expected list_functions to succeed, got %v %+v", err, result)
+	}
+	var page ListEntitiesResult
+	if err := json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &page); err != nil {
+		t.Fatalf("Failed to decode page: %v", err)
+	}
+	var names []string
+	for _, entry := range page.Entries {
+		names = append(names, entry.IndexEntry.Name)
+	}
+	if !slices.Equal(names, []string{"Fresh", "Stale", "Unknown"}) {
+		t.Errorf("Expected functions most recently changed first, got %v", names)
+	}
+
+	request.Params.Arguments = map[string]interface{}{"function_name": "Fresh"}
+	result, err = server.HandleGetFunctionContext(context.Background(), request)
+	if err != nil || result.IsError {
+		t.Fatalf("Expected get_function_context to succeed, got %v %+v", err, result)
+	}
+	var functionContext FunctionContextResult
+	if err := json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &functionContext); err != nil {
+		t.Fatalf("Failed to decode function context: %v", err)
+	}
+	if functionContext.LastModified == nil || !functionContext.LastModified.Equal(lastWeek) || functionContext.LastCommit != "bbb" {
+		t.Errorf("Expected the last change of Fresh, got %v %q", functionContext.LastModified, functionContext.LastCommit)
+	}
+}
+
+// TestHandleListFiles tests language filtering and entity-count sorting of list_files
+func TestHandleListFiles(t *testing.T) {
+	tempDir := t.TempDir()
+	storage := index.NewHybridStorage(filepath.Join(tempDir, ".repocontext"))
+	if err := storage.Initialize(); err != nil {
+		t.Fatalf("Failed to initialize storage: %v", err)
+	}
+	defer storage.Close()
+
+	for _, fileContext := range []*models.FileContext{
+		{Path: "a.go", Language: "go", Functions: []models.Function{{Name: "A"}}},
+		{Path: "b.go", Language: "go", Functions: []models.Function{{Name: "B1"}, {Name: "B2"}}},
+		{Path: "c.py", Language: "python", Functions: []models.Function{{Name: "c"}}},
+	} {
+		if err := storage.StoreFileContext(fileContext); err != nil {
+			t.Fatalf("Failed to store file context: %v", err)
+		}
+	}
+
+	server := &RepoContextMCPServer{QueryEngine: index.NewQueryEngine(storage), Storage: storage, RepoPath: tempDir}
+
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]interface{}{"language": "go", "sort_by": FileSortEntities}
+	result, err := server.HandleListFiles(context.Background(), request)
+	if err != nil || result.IsError {
+		t.Fatalf("Expected list_files to succeed, got %v %+v", err, result)
+	}
+
+	var listed ListFilesResult
+	if err := json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &listed); err != nil {
+		t.Fatalf("Failed to decode result: %v", err)
+	}
+	if listed.TotalFiles != 2 || listed.Languages["go"] != 2 {
+		t.Errorf("Expected 2 go files, got %+v", listed)
+	}
+	if len(listed.Files) == 2 && (listed.Files[0].Path != "b.go" || listed.Files[1].Path != "a.go") {
+		t.Errorf("Expected files sorted by entity count, got %s, %s", listed.Files[0].Path, listed.Files[1].Path)
+	}
+
+	request.Params.Arguments = map[string]interface{}{"sort_by": "size"}
+	result, err = server.HandleListFiles(context.Background(), request)
+	if err != nil || !result.IsError {
+		t.Errorf("Expected validation error for unknown sort_by, got %v %+v", err, result)
+	}
+}
+
+// TestHandleGetHotspots tests ranking by callers and callees in get_hotspots
+func TestHandleGetHotspots(t *testing.T) {
+	tempDir := t.TempDir()
+	storage := index.NewHybridStorage(filepath.Join(tempDir, ".repocontext"))
+	if err := storage.Initialize(); err != nil {
+		t.Fatalf("Failed to initialize storage: %v", err)
+	}
+	defer storage.Close()
+
+	if err := storage.StoreFileContext(&models.FileContext{
+		Path: "main.go", Language: "go",
+		Functions: []models.Function{
+			{Name: "main", StartLine: 1, Calls: []string{"load", "run", "log"}},
+			{Name: "run", StartLine: 5, Calls: []string{"log"}},
+			{Name: "load", StartLine: 9, Calls: []string{"log"}},
+			{Name: "log", StartLine: 13},
+		},
+	}); err != nil {
+		t.Fatalf("Failed to store file context: %v", err)
+	}
+
+	server := &RepoContextMCPServer{QueryEngine: index.NewQueryEngine(storage), Storage: storage, RepoPath: tempDir}
+
+	decode := func(arguments map[string]interface{}) HotspotsResult {
+		request := mcp.CallToolRequest{}
+		request.Params.Arguments = arguments
+		result, err := server.HandleGetHotspots(context.Background(), request)
+		if err != nil || result.IsError {
+			t.Fatalf("Expected get_hotspots to succeed, got %v %+v", err, result)
+		}
+		var hotspots HotspotsResult
+		if err := json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &hotspots); err != nil {
+			t.Fatalf("Failed to decode result: %v", err)
+		}
+		return hotspots
+	}
+
+	hotspots := decode(map[string]interface{}{"limit": 1})
+	if len(hotspots.Hotspots) != 1 || hotspots.Hotspots[0].Function != "log" || hotspots.Hotspots[0].CallerCount != 3 {
+		t.Errorf("Expected log with 3 callers, got %+v", hotspots)
+	}
+
+	hotspots = decode(map[string]interface{}{"rank_by": HotspotRankCallees})
+	if len(hotspots.Hotspots) == 0 || hotspots.Hotspots[0].Function != "main" || hotspots.Hotspots[0].CalleeCount != 3 {
+		t.Errorf("Expected main to have the highest fan-out, got %+v", hotspots)
+	}
+
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]interface{}{"rank_by": "size"}
+	result, err := server.HandleGetHotspots(context.Background(), request)
+	if err != nil || !result.IsError {
+		t.Errorf("Expected validation error for unknown rank_by, got %v %+v", err, result)
+	}
+}
+
+// TestHandleSearchContent tests line matches, limits and validation in search_content
+func TestHandleSearchContent(t *testing.T) {
+	tempDir := t.TempDir()
+	storage := index.NewHybridStorage(filepath.Join(tempDir, ".repocontext"))
+	if err := storage.Initialize(); err != nil {
+		t.Fatalf("Failed to initialize storage: %v", err)
+	}
+	defer storage.Close()
+
+	sourcePath := filepath.Join(tempDir, "client.go")
+	source := "package client\n\n// Dial reports connection refused errors\nfunc Dial() error {\n\treturn errors.New(\"connection refused\")\n}\n"
+	if err := os.WriteFile(sourcePath, []byte(source), ConstFilePermission600); err != nil {
+		t.Fatalf("Failed to write source: %v", err)
+	}
+	if err := storage.StoreFileContext(&models.FileContext{
+		Path: sourcePath, Language: "go",
+		Functions: []models.Function{{Name: "Dial", StartLine: 4}},
+	}); err != nil {
+		t.Fatalf("Failed to store file context: %v", err)
+	}
+
+	server := &RepoContextMCPServer{QueryEngine: index.NewQueryEngine(storage), Storage: storage, RepoPath: tempDir}
+
+	decode := func(arguments map[string]interface{}) index.ContentSearchResult {
+		request := mcp.CallToolRequest{}
+		request.Params.Arguments = arguments
+		result, err := server.HandleSearchContent(context.Background(), request)
+		if err != nil || result.IsError {
+			t.Fatalf("Expected search_content to succeed, got %v %+v", err, result)
+		}
+		var content index.ContentSearchResult
+		if err := json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &content); err != nil {
+			t.Fatalf("Failed to decode result: %v", err)
+		}
+		return content
+	}
+
+	content := decode(map[string]interface{}{"pattern": "Connection Refused", "case_insensitive": true})
+	if len(content.Matches) != 2 || content.FilesSearched != 1 || content.Truncated {
+		t.Fatalf("Expected the comment and the string literal to match, got %+v", content)
+	}
+	if match := content.Matches[1]; match.File != sourcePath || match.Line != 5 || match.Snippet != `return errors.New("connection refused")` {
+		t.Errorf("Expected the string literal on line 5, got %+v", match)
+	}
+
+	content = decode(map[string]interface{}{"pattern": "refused", "max_matches": 1})
+	if len(content.Matches) != 1 || !content.Truncated {
+		t.Errorf("Expected one match and truncation, got %+v", content)
+	}
+
+	for name, arguments := range map[string]map[string]interface{}{
+		"missing pattern": {},
+		"invalid pattern": {"pattern": "(unclosed"},
+		"zero limit":      {"pattern": "refused", "max_matches": 0},
+	} {
+		request := mcp.CallToolRequest{}
+		request.Params.Arguments = arguments
+		result, err := server.HandleSearchContent(context.Background(), request)
+		if err != nil || !result.IsError {
+			t.Errorf("%s: expected an error result, got %v %+v", name, err, result)
+		}
+	}
+}
+
+// TestHandleFindCallPath tests call chains and validation in find_call_path
+func TestHandleFindCallPath(t *testing.T) {
+	tempDir := t.TempDir()
+	storage := index.NewHybridStorage(filepath.Join(tempDir, ".repocontext"))
+	if err := storage.Initialize(); err != nil {
+		t.Fatalf("Failed to initialize storage: %v", err)
+	}
+	defer storage.Close()
+
+	if err := storage.StoreFileContext(&models.FileContext{
+		Path: "server.go", Language: "go",
+		Functions: []models.Function{
+			{Name: "HandleRequest", StartLine: 1, Calls: []string{"saveUser"}},
+			{Name: "saveUser", StartLine: 5, Calls: []string{"writeToDatabase"}},
+			{Name: "writeToDatabase", StartLine: 9},
+		},
+	}); err != nil {
+		t.Fatalf("Failed to store file context: %v", err)
+	}
+
+	server := &RepoContextMCPServer{QueryEngine: index.NewQueryEngine(storage), Storage: storage, RepoPath: tempDir}
+
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]interface{}{"from_function": "HandleRequest", "to_function": "writeToDatabase"}
+	result, err := server.HandleFindCallPath(context.Background(), request)
+	if err != nil || result.IsError {
+		t.Fatalf("Expected find_call_path to succeed, got %v %+v", err, result)
+	}
+	var callPath CallPathResult
+	if err := json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &callPath); err != nil {
+		t.Fatalf("Failed to decode result: %v", err)
+	}
+	if len(callPath.Paths) != 1 || !slices.Equal(callPath.Paths[0], []string{"HandleRequest", "saveUser", "writeToDatabase"}) {
+		t.Errorf("Expected the chain through saveUser, got %+v", callPath)
+	}
+	if callPath.MaxDepth != index.DefaultCallPathDepth {
+		t.Errorf("Expected default depth %d, got %d", index.DefaultCallPathDepth, callPath.MaxDepth)
+	}
+
+	for name, arguments := range map[string]map[string]interface{}{
+		"missing to_function": {"from_function": "HandleRequest"},
+		"zero depth":          {"from_function": "HandleRequest", "to_function": "saveUser", "max_depth": 0},
+	} {
+		request := mcp.CallToolRequest{}
+		request.Params.Arguments = arguments
+		result, err := server.HandleFindCallPath(context.Background(), request)
+		if err != nil || !result.IsError {
+			t.Errorf("%s: expected an error result, got %v %+v", name, err, result)
+		}
+	}
+}
+
+// TestHandleGetTestCoverage tests listing functions no test reaches in get_test_coverage
+func TestHandleGetTestCoverage(t *testing.T) {
+	tempDir := t.TempDir()
+	storage := index.NewHybridStorage(filepath.Join(tempDir, ".repocontext"))
+	if err := storage.Initialize(); err != nil {
+		t.Fatalf("Failed to initialize storage: %v", err)
+	}
+	defer storage.Close()
+
+	for _, fileContext := range []*models.FileContext{
+		{
+			Path: "user.go", Language: "go",
+			Functions: []models.Function{{Name: "GetUser", StartLine: 1}, {Name: "DeleteUser", StartLine: 5}},
+			Exports:   []models.Export{{Name: "GetUser", Kind: "function"}, {Name: "DeleteUser", Kind: "function"}},
+		},
+		{
+			Path: "user_test.go", Language: "go",
+			Functions: []models.Function{{Name: "TestGetUser", StartLine: 1, IsTest: true, Calls: []string{"GetUser"}}},
+		},
+	} {
+		if err := storage.StoreFileContext(fileContext); err != nil {
+			t.Fatalf("Failed to store file context: %v", err)
+		}
+	}
+
+	server := &RepoContextMCPServer{QueryEngine: index.NewQueryEngine(storage), Storage: storage, RepoPath: tempDir}
+
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]interface{}{"include_covered": true}
+	result, err := server.HandleGetTestCoverage(context.Background(), request)
+	if err != nil || result.IsError {
+		t.Fatalf("Expected get_test_coverage to succeed, got %v %+v", err, result)
+	}
+	var coverage TestCoverageResult
+	if err := json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &coverage); err != nil {
+		t.Fatalf("Failed to decode result: %v", err)
+	}
+	if coverage.Tests != 1 || coverage.CoveredCount != 1 || coverage.UncoveredCount != 1 ||
+		coverage.Uncovered[0].Function != "DeleteUser" || coverage.Covered[0].Function != "GetUser" {
+		t.Errorf("Expected GetUser covered and DeleteUser uncovered, got %+v", coverage)
+	}
+	if coverage.Heuristic != index.TestCoverageHeuristic {
+		t.Errorf("Expected the result to be labelled a heuristic, got %q", coverage.Heuristic)
+	}
+
+	request.Params.Arguments = map[string]interface{}{"max_depth": 0}
+	result, err = server.HandleGetTestCoverage(context.Background(), request)
+	if err != nil || !result.IsError {
+		t.Errorf("Expected an error for zero max_depth, got %v %+v", err, result)
+	}
+}
+
+// TestHandleGetOverview tests the repository summary and validation in get_overview
+func TestHandleGetOverview(t *testing.T) {
+	tempDir := t.TempDir()
+	storage := index.NewHybridStorage(filepath.Join(tempDir, ".repocontext"))
+	if err := storage.Initialize(); err != nil {
+		t.Fatalf("Failed to initialize storage: %v", err)
+	}
+	defer storage.Close()
+
+	if err := storage.StoreFileContext(&models.FileContext{
+		Path: "main.go", Language: "go",
+		Functions: []models.Function{{Name: "main", StartLine: 1, Calls: []string{"Run"}}, {Name: "Run", StartLine: 5}},
+		Exports:   []models.Export{{Name: "Run", Kind: "function"}},
+	}); err != nil {
+		t.Fatalf("Failed to store file context: %v", err)
+	}
+
+	server := &RepoContextMCPServer{QueryEngine: index.NewQueryEngine(storage), Storage: storage, RepoPath: tempDir}
+
+	request := mcp.CallToolRequest{}
+	result, err := server.HandleGetOverview(context.Background(), request)
+	if err != nil || result.IsError {
+		t.Fatalf("Expected get_overview to succeed, got %v %+v", err, result)
+	}
+	var overview index.RepositoryOverview
+	if err := json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &overview); err != nil {
+		t.Fatalf("Failed to decode result: %v", err)
+	}
+	if overview.Files != 1 || len(overview.EntryPoints) != 1 || len(overview.PublicAPI) != 1 || overview.PublicAPI[0].Name != "Run" {
+		t.Errorf("Expected main as entry point and Run as public API, got %+v", overview)
+	}
+
+	request.Params.Arguments = map[string]interface{}{"max_tokens": -1}
+	result, err = server.HandleGetOverview(context.Background(), request)
+	if err != nil || !result.IsError {
+		t.Errorf("Expected an error for negative max_tokens, got %v %+v", err, result)
+	}
+}
+
+// TestHandleFindDeadCode tests the candidates, exclusions and limit of find_dead_code
+func TestHandleFindDeadCode(t *testing.T) {
+	tempDir := t.TempDir()
+	storage := index.NewHybridStorage(filepath.Join(tempDir, ".repocontext"))
+	if err := storage.Initialize(); err != nil {
+		t.Fatalf("Failed to initialize storage: %v", err)
+	}
+	defer storage.Close()
+
+	for _, fileContext := range []*models.FileContext{
+		{
+			Path: "main.go", Language: "go",
+			Functions: []models.Function{{Name: "main", StartLine: 1, Calls: []string{"Run"}}, {Name: "Run", StartLine: 5}},
+		},
+		{
+			Path: "util/util.go", Language: "go",
+			Functions: []models.Function{{Name: "Unused", StartLine: 3}, {Name: "Stale", StartLine: 9}},
+			Exports:   []models.Export{{Name: "Unused", Kind: "function"}, {Name: "Stale", Kind: "function"}},
+		},
+		{
+			Path: "api/api.go", Language: "go",
+			Functions: []models.Function{{Name: "Serve", StartLine: 3}},
+			Exports:   []models.Export{{Name: "Serve", Kind: "function"}},
+		},
+	} {
+		if err := storage.StoreFileContext(fileContext); err != nil {
+			t.Fatalf("Failed to store file context: %v", err)
+		}
+	}
+
+	server := &RepoContextMCPServer{QueryEngine: index.NewQueryEngine(storage), Storage: storage, RepoPath: tempDir}
+
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]interface{}{"exclude": "api", "limit": 1}
+	result, err := server.HandleFindDeadCode(context.Background(), request)
+	if err != nil || result.IsError {
+		t.Fatalf("Expected find_dead_code to succeed, got %v %+v", err, result)
+	}
+	var deadCode FindDeadCodeResult
+	if err := json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &deadCode); err != nil {
+		t.Fatalf("Failed to decode result: %v", err)
+	}
+	if deadCode.Count != 2 || !deadCode.Truncated || len(deadCode.Candidates) != 1 || deadCode.Candidates[0].Name != "Unused" {
+		t.Errorf("Expected Unused as the first of 2 candidates outside api, got %+v", deadCode)
+	}
+	if deadCode.Heuristic != index.DeadCodeHeuristic {
+		t.Errorf("Expected the result to be labelled as a heuristic, got %q", deadCode.Heuristic)
+	}
+
+	request.Params.Arguments = map[string]interface{}{"limit": -1}
+	result, err = server.HandleFindDeadCode(context.Background(), request)
+	if err != nil || !result.IsError {
+		t.Errorf("Expected an error for negative limit, got %v %+v", err, result)
+	}
+}
+
+// TestHandleSymbolAtLocation tests resolving a file and line to the innermost symbol
+func TestHandleSymbolAtLocation(t *testing.T) {
+	tempDir := t.TempDir()
+	storage := index.NewHybridStorage(filepath.Join(tempDir, ".repocontext"))
+	if err := storage.Initialize(); err != nil {
+		t.Fatalf("Failed to initialize storage: %v", err)
+	}
+	defer storage.Close()
+
+	if err := storage.StoreFileContext(&models.FileContext{
+		Path: "app/report.py", Language: "python",
+		Types: []models.TypeDef{{Name: "Report", Kind: "class", StartLine: 1, EndLine: 10, Methods: []models.Method{
+			{Name: "render", Signature: "def render(self) -> str", StartLine: 4, EndLine: 10},
+		}}},
+	}); err != nil {
+		t.Fatalf("Failed to store file context: %v", err)
+	}
+
+	server := &RepoContextMCPServer{QueryEngine: index.NewQueryEngine(storage), Storage: storage, RepoPath: tempDir}
+
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]interface{}{"file_path": filepath.Join(tempDir, "app", "report.py"), "line": 6}
+	result, err := server.HandleSymbolAtLocation(context.Background(), request)
+	if err != nil || result.IsError {
+		t.Fatalf("Expected symbol_at_location to succeed, got %v %+v", err, result)
+	}
+	var location SymbolAtLocationResult
+	if err := json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &location); err != nil {
+		t.Fatalf("Failed to decode result: %v", err)
+	}
+	if location.FilePath != "app/report.py" || location.Symbol == nil || location.Symbol.Name != "Report.render" {
+		t.Errorf("Expected Report.render in app/report.py, got %+v", location)
+	}
+
+	request.Params.Arguments = map[string]interface{}{"file_path": "app/report.py", "line": 20}
+	result, err = server.HandleSymbolAtLocation(context.Background(), request)
+	if err != nil || result.IsError {
+		t.Fatalf("Expected symbol_at_location to succeed, got %v %+v", err, result)
+	}
+	location = SymbolAtLocationResult{}
+	if err := json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &location); err != nil {
+		t.Fatalf("Failed to decode result: %v", err)
+	}
+	if location.Symbol != nil {
+		t.Errorf("Expected no symbol past the end of the file, got %+v", location.Symbol)
+	}
+
+	for _, arguments := range []map[string]interface{}{
+		{"line": 6},
+		{"file_path": "app/report.py"},
+		{"file_path": "app/report.py", "line": -2},
+	} {
+		request.Params.Arguments = arguments
+		result, err = server.HandleSymbolAtLocation(context.Background(), request)
+		if err != nil || !result.IsError {
+			t.Errorf("Expected a validation error for %v, got %v %+v", arguments, err, result)
+		}
+	}
+}
+
+// TestHandleBatchQuery tests running several sub-queries in one call with per-query errors
+func TestHandleBatchQuery(t *testing.T) {
+	tempDir := t.TempDir()
+	storage := index.NewHybridStorage(filepath.Join(tempDir, ".repocontext"))
+	if err := storage.Initialize(); err != nil {
+		t.Fatalf("Failed to initialize storage: %v", err)
+	}
+	defer storage.Close()
+
+	if err := storage.StoreFileContext(&models.FileContext{
+		Path: "cmd/main.go", Language: "go",
+		Functions: []models.Function{
+			{Name: "main", Signature: "func main()", StartLine: 1, EndLine: 5, Calls: []string{"run"}},
+			{Name: "run", Signature: "func run() error", StartLine: 7, EndLine: 12},
+		},
+	}); err != nil {
+		t.Fatalf("Failed to store file context: %v", err)
+	}
+
+	server := &RepoContextMCPServer{QueryEngine: index.NewQueryEngine(storage), Storage: storage, RepoPath: tempDir}
+
+	decode := func(result *mcp.CallToolResult) BatchQueryResult {
+		t.Helper()
+		var batch BatchQueryResult
+		if err := json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &batch); err != nil {
+			t.Fatalf("Failed to decode result: %v", err)
+		}
+		return batch
+	}
+
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]interface{}{"queries": []interface{}{
+		map[string]interface{}{"type": "name", "query": "run"},
+		map[string]interface{}{"type": "call_graph", "query": "main", "options": map[string]interface{}{"max_depth": 1}},
+		map[string]interface{}{"type": "unknown", "query": "run"},
+		map[string]interface{}{"type": "pattern", "query": " "},
+		"not an object",
+	}}
+	result, err := server.HandleBatchQuery(context.Background(), request)
+	if err != nil || result.IsError {
+		t.Fatalf("Expected batch_query to succeed, got %v %+v", err, result)
+	}
+	batch := decode(result)
+	if len(batch.Results) != 5 || batch.Succeeded != 2 || batch.Failed != 3 || batch.Truncated {
+		t.Fatalf("Expected 2 successes and 3 failures, got %+v", batch)
+	}
+	for i, outcome := range batch.Results {
+		if outcome.Index != i {
+			t.Errorf("Expected results in request order, got index %d at %d", outcome.Index, i)
+		}
+	}
+	if !batch.Results[0].Success || !strings.Contains(string(batch.Results[0].Result), "func run() error") {
+		t.Errorf("Expected the name query to find run, got %+v", batch.Results[0])
+	}
+	if !batch.Results[1].Success || batch.Results[1].Tokens == 0 {
+		t.Errorf("Expected the call graph query to succeed with a token estimate, got %+v", batch.Results[1])
+	}
+	if !strings.Contains(batch.Results[2].Error, "unknown query type") || batch.Results[3].Error != "query is required" ||
+		batch.Results[4].Error != "query must be an object" {
+		t.Errorf("Expected per-query validation errors, got %+v", batch.Results[2:])
+	}
+
+	request.Params.Arguments = map[string]interface{}{"max_tokens": 1, "queries": []interface{}{
+		map[string]interface{}{"type": "name", "query": "run"},
+		map[string]interface{}{"type": "name", "query": "main"},
+	}}
+	result, err = server.HandleBatchQuery(context.Background(), request)
+	if err != nil || result.IsError {
+		t.Fatalf("Expected batch_query to succeed, got %v %+v", err, result)
+	}
+	batch = decode(result)
+	if !batch.Truncated || batch.Results[1].Success || !strings.HasPrefix(batch.Results[1].Error, "skipped") {
+		t.Errorf("Expected the second query to be skipped once the budget was spent, got %+v", batch)
+	}
+
+	tooMany := make([]interface{}, MaxBatchQueries+1)
+	for i := range tooMany {
+		tooMany[i] = map[string]interface{}{"type": "name", "query": "run"}
+	}
+	for _, arguments := range []map[string]interface{}{
+		{},
+		{"queries": []interface{}{}},
+		{"queries": tooMany},
+		{"queries": []interface{}{map[string]interface{}{"type": "name", "query": "run"}}, "max_tokens": -1},
+	} {
+		request.Params.Arguments = arguments
+		result, err = server.HandleBatchQuery(context.Background(), request)
+		if err != nil || !result.IsError {
+			t.Errorf("Expected a validation error for %v, got %v %+v", arguments, err, result)
+		}
+	}
+}
+
+// TestHandleFindRecursion tests listing self-recursive functions and mutually recursive groups
+func TestHandleFindRecursion(t *testing.T) {
+	tempDir := t.TempDir()
+	storage := index.NewHybridStorage(filepath.Join(tempDir, ".repocontext"))
+	if err := storage.Initialize(); err != nil {
+		t.Fatalf("Failed to initialize storage: %v", err)
+	}
+	defer storage.Close()
+
+	if err := storage.StoreFileContext(&models.FileContext{
+		Path: "parity.go", Language: "go",
+		Functions: []models.Function{
+			{Name: "isOdd", Signature: "func isOdd(n int) bool", StartLine: 1, EndLine: 3, Calls: []string{"isEven"}},
+			{Name: "isEven", Signature: "func isEven(n int) bool", StartLine: 5, EndLine: 7, Calls: []string{"isOdd"}},
+			{
+				Name: "factorial", Signature: "func factorial(n int) int", StartLine: 9, EndLine: 14,
+				Calls: []string{"factorial"}, IsRecursive: true,
+			},
+		},
+	}); err != nil {
+		t.Fatalf("Failed to store file context: %v", err)
+	}
+
+	server := &RepoContextMCPServer{QueryEngine: index.NewQueryEngine(storage), Storage: storage, RepoPath: tempDir}
+
+	request := mcp.CallToolRequest{}
+	result, err := server.HandleFindRecursion(context.Background(), request)
+	if err != nil || result.IsError {
+		t.Fatalf("Expected find_recursion to succeed, got %v %+v", err, result)
+	}
+	var recursion FindRecursionResult
+	if err := json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &recursion); err != nil {
+		t.Fatalf("Failed to decode result: %v", err)
+	}
+	if len(recursion.SelfRecursive) != 1 || recursion.SelfRecursive[0].Name != "factorial" {
+		t.Errorf("Expected factorial to be self-recursive, got %+v", recursion.SelfRecursive)
+	}
+	if len(recursion.Groups) != 1 || !slices.Equal(recursion.Groups[0], []string{"isEven", "isOdd"}) {
+		t.Errorf("Expected one group of isEven and isOdd, got %v", recursion.Groups)
+	}
+
+	request.Params.Arguments = map[string]interface{}{"function_name": "factorial"}
+	result, err = server.HandleGetFunctionContext(context.Background(), request)
+	if err != nil || result.IsError {
+		t.Fatalf("Expected get_function_context to succeed, got %v %+v", err, result)
+	}
+	var functionContext FunctionContextResult
+	if err := json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &functionContext); err != nil {
+		t.Fatalf("Failed to decode result: %v", err)
+	}
+	if !functionContext.IsRecursive {
+		t.Errorf("Expected get_function_context to report factorial as recursive, got %+v", functionContext)
+	}
+
+	request.Params.Arguments = map[string]interface{}{"limit": -1}
+	result, err = server.HandleFindRecursion(context.Background(), request)
+	if err != nil || !result.IsError {
+		t.Errorf("Expected a validation error for a negative limit, got %v %+v", err, result)
+	}
+}
+
+// TestHandleResolveImport tests resolving imports to their definitions and not-found suggestions
+func TestHandleResolveImport(t *testing.T) {
+	tempDir := t.TempDir()
+	storage := index.NewHybridStorage(filepath.Join(tempDir, ".repocontext"))
+	if err := storage.Initialize(); err != nil {
+		t.Fatalf("Failed to initialize storage: %v", err)
+	}
+	defer storage.Close()
+
+	for _, fileContext := range []*models.FileContext{
+		{
+			Path: "app/models.py", Language: "python",
+			Types: []models.TypeDef{{Name: "User", Kind: "class", StartLine: 3, EndLine: 10}},
+		},
+		{
+			Path: "app/views.py", Language: "python",
+			Imports: []models.Import{{Path: ".models.User"}},
+		},
+	} {
+		if err := storage.StoreFileContext(fileContext); err != nil {
+			t.Fatalf("Failed to store file context: %v", err)
+		}
+	}
+
+	server := &RepoContextMCPServer{QueryEngine: index.NewQueryEngine(storage), Storage: storage, RepoPath: tempDir}
+
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]interface{}{"import_path": ".models.User", "from_file": "app/views.py"}
+	result, err := server.HandleResolveImport(context.Background(), request)
+	if err != nil || result.IsError {
+		t.Fatalf("Expected resolve_import to succeed, got %v %+v", err, result)
+	}
+	var resolved ResolveImportResult
+	if err := json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &resolved); err != nil {
+		t.Fatalf("Failed to decode result: %v", err)
+	}
+	if resolved.Symbol != "User" || resolved.Definition == nil || resolved.Definition.File != "app/models.py" ||
+		resolved.Definition.StartLine != 3 {
+		t.Errorf("Expected User in app/models.py, got %+v", resolved)
+	}
+
+	request.Params.Arguments = map[string]interface{}{"import_path": "app.models", "symbol": "Usr"}
+	result, err = server.HandleResolveImport(context.Background(), request)
+	if err != nil || !result.IsError || !strings.Contains(toolResultText(result), "did you mean 'User'") {
+		t.Errorf("Expected a not-found error suggesting User, got %v %+v", err, result)
+	}
+
+	for _, arguments := range []map[string]interface{}{
+		{},
+		{"import_path": "models"},
+		{"import_path": ".models", "symbol": "User"},
+	} {
+		request.Params.Arguments = arguments
+		result, err = server.HandleResolveImport(context.Background(), request)
+		if err != nil || !result.IsError {
+			t.Errorf("Expected an error for %v, got %v %+v", arguments, err, result)
+		}
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"repository-context-protocol/internal/index"
 
@@ -53,6 +54,71 @@ func TestRepoContextMCPServer_RegisterQueryTools(t *testing.T) {
 			name:        "list_types",
 			description: "List all types in the repository with pagination and signature control",
 		},
+		{
+			name:        "autocomplete",
+			description: "Complete a symbol name prefix, returning matching names with their entity kind",
+		},
+		{
+			name:        "query_by_decorator",
+			description: "Find functions and types carrying a decorator or annotation, e.g. @app.route or @Entity",
+		},
+		{
+			name: "analyze_imports",
+			description: "List imported packages and modules with usage counts and importing files, " +
+				"grouped into stdlib, third_party, internal and relative imports where the language allows",
+		},
+		{
+			name:        "list_files",
+			description: "List indexed files with language, checksum, modification time and entity counts",
+		},
+		{
+			name:        "get_hotspots",
+			description: "List the most called functions, or those calling the most functions, to find the center of a codebase",
+		},
+		{
+			name: "search_content",
+			description: "Search the text of indexed files line by line with a regex, returning file:line:snippet matches. " +
+				"Finds string literals, comments and config values that symbol searches miss",
+		},
+		{
+			name:        "find_call_path",
+			description: "Find the chains of calls leading from one function to another, shortest first",
+		},
+		{
+			name: "get_test_coverage",
+			description: "Estimate which public functions have no test reaching them through the call graph. " +
+				"A heuristic based on call names, not measured coverage",
+		},
+		{
+			name: "get_overview",
+			description: "Summarize the repository in one call: languages, entity counts, entry points, public API, " +
+				"most called functions and largest packages. Use it first to orient before exploring",
+		},
+		{
+			name: "find_dead_code",
+			description: "List exported functions and types that nothing in the repository calls or mentions, as candidates for removal. " +
+				"A heuristic: callers outside the repository and reflection are not seen, so review each candidate",
+		},
+		{
+			name: "symbol_at_location",
+			description: "Find the innermost function, method or type containing a line of a file, " +
+				"e.g. to interpret a stack trace frame or an editor cursor position",
+		},
+		{
+			name: "batch_query",
+			description: "Run several read-only queries in one call and get each result or error in request order; " +
+				"a failing query does not fail the others. Cheaper than one call per query for many small lookups",
+		},
+		{
+			name: "find_recursion",
+			description: "List functions that call themselves and groups of functions that call each other in a cycle, " +
+				"e.g. to review stack depth or performance of recursive code",
+		},
+		{
+			name: "resolve_import",
+			description: "Find where an imported symbol is defined, e.g. User in 'from .models import User' or in a Go package path, " +
+				"to navigate from an import to the definition",
+		},
 	}
 
 	// Verify exact number of tools returned
@@ -166,6 +232,104 @@ func TestAdvancedHandlers_ValidationFlow(t *testing.T) {
 				return server.HandleAdvancedListTypes(ctx, request)
 			},
 		},
+		{
+			name:     "HandleAutocomplete",
+			toolName: "autocomplete",
+			handlerFunc: func(server *RepoContextMCPServer, ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				return server.HandleAutocomplete(ctx, request)
+			},
+		},
+		{
+			name:     "HandleQueryByDecorator",
+			toolName: "query_by_decorator",
+			handlerFunc: func(server *RepoContextMCPServer, ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				return server.HandleQueryByDecorator(ctx, request)
+			},
+		},
+		{
+			name:     "HandleAnalyzeImports",
+			toolName: "analyze_imports",
+			handlerFunc: func(server *RepoContextMCPServer, ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				return server.HandleAnalyzeImports(ctx, request)
+			},
+		},
+		{
+			name:     "HandleListFiles",
+			toolName: "list_files",
+			handlerFunc: func(server *RepoContextMCPServer, ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				return server.HandleListFiles(ctx, request)
+			},
+		},
+		{
+			name:     "HandleGetHotspots",
+			toolName: "get_hotspots",
+			handlerFunc: func(server *RepoContextMCPServer, ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				return server.HandleGetHotspots(ctx, request)
+			},
+		},
+		{
+			name:     "HandleSearchContent",
+			toolName: "search_content",
+			handlerFunc: func(server *RepoContextMCPServer, ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				return server.HandleSearchContent(ctx, request)
+			},
+		},
+		{
+			name:     "HandleFindCallPath",
+			toolName: "find_call_path",
+			handlerFunc: func(server *RepoContextMCPServer, ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				return server.HandleFindCallPath(ctx, request)
+			},
+		},
+		{
+			name:     "HandleGetTestCoverage",
+			toolName: "get_test_coverage",
+			handlerFunc: func(server *RepoContextMCPServer, ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				return server.HandleGetTestCoverage(ctx, request)
+			},
+		},
+		{
+			name:     "HandleGetOverview",
+			toolName: "get_overview",
+			handlerFunc: func(server *RepoContextMCPServer, ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				return server.HandleGetOverview(ctx, request)
+			},
+		},
+		{
+			name:     "HandleFindDeadCode",
+			toolName: "find_dead_code",
+			handlerFunc: func(server *RepoContextMCPServer, ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				return server.HandleFindDeadCode(ctx, request)
+			},
+		},
+		{
+			name:     "HandleSymbolAtLocation",
+			toolName: "symbol_at_location",
+			handlerFunc: func(server *RepoContextMCPServer, ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				return server.HandleSymbolAtLocation(ctx, request)
+			},
+		},
+		{
+			name:     "HandleBatchQuery",
+			toolName: "batch_query",
+			handlerFunc: func(server *RepoContextMCPServer, ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				return server.HandleBatchQuery(ctx, request)
+			},
+		},
+		{
+			name:     "HandleFindRecursion",
+			toolName: "find_recursion",
+			handlerFunc: func(server *RepoContextMCPServer, ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				return server.HandleFindRecursion(ctx, request)
+			},
+		},
+		{
+			name:     "HandleResolveImport",
+			toolName: "resolve_import",
+			handlerFunc: func(server *RepoContextMCPServer, ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				return server.HandleResolveImport(ctx, request)
+			},
+		},
 	}
 
 	for _, tc := range testCases {
@@ -569,6 +733,39 @@ func helper() string {
 		}
 	})
 
+	t.Run("failed files are reported without failing the build", func(t *testing.T) {
+		tempDir := t.TempDir()
+
+		server := NewRepoContextMCPServer()
+		if _, err := server.initializeRepositoryStructure(tempDir); err != nil {
+			t.Fatalf("Failed to initialize repository: %v", err)
+		}
+
+		files := map[string]string{
+			"main.go":   "package main\n\nfunc main() {}\n",
+			"broken.go": "package main\n\nfunc broken( {\n}\n",
+		}
+		for name, content := range files {
+			if err := os.WriteFile(filepath.Join(tempDir, name), []byte(content), ConstFilePermission600); err != nil {
+				t.Fatalf("Failed to create %s: %v", name, err)
+			}
+		}
+
+		result, err := server.buildRepositoryIndex(tempDir, false)
+		if err != nil {
+			t.Fatalf("Expected build to succeed, got %v", err)
+		}
+		if result.FilesProcessed != 1 || result.FilesFailed != 1 {
+			t.Errorf("Expected 1 file processed and 1 failed, got %d and %d", result.FilesProcessed, result.FilesFailed)
+		}
+		if len(result.FailedFiles) != 1 || filepath.Base(result.FailedFiles[0].Path) != "broken.go" || result.FailedFiles[0].Line != 3 {
+			t.Errorf("Expected broken.go to be reported with its line, got %+v", result.FailedFiles)
+		}
+		if !strings.Contains(result.Message, "1 files failed") {
+			t.Errorf("Expected message to mention the failed file, got %q", result.Message)
+		}
+	})
+
 	t.Run("successful index build with custom path", func(t *testing.T) {
 		// Create a temporary directory
 		tempDir, err := os.MkdirTemp("", "build_custom_test")
@@ -779,6 +976,68 @@ var Variable1 string
 			t.Error("Expected positive build duration")
 		}
 	})
+
+	t.Run("queries after a rebuild report the new index generation", func(t *testing.T) {
+		tempDir := t.TempDir()
+		server := NewRepoContextMCPServer()
+		if _, err := server.initializeRepositoryStructure(tempDir); err != nil {
+			t.Fatalf("Failed to initialize repository: %v", err)
+		}
+		mainPath := filepath.Join(tempDir, "main.go")
+		if err := os.WriteFile(mainPath, []byte("package main\n\nfunc main() {}\n"), ConstFilePermission600); err != nil {
+			t.Fatalf("Failed to create main.go: %v", err)
+		}
+
+		build := func() {
+			t.Helper()
+			request := mcp.CallToolRequest{}
+			request.Params.Arguments = map[string]interface{}{"path": tempDir}
+			result, err := server.HandleBuildIndex(context.Background(), request)
+			if err != nil || result.IsError {
+				t.Fatalf("Expected the build to succeed, got %v (err: %v)", result.Content, err)
+			}
+		}
+		type indexVersion struct {
+			IndexGeneration uint64    `json:"index_generation"`
+			IndexBuildTime  time.Time `json:"index_build_time"`
+		}
+		query := func() indexVersion {
+			t.Helper()
+			request := mcp.CallToolRequest{}
+			request.Params.Arguments = map[string]interface{}{"name": "main"}
+			result, err := server.HandleAdvancedQueryByName(context.Background(), request)
+			if err != nil || result.IsError {
+				t.Fatalf("Expected the query to succeed, got %v (err: %v)", result.Content, err)
+			}
+			var version indexVersion
+			if err := json.Unmarshal([]byte(toolResultText(result)), &version); err != nil {
+				t.Fatalf("Failed to parse query result: %v", err)
+			}
+			return version
+		}
+
+		build()
+		server.RepoPath = tempDir
+		if err := server.initializeQueryEngine(); err != nil {
+			t.Fatalf("Failed to initialize query engine: %v", err)
+		}
+		defer server.Storage.Close()
+		before := query()
+
+		// The build writes through its own storage, not the server's
+		if err := os.WriteFile(mainPath, []byte("package main\n\nfunc main() {\n\thelper()\n}\n\nfunc helper() {}\n"), ConstFilePermission600); err != nil {
+			t.Fatalf("Failed to edit main.go: %v", err)
+		}
+		build()
+		after := query()
+
+		if after.IndexGeneration <= before.IndexGeneration {
+			t.Errorf("Expected the generation to move past %d after the rebuild, got %d", before.IndexGeneration, after.IndexGeneration)
+		}
+		if !after.IndexBuildTime.After(before.IndexBuildTime) {
+			t.Errorf("Expected the build time to move past %v after the rebuild, got %v", before.IndexBuildTime, after.IndexBuildTime)
+		}
+	})
 }
 
 // TestGetRepositoryStatus tests the get_repository_status tool functionality
@@ -1086,9 +1345,28 @@ var Config GlobalConfig = Config{Port: 8080, Host: "localhost"}
 		if status.Statistics.LastBuildDuration.Seconds() <= 0 {
 			t.Error("Expected positive last build duration")
 		}
+		if status.Statistics.LastBuild == nil {
+			t.Fatal("Expected the last build record")
+		}
+		if status.Statistics.LastBuild.Duration != buildResult.Duration {
+			t.Errorf("Expected recorded duration %v, got %v", buildResult.Duration, status.Statistics.LastBuild.Duration)
+		}
+		if status.Statistics.LastBuild.ToolVersion != ServerVersion {
+			t.Errorf("Expected tool version %s, got %q", ServerVersion, status.Statistics.LastBuild.ToolVersion)
+		}
+		if status.Statistics.LastBuild.Languages["go"] != 2 {
+			t.Errorf("Expected 2 go files recorded, got %v", status.Statistics.LastBuild.Languages)
+		}
 		if status.Statistics.IndexSize <= 0 {
 			t.Error("Expected positive index size")
 		}
+		if status.Statistics.ChunksSize <= 0 || status.Statistics.ChunksLogicalSize != status.Statistics.ChunksSize {
+			t.Errorf("Expected equal positive chunk sizes without compression, got %d on disk and %d logical",
+				status.Statistics.ChunksSize, status.Statistics.ChunksLogicalSize)
+		}
+		if status.Statistics.FragmentationEstimate < 0 || status.Statistics.FragmentationEstimate > 1 {
+			t.Errorf("Expected fragmentation estimate between 0 and 1, got %f", status.Statistics.FragmentationEstimate)
+		}
 
 		// Verify that build result matches status statistics
 		if status.Statistics.FilesProcessed != buildResult.FilesProcessed {
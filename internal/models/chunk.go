@@ -17,6 +17,27 @@ type Manifest struct {
 	Version   string               `json:"version"`    // Version of the manifest format
 	Chunks    map[string]ChunkInfo `json:"chunks"`     // ChunkID -> ChunkInfo mapping
 	UpdatedAt time.Time            `json:"updated_at"` // Last update timestamp
+
+	// BuildHistory records the most recent index builds, oldest first
+	BuildHistory []BuildRecord `json:"build_history,omitempty"`
+
+	// PathFormat is how file paths are stored: PathFormatRepoRelative, or empty for indexes
+	// built before paths were normalized, which keep them as they were passed to the builder
+	PathFormat string `json:"path_format,omitempty"`
+}
+
+// PathFormatRepoRelative marks indexes storing file paths relative to the repository root
+const PathFormatRepoRelative = "repo-relative"
+
+// BuildRecord describes a completed index build
+type BuildRecord struct {
+	StartedAt      time.Time      `json:"started_at"`          // When the build started
+	Duration       time.Duration  `json:"duration"`            // How long the build took
+	FilesProcessed int            `json:"files_processed"`     // Files parsed and stored
+	FilesFailed    int            `json:"files_failed"`        // Files that could not be read or parsed
+	FilesSkipped   int            `json:"files_skipped"`       // Files left out by build constraints, size or binary content
+	Languages      map[string]int `json:"languages,omitempty"` // Files processed per language
+	ToolVersion    string         `json:"tool_version,omitempty"`
 }
 
 // ChunkInfo provides metadata about a specific chunk without loading the full chunk data
@@ -12,16 +12,23 @@ type RepoContext struct {
 }
 
 type FileContext struct {
-	Path      string     `json:"path"`
-	Language  string     `json:"language"`
-	Checksum  string     `json:"checksum"`
-	ModTime   time.Time  `json:"mod_time"`
-	Functions []Function `json:"functions"`
-	Types     []TypeDef  `json:"types"`
-	Variables []Variable `json:"variables"`
-	Constants []Constant `json:"constants"`
-	Imports   []Import   `json:"imports"`
-	Exports   []Export   `json:"exports"`
+	Path            string     `json:"path"`
+	Language        string     `json:"language"`
+	Checksum        string     `json:"checksum"`
+	ModTime         time.Time  `json:"mod_time"`
+	Package         string     `json:"package,omitempty"`          // Declared package, for languages that have one
+	Doc             string     `json:"doc,omitempty"`              // Module-level documentation
+	BuildConstraint string     `json:"build_constraint,omitempty"` // Go build constraint guarding the file, e.g. "linux && amd64"
+	Functions       []Function `json:"functions"`
+	Types           []TypeDef  `json:"types"`
+	Variables       []Variable `json:"variables"`
+	Constants       []Constant `json:"constants"`
+	Imports         []Import   `json:"imports"`
+	Exports         []Export   `json:"exports"`
+
+	// Gzip-compressed file content, only kept when the index is built with StoreSource.
+	// Checksum identifies the uncompressed content. Excluded from JSON responses.
+	Source []byte `json:"-"`
 }
 
 type GlobalIndex struct {
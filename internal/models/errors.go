@@ -0,0 +1,47 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Parse error kinds
+const (
+	ParseErrorSyntax      = "syntax"      // The source could not be parsed
+	ParseErrorIO          = "io"          // The source or a parser resource could not be read
+	ParseErrorSetup       = "setup"       // The parser or its external tooling is unavailable
+	ParseErrorUnsupported = "unsupported" // The source relies on a construct the parser does not handle
+)
+
+// ParseError describes why a file could not be parsed, with its location when known
+type ParseError struct {
+	File    string `json:"file"`
+	Line    int    `json:"line,omitempty"`   // 1-based line, zero when unknown
+	Column  int    `json:"column,omitempty"` // 1-based column, zero when unknown
+	Message string `json:"message"`
+	Kind    string `json:"kind"` // One of the ParseError* kinds
+	Err     error  `json:"-"`    // Underlying error, if any
+}
+
+// Error renders the error as "file: kind error at line L, column C: message",
+// omitting the parts that are unknown
+func (e *ParseError) Error() string {
+	var builder strings.Builder
+	if e.File != "" {
+		builder.WriteString(e.File + ": ")
+	}
+	builder.WriteString(e.Kind + " error")
+	if e.Line > 0 {
+		builder.WriteString(fmt.Sprintf(" at line %d", e.Line))
+		if e.Column > 0 {
+			builder.WriteString(fmt.Sprintf(", column %d", e.Column))
+		}
+	}
+	builder.WriteString(": " + e.Message)
+	return builder.String()
+}
+
+// Unwrap returns the underlying error
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
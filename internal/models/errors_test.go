@@ -0,0 +1,52 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestParseError_Error(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      *ParseError
+		expected string
+	}{
+		{
+			name:     "with line and column",
+			err:      &ParseError{File: "main.go", Line: 3, Column: 12, Message: "expected ')'", Kind: ParseErrorSyntax},
+			expected: "main.go: syntax error at line 3, column 12: expected ')'",
+		},
+		{
+			name:     "with line only",
+			err:      &ParseError{File: "Broken.java", Line: 7, Message: "unterminated literal", Kind: ParseErrorSyntax},
+			expected: "Broken.java: syntax error at line 7: unterminated literal",
+		},
+		{
+			name:     "without location",
+			err:      &ParseError{File: "app.py", Message: "python executable not found", Kind: ParseErrorSetup},
+			expected: "app.py: setup error: python executable not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.err.Error(); got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestParseError_Unwrap(t *testing.T) {
+	cause := errors.New("exit status 1")
+	err := fmt.Errorf("failed to parse file: %w", &ParseError{File: "app.py", Message: "extractor failed", Kind: ParseErrorSetup, Err: cause})
+
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) || parseErr.Kind != ParseErrorSetup {
+		t.Fatalf("Expected wrapped ParseError to be found, got %v", err)
+	}
+	if !errors.Is(err, cause) {
+		t.Error("Expected ParseError to unwrap to its cause")
+	}
+}
@@ -1,6 +1,9 @@
 package models
 
-import "slices"
+import (
+	"slices"
+	"time"
+)
 
 // Function representation and metadata
 type Function struct {
@@ -10,6 +13,32 @@ type Function struct {
 	Returns    []Type      `json:"returns"`
 	StartLine  int         `json:"start_line"`
 	EndLine    int         `json:"end_line"`
+	Doc        string      `json:"doc,omitempty"`         // Documentation comment preceding the declaration
+	TypeParams []TypeParam `json:"type_params,omitempty"` // Generic type parameters, in declaration order
+	Receiver   string      `json:"receiver,omitempty"`    // Receiver type name for methods, e.g. "User" for (u *User)
+	Decorators []string    `json:"decorators,omitempty"`  // Decorators or annotations as written, e.g. "@app.route('/x')"
+	Parent     string      `json:"parent,omitempty"`      // Enclosing function of a nested function, e.g. "retry" or "Service.run"
+	LineCount  int         `json:"line_count,omitempty"`  // Lines from StartLine to EndLine; zero when not computed
+	Complexity int         `json:"complexity,omitempty"`  // Cyclomatic complexity as defined by ComplexityDefinition; zero when not computed
+	IsTest     bool        `json:"is_test,omitempty"`     // Test function by its language's conventions, e.g. Go TestXxx(*testing.T)
+
+	// IsEntryPoint marks functions run from outside the code, which nothing in the index
+	// needs to call: main, Go init and TestMain, functions a Python module calls under
+	// if __name__ == "__main__", and functions matching the repository's entry point patterns
+	IsEntryPoint bool `json:"is_entry_point,omitempty"`
+
+	// IsRecursive marks functions calling themselves directly, by name or for methods through
+	// their receiver; mutual recursion is found from the call index instead
+	IsRecursive bool `json:"is_recursive,omitempty"`
+
+	// LastModified and LastCommit identify the newest commit touching the function's
+	// lines, as reported by git blame; nil and empty unless the index was built with it
+	LastModified *time.Time `json:"last_modified,omitempty"`
+	LastCommit   string     `json:"last_commit,omitempty"`
+
+	// ContentHash is the SHA-256 of the function's source lines, so index diffs can tell an
+	// edited body from one that only moved; empty when not computed
+	ContentHash string `json:"content_hash,omitempty"`
 
 	// Deprecated: Will be removed in v2.0 - Use LocalCalls + CrossFileCalls instead
 	Calls    []string `json:"calls,omitempty"`     // All function calls (local + cross-file)
@@ -60,13 +89,15 @@ func (cr CallReference) IsValid() bool {
 }
 
 type Parameter struct {
-	Name string `json:"name"`
-	Type string `json:"type"`
+	Name           string `json:"name"`
+	Type           string `json:"type"`
+	NormalizedType string `json:"normalized_type,omitempty"` // Type as mapped by NormalizeType; empty unless computed
 }
 
 type Type struct {
-	Name string `json:"name"`
-	Kind string `json:"kind"` // "basic", "struct", "interface", etc.
+	Name           string `json:"name"`
+	Kind           string `json:"kind"`                      // "basic", "struct", "interface", etc.
+	NormalizedType string `json:"normalized_type,omitempty"` // Name as mapped by NormalizeType; empty unless computed
 }
 
 type Variable struct {
@@ -74,6 +105,8 @@ type Variable struct {
 	Type      string `json:"type"`
 	StartLine int    `json:"start_line"`
 	EndLine   int    `json:"end_line"`
+	Doc       string `json:"doc,omitempty"`   // Documentation comment preceding the declaration
+	Scope     string `json:"scope,omitempty"` // ScopePackage, ScopeFile or ScopeFunction
 }
 
 type Constant struct {
@@ -82,6 +115,37 @@ type Constant struct {
 	Value     string `json:"value,omitempty"` // Optional: the constant value
 	StartLine int    `json:"start_line"`
 	EndLine   int    `json:"end_line"`
+	Doc       string `json:"doc,omitempty"`      // Documentation comment preceding the declaration
+	GroupID   string `json:"group_id,omitempty"` // Shared by constants declared in the same const block
+	Scope     string `json:"scope,omitempty"`    // ScopePackage, ScopeFile or ScopeFunction
+}
+
+// ComplexityDefinition documents how the Complexity of functions and methods is computed
+// so values can be reproduced. Parsers that do not walk function bodies leave it zero.
+const ComplexityDefinition = "1 plus one per branch point: each if (in Python also elif, conditional expressions " +
+	"and comprehension if clauses), each loop (Go for and range; Python for, while and comprehension for clauses), " +
+	"each case clause other than default (Go switch and select cases, Python match cases) and each && or || " +
+	"(Python and/or) operator. Go function literals and Python lambdas count toward the enclosing function; " +
+	"Python nested functions and classes are measured on their own."
+
+// LineCount returns the number of lines a declaration spans, zero when its lines are unknown
+func LineCount(startLine, endLine int) int {
+	if startLine <= 0 || endLine < startLine {
+		return 0
+	}
+	return endLine - startLine + 1
+}
+
+// Scope constants describing where a variable or constant is visible
+const (
+	ScopePackage  = "package"  // Package, module or class level declaration visible to other files
+	ScopeFile     = "file"     // Top-level declaration private to its file, e.g. a C static
+	ScopeFunction = "function" // Declaration local to a function body
+)
+
+// Scopes returns the valid scope values
+func Scopes() []string {
+	return []string{ScopePackage, ScopeFile, ScopeFunction}
 }
 
 type Import struct {
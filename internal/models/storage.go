@@ -9,6 +9,11 @@ type IndexEntry struct {
 	EndLine   int    `json:"end_line"`   // Ending line number
 	ChunkID   string `json:"chunk_id"`   // ID of the MessagePack chunk containing detailed data
 	Signature string `json:"signature"`  // Function signature, type definition, etc.
+	Language  string `json:"language"`   // Language of the defining file, empty in indexes built before it was recorded
+
+	// TokenCount is the estimated tokens of the entry itself, without its chunk, computed at
+	// index time with the default estimator; zero in indexes built before it was recorded
+	TokenCount int `json:"token_count,omitempty"`
 }
 
 // CallRelation represents a function call relationship stored in SQLite
@@ -0,0 +1,229 @@
+package models
+
+import "strings"
+
+// TypeNormalizations maps, per parser language, type names to their canonical name, the
+// vocabulary NormalizeType shares across languages: string, int, float, bool, byte, any,
+// void and error for primitives, bytes for a list of bytes, and list, map, set, tuple and
+// optional for containers.
+// A container keeps its type arguments, so with "List": "list" the Python List[str]
+// normalizes to list<string>. Extend it with RegisterTypeNormalization before indexing.
+var TypeNormalizations = map[string]map[string]string{
+	"go": {
+		"string": "string", "rune": "int", "byte": "byte", "bool": "bool", "error": "error",
+		"int": "int", "int8": "int", "int16": "int", "int32": "int", "int64": "int",
+		"uint": "int", "uint8": "int", "uint16": "int", "uint32": "int", "uint64": "int", "uintptr": "int",
+		"float32": "float", "float64": "float", "any": "any", "interface{}": "any",
+	},
+	"python": {
+		"str": "string", "int": "int", "float": "float", "bool": "bool",
+		"bytes": "bytes", "bytearray": "bytes", "Any": "any", "object": "any", "None": "void",
+		"list": "list", "List": "list", "Sequence": "list", "MutableSequence": "list",
+		"dict": "map", "Dict": "map", "Mapping": "map", "MutableMapping": "map",
+		"set": "set", "Set": "set", "frozenset": "set", "FrozenSet": "set",
+		"tuple": "tuple", "Tuple": "tuple", "Optional": "optional",
+	},
+	"typescript": {
+		"string": "string", "number": "float", "bigint": "int", "boolean": "bool",
+		"any": "any", "unknown": "any", "void": "void", "Error": "error",
+		"Array": "list", "ReadonlyArray": "list", "Map": "map", "ReadonlyMap": "map", "Record": "map",
+		"Set": "set", "ReadonlySet": "set",
+	},
+	"java": {
+		"String": "string", "CharSequence": "string", "boolean": "bool", "Boolean": "bool",
+		"int": "int", "long": "int", "short": "int", "Integer": "int", "Long": "int", "Short": "int", "BigInteger": "int",
+		"byte": "byte", "Byte": "byte", "float": "float", "double": "float", "Float": "float", "Double": "float",
+		"Object": "any", "void": "void", "Exception": "error", "Throwable": "error",
+		"List": "list", "ArrayList": "list", "LinkedList": "list", "Collection": "list",
+		"Map": "map", "HashMap": "map", "TreeMap": "map", "LinkedHashMap": "map",
+		"Set": "set", "HashSet": "set", "TreeSet": "set", "Optional": "optional",
+	},
+	"c": {
+		"char": "char", "bool": "bool", "_Bool": "bool", "void": "void", "float": "float", "double": "float",
+		"int": "int", "short": "int", "long": "int", "long long": "int", "unsigned": "int", "unsigned int": "int",
+		"unsigned long": "int", "size_t": "int", "int32_t": "int", "int64_t": "int", "uint32_t": "int", "uint64_t": "int",
+	},
+}
+
+// nullTypes are the union members that make the other members optional
+var nullTypes = map[string]bool{"None": true, "null": true, "undefined": true}
+
+// RegisterTypeNormalization maps the type name of language to canonical, replacing
+// any existing mapping. It is not safe to call while files are being indexed.
+func RegisterTypeNormalization(language, name, canonical string) {
+	if TypeNormalizations[language] == nil {
+		TypeNormalizations[language] = make(map[string]string)
+	}
+	TypeNormalizations[language][name] = canonical
+}
+
+// NormalizeType maps a type reference written in language to its canonical form, e.g.
+// List[str], []string and string[] all to list<string>, *User to User and string | null
+// to optional<string>. Names missing from TypeNormalizations are kept without their
+// package qualifier. It returns an empty string for languages without a table.
+func NormalizeType(typeRef, language string) string {
+	table, ok := TypeNormalizations[language]
+	if !ok {
+		return ""
+	}
+	return (&typeNormalizer{table: table, goSyntax: language == "go"}).normalize(typeRef)
+}
+
+// typeNormalizer normalizes the type references of one language
+type typeNormalizer struct {
+	table    map[string]string
+	goSyntax bool // []T, [N]T and map[K]V are Go containers rather than Python subscripts
+}
+
+// normalize normalizes a type reference, unions, pointers and containers included
+func (n *typeNormalizer) normalize(typeRef string) string {
+	typeRef = strings.Join(strings.Fields(typeRef), " ")
+	switch {
+	case typeRef == "":
+		return ""
+	case strings.Contains(typeRef, "=>") || strings.HasPrefix(typeRef, "func(") || strings.HasPrefix(typeRef, "func ("):
+		return "func"
+	}
+	if members := splitTopLevel(typeRef, '|'); len(members) > 1 {
+		return n.normalizeUnion(members)
+	}
+	for _, qualifier := range []string{"const ", "final ", "readonly ", "struct ", "? extends ", "? super "} {
+		typeRef = strings.TrimPrefix(typeRef, qualifier)
+	}
+	if n.goSyntax {
+		if normalized, ok := n.normalizeGoContainer(typeRef); ok {
+			return normalized
+		}
+	}
+
+	switch {
+	case typeRef == "?":
+		return "any"
+	case strings.HasPrefix(typeRef, "*") || strings.HasPrefix(typeRef, "&"):
+		return n.normalize(typeRef[1:])
+	case strings.HasPrefix(typeRef, "..."):
+		return container("list", n.normalize(typeRef[3:]))
+	case strings.HasSuffix(typeRef, "[]"):
+		return container("list", n.normalize(typeRef[:len(typeRef)-2]))
+	case strings.HasSuffix(typeRef, "*"):
+		return n.normalizePointer(strings.TrimSpace(strings.TrimSuffix(typeRef, "*")))
+	}
+	return n.normalizeNamed(typeRef)
+}
+
+// normalizeGoContainer normalizes the Go slice, array and map types, []T, [N]T and map[K]V
+func (n *typeNormalizer) normalizeGoContainer(typeRef string) (string, bool) {
+	switch {
+	case strings.HasPrefix(typeRef, "["):
+		if end := closingBracket(typeRef, 0); end > 0 {
+			return container("list", n.normalize(typeRef[end+1:])), true
+		}
+	case strings.HasPrefix(typeRef, "map["):
+		if end := closingBracket(typeRef, 3); end > 0 {
+			return container("map", n.normalize(typeRef[4:end]), n.normalize(typeRef[end+1:])), true
+		}
+	}
+	return "", false
+}
+
+// normalizeUnion turns a union with None, null or undefined into an optional of the rest
+func (n *typeNormalizer) normalizeUnion(members []string) string {
+	normalized := make([]string, 0, len(members))
+	optional := false
+	for _, member := range members {
+		if nullTypes[strings.TrimSpace(member)] {
+			optional = true
+			continue
+		}
+		normalized = append(normalized, n.normalize(member))
+	}
+	union := strings.Join(normalized, "|")
+	if optional {
+		return container("optional", union)
+	}
+	return union
+}
+
+// normalizePointer normalizes the target of a C pointer, char * being a string
+func (n *typeNormalizer) normalizePointer(target string) string {
+	switch normalized := n.normalize(target); normalized {
+	case "char":
+		return "string"
+	case "void":
+		return "any"
+	default:
+		return normalized
+	}
+}
+
+// normalizeNamed normalizes a type name with optional type arguments, as in Dict[str, int]
+// or Map<String, Integer>
+func (n *typeNormalizer) normalizeNamed(typeRef string) string {
+	name, arguments := typeRef, []string(nil)
+	if open := strings.IndexAny(typeRef, "[<"); open > 0 && closingBracket(typeRef, open) == len(typeRef)-1 {
+		name = strings.TrimSpace(typeRef[:open])
+		for _, argument := range splitTopLevel(typeRef[open+1:len(typeRef)-1], ',') {
+			arguments = append(arguments, n.normalize(argument))
+		}
+	}
+
+	canonical, ok := n.table[name]
+	if !ok {
+		if i := strings.LastIndex(name, "."); i >= 0 && !strings.ContainsAny(name, "{(") {
+			name = name[i+1:]
+		}
+		if canonical, ok = n.table[name]; !ok {
+			canonical = name
+		}
+	}
+	return container(canonical, arguments...)
+}
+
+// container renders a canonical type with its arguments, e.g. map<string,int>, a list
+// of bytes being bytes
+func container(name string, arguments ...string) string {
+	switch {
+	case len(arguments) == 0:
+		return name
+	case name == "list" && len(arguments) == 1 && arguments[0] == "byte":
+		return "bytes"
+	}
+	return name + "<" + strings.Join(arguments, ",") + ">"
+}
+
+// closingBracket returns the index of the bracket closing the one at open, or -1
+func closingBracket(typeRef string, open int) int {
+	depth := 0
+	for i := open; i < len(typeRef); i++ {
+		switch typeRef[i] {
+		case '[', '<', '(', '{':
+			depth++
+		case ']', '>', ')', '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// splitTopLevel splits typeRef at the separators outside brackets
+func splitTopLevel(typeRef string, separator byte) []string {
+	var parts []string
+	depth, start := 0, 0
+	for i := 0; i < len(typeRef); i++ {
+		switch typeRef[i] {
+		case '[', '<', '(', '{':
+			depth++
+		case ']', '>', ')', '}':
+			depth--
+		case separator:
+			if depth == 0 {
+				parts = append(parts, strings.TrimSpace(typeRef[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, strings.TrimSpace(typeRef[start:]))
+}
@@ -0,0 +1,85 @@
+package models
+
+import "testing"
+
+func TestNormalizeType(t *testing.T) {
+	tests := []struct {
+		language string
+		typeRef  string
+		expected string
+	}{
+		// The same list of strings in every language
+		{"python", "List[str]", "list<string>"},
+		{"python", "list[str]", "list<string>"},
+		{"go", "[]string", "list<string>"},
+		{"typescript", "string[]", "list<string>"},
+		{"typescript", "Array<string>", "list<string>"},
+		{"java", "List<String>", "list<string>"},
+		{"java", "String[]", "list<string>"},
+
+		// Primitives
+		{"go", "int64", "int"},
+		{"java", "Integer", "int"},
+		{"typescript", "number", "float"},
+		{"python", "bool", "bool"},
+		{"c", "unsigned int", "int"},
+		{"c", "const char *", "string"},
+		{"c", "void*", "any"},
+
+		// Containers
+		{"go", "map[string]interface{}", "map<string,any>"},
+		{"python", "Dict[str, List[int]]", "map<string,list<int>>"},
+		{"typescript", "Record<string, number>", "map<string,float>"},
+		{"java", "HashMap<String, Integer>", "map<string,int>"},
+		{"go", "[4]float32", "list<float>"},
+		{"go", "[]byte", "bytes"},
+		{"java", "byte[]", "bytes"},
+		{"python", "typing.Set[str]", "set<string>"},
+		{"go", "...string", "list<string>"},
+
+		// Optional values
+		{"python", "Optional[User]", "optional<User>"},
+		{"python", "User | None", "optional<User>"},
+		{"typescript", "string | null", "optional<string>"},
+		{"java", "Optional<User>", "optional<User>"},
+
+		// User types lose pointers and package qualifiers
+		{"go", "*models.User", "User"},
+		{"go", "[]*User", "list<User>"},
+		{"java", "List<? extends User>", "list<User>"},
+		{"typescript", "Promise<User[]>", "Promise<list<User>>"},
+
+		// Function types
+		{"go", "func(int) error", "func"},
+		{"typescript", "(user: User) => void", "func"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.language+" "+tt.typeRef, func(t *testing.T) {
+			if got := NormalizeType(tt.typeRef, tt.language); got != tt.expected {
+				t.Errorf("NormalizeType(%q, %q) = %q, expected %q", tt.typeRef, tt.language, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestNormalizeType_UnknownLanguage(t *testing.T) {
+	if got := NormalizeType("String", "ruby"); got != "" {
+		t.Errorf("Expected no normalized type for a language without a table, got %q", got)
+	}
+}
+
+func TestRegisterTypeNormalization(t *testing.T) {
+	defer delete(TypeNormalizations["python"], "UserList")
+
+	RegisterTypeNormalization("python", "UserList", "list")
+	if got := NormalizeType("UserList[User]", "python"); got != "list<User>" {
+		t.Errorf("Expected registered container to normalize to list<User>, got %q", got)
+	}
+
+	defer delete(TypeNormalizations, "kotlin")
+	RegisterTypeNormalization("kotlin", "MutableList", "list")
+	if got := NormalizeType("MutableList<Int>", "kotlin"); got != "list<Int>" {
+		t.Errorf("Expected new language table to be used, got %q", got)
+	}
+}
@@ -1,20 +1,63 @@
 package models
 
+import (
+	"strings"
+	"time"
+)
+
 // Type definitions and relationships
 type TypeDef struct {
-	Name      string   `json:"name"`
-	Kind      string   `json:"kind"` // "struct", "interface", "alias", "basic"
-	Fields    []Field  `json:"fields,omitempty"`
-	Methods   []Method `json:"methods,omitempty"`
-	StartLine int      `json:"start_line"`
-	EndLine   int      `json:"end_line"`
-	Embedded  []string `json:"embedded,omitempty"` // Embedded types
+	Name       string      `json:"name"`
+	Kind       string      `json:"kind"` // "struct", "interface", "alias", "basic"
+	Fields     []Field     `json:"fields,omitempty"`
+	Methods    []Method    `json:"methods,omitempty"`
+	StartLine  int         `json:"start_line"`
+	EndLine    int         `json:"end_line"`
+	Embedded   []string    `json:"embedded,omitempty"`    // Embedded types, or base classes as written for Python and Java
+	Doc        string      `json:"doc,omitempty"`         // Documentation comment preceding the declaration
+	TypeParams []TypeParam `json:"type_params,omitempty"` // Generic type parameters, in declaration order
+	Decorators []string    `json:"decorators,omitempty"`  // Decorators or annotations as written, e.g. "@dataclass"
+
+	// PromotedFields and PromotedMethods are the members of Go embedded types reachable
+	// through this type, each with PromotedFrom naming the type declaring it
+	PromotedFields  []Field  `json:"promoted_fields,omitempty"`
+	PromotedMethods []Method `json:"promoted_methods,omitempty"`
+
+	// LastModified and LastCommit identify the newest commit touching the declaration's
+	// lines, as reported by git blame; nil and empty unless the index was built with it
+	LastModified *time.Time `json:"last_modified,omitempty"`
+	LastCommit   string     `json:"last_commit,omitempty"`
+
+	// ContentHash is the SHA-256 of the declaration's source lines; empty when not computed
+	ContentHash string `json:"content_hash,omitempty"`
+}
+
+// TypeParam is a generic type parameter and its constraint
+type TypeParam struct {
+	Name       string `json:"name"`
+	Constraint string `json:"constraint"`
+}
+
+// FormatTypeParams renders type parameters in Go syntax, e.g. "[K comparable, V any]".
+// It returns an empty string when there are no type parameters.
+func FormatTypeParams(typeParams []TypeParam) string {
+	if len(typeParams) == 0 {
+		return ""
+	}
+	parts := make([]string, len(typeParams))
+	for i, typeParam := range typeParams {
+		parts[i] = typeParam.Name + " " + typeParam.Constraint
+	}
+	return "[" + strings.Join(parts, ", ") + "]"
 }
 
 type Field struct {
-	Name string `json:"name"`
-	Type string `json:"type"`
-	Tag  string `json:"tag,omitempty"`
+	Name           string `json:"name"`
+	Type           string `json:"type"`
+	Tag            string `json:"tag,omitempty"`
+	Default        string `json:"default,omitempty"`         // Default value as written, e.g. for Python dataclass fields
+	NormalizedType string `json:"normalized_type,omitempty"` // Type as mapped by NormalizeType; empty unless computed
+	PromotedFrom   string `json:"promoted_from,omitempty"`   // Embedded type declaring a promoted field
 }
 
 type Method struct {
@@ -24,4 +67,14 @@ type Method struct {
 	Returns    []Type      `json:"returns"`
 	StartLine  int         `json:"start_line"`
 	EndLine    int         `json:"end_line"`
+	Doc        string      `json:"doc,omitempty"`        // Documentation comment preceding the declaration
+	Receiver   string      `json:"receiver,omitempty"`   // Receiver type name, empty for interface methods
+	File       string      `json:"file,omitempty"`       // Declaring file when it differs from the type's file
+	Decorators []string    `json:"decorators,omitempty"` // Decorators or annotations as written, e.g. "@property"
+	LineCount  int         `json:"line_count,omitempty"` // Lines from StartLine to EndLine; zero when not computed
+	Complexity int         `json:"complexity,omitempty"` // Cyclomatic complexity as defined by ComplexityDefinition; zero when not computed
+	IsTest     bool        `json:"is_test,omitempty"`    // Test method by its language's conventions, e.g. Python test_* in a Test* class
+	Calls      []string    `json:"calls,omitempty"`      // Calls made by the method where methods are not listed as functions (Python, Ruby)
+
+	PromotedFrom string `json:"promoted_from,omitempty"` // Embedded type declaring a promoted method
 }